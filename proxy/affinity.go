@@ -0,0 +1,51 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy
+
+import (
+	"context"
+	"time"
+)
+
+// AffinityStateNamespace is the prefix of the affinity mappings in the ClusterState.
+const AffinityStateNamespace = "affinity/"
+
+// AffinityKeyFunc returns the affinity key of the call (e.g. the client or the session ID from the metadata),
+// empty key means no affinity.
+type AffinityKeyFunc func(ctx context.Context, fullMethodName string) string
+
+// AffinityDirector pins the one2one calls with the same affinity key to the same backend.
+//
+// The first call with the key is proxied to the first backend returned by the director, and the mapping is recorded
+// in the state for ttl (zero means forever). The following calls with the key are proxied to the mapped backend
+// as long as the director still returns it (the backends are matched by String()), otherwise the mapping is replaced.
+// With the ClusterState shared by the proxy replicas, the mappings are consistent across the fleet.
+// The backends can set the mappings with the routing hints, see WithRouteHints.
+func AffinityDirector(director StreamDirector, state *ClusterState, key AffinityKeyFunc, ttl time.Duration) StreamDirector {
+	return func(ctx context.Context, fullMethodName string) (Mode, []Backend, error) {
+		mode, backends, err := director(ctx, fullMethodName)
+		if err != nil || mode != One2One || len(backends) == 0 {
+			return mode, backends, err
+		}
+
+		affinityKey := key(ctx, fullMethodName)
+		if affinityKey == "" {
+			return mode, backends, nil
+		}
+
+		stateKey := AffinityStateNamespace + affinityKey
+
+		if name, ok := state.Get(stateKey); ok {
+			for _, backend := range backends {
+				if backend.String() == name {
+					return mode, []Backend{backend}, nil
+				}
+			}
+		}
+
+		state.Set(stateKey, backends[0].String(), ttl)
+
+		return mode, backends[:1], nil
+	}
+}
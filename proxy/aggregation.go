@@ -0,0 +1,368 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy
+
+import (
+	"fmt"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// AggregateFunc combines the values of the response field across the backends.
+type AggregateFunc int
+
+// AggregateFunc constants.
+const (
+	// AggregateSum sums the numeric field.
+	AggregateSum AggregateFunc = iota + 1
+	// AggregateMin picks the minimum of the numeric, string, google.protobuf.Timestamp or google.protobuf.Duration
+	// field, the backends which didn't set the field are ignored.
+	AggregateMin
+	// AggregateMax picks the maximum, see AggregateMin.
+	AggregateMax
+	// AggregateConcat concatenates the repeated field.
+	AggregateConcat
+)
+
+func (f AggregateFunc) String() string {
+	switch f {
+	case AggregateSum:
+		return "sum"
+	case AggregateMin:
+		return "min"
+	case AggregateMax:
+		return "max"
+	case AggregateConcat:
+		return "concat"
+	default:
+		return "unknown"
+	}
+}
+
+// AggregationPolicy configures merging of the one2many unary responses into a single response.
+type AggregationPolicy struct {
+	// Fields maps the dotted path of the field (by protobuf names, e.g. "stats.requests") to the aggregate function.
+	//
+	// The fields not listed are taken from the response of the first backend (in the priority order).
+	Fields map[string]AggregateFunc
+	// EnvelopeField is the name of the repeated message field of the output message which holds the responses
+	// of the backends (e.g. "response" for `repeated Response response = 1`), the elements of the field
+	// are aggregated into a single element. If empty, the output messages are aggregated as a whole.
+	EnvelopeField string
+}
+
+// WithResponseAggregation merges the responses of the listed one2many unary methods with the policy, instead of
+// concatenating the responses of the backends.
+//
+// The method descriptors are looked up with the descriptor resolver (see WithDescriptorResolver), the call fails
+// with ErrInternal if the method is not known or the responses don't match the policy.
+func WithResponseAggregation(policy AggregationPolicy, fullMethodNames ...string) Option {
+	return func(o *handlerOptions) {
+		for path, fn := range policy.Fields {
+			if fn < AggregateSum || fn > AggregateConcat {
+				o.invalid("unknown aggregate function %d for field %q", fn, path)
+			}
+		}
+
+		if o.aggregationPolicies == nil {
+			o.aggregationPolicies = map[string]*AggregationPolicy{}
+		}
+
+		for _, name := range fullMethodNames {
+			o.aggregationPolicies[name] = &policy
+		}
+	}
+}
+
+// mergeResponses merges the responses of the backends sorted by the priority.
+func (o *handlerOptions) mergeResponses(fullMethodName string, payloads []prioritizedPayload) ([]byte, error) {
+	// the method is resolved once for the checks and the aggregation
+	methodDesc, lookupErr := o.lookupMethod(fullMethodName)
+
+	var desc protoreflect.MessageDescriptor
+
+	if lookupErr == nil {
+		desc = methodDesc.Output()
+	}
+
+	if err := checkPayloads(fullMethodName, desc, payloads); err != nil {
+		return nil, err
+	}
+
+	policy := o.aggregationPolicies[fullMethodName]
+
+	if policy == nil {
+		var merged []byte
+		for _, p := range payloads {
+			merged = append(merged, p.payload...)
+		}
+
+		return merged, nil
+	}
+
+	if lookupErr != nil {
+		return nil, newError(ErrInternal, "error aggregating responses of %s: %v", fullMethodName, lookupErr)
+	}
+
+	merged, err := policy.aggregate(desc, payloads)
+	if err != nil {
+		return nil, newError(ErrInternal, "error aggregating responses of %s: %v", fullMethodName, err)
+	}
+
+	return merged, nil
+}
+
+// aggregate decodes the responses as the output messages and aggregates them.
+func (p *AggregationPolicy) aggregate(desc protoreflect.MessageDescriptor, payloads []prioritizedPayload) ([]byte, error) {
+	var envelope protoreflect.FieldDescriptor
+
+	if p.EnvelopeField != "" {
+		envelope = desc.Fields().ByName(protoreflect.Name(p.EnvelopeField))
+
+		if envelope == nil || !envelope.IsList() || envelope.Message() == nil {
+			return nil, fmt.Errorf("%s has no repeated message field %q", desc.FullName(), p.EnvelopeField)
+		}
+	}
+
+	elements := make([]protoreflect.Message, 0, len(payloads))
+
+	for _, payload := range payloads {
+		msg := dynamicpb.NewMessage(desc)
+
+		if err := proto.Unmarshal(payload.payload, msg); err != nil {
+			return nil, fmt.Errorf("error decoding response %s: %w", desc.FullName(), err)
+		}
+
+		if envelope == nil {
+			elements = append(elements, msg)
+
+			continue
+		}
+
+		list := msg.Get(envelope).List()
+
+		for i := 0; i < list.Len(); i++ {
+			elements = append(elements, list.Get(i).Message())
+		}
+	}
+
+	if len(elements) == 0 {
+		return nil, nil
+	}
+
+	result := proto.Clone(elements[0].Interface()).ProtoReflect()
+
+	for path, fn := range p.Fields {
+		if err := aggregateField(result, elements, strings.Split(path, "."), fn); err != nil {
+			return nil, fmt.Errorf("error aggregating field %q: %w", path, err)
+		}
+	}
+
+	if envelope == nil {
+		return proto.Marshal(result.Interface())
+	}
+
+	out := dynamicpb.NewMessage(desc)
+	out.Mutable(envelope).List().Append(protoreflect.ValueOfMessage(result))
+
+	return proto.Marshal(out)
+}
+
+// aggregateField sets the field of the result at the path to the aggregate of the field of the elements.
+func aggregateField(result protoreflect.Message, elements []protoreflect.Message, path []string, fn AggregateFunc) error {
+	// resolve the parent message of the field in the result, and the field values of the elements
+	parent := result
+	values := append([]protoreflect.Message(nil), elements...)
+
+	for i, name := range path {
+		fd := parent.Descriptor().Fields().ByName(protoreflect.Name(name))
+		if fd == nil {
+			return fmt.Errorf("%s has no field %q", parent.Descriptor().FullName(), name)
+		}
+
+		if i == len(path)-1 {
+			return setAggregate(parent, fd, values, fn)
+		}
+
+		if fd.IsList() || fd.IsMap() || fd.Message() == nil {
+			return fmt.Errorf("field %q is not a singular message", name)
+		}
+
+		parent = parent.Mutable(fd).Message()
+
+		nested := values[:0]
+
+		for _, v := range values {
+			if v.Has(fd) {
+				nested = append(nested, v.Get(fd).Message())
+			}
+		}
+
+		values = nested
+	}
+
+	return nil
+}
+
+// setAggregate sets the field of the parent to the aggregate of the field of the values.
+func setAggregate(parent protoreflect.Message, fd protoreflect.FieldDescriptor, values []protoreflect.Message, fn AggregateFunc) error {
+	if fn == AggregateConcat {
+		if !fd.IsList() {
+			return fmt.Errorf("concat requires a repeated field")
+		}
+
+		parent.Clear(fd)
+
+		list := parent.Mutable(fd).List()
+
+		for _, v := range values {
+			src := v.Get(fd).List()
+
+			for i := 0; i < src.Len(); i++ {
+				list.Append(src.Get(i))
+			}
+		}
+
+		return nil
+	}
+
+	if fd.IsList() || fd.IsMap() {
+		return fmt.Errorf("%s requires a singular field", fn)
+	}
+
+	if fn == AggregateSum {
+		sum, err := sumValues(fd, values)
+		if err != nil {
+			return err
+		}
+
+		parent.Set(fd, sum)
+
+		return nil
+	}
+
+	var (
+		picked protoreflect.Value
+		found  bool
+	)
+
+	for _, v := range values {
+		if !v.Has(fd) {
+			continue
+		}
+
+		value := v.Get(fd)
+
+		if !found {
+			picked, found = value, true
+
+			continue
+		}
+
+		cmp, err := compareValues(fd, value, picked)
+		if err != nil {
+			return err
+		}
+
+		if (fn == AggregateMin && cmp < 0) || (fn == AggregateMax && cmp > 0) {
+			picked = value
+		}
+	}
+
+	if found {
+		parent.Set(fd, picked)
+	}
+
+	return nil
+}
+
+// sumValues sums the numeric field of the values.
+func sumValues(fd protoreflect.FieldDescriptor, values []protoreflect.Message) (protoreflect.Value, error) {
+	var (
+		i int64
+		u uint64
+		f float64
+	)
+
+	for _, v := range values {
+		value := v.Get(fd)
+
+		switch fd.Kind() { //nolint:exhaustive
+		case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind,
+			protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+			i += value.Int()
+		case protoreflect.Uint32Kind, protoreflect.Fixed32Kind, protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+			u += value.Uint()
+		case protoreflect.FloatKind, protoreflect.DoubleKind:
+			f += value.Float()
+		default:
+			return protoreflect.Value{}, fmt.Errorf("sum requires a numeric field, got %s", fd.Kind())
+		}
+	}
+
+	switch fd.Kind() { //nolint:exhaustive
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		return protoreflect.ValueOfInt32(int32(i)), nil
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		return protoreflect.ValueOfInt64(i), nil
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		return protoreflect.ValueOfUint32(uint32(u)), nil
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return protoreflect.ValueOfUint64(u), nil
+	case protoreflect.FloatKind:
+		return protoreflect.ValueOfFloat32(float32(f)), nil
+	case protoreflect.DoubleKind:
+		return protoreflect.ValueOfFloat64(f), nil
+	default:
+		return protoreflect.Value{}, fmt.Errorf("sum requires a numeric field, got %s", fd.Kind())
+	}
+}
+
+// compareValues compares the values of the field, returning -1, 0 or 1.
+func compareValues(fd protoreflect.FieldDescriptor, a, b protoreflect.Value) (int, error) {
+	switch fd.Kind() { //nolint:exhaustive
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind,
+		protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		return compareOrdered(a.Int(), b.Int()), nil
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind, protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return compareOrdered(a.Uint(), b.Uint()), nil
+	case protoreflect.FloatKind, protoreflect.DoubleKind:
+		return compareOrdered(a.Float(), b.Float()), nil
+	case protoreflect.StringKind:
+		return compareOrdered(a.String(), b.String()), nil
+	case protoreflect.MessageKind:
+		switch fd.Message().FullName() {
+		case "google.protobuf.Timestamp", "google.protobuf.Duration":
+			return compareSecondsNanos(a.Message(), b.Message()), nil
+		}
+	}
+
+	return 0, fmt.Errorf("field of kind %s is not ordered", fd.Kind())
+}
+
+// compareSecondsNanos compares google.protobuf.Timestamp or google.protobuf.Duration messages.
+func compareSecondsNanos(a, b protoreflect.Message) int {
+	fields := a.Descriptor().Fields()
+	seconds, nanos := fields.ByName("seconds"), fields.ByName("nanos")
+
+	if cmp := compareOrdered(a.Get(seconds).Int(), b.Get(seconds).Int()); cmp != 0 {
+		return cmp
+	}
+
+	return compareOrdered(a.Get(nanos).Int(), b.Get(nanos).Int())
+}
+
+func compareOrdered[T int64 | uint64 | float64 | string](a, b T) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,79 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy_test
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/noncepad/grpc-proxy/proxy"
+	pb "github.com/noncepad/grpc-proxy/testservice"
+)
+
+// counterService responds with the counter set to the backend tag.
+type counterService struct {
+	lenientService
+}
+
+func (s *counterService) Ping(ctx context.Context, ping *pb.PingRequest) (*pb.PingResponse, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	tag := md.Get(backendTagMdKey)[0]
+
+	counter, err := strconv.Atoi(tag)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	return &pb.PingResponse{Value: "backend" + tag, Counter: int32(counter)}, nil
+}
+
+func TestResponseAggregation(t *testing.T) {
+	const method = "/talos.testproto.TestService/Ping"
+
+	for _, tt := range []struct {
+		fn       proxy.AggregateFunc
+		expected int32
+	}{
+		{fn: proxy.AggregateSum, expected: 9},
+		{fn: proxy.AggregateMin, expected: 1},
+		{fn: proxy.AggregateMax, expected: 5},
+	} {
+		tt := tt
+
+		t.Run(tt.fn.String(), func(t *testing.T) {
+			h := newTestHarnessWithService(t, &counterService{}, allFailedDirector("3", "5", "1"),
+				proxy.WithResponseAggregation(proxy.AggregationPolicy{
+					Fields: map[string]proxy.AggregateFunc{"counter": tt.fn},
+				}, method),
+			)
+
+			resp, err := h.client.Ping(testContext(t), &pb.PingRequest{Value: "ping"})
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.expected, resp.Counter)
+			// the other fields come from the first backend
+			assert.Equal(t, "backend3", resp.Value)
+		})
+	}
+}
+
+func TestResponseAggregationInvalid(t *testing.T) {
+	const method = "/talos.testproto.TestService/Ping"
+
+	h := newTestHarnessWithService(t, &counterService{}, allFailedDirector("3", "5"),
+		proxy.WithResponseAggregation(proxy.AggregationPolicy{
+			Fields: map[string]proxy.AggregateFunc{"Value": proxy.AggregateSum},
+		}, method),
+	)
+
+	_, err := h.client.Ping(testContext(t), &pb.PingRequest{Value: "ping"})
+	assert.Equal(t, codes.Internal, status.Code(err))
+}
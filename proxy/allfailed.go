@@ -0,0 +1,192 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy
+
+import (
+	"context"
+	"sync"
+)
+
+// BackendError is the error of the backend of one2many call.
+type BackendError struct {
+	Backend Backend
+	Err     error
+}
+
+// AllFailedBuilder builds the response sent to the client when all the backends of one2many call fail.
+//
+// The response replaces the error responses built by Backend.BuildError for each backend, so that the clients of
+// the aggregate APIs receive one well-formed summary instead of an error envelope per backend. If AllFailedBuilder
+// returns an error, the call fails with that error.
+type AllFailedBuilder func(fullMethodName string, streaming bool, errs []BackendError) ([]byte, error)
+
+// WithAllFailedResponse sets the builder of the response for one2many calls of the listed methods which fail on all
+// the backends.
+//
+// The director can override the builder for the call with SetAllFailedResponse.
+// If fullMethodNames is empty, the builder is used for all methods without a method-specific builder.
+//
+// In streaming mode, the errors of the backends are delayed until any backend sends a response (or finishes the call
+// successfully), as only then the proxy knows that not all the backends failed.
+func WithAllFailedResponse(builder AllFailedBuilder, fullMethodNames ...string) Option {
+	return func(o *handlerOptions) {
+		if o.allFailedBuilders == nil {
+			o.allFailedBuilders = map[string]AllFailedBuilder{}
+		}
+
+		if len(fullMethodNames) == 0 {
+			o.allFailedBuilders[""] = builder
+
+			return
+		}
+
+		for _, name := range fullMethodNames {
+			o.allFailedBuilders[name] = builder
+		}
+	}
+}
+
+type allFailedKey struct{}
+
+// allFailedSlot holds the builder set by the director.
+type allFailedSlot struct {
+	builder AllFailedBuilder
+}
+
+// SetAllFailedResponse sets the builder of the response for the call if all the backends fail.
+//
+// SetAllFailedResponse should be called by the director with the context it was invoked with, it returns false
+// if the context doesn't belong to the call.
+func SetAllFailedResponse(ctx context.Context, builder AllFailedBuilder) bool {
+	slot, ok := ctx.Value(allFailedKey{}).(*allFailedSlot)
+	if !ok {
+		return false
+	}
+
+	slot.builder = builder
+
+	return true
+}
+
+// directorContext returns the context for the director, which allows the director to set the all-failed builder.
+func directorContext(ctx context.Context) (context.Context, *allFailedSlot) {
+	slot := &allFailedSlot{}
+
+	return context.WithValue(ctx, allFailedKey{}, slot), slot
+}
+
+// allFailedBuilder returns the builder for the call, the builder set by the director takes precedence.
+func (o *handlerOptions) allFailedBuilder(fullMethodName string, slot *allFailedSlot) AllFailedBuilder {
+	if slot.builder != nil {
+		return slot.builder
+	}
+
+	if builder, ok := o.allFailedBuilders[fullMethodName]; ok {
+		return builder
+	}
+
+	return o.allFailedBuilders[""]
+}
+
+// allFailedStream delays the errors of the backends of the streaming one2many call until it is known whether
+// all the backends failed.
+//
+// All methods are safe to be called on nil stream, which doesn't delay the errors.
+type allFailedStream struct {
+	builder AllFailedBuilder
+	method  string
+	total   int
+
+	mu        sync.Mutex
+	succeeded bool
+	errs      []BackendError
+	delayed   []*backendConnection
+}
+
+func newAllFailedStream(builder AllFailedBuilder, fullMethodName string, total int) *allFailedStream {
+	if builder == nil {
+		return nil
+	}
+
+	return &allFailedStream{
+		builder: builder,
+		method:  fullMethodName,
+		total:   total,
+	}
+}
+
+// fail records the error of the backend, it returns false if the error should be sent right away.
+func (a *allFailedStream) fail(src *backendConnection, err error) bool {
+	if a == nil {
+		return false
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.succeeded {
+		return false
+	}
+
+	a.errs = append(a.errs, BackendError{Backend: src.backend, Err: err})
+	a.delayed = append(a.delayed, src)
+
+	return true
+}
+
+// succeed records the success of the backend, the delayed errors are sent with the send function.
+func (a *allFailedStream) succeed(send func(src *backendConnection, err error) error) error {
+	if a == nil {
+		return nil
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.succeeded {
+		return nil
+	}
+
+	a.succeeded = true
+
+	return a.flushLocked(send)
+}
+
+// finish sends the all-failed response if all the backends failed, or the delayed errors otherwise.
+//
+// finish is called once all the backends are done.
+func (a *allFailedStream) finish(send func(src *backendConnection, err error) error, sendSummary func(payload []byte) error) error {
+	if a == nil {
+		return nil
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.succeeded || len(a.errs) < a.total {
+		a.succeeded = true
+
+		return a.flushLocked(send)
+	}
+
+	payload, err := a.builder(a.method, true, a.errs)
+	if err != nil {
+		return err
+	}
+
+	return sendSummary(payload)
+}
+
+func (a *allFailedStream) flushLocked(send func(src *backendConnection, err error) error) error {
+	delayed, errs := a.delayed, a.errs
+	a.delayed, a.errs = nil, nil
+
+	for i := range delayed {
+		if err := send(delayed[i], errs[i].Err); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,161 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/noncepad/grpc-proxy/proxy"
+	pb "github.com/noncepad/grpc-proxy/testservice"
+)
+
+// badTagService fails the calls to the backends tagged "bad*".
+type badTagService struct {
+	lenientService
+}
+
+func badTag(ctx context.Context) error {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	if strings.HasPrefix(md.Get(backendTagMdKey)[0], "bad") {
+		return status.Error(codes.Unavailable, "backend is bad")
+	}
+
+	return nil
+}
+
+func (s *badTagService) Ping(ctx context.Context, ping *pb.PingRequest) (*pb.PingResponse, error) {
+	if err := badTag(ctx); err != nil {
+		return nil, err
+	}
+
+	return &pb.PingResponse{Value: ping.Value}, nil
+}
+
+func (s *badTagService) PingStream(stream pb.TestService_PingStreamServer) error {
+	if err := badTag(stream.Context()); err != nil {
+		return err
+	}
+
+	return s.lenientService.PingStream(stream)
+}
+
+func allFailedSummary(fullMethodName string, streaming bool, errs []proxy.BackendError) ([]byte, error) {
+	return proto.Marshal(&pb.PingResponse{Value: fmt.Sprintf("all %d failed", len(errs))})
+}
+
+func allFailedDirector(tags ...string) func(backend proxy.Backend) proxy.StreamDirector {
+	return func(backend proxy.Backend) proxy.StreamDirector {
+		return func(ctx context.Context, fullMethodName string) (proxy.Mode, []proxy.Backend, error) {
+			backends := make([]proxy.Backend, 0, len(tags))
+
+			for i, tag := range tags {
+				backends = append(backends, proxy.BackendWithPriority(&reportingBackend{taggedBackend{Backend: backend, tag: tag}}, i))
+			}
+
+			return proxy.One2Many, backends, nil
+		}
+	}
+}
+
+func TestAllFailedResponseUnary(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		tags     []string
+		expected string
+	}{
+		{
+			name:     "all failed",
+			tags:     []string{"bad1", "bad2"},
+			expected: "all 2 failed",
+		},
+		{
+			name: "partial",
+			tags: []string{"good", "bad1"},
+			// responses are merged in the priority order, so the error response comes last
+			expected: "bad1:Unavailable",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestHarnessWithService(t, &badTagService{}, allFailedDirector(tt.tags...), proxy.WithAllFailedResponse(allFailedSummary))
+
+			resp, err := h.client.Ping(testContext(t), &pb.PingRequest{Value: "good"})
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, resp.Value)
+		})
+	}
+}
+
+func TestAllFailedResponseStreaming(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		tags     []string
+		expected []string
+	}{
+		{
+			name:     "all failed",
+			tags:     []string{"bad1", "bad2"},
+			expected: []string{"all 2 failed"},
+		},
+		{
+			name:     "partial",
+			tags:     []string{"good", "bad1"},
+			expected: []string{"foo", "bad1:Unavailable"},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestHarnessWithService(t, &badTagService{}, allFailedDirector(tt.tags...), proxy.WithAllFailedResponse(allFailedSummary),
+				proxy.WithStreamedDetector(func(fullMethodName string) bool { return fullMethodName == "/talos.testproto.TestService/PingStream" }))
+
+			stream, err := h.client.PingStream(testContext(t))
+			require.NoError(t, err)
+
+			require.NoError(t, stream.Send(&pb.PingRequest{Value: "foo"}))
+			require.NoError(t, stream.CloseSend())
+
+			var values []string
+
+			for {
+				resp, err := stream.Recv()
+				if errors.Is(err, io.EOF) {
+					break
+				}
+
+				require.NoError(t, err)
+
+				values = append(values, resp.Value)
+			}
+
+			assert.ElementsMatch(t, tt.expected, values)
+		})
+	}
+}
+
+func TestAllFailedResponseDirector(t *testing.T) {
+	h := newTestHarnessWithService(t, &badTagService{}, func(backend proxy.Backend) proxy.StreamDirector {
+		director := allFailedDirector("bad1", "bad2", "bad3")(backend)
+
+		return func(ctx context.Context, fullMethodName string) (proxy.Mode, []proxy.Backend, error) {
+			assert.True(t, proxy.SetAllFailedResponse(ctx, allFailedSummary))
+
+			return director(ctx, fullMethodName)
+		}
+	})
+
+	resp, err := h.client.Ping(testContext(t), &pb.PingRequest{Value: "good"})
+	require.NoError(t, err)
+	assert.Equal(t, "all 3 failed", resp.Value)
+}
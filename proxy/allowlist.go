@@ -0,0 +1,71 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy
+
+import (
+	"strings"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// WithMethodAllowlist configures the list of methods which are allowed to be proxied.
+//
+// Each entry is either a full method name (/package.Service/Method) or a whole service (/package.Service/*).
+// Calls to any other method are rejected with codes.PermissionDenied before the director is invoked,
+// so no backend connection is ever made for them.
+func WithMethodAllowlist(fullMethodNames ...string) Option {
+	return func(o *handlerOptions) {
+		o.methodAllowlist = map[string]struct{}{}
+
+		for _, name := range fullMethodNames {
+			o.methodAllowlist[name] = struct{}{}
+		}
+	}
+}
+
+// WithRequestTypeDenylist configures the list of request message types which are not allowed to be proxied.
+//
+// Each entry is a fully-qualified protobuf message name (e.g. `google.protobuf.Empty`). The request type
+// of the method is resolved using the registered descriptors (see WithDescriptorFiles). Calls to methods with the
+// request type in the denylist, and calls to methods without registered descriptors, are rejected
+// with codes.PermissionDenied before the director is invoked.
+func WithRequestTypeDenylist(messageNames ...string) Option {
+	return func(o *handlerOptions) {
+		o.requestTypeDenylist = map[protoreflect.FullName]struct{}{}
+
+		for _, name := range messageNames {
+			o.requestTypeDenylist[protoreflect.FullName(name)] = struct{}{}
+		}
+	}
+}
+
+// checkAllowed verifies the method against allowlist and denylist.
+func (o *handlerOptions) checkAllowed(fullMethodName string) error {
+	if o.methodAllowlist != nil {
+		_, allowed := o.methodAllowlist[fullMethodName]
+
+		if !allowed {
+			if pos := strings.LastIndex(fullMethodName, "/"); pos > 0 {
+				_, allowed = o.methodAllowlist[fullMethodName[:pos]+"/*"]
+			}
+		}
+
+		if !allowed {
+			return newError(ErrMethodNotAllowed, "method %s is not allowed", fullMethodName)
+		}
+	}
+
+	if o.requestTypeDenylist != nil {
+		methodDesc, err := o.lookupMethod(fullMethodName)
+		if err != nil {
+			return newError(ErrMethodNotAllowed, "method %s is not allowed: %v", fullMethodName, err)
+		}
+
+		if _, denied := o.requestTypeDenylist[methodDesc.Input().FullName()]; denied {
+			return newError(ErrMethodNotAllowed, "method %s is not allowed: request type %s is denied", fullMethodName, methodDesc.Input().FullName())
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,60 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/noncepad/grpc-proxy/proxy"
+	pb "github.com/noncepad/grpc-proxy/testservice"
+)
+
+func TestMethodAllowlist(t *testing.T) {
+	directorCalls := 0
+
+	h := newTestHarness(t, func(backend proxy.Backend) proxy.StreamDirector {
+		return func(ctx context.Context, fullMethodName string) (proxy.Mode, []proxy.Backend, error) {
+			directorCalls++
+
+			return proxy.One2One, []proxy.Backend{backend}, nil
+		}
+	}, proxy.WithMethodAllowlist("/talos.testproto.TestService/Ping"))
+
+	ctx := testContext(t)
+
+	_, err := h.client.Ping(ctx, &pb.PingRequest{Value: "foo"})
+	require.NoError(t, err)
+
+	_, err = h.client.PingEmpty(ctx, &pb.Empty{})
+	require.Error(t, err)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	assert.Equal(t, 1, directorCalls)
+}
+
+func TestMethodAllowlistService(t *testing.T) {
+	h := newTestHarness(t, one2oneDirector, proxy.WithMethodAllowlist("/talos.testproto.TestService/*"))
+
+	_, err := h.client.PingEmpty(testContext(t), &pb.Empty{})
+	require.NoError(t, err)
+}
+
+func TestRequestTypeDenylist(t *testing.T) {
+	h := newTestHarness(t, one2oneDirector, proxy.WithRequestTypeDenylist("talos.testproto.Empty"))
+
+	ctx := testContext(t)
+
+	_, err := h.client.Ping(ctx, &pb.PingRequest{Value: "foo"})
+	require.NoError(t, err)
+
+	_, err = h.client.PingEmpty(ctx, &pb.Empty{})
+	require.Error(t, err)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+}
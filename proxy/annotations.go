@@ -0,0 +1,458 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/encoding"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+)
+
+// AnnotationContentSubtype is the content-subtype negotiated by the clients which accept annotation frames.
+//
+// With this content-subtype each message from the proxy to the client is prefixed with a byte which tells data
+// messages from annotations. Clients should use AnnotationStreamClientInterceptor, which negotiates the
+// content-subtype and strips the annotations.
+const AnnotationContentSubtype = "proxy-annotated"
+
+// ProgressAnnotationName is the name of the progress annotations, see WithAnnotations.
+const ProgressAnnotationName = "proxy.progress"
+
+// Annotation frame prefixes.
+const (
+	annotationPrefixData       byte = 0
+	annotationPrefixAnnotation byte = 1
+)
+
+// maxPendingAnnotations is the number of annotations buffered until the headers are sent.
+const maxPendingAnnotations = 64
+
+// Annotation is a side-channel message sent by the proxy to the client stream.
+type Annotation struct {
+	// Backend is the name of the backend the annotation relates to, if any.
+	Backend string
+	Name    string
+	Value   []byte
+}
+
+// Progress is the value of the progress annotation (JSON-encoded).
+type Progress struct {
+	Backend string `json:"backend"`
+	// Messages is the number of messages received from the backend so far.
+	Messages uint64 `json:"messages"`
+	Done     bool   `json:"done"`
+}
+
+// Annotator sends annotations to the client stream.
+type Annotator interface {
+	Annotate(annotation Annotation) error
+}
+
+type annotatorKey struct{}
+
+// AnnotatorFromContext returns the Annotator of the call, if the client accepts annotations.
+//
+// The context passed to the director (and to the backends) carries the Annotator, so that user-defined annotations
+// can be sent during the call.
+func AnnotatorFromContext(ctx context.Context) (Annotator, bool) {
+	annotator, ok := ctx.Value(annotatorKey{}).(Annotator)
+
+	return annotator, ok
+}
+
+// WithAnnotations enables annotation frames for the clients which negotiated AnnotationContentSubtype.
+//
+// If progressInterval is positive, one2many streaming calls periodically report ProgressAnnotationName
+// annotations for each backend, and the final progress when the call is done.
+//
+// Annotations sent before the response headers are buffered until the headers are sent, so that they don't
+// prevent upstream headers from being forwarded.
+func WithAnnotations(progressInterval time.Duration) Option {
+	return func(o *handlerOptions) {
+		o.annotations = &annotationOptions{progressInterval: progressInterval}
+	}
+}
+
+type annotationOptions struct {
+	progressInterval time.Duration
+}
+
+// annotationsNegotiated checks whether the client negotiated annotations.
+func annotationsNegotiated(ctx context.Context) bool {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	for _, contentType := range md.Get("content-type") {
+		if strings.HasSuffix(contentType, "+"+AnnotationContentSubtype) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// annotatingServerStream prefixes the messages to the client and sends the annotations.
+type annotatingServerStream struct {
+	grpc.ServerStream
+
+	ctx     context.Context //nolint:containedctx
+	pending []Annotation
+
+	mu         sync.Mutex
+	headerSent bool
+}
+
+func newAnnotatingServerStream(serverStream grpc.ServerStream) *annotatingServerStream {
+	s := &annotatingServerStream{ServerStream: serverStream}
+	s.ctx = context.WithValue(serverStream.Context(), annotatorKey{}, Annotator(s))
+
+	return s
+}
+
+// Context returns the context which carries the Annotator.
+func (s *annotatingServerStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *annotatingServerStream) SendHeader(md metadata.MD) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.ServerStream.SendHeader(md); err != nil {
+		return err
+	}
+
+	return s.flushLocked()
+}
+
+func (s *annotatingServerStream) SendMsg(m interface{}) error {
+	var payload []byte
+
+	switch msg := m.(type) {
+	case *Frame:
+		payload = msg.payload
+	case proto.Message:
+		var err error
+
+		if payload, err = proto.Marshal(msg); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unsupported message type %T", m)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.ServerStream.SendMsg(NewFrame(append([]byte{annotationPrefixData}, payload...))); err != nil {
+		return err
+	}
+
+	return s.flushLocked()
+}
+
+// Annotate implements Annotator.
+func (s *annotatingServerStream) Annotate(annotation Annotation) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.headerSent {
+		if len(s.pending) >= maxPendingAnnotations {
+			s.pending = append(s.pending[:0], s.pending[1:]...)
+		}
+
+		s.pending = append(s.pending, annotation)
+
+		return nil
+	}
+
+	return s.sendAnnotationLocked(annotation)
+}
+
+// finish flushes the pending annotations once the call is done.
+func (s *annotatingServerStream) finish() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.flushLocked() //nolint:errcheck
+}
+
+func (s *annotatingServerStream) flushLocked() error {
+	s.headerSent = true
+
+	for len(s.pending) > 0 {
+		if err := s.sendAnnotationLocked(s.pending[0]); err != nil {
+			return err
+		}
+
+		s.pending = s.pending[1:]
+	}
+
+	return nil
+}
+
+func (s *annotatingServerStream) sendAnnotationLocked(annotation Annotation) error {
+	payload := []byte{annotationPrefixAnnotation}
+	payload = protowire.AppendTag(payload, 1, protowire.BytesType)
+	payload = protowire.AppendString(payload, annotation.Backend)
+	payload = protowire.AppendTag(payload, 2, protowire.BytesType)
+	payload = protowire.AppendString(payload, annotation.Name)
+	payload = protowire.AppendTag(payload, 3, protowire.BytesType)
+	payload = protowire.AppendBytes(payload, annotation.Value)
+
+	return s.ServerStream.SendMsg(NewFrame(payload))
+}
+
+// progressReporter reports the progress of the backends of one2many call.
+type progressReporter struct {
+	annotator Annotator
+	stop      chan struct{}
+	stopped   chan struct{}
+	backends  []*progressClientStream
+}
+
+// startProgress wraps the upstream streams to count the messages and starts reporting the progress.
+func startProgress(annotator Annotator, interval time.Duration, backendConnections []backendConnection) *progressReporter {
+	r := &progressReporter{
+		annotator: annotator,
+		stop:      make(chan struct{}),
+		stopped:   make(chan struct{}),
+	}
+
+	for i := range backendConnections {
+		if backendConnections[i].clientStream == nil {
+			continue
+		}
+
+		stream := &progressClientStream{ClientStream: backendConnections[i].clientStream, backend: backendConnections[i].backend.String()}
+		backendConnections[i].clientStream = stream
+		r.backends = append(r.backends, stream)
+	}
+
+	go func() {
+		defer close(r.stopped)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				r.report()
+			case <-r.stop:
+				return
+			}
+		}
+	}()
+
+	return r
+}
+
+// finish stops the periodic reports and reports the final progress.
+func (r *progressReporter) finish() {
+	close(r.stop)
+	<-r.stopped
+
+	r.report()
+}
+
+func (r *progressReporter) report() {
+	for _, stream := range r.backends {
+		value, err := json.Marshal(Progress{
+			Backend:  stream.backend,
+			Messages: atomic.LoadUint64(&stream.messages),
+			Done:     atomic.LoadUint32(&stream.done) == 1,
+		})
+		if err != nil {
+			continue
+		}
+
+		r.annotator.Annotate(Annotation{Backend: stream.backend, Name: ProgressAnnotationName, Value: value}) //nolint:errcheck
+	}
+}
+
+// progressClientStream counts the messages received from the backend.
+type progressClientStream struct {
+	grpc.ClientStream
+
+	backend  string
+	messages uint64
+	done     uint32
+}
+
+func (s *progressClientStream) RecvMsg(m interface{}) error {
+	err := s.ClientStream.RecvMsg(m)
+	if err != nil {
+		atomic.StoreUint32(&s.done, 1)
+
+		return err
+	}
+
+	atomic.AddUint64(&s.messages, 1)
+
+	return nil
+}
+
+// AnnotationHandler consumes the annotations received by the client.
+type AnnotationHandler func(ctx context.Context, annotation Annotation)
+
+// AnnotationStreamClientInterceptor returns a client interceptor which negotiates annotations for the client streams,
+// strips the annotation frames and passes them to the handler.
+//
+// The messages are marshaled with protobuf.
+func AnnotationStreamClientInterceptor(handler AnnotationHandler) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		stream, err := streamer(ctx, desc, cc, method, append(opts, grpc.CallContentSubtype(AnnotationContentSubtype))...)
+		if err != nil {
+			return nil, err
+		}
+
+		return &annotatedClientStream{ClientStream: stream, handler: handler}, nil
+	}
+}
+
+type annotatedClientStream struct {
+	grpc.ClientStream
+
+	handler AnnotationHandler
+}
+
+func (s *annotatedClientStream) RecvMsg(m interface{}) error {
+	for {
+		var msg annotatedMessage
+
+		if err := s.ClientStream.RecvMsg(&msg); err != nil {
+			return err
+		}
+
+		if msg.annotation != nil {
+			s.handler(s.Context(), *msg.annotation)
+
+			continue
+		}
+
+		return annotationCodec{}.Unmarshal(append([]byte{annotationPrefixData}, msg.data...), m)
+	}
+}
+
+// annotatedMessage is either a data message or an annotation.
+type annotatedMessage struct {
+	annotation *Annotation
+	data       []byte
+}
+
+// annotationCodec is the client codec for AnnotationContentSubtype.
+type annotationCodec struct{}
+
+func (annotationCodec) Marshal(v interface{}) ([]byte, error) {
+	switch msg := v.(type) {
+	case *Frame:
+		return msg.payload, nil
+	case proto.Message:
+		return proto.Marshal(msg)
+	default:
+		return nil, fmt.Errorf("unsupported message type %T", v)
+	}
+}
+
+func (annotationCodec) Unmarshal(data []byte, v interface{}) error {
+	if len(data) == 0 {
+		return errors.New("missing annotation frame prefix")
+	}
+
+	prefix, data := data[0], data[1:]
+
+	switch msg := v.(type) {
+	case *annotatedMessage:
+		switch prefix {
+		case annotationPrefixData:
+			msg.data = append([]byte(nil), data...)
+
+			return nil
+		case annotationPrefixAnnotation:
+			annotation, err := decodeAnnotation(data)
+			if err != nil {
+				return err
+			}
+
+			msg.annotation = &annotation
+
+			return nil
+		}
+	case *Frame:
+		if prefix == annotationPrefixData {
+			msg.payload = append([]byte(nil), data...)
+
+			return nil
+		}
+	case proto.Message:
+		if prefix == annotationPrefixData {
+			return proto.Unmarshal(data, msg)
+		}
+	default:
+		return fmt.Errorf("unsupported message type %T", v)
+	}
+
+	return fmt.Errorf("unexpected annotation frame prefix %d", prefix)
+}
+
+func (annotationCodec) Name() string {
+	return AnnotationContentSubtype
+}
+
+func decodeAnnotation(data []byte) (Annotation, error) {
+	var annotation Annotation
+
+	for len(data) > 0 {
+		num, typ, n := protowire.ConsumeTag(data)
+		if n < 0 {
+			return annotation, protowire.ParseError(n)
+		}
+
+		data = data[n:]
+
+		if typ != protowire.BytesType {
+			n = protowire.ConsumeFieldValue(num, typ, data)
+			if n < 0 {
+				return annotation, protowire.ParseError(n)
+			}
+
+			data = data[n:]
+
+			continue
+		}
+
+		value, n := protowire.ConsumeBytes(data)
+		if n < 0 {
+			return annotation, protowire.ParseError(n)
+		}
+
+		data = data[n:]
+
+		switch num {
+		case 1:
+			annotation.Backend = string(value)
+		case 2:
+			annotation.Name = string(value)
+		case 3:
+			annotation.Value = append([]byte(nil), value...)
+		}
+	}
+
+	return annotation, nil
+}
+
+func init() {
+	encoding.RegisterCodec(annotationCodec{})
+}
@@ -0,0 +1,103 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"github.com/noncepad/grpc-proxy/proxy"
+	pb "github.com/noncepad/grpc-proxy/testservice"
+)
+
+func TestAnnotations(t *testing.T) {
+	h := newTestHarnessWithService(t, &metadataEchoService{}, func(backend proxy.Backend) proxy.StreamDirector {
+		director := one2manyReportingDirector(backend)
+
+		return func(ctx context.Context, fullMethodName string) (proxy.Mode, []proxy.Backend, error) {
+			if annotator, ok := proxy.AnnotatorFromContext(ctx); ok {
+				require.NoError(t, annotator.Annotate(proxy.Annotation{Name: "routed", Value: []byte(fullMethodName)}))
+			}
+
+			return director(ctx, fullMethodName)
+		}
+	},
+		proxy.WithStreamedDetector(func(fullMethodName string) bool { return fullMethodName == "/talos.testproto.TestService/PingStream" }),
+		proxy.WithAnnotations(10*time.Millisecond),
+	)
+
+	var (
+		annotations []proxy.Annotation
+		mu          sync.Mutex
+	)
+
+	conn, err := grpc.Dial(h.clientConn.Target(),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithStreamInterceptor(proxy.AnnotationStreamClientInterceptor(func(ctx context.Context, annotation proxy.Annotation) {
+			mu.Lock()
+			defer mu.Unlock()
+
+			annotations = append(annotations, annotation)
+		})),
+	)
+	require.NoError(t, err)
+
+	t.Cleanup(func() { conn.Close() }) //nolint: errcheck
+
+	for _, client := range []pb.TestServiceClient{pb.NewTestServiceClient(conn), h.client} {
+		stream, err := client.PingStream(testContext(t))
+		require.NoError(t, err)
+
+		for i := 0; i < 3; i++ {
+			require.NoError(t, stream.Send(&pb.PingRequest{Value: backendTagMdKey}))
+			assert.ElementsMatch(t, []string{backendTagMdKey + "=a", backendTagMdKey + "=b"}, recvValues(t, stream, 2))
+
+			time.Sleep(20 * time.Millisecond)
+		}
+
+		require.NoError(t, stream.CloseSend())
+
+		_, err = stream.Recv()
+		require.True(t, errors.Is(err, io.EOF), "unexpected error %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	require.NotEmpty(t, annotations)
+	assert.Equal(t, proxy.Annotation{Name: "routed", Value: []byte("/talos.testproto.TestService/PingStream")}, annotations[0])
+
+	last := map[string]proxy.Progress{}
+
+	for _, annotation := range annotations[1:] {
+		require.Equal(t, proxy.ProgressAnnotationName, annotation.Name)
+
+		var progress proxy.Progress
+
+		require.NoError(t, json.Unmarshal(annotation.Value, &progress))
+		assert.Equal(t, annotation.Backend, progress.Backend)
+
+		if previous, ok := last[progress.Backend]; ok {
+			assert.GreaterOrEqual(t, progress.Messages, previous.Messages)
+		}
+
+		last[progress.Backend] = progress
+	}
+
+	// the annotations are consumed only by the negotiating client, the final progress is reported when the call is done
+	assert.Equal(t, map[string]proxy.Progress{
+		"a": {Backend: "a", Messages: 3, Done: true},
+		"b": {Backend: "b", Messages: 3, Done: true},
+	}, last)
+}
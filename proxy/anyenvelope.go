@@ -0,0 +1,316 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy
+
+import (
+	"context"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+// anyTypeURLPrefix is the default prefix of the google.protobuf.Any type URLs.
+const anyTypeURLPrefix = "type.googleapis.com/"
+
+// BackendResourceType is the type of errdetails.ResourceInfo which identifies the backend of the error
+// in the envelope, see WithAnyEnvelope.
+const BackendResourceType = "backend"
+
+// WithAnyEnvelope enables google.protobuf.Any envelopes for the listed methods.
+//
+// The client sends the request of the method as google.protobuf.Any, and the proxy forwards the wrapped message
+// to the backends. Each response message of the backend is wrapped into google.protobuf.Any, and sent to the client
+// as the envelope:
+//
+//	message AnyEnvelope {
+//	  repeated google.protobuf.Any responses = 1;
+//	}
+//
+// Envelopes of the backends are merged in one2many unary calls, so that heterogeneous backends returning different
+// concrete types can be aggregated safely. The type of the response is the output type of the method, unless
+// the backend implements ResponseTypeBackend.
+//
+// The errors of the backends which don't build the error responses themselves (see Backend.BuildError) are put
+// into the envelope as google.rpc.Status, with errdetails.ResourceInfo of BackendResourceType naming the backend,
+// so that one failing backend doesn't fail the whole one2many call. Clients might decode the envelope
+// with DecodeAnyEnvelope, or with the typed helpers of the envelope package.
+func WithAnyEnvelope(fullMethodNames ...string) Option {
+	return func(o *handlerOptions) {
+		if o.anyEnvelopeMethods == nil {
+			o.anyEnvelopeMethods = map[string]struct{}{}
+		}
+
+		for _, name := range fullMethodNames {
+			o.anyEnvelopeMethods[name] = struct{}{}
+		}
+	}
+}
+
+// ResponseTypeBackend is an optional interface implemented by backends which respond with the messages
+// of a type different from the output type of the method, see WithAnyEnvelope.
+type ResponseTypeBackend interface {
+	Backend
+
+	// ResponseType returns the full name of the response message type for the method.
+	ResponseType(fullMethodName string) protoreflect.FullName
+}
+
+// BackendWithResponseType wraps the backend to set the type of its responses, see ResponseTypeBackend.
+func BackendWithResponseType(backend Backend, responseType protoreflect.FullName) ResponseTypeBackend {
+	return &responseTypeBackend{Backend: backend, responseType: responseType}
+}
+
+type responseTypeBackend struct {
+	Backend
+
+	responseType protoreflect.FullName
+}
+
+func (b *responseTypeBackend) ResponseType(string) protoreflect.FullName {
+	return b.responseType
+}
+
+// Unwrap returns the wrapped backend.
+func (b *responseTypeBackend) Unwrap() Backend {
+	return b.Backend
+}
+
+// DecodeAnyEnvelope decodes the response message sent to the client by the proxy, see WithAnyEnvelope.
+func DecodeAnyEnvelope(payload []byte) ([]*anypb.Any, error) {
+	var responses []*anypb.Any
+
+	for len(payload) > 0 {
+		num, typ, n := protowire.ConsumeTag(payload)
+		if n < 0 {
+			return nil, protowire.ParseError(n)
+		}
+
+		payload = payload[n:]
+
+		if num != 1 || typ != protowire.BytesType {
+			n = protowire.ConsumeFieldValue(num, typ, payload)
+			if n < 0 {
+				return nil, protowire.ParseError(n)
+			}
+
+			payload = payload[n:]
+
+			continue
+		}
+
+		value, n := protowire.ConsumeBytes(payload)
+		if n < 0 {
+			return nil, protowire.ParseError(n)
+		}
+
+		payload = payload[n:]
+
+		response := &anypb.Any{}
+
+		if err := proto.Unmarshal(value, response); err != nil {
+			return nil, err
+		}
+
+		responses = append(responses, response)
+	}
+
+	return responses, nil
+}
+
+// anyEnvelopeEnabled checks whether the envelopes are enabled for the method.
+func (o *handlerOptions) anyEnvelopeEnabled(fullMethodName string) bool {
+	_, ok := o.anyEnvelopeMethods[fullMethodName]
+
+	return ok
+}
+
+// anyEnvelopeBackends wraps the backends to put their responses into the envelopes.
+func (o *handlerOptions) anyEnvelopeBackends(fullMethodName string, backends []Backend) ([]Backend, error) {
+	var outputType protoreflect.FullName
+
+	if methodDesc, err := o.lookupMethod(fullMethodName); err == nil {
+		outputType = methodDesc.Output().FullName()
+	}
+
+	wrapped := make([]Backend, len(backends))
+
+	for i, backend := range backends {
+		responseType := outputType
+
+		if rb, ok := backendAs[ResponseTypeBackend](backend); ok {
+			responseType = rb.ResponseType(fullMethodName)
+		}
+
+		if responseType == "" {
+			return nil, newError(ErrInternal, "response type of %s for %s is unknown", backend, fullMethodName)
+		}
+
+		wrapped[i] = &anyEnvelopeBackend{Backend: backend, typeURL: anyTypeURLPrefix + string(responseType)}
+	}
+
+	return wrapped, nil
+}
+
+// anyEnvelopeBackend wraps the responses and the errors of the backend into the envelopes.
+type anyEnvelopeBackend struct {
+	Backend
+
+	typeURL string
+}
+
+// Unwrap returns the wrapped backend.
+func (b *anyEnvelopeBackend) Unwrap() Backend {
+	return b.Backend
+}
+
+func (b *anyEnvelopeBackend) AppendInfo(streaming bool, resp []byte) ([]byte, error) {
+	return b.AppendInfoContext(context.Background(), "", streaming, resp)
+}
+
+func (b *anyEnvelopeBackend) BuildError(streaming bool, backendErr error) ([]byte, error) {
+	return b.BuildErrorContext(context.Background(), "", streaming, backendErr)
+}
+
+// AppendInfoContext implements ContextBackend, so that the wrapped ContextBackend is not bypassed.
+func (b *anyEnvelopeBackend) AppendInfoContext(ctx context.Context, fullMethodName string, streaming bool, resp []byte) ([]byte, error) {
+	resp, err := appendInfo(ctx, b.Backend, fullMethodName, streaming, resp)
+	if err != nil {
+		return nil, err
+	}
+
+	return b.envelope(resp)
+}
+
+// BuildErrorContext implements ContextBackend.
+func (b *anyEnvelopeBackend) BuildErrorContext(ctx context.Context, fullMethodName string, streaming bool, backendErr error) ([]byte, error) {
+	payload, err := buildError(ctx, b.Backend, fullMethodName, streaming, backendErr)
+	if err != nil {
+		return nil, err
+	}
+
+	if payload == nil {
+		return b.errorEnvelope(backendErr)
+	}
+
+	return b.envelope(payload)
+}
+
+// errorEnvelope puts the error into the envelope as google.rpc.Status.
+func (b *anyEnvelopeBackend) errorEnvelope(backendErr error) ([]byte, error) {
+	st := status.Convert(backendErr)
+
+	if withBackend, err := st.WithDetails(&errdetails.ResourceInfo{
+		ResourceType: BackendResourceType,
+		ResourceName: b.Backend.String(),
+	}); err == nil {
+		st = withBackend
+	}
+
+	response, err := anypb.New(st.Proto())
+	if err != nil {
+		return nil, err
+	}
+
+	return marshalAnyEnvelope(response)
+}
+
+func (b *anyEnvelopeBackend) envelope(payload []byte) ([]byte, error) {
+	return marshalAnyEnvelope(&anypb.Any{TypeUrl: b.typeURL, Value: payload})
+}
+
+// marshalAnyEnvelope marshals the envelope of the single response.
+func marshalAnyEnvelope(response *anypb.Any) ([]byte, error) {
+	wrapped, err := proto.Marshal(response)
+	if err != nil {
+		return nil, err
+	}
+
+	envelope := protowire.AppendTag(make([]byte, 0, len(wrapped)+8), 1, protowire.BytesType)
+
+	return protowire.AppendBytes(envelope, wrapped), nil
+}
+
+// wrapClientStream wraps the responses of the one2one upstream stream into the envelopes.
+func (b *anyEnvelopeBackend) wrapClientStream(clientStream grpc.ClientStream) grpc.ClientStream {
+	return &anyEnvelopeClientStream{ClientStream: clientStream, backend: b}
+}
+
+type anyEnvelopeClientStream struct {
+	grpc.ClientStream
+
+	backend *anyEnvelopeBackend
+}
+
+func (s *anyEnvelopeClientStream) RecvMsg(m interface{}) error {
+	if err := s.ClientStream.RecvMsg(m); err != nil {
+		return err
+	}
+
+	f, ok := m.(*Frame)
+	if !ok {
+		return nil
+	}
+
+	payload, err := s.backend.envelope(f.payload)
+	if err != nil {
+		return err
+	}
+
+	f.payload = payload
+
+	return nil
+}
+
+// anyUnwrappingServerStream unwraps the requests of the client sent as google.protobuf.Any.
+type anyUnwrappingServerStream struct {
+	grpc.ServerStream
+
+	inputType protoreflect.FullName
+}
+
+func (s *anyUnwrappingServerStream) RecvMsg(m interface{}) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+
+	f, ok := m.(*Frame)
+	if !ok {
+		return nil
+	}
+
+	request := &anypb.Any{}
+
+	if err := proto.Unmarshal(f.payload, request); err != nil {
+		return newError(ErrMalformedRequest, "error decoding request envelope: %v", err)
+	}
+
+	name := request.MessageName()
+	if !name.IsValid() {
+		return newError(ErrMalformedRequest, "request envelope has no valid type URL %q", request.TypeUrl)
+	}
+
+	if s.inputType != "" && name != s.inputType {
+		return newError(ErrMalformedRequest, "unexpected request type %s, expected %s", name, s.inputType)
+	}
+
+	f.payload = request.Value
+
+	return nil
+}
+
+// newAnyUnwrappingServerStream wraps the client stream to unwrap the requests.
+func (o *handlerOptions) newAnyUnwrappingServerStream(serverStream grpc.ServerStream, fullMethodName string) grpc.ServerStream {
+	wrapped := &anyUnwrappingServerStream{ServerStream: serverStream}
+
+	if methodDesc, err := o.lookupMethod(fullMethodName); err == nil {
+		wrapped.inputType = methodDesc.Input().FullName()
+	}
+
+	return wrapped
+}
@@ -0,0 +1,167 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/noncepad/grpc-proxy/proxy"
+	"github.com/noncepad/grpc-proxy/proxy/envelope"
+	pb "github.com/noncepad/grpc-proxy/testservice"
+)
+
+// bytesCodec sends and receives raw message bytes.
+type bytesCodec struct{}
+
+func (bytesCodec) Marshal(v interface{}) ([]byte, error) {
+	return *v.(*[]byte), nil //nolint:forcetypeassert
+}
+
+func (bytesCodec) Unmarshal(data []byte, v interface{}) error {
+	*v.(*[]byte) = append([]byte(nil), data...) //nolint:forcetypeassert
+
+	return nil
+}
+
+func (bytesCodec) Name() string {
+	return "proto"
+}
+
+func TestAnyEnvelope(t *testing.T) {
+	const ping = "/talos.testproto.TestService/Ping"
+
+	for _, tt := range []struct {
+		name      string
+		mode      proxy.Mode
+		typeURLs  []string
+		responses int
+	}{
+		{
+			name:     "one2one",
+			mode:     proxy.One2One,
+			typeURLs: []string{"type.googleapis.com/talos.testproto.PingResponse"},
+		},
+		{
+			name: "one2many",
+			mode: proxy.One2Many,
+			typeURLs: []string{
+				"type.googleapis.com/talos.testproto.PingResponse",
+				"type.googleapis.com/talos.testproto.MultiPingResponse",
+			},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestHarnessWithService(t, &tagEchoService{}, func(backend proxy.Backend) proxy.StreamDirector {
+				return func(ctx context.Context, fullMethodName string) (proxy.Mode, []proxy.Backend, error) {
+					backends := []proxy.Backend{
+						proxy.BackendWithPriority(&taggedBackend{Backend: backend, tag: "a"}, 0),
+						proxy.BackendWithPriority(proxy.BackendWithResponseType(&taggedBackend{Backend: backend, tag: "b"}, "talos.testproto.MultiPingResponse"), 1),
+					}
+
+					if tt.mode == proxy.One2One {
+						backends = backends[:1]
+					}
+
+					return tt.mode, backends, nil
+				}
+			}, proxy.WithAnyEnvelope(ping))
+
+			request, err := anypb.New(&pb.PingRequest{Value: "foo"})
+			require.NoError(t, err)
+
+			in, err := proto.Marshal(request)
+			require.NoError(t, err)
+
+			var out []byte
+
+			require.NoError(t, h.clientConn.Invoke(testContext(t), ping, &in, &out, grpc.ForceCodec(bytesCodec{})))
+
+			responses, err := proxy.DecodeAnyEnvelope(out)
+			require.NoError(t, err)
+			require.Len(t, responses, len(tt.typeURLs))
+
+			for i, response := range responses {
+				assert.Equal(t, tt.typeURLs[i], response.TypeUrl)
+
+				// both backends respond with PingResponse in the test
+				var resp pb.PingResponse
+
+				require.NoError(t, proto.Unmarshal(response.Value, &resp))
+				assert.Equal(t, string(rune('a'+i)), resp.Value)
+			}
+		})
+	}
+}
+
+func TestAnyEnvelopeUnpack(t *testing.T) {
+	const ping = "/talos.testproto.TestService/Ping"
+
+	h := newTestHarnessWithService(t, &badTagService{}, func(backend proxy.Backend) proxy.StreamDirector {
+		return func(ctx context.Context, fullMethodName string) (proxy.Mode, []proxy.Backend, error) {
+			return proxy.One2Many, []proxy.Backend{
+				proxy.BackendWithPriority(&taggedBackend{Backend: backend, tag: "a"}, 0),
+				proxy.BackendWithPriority(&taggedBackend{Backend: backend, tag: "bad1"}, 1),
+				proxy.BackendWithPriority(&taggedBackend{Backend: backend, tag: "b"}, 2),
+			}, nil
+		}
+	}, proxy.WithAnyEnvelope(ping))
+
+	request, err := anypb.New(&pb.PingRequest{Value: "foo"})
+	require.NoError(t, err)
+
+	in, err := proto.Marshal(request)
+	require.NoError(t, err)
+
+	var out []byte
+
+	require.NoError(t, h.clientConn.Invoke(testContext(t), ping, &in, &out, grpc.ForceCodec(bytesCodec{})))
+
+	responses, backendErrors, err := envelope.Unpack[pb.PingResponse](out)
+	require.NoError(t, err)
+
+	require.Len(t, responses, 2)
+	assert.Equal(t, "foo", responses[0].Value)
+	assert.Equal(t, "foo", responses[1].Value)
+
+	require.Len(t, backendErrors, 1)
+	assert.Equal(t, "bad1", backendErrors[0].Backend)
+	assert.Equal(t, codes.Unavailable, status.Code(backendErrors[0]))
+	assert.Equal(t, "backend is bad", backendErrors[0].Status.Message())
+
+	// the proxy-originated errors are not confused with the backend errors
+	_, ok := proxy.FromError(backendErrors[0])
+	assert.False(t, ok)
+
+	_, _, err = envelope.Unpack[pb.MultiPingResponse](out)
+	assert.ErrorContains(t, err, "unexpected type talos.testproto.PingResponse of response 0 of envelope, expected talos.testproto.MultiPingResponse")
+}
+
+func TestAnyEnvelopeUnexpectedRequestType(t *testing.T) {
+	const ping = "/talos.testproto.TestService/Ping"
+
+	h := newTestHarnessWithService(t, &tagEchoService{}, one2oneDirector, proxy.WithAnyEnvelope(ping))
+
+	for _, request := range []*anypb.Any{
+		{TypeUrl: "type.googleapis.com/talos.testproto.Empty"},
+		{},
+	} {
+		in, err := proto.Marshal(request)
+		require.NoError(t, err)
+
+		var out []byte
+
+		err = h.clientConn.Invoke(testContext(t), ping, &in, &out, grpc.ForceCodec(bytesCodec{}))
+		require.Error(t, err)
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	}
+}
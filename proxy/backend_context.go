@@ -0,0 +1,44 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy
+
+import (
+	"context"
+)
+
+// ContextBackend is an optional interface implemented by backends which build the responses depending on the call,
+// e.g. on the tenant in the metadata of the call or on its deadline.
+//
+// If the backend implements ContextBackend, AppendInfoContext and BuildErrorContext are called instead of
+// Backend.AppendInfo and Backend.BuildError with the context of the incoming call and the full method name.
+//
+// The backend wrappers which override AppendInfo or BuildError should implement ContextBackend as well, otherwise
+// the wrapped ContextBackend is called bypassing the wrapper.
+type ContextBackend interface {
+	Backend
+
+	// AppendInfoContext is Backend.AppendInfo with the context of the incoming call.
+	AppendInfoContext(ctx context.Context, fullMethodName string, streaming bool, resp []byte) ([]byte, error)
+
+	// BuildErrorContext is Backend.BuildError with the context of the incoming call.
+	BuildErrorContext(ctx context.Context, fullMethodName string, streaming bool, err error) ([]byte, error)
+}
+
+// appendInfo calls AppendInfoContext of the backend if implemented, AppendInfo otherwise.
+func appendInfo(ctx context.Context, backend Backend, fullMethodName string, streaming bool, resp []byte) ([]byte, error) {
+	if cb, ok := backendAs[ContextBackend](backend); ok {
+		return cb.AppendInfoContext(ctx, fullMethodName, streaming, resp)
+	}
+
+	return backend.AppendInfo(streaming, resp)
+}
+
+// buildError calls BuildErrorContext of the backend if implemented, BuildError otherwise.
+func buildError(ctx context.Context, backend Backend, fullMethodName string, streaming bool, err error) ([]byte, error) {
+	if cb, ok := backendAs[ContextBackend](backend); ok {
+		return cb.BuildErrorContext(ctx, fullMethodName, streaming, err)
+	}
+
+	return backend.BuildError(streaming, err)
+}
@@ -0,0 +1,69 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/noncepad/grpc-proxy/proxy"
+	pb "github.com/noncepad/grpc-proxy/testservice"
+)
+
+// tenantBackend responds with the tenant of the call instead of the response.
+type tenantBackend struct {
+	taggedBackend
+}
+
+func tenantValue(ctx context.Context, value string) []byte {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	return protowire.AppendString(protowire.AppendTag(nil, 1, protowire.BytesType), value+"@"+md.Get("x-tenant")[0])
+}
+
+func (b *tenantBackend) AppendInfoContext(ctx context.Context, fullMethodName string, streaming bool, resp []byte) ([]byte, error) {
+	return tenantValue(ctx, b.tag+" "+fullMethodName), nil
+}
+
+func (b *tenantBackend) BuildErrorContext(ctx context.Context, fullMethodName string, streaming bool, err error) ([]byte, error) {
+	return tenantValue(ctx, b.tag+" "+status.Convert(err).Message()), nil
+}
+
+func TestContextBackend(t *testing.T) {
+	const ping = "/talos.testproto.TestService/Ping"
+
+	h := newTestHarnessWithService(t, &tagValueService{}, func(backend proxy.Backend) proxy.StreamDirector {
+		return func(ctx context.Context, fullMethodName string) (proxy.Mode, []proxy.Backend, error) {
+			return proxy.One2Many, []proxy.Backend{
+				// the context backend is found through the wrappers
+				proxy.BackendWithPriority(&tenantBackend{taggedBackend{Backend: backend, tag: "good"}}, 0),
+				proxy.BackendWithPriority(&tenantBackend{taggedBackend{Backend: backend, tag: "bad"}}, 1),
+				proxy.BackendWithPriority(&taggedBackend{Backend: backend, tag: "plain"}, 2),
+			}, nil
+		}
+	})
+
+	in, err := proto.Marshal(&pb.PingRequest{Value: "ping"})
+	require.NoError(t, err)
+
+	var out []byte
+
+	ctx := metadata.AppendToOutgoingContext(testContext(t), "x-tenant", "acme")
+
+	require.NoError(t, h.clientConn.Invoke(ctx, ping, &in, &out, grpc.ForceCodec(bytesCodec{})))
+
+	assert.Equal(t, []string{
+		"good " + ping + "@acme",
+		"bad backend is bad@acme",
+		"plain",
+	}, pingValues(t, out))
+}
@@ -0,0 +1,172 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+// BackendGroup is a set of backends which can be updated while the calls are being proxied.
+//
+// Membership updates are atomic: readers always observe a consistent snapshot of the group, and the snapshot
+// is never modified after it was returned, so the director can use it without any locking.
+// Backends are identified by their String() representation.
+type BackendGroup struct {
+	snapshot atomic.Pointer[BackendGroupSnapshot]
+
+	watchers map[chan *BackendGroupSnapshot]struct{}
+
+	mu sync.Mutex
+}
+
+// BackendGroupSnapshot is an immutable view of the BackendGroup membership.
+type BackendGroupSnapshot struct {
+	// Backends in the order they were added, should not be modified.
+	Backends []Backend
+	// Version is incremented on each membership change.
+	Version uint64
+}
+
+// NewBackendGroup creates a new BackendGroup with the initial list of backends.
+func NewBackendGroup(backends ...Backend) *BackendGroup {
+	g := &BackendGroup{
+		watchers: map[chan *BackendGroupSnapshot]struct{}{},
+	}
+
+	g.snapshot.Store(&BackendGroupSnapshot{Backends: dedupBackends(nil, backends)})
+
+	return g
+}
+
+// Snapshot returns current membership of the group.
+func (g *BackendGroup) Snapshot() *BackendGroupSnapshot {
+	return g.snapshot.Load()
+}
+
+// Backends returns current list of backends, the list should not be modified.
+func (g *BackendGroup) Backends() []Backend {
+	return g.Snapshot().Backends
+}
+
+// Add adds backends to the group, backends which are already in the group are skipped.
+func (g *BackendGroup) Add(backends ...Backend) {
+	g.update(func(current []Backend) ([]Backend, bool) {
+		result := dedupBackends(current, backends)
+
+		return result, len(result) != len(current)
+	})
+}
+
+// Remove removes backends with the given names from the group.
+func (g *BackendGroup) Remove(names ...string) {
+	removed := make(map[string]struct{}, len(names))
+
+	for _, name := range names {
+		removed[name] = struct{}{}
+	}
+
+	g.update(func(current []Backend) ([]Backend, bool) {
+		result := make([]Backend, 0, len(current))
+
+		for _, backend := range current {
+			if _, ok := removed[backend.String()]; !ok {
+				result = append(result, backend)
+			}
+		}
+
+		return result, len(result) != len(current)
+	})
+}
+
+// Replace replaces the group membership.
+func (g *BackendGroup) Replace(backends ...Backend) {
+	g.update(func([]Backend) ([]Backend, bool) {
+		return dedupBackends(nil, backends), true
+	})
+}
+
+// Watch returns a channel which receives a snapshot on each membership change.
+//
+// Slow readers receive only the latest snapshot. The channel is closed when the context is canceled.
+func (g *BackendGroup) Watch(ctx context.Context) <-chan *BackendGroupSnapshot {
+	ch := make(chan *BackendGroupSnapshot, 1)
+
+	g.mu.Lock()
+	g.watchers[ch] = struct{}{}
+	g.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+
+		g.mu.Lock()
+		delete(g.watchers, ch)
+		close(ch)
+		g.mu.Unlock()
+	}()
+
+	return ch
+}
+
+// Director returns a StreamDirector which proxies all calls to the current members of the group.
+func (g *BackendGroup) Director(mode Mode) StreamDirector {
+	return func(ctx context.Context, fullMethodName string) (Mode, []Backend, error) {
+		backends := g.Backends()
+
+		if len(backends) == 0 {
+			return mode, nil, newError(ErrNoBackends, "no backends in the group for %s", fullMethodName)
+		}
+
+		return mode, backends, nil
+	}
+}
+
+func (g *BackendGroup) update(f func([]Backend) ([]Backend, bool)) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	current := g.snapshot.Load()
+
+	backends, changed := f(current.Backends)
+	if !changed {
+		return
+	}
+
+	next := &BackendGroupSnapshot{
+		Backends: backends,
+		Version:  current.Version + 1,
+	}
+
+	g.snapshot.Store(next)
+
+	for ch := range g.watchers {
+		// drop the stale snapshot, if any
+		select {
+		case <-ch:
+		default:
+		}
+
+		ch <- next
+	}
+}
+
+// dedupBackends appends backends which are not in the list yet.
+func dedupBackends(current, backends []Backend) []Backend {
+	seen := make(map[string]struct{}, len(current)+len(backends))
+	result := make([]Backend, 0, len(current)+len(backends))
+
+	for _, list := range [][]Backend{current, backends} {
+		for _, backend := range list {
+			if _, ok := seen[backend.String()]; ok {
+				continue
+			}
+
+			seen[backend.String()] = struct{}{}
+			result = append(result, backend)
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,115 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/noncepad/grpc-proxy/proxy"
+	pb "github.com/noncepad/grpc-proxy/testservice"
+)
+
+func backendNames(backends []proxy.Backend) []string {
+	names := make([]string, 0, len(backends))
+
+	for _, backend := range backends {
+		names = append(names, backend.String())
+	}
+
+	return names
+}
+
+func TestBackendGroup(t *testing.T) {
+	group := proxy.NewBackendGroup(&taggedBackend{tag: "a"}, &taggedBackend{tag: "b"}, &taggedBackend{tag: "a"})
+	assert.Equal(t, []string{"a", "b"}, backendNames(group.Backends()))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	watch := group.Watch(ctx)
+
+	snapshot := group.Snapshot()
+
+	group.Add(&taggedBackend{tag: "c"}, &taggedBackend{tag: "b"})
+	assert.Equal(t, []string{"a", "b", "c"}, backendNames(group.Backends()))
+
+	// snapshots are immutable
+	assert.Equal(t, []string{"a", "b"}, backendNames(snapshot.Backends))
+
+	group.Remove("a", "unknown")
+	assert.Equal(t, []string{"b", "c"}, backendNames(group.Backends()))
+
+	// no-op updates are not published
+	group.Remove("a")
+	group.Add(&taggedBackend{tag: "c"})
+
+	latest := <-watch
+	assert.Equal(t, []string{"b", "c"}, backendNames(latest.Backends))
+	assert.EqualValues(t, 2, latest.Version)
+
+	group.Replace(&taggedBackend{tag: "d"})
+
+	latest = <-watch
+	assert.Equal(t, []string{"d"}, backendNames(latest.Backends))
+
+	cancel()
+
+	for range watch { //nolint:revive
+	}
+}
+
+func TestBackendGroupConcurrentDispatch(t *testing.T) {
+	var (
+		group   *proxy.BackendGroup
+		backend proxy.Backend
+	)
+
+	h := newTestHarness(t, func(b proxy.Backend) proxy.StreamDirector {
+		backend = b
+		group = proxy.NewBackendGroup(backend)
+
+		return group.Director(proxy.One2One)
+	})
+
+	var wg sync.WaitGroup
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	wg.Add(1)
+
+	go func() {
+		defer wg.Done()
+
+		for i := 0; ctx.Err() == nil; i++ {
+			group.Add(&taggedBackend{Backend: backend, tag: fmt.Sprint(i)})
+			group.Remove(fmt.Sprint(i))
+		}
+	}()
+
+	for i := 0; i < 20; i++ {
+		_, err := h.client.Ping(testContext(t), &pb.PingRequest{Value: "foo"})
+		if err != nil {
+			// call might be proxied in one2one mode with two backends while the other one is being added
+			assert.Contains(t, err.Error(), "one2one proxying should have exactly one connection")
+		}
+	}
+
+	cancel()
+	wg.Wait()
+
+	group.Replace()
+
+	_, err := h.client.Ping(testContext(t), &pb.PingRequest{Value: "foo"})
+	require.Error(t, err)
+
+	proxyErr, ok := proxy.FromError(err)
+	require.True(t, ok)
+	assert.True(t, errors.Is(proxyErr, proxy.ErrNoBackends))
+}
@@ -0,0 +1,67 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy
+
+import (
+	"google.golang.org/grpc/metadata"
+)
+
+// MetadataDelta describes changes to the outgoing metadata of the upstream call.
+//
+// Keys in Remove are deleted first, then the pairs from Add are appended, so listing the key in both
+// replaces its values.
+type MetadataDelta struct {
+	Add    metadata.MD
+	Remove []string
+}
+
+// MetadataBackend is an optional interface implemented by backends to augment the outgoing metadata
+// of the upstream call.
+type MetadataBackend interface {
+	Backend
+
+	// MetadataDelta returns the changes to apply to the outgoing metadata of the upstream call for the method.
+	MetadataDelta(fullMethodName string) MetadataDelta
+}
+
+// BackendWithMetadata wraps the backend to apply the metadata delta to each upstream call.
+//
+// It allows the director to send different headers (e.g. shard hints) to different upstreams of the same
+// one2many call without a custom Backend type for each variation.
+func BackendWithMetadata(backend Backend, delta MetadataDelta) MetadataBackend {
+	return &metadataBackend{Backend: backend, delta: delta}
+}
+
+type metadataBackend struct {
+	Backend
+
+	delta MetadataDelta
+}
+
+func (b *metadataBackend) MetadataDelta(string) MetadataDelta {
+	return b.delta
+}
+
+// Unwrap returns the wrapped backend.
+func (b *metadataBackend) Unwrap() Backend {
+	return b.Backend
+}
+
+// applyMetadataDelta applies the backend metadata delta to the outgoing metadata.
+func applyMetadataDelta(md *outgoingMetadata, backend Backend, fullMethodName string) {
+	mb, ok := backendAs[MetadataBackend](backend)
+	if !ok {
+		return
+	}
+
+	delta := mb.MetadataDelta(fullMethodName)
+
+	for _, key := range delta.Remove {
+		md.delete(key)
+	}
+
+	for key, values := range delta.Add {
+		md.append(key, values...)
+	}
+}
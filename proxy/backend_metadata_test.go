@@ -0,0 +1,195 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/noncepad/grpc-proxy/proxy"
+	pb "github.com/noncepad/grpc-proxy/testservice"
+)
+
+// metadataEchoService responds to PingStream with the incoming metadata keys listed in the request.
+type metadataEchoService struct {
+	assertingService
+}
+
+func (s *metadataEchoService) PingStream(stream pb.TestService_PingStreamServer) error {
+	md, _ := metadata.FromIncomingContext(stream.Context())
+
+	for {
+		ping, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		var values []string
+
+		for _, key := range strings.Split(ping.Value, ",") {
+			values = append(values, key+"="+strings.Join(md.Get(key), "|"))
+		}
+
+		if err = stream.Send(&pb.PingResponse{Value: strings.Join(values, ",")}); err != nil {
+			return err
+		}
+	}
+}
+
+func TestBackendWithMetadata(t *testing.T) {
+	h := newTestHarnessWithService(t, &metadataEchoService{}, func(backend proxy.Backend) proxy.StreamDirector {
+		return func(ctx context.Context, fullMethodName string) (proxy.Mode, []proxy.Backend, error) {
+			return proxy.One2Many, []proxy.Backend{
+				proxy.BackendWithMetadata(&taggedBackend{Backend: backend, tag: "a"}, proxy.MetadataDelta{
+					Add: metadata.Pairs("shard-hint", "1", "shard-hint", "2"),
+				}),
+				proxy.BackendWithMetadata(&taggedBackend{Backend: backend, tag: "b"}, proxy.MetadataDelta{
+					Add:    metadata.Pairs("shard-hint", "3", backendTagMdKey, "c"),
+					Remove: []string{clientMdKey, backendTagMdKey},
+				}),
+			}, nil
+		}
+	}, proxy.WithStreamedDetector(func(fullMethodName string) bool { return fullMethodName == "/talos.testproto.TestService/PingStream" }))
+
+	stream, err := h.client.PingStream(testContext(t))
+	require.NoError(t, err)
+
+	require.NoError(t, stream.Send(&pb.PingRequest{Value: strings.Join([]string{backendTagMdKey, "shard-hint", clientMdKey}, ",")}))
+	require.NoError(t, stream.CloseSend())
+
+	var responses []string
+
+	for {
+		resp, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		require.NoError(t, err)
+
+		responses = append(responses, resp.Value)
+	}
+
+	assert.ElementsMatch(t, []string{
+		backendTagMdKey + "=a,shard-hint=1|2," + clientMdKey + "=true",
+		backendTagMdKey + "=c,shard-hint=3," + clientMdKey + "=",
+	}, responses)
+}
+
+// sharedMetadataBackend attaches the same outgoing metadata to all the upstream calls.
+type sharedMetadataBackend struct {
+	proxy.Backend
+
+	md metadata.MD
+}
+
+func (b *sharedMetadataBackend) GetConnection(ctx context.Context, fullMethodName string) (context.Context, *grpc.ClientConn, error) {
+	_, conn, err := b.Backend.GetConnection(ctx, fullMethodName)
+
+	return metadata.NewOutgoingContext(ctx, b.md), conn, err
+}
+
+func TestBackendWithMetadataShared(t *testing.T) {
+	hints := make([]string, 1, 8)
+	hints[0] = "0"
+
+	shared := &sharedMetadataBackend{md: metadata.MD{"shard-hint": hints, clientMdKey: []string{"true"}}}
+
+	h := newTestHarnessWithService(t, &metadataEchoService{}, func(backend proxy.Backend) proxy.StreamDirector {
+		shared.Backend = backend
+
+		return func(ctx context.Context, fullMethodName string) (proxy.Mode, []proxy.Backend, error) {
+			return proxy.One2Many, []proxy.Backend{
+				proxy.BackendWithMetadata(shared, proxy.MetadataDelta{Add: metadata.Pairs("shard-hint", "1")}),
+				proxy.BackendWithMetadata(shared, proxy.MetadataDelta{Add: metadata.Pairs("shard-hint", "2")}),
+				proxy.BackendWithMetadata(shared, proxy.MetadataDelta{Remove: []string{"shard-hint"}}),
+			}, nil
+		}
+	}, proxy.WithStreamedDetector(func(fullMethodName string) bool { return fullMethodName == "/talos.testproto.TestService/PingStream" }))
+
+	for i := 0; i < 3; i++ {
+		stream, err := h.client.PingStream(testContext(t))
+		require.NoError(t, err)
+
+		require.NoError(t, stream.Send(&pb.PingRequest{Value: "shard-hint"}))
+		require.NoError(t, stream.CloseSend())
+
+		var responses []string
+
+		for {
+			resp, err := stream.Recv()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+
+			require.NoError(t, err)
+
+			responses = append(responses, resp.Value)
+		}
+
+		assert.ElementsMatch(t, []string{"shard-hint=0|1", "shard-hint=0|2", "shard-hint="}, responses)
+	}
+
+	// the shared metadata is never modified
+	assert.Equal(t, metadata.MD{"shard-hint": {"0"}, clientMdKey: {"true"}}, shared.md)
+	assert.Equal(t, []string{"0", ""}, hints[:2])
+}
+
+func BenchmarkOutgoingMetadata(b *testing.B) {
+	md := metadata.Pairs(clientMdKey, "true")
+
+	for i := 0; i < 16; i++ {
+		md.Append(fmt.Sprintf("x-header-%d", i), strings.Repeat("v", 64))
+	}
+
+	for _, bb := range []struct {
+		name    string
+		options []proxy.Option
+		delta   *proxy.MetadataDelta
+	}{
+		{name: "unchanged"},
+		{name: "delta", delta: &proxy.MetadataDelta{Add: metadata.Pairs("shard-hint", "1")}},
+		{
+			name: "loop detection and identity",
+			options: []proxy.Option{
+				proxy.WithLoopDetection("proxy-1", 8),
+				proxy.WithProxyIdentity(proxy.ProxyIdentity{Name: "proxy", Version: "1.0", InstanceID: "1"}),
+			},
+		},
+	} {
+		b.Run(bb.name, func(b *testing.B) {
+			h := newTestHarnessWithService(b, &lenientService{}, func(backend proxy.Backend) proxy.StreamDirector {
+				if bb.delta != nil {
+					backend = proxy.BackendWithMetadata(backend, *bb.delta)
+				}
+
+				return one2oneDirector(backend)
+			}, append(bb.options, proxy.WithUnaryFastPath())...)
+
+			ctx := metadata.NewOutgoingContext(testContext(b), md)
+
+			b.ReportAllocs()
+			b.ResetTimer()
+
+			for i := 0; i < b.N; i++ {
+				if _, err := h.client.PingEmpty(ctx, &pb.Empty{}); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
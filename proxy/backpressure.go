@@ -0,0 +1,232 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy
+
+import (
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+// BackpressureMetadataKey is the request metadata key advertising the backpressure semantics to the backends:
+// BackpressurePause or BackpressureSignal.
+const BackpressureMetadataKey = "proxy-backpressure"
+
+// Backpressure semantics advertised to the backends.
+const (
+	// BackpressurePause means the proxy stops receiving the responses while the client is slow to read them,
+	// so the backend Send blocks on the HTTP/2 flow control until the client catches up.
+	BackpressurePause = "pause"
+	// BackpressureSignal means BackpressurePause, and the proxy also sends the signal messages to the backend
+	// when the client becomes slow and when it catches up.
+	BackpressureSignal = "signal"
+)
+
+// defaultSlowReaderThreshold is the default BackpressurePolicy.SlowThreshold.
+const defaultSlowReaderThreshold = 100 * time.Millisecond
+
+// BackpressurePolicy configures how the slow reader condition of the client is conveyed to the backends.
+type BackpressurePolicy struct {
+	// SlowThreshold is the time the response waits to be accepted by the client before the client is considered
+	// the slow reader (default 100ms).
+	SlowThreshold time.Duration
+	// Signal returns the serialized request message sent to the backends when the client becomes slow (slow is true)
+	// and when it catches up (slow is false), if set. The nil message is not sent.
+	//
+	// The signals are only sent until the client half-closes the stream, so they are only useful
+	// for the bidirectional streams, and the backends must recognize the signal messages among the client requests.
+	Signal func(fullMethodName string, slow bool) []byte
+}
+
+// WithBackpressure conveys the slow reader condition of the client to the backends of the listed streaming
+// methods, so that the producers can adapt their send rate instead of the responses being buffered, or the call being
+// failed on a timeout.
+//
+// The proxy never reads ahead of the client: while the response is waiting to be accepted by the client, no more
+// responses are received from the backends, so the backends observe the HTTP/2 flow control (Send blocks)
+// once the connection windows are full. The semantics is advertised to the backends with BackpressureMetadataKey,
+// and with policy.Signal set, the explicit signal messages are sent to the backends as well.
+// If fullMethodNames is empty, the policy is applied to all methods without a method-specific policy.
+//
+// The options buffering the responses (e.g. WithFrameWindowing, WithResponseVerifier) delay the backpressure
+// by the size of their buffers.
+func WithBackpressure(policy BackpressurePolicy, fullMethodNames ...string) Option {
+	if policy.SlowThreshold == 0 {
+		policy.SlowThreshold = defaultSlowReaderThreshold
+	}
+
+	return func(o *handlerOptions) {
+		if policy.SlowThreshold < 0 {
+			o.invalid("backpressure slow threshold should not be negative")
+		}
+
+		if o.backpressure == nil {
+			o.backpressure = map[string]BackpressurePolicy{}
+		}
+
+		if len(fullMethodNames) == 0 {
+			o.backpressure[""] = policy
+
+			return
+		}
+
+		for _, name := range fullMethodNames {
+			o.backpressure[name] = policy
+		}
+	}
+}
+
+// backpressurePolicy returns the policy of the method.
+func (o *handlerOptions) backpressurePolicy(fullMethodName string) (BackpressurePolicy, bool) {
+	if policy, ok := o.backpressure[fullMethodName]; ok {
+		return policy, true
+	}
+
+	policy, ok := o.backpressure[""]
+
+	return policy, ok
+}
+
+// backpressureOutgoingMetadata advertises the backpressure semantics of the streaming calls to the backends.
+func (o *handlerOptions) backpressureOutgoingMetadata(fullMethodName string, md *outgoingMetadata) {
+	policy, ok := o.backpressurePolicy(fullMethodName)
+	if !ok {
+		return
+	}
+
+	if policy.Signal != nil {
+		md.set(BackpressureMetadataKey, BackpressureSignal)
+	} else {
+		md.set(BackpressureMetadataKey, BackpressurePause)
+	}
+}
+
+// wrapBackpressure detects the slow reader of the responses and signals the backends, if configured.
+func (o *handlerOptions) wrapBackpressure(fullMethodName string, serverStream grpc.ServerStream, backendConnections []backendConnection) grpc.ServerStream {
+	policy, ok := o.backpressurePolicy(fullMethodName)
+	if !ok || policy.Signal == nil {
+		// pausing is the way the forwarders work anyways
+		return serverStream
+	}
+
+	s := &backpressureServerStream{
+		ServerStream: serverStream,
+		policy:       policy,
+		method:       fullMethodName,
+	}
+
+	for i := range backendConnections {
+		if backendConnections[i].connError != nil {
+			continue
+		}
+
+		upstream := &signalingClientStream{ClientStream: backendConnections[i].clientStream}
+		backendConnections[i].clientStream = upstream
+		s.upstreams = append(s.upstreams, upstream)
+	}
+
+	return s
+}
+
+// backpressureServerStream detects the slow reader of the responses.
+type backpressureServerStream struct {
+	grpc.ServerStream
+
+	policy    BackpressurePolicy
+	method    string
+	upstreams []*signalingClientStream
+
+	mu sync.Mutex
+	// slowSends is the number of the responses waiting over the threshold
+	slowSends int
+}
+
+func (s *backpressureServerStream) SendMsg(m interface{}) error {
+	fired := make(chan struct{})
+	timer := time.AfterFunc(s.policy.SlowThreshold, func() {
+		defer close(fired)
+
+		s.slow(1)
+	})
+
+	err := s.ServerStream.SendMsg(m)
+
+	if !timer.Stop() {
+		<-fired
+
+		s.slow(-1)
+	}
+
+	return err
+}
+
+// slow tracks the responses waiting over the threshold, signaling the backends on the transitions.
+func (s *backpressureServerStream) slow(delta int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.slowSends += delta
+
+	switch {
+	case delta > 0 && s.slowSends == 1:
+		s.signal(true)
+	case delta < 0 && s.slowSends == 0:
+		s.signal(false)
+	}
+}
+
+func (s *backpressureServerStream) signal(slow bool) {
+	payload := s.policy.Signal(s.method, slow)
+	if payload == nil {
+		return
+	}
+
+	for _, upstream := range s.upstreams {
+		upstream.signal(payload)
+	}
+}
+
+// signalingClientStream serializes the forwarded requests and the signals sent to the backend.
+type signalingClientStream struct {
+	grpc.ClientStream
+
+	mu sync.Mutex
+	// done is set once the requests are finished, no signals are sent afterwards
+	done bool
+}
+
+func (s *signalingClientStream) SendMsg(m interface{}) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	err := s.ClientStream.SendMsg(m)
+	if err != nil {
+		s.done = true
+	}
+
+	return err
+}
+
+func (s *signalingClientStream) CloseSend() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.done = true
+
+	return s.ClientStream.CloseSend()
+}
+
+func (s *signalingClientStream) signal(payload []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.done {
+		return
+	}
+
+	if err := s.ClientStream.SendMsg(NewFrame(payload)); err != nil {
+		s.done = true
+	}
+}
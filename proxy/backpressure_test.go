@@ -0,0 +1,133 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/noncepad/grpc-proxy/proxy"
+	pb "github.com/noncepad/grpc-proxy/testservice"
+)
+
+// producerService streams the responses until the client catches up after being slow.
+type producerService struct {
+	assertingService
+
+	semantics chan string
+}
+
+func (s *producerService) PingStream(stream pb.TestService_PingStreamServer) error {
+	md, _ := metadata.FromIncomingContext(stream.Context())
+	s.semantics <- strings.Join(md.Get(proxy.BackpressureMetadataKey), ",")
+
+	signals := make(chan string, 16)
+
+	go func() {
+		defer close(signals)
+
+		for {
+			ping, err := stream.Recv()
+			if err != nil {
+				return
+			}
+
+			signals <- ping.Value
+		}
+	}()
+
+	<-signals // start
+
+	payload := strings.Repeat("x", 32*1024)
+
+	var received []string
+
+	for {
+		select {
+		case signal := <-signals:
+			received = append(received, signal)
+
+			if signal == "fast" {
+				// the last response carries the signals observed
+				return stream.Send(&pb.PingResponse{Value: strings.Join(received, ",")})
+			}
+		default:
+		}
+
+		if err := stream.Send(&pb.PingResponse{Value: payload}); err != nil {
+			return err
+		}
+	}
+}
+
+func TestBackpressureSignal(t *testing.T) {
+	service := &producerService{semantics: make(chan string, 1)}
+
+	h := newTestHarnessWithService(t, service, one2oneDirector, proxy.WithBackpressure(proxy.BackpressurePolicy{
+		SlowThreshold: 50 * time.Millisecond,
+		Signal: func(fullMethodName string, slow bool) []byte {
+			value := "fast"
+			if slow {
+				value = "slow"
+			}
+
+			payload, err := proto.Marshal(&pb.PingRequest{Value: value})
+			require.NoError(t, err)
+
+			return payload
+		},
+	}, "/talos.testproto.TestService/PingStream"))
+
+	ctx, cancel := context.WithTimeout(testContext(t), 10*time.Second)
+	defer cancel()
+
+	stream, err := h.client.PingStream(ctx)
+	require.NoError(t, err)
+
+	require.NoError(t, stream.Send(&pb.PingRequest{Value: "start"}))
+
+	assert.Equal(t, proxy.BackpressureSignal, <-service.semantics)
+
+	// the slow reader
+	time.Sleep(500 * time.Millisecond)
+
+	var last string
+
+	for {
+		out, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		require.NoError(t, err)
+
+		last = out.Value
+	}
+
+	assert.Equal(t, "slow,fast", last)
+}
+
+func TestBackpressurePause(t *testing.T) {
+	h := newTestHarnessWithService(t, &metadataEchoService{}, one2oneDirector, proxy.WithBackpressure(proxy.BackpressurePolicy{}))
+
+	stream, err := h.client.PingStream(testContext(t))
+	require.NoError(t, err)
+
+	require.NoError(t, stream.Send(&pb.PingRequest{Value: proxy.BackpressureMetadataKey}))
+
+	out, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, proxy.BackpressureMetadataKey+"="+proxy.BackpressurePause, out.Value)
+
+	require.NoError(t, stream.CloseSend())
+}
@@ -0,0 +1,352 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+// FrameSizeBuckets are the upper bounds (inclusive) of the frame size histogram buckets.
+//
+// The last bucket of the histogram counts frames larger than the last bound.
+var FrameSizeBuckets = []int{64, 256, 1 << 10, 4 << 10, 16 << 10, 64 << 10, 256 << 10, 1 << 20, 4 << 20, 16 << 20}
+
+// FrameSizeHistogram is a histogram of frame sizes, see FrameSizeBuckets.
+type FrameSizeHistogram [11]uint64
+
+type frameSizeHistogram [11]uint64
+
+func (h *frameSizeHistogram) observe(size int) {
+	bucket := sort.SearchInts(FrameSizeBuckets, size)
+
+	atomic.AddUint64(&h[bucket], 1)
+}
+
+func (h *frameSizeHistogram) snapshot() FrameSizeHistogram {
+	var result FrameSizeHistogram
+
+	for i := range h {
+		result[i] = atomic.LoadUint64(&h[i])
+	}
+
+	return result
+}
+
+// bandwidthCounter counts bytes and frames in both directions.
+type bandwidthCounter struct {
+	inBytes   uint64
+	outBytes  uint64
+	inFrames  frameSizeHistogram
+	outFrames frameSizeHistogram
+}
+
+func (c *bandwidthCounter) in(size int) {
+	atomic.AddUint64(&c.inBytes, uint64(size))
+	c.inFrames.observe(size)
+}
+
+func (c *bandwidthCounter) out(size int) {
+	atomic.AddUint64(&c.outBytes, uint64(size))
+	c.outFrames.observe(size)
+}
+
+// BandwidthCounters is a snapshot of bandwidth counters.
+//
+// For methods, "in" is the direction from the client to the proxy, and "out" from the proxy to the client.
+// For backends, "in" is the direction from the backend to the proxy, and "out" from the proxy to the backend.
+type BandwidthCounters struct {
+	InBytes   uint64             `json:"in_bytes"`
+	OutBytes  uint64             `json:"out_bytes"`
+	InFrames  FrameSizeHistogram `json:"in_frames"`
+	OutFrames FrameSizeHistogram `json:"out_frames"`
+}
+
+func (c *bandwidthCounter) snapshot() BandwidthCounters {
+	return BandwidthCounters{
+		InBytes:   atomic.LoadUint64(&c.inBytes),
+		OutBytes:  atomic.LoadUint64(&c.outBytes),
+		InFrames:  c.inFrames.snapshot(),
+		OutFrames: c.outFrames.snapshot(),
+	}
+}
+
+// BandwidthSnapshot is a snapshot of all bandwidth counters.
+type BandwidthSnapshot struct {
+	Methods  map[string]BandwidthCounters `json:"methods"`
+	Backends map[string]BandwidthCounters `json:"backends"`
+	// Routes is the number of upstream calls per method and backend.
+	Routes map[string]map[string]uint64 `json:"routes"`
+}
+
+// MethodBandwidth is the bandwidth of the method over the report interval.
+type MethodBandwidth struct {
+	Method string `json:"method"`
+	// Bytes transferred in both directions over the interval.
+	Bytes uint64 `json:"bytes"`
+	// BytesPerSecond is the average rate over the interval.
+	BytesPerSecond float64 `json:"bytes_per_second"`
+}
+
+// BandwidthReport is a periodically computed top-N methods by bandwidth.
+type BandwidthReport struct {
+	Timestamp time.Time         `json:"timestamp"`
+	Interval  time.Duration     `json:"interval"`
+	Top       []MethodBandwidth `json:"top"`
+}
+
+// BandwidthStats collects per-method and per-backend bandwidth statistics.
+//
+// BandwidthStats implements http.Handler which serves current snapshot and the last report as JSON, so that it can be
+// mounted on the admin HTTP server.
+type BandwidthStats struct {
+	methods  sync.Map // map[string]*bandwidthCounter
+	backends sync.Map // map[string]*bandwidthCounter
+	routes   sync.Map // map[routeKey]*uint64
+
+	lastTotals map[string]uint64
+	lastReport BandwidthReport
+	lastRun    time.Time
+
+	topN int
+
+	mu sync.Mutex
+}
+
+// NewBandwidthStats creates new BandwidthStats, topN is the number of methods included in the report.
+func NewBandwidthStats(topN int) *BandwidthStats {
+	return &BandwidthStats{
+		topN:       topN,
+		lastTotals: map[string]uint64{},
+		lastRun:    time.Now(),
+	}
+}
+
+// WithBandwidthStats enables collection of the bandwidth statistics.
+func WithBandwidthStats(stats *BandwidthStats) Option {
+	return func(o *handlerOptions) {
+		o.bandwidthStats = stats
+	}
+}
+
+func loadCounter(m *sync.Map, key string) *bandwidthCounter {
+	if c, ok := m.Load(key); ok {
+		return c.(*bandwidthCounter) //nolint:forcetypeassert
+	}
+
+	c, _ := m.LoadOrStore(key, &bandwidthCounter{})
+
+	return c.(*bandwidthCounter) //nolint:forcetypeassert
+}
+
+// routeKey identifies the route of the upstream call.
+type routeKey struct {
+	method  string
+	backend string
+}
+
+func loadRoute(m *sync.Map, key routeKey) *uint64 {
+	if c, ok := m.Load(key); ok {
+		return c.(*uint64) //nolint:forcetypeassert
+	}
+
+	c, _ := m.LoadOrStore(key, new(uint64))
+
+	return c.(*uint64) //nolint:forcetypeassert
+}
+
+// Snapshot returns current values of the counters.
+func (b *BandwidthStats) Snapshot() BandwidthSnapshot {
+	snapshot := BandwidthSnapshot{
+		Methods:  map[string]BandwidthCounters{},
+		Backends: map[string]BandwidthCounters{},
+		Routes:   map[string]map[string]uint64{},
+	}
+
+	b.methods.Range(func(key, value interface{}) bool {
+		snapshot.Methods[key.(string)] = value.(*bandwidthCounter).snapshot() //nolint:forcetypeassert
+
+		return true
+	})
+
+	b.backends.Range(func(key, value interface{}) bool {
+		snapshot.Backends[key.(string)] = value.(*bandwidthCounter).snapshot() //nolint:forcetypeassert
+
+		return true
+	})
+
+	b.routes.Range(func(key, value interface{}) bool {
+		route := key.(routeKey) //nolint:forcetypeassert
+
+		if snapshot.Routes[route.method] == nil {
+			snapshot.Routes[route.method] = map[string]uint64{}
+		}
+
+		snapshot.Routes[route.method][route.backend] = atomic.LoadUint64(value.(*uint64)) //nolint:forcetypeassert
+
+		return true
+	})
+
+	return snapshot
+}
+
+// Report computes top-N methods by bandwidth since the previous call to Report.
+func (b *BandwidthStats) Report() BandwidthReport {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	interval := now.Sub(b.lastRun)
+
+	var top []MethodBandwidth
+
+	b.methods.Range(func(key, value interface{}) bool {
+		method := key.(string)         //nolint:forcetypeassert
+		c := value.(*bandwidthCounter) //nolint:forcetypeassert
+
+		total := atomic.LoadUint64(&c.inBytes) + atomic.LoadUint64(&c.outBytes)
+		delta := total - b.lastTotals[method]
+		b.lastTotals[method] = total
+
+		if delta > 0 {
+			top = append(top, MethodBandwidth{
+				Method:         method,
+				Bytes:          delta,
+				BytesPerSecond: float64(delta) / interval.Seconds(),
+			})
+		}
+
+		return true
+	})
+
+	sort.Slice(top, func(i, j int) bool {
+		if top[i].Bytes != top[j].Bytes {
+			return top[i].Bytes > top[j].Bytes
+		}
+
+		return top[i].Method < top[j].Method
+	})
+
+	if b.topN > 0 && len(top) > b.topN {
+		top = top[:b.topN]
+	}
+
+	b.lastRun = now
+	b.lastReport = BandwidthReport{
+		Timestamp: now,
+		Interval:  interval,
+		Top:       top,
+	}
+
+	return b.lastReport
+}
+
+// LastReport returns the last computed report.
+func (b *BandwidthStats) LastReport() BandwidthReport {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.lastReport
+}
+
+// Run computes the report every interval until the context is canceled.
+func (b *BandwidthStats) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			b.Report()
+		}
+	}
+}
+
+// ServeHTTP implements http.Handler.
+func (b *BandwidthStats) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	json.NewEncoder(w).Encode(struct { //nolint:errcheck
+		Snapshot BandwidthSnapshot `json:"snapshot"`
+		Report   BandwidthReport   `json:"report"`
+	}{
+		Snapshot: b.Snapshot(),
+		Report:   b.LastReport(),
+	})
+}
+
+// wrapServerStream counts frames exchanged with the client.
+func (b *BandwidthStats) wrapServerStream(serverStream grpc.ServerStream, fullMethodName string) grpc.ServerStream {
+	return &countingServerStream{
+		ServerStream: serverStream,
+		counter:      loadCounter(&b.methods, fullMethodName),
+	}
+}
+
+// wrapClientStream counts the upstream call and frames exchanged with the backend.
+func (b *BandwidthStats) wrapClientStream(clientStream grpc.ClientStream, backend Backend, fullMethodName string) grpc.ClientStream {
+	atomic.AddUint64(loadRoute(&b.routes, routeKey{method: fullMethodName, backend: backend.String()}), 1)
+
+	return &countingClientStream{
+		ClientStream: clientStream,
+		counter:      loadCounter(&b.backends, backend.String()),
+	}
+}
+
+type countingServerStream struct {
+	grpc.ServerStream
+
+	counter *bandwidthCounter
+}
+
+func (s *countingServerStream) SendMsg(m interface{}) error {
+	if f, ok := m.(*Frame); ok {
+		s.counter.out(f.Size())
+	}
+
+	return s.ServerStream.SendMsg(m)
+}
+
+func (s *countingServerStream) RecvMsg(m interface{}) error {
+	err := s.ServerStream.RecvMsg(m)
+
+	if f, ok := m.(*Frame); ok && err == nil {
+		s.counter.in(f.Size())
+	}
+
+	return err
+}
+
+type countingClientStream struct {
+	grpc.ClientStream
+
+	counter *bandwidthCounter
+}
+
+func (s *countingClientStream) SendMsg(m interface{}) error {
+	if f, ok := m.(*Frame); ok {
+		s.counter.out(f.Size())
+	}
+
+	return s.ClientStream.SendMsg(m)
+}
+
+func (s *countingClientStream) RecvMsg(m interface{}) error {
+	err := s.ClientStream.RecvMsg(m)
+
+	if f, ok := m.(*Frame); ok && err == nil {
+		s.counter.in(f.Size())
+	}
+
+	return err
+}
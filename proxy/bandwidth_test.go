@@ -0,0 +1,67 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/noncepad/grpc-proxy/proxy"
+	pb "github.com/noncepad/grpc-proxy/testservice"
+)
+
+func TestBandwidthStats(t *testing.T) {
+	stats := proxy.NewBandwidthStats(1)
+
+	h := newTestHarness(t, one2oneDirector, proxy.WithBandwidthStats(stats))
+
+	for i := 0; i < 3; i++ {
+		_, err := h.client.Ping(testContext(t), &pb.PingRequest{Value: "foo"})
+		require.NoError(t, err)
+	}
+
+	_, err := h.client.PingEmpty(testContext(t), &pb.Empty{})
+	require.NoError(t, err)
+
+	snapshot := stats.Snapshot()
+
+	ping := snapshot.Methods["/talos.testproto.TestService/Ping"]
+	assert.NotZero(t, ping.InBytes)
+	assert.NotZero(t, ping.OutBytes)
+	assert.EqualValues(t, 3, ping.InFrames[0])
+	assert.EqualValues(t, 3, ping.OutFrames[0])
+
+	require.Len(t, snapshot.Backends, 1)
+
+	pingEmpty := snapshot.Methods["/talos.testproto.TestService/PingEmpty"]
+
+	for _, backend := range snapshot.Backends {
+		assert.Equal(t, ping.InBytes+pingEmpty.InBytes, backend.OutBytes)
+		assert.Equal(t, ping.OutBytes+pingEmpty.OutBytes, backend.InBytes)
+	}
+
+	report := stats.Report()
+	require.Len(t, report.Top, 1)
+	assert.Equal(t, "/talos.testproto.TestService/Ping", report.Top[0].Method)
+	assert.Equal(t, ping.InBytes+ping.OutBytes, report.Top[0].Bytes)
+
+	assert.Empty(t, stats.Report().Top)
+
+	rec := httptest.NewRecorder()
+	stats.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/bandwidth", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var body struct {
+		Snapshot proxy.BandwidthSnapshot `json:"snapshot"`
+		Report   proxy.BandwidthReport   `json:"report"`
+	}
+
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	assert.Contains(t, body.Snapshot.Methods, "/talos.testproto.TestService/Ping")
+}
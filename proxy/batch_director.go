@@ -0,0 +1,260 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// BatchDirector picks the backends for all the calls of the method with the same routing key at once.
+//
+// Unlike StreamDirector, BatchDirector is not invoked for every call: the decision is memoized by MemoizedDirector
+// per (method, routing key) pair, so the director should not depend on anything in the context other than the routing key.
+type BatchDirector interface {
+	Direct(ctx context.Context, fullMethodName, routingKey string) (Mode, []Backend, error)
+}
+
+// BatchDirectorFunc is an adapter to use functions as BatchDirector.
+type BatchDirectorFunc func(ctx context.Context, fullMethodName, routingKey string) (Mode, []Backend, error)
+
+// Direct implements BatchDirector.
+func (f BatchDirectorFunc) Direct(ctx context.Context, fullMethodName, routingKey string) (Mode, []Backend, error) {
+	return f(ctx, fullMethodName, routingKey)
+}
+
+// RoutingKeyFunc extracts the routing key of the call.
+type RoutingKeyFunc func(ctx context.Context, fullMethodName string) string
+
+// MetadataRoutingKey returns RoutingKeyFunc which uses the first value of the metadata key as the routing key.
+func MetadataRoutingKey(key string) RoutingKeyFunc {
+	return func(ctx context.Context, fullMethodName string) string {
+		md, _ := metadata.FromIncomingContext(ctx)
+
+		if values := md.Get(key); len(values) > 0 {
+			return values[0]
+		}
+
+		return ""
+	}
+}
+
+// defaultMemoMaxEntries is the default of MemoizedDirector.MaxEntries.
+const defaultMemoMaxEntries = 10000
+
+// MemoizedDirector memoizes BatchDirector decisions per (method, routing key).
+//
+// Concurrent calls with the same method and routing key wait for a single BatchDirector invocation.
+// Errors are not memoized. Lookups of the memoized decisions don't take any locks.
+//
+// The decisions expire after the TTL, and the number of the memoized decisions is bounded by MaxEntries, so that
+// the routing keys controlled by the clients can't grow the memo without a limit.
+type MemoizedDirector struct {
+	director   BatchDirector
+	routingKey RoutingKeyFunc
+	entries    atomic.Pointer[sync.Map]
+	lastSweep  time.Time
+	ttl        time.Duration
+
+	// MaxEntries limits the number of the memoized decisions (default 10000), once exceeded the decisions
+	// are evicted regardless of their TTL.
+	MaxEntries int
+
+	// mu serializes the inserts and removals of the entries
+	mu sync.Mutex
+	// count is the number of the entries, guarded by mu
+	count int
+}
+
+type memoKey struct {
+	method     string
+	routingKey string
+}
+
+type memoEntry struct {
+	done      chan struct{}
+	expiresAt time.Time
+	backends  []Backend
+	mode      Mode
+	err       error
+}
+
+// NewMemoizedDirector creates MemoizedDirector, decisions expire after ttl (zero means never, see Invalidate
+// and MaxEntries).
+func NewMemoizedDirector(director BatchDirector, routingKey RoutingKeyFunc, ttl time.Duration) *MemoizedDirector {
+	d := &MemoizedDirector{
+		director:   director,
+		routingKey: routingKey,
+		ttl:        ttl,
+	}
+
+	d.entries.Store(&sync.Map{})
+
+	return d
+}
+
+// Director returns StreamDirector to be used with the proxy handler.
+func (d *MemoizedDirector) Director() StreamDirector {
+	return d.Direct
+}
+
+// Invalidate drops all memoized decisions, e.g. after the backend membership change.
+func (d *MemoizedDirector) Invalidate() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.entries.Store(&sync.Map{})
+	d.count = 0
+}
+
+// Direct implements StreamDirector.
+func (d *MemoizedDirector) Direct(ctx context.Context, fullMethodName string) (Mode, []Backend, error) {
+	key := memoKey{method: fullMethodName, routingKey: d.routingKey(ctx, fullMethodName)}
+
+	entry, found := d.lookup(key)
+	if !found {
+		entry, found = d.insert(key)
+	}
+
+	if found {
+		select {
+		case <-entry.done:
+		case <-ctx.Done():
+			return One2One, nil, status.FromContextError(ctx.Err()).Err()
+		}
+
+		if entry.err == nil {
+			return entry.mode, entry.backends, nil
+		}
+
+		// the decision failed, make our own
+		return d.director.Direct(ctx, fullMethodName, key.routingKey)
+	}
+
+	entry.mode, entry.backends, entry.err = d.director.Direct(ctx, fullMethodName, key.routingKey)
+	entry.expiresAt = time.Now().Add(d.ttl)
+
+	if entry.err != nil {
+		d.remove(key, entry)
+	}
+
+	close(entry.done)
+
+	return entry.mode, entry.backends, entry.err
+}
+
+// lookup finds a valid entry without locking.
+func (d *MemoizedDirector) lookup(key memoKey) (*memoEntry, bool) {
+	value, ok := d.entries.Load().Load(key)
+	if !ok {
+		return nil, false
+	}
+
+	entry := value.(*memoEntry) //nolint:forcetypeassert
+
+	if !d.valid(entry, time.Now()) {
+		return nil, false
+	}
+
+	return entry, true
+}
+
+// insert either returns an entry inserted concurrently, or inserts a new pending entry.
+func (d *MemoizedDirector) insert(key memoKey) (entry *memoEntry, found bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := time.Now()
+	entries := d.entries.Load()
+
+	existing, ok := entries.Load(key)
+	if ok && d.valid(existing.(*memoEntry), now) { //nolint:forcetypeassert
+		return existing.(*memoEntry), true //nolint:forcetypeassert
+	}
+
+	entry = &memoEntry{
+		done: make(chan struct{}),
+	}
+
+	entries.Store(key, entry)
+
+	if !ok {
+		d.count++
+	}
+
+	d.sweepLocked(entries, now)
+
+	return entry, false
+}
+
+// remove removes the entry unless it was replaced concurrently.
+func (d *MemoizedDirector) remove(key memoKey, entry *memoEntry) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	entries := d.entries.Load()
+
+	if current, ok := entries.Load(key); ok && current == entry {
+		entries.Delete(key)
+		d.count--
+	}
+}
+
+// valid checks whether the entry is still valid, pending entries are always valid.
+func (d *MemoizedDirector) valid(entry *memoEntry, now time.Time) bool {
+	return !entry.finished() || d.ttl == 0 || now.Before(entry.expiresAt)
+}
+
+// sweepLocked removes the expired entries once per TTL, and evicts the finished entries once the number of
+// the entries exceeds MaxEntries, it should be called with the lock held.
+//
+// The eviction leaves the room for a quarter of MaxEntries, so that the sweeps are amortized over the inserts.
+func (d *MemoizedDirector) sweepLocked(entries *sync.Map, now time.Time) {
+	maxEntries := d.MaxEntries
+	if maxEntries <= 0 {
+		maxEntries = defaultMemoMaxEntries
+	}
+
+	expire := d.ttl > 0 && now.Sub(d.lastSweep) >= d.ttl
+	overflow := d.count > maxEntries
+
+	if !expire && !overflow {
+		return
+	}
+
+	if expire {
+		d.lastSweep = now
+	}
+
+	target := maxEntries - maxEntries/4
+
+	entries.Range(func(key, value interface{}) bool {
+		entry := value.(*memoEntry) //nolint:forcetypeassert
+
+		if !entry.finished() {
+			return true
+		}
+
+		if (expire && now.After(entry.expiresAt)) || (overflow && d.count > target) {
+			entries.Delete(key)
+			d.count--
+		}
+
+		return true
+	})
+}
+
+func (entry *memoEntry) finished() bool {
+	select {
+	case <-entry.done:
+		return true
+	default:
+		return false
+	}
+}
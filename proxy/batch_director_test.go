@@ -0,0 +1,158 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/noncepad/grpc-proxy/proxy"
+	pb "github.com/noncepad/grpc-proxy/testservice"
+)
+
+const routingMdKey = "test-routing-key"
+
+func TestMemoizedDirector(t *testing.T) {
+	var calls int32
+
+	var backend proxy.Backend
+
+	memoized := proxy.NewMemoizedDirector(proxy.BatchDirectorFunc(func(ctx context.Context, fullMethodName, routingKey string) (proxy.Mode, []proxy.Backend, error) {
+		atomic.AddInt32(&calls, 1)
+
+		if routingKey == "broken" {
+			return proxy.One2One, nil, errors.New("broken")
+		}
+
+		time.Sleep(10 * time.Millisecond)
+
+		return proxy.One2One, []proxy.Backend{backend}, nil
+	}), proxy.MetadataRoutingKey(routingMdKey), 0)
+
+	h := newTestHarness(t, func(b proxy.Backend) proxy.StreamDirector {
+		backend = b
+
+		return memoized.Director()
+	})
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+
+		go func(i int) {
+			defer wg.Done()
+
+			ctx := metadata.AppendToOutgoingContext(testContext(t), routingMdKey, fmt.Sprint(i%2))
+
+			_, err := h.client.Ping(ctx, &pb.PingRequest{Value: "foo"})
+			assert.NoError(t, err)
+		}(i)
+	}
+
+	wg.Wait()
+
+	// one invocation per routing key
+	assert.EqualValues(t, 2, atomic.LoadInt32(&calls))
+
+	_, err := h.client.PingEmpty(metadata.AppendToOutgoingContext(testContext(t), routingMdKey, "0"), &pb.Empty{})
+	require.NoError(t, err)
+	assert.EqualValues(t, 3, atomic.LoadInt32(&calls))
+
+	// errors are not memoized
+	for i := 0; i < 2; i++ {
+		_, err = h.client.Ping(metadata.AppendToOutgoingContext(testContext(t), routingMdKey, "broken"), &pb.PingRequest{Value: "foo"})
+		require.Error(t, err)
+	}
+
+	assert.EqualValues(t, 5, atomic.LoadInt32(&calls))
+
+	memoized.Invalidate()
+
+	_, err = h.client.Ping(metadata.AppendToOutgoingContext(testContext(t), routingMdKey, "0"), &pb.PingRequest{Value: "foo"})
+	require.NoError(t, err)
+	assert.EqualValues(t, 6, atomic.LoadInt32(&calls))
+}
+
+func TestMemoizedDirectorMaxEntries(t *testing.T) {
+	var calls int32
+
+	memoized := proxy.NewMemoizedDirector(proxy.BatchDirectorFunc(func(ctx context.Context, fullMethodName, routingKey string) (proxy.Mode, []proxy.Backend, error) {
+		atomic.AddInt32(&calls, 1)
+
+		return proxy.One2One, []proxy.Backend{&taggedBackend{tag: routingKey}}, nil
+	}), proxy.MetadataRoutingKey(routingMdKey), 0)
+
+	memoized.MaxEntries = 4
+
+	direct := func(routingKey string) {
+		ctx := metadata.NewIncomingContext(testContext(t), metadata.Pairs(routingMdKey, routingKey))
+
+		_, backends, err := memoized.Direct(ctx, "/talos.testproto.TestService/Ping")
+		require.NoError(t, err)
+		assert.Equal(t, routingKey, backends[0].String())
+	}
+
+	for i := 0; i < 10; i++ {
+		direct(fmt.Sprint(i))
+	}
+
+	require.EqualValues(t, 10, atomic.LoadInt32(&calls))
+
+	// the decisions never expire, but at most MaxEntries of them are kept
+	for i := 0; i < 10; i++ {
+		direct(fmt.Sprint(i))
+	}
+
+	assert.GreaterOrEqual(t, atomic.LoadInt32(&calls), int32(10+6))
+}
+
+func benchmarkDirector(b *testing.B, director proxy.StreamDirector) {
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(routingMdKey, "worker-3", "authorization", "Bearer token"))
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if _, _, err := director(ctx, "/talos.testproto.TestService/Ping"); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func BenchmarkDirector(b *testing.B) {
+	backends := map[string][]proxy.Backend{"worker-3": {&taggedBackend{tag: "worker-3"}}}
+
+	direct := func(ctx context.Context, fullMethodName, routingKey string) (proxy.Mode, []proxy.Backend, error) {
+		// simulate the cost of the routing decision
+		if _, ok := metadata.FromIncomingContext(ctx); !ok {
+			return proxy.One2One, nil, errors.New("no metadata")
+		}
+
+		return proxy.One2One, backends[routingKey], nil
+	}
+
+	routingKey := proxy.MetadataRoutingKey(routingMdKey)
+
+	b.Run("PerCall", func(b *testing.B) {
+		benchmarkDirector(b, func(ctx context.Context, fullMethodName string) (proxy.Mode, []proxy.Backend, error) {
+			return direct(ctx, fullMethodName, routingKey(ctx, fullMethodName))
+		})
+	})
+
+	b.Run("Memoized", func(b *testing.B) {
+		benchmarkDirector(b, proxy.NewMemoizedDirector(proxy.BatchDirectorFunc(direct), routingKey, time.Minute).Director())
+	})
+}
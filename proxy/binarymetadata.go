@@ -0,0 +1,137 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy
+
+import (
+	"encoding/base64"
+	"strings"
+
+	"google.golang.org/grpc/metadata"
+)
+
+// binaryMetadataSuffix is the suffix of the binary metadata keys.
+const binaryMetadataSuffix = "-bin"
+
+// isBinaryMetadataKey checks whether the values of the key are binary.
+//
+// gRPC carries the binary values base64-encoded on the wire, but the metadata in the context holds the raw bytes.
+func isBinaryMetadataKey(key string) bool {
+	return strings.HasSuffix(strings.ToLower(key), binaryMetadataSuffix)
+}
+
+// metadataEntrySize returns the size of the entry on the wire: the binary values are base64-encoded without padding.
+func metadataEntrySize(key, value string) int {
+	if isBinaryMetadataKey(key) {
+		return len(key) + base64.RawStdEncoding.EncodedLen(len(value)) + metadataEntryOverhead
+	}
+
+	return len(key) + len(value) + metadataEntryOverhead
+}
+
+// decodeBinaryMetadata decodes the base64-encoded binary value in any of the encodings seen in the wild:
+// with or without padding, standard or URL alphabet.
+func decodeBinaryMetadata(value string) ([]byte, bool) {
+	for _, encoding := range []*base64.Encoding{
+		base64.StdEncoding, base64.RawStdEncoding, base64.URLEncoding, base64.RawURLEncoding,
+	} {
+		if decoded, err := encoding.DecodeString(value); err == nil {
+			return decoded, true
+		}
+	}
+
+	return nil, false
+}
+
+// WithBinaryMetadataNormalization decodes the base64-encoded values of the binary metadata keys (with the "-bin"
+// suffix) before they are forwarded to the backends.
+//
+// gRPC expects the raw bytes as the values of the binary keys in the metadata and encodes them on the wire itself,
+// but the middleware (e.g. HTTP gateways, the interceptors copying the headers) often passes the values still
+// base64-encoded, so they reach the backends encoded twice. The values of the listed keys (all the binary keys
+// if none are listed) are decoded in any of the base64 encodings (with or without padding, standard or URL alphabet),
+// the values which are not valid base64 are forwarded as is. The values added by MetadataBackend are normalized
+// as well.
+func WithBinaryMetadataNormalization(keys ...string) Option {
+	return func(o *handlerOptions) {
+		if o.binaryMetadataKeys == nil {
+			o.binaryMetadataKeys = map[string]struct{}{}
+		}
+
+		if len(keys) == 0 {
+			o.binaryMetadataKeys[""] = struct{}{}
+
+			return
+		}
+
+		for _, key := range keys {
+			if !isBinaryMetadataKey(key) {
+				o.invalid("metadata key %q is not binary (no %q suffix)", key, binaryMetadataSuffix)
+			}
+
+			o.binaryMetadataKeys[strings.ToLower(key)] = struct{}{}
+		}
+	}
+}
+
+// normalizeBinaryMetadata decodes the base64-encoded binary values of the outgoing metadata.
+func (o *handlerOptions) normalizeBinaryMetadata(md *outgoingMetadata) {
+	if o.binaryMetadataKeys == nil {
+		return
+	}
+
+	_, all := o.binaryMetadataKeys[""]
+
+	for key, values := range md.md {
+		if !isBinaryMetadataKey(key) {
+			continue
+		}
+
+		if _, ok := o.binaryMetadataKeys[strings.ToLower(key)]; !ok && !all {
+			continue
+		}
+
+		var normalized []string
+
+		for i, value := range values {
+			decoded, ok := decodeBinaryMetadata(value)
+			if !ok {
+				continue
+			}
+
+			if normalized == nil {
+				normalized = append([]string(nil), values...)
+			}
+
+			normalized[i] = string(decoded)
+		}
+
+		if normalized != nil {
+			md.set(key, normalized...)
+		}
+	}
+}
+
+// policyMetadata returns the copy of the metadata with the binary values base64-encoded, so that the raw bytes
+// survive the JSON encoding of the policy input.
+func policyMetadata(md metadata.MD) map[string][]string {
+	result := make(map[string][]string, len(md))
+
+	for key, values := range md {
+		if !isBinaryMetadataKey(key) {
+			result[key] = values
+
+			continue
+		}
+
+		encoded := make([]string, len(values))
+
+		for i, value := range values {
+			encoded[i] = base64.StdEncoding.EncodeToString([]byte(value))
+		}
+
+		result[key] = encoded
+	}
+
+	return result
+}
@@ -0,0 +1,127 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy_test
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/noncepad/grpc-proxy/proxy"
+	pb "github.com/noncepad/grpc-proxy/testservice"
+)
+
+func TestBinaryMetadataNormalization(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		options  []proxy.Option
+		expected string
+	}{
+		{
+			name:     "as is",
+			expected: "x-token-bin=aGVsbG8,x-other-bin=d29ybGQ=|not base64!,x-delta-bin=ZGVsdGE=",
+		},
+		{
+			name:     "all keys",
+			options:  []proxy.Option{proxy.WithBinaryMetadataNormalization()},
+			expected: "x-token-bin=hello,x-other-bin=world|not base64!,x-delta-bin=delta",
+		},
+		{
+			name:     "listed keys",
+			options:  []proxy.Option{proxy.WithBinaryMetadataNormalization("X-Token-Bin")},
+			expected: "x-token-bin=hello,x-other-bin=d29ybGQ=|not base64!,x-delta-bin=ZGVsdGE=",
+		},
+	} {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestHarnessWithService(t, &metadataEchoService{}, func(backend proxy.Backend) proxy.StreamDirector {
+				return func(ctx context.Context, fullMethodName string) (proxy.Mode, []proxy.Backend, error) {
+					return proxy.One2One, []proxy.Backend{proxy.BackendWithMetadata(backend, proxy.MetadataDelta{
+						Add: metadata.Pairs("x-delta-bin", "ZGVsdGE="),
+					})}, nil
+				}
+			}, tt.options...)
+
+			// the values are base64-encoded by the client middleware, and encoded once again on the wire
+			ctx := metadata.AppendToOutgoingContext(testContext(t),
+				"x-token-bin", "aGVsbG8",
+				"x-other-bin", "d29ybGQ=",
+				"x-other-bin", "not base64!",
+			)
+
+			stream, err := h.client.PingStream(ctx)
+			require.NoError(t, err)
+
+			require.NoError(t, stream.Send(&pb.PingRequest{Value: "x-token-bin,x-other-bin,x-delta-bin"}))
+
+			out, err := stream.Recv()
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, out.Value)
+
+			require.NoError(t, stream.CloseSend())
+		})
+	}
+
+	_, err := proxy.NewTransparentHandler(one2oneDirector(nil), proxy.WithBinaryMetadataNormalization("x-token"))
+	require.ErrorIs(t, err, proxy.ErrInvalidOptions)
+}
+
+func TestBinaryMetadataPolicyInput(t *testing.T) {
+	var encoded []byte
+
+	h := newTestHarness(t, func(backend proxy.Backend) proxy.StreamDirector {
+		director := &proxy.PolicyDirector{
+			Policy: proxy.PolicyFunc(func(ctx context.Context, input *proxy.PolicyInput) (*proxy.PolicyDecision, error) {
+				var err error
+
+				encoded, err = json.Marshal(input.Metadata["x-trace-bin"])
+				if err != nil {
+					return nil, err
+				}
+
+				return &proxy.PolicyDecision{Allow: true, Backends: []string{"default"}}, nil
+			}),
+			Resolve: func(ctx context.Context, name string) (proxy.Backend, error) {
+				return backend, nil
+			},
+		}
+
+		return director.Director
+	})
+
+	_, err := h.client.Ping(metadata.AppendToOutgoingContext(testContext(t), "x-trace-bin", "\x00\x01\xfe\xff"), &pb.PingRequest{Value: "foo"})
+	require.NoError(t, err)
+
+	// the raw bytes survive the JSON encoding
+	assert.Equal(t, `["AAH+/w=="]`, string(encoded))
+}
+
+func TestBinaryMetadataLimits(t *testing.T) {
+	var events []proxy.MetadataLimitEvent
+
+	h := newTestHarness(t, one2oneDirector, proxy.WithMetadataLimits(proxy.MetadataLimitsPolicy{
+		Request:  proxy.MetadataLimit{MaxSize: 400},
+		Observer: func(event proxy.MetadataLimitEvent) { events = append(events, event) },
+	}))
+
+	ctx := testContext(t)
+
+	_, err := h.client.Ping(metadata.AppendToOutgoingContext(ctx, "x-blob", strings.Repeat("x", 300)), &pb.PingRequest{Value: "foo"})
+	require.NoError(t, err)
+
+	// 300 bytes are 400 bytes on the wire
+	_, err = h.client.Ping(metadata.AppendToOutgoingContext(ctx, "x-blob-bin", strings.Repeat("\xff", 300)), &pb.PingRequest{Value: "foo"})
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	require.Len(t, events, 1)
+	assert.Greater(t, events[0].Size, len("x-blob-bin")+400+32)
+}
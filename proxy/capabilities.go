@@ -0,0 +1,249 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	rpb "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// Capabilities are the services and the methods implemented by the backend.
+type Capabilities struct {
+	// Services maps the full service name to the method names.
+	Services map[string][]string
+	// Probed is the time of the probe.
+	Probed time.Time
+}
+
+// Supports checks whether the method is implemented.
+func (c Capabilities) Supports(fullMethodName string) bool {
+	service, method, ok := splitMethodName(fullMethodName)
+	if !ok {
+		return false
+	}
+
+	for _, name := range c.Services[service] {
+		if name == method {
+			return true
+		}
+	}
+
+	return false
+}
+
+// CapabilityProber queries the backends for the implemented methods with the gRPC server reflection, and caches
+// the results, so that the director and the fan-out can skip the backends which don't implement the method
+// instead of collecting codes.Unimplemented errors.
+//
+// The backends are identified by String(). The capabilities of the backends which can't be probed (e.g. they
+// don't serve the reflection) are unknown, and such backends are assumed to implement all the methods.
+// Both the capabilities and the probe failures are cached for the TTL.
+//
+// CapabilityProber is safe for concurrent use.
+type CapabilityProber struct {
+	ttl time.Duration
+
+	mu      sync.Mutex
+	entries map[string]*capabilityEntry
+}
+
+type capabilityEntry struct {
+	mu sync.Mutex
+
+	capabilities *Capabilities
+	err          error
+	expires      time.Time
+}
+
+// NewCapabilityProber creates the prober caching the results for ttl (zero means forever).
+func NewCapabilityProber(ttl time.Duration) *CapabilityProber {
+	return &CapabilityProber{
+		ttl:     ttl,
+		entries: map[string]*capabilityEntry{},
+	}
+}
+
+// Capabilities returns the capabilities of the backend, probing it if they are not cached.
+func (p *CapabilityProber) Capabilities(ctx context.Context, backend Backend) (*Capabilities, error) {
+	p.mu.Lock()
+	entry, ok := p.entries[backend.String()]
+
+	if !ok {
+		entry = &capabilityEntry{}
+		p.entries[backend.String()] = entry
+	}
+	p.mu.Unlock()
+
+	// concurrent lookups of the same backend wait for a single probe
+	entry.mu.Lock()
+	defer entry.mu.Unlock()
+
+	if (entry.capabilities != nil || entry.err != nil) && (p.ttl == 0 || time.Now().Before(entry.expires)) {
+		return entry.capabilities, entry.err
+	}
+
+	entry.capabilities, entry.err = probeCapabilities(ctx, backend)
+	entry.expires = time.Now().Add(p.ttl)
+
+	return entry.capabilities, entry.err
+}
+
+// Invalidate drops the cached capabilities of the backend, e.g. once it is redeployed.
+func (p *CapabilityProber) Invalidate(backend Backend) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	delete(p.entries, backend.String())
+}
+
+// Supports checks whether the backend implements the method, backends with unknown capabilities are assumed to.
+func (p *CapabilityProber) Supports(ctx context.Context, backend Backend, fullMethodName string) bool {
+	capabilities, err := p.Capabilities(ctx, backend)
+	if err != nil {
+		return true
+	}
+
+	return capabilities.Supports(fullMethodName)
+}
+
+// Filter returns the backends implementing the method, the backends are probed concurrently.
+func (p *CapabilityProber) Filter(ctx context.Context, fullMethodName string, backends []Backend) []Backend {
+	supported := make([]bool, len(backends))
+
+	var wg sync.WaitGroup
+
+	for i := range backends {
+		wg.Add(1)
+
+		go func(i int) {
+			defer wg.Done()
+
+			supported[i] = p.Supports(ctx, backends[i], fullMethodName)
+		}(i)
+	}
+
+	wg.Wait()
+
+	filtered := make([]Backend, 0, len(backends))
+
+	for i, backend := range backends {
+		if supported[i] {
+			filtered = append(filtered, backend)
+		}
+	}
+
+	return filtered
+}
+
+// Director wraps the director to skip the backends which don't implement the method.
+//
+// If none of the backends implements the method, the call fails with codes.Unimplemented.
+func (p *CapabilityProber) Director(director StreamDirector) StreamDirector {
+	return func(ctx context.Context, fullMethodName string) (Mode, []Backend, error) {
+		mode, backends, err := director(ctx, fullMethodName)
+		if err != nil || len(backends) == 0 {
+			return mode, backends, err
+		}
+
+		filtered := p.Filter(ctx, fullMethodName, backends)
+		if len(filtered) == 0 {
+			return mode, nil, status.Errorf(codes.Unimplemented, "no backend implements %s", fullMethodName)
+		}
+
+		return mode, filtered, nil
+	}
+}
+
+// probeCapabilities lists the services of the backend and their methods with the gRPC server reflection.
+func probeCapabilities(ctx context.Context, backend Backend) (*Capabilities, error) {
+	ctx, cancel := context.WithTimeout(ctx, reflectionTimeout)
+	defer cancel()
+
+	outgoingCtx, conn, err := backend.GetConnection(ctx, reflectionMethod)
+	if err != nil {
+		return nil, err
+	}
+
+	stream, err := rpb.NewServerReflectionClient(conn).ServerReflectionInfo(outgoingCtx)
+	if err != nil {
+		return nil, err
+	}
+
+	defer stream.CloseSend() //nolint:errcheck
+
+	request := func(req *rpb.ServerReflectionRequest) (*rpb.ServerReflectionResponse, error) {
+		if err := stream.Send(req); err != nil {
+			return nil, err
+		}
+
+		resp, err := stream.Recv()
+		if err != nil {
+			return nil, err
+		}
+
+		if errResp := resp.GetErrorResponse(); errResp != nil {
+			return nil, errors.New(errResp.GetErrorMessage())
+		}
+
+		return resp, nil
+	}
+
+	resp, err := request(&rpb.ServerReflectionRequest{
+		MessageRequest: &rpb.ServerReflectionRequest_ListServices{ListServices: "*"},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	capabilities := &Capabilities{
+		Services: map[string][]string{},
+		Probed:   time.Now(),
+	}
+
+	for _, service := range resp.GetListServicesResponse().GetService() {
+		capabilities.Services[service.GetName()] = nil
+	}
+
+	for serviceName := range capabilities.Services {
+		resp, err = request(&rpb.ServerReflectionRequest{
+			MessageRequest: &rpb.ServerReflectionRequest_FileContainingSymbol{FileContainingSymbol: serviceName},
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, encoded := range resp.GetFileDescriptorResponse().GetFileDescriptorProto() {
+			fdp := &descriptorpb.FileDescriptorProto{}
+
+			if err = proto.Unmarshal(encoded, fdp); err != nil {
+				return nil, err
+			}
+
+			for _, service := range fdp.GetService() {
+				name := service.GetName()
+				if fdp.GetPackage() != "" {
+					name = fdp.GetPackage() + "." + name
+				}
+
+				if name != serviceName {
+					continue
+				}
+
+				for _, method := range service.GetMethod() {
+					capabilities.Services[serviceName] = append(capabilities.Services[serviceName], method.GetName())
+				}
+			}
+		}
+	}
+
+	return capabilities, nil
+}
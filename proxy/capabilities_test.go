@@ -0,0 +1,70 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/noncepad/grpc-proxy/proxy"
+)
+
+func TestCapabilityProber(t *testing.T) {
+	reflected := newReflectionBackend(t)
+	probed := &taggedBackend{Backend: reflected, tag: "reflected"}
+
+	// the harness backend doesn't serve the reflection
+	h := newTestHarness(t, one2oneDirector)
+	unknown := &taggedBackend{Backend: &proxy.SingleBackend{
+		GetConn: func(ctx context.Context) (context.Context, *grpc.ClientConn, error) {
+			return ctx, h.backendConn, nil
+		},
+	}, tag: "unknown"}
+
+	prober := proxy.NewCapabilityProber(time.Minute)
+	ctx := testContext(t)
+
+	capabilities, err := prober.Capabilities(ctx, probed)
+	require.NoError(t, err)
+	assert.Contains(t, capabilities.Services["talos.testproto.TestService"], "Ping")
+	assert.True(t, capabilities.Supports("/talos.testproto.TestService/PingStream"))
+	assert.False(t, capabilities.Supports("/talos.testproto.TestService/Missing"))
+
+	_, err = prober.Capabilities(ctx, unknown)
+	require.Error(t, err)
+
+	director := prober.Director(func(ctx context.Context, fullMethodName string) (proxy.Mode, []proxy.Backend, error) {
+		return proxy.One2Many, []proxy.Backend{probed, unknown}, nil
+	})
+
+	_, backends, err := director(ctx, "/talos.testproto.TestService/Ping")
+	require.NoError(t, err)
+	assert.Equal(t, []proxy.Backend{probed, unknown}, backends)
+
+	_, backends, err = director(ctx, "/other.Service/Method")
+	require.NoError(t, err)
+	assert.Equal(t, []proxy.Backend{unknown}, backends)
+
+	director = prober.Director(func(ctx context.Context, fullMethodName string) (proxy.Mode, []proxy.Backend, error) {
+		return proxy.One2One, []proxy.Backend{probed}, nil
+	})
+
+	_, _, err = director(ctx, "/other.Service/Method")
+	assert.Equal(t, codes.Unimplemented, status.Code(err))
+
+	// the results are cached until invalidated
+	assert.EqualValues(t, 1, atomic.LoadInt32(&reflected.connections))
+
+	prober.Invalidate(probed)
+	assert.True(t, prober.Supports(ctx, probed, "/talos.testproto.TestService/Ping"))
+	assert.EqualValues(t, 2, atomic.LoadInt32(&reflected.connections))
+}
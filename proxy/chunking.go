@@ -0,0 +1,306 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+)
+
+// ChunkingMetadataKey is the metadata key which enables the chunking protocol on the upstream stream.
+//
+// The value is the maximum size of the chunk frame (in bytes) the upstream accepts.
+const ChunkingMetadataKey = "proxy-chunking"
+
+// Chunk frame flags.
+const (
+	chunkFinal        byte = 0
+	chunkContinuation byte = 1
+)
+
+// minChunkSize is the minimum supported chunk frame size.
+const minChunkSize = 64
+
+// defaultChunkingMaxMessageSize is the default limit of the reassembled message size.
+const defaultChunkingMaxMessageSize = 64 << 20
+
+// WithChunking enables the chunking protocol for the upstream streams of the listed methods.
+//
+// Chunking bridges mismatched message size limits: when an upstream enforces a smaller max message size than
+// the client sends, each message is split into chunk frames of at most maxChunkSize bytes, and reassembled on
+// the other side. Each chunk frame consists of a flag byte (final or continuation), the varint encoded length of the
+// data and the data itself. The protocol is enabled by sending ChunkingMetadataKey to the upstream, and it applies
+// to the messages in both directions.
+//
+// The chunk size should be at least 64 bytes, the smaller sizes are raised to it. The reassembled messages are limited
+// to 64 MiB, see WithChunkingMaxMessageSize.
+//
+// The upstream should support the protocol, see ChunkingStreamServerInterceptor.
+func WithChunking(maxChunkSize int, fullMethodNames ...string) Option {
+	return func(o *handlerOptions) {
+		if len(fullMethodNames) == 0 {
+			o.invalid("WithChunking has no method names")
+		}
+
+		if maxChunkSize < minChunkSize {
+			o.invalid("chunk size should be at least %d, got %d", minChunkSize, maxChunkSize)
+
+			maxChunkSize = minChunkSize
+		}
+
+		if o.chunkingMethods == nil {
+			o.chunkingMethods = map[string]int{}
+		}
+
+		for _, name := range fullMethodNames {
+			o.chunkingMethods[name] = maxChunkSize
+		}
+	}
+}
+
+// WithChunkingMaxMessageSize limits the size of the messages reassembled from the chunk frames of the upstream
+// (default 64 MiB).
+//
+// The upstream could otherwise make the proxy buffer the unbounded message by never sending the final chunk frame.
+// The call fails with ErrMessageTooLarge once the limit is exceeded.
+func WithChunkingMaxMessageSize(maxMessageSize int) Option {
+	return func(o *handlerOptions) {
+		if maxMessageSize <= 0 {
+			o.invalid("WithChunkingMaxMessageSize has non-positive size %d", maxMessageSize)
+
+			return
+		}
+
+		o.chunkingMaxMessageSize = maxMessageSize
+	}
+}
+
+// writeChunks splits the payload into chunk frames.
+func writeChunks(payload []byte, maxChunkSize int, send func(chunk []byte) error) error {
+	for {
+		// flag + length varint (the length never exceeds maxChunkSize)
+		dataSize := maxChunkSize - 1 - protowire.SizeVarint(uint64(maxChunkSize))
+
+		flag := chunkContinuation
+
+		if len(payload) <= dataSize {
+			dataSize = len(payload)
+			flag = chunkFinal
+		}
+
+		chunk := make([]byte, 0, 1+protowire.SizeVarint(uint64(dataSize))+dataSize)
+		chunk = append(chunk, flag)
+		chunk = protowire.AppendVarint(chunk, uint64(dataSize))
+		chunk = append(chunk, payload[:dataSize]...)
+
+		if err := send(chunk); err != nil {
+			return err
+		}
+
+		payload = payload[dataSize:]
+
+		if flag == chunkFinal {
+			return nil
+		}
+	}
+}
+
+// chunkReassembler reassembles the messages from chunk frames.
+type chunkReassembler struct {
+	buf []byte
+	// capacity is the initial capacity of the message buffer, see WithPayloadSizeClass.
+	capacity int
+	// maxMessageSize limits the size of the reassembled message, defaultChunkingMaxMessageSize is used if zero.
+	maxMessageSize int
+}
+
+// add adds the chunk, it returns the message once the final chunk was added.
+func (r *chunkReassembler) add(chunk []byte) ([]byte, bool, error) {
+	if len(chunk) == 0 {
+		return nil, false, errors.New("empty chunk frame")
+	}
+
+	flag := chunk[0]
+
+	size, n := protowire.ConsumeVarint(chunk[1:])
+	if n < 0 || uint64(len(chunk)-1-n) != size {
+		return nil, false, errors.New("malformed chunk frame")
+	}
+
+	data := chunk[1+n:]
+
+	maxMessageSize := r.maxMessageSize
+	if maxMessageSize <= 0 {
+		maxMessageSize = defaultChunkingMaxMessageSize
+	}
+
+	if len(r.buf)+len(data) > maxMessageSize {
+		r.buf = nil
+
+		return nil, false, newError(ErrMessageTooLarge, "reassembled message exceeds %d bytes", maxMessageSize)
+	}
+
+	switch flag {
+	case chunkContinuation:
+		if r.buf == nil && r.capacity > len(data) {
+			r.buf = make([]byte, 0, r.capacity)
+		}
+
+		r.buf = append(r.buf, data...)
+
+		return nil, false, nil
+	case chunkFinal:
+		payload := append(r.buf, data...) //nolint:gocritic
+		r.buf = nil
+
+		return payload, true, nil
+	default:
+		return nil, false, fmt.Errorf("unknown chunk frame flag %d", flag)
+	}
+}
+
+// recvChunked receives chunk frames until the message is complete.
+func recvChunked(reassembler *chunkReassembler, recv func(*Frame) error) ([]byte, error) {
+	for {
+		f := &Frame{}
+
+		if err := recv(f); err != nil {
+			return nil, err
+		}
+
+		payload, complete, err := reassembler.add(f.payload)
+		if err != nil {
+			return nil, err
+		}
+
+		if complete {
+			return payload, nil
+		}
+	}
+}
+
+// chunkingClientStream applies the chunking protocol to the upstream stream.
+type chunkingClientStream struct {
+	grpc.ClientStream
+
+	reassembler  chunkReassembler
+	maxChunkSize int
+}
+
+func (s *chunkingClientStream) SendMsg(m interface{}) error {
+	f, ok := m.(*Frame)
+	if !ok {
+		return s.ClientStream.SendMsg(m)
+	}
+
+	return writeChunks(f.payload, s.maxChunkSize, func(chunk []byte) error {
+		return s.ClientStream.SendMsg(NewFrame(chunk))
+	})
+}
+
+func (s *chunkingClientStream) RecvMsg(m interface{}) error {
+	f, ok := m.(*Frame)
+	if !ok {
+		return s.ClientStream.RecvMsg(m)
+	}
+
+	payload, err := recvChunked(&s.reassembler, func(chunk *Frame) error { return s.ClientStream.RecvMsg(chunk) })
+	if err != nil {
+		return err
+	}
+
+	f.payload = payload
+
+	return nil
+}
+
+// chunkingOutgoingContext enables chunking for the upstream call.
+func chunkingOutgoingContext(ctx context.Context, maxChunkSize int) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, ChunkingMetadataKey, strconv.Itoa(maxChunkSize))
+}
+
+// ChunkingStreamServerInterceptor returns a server interceptor which implements the upstream side of the chunking
+// protocol (see WithChunking).
+//
+// The streams which don't carry ChunkingMetadataKey are not modified. The server should use the proxy codec
+// (see Codec), so that the chunk frames can be received as raw bytes; messages are unmarshaled with protobuf
+// after reassembly.
+//
+// The reassembled messages are limited to maxMessageSize bytes (64 MiB if zero), the call fails with
+// ErrMessageTooLarge once the limit is exceeded.
+func ChunkingStreamServerInterceptor(maxMessageSize int) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		md, _ := metadata.FromIncomingContext(ss.Context())
+
+		values := md.Get(ChunkingMetadataKey)
+		if len(values) == 0 {
+			return handler(srv, ss)
+		}
+
+		maxChunkSize, err := strconv.Atoi(values[0])
+		if err != nil || maxChunkSize < minChunkSize {
+			return newError(ErrMalformedRequest, "invalid chunk size %q", values[0])
+		}
+
+		return handler(srv, &chunkingServerStream{
+			ServerStream: ss,
+			reassembler:  chunkReassembler{maxMessageSize: maxMessageSize},
+			maxChunkSize: maxChunkSize,
+		})
+	}
+}
+
+// chunkingServerStream applies the chunking protocol on the upstream side.
+type chunkingServerStream struct {
+	grpc.ServerStream
+
+	reassembler  chunkReassembler
+	maxChunkSize int
+}
+
+func (s *chunkingServerStream) SendMsg(m interface{}) error {
+	var payload []byte
+
+	switch msg := m.(type) {
+	case *Frame:
+		payload = msg.payload
+	case proto.Message:
+		var err error
+
+		if payload, err = proto.Marshal(msg); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unsupported message type %T", m)
+	}
+
+	return writeChunks(payload, s.maxChunkSize, func(chunk []byte) error {
+		return s.ServerStream.SendMsg(NewFrame(chunk))
+	})
+}
+
+func (s *chunkingServerStream) RecvMsg(m interface{}) error {
+	payload, err := recvChunked(&s.reassembler, func(chunk *Frame) error { return s.ServerStream.RecvMsg(chunk) })
+	if err != nil {
+		return err
+	}
+
+	switch msg := m.(type) {
+	case *Frame:
+		msg.payload = payload
+
+		return nil
+	case proto.Message:
+		return proto.Unmarshal(payload, msg)
+	default:
+		return fmt.Errorf("unsupported message type %T", m)
+	}
+}
@@ -0,0 +1,130 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/noncepad/grpc-proxy/proxy"
+	pb "github.com/noncepad/grpc-proxy/testservice"
+)
+
+func TestChunking(t *testing.T) {
+	const pingStream = "/talos.testproto.TestService/PingStream"
+
+	streamed := proxy.WithStreamedDetector(func(fullMethodName string) bool { return fullMethodName == pingStream })
+
+	payload := strings.Repeat("x", 200<<10)
+
+	for _, tt := range []struct {
+		name    string
+		options []proxy.Option
+		// maxMessageSize is the limit of the upstream
+		maxMessageSize int
+		code           codes.Code
+		reason         string
+	}{
+		{
+			name:    "chunked",
+			options: []proxy.Option{streamed, proxy.WithChunking(32<<10, pingStream)},
+			code:    codes.OK,
+		},
+		{
+			name:    "not chunked",
+			options: []proxy.Option{streamed},
+			code:    codes.ResourceExhausted,
+		},
+		{
+			name:           "upstream limit",
+			options:        []proxy.Option{streamed, proxy.WithChunking(32<<10, pingStream)},
+			maxMessageSize: 100 << 10,
+			code:           codes.ResourceExhausted,
+			reason:         proxy.ReasonMessageTooLarge,
+		},
+		{
+			name:    "proxy limit",
+			options: []proxy.Option{streamed, proxy.WithChunking(32<<10, pingStream), proxy.WithChunkingMaxMessageSize(100 << 10)},
+			code:    codes.ResourceExhausted,
+			reason:  proxy.ReasonMessageTooLarge,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			serverOptions := []grpc.ServerOption{
+				grpc.CustomCodec(proxy.Codec()), //nolint: staticcheck
+				grpc.MaxRecvMsgSize(64 << 10),
+				grpc.StreamInterceptor(proxy.ChunkingStreamServerInterceptor(tt.maxMessageSize)),
+			}
+
+			h := newTestHarnessWithServer(t, &lenientService{}, serverOptions, one2oneDirector, tt.options...)
+
+			stream, err := h.client.PingStream(testContext(t))
+			require.NoError(t, err)
+
+			for i := 0; i < 3; i++ {
+				require.NoError(t, stream.Send(&pb.PingRequest{Value: payload}))
+
+				var resp *pb.PingResponse
+
+				resp, err = stream.Recv()
+				if err != nil {
+					break
+				}
+
+				assert.Equal(t, payload, resp.Value)
+				assert.EqualValues(t, i, resp.Counter)
+			}
+
+			if tt.code == codes.OK {
+				require.NoError(t, err)
+				require.NoError(t, stream.CloseSend())
+
+				return
+			}
+
+			assert.Equal(t, tt.code, status.Code(err))
+
+			if tt.reason != "" {
+				proxyErr, ok := proxy.FromError(err)
+				require.True(t, ok, "%v", err)
+
+				assert.Equal(t, tt.reason, proxyErr.Reason)
+			}
+		})
+	}
+}
+
+func TestChunkingSmallMessages(t *testing.T) {
+	const pingStream = "/talos.testproto.TestService/PingStream"
+
+	h := newTestHarnessWithServer(t, &lenientService{},
+		[]grpc.ServerOption{
+			grpc.CustomCodec(proxy.Codec()), //nolint: staticcheck
+			grpc.StreamInterceptor(proxy.ChunkingStreamServerInterceptor(0)),
+		},
+		one2oneDirector,
+		proxy.WithStreamedDetector(func(fullMethodName string) bool { return fullMethodName == pingStream }),
+		proxy.WithChunking(1<<10, pingStream),
+	)
+
+	stream, err := h.client.PingStream(testContext(t))
+	require.NoError(t, err)
+
+	for _, value := range []string{"", "foo", strings.Repeat("y", 1<<10), strings.Repeat("z", 5<<10+1)} {
+		require.NoError(t, stream.Send(&pb.PingRequest{Value: value}))
+
+		resp, err := stream.Recv()
+		require.NoError(t, err)
+
+		assert.Equal(t, value, resp.Value)
+	}
+
+	require.NoError(t, stream.CloseSend())
+}
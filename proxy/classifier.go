@@ -0,0 +1,143 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// ClassificationRequest is the input of the request classifier.
+type ClassificationRequest struct {
+	Method   string              `json:"method"`
+	Metadata map[string][]string `json:"metadata,omitempty"`
+	// Message is the serialized first request message, nil if the client didn't send any.
+	Message []byte `json:"message,omitempty"`
+}
+
+// Classification is the result of the request classifier.
+type Classification struct {
+	// Class is the class of the call (e.g. "bulk" or "interactive"), available to the director and the backends
+	// via ClassFromContext.
+	Class string `json:"class,omitempty"`
+	// Metadata is set in the incoming metadata of the call, so the director and the upstream calls see it.
+	Metadata map[string]string `json:"metadata,omitempty"`
+	// Message replaces the first request message, if set.
+	Message []byte `json:"message,omitempty"`
+	// Reject rejects the call with ErrPolicyDenied and the message, if set.
+	Reject string `json:"reject,omitempty"`
+}
+
+// Classifier classifies and transforms the calls before the director is invoked.
+//
+// Classifier is the plugin interface for the routing logic which changes more often than the embedding binary,
+// see ModuleClassifier for the classifiers loaded at runtime.
+type Classifier interface {
+	Classify(ctx context.Context, request ClassificationRequest) (Classification, error)
+}
+
+// ClassifierFunc is a function adapter for Classifier.
+type ClassifierFunc func(ctx context.Context, request ClassificationRequest) (Classification, error)
+
+// Classify implements Classifier.
+func (f ClassifierFunc) Classify(ctx context.Context, request ClassificationRequest) (Classification, error) {
+	return f(ctx, request)
+}
+
+type classKey struct{}
+
+// ClassFromContext returns the class of the call assigned by the classifier.
+func ClassFromContext(ctx context.Context) (string, bool) {
+	class, ok := ctx.Value(classKey{}).(string)
+
+	return class, ok
+}
+
+// WithClassifier classifies the calls of the listed methods (all methods if none listed) with the classifier before
+// the director is invoked.
+//
+// Classification requires the first request message, so this option implies WithRequestPeek for the listed methods.
+// If the classifier returns an error, the call fails with ErrPolicyFailed.
+func WithClassifier(classifier Classifier, fullMethodNames ...string) Option {
+	return func(o *handlerOptions) {
+		o.classifier = classifier
+		o.classifierMethods = nil
+
+		if len(fullMethodNames) == 0 {
+			o.requestPeek = true
+
+			return
+		}
+
+		o.classifierMethods = map[string]struct{}{}
+
+		if o.requestPeekMethods == nil {
+			o.requestPeekMethods = map[string]struct{}{}
+		}
+
+		for _, name := range fullMethodNames {
+			o.classifierMethods[name] = struct{}{}
+			o.requestPeekMethods[name] = struct{}{}
+		}
+	}
+}
+
+// classified checks whether the calls of the method are classified.
+func (o *handlerOptions) classified(fullMethodName string) bool {
+	if o.classifier == nil {
+		return false
+	}
+
+	if o.classifierMethods == nil {
+		return true
+	}
+
+	_, ok := o.classifierMethods[fullMethodName]
+
+	return ok
+}
+
+// classify applies the classifier to the call, it returns the server stream with the classified context.
+func (o *handlerOptions) classify(serverStream grpc.ServerStream, fullMethodName string) (grpc.ServerStream, error) {
+	ctx := serverStream.Context()
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	message, _ := RequestFrameFromContext(ctx)
+
+	classification, err := o.classifier.Classify(ctx, ClassificationRequest{
+		Method:   fullMethodName,
+		Metadata: md,
+		Message:  message,
+	})
+	if err != nil {
+		return nil, newError(ErrPolicyFailed, "error classifying %s: %v", fullMethodName, err)
+	}
+
+	if classification.Reject != "" {
+		return nil, newError(ErrPolicyDenied, "%s", classification.Reject)
+	}
+
+	if classification.Class != "" {
+		ctx = context.WithValue(ctx, classKey{}, classification.Class)
+	}
+
+	if len(classification.Metadata) > 0 {
+		md = md.Copy()
+
+		for key, value := range classification.Metadata {
+			md.Set(key, value)
+		}
+
+		ctx = metadata.NewIncomingContext(ctx, md)
+	}
+
+	if peeked, ok := serverStream.(*peekedServerStream); ok && classification.Message != nil && peeked.payload != nil {
+		peeked.payload = classification.Message
+		ctx = context.WithValue(ctx, requestFrameKey{}, classification.Message)
+	}
+
+	return &contextServerStream{ServerStream: serverStream, ctx: ctx}, nil
+}
@@ -0,0 +1,194 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/noncepad/grpc-proxy/proxy"
+	pb "github.com/noncepad/grpc-proxy/testservice"
+)
+
+// fakeClassifierRuntime instantiates the "modules" which are the Go classifiers named by the binary.
+type fakeClassifierRuntime struct {
+	classifiers map[string]proxy.ClassifierFunc
+}
+
+func (r *fakeClassifierRuntime) Instantiate(_ context.Context, binary []byte) (proxy.ClassifierModule, error) {
+	classifier, ok := r.classifiers[string(binary)]
+	if !ok {
+		return nil, errors.New("invalid module")
+	}
+
+	return &fakeClassifierModule{classifier: classifier}, nil
+}
+
+type fakeClassifierModule struct {
+	classifier proxy.ClassifierFunc
+	closed     bool
+}
+
+func (m *fakeClassifierModule) Call(ctx context.Context, function string, input []byte) ([]byte, error) {
+	if function != proxy.DefaultClassifyFunction {
+		return nil, errors.New("unknown function")
+	}
+
+	var request proxy.ClassificationRequest
+
+	if err := json.Unmarshal(input, &request); err != nil {
+		return nil, err
+	}
+
+	classification, err := m.classifier(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(classification)
+}
+
+func (m *fakeClassifierModule) Close(context.Context) error {
+	m.closed = true
+
+	return nil
+}
+
+func TestClassifier(t *testing.T) {
+	runtime := &fakeClassifierRuntime{
+		classifiers: map[string]proxy.ClassifierFunc{
+			"v1": func(ctx context.Context, request proxy.ClassificationRequest) (proxy.Classification, error) {
+				if request.Method == "/talos.testproto.TestService/PingEmpty" {
+					return proxy.Classification{Reject: "empty pings are not allowed"}, nil
+				}
+
+				var ping pb.PingRequest
+
+				if err := proto.Unmarshal(request.Message, &ping); err != nil {
+					return proxy.Classification{}, err
+				}
+
+				message, err := proto.Marshal(&pb.PingRequest{Value: ping.Value + "-rewritten"})
+				if err != nil {
+					return proxy.Classification{}, err
+				}
+
+				return proxy.Classification{
+					Class:    "v1-" + request.Metadata["tenant"][0],
+					Metadata: map[string]string{"x-class": "v1"},
+					Message:  message,
+				}, nil
+			},
+			"v2": func(ctx context.Context, request proxy.ClassificationRequest) (proxy.Classification, error) {
+				return proxy.Classification{Class: "v2"}, nil
+			},
+		},
+	}
+
+	classifier := proxy.NewModuleClassifier(runtime, "")
+
+	var (
+		mu      sync.Mutex
+		classes []string
+	)
+
+	h := newTestHarness(t, func(backend proxy.Backend) proxy.StreamDirector {
+		return func(ctx context.Context, fullMethodName string) (proxy.Mode, []proxy.Backend, error) {
+			class, _ := proxy.ClassFromContext(ctx)
+
+			mu.Lock()
+			classes = append(classes, class+"/"+strings.Join(metadata.ValueFromIncomingContext(ctx, "x-class"), ","))
+			mu.Unlock()
+
+			return proxy.One2One, []proxy.Backend{backend}, nil
+		}
+	}, proxy.WithClassifier(classifier))
+
+	ctx := metadata.AppendToOutgoingContext(testContext(t), "tenant", "acme")
+
+	// no module loaded yet
+	_, err := h.client.Ping(ctx, &pb.PingRequest{Value: "foo"})
+	assert.Equal(t, codes.Internal, status.Code(err))
+
+	require.Error(t, classifier.Load(ctx, []byte("invalid")))
+	require.NoError(t, classifier.Load(ctx, []byte("v1")))
+
+	resp, err := h.client.Ping(ctx, &pb.PingRequest{Value: "foo"})
+	require.NoError(t, err)
+	assert.Equal(t, "foo-rewritten", resp.Value)
+
+	_, err = h.client.PingEmpty(ctx, &pb.Empty{})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	assert.Contains(t, status.Convert(err).Message(), "empty pings are not allowed")
+
+	// the module is replaced at runtime
+	require.NoError(t, classifier.Load(ctx, []byte("v2")))
+
+	resp, err = h.client.Ping(ctx, &pb.PingRequest{Value: "foo"})
+	require.NoError(t, err)
+	assert.Equal(t, "foo", resp.Value)
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	assert.Equal(t, []string{"v1-acme/v1", "v2/"}, classes)
+}
+
+func TestClassifierMethods(t *testing.T) {
+	var (
+		mu         sync.Mutex
+		classified []string
+		peeked     = map[string]bool{}
+	)
+
+	classifier := proxy.ClassifierFunc(func(ctx context.Context, request proxy.ClassificationRequest) (proxy.Classification, error) {
+		mu.Lock()
+		classified = append(classified, request.Method)
+		mu.Unlock()
+
+		return proxy.Classification{Class: "bulk"}, nil
+	})
+
+	h := newTestHarness(t, func(backend proxy.Backend) proxy.StreamDirector {
+		return func(ctx context.Context, fullMethodName string) (proxy.Mode, []proxy.Backend, error) {
+			_, ok := proxy.RequestFrameFromContext(ctx)
+
+			mu.Lock()
+			peeked[fullMethodName] = ok
+			mu.Unlock()
+
+			return proxy.One2One, []proxy.Backend{backend}, nil
+		}
+	}, proxy.WithClassifier(classifier, "/talos.testproto.TestService/Ping"))
+
+	ctx := testContext(t)
+
+	_, err := h.client.Ping(ctx, &pb.PingRequest{Value: "foo"})
+	require.NoError(t, err)
+
+	_, err = h.client.PingEmpty(ctx, &pb.Empty{})
+	require.NoError(t, err)
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	assert.Equal(t, []string{"/talos.testproto.TestService/Ping"}, classified)
+
+	// only the requests of the classified methods are peeked
+	assert.Equal(t, map[string]bool{
+		"/talos.testproto.TestService/Ping":      true,
+		"/talos.testproto.TestService/PingEmpty": false,
+	}, peeked)
+}
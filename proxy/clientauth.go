@@ -0,0 +1,231 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy
+
+import (
+	"context"
+	"crypto/x509"
+	"path"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+// Canonical client identity metadata keys set for the backends.
+const (
+	// ClientIdentityMetadataKey is the verified principal of the client (e.g. the SPIFFE ID of the certificate,
+	// the ALTS service account or the token subject).
+	ClientIdentityMetadataKey = "proxy-client-identity"
+	// ClientAuthMechanismMetadataKey is the mechanism the client identity was verified with.
+	ClientAuthMechanismMetadataKey = "proxy-client-auth"
+)
+
+// Client authentication mechanisms.
+const (
+	ClientAuthMTLS  = "mtls"
+	ClientAuthALTS  = "alts"
+	ClientAuthToken = "token"
+)
+
+// ClientIdentity is the identity of the client verified by the ClientAuthenticator.
+type ClientIdentity struct {
+	Mechanism string
+	Principal string
+}
+
+type clientIdentityKey struct{}
+
+// ClientIdentityFromContext returns the verified identity of the client.
+func ClientIdentityFromContext(ctx context.Context) (ClientIdentity, bool) {
+	identity, ok := ctx.Value(clientIdentityKey{}).(ClientIdentity)
+
+	return identity, ok
+}
+
+// ClientAuthenticator verifies the credentials presented by the client.
+//
+// Authenticate returns false if the client didn't present the credentials of the authenticator (or they are not
+// acceptable), and an error if the credentials are invalid.
+type ClientAuthenticator interface {
+	Authenticate(ctx context.Context) (ClientIdentity, bool, error)
+}
+
+// MTLSAuthenticator accepts the verified client certificates with the SAN matching one of the patterns.
+//
+// The patterns are matched with path.Match against the URI SANs (e.g. "spiffe://example.org/ns/*/sa/*"),
+// the DNS SANs and the email SANs of the leaf certificate, in that order; the first matching SAN is the principal.
+// The server should verify the client certificates (tls.RequireAndVerifyClientCert), the unverified chains
+// are not accepted.
+type MTLSAuthenticator struct {
+	SANPatterns []string
+}
+
+// Authenticate implements ClientAuthenticator.
+func (a *MTLSAuthenticator) Authenticate(ctx context.Context) (ClientIdentity, bool, error) {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return ClientIdentity{}, false, nil
+	}
+
+	tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(tlsInfo.State.VerifiedChains) == 0 || len(tlsInfo.State.VerifiedChains[0]) == 0 {
+		return ClientIdentity{}, false, nil
+	}
+
+	for _, san := range certificateSANs(tlsInfo.State.VerifiedChains[0][0]) {
+		for _, pattern := range a.SANPatterns {
+			if matched, _ := path.Match(pattern, san); matched { //nolint:errcheck // invalid patterns never match
+				return ClientIdentity{Mechanism: ClientAuthMTLS, Principal: san}, true, nil
+			}
+		}
+	}
+
+	return ClientIdentity{}, false, nil
+}
+
+// certificateSANs lists the SANs of the certificate.
+func certificateSANs(cert *x509.Certificate) []string {
+	sans := make([]string, 0, len(cert.URIs)+len(cert.DNSNames)+len(cert.EmailAddresses))
+
+	for _, uri := range cert.URIs {
+		sans = append(sans, uri.String())
+	}
+
+	sans = append(sans, cert.DNSNames...)
+
+	return append(sans, cert.EmailAddresses...)
+}
+
+// ALTSAuthenticator accepts the ALTS peers with the service account matching one of the patterns (path.Match).
+type ALTSAuthenticator struct {
+	ServiceAccounts []string
+}
+
+// Authenticate implements ClientAuthenticator.
+func (a *ALTSAuthenticator) Authenticate(ctx context.Context) (ClientIdentity, bool, error) {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return ClientIdentity{}, false, nil
+	}
+
+	// alts.AuthInfo, without depending on the ALTS implementation
+	altsInfo, ok := p.AuthInfo.(interface{ PeerServiceAccount() string })
+	if !ok {
+		return ClientIdentity{}, false, nil
+	}
+
+	account := altsInfo.PeerServiceAccount()
+
+	for _, pattern := range a.ServiceAccounts {
+		if matched, _ := path.Match(pattern, account); matched { //nolint:errcheck // invalid patterns never match
+			return ClientIdentity{Mechanism: ClientAuthALTS, Principal: account}, true, nil
+		}
+	}
+
+	return ClientIdentity{}, false, nil
+}
+
+// TokenAuthenticator accepts the tokens validated by the JWTValidator, the principal is the value of the claim
+// (default "sub").
+type TokenAuthenticator struct {
+	Validator *JWTValidator
+	Claim     string
+}
+
+// Authenticate implements ClientAuthenticator.
+func (a *TokenAuthenticator) Authenticate(ctx context.Context) (ClientIdentity, bool, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	token, ok := a.Validator.token(md)
+	if !ok {
+		return ClientIdentity{}, false, nil
+	}
+
+	claims, err := a.Validator.Validate(ctx, token)
+	if err != nil {
+		return ClientIdentity{}, false, err
+	}
+
+	claim := a.Claim
+	if claim == "" {
+		claim = "sub"
+	}
+
+	principal := claims.String(claim)
+	if principal == "" {
+		return ClientIdentity{}, false, nil
+	}
+
+	return ClientIdentity{Mechanism: ClientAuthToken, Principal: principal}, true, nil
+}
+
+// ClientAuthPolicy configures the client authentication.
+type ClientAuthPolicy struct {
+	// Authenticators are tried in order, the first one accepting the client credentials wins.
+	Authenticators []ClientAuthenticator
+	// Optional allows the calls without acceptable credentials, the backends receive no identity for them.
+	Optional bool
+}
+
+// WithClientAuth verifies the credentials presented by the client (mTLS certificate, ALTS peer, token), and maps
+// the verified identity into the canonical ClientIdentityMetadataKey and ClientAuthMechanismMetadataKey metadata
+// for the backends.
+//
+// The canonical keys sent by the client are always dropped, so the backends can trust them. Calls without
+// acceptable credentials are rejected with codes.Unauthenticated before the director is invoked (unless the policy
+// is optional), as well as the calls with invalid credentials. The identity is available to the director via
+// ClientIdentityFromContext.
+func WithClientAuth(policy ClientAuthPolicy) Option {
+	return func(o *handlerOptions) {
+		if len(policy.Authenticators) == 0 {
+			o.invalid("client authentication policy has no authenticators")
+		}
+
+		o.clientAuth = &policy
+	}
+}
+
+// authenticateClient verifies the client and returns the server stream with the identity metadata.
+func (o *handlerOptions) authenticateClient(serverStream grpc.ServerStream) (grpc.ServerStream, error) {
+	ctx := serverStream.Context()
+
+	var (
+		identity      ClientIdentity
+		authenticated bool
+	)
+
+	for _, authenticator := range o.clientAuth.Authenticators {
+		var err error
+
+		identity, authenticated, err = authenticator.Authenticate(ctx)
+		if err != nil {
+			return nil, newError(ErrUnauthenticated, "invalid credentials: %v", err)
+		}
+
+		if authenticated {
+			break
+		}
+	}
+
+	if !authenticated && !o.clientAuth.Optional {
+		return nil, newError(ErrUnauthenticated, "no acceptable credentials")
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	md = md.Copy()
+
+	delete(md, ClientIdentityMetadataKey)
+	delete(md, ClientAuthMechanismMetadataKey)
+
+	if authenticated {
+		md.Set(ClientIdentityMetadataKey, identity.Principal)
+		md.Set(ClientAuthMechanismMetadataKey, identity.Mechanism)
+
+		ctx = context.WithValue(ctx, clientIdentityKey{}, identity)
+	}
+
+	return &contextServerStream{ServerStream: serverStream, ctx: metadata.NewIncomingContext(ctx, md)}, nil
+}
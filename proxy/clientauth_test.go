@@ -0,0 +1,162 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/noncepad/grpc-proxy/proxy"
+	pb "github.com/noncepad/grpc-proxy/testservice"
+)
+
+type testALTSInfo struct {
+	credentials.CommonAuthInfo
+
+	account string
+}
+
+func (testALTSInfo) AuthType() string { return "alts" }
+
+func (i testALTSInfo) PeerServiceAccount() string { return i.account }
+
+func TestClientAuthenticators(t *testing.T) {
+	spiffeID, err := url.Parse("spiffe://example.org/ns/prod/sa/billing")
+	require.NoError(t, err)
+
+	cert := &x509.Certificate{URIs: []*url.URL{spiffeID}, DNSNames: []string{"billing.example.org"}}
+	mtlsCtx := peer.NewContext(context.Background(), &peer.Peer{
+		AuthInfo: credentials.TLSInfo{State: tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}}},
+	})
+
+	identity, ok, err := (&proxy.MTLSAuthenticator{SANPatterns: []string{"spiffe://example.org/ns/*/sa/*"}}).Authenticate(mtlsCtx)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, proxy.ClientIdentity{Mechanism: proxy.ClientAuthMTLS, Principal: "spiffe://example.org/ns/prod/sa/billing"}, identity)
+
+	identity, ok, err = (&proxy.MTLSAuthenticator{SANPatterns: []string{"*.example.org"}}).Authenticate(mtlsCtx)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "billing.example.org", identity.Principal)
+
+	_, ok, err = (&proxy.MTLSAuthenticator{SANPatterns: []string{"spiffe://other.org/*"}}).Authenticate(mtlsCtx)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	unverifiedCtx := peer.NewContext(context.Background(), &peer.Peer{
+		AuthInfo: credentials.TLSInfo{State: tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}}},
+	})
+
+	_, ok, err = (&proxy.MTLSAuthenticator{SANPatterns: []string{"*"}}).Authenticate(unverifiedCtx)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	altsCtx := peer.NewContext(context.Background(), &peer.Peer{AuthInfo: testALTSInfo{account: "billing@example.iam.gserviceaccount.com"}})
+
+	identity, ok, err = (&proxy.ALTSAuthenticator{ServiceAccounts: []string{"*@example.iam.gserviceaccount.com"}}).Authenticate(altsCtx)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, proxy.ClientIdentity{Mechanism: proxy.ClientAuthALTS, Principal: "billing@example.iam.gserviceaccount.com"}, identity)
+
+	_, ok, err = (&proxy.ALTSAuthenticator{ServiceAccounts: []string{"*"}}).Authenticate(mtlsCtx)
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestClientAuth(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	jwksServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{ //nolint:errcheck
+			"keys": []map[string]string{
+				{
+					"kty": "RSA",
+					"kid": "key1",
+					"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+					"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+				},
+			},
+		})
+	}))
+	t.Cleanup(jwksServer.Close)
+
+	var identities []proxy.ClientIdentity
+
+	newHarness := func(optional bool) *testHarness {
+		return newTestHarnessWithService(t, &metadataEchoService{}, func(backend proxy.Backend) proxy.StreamDirector {
+			return func(ctx context.Context, fullMethodName string) (proxy.Mode, []proxy.Backend, error) {
+				if identity, ok := proxy.ClientIdentityFromContext(ctx); ok {
+					identities = append(identities, identity)
+				}
+
+				return proxy.One2One, []proxy.Backend{backend}, nil
+			}
+		}, proxy.WithClientAuth(proxy.ClientAuthPolicy{
+			Authenticators: []proxy.ClientAuthenticator{
+				&proxy.MTLSAuthenticator{SANPatterns: []string{"spiffe://example.org/*"}},
+				&proxy.TokenAuthenticator{Validator: &proxy.JWTValidator{Keys: &proxy.JWKS{URL: jwksServer.URL}, Issuer: "test"}},
+			},
+			Optional: optional,
+		}))
+	}
+
+	keys := proxy.ClientIdentityMetadataKey + "," + proxy.ClientAuthMechanismMetadataKey
+	exp := time.Now().Add(time.Hour).Unix()
+
+	call := func(h *testHarness, pairs ...string) (string, error) {
+		ctx := metadata.AppendToOutgoingContext(testContext(t), append(pairs, proxy.ClientIdentityMetadataKey, "spoofed")...)
+
+		stream, err := h.client.PingStream(ctx)
+		require.NoError(t, err)
+
+		require.NoError(t, stream.Send(&pb.PingRequest{Value: keys}))
+
+		resp, err := stream.Recv()
+		if err != nil {
+			return "", err
+		}
+
+		require.NoError(t, stream.CloseSend())
+
+		return resp.Value, nil
+	}
+
+	h := newHarness(false)
+
+	value, err := call(h, "authorization", "Bearer "+signTestJWT(t, key, "key1", map[string]interface{}{"iss": "test", "sub": "alice", "exp": exp}))
+	require.NoError(t, err)
+	assert.Equal(t, proxy.ClientIdentityMetadataKey+"=alice,"+proxy.ClientAuthMechanismMetadataKey+"=token", value)
+	assert.Equal(t, []proxy.ClientIdentity{{Mechanism: proxy.ClientAuthToken, Principal: "alice"}}, identities)
+
+	_, err = call(h, "authorization", "Bearer "+signTestJWT(t, key, "key1", map[string]interface{}{"iss": "other", "sub": "alice", "exp": exp}))
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	_, err = call(h)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	value, err = call(newHarness(true))
+	require.NoError(t, err)
+	assert.Equal(t, proxy.ClientIdentityMetadataKey+"=,"+proxy.ClientAuthMechanismMetadataKey+"=", value)
+	assert.Len(t, identities, 1)
+}
@@ -0,0 +1,259 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// defaultTombstoneTTL is the time the deleted entries are kept to propagate the deletion to the peers.
+const defaultTombstoneTTL = 10 * time.Minute
+
+// ClusterEntry is the versioned entry of the ClusterState.
+type ClusterEntry struct {
+	Key   string `json:"key"`
+	Value string `json:"value,omitempty"`
+	// Version orders the writes of the key, the write with the higher version (then the higher Node) wins.
+	Version int64  `json:"version"`
+	Node    string `json:"node"`
+	// Expires is the expiration time of the entry in unix nanoseconds, zero means never.
+	Expires int64 `json:"expires,omitempty"`
+	Deleted bool  `json:"deleted,omitempty"`
+}
+
+func (e ClusterEntry) newer(other ClusterEntry) bool {
+	if e.Version != other.Version {
+		return e.Version > other.Version
+	}
+
+	return e.Node > other.Node
+}
+
+func (e ClusterEntry) expired(now time.Time) bool {
+	return e.Expires != 0 && now.UnixNano() >= e.Expires
+}
+
+// ClusterState is the routing state shared by the fleet of the proxy replicas, so that the client gets consistent
+// routing when the load balancer moves it between the replicas.
+//
+// ClusterState is a last-writer-wins replicated key-value map: the replicas exchange their entries with the peers
+// periodically (see Sync and Run), and the conflicting writes are resolved by the entry version, which is
+// the write time of the replica. The state is eventually consistent: the write becomes visible on the other
+// replicas after the next sync, so it suits the state which is expensive, but not fatal to get wrong
+// (e.g. the affinity mappings, see AffinityDirector).
+//
+// The keys are namespaced by the prefix of the feature using the state, e.g. "affinity/".
+//
+// ClusterState implements http.Handler which serves the sync protocol: GET returns the entries of the replica,
+// POST merges the entries of the request and returns the entries of the replica.
+type ClusterState struct {
+	node    string
+	entries map[string]ClusterEntry
+	version int64
+
+	mu sync.Mutex
+}
+
+// NewClusterState creates the empty state of the replica, node is the unique name of the replica.
+func NewClusterState(node string) *ClusterState {
+	return &ClusterState{
+		node:    node,
+		entries: map[string]ClusterEntry{},
+	}
+}
+
+// Get returns the value of the key.
+func (s *ClusterState) Get(key string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.entries[key]
+	if !ok || entry.Deleted || entry.expired(time.Now()) {
+		return "", false
+	}
+
+	return entry.Value, true
+}
+
+// Set sets the value of the key, ttl is the lifetime of the value, zero means forever.
+func (s *ClusterState) Set(key, value string, ttl time.Duration) {
+	s.write(ClusterEntry{Key: key, Value: value}, ttl)
+}
+
+// Delete deletes the key.
+func (s *ClusterState) Delete(key string) {
+	s.write(ClusterEntry{Key: key, Deleted: true}, defaultTombstoneTTL)
+}
+
+func (s *ClusterState) write(entry ClusterEntry, ttl time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+
+	entry.Version = s.nextVersionLocked(now)
+	entry.Node = s.node
+
+	if ttl > 0 {
+		entry.Expires = now.Add(ttl).UnixNano()
+	}
+
+	s.entries[entry.Key] = entry
+}
+
+// nextVersionLocked returns the version of the local write, which is newer than any version seen by the replica.
+func (s *ClusterState) nextVersionLocked(now time.Time) int64 {
+	version := now.UnixNano()
+	if version <= s.version {
+		version = s.version + 1
+	}
+
+	s.version = version
+
+	return version
+}
+
+// Entries returns the entries of the replica, including the deleted ones, sorted by key.
+//
+// The expired entries are removed.
+func (s *ClusterState) Entries() []ClusterEntry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	entries := make([]ClusterEntry, 0, len(s.entries))
+
+	for key, entry := range s.entries {
+		if entry.expired(now) {
+			delete(s.entries, key)
+
+			continue
+		}
+
+		entries = append(entries, entry)
+	}
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Key < entries[j].Key })
+
+	return entries
+}
+
+// Merge merges the entries received from the peer, it returns the number of the entries updated.
+func (s *ClusterState) Merge(entries []ClusterEntry) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	updated := 0
+
+	for _, entry := range entries {
+		if entry.expired(now) {
+			continue
+		}
+
+		if entry.Version > s.version {
+			s.version = entry.Version
+		}
+
+		if current, ok := s.entries[entry.Key]; ok && !entry.newer(current) {
+			continue
+		}
+
+		s.entries[entry.Key] = entry
+		updated++
+	}
+
+	return updated
+}
+
+// ServeHTTP implements http.Handler.
+func (s *ClusterState) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPost:
+		var entries []ClusterEntry
+
+		if err := json.NewDecoder(r.Body).Decode(&entries); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+
+			return
+		}
+
+		s.Merge(entries)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	json.NewEncoder(w).Encode(s.Entries()) //nolint:errcheck
+}
+
+// Sync exchanges the entries with the peer serving the ClusterState at the URL.
+func (s *ClusterState) Sync(ctx context.Context, client *http.Client, url string) error {
+	body, err := json.Marshal(s.Entries())
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close() //nolint:errcheck
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("error syncing with %s: %s", url, resp.Status)
+	}
+
+	var entries []ClusterEntry
+
+	if err = json.NewDecoder(resp.Body).Decode(&entries); err != nil {
+		return err
+	}
+
+	s.Merge(entries)
+
+	return nil
+}
+
+// Run syncs with the peers every interval until the context is canceled.
+//
+// The peers are looked up on each sync, so the peer list can follow the fleet membership. The errors of the sync
+// are passed to onError, if set.
+func (s *ClusterState) Run(ctx context.Context, client *http.Client, peers func() []string, interval time.Duration, onError func(peer string, err error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		for _, peer := range peers() {
+			if err := s.Sync(ctx, client, peer); err != nil && onError != nil {
+				onError(peer, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,116 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/noncepad/grpc-proxy/proxy"
+)
+
+func TestClusterStateSync(t *testing.T) {
+	a := proxy.NewClusterState("a")
+	b := proxy.NewClusterState("b")
+
+	server := httptest.NewServer(b)
+	defer server.Close()
+
+	a.Set("affinity/x", "backend1", 0)
+	a.Set("affinity/gone", "backend1", 0)
+	a.Set("affinity/expired", "backend1", time.Millisecond)
+	b.Set("affinity/y", "backend2", 0)
+
+	time.Sleep(5 * time.Millisecond)
+
+	ctx := context.Background()
+
+	require.NoError(t, a.Sync(ctx, server.Client(), server.URL))
+
+	for _, state := range []*proxy.ClusterState{a, b} {
+		value, ok := state.Get("affinity/x")
+		assert.True(t, ok)
+		assert.Equal(t, "backend1", value)
+
+		value, ok = state.Get("affinity/y")
+		assert.True(t, ok)
+		assert.Equal(t, "backend2", value)
+
+		_, ok = state.Get("affinity/expired")
+		assert.False(t, ok)
+	}
+
+	// the later write wins, the deletion is propagated
+	b.Set("affinity/x", "backend3", 0)
+	a.Delete("affinity/gone")
+
+	require.NoError(t, a.Sync(ctx, server.Client(), server.URL))
+
+	for _, state := range []*proxy.ClusterState{a, b} {
+		value, ok := state.Get("affinity/x")
+		assert.True(t, ok)
+		assert.Equal(t, "backend3", value)
+
+		_, ok = state.Get("affinity/gone")
+		assert.False(t, ok)
+	}
+
+	assert.Len(t, b.Entries(), 3)
+
+	// stale entries are ignored
+	stale := a.Entries()[2]
+	stale.Value, stale.Version = "stale", 1
+	assert.Zero(t, b.Merge([]proxy.ClusterEntry{stale}))
+
+	resp, err := server.Client().Get(server.URL)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	require.NoError(t, resp.Body.Close())
+}
+
+func TestAffinityDirector(t *testing.T) {
+	backends := []proxy.Backend{
+		&proxy.DialBackend{Target: "backend1"},
+		&proxy.DialBackend{Target: "backend2"},
+	}
+
+	// each replica orders the backends differently
+	director := func(reverse bool) proxy.StreamDirector {
+		return func(ctx context.Context, fullMethodName string) (proxy.Mode, []proxy.Backend, error) {
+			if reverse {
+				return proxy.One2One, []proxy.Backend{backends[1], backends[0]}, nil
+			}
+
+			return proxy.One2One, backends, nil
+		}
+	}
+
+	key := func(ctx context.Context, fullMethodName string) string { return "client" }
+
+	a := proxy.NewClusterState("a")
+	b := proxy.NewClusterState("b")
+
+	directorA := proxy.AffinityDirector(director(false), a, key, time.Minute)
+	directorB := proxy.AffinityDirector(director(true), b, key, time.Minute)
+
+	ctx := context.Background()
+
+	_, selected, err := directorA(ctx, "/svc/Method")
+	require.NoError(t, err)
+	require.Len(t, selected, 1)
+	assert.Same(t, backends[0], selected[0])
+
+	assert.Equal(t, 1, b.Merge(a.Entries()))
+
+	_, selected, err = directorB(ctx, "/svc/Method")
+	require.NoError(t, err)
+	require.Len(t, selected, 1)
+	assert.Same(t, backends[0], selected[0])
+}
@@ -0,0 +1,73 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// concurrencyGate limits number of concurrent calls.
+type concurrencyGate struct {
+	slots        chan struct{}
+	queueTimeout time.Duration
+}
+
+// WithMethodConcurrencyLimit limits the number of in-flight calls to the method.
+//
+// Calls over the limit wait for a free slot up to queueTimeout (zero means wait as long as the call context allows),
+// and fail with codes.ResourceExhausted if no slot was freed up. The limit is enforced before the director is invoked.
+//
+// The limit is shared by all handlers configured with the same Option value, so passing the same Option
+// to several handlers (e.g. TransparentHandler and RegisterService) enforces the limit across the whole proxy.
+func WithMethodConcurrencyLimit(fullMethodName string, limit int, queueTimeout time.Duration) Option {
+	gate := &concurrencyGate{
+		slots:        make(chan struct{}, limit),
+		queueTimeout: queueTimeout,
+	}
+
+	return func(o *handlerOptions) {
+		if limit <= 0 {
+			o.invalid("concurrency limit of %s should be positive, got %d", fullMethodName, limit)
+		}
+
+		if o.concurrencyGates == nil {
+			o.concurrencyGates = map[string]*concurrencyGate{}
+		}
+
+		o.concurrencyGates[fullMethodName] = gate
+	}
+}
+
+// acquire waits for a free slot, it returns a function to release the slot.
+func (gate *concurrencyGate) acquire(ctx context.Context, fullMethodName string) (func(), error) {
+	release := func() { <-gate.slots }
+
+	select {
+	case gate.slots <- struct{}{}:
+		return release, nil
+	default:
+	}
+
+	if gate.queueTimeout > 0 {
+		var cancel context.CancelFunc
+
+		ctx, cancel = context.WithTimeout(ctx, gate.queueTimeout)
+		defer cancel()
+	}
+
+	select {
+	case gate.slots <- struct{}{}:
+		return release, nil
+	case <-ctx.Done():
+		if ctxErr := status.FromContextError(ctx.Err()); ctxErr.Code() == codes.Canceled {
+			return nil, ctxErr.Err()
+		}
+
+		return nil, newError(ErrConcurrencyLimit, "too many concurrent calls to %s", fullMethodName)
+	}
+}
@@ -0,0 +1,63 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy_test
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/noncepad/grpc-proxy/proxy"
+	pb "github.com/noncepad/grpc-proxy/testservice"
+)
+
+func TestMethodConcurrencyLimit(t *testing.T) {
+	h := newTestHarness(t, one2oneDirector,
+		proxy.WithMethodConcurrencyLimit("/talos.testproto.TestService/PingStream", 1, 50*time.Millisecond))
+
+	ctx := testContext(t)
+
+	stream1, err := h.client.PingStream(ctx)
+	require.NoError(t, err)
+
+	require.NoError(t, stream1.Send(&pb.PingRequest{Value: "foo"}))
+
+	_, err = stream1.Recv()
+	require.NoError(t, err)
+
+	stream2, err := h.client.PingStream(ctx)
+	require.NoError(t, err)
+
+	_, err = stream2.Recv()
+	require.Error(t, err)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	// other methods are not limited
+	_, err = h.client.Ping(ctx, &pb.PingRequest{Value: "foo"})
+	require.NoError(t, err)
+
+	require.NoError(t, stream1.CloseSend())
+
+	_, err = stream1.Recv()
+	require.ErrorIs(t, err, io.EOF)
+
+	// slot is released
+	stream3, err := h.client.PingStream(ctx)
+	require.NoError(t, err)
+
+	require.NoError(t, stream3.Send(&pb.PingRequest{Value: "foo"}))
+
+	_, err = stream3.Recv()
+	require.NoError(t, err)
+
+	require.NoError(t, stream3.CloseSend())
+
+	_, err = stream3.Recv()
+	require.ErrorIs(t, err, io.EOF)
+}
@@ -0,0 +1,68 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy
+
+import (
+	"math/rand"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/keepalive"
+)
+
+// ConnectionAgePolicy limits the age of the client connections to the proxy.
+//
+// Clients keep the long-lived connections to the proxy instance they connected to first, so the load doesn't
+// rebalance when the proxy fleet is scaled out. Once the connection reaches the maximum age, the proxy sends GOAWAY:
+// the clients open new connections for the new calls (which the load balancer spreads across the fleet), while
+// the calls in flight continue on the old connection until they finish or the grace period passes.
+type ConnectionAgePolicy struct {
+	// MaxAge is the maximum age of the connection.
+	MaxAge time.Duration
+	// Jitter randomizes MaxAge of the proxy instance by up to the fraction (e.g. 0.1 for ±10%), so that the
+	// instances started at the same time don't send GOAWAY to all their clients at once.
+	//
+	// grpc also randomizes the age of each connection by ±10%.
+	Jitter float64
+	// Grace is the time the calls in flight are allowed to run after GOAWAY before the connection is closed.
+	//
+	// Zero means no limit, so the proxied streams are never cut mid-flight.
+	Grace time.Duration
+	// Keepalive configures the rest of the keepalive parameters of the server, as grpc accepts only one
+	// grpc.KeepaliveParams option. MaxConnectionAge and MaxConnectionAgeGrace are overwritten by the policy.
+	Keepalive keepalive.ServerParameters
+}
+
+// ServerOptions returns the options for the grpc server of the proxy.
+func (p ConnectionAgePolicy) ServerOptions() []grpc.ServerOption {
+	if p.MaxAge <= 0 {
+		return nil
+	}
+
+	params := p.Keepalive
+	params.MaxConnectionAge = p.maxAge()
+	params.MaxConnectionAgeGrace = p.Grace
+
+	return []grpc.ServerOption{grpc.KeepaliveParams(params)}
+}
+
+// maxAge applies the jitter to MaxAge.
+func (p ConnectionAgePolicy) maxAge() time.Duration {
+	if p.Jitter <= 0 {
+		return p.MaxAge
+	}
+
+	jitter := p.Jitter
+	if jitter > 1 {
+		jitter = 1
+	}
+
+	//nolint:gosec // jitter doesn't need a secure random source
+	maxAge := time.Duration(float64(p.MaxAge) * (1 + jitter*(2*rand.Float64()-1)))
+	if maxAge <= 0 {
+		return p.MaxAge
+	}
+
+	return maxAge
+}
@@ -0,0 +1,102 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy_test
+
+import (
+	"context"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/stats"
+
+	"github.com/noncepad/grpc-proxy/proxy"
+	pb "github.com/noncepad/grpc-proxy/testservice"
+)
+
+// connCounter counts the connections accepted by the server.
+type connCounter struct {
+	conns int32
+}
+
+func (c *connCounter) TagRPC(ctx context.Context, _ *stats.RPCTagInfo) context.Context { return ctx }
+
+func (c *connCounter) HandleRPC(context.Context, stats.RPCStats) {}
+
+func (c *connCounter) TagConn(ctx context.Context, _ *stats.ConnTagInfo) context.Context { return ctx }
+
+func (c *connCounter) HandleConn(_ context.Context, s stats.ConnStats) {
+	if _, ok := s.(*stats.ConnBegin); ok {
+		atomic.AddInt32(&c.conns, 1)
+	}
+}
+
+func TestConnectionAgePolicy(t *testing.T) {
+	assert.Empty(t, proxy.ConnectionAgePolicy{}.ServerOptions())
+
+	h := newTestHarness(t, one2oneDirector)
+	defer h.stop()
+
+	backend := &proxy.SingleBackend{
+		GetConn: func(ctx context.Context) (context.Context, *grpc.ClientConn, error) {
+			return ctx, h.backendConn, nil
+		},
+	}
+
+	counter := &connCounter{}
+
+	policy := proxy.ConnectionAgePolicy{MaxAge: 200 * time.Millisecond, Jitter: 0.1}
+
+	handler, err := proxy.NewTransparentHandler(one2oneDirector(backend))
+	require.NoError(t, err)
+
+	server := grpc.NewServer(append(policy.ServerOptions(),
+		grpc.CustomCodec(proxy.Codec()), //nolint: staticcheck
+		grpc.StatsHandler(counter),
+		grpc.UnknownServiceHandler(handler),
+	)...)
+	defer server.Stop()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	go server.Serve(listener) //nolint: errcheck
+
+	conn, err := grpc.Dial(listener.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+
+	defer conn.Close() //nolint: errcheck
+
+	client := pb.NewTestServiceClient(conn)
+
+	stream, err := client.PingStream(testContext(t))
+	require.NoError(t, err)
+
+	require.NoError(t, stream.Send(&pb.PingRequest{Value: "foo"}))
+
+	_, err = stream.Recv()
+	require.NoError(t, err)
+
+	time.Sleep(500 * time.Millisecond)
+
+	// the stream in flight survives GOAWAY
+	require.NoError(t, stream.Send(&pb.PingRequest{Value: "bar"}))
+
+	resp, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, "bar", resp.Value)
+
+	require.NoError(t, stream.CloseSend())
+
+	// new calls go to the new connection
+	_, err = client.Ping(testContext(t), &pb.PingRequest{Value: "foo"})
+	require.NoError(t, err)
+
+	assert.GreaterOrEqual(t, atomic.LoadInt32(&counter.conns), int32(2))
+}
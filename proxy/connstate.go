@@ -0,0 +1,147 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+)
+
+// connStateBuffer is the capacity of the channels returned by ConnPool.WatchState.
+const connStateBuffer = 64
+
+// ConnStateEvent is the change of the connectivity state of the pooled connection.
+type ConnStateEvent struct {
+	Time     time.Time
+	Target   string
+	Identity DialIdentity
+	// Addr is the resolved address of the target.
+	Addr     string
+	State    connectivity.State
+	Previous connectivity.State
+}
+
+// WatchState returns a channel which receives the connectivity state changes of the pooled connections
+// (e.g. READY, TRANSIENT_FAILURE), so that the director can react to the upstream failures immediately instead
+// of discovering them per call.
+//
+// The connection reports SHUTDOWN once it is closed (by Close, or once the calls in flight finish after the target
+// is re-resolved to a different address or the connection with the identity override is idle). If the reader is slow, the oldest events are dropped, see State for the current state.
+// The channel is closed when the context is canceled.
+func (p *ConnPool) WatchState(ctx context.Context) <-chan ConnStateEvent {
+	ch := make(chan ConnStateEvent, connStateBuffer)
+
+	p.watchMu.Lock()
+
+	if p.stateWatchers == nil {
+		p.stateWatchers = map[chan ConnStateEvent]struct{}{}
+	}
+
+	p.stateWatchers[ch] = struct{}{}
+	p.watchMu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+
+		p.watchMu.Lock()
+		delete(p.stateWatchers, ch)
+		close(ch)
+		p.watchMu.Unlock()
+	}()
+
+	return ch
+}
+
+// State returns the connectivity state of the pooled connection to the target (with the default identity),
+// ok is false if there is no connection to the target in the pool.
+func (p *ConnPool) State(target string) (state connectivity.State, ok bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	pooled, ok := p.conns[target]
+	if !ok {
+		return 0, false
+	}
+
+	return pooled.conn.GetState(), true
+}
+
+// ReadyFilter wraps the director to skip the backends of the pool whose connection is in TRANSIENT_FAILURE.
+//
+// The backends are matched to the pool targets by String() (as DialBackend is), the backends which are not connected
+// via the pool are kept. If all the backends are failing, they are returned as is, so that the call fails with
+// the error of the backend.
+func (p *ConnPool) ReadyFilter(director StreamDirector) StreamDirector {
+	return func(ctx context.Context, fullMethodName string) (Mode, []Backend, error) {
+		mode, backends, err := director(ctx, fullMethodName)
+		if err != nil || len(backends) == 0 {
+			return mode, backends, err
+		}
+
+		filtered := make([]Backend, 0, len(backends))
+
+		for _, backend := range backends {
+			if state, ok := p.State(backend.String()); ok && state == connectivity.TransientFailure {
+				continue
+			}
+
+			filtered = append(filtered, backend)
+		}
+
+		if len(filtered) == 0 {
+			return mode, backends, nil
+		}
+
+		return mode, filtered, nil
+	}
+}
+
+// watchState reports the state changes of the connection until it is shut down.
+//
+// The connection starts connecting once dialed, so the transitions which happen before the watch starts are reported
+// as a single change from IDLE.
+func (p *ConnPool) watchState(target string, identity DialIdentity, addr string, conn *grpc.ClientConn) {
+	state := connectivity.Idle
+
+	for {
+		if current := conn.GetState(); current != state {
+			p.emitState(ConnStateEvent{
+				Time:     time.Now(),
+				Target:   target,
+				Identity: identity,
+				Addr:     addr,
+				State:    current,
+				Previous: state,
+			})
+
+			state = current
+		}
+
+		if state == connectivity.Shutdown || !conn.WaitForStateChange(context.Background(), state) {
+			return
+		}
+	}
+}
+
+func (p *ConnPool) emitState(event ConnStateEvent) {
+	p.watchMu.Lock()
+	defer p.watchMu.Unlock()
+
+	for ch := range p.stateWatchers {
+		select {
+		case ch <- event:
+		default:
+			// drop the oldest event to make room
+			select {
+			case <-ch:
+			default:
+			}
+
+			ch <- event
+		}
+	}
+}
@@ -0,0 +1,86 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy_test
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"github.com/noncepad/grpc-proxy/proxy"
+)
+
+// waitStates reads the events until each target reaches its state, returning the last events of the targets.
+func waitStates(t *testing.T, events <-chan proxy.ConnStateEvent, states map[string]connectivity.State) map[string]proxy.ConnStateEvent {
+	t.Helper()
+
+	timeout := time.After(10 * time.Second)
+	reached := map[string]proxy.ConnStateEvent{}
+
+	for len(reached) < len(states) {
+		select {
+		case event := <-events:
+			if state, ok := states[event.Target]; ok && event.State == state {
+				reached[event.Target] = event
+			}
+		case <-timeout:
+			require.FailNow(t, "timeout waiting for the states", "%v", states)
+		}
+	}
+
+	return reached
+}
+
+func TestConnPoolStateEvents(t *testing.T) {
+	pool := proxy.NewConnPool(grpc.WithTransportCredentials(insecure.NewCredentials()))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	events := pool.WatchState(ctx)
+
+	// the port which is not listened on anymore
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	deadTarget := lis.Addr().String()
+	require.NoError(t, lis.Close())
+
+	h := newTestHarness(t, one2oneDirector)
+
+	live, dead := &proxy.DialBackend{Pool: pool, Target: h.backendAddr}, &proxy.DialBackend{Pool: pool, Target: deadTarget}
+
+	for _, backend := range []*proxy.DialBackend{live, dead} {
+		_, conn, err := backend.GetConnection(ctx, "/talos.testproto.TestService/Ping")
+		require.NoError(t, err)
+
+		conn.Connect()
+	}
+
+	waitStates(t, events, map[string]connectivity.State{h.backendAddr: connectivity.Ready, deadTarget: connectivity.TransientFailure})
+
+	state, ok := pool.State(h.backendAddr)
+	assert.True(t, ok)
+	assert.Equal(t, connectivity.Ready, state)
+
+	director := pool.ReadyFilter(func(ctx context.Context, fullMethodName string) (proxy.Mode, []proxy.Backend, error) {
+		return proxy.One2Many, []proxy.Backend{dead, live}, nil
+	})
+
+	_, backends, err := director(ctx, "/talos.testproto.TestService/Ping")
+	require.NoError(t, err)
+	assert.Equal(t, []proxy.Backend{live}, backends)
+
+	require.NoError(t, pool.Close())
+
+	event := waitStates(t, events, map[string]connectivity.State{h.backendAddr: connectivity.Shutdown})[h.backendAddr]
+	assert.Equal(t, connectivity.Ready, event.Previous)
+}
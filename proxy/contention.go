@@ -0,0 +1,117 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"runtime/metrics"
+	"sync/atomic"
+	"time"
+)
+
+// lockContention counts contended acquisitions of the proxy locks on the hot path.
+var lockContention struct {
+	count     uint64
+	waitNanos uint64
+}
+
+func recordLockContention(wait time.Duration) {
+	atomic.AddUint64(&lockContention.count, 1)
+	atomic.AddUint64(&lockContention.waitNanos, uint64(wait))
+}
+
+// ContentionStats reports lock contention and scheduling latency, so that users can verify that the proxy
+// scales across cores.
+type ContentionStats struct {
+	// ProxyLockContentions is the number of times the proxy had to wait for the lock on the hot path
+	// (e.g. concurrent sends to the client in one2many mode).
+	ProxyLockContentions uint64 `json:"proxy_lock_contentions"`
+	// ProxyLockWait is the total time spent waiting for the proxy locks.
+	ProxyLockWait time.Duration `json:"proxy_lock_wait"`
+	// RuntimeMutexWait is the total time goroutines spent blocked on sync.Mutex and sync.RWMutex process-wide.
+	RuntimeMutexWait time.Duration `json:"runtime_mutex_wait"`
+	// SchedLatencyP50 and SchedLatencyP99 are percentiles of the time goroutines spent runnable before running.
+	SchedLatencyP50 time.Duration `json:"sched_latency_p50"`
+	SchedLatencyP99 time.Duration `json:"sched_latency_p99"`
+}
+
+// ReadContentionStats returns current contention statistics.
+//
+// Runtime statistics are zero if not supported by the Go runtime.
+func ReadContentionStats() ContentionStats {
+	stats := ContentionStats{
+		ProxyLockContentions: atomic.LoadUint64(&lockContention.count),
+		ProxyLockWait:        time.Duration(atomic.LoadUint64(&lockContention.waitNanos)),
+	}
+
+	samples := []metrics.Sample{
+		{Name: "/sync/mutex/wait/total:seconds"},
+		{Name: "/sched/latencies:seconds"},
+	}
+
+	metrics.Read(samples)
+
+	if samples[0].Value.Kind() == metrics.KindFloat64 {
+		stats.RuntimeMutexWait = secondsToDuration(samples[0].Value.Float64())
+	}
+
+	if samples[1].Value.Kind() == metrics.KindFloat64Histogram {
+		histogram := samples[1].Value.Float64Histogram()
+
+		stats.SchedLatencyP50 = secondsToDuration(histogramPercentile(histogram, 0.5))
+		stats.SchedLatencyP99 = secondsToDuration(histogramPercentile(histogram, 0.99))
+	}
+
+	return stats
+}
+
+// ContentionStatsHandler returns http.Handler which serves ContentionStats as JSON.
+func ContentionStatsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		json.NewEncoder(w).Encode(ReadContentionStats()) //nolint:errcheck
+	})
+}
+
+func secondsToDuration(seconds float64) time.Duration {
+	if math.IsInf(seconds, 0) || math.IsNaN(seconds) {
+		return 0
+	}
+
+	return time.Duration(seconds * float64(time.Second))
+}
+
+// histogramPercentile returns the upper bound of the bucket containing the percentile.
+func histogramPercentile(histogram *metrics.Float64Histogram, percentile float64) float64 {
+	var total uint64
+
+	for _, count := range histogram.Counts {
+		total += count
+	}
+
+	if total == 0 {
+		return 0
+	}
+
+	threshold := uint64(math.Ceil(float64(total) * percentile))
+
+	var cumulative uint64
+
+	for i, count := range histogram.Counts {
+		cumulative += count
+
+		if cumulative >= threshold {
+			if upper := histogram.Buckets[i+1]; !math.IsInf(upper, 1) {
+				return upper
+			}
+
+			return histogram.Buckets[i]
+		}
+	}
+
+	return histogram.Buckets[len(histogram.Buckets)-1]
+}
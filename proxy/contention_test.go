@@ -0,0 +1,166 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	"github.com/noncepad/grpc-proxy/proxy"
+	pb "github.com/noncepad/grpc-proxy/testservice"
+)
+
+// discardServerStream discards all sent messages.
+type discardServerStream struct {
+	grpc.ServerStream
+}
+
+func (s *discardServerStream) SendMsg(m interface{}) error {
+	return nil
+}
+
+func TestContentionStats(t *testing.T) {
+	before := proxy.ReadContentionStats()
+
+	wrapper := &proxy.ServerStreamWrapper{ServerStream: &discardServerStream{}}
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for j := 0; j < 1000; j++ {
+				assert.NoError(t, wrapper.SendMsg(proxy.NewFrame(nil)))
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	after := proxy.ReadContentionStats()
+	assert.GreaterOrEqual(t, after.ProxyLockContentions, before.ProxyLockContentions)
+	assert.GreaterOrEqual(t, after.ProxyLockWait, before.ProxyLockWait)
+
+	rec := httptest.NewRecorder()
+	proxy.ContentionStatsHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/contention", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "proxy_lock_contentions")
+}
+
+// BenchmarkOne2OneConcurrentStreams proxies pings over 1k concurrent streams.
+func BenchmarkOne2OneConcurrentStreams(b *testing.B) {
+	const streams = 1000
+
+	h := newTestHarnessWithService(b, &lenientService{}, one2oneDirector)
+
+	ctx := testContext(b)
+
+	clients := make([]pb.TestService_PingStreamClient, streams)
+
+	for i := range clients {
+		var err error
+
+		clients[i], err = h.client.PingStream(ctx)
+		require.NoError(b, err)
+	}
+
+	before := proxy.ReadContentionStats()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	var wg sync.WaitGroup
+
+	for i := range clients {
+		wg.Add(1)
+
+		go func(i int) {
+			defer wg.Done()
+
+			stream := clients[i]
+
+			for j := i; j < b.N; j += streams {
+				if err := stream.Send(&pb.PingRequest{Value: "foo"}); err != nil {
+					b.Error(err)
+
+					return
+				}
+
+				if _, err := stream.Recv(); err != nil {
+					b.Error(err)
+
+					return
+				}
+			}
+
+			stream.CloseSend() //nolint: errcheck
+
+			for {
+				if _, err := stream.Recv(); err != nil {
+					return
+				}
+			}
+		}(i)
+	}
+
+	wg.Wait()
+
+	b.StopTimer()
+
+	after := proxy.ReadContentionStats()
+
+	b.ReportMetric(float64(after.RuntimeMutexWait-before.RuntimeMutexWait)/float64(b.N), "mutex-wait-ns/op")
+	b.ReportMetric(float64(after.SchedLatencyP99), "sched-p99-ns")
+}
+
+// BenchmarkOne2OneConcurrentCalls proxies pings as unary calls from 1k concurrent clients, exercising the dispatch
+// of the calls (the director, the stream registry and the upstream stream setup).
+func BenchmarkOne2OneConcurrentCalls(b *testing.B) {
+	const clients = 1000
+
+	h := newTestHarnessWithService(b, &lenientService{}, one2oneDirector, proxy.WithStreamRegistry(proxy.NewStreamRegistry()))
+
+	ctx := testContext(b)
+
+	before := proxy.ReadContentionStats()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < clients; i++ {
+		wg.Add(1)
+
+		go func(i int) {
+			defer wg.Done()
+
+			for j := i; j < b.N; j += clients {
+				if _, err := h.client.Ping(ctx, &pb.PingRequest{Value: "foo"}); err != nil {
+					b.Error(err)
+
+					return
+				}
+			}
+		}(i)
+	}
+
+	wg.Wait()
+
+	b.StopTimer()
+
+	after := proxy.ReadContentionStats()
+
+	b.ReportMetric(float64(after.RuntimeMutexWait-before.RuntimeMutexWait)/float64(b.N), "mutex-wait-ns/op")
+	b.ReportMetric(float64(after.SchedLatencyP99), "sched-p99-ns")
+}
@@ -0,0 +1,192 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy
+
+import (
+	"context"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// DefaultCostTrailer is the default trailer key of the call cost.
+const DefaultCostTrailer = "x-proxy-cost"
+
+// Usage is the usage of the proxied call, the input of the cost computation.
+type Usage struct {
+	Method string `json:"method"`
+	// Backends are the backends selected by the director.
+	Backends []string `json:"backends,omitempty"`
+	// Tenant is the key of the tenant the call is billed to.
+	Tenant string `json:"tenant,omitempty"`
+	// BytesReceived is the size of the request messages received from the client.
+	BytesReceived uint64 `json:"bytesReceived"`
+	// BytesSent is the size of the response messages sent to the client.
+	BytesSent uint64        `json:"bytesSent"`
+	Duration  time.Duration `json:"duration"`
+	// Code is the gRPC status code of the call.
+	Code string `json:"code"`
+}
+
+// CostRecord is the metered call, exported to the CostSink.
+type CostRecord struct {
+	Time  time.Time `json:"time"`
+	Usage Usage     `json:"usage"`
+	Cost  float64   `json:"cost"`
+}
+
+// CostFunc computes the cost of the call from its usage.
+type CostFunc func(usage Usage) float64
+
+// CostSink receives the metered calls, e.g. to export them to the billing pipeline.
+//
+// Record is called synchronously at the end of each call, so it should not block.
+type CostSink interface {
+	Record(record CostRecord)
+}
+
+// CostSinkFunc is a function adapter for CostSink.
+type CostSinkFunc func(record CostRecord)
+
+// Record implements CostSink.
+func (f CostSinkFunc) Record(record CostRecord) {
+	f(record)
+}
+
+// CostPolicy configures the metering of the proxied calls.
+type CostPolicy struct {
+	Cost CostFunc
+	// Tenant returns the tenant key of the call (e.g. from the metadata or the JWT identity), it is called at the end
+	// of the call with the call context.
+	Tenant func(ctx context.Context) string
+	// Trailer is the trailer key the cost is attached to (default DefaultCostTrailer), "-" disables the trailer.
+	Trailer string
+	// Sink receives the metered calls, if set.
+	Sink CostSink
+}
+
+// WithCost meters the proxied calls: at the end of each call the cost is computed from the call usage (bytes
+// exchanged with the client, duration, method, backends and tenant), attached to the trailers and exported to the sink,
+// so that the proxy can be the metering point of the usage-billed API.
+//
+// The calls rejected before the director (e.g. by the allowlist or the rate limit) are metered too, with no backends.
+func WithCost(policy CostPolicy) Option {
+	return func(o *handlerOptions) {
+		if policy.Cost == nil {
+			o.cost = nil
+
+			return
+		}
+
+		if policy.Trailer == "" {
+			policy.Trailer = DefaultCostTrailer
+		}
+
+		o.cost = &policy
+	}
+}
+
+type costMeterKey struct{}
+
+// costMeter counts the usage of the call.
+type costMeter struct {
+	grpc.ServerStream
+
+	ctx     context.Context //nolint:containedctx
+	policy  *CostPolicy
+	method  string
+	start   time.Time
+	callCtx context.Context //nolint:containedctx
+
+	backends []string
+	received uint64
+	sent     uint64
+}
+
+func newCostMeter(policy *CostPolicy, serverStream grpc.ServerStream, fullMethodName string) *costMeter {
+	meter := &costMeter{
+		ServerStream: serverStream,
+		policy:       policy,
+		method:       fullMethodName,
+		start:        time.Now(),
+		callCtx:      serverStream.Context(),
+	}
+
+	meter.ctx = context.WithValue(serverStream.Context(), costMeterKey{}, meter)
+
+	return meter
+}
+
+// observeCost records the backends of the call, and the call context for the tenant lookup.
+func observeCost(ctx context.Context, backends []Backend) {
+	meter, ok := ctx.Value(costMeterKey{}).(*costMeter)
+	if !ok {
+		return
+	}
+
+	meter.callCtx = ctx
+	meter.backends = make([]string, 0, len(backends))
+
+	for _, backend := range backends {
+		meter.backends = append(meter.backends, backend.String())
+	}
+}
+
+func (m *costMeter) Context() context.Context {
+	return m.ctx
+}
+
+func (m *costMeter) RecvMsg(msg interface{}) error {
+	err := m.ServerStream.RecvMsg(msg)
+
+	if f, ok := msg.(*Frame); ok && err == nil {
+		atomic.AddUint64(&m.received, uint64(f.Size()))
+	}
+
+	return err
+}
+
+func (m *costMeter) SendMsg(msg interface{}) error {
+	err := m.ServerStream.SendMsg(msg)
+
+	if f, ok := msg.(*Frame); ok && err == nil {
+		atomic.AddUint64(&m.sent, uint64(f.Size()))
+	}
+
+	return err
+}
+
+// finish computes the cost of the call, attaches it to the trailers and exports it.
+func (m *costMeter) finish(err error) {
+	usage := Usage{
+		Method:        m.method,
+		Backends:      m.backends,
+		BytesReceived: atomic.LoadUint64(&m.received),
+		BytesSent:     atomic.LoadUint64(&m.sent),
+		Duration:      time.Since(m.start),
+		Code:          status.Convert(err).Code().String(),
+	}
+
+	if m.policy.Tenant != nil {
+		usage.Tenant = m.policy.Tenant(m.callCtx)
+	}
+
+	cost := m.policy.Cost(usage)
+
+	if m.policy.Trailer != "-" {
+		m.ServerStream.SetTrailer(metadata.Pairs(m.policy.Trailer, strconv.FormatFloat(cost, 'g', -1, 64)))
+	}
+
+	if m.policy.Sink != nil {
+		m.policy.Sink.Record(CostRecord{
+			Time:  time.Now(),
+			Usage: usage,
+			Cost:  cost,
+		})
+	}
+}
@@ -0,0 +1,83 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy_test
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/noncepad/grpc-proxy/proxy"
+	pb "github.com/noncepad/grpc-proxy/testservice"
+)
+
+func TestCost(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		records []proxy.CostRecord
+	)
+
+	h := newTestHarness(t, one2oneDirector,
+		proxy.WithMethodAllowlist("/talos.testproto.TestService/Ping"),
+		proxy.WithCost(proxy.CostPolicy{
+			Cost: func(usage proxy.Usage) float64 {
+				return 0.5 + float64(usage.BytesReceived+usage.BytesSent)
+			},
+			Tenant: func(ctx context.Context) string {
+				if values := metadata.ValueFromIncomingContext(ctx, "tenant"); len(values) > 0 {
+					return values[0]
+				}
+
+				return ""
+			},
+			Sink: proxy.CostSinkFunc(func(record proxy.CostRecord) {
+				mu.Lock()
+				defer mu.Unlock()
+
+				records = append(records, record)
+			}),
+		}))
+
+	ctx := metadata.AppendToOutgoingContext(testContext(t), "tenant", "acme")
+
+	var trailer metadata.MD
+
+	_, err := h.client.Ping(ctx, &pb.PingRequest{Value: "foo"}, grpc.Trailer(&trailer))
+	require.NoError(t, err)
+
+	mu.Lock()
+	require.Len(t, records, 1)
+	record := records[0]
+	mu.Unlock()
+
+	assert.Equal(t, "/talos.testproto.TestService/Ping", record.Usage.Method)
+	assert.Equal(t, "acme", record.Usage.Tenant)
+	assert.Len(t, record.Usage.Backends, 1)
+	assert.EqualValues(t, 5, record.Usage.BytesReceived)
+	assert.NotZero(t, record.Usage.BytesSent)
+	assert.NotZero(t, record.Usage.Duration)
+	assert.Equal(t, "OK", record.Usage.Code)
+	assert.Equal(t, 0.5+float64(record.Usage.BytesReceived+record.Usage.BytesSent), record.Cost)
+	assert.Equal(t, []string{strconv.FormatFloat(record.Cost, 'g', -1, 64)}, trailer.Get(proxy.DefaultCostTrailer))
+
+	// rejected calls are metered without backends
+	_, err = h.client.PingError(ctx, &pb.PingRequest{Value: "foo"}, grpc.Trailer(&trailer))
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	assert.Equal(t, []string{"0.5"}, trailer.Get(proxy.DefaultCostTrailer))
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	require.Len(t, records, 2)
+	assert.Empty(t, records[1].Usage.Backends)
+	assert.Equal(t, "PermissionDenied", records[1].Usage.Code)
+}
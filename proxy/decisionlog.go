@@ -0,0 +1,450 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// DecisionLogService is the name of the gRPC service of the decision log, see DecisionLog.ControlService.
+const DecisionLogService = "grpcproxy.admin.v1.DecisionLog"
+
+// decisionLogBuffer is the capacity of the channels returned by DecisionLog.Subscribe.
+const decisionLogBuffer = 64
+
+// decisionLogMaxValueBytes is the limit of the metadata values in the summary of the routing decision.
+const decisionLogMaxValueBytes = 64
+
+// RoutingDecision is the decision of the director on the backends of the call.
+type RoutingDecision struct {
+	Time   time.Time `json:"time"`
+	Method string    `json:"method"`
+	// Metadata is the summary of the request metadata: the values of the keys which are not shown (see NewDecisionLog)
+	// are replaced with RedactedValue, the shown values are truncated, and the binary values are replaced with
+	// their size.
+	Metadata map[string]string `json:"metadata,omitempty"`
+	// Mode is "one2one" or "one2many", Backends are the backends selected by the director.
+	Mode     string   `json:"mode,omitempty"`
+	Backends []string `json:"backends,omitempty"`
+	// Duration is the time the director took to select the backends.
+	Duration time.Duration `json:"duration"`
+	// Error is the error of the director, if it failed.
+	Error string `json:"error,omitempty"`
+	// Dropped is the number of the decisions dropped for the subscriber before this one, as it was reading slowly.
+	Dropped uint64 `json:"dropped,omitempty"`
+}
+
+// DecisionLog streams the routing decisions of the proxy to the subscribers in real time, so that the operators
+// can watch the routing live, e.g. while rolling out the config changes of the director.
+//
+// The decisions are only recorded while there are subscribers. The decisions of the calls proxied with the lazy
+// director (see WithLazyDirector) are not recorded.
+//
+// The log is served as the server-streaming gRPC service on the control plane (see ControlService):
+//
+//	service DecisionLog {
+//	  rpc Watch(WatchDecisionsRequest) returns (stream RoutingDecision);
+//	}
+//
+//	message WatchDecisionsRequest {
+//	  // method_prefix limits the decisions to the methods with the prefix, e.g. "/package.Service/".
+//	  string method_prefix = 1;
+//	}
+//
+//	message RoutingDecision {
+//	  int64 time_unix_nano = 1;
+//	  string method = 2;
+//	  map<string, string> metadata = 3;
+//	  string mode = 4;
+//	  repeated string backends = 5;
+//	  int64 duration_nanos = 6;
+//	  string error = 7;
+//	  uint64 dropped = 8;
+//	}
+//
+// The descriptors of the service are registered in protoregistry.GlobalFiles by ControlService, so that the service
+// can be called with the tools relying on the server reflection. Go clients might use WatchDecisions.
+type DecisionLog struct {
+	show metadataKeyMatcher
+
+	mu          sync.Mutex
+	subscribers map[*decisionSubscriber]struct{}
+	active      int32
+}
+
+// decisionSubscriber is the subscriber of the decision log.
+type decisionSubscriber struct {
+	ch      chan RoutingDecision
+	prefix  string
+	dropped uint64
+}
+
+// NewDecisionLog creates the decision log, the values of the metadata keys are replaced with RedactedValue
+// except for the reserved keys (e.g. user-agent) and showKeys.
+//
+// The metadata commonly carries the credentials under the arbitrary keys (API keys, tokens, signatures), so the values
+// are shown only if allowed explicitly. The keys are matched as in MetadataAllowlistPolicy, e.g. "x-tenant-*".
+func NewDecisionLog(showKeys ...string) *DecisionLog {
+	return &DecisionLog{
+		show:        newMetadataKeyMatcher(showKeys),
+		subscribers: map[*decisionSubscriber]struct{}{},
+	}
+}
+
+// WithDecisionLog records the routing decisions to the log.
+func WithDecisionLog(log *DecisionLog) Option {
+	return func(o *handlerOptions) {
+		o.decisionLog = log
+	}
+}
+
+// Subscribe returns a channel which receives the routing decisions of the methods with the prefix (all methods
+// if the prefix is empty).
+//
+// If the reader is slow, the new decisions are dropped, and the next received decision reports the number
+// of the dropped ones. The channel is closed when the context is canceled.
+func (l *DecisionLog) Subscribe(ctx context.Context, methodPrefix string) <-chan RoutingDecision {
+	sub := &decisionSubscriber{
+		ch:     make(chan RoutingDecision, decisionLogBuffer),
+		prefix: methodPrefix,
+	}
+
+	l.mu.Lock()
+	l.subscribers[sub] = struct{}{}
+	atomic.StoreInt32(&l.active, int32(len(l.subscribers)))
+	l.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+
+		l.mu.Lock()
+		delete(l.subscribers, sub)
+		atomic.StoreInt32(&l.active, int32(len(l.subscribers)))
+		close(sub.ch)
+		l.mu.Unlock()
+	}()
+
+	return sub.ch
+}
+
+// ControlService returns the gRPC service of the log to be served on the control plane, see ServerConfig.
+func (l *DecisionLog) ControlService() ControlService {
+	registerDecisionLogFile()
+
+	return ControlService{
+		Desc: &grpc.ServiceDesc{
+			ServiceName: DecisionLogService,
+			HandlerType: (*interface{})(nil),
+			Streams: []grpc.StreamDesc{
+				{
+					StreamName: "Watch",
+					Handler: func(srv interface{}, stream grpc.ServerStream) error {
+						return srv.(*DecisionLog).watch(stream) //nolint:forcetypeassert
+					},
+					ServerStreams: true,
+				},
+			},
+			Metadata: decisionLogFile.Path(),
+		},
+		Impl: l,
+	}
+}
+
+// watch serves the Watch method.
+func (l *DecisionLog) watch(stream grpc.ServerStream) error {
+	request := dynamicpb.NewMessage(watchDecisionsRequestDesc)
+
+	if err := stream.RecvMsg(request); err != nil {
+		return err
+	}
+
+	prefix := request.Get(watchDecisionsRequestDesc.Fields().ByName("method_prefix")).String()
+
+	for decision := range l.Subscribe(stream.Context(), prefix) {
+		if err := stream.SendMsg(decision.toProto()); err != nil {
+			return err
+		}
+	}
+
+	return stream.Context().Err()
+}
+
+// record sends the decision to the subscribers.
+//
+// It is safe to be called on nil log.
+func (l *DecisionLog) record(ctx context.Context, fullMethodName string, duration time.Duration, mode Mode, backends []Backend, err error) {
+	if l == nil || atomic.LoadInt32(&l.active) == 0 {
+		return
+	}
+
+	decision := RoutingDecision{
+		Time:     time.Now(),
+		Method:   fullMethodName,
+		Duration: duration,
+	}
+
+	if err != nil {
+		decision.Error = err.Error()
+	} else {
+		decision.Mode = mode.String()
+
+		for _, backend := range backends {
+			decision.Backends = append(decision.Backends, backend.String())
+		}
+	}
+
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		decision.Metadata = l.summarize(md)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for sub := range l.subscribers {
+		if !strings.HasPrefix(fullMethodName, sub.prefix) {
+			continue
+		}
+
+		decision := decision
+		decision.Dropped = sub.dropped
+
+		select {
+		case sub.ch <- decision:
+			sub.dropped = 0
+		default:
+			sub.dropped++
+		}
+	}
+}
+
+// summarize returns the summary of the metadata.
+func (l *DecisionLog) summarize(md metadata.MD) map[string]string {
+	summary := make(map[string]string, len(md))
+
+	for key, values := range md {
+		if !l.show.allowed(key) {
+			summary[key] = RedactedValue
+
+			continue
+		}
+
+		if strings.HasSuffix(key, "-bin") {
+			size := 0
+
+			for _, value := range values {
+				size += len(value)
+			}
+
+			summary[key] = fmt.Sprintf("[%d bytes]", size)
+
+			continue
+		}
+
+		value := strings.Join(values, ", ")
+		if len(value) > decisionLogMaxValueBytes {
+			value = value[:decisionLogMaxValueBytes] + "..."
+		}
+
+		summary[key] = value
+	}
+
+	return summary
+}
+
+// WatchDecisions subscribes to the decision log served by the connection (see DecisionLog.ControlService),
+// recv returns the decisions of the methods with the prefix as they are made.
+func WatchDecisions(ctx context.Context, conn grpc.ClientConnInterface, methodPrefix string) (recv func() (RoutingDecision, error), err error) {
+	stream, err := conn.NewStream(ctx, &grpc.StreamDesc{ServerStreams: true}, "/"+DecisionLogService+"/Watch")
+	if err != nil {
+		return nil, err
+	}
+
+	request := dynamicpb.NewMessage(watchDecisionsRequestDesc)
+	request.Set(watchDecisionsRequestDesc.Fields().ByName("method_prefix"), protoreflect.ValueOfString(methodPrefix))
+
+	if err = stream.SendMsg(request); err != nil {
+		return nil, err
+	}
+
+	if err = stream.CloseSend(); err != nil {
+		return nil, err
+	}
+
+	return func() (RoutingDecision, error) {
+		msg := dynamicpb.NewMessage(routingDecisionDesc)
+
+		if err := stream.RecvMsg(msg); err != nil {
+			return RoutingDecision{}, err
+		}
+
+		return routingDecisionFromProto(msg), nil
+	}, nil
+}
+
+// toProto converts the decision to the RoutingDecision message.
+func (d RoutingDecision) toProto() proto.Message {
+	msg := dynamicpb.NewMessage(routingDecisionDesc)
+	fields := routingDecisionDesc.Fields()
+
+	msg.Set(fields.ByName("time_unix_nano"), protoreflect.ValueOfInt64(d.Time.UnixNano()))
+	msg.Set(fields.ByName("method"), protoreflect.ValueOfString(d.Method))
+	msg.Set(fields.ByName("mode"), protoreflect.ValueOfString(d.Mode))
+	msg.Set(fields.ByName("duration_nanos"), protoreflect.ValueOfInt64(int64(d.Duration)))
+	msg.Set(fields.ByName("error"), protoreflect.ValueOfString(d.Error))
+	msg.Set(fields.ByName("dropped"), protoreflect.ValueOfUint64(d.Dropped))
+
+	md := msg.Mutable(fields.ByName("metadata")).Map()
+
+	for key, value := range d.Metadata {
+		md.Set(protoreflect.ValueOfString(key).MapKey(), protoreflect.ValueOfString(value))
+	}
+
+	backends := msg.Mutable(fields.ByName("backends")).List()
+
+	for _, backend := range d.Backends {
+		backends.Append(protoreflect.ValueOfString(backend))
+	}
+
+	return msg
+}
+
+// routingDecisionFromProto converts the RoutingDecision message to the decision.
+func routingDecisionFromProto(msg protoreflect.Message) RoutingDecision {
+	fields := routingDecisionDesc.Fields()
+
+	d := RoutingDecision{
+		Time:     time.Unix(0, msg.Get(fields.ByName("time_unix_nano")).Int()),
+		Method:   msg.Get(fields.ByName("method")).String(),
+		Mode:     msg.Get(fields.ByName("mode")).String(),
+		Duration: time.Duration(msg.Get(fields.ByName("duration_nanos")).Int()),
+		Error:    msg.Get(fields.ByName("error")).String(),
+		Dropped:  msg.Get(fields.ByName("dropped")).Uint(),
+	}
+
+	if md := msg.Get(fields.ByName("metadata")).Map(); md.Len() > 0 {
+		d.Metadata = make(map[string]string, md.Len())
+
+		md.Range(func(key protoreflect.MapKey, value protoreflect.Value) bool {
+			d.Metadata[key.String()] = value.String()
+
+			return true
+		})
+	}
+
+	backends := msg.Get(fields.ByName("backends")).List()
+
+	for i := 0; i < backends.Len(); i++ {
+		d.Backends = append(d.Backends, backends.Get(i).String())
+	}
+
+	return d
+}
+
+// Descriptors of the decision log service.
+var (
+	decisionLogFile           = buildDecisionLogFile()
+	watchDecisionsRequestDesc = decisionLogFile.Messages().ByName("WatchDecisionsRequest")
+	routingDecisionDesc       = decisionLogFile.Messages().ByName("RoutingDecision")
+)
+
+var decisionLogFileOnce sync.Once
+
+// registerDecisionLogFile registers the descriptor of the decision log service in protoregistry.GlobalFiles once.
+func registerDecisionLogFile() {
+	decisionLogFileOnce.Do(func() {
+		// the descriptor is only used for the reflection, so the conflict is not fatal
+		protoregistry.GlobalFiles.RegisterFile(decisionLogFile) //nolint:errcheck
+	})
+}
+
+// buildDecisionLogFile builds the descriptor of the decision log service.
+func buildDecisionLogFile() protoreflect.FileDescriptor {
+	field := func(name string, number int32, typ descriptorpb.FieldDescriptorProto_Type, label descriptorpb.FieldDescriptorProto_Label) *descriptorpb.FieldDescriptorProto {
+		return &descriptorpb.FieldDescriptorProto{
+			Name:   proto.String(name),
+			Number: proto.Int32(number),
+			Type:   typ.Enum(),
+			Label:  label.Enum(),
+		}
+	}
+
+	const (
+		optional = descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL
+		repeated = descriptorpb.FieldDescriptorProto_LABEL_REPEATED
+	)
+
+	metadataField := field("metadata", 3, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, repeated)
+	metadataField.TypeName = proto.String(".grpcproxy.admin.v1.RoutingDecision.MetadataEntry")
+
+	fdp := &descriptorpb.FileDescriptorProto{
+		Name:    proto.String("grpcproxy/admin/v1/decisionlog.proto"),
+		Package: proto.String("grpcproxy.admin.v1"),
+		Syntax:  proto.String("proto3"),
+		MessageType: []*descriptorpb.DescriptorProto{
+			{
+				Name: proto.String("WatchDecisionsRequest"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					field("method_prefix", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING, optional),
+				},
+			},
+			{
+				Name: proto.String("RoutingDecision"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					field("time_unix_nano", 1, descriptorpb.FieldDescriptorProto_TYPE_INT64, optional),
+					field("method", 2, descriptorpb.FieldDescriptorProto_TYPE_STRING, optional),
+					metadataField,
+					field("mode", 4, descriptorpb.FieldDescriptorProto_TYPE_STRING, optional),
+					field("backends", 5, descriptorpb.FieldDescriptorProto_TYPE_STRING, repeated),
+					field("duration_nanos", 6, descriptorpb.FieldDescriptorProto_TYPE_INT64, optional),
+					field("error", 7, descriptorpb.FieldDescriptorProto_TYPE_STRING, optional),
+					field("dropped", 8, descriptorpb.FieldDescriptorProto_TYPE_UINT64, optional),
+				},
+				NestedType: []*descriptorpb.DescriptorProto{
+					{
+						Name: proto.String("MetadataEntry"),
+						Field: []*descriptorpb.FieldDescriptorProto{
+							field("key", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING, optional),
+							field("value", 2, descriptorpb.FieldDescriptorProto_TYPE_STRING, optional),
+						},
+						Options: &descriptorpb.MessageOptions{MapEntry: proto.Bool(true)},
+					},
+				},
+			},
+		},
+		Service: []*descriptorpb.ServiceDescriptorProto{
+			{
+				Name: proto.String("DecisionLog"),
+				Method: []*descriptorpb.MethodDescriptorProto{
+					{
+						Name:            proto.String("Watch"),
+						InputType:       proto.String(".grpcproxy.admin.v1.WatchDecisionsRequest"),
+						OutputType:      proto.String(".grpcproxy.admin.v1.RoutingDecision"),
+						ServerStreaming: proto.Bool(true),
+					},
+				},
+			},
+		},
+	}
+
+	fd, err := protodesc.NewFile(fdp, protoregistry.GlobalFiles)
+	if err != nil {
+		panic(fmt.Sprintf("invalid decision log descriptor: %v", err))
+	}
+
+	return fd
+}
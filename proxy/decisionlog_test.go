@@ -0,0 +1,194 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy_test
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/noncepad/grpc-proxy/proxy"
+	pb "github.com/noncepad/grpc-proxy/testservice"
+)
+
+func TestDecisionLogWatch(t *testing.T) {
+	var upstream proxy.Backend
+
+	newTestHarness(t, func(backend proxy.Backend) proxy.StreamDirector {
+		upstream = backend
+
+		return one2oneDirector(backend)
+	})
+
+	log := proxy.NewDecisionLog("x-tenant", "x-trace-*")
+
+	server, err := proxy.NewServer(proxy.ServerConfig{
+		Director: func(ctx context.Context, fullMethodName string) (proxy.Mode, []proxy.Backend, error) {
+			if fullMethodName == "/talos.testproto.TestService/PingEmpty" {
+				return proxy.One2One, nil, status.Error(codes.FailedPrecondition, "no route")
+			}
+
+			return proxy.One2One, []proxy.Backend{upstream}, nil
+		},
+		Options:         []proxy.Option{proxy.WithDecisionLog(log)},
+		ControlServices: []proxy.ControlService{log.ControlService()},
+	})
+	require.NoError(t, err)
+
+	dataListener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	controlListener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	go server.Serve(dataListener, controlListener) //nolint:errcheck
+
+	t.Cleanup(server.Stop)
+
+	dial := func(listener net.Listener) *grpc.ClientConn {
+		conn, dialErr := grpc.Dial(listener.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+		require.NoError(t, dialErr)
+
+		t.Cleanup(func() { conn.Close() }) //nolint: errcheck
+
+		return conn
+	}
+
+	client := pb.NewTestServiceClient(dial(dataListener))
+
+	ctx := testContext(t)
+
+	recv, err := proxy.WatchDecisions(ctx, dial(controlListener), "/talos.testproto.TestService/PingE")
+	require.NoError(t, err)
+
+	decisions := make(chan proxy.RoutingDecision, 100)
+
+	go func() {
+		for {
+			decision, recvErr := recv()
+			if recvErr != nil {
+				close(decisions)
+
+				return
+			}
+
+			decisions <- decision
+		}
+	}()
+
+	// the subscription is established asynchronously
+	require.Eventually(t, func() bool {
+		_, pingErr := client.PingEmpty(ctx, &pb.Empty{})
+		require.Error(t, pingErr)
+
+		select {
+		case <-decisions:
+			return true
+		case <-time.After(100 * time.Millisecond):
+			return false
+		}
+	}, 10*time.Second, time.Millisecond)
+
+	for len(decisions) > 0 {
+		<-decisions
+	}
+
+	callCtx := metadata.AppendToOutgoingContext(ctx,
+		"authorization", "Bearer secret",
+		"x-api-key", "secret",
+		"x-session-token", "secret",
+		"proxy-signature", "secret",
+		"x-tenant", "acme",
+		"x-trace-bin", "\x00\x01\x02",
+	)
+
+	// the method out of the prefix is not reported
+	_, err = client.Ping(ctx, &pb.PingRequest{Value: "foo"})
+	require.NoError(t, err)
+
+	_, err = client.PingError(callCtx, &pb.PingRequest{Value: "foo"})
+	require.Error(t, err)
+
+	_, err = client.PingEmpty(ctx, &pb.Empty{})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	decision := <-decisions
+
+	assert.Equal(t, "/talos.testproto.TestService/PingError", decision.Method)
+	assert.Equal(t, "one2one", decision.Mode)
+	assert.Equal(t, []string{upstream.String()}, decision.Backends)
+	assert.Empty(t, decision.Error)
+	assert.WithinDuration(t, time.Now(), decision.Time, 10*time.Second)
+	assert.Positive(t, decision.Duration)
+	assert.Equal(t, proxy.RedactedValue, decision.Metadata["authorization"])
+	assert.Equal(t, proxy.RedactedValue, decision.Metadata["x-api-key"])
+	assert.Equal(t, proxy.RedactedValue, decision.Metadata["x-session-token"])
+	assert.Equal(t, proxy.RedactedValue, decision.Metadata["proxy-signature"])
+	assert.Contains(t, decision.Metadata["user-agent"], "grpc-go")
+	assert.Equal(t, "acme", decision.Metadata["x-tenant"])
+	assert.Equal(t, "[3 bytes]", decision.Metadata["x-trace-bin"])
+
+	decision = <-decisions
+
+	assert.Equal(t, "/talos.testproto.TestService/PingEmpty", decision.Method)
+	assert.Empty(t, decision.Mode)
+	assert.Empty(t, decision.Backends)
+	assert.Contains(t, decision.Error, "no route")
+
+	select {
+	case decision = <-decisions:
+		assert.Fail(t, "unexpected decision", "%+v", decision)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestDecisionLogDropped(t *testing.T) {
+	log := proxy.NewDecisionLog()
+
+	h := newTestHarness(t, one2oneDirector, proxy.WithDecisionLog(log))
+
+	ctx, cancel := context.WithCancel(testContext(t))
+
+	decisions := log.Subscribe(ctx, "")
+
+	const calls = 70
+
+	for i := 0; i < calls; i++ {
+		_, err := h.client.Ping(ctx, &pb.PingRequest{Value: "foo"})
+		require.NoError(t, err)
+	}
+
+	// the subscriber is not reading, so the decisions over the buffer are dropped
+	received := 0
+
+	for len(decisions) > 0 {
+		decision := <-decisions
+		assert.Zero(t, decision.Dropped)
+
+		received++
+	}
+
+	require.Less(t, received, calls)
+
+	_, err := h.client.Ping(ctx, &pb.PingRequest{Value: "foo"})
+	require.NoError(t, err)
+
+	decision := <-decisions
+	assert.EqualValues(t, calls-received, decision.Dropped)
+
+	cancel()
+
+	// the channel is closed once the context is canceled
+	for range decisions { //nolint:revive
+	}
+}
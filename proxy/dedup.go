@@ -0,0 +1,140 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy
+
+import (
+	"sync"
+
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+// defaultDedupWindow is the default number of message IDs remembered per call.
+const defaultDedupWindow = 1024
+
+// MessageIDFunc extracts the ID of the response message received from the backend.
+//
+// The payload is the raw message as received from the backend, before Backend.AppendInfo. Messages without the ID
+// (ok is false) are never deduplicated.
+type MessageIDFunc func(fullMethodName string, payload []byte) (id string, ok bool)
+
+// DedupPolicy configures deduplication of the responses of one2many streaming calls.
+type DedupPolicy struct {
+	// ID extracts the message ID.
+	ID MessageIDFunc
+	// Window is the number of the most recent message IDs remembered per call.
+	//
+	// Default is 1024.
+	Window int
+}
+
+// WithDeduplication drops the duplicate responses of the one2many streaming calls of the listed methods.
+//
+// With redundant upstreams producing the same events (at-least-once delivery), each event is delivered to the client
+// once: the first copy received from any backend is forwarded, and the copies with the same ID received while the ID
+// is within the window are dropped. If fullMethodNames is empty, the policy is applied to all methods.
+func WithDeduplication(policy DedupPolicy, fullMethodNames ...string) Option {
+	if policy.Window <= 0 {
+		policy.Window = defaultDedupWindow
+	}
+
+	return func(o *handlerOptions) {
+		if o.dedupPolicies == nil {
+			o.dedupPolicies = map[string]DedupPolicy{}
+		}
+
+		if len(fullMethodNames) == 0 {
+			o.dedupPolicies[""] = policy
+
+			return
+		}
+
+		for _, name := range fullMethodNames {
+			o.dedupPolicies[name] = policy
+		}
+	}
+}
+
+// ResponseFieldID returns the MessageIDFunc which uses the field of the response message by the dotted path as the ID.
+//
+// The response type is looked up in the files by the method name, see WithDescriptors.
+func ResponseFieldID(files *protoregistry.Files, path string) MessageIDFunc {
+	return func(fullMethodName string, payload []byte) (string, bool) {
+		methodDesc, err := lookupMethod(files, fullMethodName)
+		if err != nil {
+			return "", false
+		}
+
+		id, err := messageField(methodDesc.Output(), payload, path)
+		if err != nil || len(id) == 0 {
+			return "", false
+		}
+
+		return string(id), true
+	}
+}
+
+// newDedupWindow returns the dedup window of the call, or nil if the deduplication is not enabled.
+func (o *handlerOptions) newDedupWindow(fullMethodName string) *dedupWindow {
+	policy, ok := o.dedupPolicies[fullMethodName]
+	if !ok {
+		policy, ok = o.dedupPolicies[""]
+	}
+
+	if !ok || policy.ID == nil {
+		return nil
+	}
+
+	return &dedupWindow{
+		policy: policy,
+		method: fullMethodName,
+		seen:   make(map[string]struct{}, policy.Window),
+		ring:   make([]string, 0, policy.Window),
+	}
+}
+
+// dedupWindow remembers the most recent message IDs of the call.
+type dedupWindow struct {
+	seen map[string]struct{}
+	// ring keeps the IDs in the arrival order, next is the position of the oldest ID once the ring is full
+	ring []string
+	next int
+
+	policy DedupPolicy
+	method string
+
+	mu sync.Mutex
+}
+
+// duplicate records the message ID and returns true if the message is a duplicate.
+func (w *dedupWindow) duplicate(payload []byte) bool {
+	if w == nil {
+		return false
+	}
+
+	id, ok := w.policy.ID(w.method, payload)
+	if !ok {
+		return false
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if _, ok = w.seen[id]; ok {
+		return true
+	}
+
+	w.seen[id] = struct{}{}
+
+	if len(w.ring) < w.policy.Window {
+		w.ring = append(w.ring, id)
+
+		return false
+	}
+
+	delete(w.seen, w.ring[w.next])
+	w.ring[w.next] = id
+	w.next = (w.next + 1) % w.policy.Window
+
+	return false
+}
@@ -0,0 +1,78 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/reflect/protoregistry"
+
+	"github.com/noncepad/grpc-proxy/proxy"
+	pb "github.com/noncepad/grpc-proxy/testservice"
+)
+
+func TestDeduplication(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		options  []proxy.Option
+		expected []string
+	}{
+		{
+			name:     "disabled",
+			expected: []string{"a", "a", "b", "b", "a", "a", "c", "c"},
+		},
+		{
+			name: "enabled",
+			options: []proxy.Option{
+				proxy.WithDeduplication(proxy.DedupPolicy{ID: proxy.ResponseFieldID(protoregistry.GlobalFiles, "Value")}),
+			},
+			expected: []string{"a", "b", "c"},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestHarness(t, func(backend proxy.Backend) proxy.StreamDirector {
+				return func(ctx context.Context, fullMethodName string) (proxy.Mode, []proxy.Backend, error) {
+					return proxy.One2Many, []proxy.Backend{
+						&taggedBackend{Backend: backend, tag: "a"},
+						&taggedBackend{Backend: backend, tag: "b"},
+					}, nil
+				}
+			}, append(tt.options,
+				proxy.WithStreamedDetector(func(fullMethodName string) bool { return fullMethodName == "/talos.testproto.TestService/PingStream" }))...)
+
+			stream, err := h.client.PingStream(testContext(t))
+			require.NoError(t, err)
+
+			for _, value := range []string{"a", "b", "a", "c"} {
+				require.NoError(t, stream.Send(&pb.PingRequest{Value: value}))
+			}
+
+			require.NoError(t, stream.CloseSend())
+
+			var values []string
+
+			for {
+				resp, err := stream.Recv()
+				if errors.Is(err, io.EOF) {
+					break
+				}
+
+				require.NoError(t, err)
+
+				values = append(values, resp.Value)
+			}
+
+			if tt.options == nil {
+				assert.ElementsMatch(t, tt.expected, values)
+			} else {
+				assert.Equal(t, tt.expected, values)
+			}
+		})
+	}
+}
@@ -0,0 +1,220 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+)
+
+// DeltaEncodingMetadataKey is the metadata key used to negotiate delta encoding.
+//
+// The client requests delta encoding by sending the key with value "1", the proxy confirms it
+// by sending the same key in the response headers.
+const DeltaEncodingMetadataKey = "proxy-delta-encoding"
+
+// Delta frame types.
+const (
+	deltaFrameFull  byte = 0
+	deltaFrameDelta byte = 1
+)
+
+// WithDeltaEncoding enables delta encoding of the response messages for the listed methods.
+//
+// Delta encoding is used only if requested by the client (see DeltaEncodingMetadataKey). When enabled,
+// each response message sent to the client is either sent as is, or encoded as a difference from the previous message,
+// which reduces egress bandwidth for streams of largely repeating messages. Clients should decode the messages
+// with DeltaDecoder or NewDeltaClientStream.
+func WithDeltaEncoding(fullMethodNames ...string) Option {
+	return func(o *handlerOptions) {
+		o.deltaMethods = map[string]struct{}{}
+
+		for _, name := range fullMethodNames {
+			o.deltaMethods[name] = struct{}{}
+		}
+	}
+}
+
+// deltaRequested checks whether delta encoding should be used for the call.
+func (o *handlerOptions) deltaRequested(ctx context.Context, fullMethodName string) bool {
+	if _, ok := o.deltaMethods[fullMethodName]; !ok {
+		return false
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get(DeltaEncodingMetadataKey)
+
+	return len(values) > 0 && values[0] == "1"
+}
+
+// DeltaEncoder encodes a sequence of messages as deltas.
+type DeltaEncoder struct {
+	prev []byte
+}
+
+// Encode encodes the message, picking the smaller of full and delta encodings.
+func (e *DeltaEncoder) Encode(payload []byte) []byte {
+	prev := e.prev
+	e.prev = payload
+
+	if prev == nil {
+		return append([]byte{deltaFrameFull}, payload...)
+	}
+
+	prefix := 0
+	for prefix < len(prev) && prefix < len(payload) && prev[prefix] == payload[prefix] {
+		prefix++
+	}
+
+	suffix := 0
+	for suffix < len(prev)-prefix && suffix < len(payload)-prefix && prev[len(prev)-1-suffix] == payload[len(payload)-1-suffix] {
+		suffix++
+	}
+
+	middle := payload[prefix : len(payload)-suffix]
+
+	if 1+protowire.SizeVarint(uint64(prefix))+protowire.SizeVarint(uint64(suffix))+len(middle) >= 1+len(payload) {
+		return append([]byte{deltaFrameFull}, payload...)
+	}
+
+	encoded := []byte{deltaFrameDelta}
+	encoded = protowire.AppendVarint(encoded, uint64(prefix))
+	encoded = protowire.AppendVarint(encoded, uint64(suffix))
+
+	return append(encoded, middle...)
+}
+
+// DeltaDecoder decodes a sequence of messages encoded by the DeltaEncoder.
+type DeltaDecoder struct {
+	prev []byte
+}
+
+// Decode decodes the message.
+func (d *DeltaDecoder) Decode(encoded []byte) ([]byte, error) {
+	if len(encoded) == 0 {
+		return nil, errors.New("empty delta frame")
+	}
+
+	var payload []byte
+
+	switch encoded[0] {
+	case deltaFrameFull:
+		payload = append([]byte{}, encoded[1:]...)
+	case deltaFrameDelta:
+		if d.prev == nil {
+			return nil, errors.New("delta frame without the base frame")
+		}
+
+		rest := encoded[1:]
+
+		prefix, n := protowire.ConsumeVarint(rest)
+		if n < 0 {
+			return nil, errors.New("malformed delta frame")
+		}
+
+		rest = rest[n:]
+
+		suffix, n := protowire.ConsumeVarint(rest)
+		if n < 0 || prefix+suffix > uint64(len(d.prev)) {
+			return nil, errors.New("malformed delta frame")
+		}
+
+		rest = rest[n:]
+
+		payload = make([]byte, 0, int(prefix)+len(rest)+int(suffix))
+		payload = append(payload, d.prev[:prefix]...)
+		payload = append(payload, rest...)
+		payload = append(payload, d.prev[uint64(len(d.prev))-suffix:]...)
+	default:
+		return nil, fmt.Errorf("unknown delta frame type %d", encoded[0])
+	}
+
+	d.prev = payload
+
+	return payload, nil
+}
+
+// deltaServerStream encodes messages sent to the client.
+//
+// SendMsg is not safe for concurrent use, so the stream should be wrapped with ServerStreamWrapper for one2many proxying.
+type deltaServerStream struct {
+	grpc.ServerStream
+
+	encoder DeltaEncoder
+}
+
+func (s *deltaServerStream) SendMsg(m interface{}) error {
+	f, ok := m.(*Frame)
+	if !ok {
+		return s.ServerStream.SendMsg(m)
+	}
+
+	return s.ServerStream.SendMsg(NewFrame(s.encoder.Encode(f.payload)))
+}
+
+// NewDeltaClientStream wraps the client stream to decode delta encoded responses.
+//
+// The client connection should use the proxy codec (see Codec), and the outgoing context of the call should carry
+// DeltaEncodingMetadataKey. Messages which are not *Frame are decoded with protobuf codec.
+// If the proxy didn't confirm delta encoding in the response headers, messages are passed through as is.
+func NewDeltaClientStream(cs grpc.ClientStream) grpc.ClientStream {
+	return &deltaClientStream{ClientStream: cs}
+}
+
+type deltaClientStream struct {
+	grpc.ClientStream
+
+	decoder   DeltaDecoder
+	checked   bool
+	confirmed bool
+}
+
+func (s *deltaClientStream) RecvMsg(m interface{}) error {
+	f := &Frame{}
+
+	if err := s.ClientStream.RecvMsg(f); err != nil {
+		return err
+	}
+
+	if !s.checked {
+		s.checked = true
+
+		md, err := s.ClientStream.Header()
+		if err != nil {
+			return err
+		}
+
+		values := md.Get(DeltaEncodingMetadataKey)
+		s.confirmed = len(values) > 0 && values[0] == "1"
+	}
+
+	payload := f.payload
+
+	if s.confirmed {
+		var err error
+
+		if payload, err = s.decoder.Decode(payload); err != nil {
+			return err
+		}
+	}
+
+	if dst, ok := m.(*Frame); ok {
+		dst.payload = payload
+
+		return nil
+	}
+
+	msg, ok := m.(proto.Message)
+	if !ok {
+		return fmt.Errorf("unsupported message type %T", m)
+	}
+
+	return proto.Unmarshal(payload, msg)
+}
@@ -0,0 +1,96 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/noncepad/grpc-proxy/proxy"
+	pb "github.com/noncepad/grpc-proxy/testservice"
+)
+
+func TestDeltaEncoderDecoder(t *testing.T) {
+	var (
+		encoder proxy.DeltaEncoder
+		decoder proxy.DeltaDecoder
+	)
+
+	rnd := rand.New(rand.NewSource(42))
+	payload := bytes.Repeat([]byte("0123456789"), 100)
+	encodedSize, rawSize := 0, 0
+
+	for i := 0; i < 1000; i++ {
+		next := append([]byte(nil), payload...)
+
+		switch rnd.Intn(4) {
+		case 0:
+			next[rnd.Intn(len(next))]++
+		case 1:
+			next = append(next, byte(rnd.Intn(256)))
+		case 2:
+			next = next[:rnd.Intn(len(next)+1)]
+		case 3:
+			next = []byte{}
+		}
+
+		if len(next) == 0 {
+			next = bytes.Repeat([]byte("x"), rnd.Intn(100))
+		}
+
+		payload = next
+
+		encoded := encoder.Encode(payload)
+		encodedSize += len(encoded)
+		rawSize += len(payload)
+
+		decoded, err := decoder.Decode(encoded)
+		require.NoError(t, err)
+		require.Equal(t, payload, decoded)
+	}
+
+	assert.Less(t, encodedSize, rawSize/2)
+}
+
+func TestDeltaEncodingStream(t *testing.T) {
+	h := newTestHarness(t, one2oneDirector, proxy.WithDeltaEncoding("/talos.testproto.TestService/PingList"))
+
+	ctx := metadata.AppendToOutgoingContext(testContext(t), proxy.DeltaEncodingMetadataKey, "1")
+
+	cs, err := h.clientConn.NewStream(ctx, &grpc.StreamDesc{ServerStreams: true}, "/talos.testproto.TestService/PingList",
+		grpc.CallCustomCodec(proxy.Codec())) //nolint: staticcheck
+	require.NoError(t, err)
+
+	stream := proxy.NewDeltaClientStream(cs)
+
+	require.NoError(t, stream.SendMsg(&pb.PingRequest{Value: "foo"}))
+	require.NoError(t, stream.CloseSend())
+
+	for i := 0; ; i++ {
+		var resp pb.PingResponse
+
+		err = stream.RecvMsg(&resp)
+		if errors.Is(err, io.EOF) {
+			assert.Equal(t, countListResponses, i)
+
+			break
+		}
+
+		require.NoError(t, err)
+		assert.Equal(t, "foo", resp.Value)
+		assert.EqualValues(t, i, resp.Counter)
+	}
+
+	header, err := stream.Header()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1"}, header.Get(proxy.DeltaEncodingMetadataKey))
+}
@@ -0,0 +1,351 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	rpb "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// reflectionTimeout bounds the time spent fetching the descriptors from the backend.
+const reflectionTimeout = 10 * time.Second
+
+const reflectionMethod = "/grpc.reflection.v1alpha.ServerReflection/ServerReflectionInfo"
+
+// DescriptorResolver resolves the method descriptors for the descriptor-aware features (transcoding, validation,
+// field masking, routing by request fields, etc.), caching the lookups.
+//
+// Descriptors are looked up in the local registry first. If the service is not registered locally and the resolver
+// has a reflection backend or a schema registry, the descriptors are fetched lazily from the backend with the gRPC
+// server reflection (or from the schema registry), and cached until the backend version changes (see SetVersion).
+//
+// Resolvers are safe for concurrent use. The resolvers of the local registries are shared process-wide, so all
+// the descriptor-aware features using the same registry share the cache.
+type DescriptorResolver struct {
+	files    *protoregistry.Files
+	backend  Backend
+	registry SchemaRegistry
+
+	// remote keeps the descriptors fetched from the backend
+	remote *protoregistry.Files
+
+	methods map[string]protoreflect.MethodDescriptor
+	// failed keeps the services which failed to be fetched from the backend
+	failed map[string]error
+
+	version string
+
+	mu      sync.Mutex
+	fetchMu sync.Mutex
+}
+
+// NewDescriptorResolver creates the resolver with the local registry (protoregistry.GlobalFiles if nil).
+//
+// If the backend is not nil, services which are not registered locally are fetched from the backend with the gRPC
+// server reflection.
+func NewDescriptorResolver(files *protoregistry.Files, backend Backend) *DescriptorResolver {
+	if files == nil {
+		files = protoregistry.GlobalFiles
+	}
+
+	return &DescriptorResolver{
+		files:   files,
+		backend: backend,
+		remote:  &protoregistry.Files{},
+		methods: map[string]protoreflect.MethodDescriptor{},
+		failed:  map[string]error{},
+	}
+}
+
+// NewSchemaRegistryResolver creates the resolver with the local registry (protoregistry.GlobalFiles if nil), which
+// fetches the services not registered locally from the schema registry, at the schema version.
+//
+// The version can be changed with SetVersion, e.g. when the backend is upgraded.
+func NewSchemaRegistryResolver(files *protoregistry.Files, registry SchemaRegistry, version string) *DescriptorResolver {
+	r := NewDescriptorResolver(files, nil)
+	r.registry = registry
+	r.version = version
+
+	return r
+}
+
+var (
+	sharedResolversMu sync.Mutex
+	sharedResolvers   = map[*protoregistry.Files]*DescriptorResolver{}
+)
+
+// sharedResolver returns the process-wide resolver for the local registry (nil means global registry).
+func sharedResolver(files *protoregistry.Files) *DescriptorResolver {
+	if files == nil {
+		files = protoregistry.GlobalFiles
+	}
+
+	sharedResolversMu.Lock()
+	defer sharedResolversMu.Unlock()
+
+	r, ok := sharedResolvers[files]
+	if !ok {
+		r = NewDescriptorResolver(files, nil)
+		sharedResolvers[files] = r
+	}
+
+	return r
+}
+
+// SetVersion records the version of the backend, the descriptors fetched from the backend are dropped if
+// the version changed.
+//
+// With the schema registry, the version is the schema version fetched from the registry.
+func (r *DescriptorResolver) SetVersion(version string) {
+	r.mu.Lock()
+	changed := r.version != version
+	r.version = version
+	r.mu.Unlock()
+
+	if changed {
+		r.Invalidate()
+	}
+}
+
+// Invalidate drops the cached lookups and the descriptors fetched from the backend.
+func (r *DescriptorResolver) Invalidate() {
+	r.fetchMu.Lock()
+	defer r.fetchMu.Unlock()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.remote = &protoregistry.Files{}
+	r.methods = map[string]protoreflect.MethodDescriptor{}
+	r.failed = map[string]error{}
+}
+
+// FindMethod returns the descriptor of the method by the full method name (/package.Service/Method).
+func (r *DescriptorResolver) FindMethod(ctx context.Context, fullMethodName string) (protoreflect.MethodDescriptor, error) {
+	r.mu.Lock()
+	methodDesc, ok := r.methods[fullMethodName]
+	r.mu.Unlock()
+
+	if ok {
+		return methodDesc, nil
+	}
+
+	methodDesc, err := findMethod(r.files, fullMethodName)
+	if err != nil && (r.backend != nil || r.registry != nil) {
+		methodDesc, err = r.findRemoteMethod(ctx, fullMethodName)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	r.methods[fullMethodName] = methodDesc
+	r.mu.Unlock()
+
+	return methodDesc, nil
+}
+
+// findRemoteMethod looks up the method in the descriptors fetched from the backend, fetching them if necessary.
+func (r *DescriptorResolver) findRemoteMethod(ctx context.Context, fullMethodName string) (protoreflect.MethodDescriptor, error) {
+	serviceName, _, ok := splitMethodName(fullMethodName)
+	if !ok {
+		return nil, fmt.Errorf("malformed method name %q", fullMethodName)
+	}
+
+	r.fetchMu.Lock()
+	defer r.fetchMu.Unlock()
+
+	r.mu.Lock()
+	remote, fetchErr := r.remote, r.failed[serviceName]
+	r.mu.Unlock()
+
+	if fetchErr != nil {
+		return nil, fetchErr
+	}
+
+	if methodDesc, err := findMethod(remote, fullMethodName); err == nil {
+		return methodDesc, nil
+	}
+
+	fetch, source := r.fetch, fmt.Sprint(r.backend)
+	if r.registry != nil {
+		fetch, source = r.fetchSchema, "schema registry"
+	}
+
+	if err := fetch(ctx, remote, serviceName); err != nil {
+		err = fmt.Errorf("error fetching descriptors of %q from %s: %w", serviceName, source, err)
+
+		r.mu.Lock()
+		r.failed[serviceName] = err
+		r.mu.Unlock()
+
+		return nil, err
+	}
+
+	return findMethod(remote, fullMethodName)
+}
+
+// fetch fetches the file declaring the service and its dependencies with the server reflection.
+func (r *DescriptorResolver) fetch(ctx context.Context, remote *protoregistry.Files, serviceName string) error {
+	ctx, cancel := context.WithTimeout(ctx, reflectionTimeout)
+	defer cancel()
+
+	outgoingCtx, conn, err := r.backend.GetConnection(ctx, reflectionMethod)
+	if err != nil {
+		return err
+	}
+
+	stream, err := rpb.NewServerReflectionClient(conn).ServerReflectionInfo(outgoingCtx)
+	if err != nil {
+		return err
+	}
+
+	defer stream.CloseSend() //nolint:errcheck
+
+	fetched := map[string]*descriptorpb.FileDescriptorProto{}
+
+	request := func(req *rpb.ServerReflectionRequest) error {
+		if err = stream.Send(req); err != nil {
+			return err
+		}
+
+		resp, err := stream.Recv()
+		if err != nil {
+			return err
+		}
+
+		if errResp := resp.GetErrorResponse(); errResp != nil {
+			return errors.New(errResp.GetErrorMessage())
+		}
+
+		for _, encoded := range resp.GetFileDescriptorResponse().GetFileDescriptorProto() {
+			fdp := &descriptorpb.FileDescriptorProto{}
+
+			if err = proto.Unmarshal(encoded, fdp); err != nil {
+				return err
+			}
+
+			fetched[fdp.GetName()] = fdp
+		}
+
+		return nil
+	}
+
+	if err = request(&rpb.ServerReflectionRequest{
+		MessageRequest: &rpb.ServerReflectionRequest_FileContainingSymbol{FileContainingSymbol: serviceName},
+	}); err != nil {
+		return err
+	}
+
+	return r.register(remote, fetched, func(dep string) error {
+		return request(&rpb.ServerReflectionRequest{
+			MessageRequest: &rpb.ServerReflectionRequest_FileByFilename{FileByFilename: dep},
+		})
+	})
+}
+
+// fetchSchema fetches the files of the service at the resolver version from the schema registry.
+func (r *DescriptorResolver) fetchSchema(ctx context.Context, remote *protoregistry.Files, serviceName string) error {
+	r.mu.Lock()
+	version := r.version
+	r.mu.Unlock()
+
+	set, err := r.registry.Schema(ctx, serviceName, version)
+	if err != nil {
+		return err
+	}
+
+	fetched := map[string]*descriptorpb.FileDescriptorProto{}
+
+	for _, fdp := range set.GetFile() {
+		fetched[fdp.GetName()] = fdp
+	}
+
+	return r.register(remote, fetched, nil)
+}
+
+// register registers the fetched files and their dependencies in the remote registry, the dependencies which are
+// neither fetched nor registered locally are requested with fetchDep (if set).
+func (r *DescriptorResolver) register(remote *protoregistry.Files, fetched map[string]*descriptorpb.FileDescriptorProto,
+	fetchDep func(dep string) error,
+) error {
+	resolver := &fallbackResolver{primary: remote, fallback: r.files}
+
+	var register func(fdp *descriptorpb.FileDescriptorProto) error
+
+	register = func(fdp *descriptorpb.FileDescriptorProto) error {
+		if _, err := resolver.FindFileByPath(fdp.GetName()); err == nil {
+			return nil
+		}
+
+		for _, dep := range fdp.GetDependency() {
+			if _, err := resolver.FindFileByPath(dep); err == nil {
+				continue
+			}
+
+			if _, ok := fetched[dep]; !ok && fetchDep != nil {
+				if err := fetchDep(dep); err != nil {
+					return err
+				}
+			}
+
+			depFdp, ok := fetched[dep]
+			if !ok {
+				return fmt.Errorf("dependency %q is not served", dep)
+			}
+
+			if err := register(depFdp); err != nil {
+				return err
+			}
+		}
+
+		fd, err := protodesc.NewFile(fdp, resolver)
+		if err != nil {
+			return err
+		}
+
+		return remote.RegisterFile(fd)
+	}
+
+	for _, fdp := range fetched {
+		if err := register(fdp); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// fallbackResolver looks up the descriptors in the primary registry, then in the fallback one.
+type fallbackResolver struct {
+	primary  *protoregistry.Files
+	fallback *protoregistry.Files
+}
+
+func (r *fallbackResolver) FindFileByPath(path string) (protoreflect.FileDescriptor, error) {
+	if fd, err := r.primary.FindFileByPath(path); err == nil {
+		return fd, nil
+	}
+
+	return r.fallback.FindFileByPath(path)
+}
+
+func (r *fallbackResolver) FindDescriptorByName(name protoreflect.FullName) (protoreflect.Descriptor, error) {
+	if desc, err := r.primary.FindDescriptorByName(name); err == nil {
+		return desc, nil
+	}
+
+	return r.fallback.FindDescriptorByName(name)
+}
@@ -0,0 +1,129 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy_test
+
+import (
+	"context"
+	"net"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/reflect/protoregistry"
+
+	"github.com/noncepad/grpc-proxy/proxy"
+	pb "github.com/noncepad/grpc-proxy/testservice"
+)
+
+// countingBackend counts the connections requested from the backend.
+type countingBackend struct {
+	proxy.Backend
+
+	connections int32
+}
+
+func (b *countingBackend) GetConnection(ctx context.Context, fullMethodName string) (context.Context, *grpc.ClientConn, error) {
+	atomic.AddInt32(&b.connections, 1)
+
+	return b.Backend.GetConnection(ctx, fullMethodName)
+}
+
+// newReflectionBackend starts TestService upstream with the server reflection.
+func newReflectionBackend(t *testing.T, dialOptions ...grpc.DialOption) *countingBackend {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	server := grpc.NewServer()
+	pb.RegisterTestServiceServer(server, &lenientService{})
+	reflection.Register(server)
+
+	go server.Serve(listener) //nolint: errcheck
+
+	conn, err := grpc.Dial(listener.Addr().String(), append(dialOptions, grpc.WithTransportCredentials(insecure.NewCredentials()))...)
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		conn.Close() //nolint: errcheck
+		server.Stop()
+	})
+
+	return &countingBackend{
+		Backend: &proxy.SingleBackend{
+			GetConn: func(ctx context.Context) (context.Context, *grpc.ClientConn, error) {
+				return ctx, conn, nil
+			},
+		},
+	}
+}
+
+func TestDescriptorResolverReflection(t *testing.T) {
+	backend := newReflectionBackend(t)
+	resolver := proxy.NewDescriptorResolver(&protoregistry.Files{}, backend)
+
+	ctx := testContext(t)
+
+	methodDesc, err := resolver.FindMethod(ctx, "/talos.testproto.TestService/Ping")
+	require.NoError(t, err)
+
+	assert.EqualValues(t, "talos.testproto.PingRequest", methodDesc.Input().FullName())
+	assert.EqualValues(t, "talos.testproto.PingResponse", methodDesc.Output().FullName())
+	assert.EqualValues(t, 1, atomic.LoadInt32(&backend.connections))
+
+	// lookups are served from the cache and the fetched descriptors
+	for _, method := range []string{"/talos.testproto.TestService/Ping", "/talos.testproto.TestService/PingStream"} {
+		methodDesc, err = resolver.FindMethod(ctx, method)
+		require.NoError(t, err)
+		assert.Equal(t, method[len("/talos.testproto.TestService/"):], string(methodDesc.Name()))
+	}
+
+	assert.EqualValues(t, 1, atomic.LoadInt32(&backend.connections))
+
+	// failures are cached as well
+	for i := 0; i < 2; i++ {
+		_, err = resolver.FindMethod(ctx, "/talos.testproto.UnknownService/Ping")
+		require.Error(t, err)
+	}
+
+	assert.EqualValues(t, 2, atomic.LoadInt32(&backend.connections))
+
+	// version change drops the fetched descriptors
+	resolver.SetVersion("v2")
+
+	_, err = resolver.FindMethod(ctx, "/talos.testproto.TestService/Ping")
+	require.NoError(t, err)
+	assert.EqualValues(t, 3, atomic.LoadInt32(&backend.connections))
+
+	resolver.SetVersion("v2")
+
+	_, err = resolver.FindMethod(ctx, "/talos.testproto.TestService/Ping")
+	require.NoError(t, err)
+	assert.EqualValues(t, 3, atomic.LoadInt32(&backend.connections))
+}
+
+func TestDescriptorResolverHandler(t *testing.T) {
+	resolver := proxy.NewDescriptorResolver(&protoregistry.Files{}, newReflectionBackend(t))
+
+	h := newTestHarnessWithService(t, &lenientService{}, one2oneDirector,
+		proxy.WithDescriptorResolver(resolver),
+		proxy.WithRequestTypeDenylist("talos.testproto.Empty"),
+	)
+
+	ctx := testContext(t)
+
+	resp, err := h.client.Ping(ctx, &pb.PingRequest{Value: "foo"})
+	require.NoError(t, err)
+	assert.Equal(t, "foo", resp.Value)
+
+	_, err = h.client.PingEmpty(ctx, &pb.Empty{})
+	require.Error(t, err)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+}
@@ -0,0 +1,130 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// WithDescriptorFiles configures the registry of protobuf file descriptors used by descriptor-aware options.
+//
+// By default protoregistry.GlobalFiles is used, which contains descriptors of all the generated protobuf
+// packages linked into the binary.
+func WithDescriptorFiles(files *protoregistry.Files) Option {
+	return func(o *handlerOptions) {
+		o.descriptorFiles = files
+	}
+}
+
+// splitMethodName splits full method name (/package.Service/Method) into service and method name.
+func splitMethodName(fullMethodName string) (string, string, bool) {
+	name := strings.TrimPrefix(fullMethodName, "/")
+
+	pos := strings.LastIndex(name, "/")
+	if pos <= 0 || pos == len(name)-1 {
+		return "", "", false
+	}
+
+	return name[:pos], name[pos+1:], true
+}
+
+// WithDescriptorResolver configures the resolver of method descriptors used by descriptor-aware options,
+// e.g. to fetch the descriptors from the backends with the server reflection.
+//
+// If not set, the process-wide resolver of the registry configured with WithDescriptorFiles is used.
+func WithDescriptorResolver(resolver *DescriptorResolver) Option {
+	return func(o *handlerOptions) {
+		o.descriptorResolver = resolver
+	}
+}
+
+// resolver returns the descriptor resolver of the handler.
+func (o *handlerOptions) resolver() *DescriptorResolver {
+	if o.descriptorResolver != nil {
+		return o.descriptorResolver
+	}
+
+	return sharedResolver(o.descriptorFiles)
+}
+
+// lookupMethod finds protobuf method descriptor for the full method name.
+func (o *handlerOptions) lookupMethod(fullMethodName string) (protoreflect.MethodDescriptor, error) {
+	return o.resolver().FindMethod(context.Background(), fullMethodName)
+}
+
+// lookupMethod finds protobuf method descriptor for the full method name in the registry (nil means global registry)
+// via the process-wide resolver of the registry.
+func lookupMethod(files *protoregistry.Files, fullMethodName string) (protoreflect.MethodDescriptor, error) {
+	return sharedResolver(files).FindMethod(context.Background(), fullMethodName)
+}
+
+// findMethod finds protobuf method descriptor for the full method name in the registry (nil means global registry).
+func findMethod(files *protoregistry.Files, fullMethodName string) (protoreflect.MethodDescriptor, error) {
+	serviceName, methodName, ok := splitMethodName(fullMethodName)
+	if !ok {
+		return nil, fmt.Errorf("malformed method name %q", fullMethodName)
+	}
+
+	if files == nil {
+		files = protoregistry.GlobalFiles
+	}
+
+	desc, err := files.FindDescriptorByName(protoreflect.FullName(serviceName))
+	if err != nil {
+		return nil, fmt.Errorf("error looking up service %q: %w", serviceName, err)
+	}
+
+	serviceDesc, ok := desc.(protoreflect.ServiceDescriptor)
+	if !ok {
+		return nil, fmt.Errorf("%q is not a service", serviceName)
+	}
+
+	methodDesc := serviceDesc.Methods().ByName(protoreflect.Name(methodName))
+	if methodDesc == nil {
+		return nil, fmt.Errorf("method %q not found in service %q", methodName, serviceName)
+	}
+
+	return methodDesc, nil
+}
+
+// decodeRequest decodes serialized request message of the method into a generic JSON-like map.
+func decodeRequest(files *protoregistry.Files, fullMethodName string, payload []byte) (map[string]interface{}, error) {
+	methodDesc, err := lookupMethod(files, fullMethodName)
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeMessage(methodDesc.Input(), payload)
+}
+
+// decodeMessage decodes serialized message into a generic JSON-like map with protobuf field names as keys.
+func decodeMessage(desc protoreflect.MessageDescriptor, payload []byte) (map[string]interface{}, error) {
+	msg := dynamicpb.NewMessage(desc)
+
+	if err := proto.Unmarshal(payload, msg); err != nil {
+		return nil, fmt.Errorf("error decoding request %s: %w", desc.FullName(), err)
+	}
+
+	encoded, err := protojson.MarshalOptions{UseProtoNames: true}.Marshal(msg)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding request %s: %w", desc.FullName(), err)
+	}
+
+	var result map[string]interface{}
+
+	if err = json.Unmarshal(encoded, &result); err != nil {
+		return nil, fmt.Errorf("error encoding request %s: %w", desc.FullName(), err)
+	}
+
+	return result, nil
+}
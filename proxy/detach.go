@@ -0,0 +1,103 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// WithDetachedUpstream forwards the upstream calls of the listed methods with a detached context.
+//
+// By default upstream calls inherit the deadline and the cancellation of the client call, so that a client
+// disconnect aborts the backend work. For "trigger and detach" operations this is not desirable: with this option
+// the upstream context keeps the values of the client context (metadata, peer, etc.), but it is not canceled when
+// the client goes away, and it has its own deadline set to timeout instead of the client deadline.
+//
+// The upstream call is canceled only when the timeout expires, so the timeout should be positive and bound the time
+// the backend needs to finish the work. The upstream context is released once the handler returned and the upstream
+// calls finished.
+func WithDetachedUpstream(timeout time.Duration, fullMethodNames ...string) Option {
+	return func(o *handlerOptions) {
+		if timeout <= 0 {
+			o.invalid("detached upstream timeout should be positive, got %s", timeout)
+		}
+
+		if o.detachedMethods == nil {
+			o.detachedMethods = map[string]time.Duration{}
+		}
+
+		for _, name := range fullMethodNames {
+			o.detachedMethods[name] = timeout
+		}
+	}
+}
+
+// upstreamContext returns the parent context for the upstream calls.
+//
+// The context of the detached methods is canceled by the returned function only once the forwarders reading
+// the upstream calls finished (see detachedUpstream).
+func (o *handlerOptions) upstreamContext(ctx context.Context, fullMethodName string) (context.Context, context.CancelFunc) {
+	timeout, detached := o.detachedMethods[fullMethodName]
+	if !detached {
+		return context.WithCancel(ctx)
+	}
+
+	upstreamCtx, cancel := context.WithTimeout(detachedContext{ctx}, timeout)
+
+	upstream := &detachedUpstream{cancel: cancel, refs: 1}
+
+	var once sync.Once
+
+	return context.WithValue(upstreamCtx, detachedUpstreamKey{}, upstream), func() { once.Do(upstream.release) }
+}
+
+type detachedUpstreamKey struct{}
+
+// detachedUpstream cancels the detached upstream context once the handler returned and the forwarders reading
+// the upstream calls finished, as the upstream calls might outlive the handler.
+type detachedUpstream struct {
+	cancel context.CancelFunc
+	refs   int32
+}
+
+// detachedUpstreamFromContext returns the detachedUpstream of the upstream context, nil if not detached.
+func detachedUpstreamFromContext(ctx context.Context) *detachedUpstream {
+	upstream, _ := ctx.Value(detachedUpstreamKey{}).(*detachedUpstream)
+
+	return upstream
+}
+
+func (d *detachedUpstream) acquire() {
+	atomic.AddInt32(&d.refs, 1)
+}
+
+func (d *detachedUpstream) release() {
+	if atomic.AddInt32(&d.refs, -1) == 0 {
+		d.cancel()
+	}
+}
+
+// detachedContext keeps the values of the parent context, but not its deadline and cancellation.
+type detachedContext struct {
+	parent context.Context //nolint:containedctx
+}
+
+func (detachedContext) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+func (detachedContext) Done() <-chan struct{} {
+	return nil
+}
+
+func (detachedContext) Err() error {
+	return nil
+}
+
+func (ctx detachedContext) Value(key interface{}) interface{} {
+	return ctx.parent.Value(key)
+}
@@ -0,0 +1,108 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/noncepad/grpc-proxy/proxy"
+	pb "github.com/noncepad/grpc-proxy/testservice"
+)
+
+// slowService reports whether the Ping and PingEmpty calls ran to completion.
+type slowService struct {
+	assertingService
+
+	results chan error
+}
+
+func (s *slowService) work(ctx context.Context) error {
+	select {
+	case <-time.After(300 * time.Millisecond):
+		s.results <- nil
+	case <-ctx.Done():
+		s.results <- ctx.Err()
+	}
+
+	return ctx.Err()
+}
+
+func (s *slowService) Ping(ctx context.Context, ping *pb.PingRequest) (*pb.PingResponse, error) {
+	if err := s.work(ctx); err != nil {
+		return nil, err
+	}
+
+	return &pb.PingResponse{Value: ping.Value}, nil
+}
+
+func (s *slowService) PingEmpty(ctx context.Context, _ *pb.Empty) (*pb.PingResponse, error) {
+	if err := s.work(ctx); err != nil {
+		return nil, err
+	}
+
+	return &pb.PingResponse{}, nil
+}
+
+func TestDetachedUpstream(t *testing.T) {
+	service := &slowService{results: make(chan error, 1)}
+
+	upstreamCtxs := make(chan context.Context, 1)
+
+	h := newTestHarnessWithService(t, service, one2oneDirector,
+		proxy.WithDetachedUpstream(5*time.Second, "/talos.testproto.TestService/Ping"),
+		proxy.WithUpstreamStreamInterceptors(func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string,
+			streamer grpc.Streamer, opts ...grpc.CallOption,
+		) (grpc.ClientStream, error) {
+			if method == "/talos.testproto.TestService/Ping" {
+				upstreamCtxs <- ctx
+			}
+
+			return streamer(ctx, desc, cc, method, opts...)
+		}))
+
+	call := func(f func(ctx context.Context) error) error {
+		ctx, cancel := context.WithTimeout(testContext(t), 50*time.Millisecond)
+		defer cancel()
+
+		err := f(ctx)
+		require.Error(t, err)
+		assert.Equal(t, codes.DeadlineExceeded, status.Code(err))
+
+		select {
+		case result := <-service.results:
+			return result
+		case <-time.After(5 * time.Second):
+			require.FailNow(t, "backend call didn't finish")
+		}
+
+		return nil
+	}
+
+	// detached call runs to completion after the client went away
+	assert.NoError(t, call(func(ctx context.Context) error {
+		_, err := h.client.Ping(ctx, &pb.PingRequest{Value: "foo"})
+
+		return err
+	}))
+
+	// and the upstream context is released once the upstream call finished, before the timeout
+	upstreamCtx := <-upstreamCtxs
+
+	assert.Eventually(t, func() bool { return upstreamCtx.Err() != nil }, time.Second, 10*time.Millisecond)
+
+	// regular call is canceled with the client
+	assert.Error(t, call(func(ctx context.Context) error {
+		_, err := h.client.PingEmpty(ctx, &pb.Empty{})
+
+		return err
+	}))
+}
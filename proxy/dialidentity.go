@@ -0,0 +1,47 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+)
+
+// DialIdentity overrides the identity of the upstream connection, e.g. for the multi-tenant upstream gateways
+// which select the tenant by the authority.
+//
+// The connections with the different identities are pooled separately, and they are retired once idle
+// (see ConnPool.IdentityIdleTimeout).
+type DialIdentity struct {
+	// Authority is the :authority of the upstream calls, the target by default.
+	Authority string
+	// ServerName is the TLS server name sent in the handshake (SNI) and verified against the upstream certificate,
+	// the host of the authority by default. It is only applied to the TLS configured with ConnPool.Credentials
+	// or ConnPool.TLSConfig.
+	ServerName string
+}
+
+// key returns the pool key of the connection to the target with the identity.
+func (id DialIdentity) key(target string) string {
+	if id == (DialIdentity{}) {
+		return target
+	}
+
+	return target + "\x00" + id.Authority + "\x00" + id.ServerName
+}
+
+// dialOptions overrides the identity of the connection.
+func (id DialIdentity) dialOptions(options []grpc.DialOption) []grpc.DialOption {
+	if id.Authority != "" {
+		options = append(options, grpc.WithAuthority(id.Authority))
+	}
+
+	return options
+}
+
+// GetWithIdentity returns a connection to the target with the identity override, dialing it if necessary.
+func (p *ConnPool) GetWithIdentity(ctx context.Context, target string, identity DialIdentity) (*grpc.ClientConn, error) {
+	return p.get(ctx, target, identity)
+}
@@ -0,0 +1,166 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy_test
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/noncepad/grpc-proxy/proxy"
+	pb "github.com/noncepad/grpc-proxy/testservice"
+)
+
+func TestDialIdentityAuthority(t *testing.T) {
+	pool := proxy.NewConnPool(grpc.WithTransportCredentials(insecure.NewCredentials()))
+
+	t.Cleanup(func() { pool.Close() }) //nolint: errcheck
+
+	backend := &proxy.DialBackend{
+		Pool: pool,
+		Identity: func(ctx context.Context, fullMethodName string) proxy.DialIdentity {
+			if tenant := metadata.ValueFromIncomingContext(ctx, "tenant"); len(tenant) > 0 {
+				return proxy.DialIdentity{Authority: tenant[0] + ".gateway.local"}
+			}
+
+			return proxy.DialIdentity{}
+		},
+	}
+
+	h := newTestHarnessWithService(t, &metadataEchoService{}, func(upstream proxy.Backend) proxy.StreamDirector {
+		return func(ctx context.Context, fullMethodName string) (proxy.Mode, []proxy.Backend, error) {
+			return proxy.One2One, []proxy.Backend{backend}, nil
+		}
+	})
+
+	backend.Target = h.backendAddr
+
+	authority := func(tenant string) string {
+		ctx := testContext(t)
+		if tenant != "" {
+			ctx = metadata.AppendToOutgoingContext(ctx, "tenant", tenant)
+		}
+
+		stream, err := h.client.PingStream(ctx)
+		require.NoError(t, err)
+
+		require.NoError(t, stream.Send(&pb.PingRequest{Value: ":authority"}))
+
+		out, err := stream.Recv()
+		require.NoError(t, err)
+
+		require.NoError(t, stream.CloseSend())
+
+		return out.Value
+	}
+
+	assert.Equal(t, ":authority=a.gateway.local", authority("a"))
+	assert.Equal(t, ":authority=b.gateway.local", authority("b"))
+	assert.Equal(t, ":authority=a.gateway.local", authority("a"))
+	assert.Equal(t, ":authority="+h.backendAddr, authority(""))
+
+	// connections are pooled per identity
+	assert.Equal(t, uint64(3), pool.Stats().Dials)
+}
+
+func TestDialIdentityIdle(t *testing.T) {
+	h := newTestHarness(t, one2oneDirector)
+
+	pool := proxy.NewConnPool(grpc.WithTransportCredentials(insecure.NewCredentials()))
+	pool.IdentityIdleTimeout = 100 * time.Millisecond
+
+	t.Cleanup(func() { pool.Close() }) //nolint: errcheck
+
+	ctx := testContext(t)
+	events := pool.WatchState(ctx)
+
+	get := func(tenant string) {
+		var identity proxy.DialIdentity
+
+		if tenant != "" {
+			identity.Authority = tenant + ".gateway.local"
+		}
+
+		_, err := pool.GetWithIdentity(ctx, h.backendAddr, identity)
+		require.NoError(t, err)
+	}
+
+	get("")
+	get("a")
+
+	time.Sleep(200 * time.Millisecond)
+
+	// the idle connection of the tenant is retired, the connection without the identity is kept
+	get("b")
+	get("")
+
+	shutdown := map[string]bool{}
+
+	require.Eventually(t, func() bool {
+		for {
+			select {
+			case event := <-events:
+				if event.State == connectivity.Shutdown {
+					shutdown[event.Identity.Authority] = true
+				}
+			default:
+				return shutdown["a.gateway.local"]
+			}
+		}
+	}, 5*time.Second, 10*time.Millisecond)
+
+	assert.Equal(t, map[string]bool{"a.gateway.local": true}, shutdown)
+	assert.Equal(t, uint64(3), pool.Stats().Dials)
+
+	// the tenant connection is dialed again once needed
+	get("a")
+	assert.Equal(t, uint64(4), pool.Stats().Dials)
+}
+
+func TestDialIdentityServerName(t *testing.T) {
+	addr, roots := startTLSUpstream(t)
+
+	_, port, err := net.SplitHostPort(addr)
+	require.NoError(t, err)
+
+	pool := proxy.NewConnPool()
+	pool.TLSConfig = &tls.Config{RootCAs: roots, MinVersion: tls.VersionTLS12}
+
+	t.Cleanup(func() { pool.Close() }) //nolint: errcheck
+
+	h := newTestHarness(t, func(proxy.Backend) proxy.StreamDirector {
+		return func(ctx context.Context, fullMethodName string) (proxy.Mode, []proxy.Backend, error) {
+			return proxy.One2One, []proxy.Backend{&proxy.DialBackend{
+				Pool:   pool,
+				Target: net.JoinHostPort("127.0.0.1", port),
+				Identity: func(ctx context.Context, fullMethodName string) proxy.DialIdentity {
+					if fullMethodName == "/talos.testproto.TestService/PingEmpty" {
+						// the certificate is not valid for the IP address
+						return proxy.DialIdentity{}
+					}
+
+					return proxy.DialIdentity{ServerName: "localhost"}
+				},
+			}}, nil
+		}
+	})
+
+	out, err := h.client.Ping(testContext(t), &pb.PingRequest{Value: "foo"})
+	require.NoError(t, err)
+	assert.Equal(t, "foo", out.Value)
+
+	_, err = h.client.PingEmpty(testContext(t), &pb.Empty{})
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+}
@@ -0,0 +1,248 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+// DialStats describes the connection attempt to the backend made by ConnPool.
+//
+// The phases are measured separately, so that slow upstream dials can be told apart from slow RPCs.
+type DialStats struct {
+	Start time.Time
+	// Err is set if the connection attempt failed.
+	Err error
+	// Target is the pool target, Addr is the resolved address.
+	Target string
+	Addr   string
+	// DNS is the time spent resolving the host name, zero if the address is an IP address.
+	DNS time.Duration
+	// Connect is the time to establish the TCP connection.
+	Connect time.Duration
+	// TLSHandshake is the time of the TLS handshake, it is only measured with ConnPool.Credentials
+	// (or ConnPool.TLSConfig) set.
+	TLSHandshake time.Duration
+	// TLSResumed is set if the TLS handshake resumed the session.
+	TLSResumed bool
+	// FirstByte is the time from the end of the handshake to the first byte received from the backend.
+	FirstByte time.Duration
+	// Total is the time from the start of the attempt to the first byte (or to the failure).
+	Total time.Duration
+}
+
+// telemetryEnabled returns true if the dials should be measured.
+func (p *ConnPool) telemetryEnabled() bool {
+	return p.OnDial != nil || p.OnSlowDial != nil
+}
+
+// connDialOptions returns the dial options for the target with the identity.
+func (p *ConnPool) connDialOptions(target string, identity DialIdentity) []grpc.DialOption {
+	options := identity.dialOptions(append(append([]grpc.DialOption(nil), p.dialOptions...), p.HTTP2.DialOptions()...))
+
+	creds := p.transportCredentials()
+
+	if creds != nil && identity.ServerName != "" {
+		creds = creds.Clone()
+		creds.OverrideServerName(identity.ServerName) //nolint: errcheck,staticcheck
+	}
+
+	if p.telemetryEnabled() {
+		options = append(options, grpc.WithContextDialer(p.dialer(target)))
+
+		if creds != nil {
+			creds = &telemetryCredentials{TransportCredentials: creds}
+		}
+	} else if p.Racing != nil {
+		options = append(options, grpc.WithContextDialer(p.Racing.dial))
+	}
+
+	if creds != nil {
+		options = append(options, grpc.WithTransportCredentials(creds))
+	}
+
+	return options
+}
+
+// dialer returns the measuring dialer for the target.
+func (p *ConnPool) dialer(target string) func(ctx context.Context, addr string) (net.Conn, error) {
+	return func(ctx context.Context, addr string) (net.Conn, error) {
+		conn := &telemetryConn{
+			pool: p,
+			stats: DialStats{
+				Start:  time.Now(),
+				Target: target,
+				Addr:   addr,
+			},
+		}
+
+		host, port, err := net.SplitHostPort(addr)
+		if err != nil {
+			conn.report(err)
+
+			return nil, err
+		}
+
+		dialAddrs := []string{addr}
+
+		if p.Racing != nil {
+			dnsStart := time.Now()
+
+			dialAddrs, err = p.Racing.addresses(ctx, addr)
+			conn.stats.DNS = time.Since(dnsStart)
+
+			if err != nil {
+				conn.report(err)
+
+				return nil, err
+			}
+		} else if net.ParseIP(host) == nil {
+			dnsStart := time.Now()
+
+			var ips []net.IPAddr
+
+			ips, err = net.DefaultResolver.LookupIPAddr(ctx, host)
+			conn.stats.DNS = time.Since(dnsStart)
+
+			if err != nil {
+				conn.report(err)
+
+				return nil, err
+			}
+
+			dialAddrs = dialAddrs[:0]
+
+			for _, ip := range ips {
+				dialAddrs = append(dialAddrs, net.JoinHostPort(ip.String(), port))
+			}
+		}
+
+		connectStart := time.Now()
+
+		if p.Racing != nil {
+			conn.Conn, err = p.Racing.race(ctx, dialAddrs)
+		} else {
+			var dialer net.Dialer
+
+			for _, dialAddr := range dialAddrs {
+				if conn.Conn, err = dialer.DialContext(ctx, "tcp", dialAddr); err == nil {
+					break
+				}
+			}
+		}
+
+		conn.stats.Connect = time.Since(connectStart)
+
+		if err != nil {
+			conn.report(err)
+
+			return nil, err
+		}
+
+		if p.Credentials == nil && p.TLSConfig == nil {
+			// no handshake to measure
+			conn.handshakeDone(time.Now())
+		}
+
+		return conn, nil
+	}
+}
+
+// errClosedBeforeFirstByte is reported if the connection is closed before the backend sent anything.
+var errClosedBeforeFirstByte = errors.New("connection closed before the first byte")
+
+// telemetryConn measures the time to the first byte.
+type telemetryConn struct {
+	net.Conn
+
+	pool         *ConnPool
+	handshakeEnd time.Time
+	stats        DialStats
+	reportOnce   sync.Once
+	handshaked   atomic.Bool
+}
+
+func (c *telemetryConn) handshakeDone(end time.Time) {
+	c.handshakeEnd = end
+	c.handshaked.Store(true)
+}
+
+func (c *telemetryConn) Read(b []byte) (int, error) {
+	n, err := c.Conn.Read(b)
+
+	if n > 0 && c.handshaked.Load() {
+		c.report(nil)
+	}
+
+	return n, err
+}
+
+func (c *telemetryConn) Close() error {
+	c.report(errClosedBeforeFirstByte)
+
+	return c.Conn.Close()
+}
+
+// report reports the stats of the attempt, only the first call has any effect.
+func (c *telemetryConn) report(err error) {
+	c.reportOnce.Do(func() {
+		now := time.Now()
+
+		c.stats.Err = err
+		c.stats.Total = now.Sub(c.stats.Start)
+
+		if err == nil {
+			c.stats.FirstByte = now.Sub(c.handshakeEnd)
+		}
+
+		if c.pool.OnDial != nil {
+			c.pool.OnDial(c.stats)
+		}
+
+		if c.pool.OnSlowDial != nil && c.pool.SlowDialThreshold > 0 && c.stats.Total > c.pool.SlowDialThreshold {
+			c.pool.OnSlowDial(c.stats)
+		}
+	})
+}
+
+// telemetryCredentials measures the TLS handshake.
+type telemetryCredentials struct {
+	credentials.TransportCredentials
+}
+
+func (c *telemetryCredentials) ClientHandshake(ctx context.Context, authority string, rawConn net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	tc, ok := rawConn.(*telemetryConn)
+	if !ok {
+		return c.TransportCredentials.ClientHandshake(ctx, authority, rawConn)
+	}
+
+	start := time.Now()
+
+	conn, info, err := c.TransportCredentials.ClientHandshake(ctx, authority, rawConn)
+
+	tc.stats.TLSHandshake = time.Since(start)
+
+	if err != nil {
+		tc.report(err)
+
+		return conn, info, err
+	}
+
+	tc.stats.TLSResumed = tlsResumed(info)
+	tc.handshakeDone(time.Now())
+
+	return conn, info, nil
+}
+
+func (c *telemetryCredentials) Clone() credentials.TransportCredentials {
+	return &telemetryCredentials{TransportCredentials: c.TransportCredentials.Clone()}
+}
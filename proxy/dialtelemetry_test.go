@@ -0,0 +1,154 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"math/big"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+
+	"github.com/noncepad/grpc-proxy/proxy"
+	pb "github.com/noncepad/grpc-proxy/testservice"
+)
+
+// startTLSUpstream starts TestService upstream with a self-signed certificate for localhost.
+func startTLSUpstream(t *testing.T) (string, *x509.CertPool) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		DNSNames:     []string{"localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		IsCA:         true,
+
+		BasicConstraintsValid: true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	roots := x509.NewCertPool()
+	roots.AddCert(cert)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	server := grpc.NewServer(grpc.Creds(credentials.NewTLS(&tls.Config{
+		Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}},
+		MinVersion:   tls.VersionTLS12,
+	})))
+	pb.RegisterTestServiceServer(server, &assertingService{t: t})
+
+	go server.Serve(listener) //nolint: errcheck
+
+	t.Cleanup(server.Stop)
+
+	_, port, err := net.SplitHostPort(listener.Addr().String())
+	require.NoError(t, err)
+
+	return net.JoinHostPort("localhost", port), roots
+}
+
+func TestDialTelemetry(t *testing.T) {
+	addr, roots := startTLSUpstream(t)
+
+	// reserve the address with no upstream
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	closedAddr := listener.Addr().String()
+	require.NoError(t, listener.Close())
+
+	var (
+		dials, slowDials []proxy.DialStats
+		mu               sync.Mutex
+	)
+
+	pool := proxy.NewConnPool()
+	pool.Credentials = credentials.NewTLS(&tls.Config{RootCAs: roots, MinVersion: tls.VersionTLS12})
+	pool.OnDial = func(stats proxy.DialStats) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		dials = append(dials, stats)
+	}
+	pool.SlowDialThreshold = time.Nanosecond
+	pool.OnSlowDial = func(stats proxy.DialStats) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		slowDials = append(slowDials, stats)
+	}
+
+	t.Cleanup(func() { pool.Close() }) //nolint: errcheck
+
+	h := newTestHarness(t, func(proxy.Backend) proxy.StreamDirector {
+		return func(ctx context.Context, fullMethodName string) (proxy.Mode, []proxy.Backend, error) {
+			if fullMethodName == "/talos.testproto.TestService/PingEmpty" {
+				return proxy.One2One, []proxy.Backend{&proxy.DialBackend{Pool: pool, Target: closedAddr}}, nil
+			}
+
+			return proxy.One2One, []proxy.Backend{&proxy.DialBackend{Pool: pool, Target: addr}}, nil
+		}
+	})
+
+	out, err := h.client.Ping(testContext(t), &pb.PingRequest{Value: "foo"})
+	require.NoError(t, err)
+	assert.Equal(t, "foo", out.Value)
+
+	_, err = h.client.PingEmpty(testContext(t), &pb.Empty{})
+	require.Error(t, err)
+
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+
+		return len(dials) >= 2
+	}, 5*time.Second, 10*time.Millisecond)
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	assert.Len(t, slowDials, len(dials))
+
+	byTarget := map[string]proxy.DialStats{}
+	for _, stats := range dials {
+		byTarget[stats.Target] = stats
+	}
+
+	ok := byTarget[addr]
+	require.NoError(t, ok.Err)
+	assert.Positive(t, ok.DNS)
+	assert.Positive(t, ok.Connect)
+	assert.Positive(t, ok.TLSHandshake)
+	assert.Positive(t, ok.FirstByte)
+	assert.GreaterOrEqual(t, ok.Total, ok.DNS+ok.Connect+ok.TLSHandshake+ok.FirstByte)
+
+	failed := byTarget[closedAddr]
+	assert.Error(t, failed.Err)
+	assert.Zero(t, failed.DNS)
+	assert.Zero(t, failed.TLSHandshake)
+}
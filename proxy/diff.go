@@ -0,0 +1,277 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// DiffMode defines how the responses are compared by ResponseDiff.
+type DiffMode int
+
+// Diff modes.
+const (
+	// DiffRaw compares the serialized responses byte by byte.
+	DiffRaw DiffMode = iota
+	// DiffFields decodes the responses with the method descriptor (see WithDescriptorFiles) and compares them
+	// field by field, skipping the ignored fields.
+	DiffFields
+)
+
+// ResponseDiff compares the responses of the primary and the canary backends for the shadow traffic (see WithShadowTraffic).
+//
+// ResponseDiff counts matches and mismatches, and keeps the most recent mismatch samples. ResponseDiff implements
+// http.Handler which serves DiffStats as JSON, so that it can be mounted on the admin HTTP server.
+type ResponseDiff struct {
+	ignore     [][]string
+	samples    []DiffSample
+	stats      DiffStats
+	mode       DiffMode
+	maxSamples int
+
+	mu sync.Mutex
+}
+
+// DiffStats is a snapshot of the ResponseDiff statistics.
+type DiffStats struct {
+	Samples []DiffSample `json:"samples"`
+	// Compared is the number of calls compared, Matched + Mismatched == Compared.
+	Compared   uint64 `json:"compared"`
+	Matched    uint64 `json:"matched"`
+	Mismatched uint64 `json:"mismatched"`
+	// Failed is the number of mirrored calls which couldn't be compared, e.g. the canary connection failed.
+	Failed uint64 `json:"failed"`
+}
+
+// DiffSample describes a mismatch between the primary and the canary responses.
+type DiffSample struct {
+	Time    time.Time `json:"time"`
+	Method  string    `json:"method"`
+	Reason  string    `json:"reason"`
+	Primary [][]byte  `json:"primary"`
+	Canary  [][]byte  `json:"canary"`
+}
+
+// NewResponseDiff creates ResponseDiff which keeps up to maxSamples mismatch samples.
+//
+// Ignored fields are specified as paths of the protobuf field names separated by dots, e.g. "metadata.hostname".
+// Repeated message fields are traversed, so that the rest of the path is applied to each element. Ignored fields are
+// only supported in DiffFields mode.
+func NewResponseDiff(mode DiffMode, maxSamples int, ignoreFields ...string) *ResponseDiff {
+	d := &ResponseDiff{
+		mode:       mode,
+		maxSamples: maxSamples,
+	}
+
+	for _, field := range ignoreFields {
+		d.ignore = append(d.ignore, strings.Split(field, "."))
+	}
+
+	return d
+}
+
+// Stats returns the current statistics.
+func (d *ResponseDiff) Stats() DiffStats {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	stats := d.stats
+	stats.Samples = append([]DiffSample(nil), d.samples...)
+
+	return stats
+}
+
+// ServeHTTP implements http.Handler.
+func (d *ResponseDiff) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	json.NewEncoder(w).Encode(d.Stats()) //nolint:errcheck
+}
+
+// failed records the call which couldn't be compared.
+func (d *ResponseDiff) failed() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.stats.Failed++
+}
+
+// compare compares the responses and the errors of the primary and the canary calls.
+func (d *ResponseDiff) compare(resolver *DescriptorResolver, fullMethodName string, primary, canary [][]byte, primaryErr, canaryErr error) {
+	reason := d.diff(resolver, fullMethodName, primary, canary, primaryErr, canaryErr)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.stats.Compared++
+
+	if reason == "" {
+		d.stats.Matched++
+
+		return
+	}
+
+	d.stats.Mismatched++
+
+	if d.maxSamples <= 0 {
+		return
+	}
+
+	if len(d.samples) >= d.maxSamples {
+		d.samples = append(d.samples[:0], d.samples[1:]...)
+	}
+
+	d.samples = append(d.samples, DiffSample{
+		Time:    time.Now(),
+		Method:  fullMethodName,
+		Reason:  reason,
+		Primary: primary,
+		Canary:  canary,
+	})
+}
+
+// diff returns the description of the first difference, or empty string if the responses match.
+func (d *ResponseDiff) diff(resolver *DescriptorResolver, fullMethodName string, primary, canary [][]byte, primaryErr, canaryErr error) string {
+	if primaryCode, canaryCode := status.Code(primaryErr), status.Code(canaryErr); primaryCode != canaryCode {
+		return fmt.Sprintf("status code %s != %s", primaryCode, canaryCode)
+	}
+
+	if len(primary) != len(canary) {
+		return fmt.Sprintf("number of responses %d != %d", len(primary), len(canary))
+	}
+
+	var output protoreflect.MessageDescriptor
+
+	if d.mode == DiffFields {
+		methodDesc, err := resolver.FindMethod(context.Background(), fullMethodName)
+		if err != nil {
+			return fmt.Sprintf("error looking up method: %s", err)
+		}
+
+		output = methodDesc.Output()
+	}
+
+	for i := range primary {
+		if output == nil {
+			if !bytes.Equal(primary[i], canary[i]) {
+				return fmt.Sprintf("response %d differs", i)
+			}
+
+			continue
+		}
+
+		if path, err := d.diffFields(output, primary[i], canary[i]); err != nil {
+			return fmt.Sprintf("response %d: %s", i, err)
+		} else if path != "" {
+			return fmt.Sprintf("response %d: field %q differs", i, path)
+		}
+	}
+
+	return ""
+}
+
+// diffFields decodes the responses and returns the path of the first differing field.
+func (d *ResponseDiff) diffFields(desc protoreflect.MessageDescriptor, primary, canary []byte) (string, error) {
+	primaryMsg, canaryMsg := dynamicpb.NewMessage(desc), dynamicpb.NewMessage(desc)
+
+	if err := proto.Unmarshal(primary, primaryMsg); err != nil {
+		return "", fmt.Errorf("error decoding primary response: %w", err)
+	}
+
+	if err := proto.Unmarshal(canary, canaryMsg); err != nil {
+		return "", fmt.Errorf("error decoding canary response: %w", err)
+	}
+
+	for _, path := range d.ignore {
+		clearField(primaryMsg, path)
+		clearField(canaryMsg, path)
+	}
+
+	return diffMessages(primaryMsg, canaryMsg, ""), nil
+}
+
+// clearField clears the field by path, traversing repeated messages.
+func clearField(msg protoreflect.Message, path []string) {
+	fd := msg.Descriptor().Fields().ByName(protoreflect.Name(path[0]))
+	if fd == nil || !msg.Has(fd) {
+		return
+	}
+
+	if len(path) == 1 {
+		msg.Clear(fd)
+
+		return
+	}
+
+	if fd.Kind() != protoreflect.MessageKind || fd.IsMap() {
+		return
+	}
+
+	if fd.IsList() {
+		list := msg.Get(fd).List()
+
+		for i := 0; i < list.Len(); i++ {
+			clearField(list.Get(i).Message(), path[1:])
+		}
+
+		return
+	}
+
+	clearField(msg.Get(fd).Message(), path[1:])
+}
+
+// diffMessages returns the path of the first field which differs in the messages.
+func diffMessages(a, b protoreflect.Message, prefix string) string {
+	fields := a.Descriptor().Fields()
+
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		path := prefix + string(fd.Name())
+
+		if fd.Kind() == protoreflect.MessageKind && !fd.IsList() && !fd.IsMap() && a.Has(fd) && b.Has(fd) {
+			if diffPath := diffMessages(a.Get(fd).Message(), b.Get(fd).Message(), path+"."); diffPath != "" {
+				return diffPath
+			}
+
+			continue
+		}
+
+		if !fieldEqual(a, b, fd) {
+			return path
+		}
+	}
+
+	if !bytes.Equal(a.GetUnknown(), b.GetUnknown()) {
+		return prefix + "<unknown fields>"
+	}
+
+	return ""
+}
+
+// fieldEqual compares the single field of the messages.
+func fieldEqual(a, b protoreflect.Message, fd protoreflect.FieldDescriptor) bool {
+	onlyField := func(msg protoreflect.Message) proto.Message {
+		result := msg.New()
+
+		if msg.Has(fd) {
+			result.Set(fd, msg.Get(fd))
+		}
+
+		return result.Interface()
+	}
+
+	return proto.Equal(onlyField(a), onlyField(b))
+}
@@ -38,6 +38,7 @@ type Backend interface {
 	// response from each of the backends participating in the proxying.
 	//
 	// If not additional proxying is required, simply returning the buffer without changes works fine.
+	// See ContextBackend to access the context of the call.
 	AppendInfo(streaming bool, resp []byte) ([]byte, error)
 
 	// BuildError is called to convert error from upstream into response field.
@@ -51,6 +52,7 @@ type Backend interface {
 	// N2 error responses so that N1 + N2 == N.
 	//
 	// If BuildError returns nil, error is returned as grpc error (failing whole request).
+	// See ContextBackend to access the context of the call.
 	BuildError(streaming bool, err error) ([]byte, error)
 }
 
@@ -102,3 +104,23 @@ func (sb *SingleBackend) BuildError(streaming bool, err error) ([]byte, error) {
 //
 // See the rather rich example.
 type StreamDirector func(ctx context.Context, fullMethodName string) (Mode, []Backend, error)
+
+// backendAs finds the first backend in the chain of wrapped backends (see BackendWithMetadata) which implements T.
+func backendAs[T Backend](backend Backend) (T, bool) {
+	for backend != nil {
+		if b, ok := backend.(T); ok {
+			return b, true
+		}
+
+		wrapper, ok := backend.(interface{ Unwrap() Backend })
+		if !ok {
+			break
+		}
+
+		backend = wrapper.Unwrap()
+	}
+
+	var zero T
+
+	return zero, false
+}
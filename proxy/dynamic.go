@@ -0,0 +1,153 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ServiceRegistry is the table of the proxied services which can be changed on the running proxy, so that
+// the control plane can expose new upstream APIs without restarting the proxy process.
+//
+// The grpc.Server doesn't allow registering services once it is serving, so the registry is installed
+// as the unknown service handler of the server:
+//
+//	registry := proxy.NewServiceRegistry()
+//	server := grpc.NewServer(grpc.UnknownServiceHandler(registry.Handler()), ...)
+//
+// Services registered with the server itself (including the ones registered with RegisterService) take precedence
+// over the registry.
+type ServiceRegistry struct {
+	services map[string]*handler
+	fallback grpc.StreamHandler
+
+	mu sync.RWMutex
+}
+
+// NewServiceRegistry creates the empty ServiceRegistry.
+func NewServiceRegistry() *ServiceRegistry {
+	return &ServiceRegistry{
+		services: map[string]*handler{},
+	}
+}
+
+// SetFallback sets the handler of the calls to the services not in the registry (e.g. TransparentHandler),
+// by default they fail with codes.Unimplemented.
+func (r *ServiceRegistry) SetFallback(fallback grpc.StreamHandler) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.fallback = fallback
+}
+
+// Register sets up the proxy handler for the service, replacing the previous one of the same service.
+//
+// The options are the same as for RegisterService. If the method names are configured with WithMethodNames,
+// only those methods are proxied, otherwise all methods of the service are.
+func (r *ServiceRegistry) Register(director StreamDirector, serviceName string, options ...Option) error {
+	streamer, err := newHandler(director, serviceName, false, options)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	previous := r.services[serviceName]
+	r.services[serviceName] = streamer
+	r.mu.Unlock()
+
+	if previous != nil {
+		previous.options.topology.unregister(previous)
+	}
+
+	streamer.options.topology.register(streamer, false)
+
+	return nil
+}
+
+// Unregister removes the service from the registry, it reports whether the service was registered.
+//
+// The calls in flight are not interrupted, new calls are handled by the fallback.
+func (r *ServiceRegistry) Unregister(serviceName string) bool {
+	r.mu.Lock()
+	streamer, ok := r.services[serviceName]
+	delete(r.services, serviceName)
+	r.mu.Unlock()
+
+	if ok {
+		streamer.options.topology.unregister(streamer)
+	}
+
+	return ok
+}
+
+// Services returns the sorted names of the registered services.
+func (r *ServiceRegistry) Services() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make([]string, 0, len(r.services))
+
+	for name := range r.services {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// Handler returns the handler dispatching the calls to the registered services, it should be used
+// as a `grpc.UnknownServiceHandler`.
+func (r *ServiceRegistry) Handler() grpc.StreamHandler {
+	return func(srv interface{}, serverStream grpc.ServerStream) error {
+		fullMethodName, ok := grpc.MethodFromServerStream(serverStream)
+		if !ok {
+			return newError(ErrInternal, "lowLevelServerStream doesn't exist in the context")
+		}
+
+		r.mu.RLock()
+		streamer, fallback := r.lookup(fullMethodName), r.fallback
+		r.mu.RUnlock()
+
+		switch {
+		case streamer != nil:
+			return streamer.handler(srv, serverStream)
+		case fallback != nil:
+			return fallback(srv, serverStream)
+		default:
+			return status.Errorf(codes.Unimplemented, "unknown method %s", fullMethodName)
+		}
+	}
+}
+
+// lookup finds the handler of the method.
+func (r *ServiceRegistry) lookup(fullMethodName string) *handler {
+	pos := strings.LastIndex(fullMethodName, "/")
+	if pos <= 0 {
+		return nil
+	}
+
+	streamer, ok := r.services[fullMethodName[1:pos]]
+	if !ok {
+		return nil
+	}
+
+	if len(streamer.options.methodNames) == 0 {
+		return streamer
+	}
+
+	for _, name := range streamer.options.methodNames {
+		if name == fullMethodName[pos+1:] {
+			return streamer
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,98 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy_test
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/noncepad/grpc-proxy/proxy"
+	pb "github.com/noncepad/grpc-proxy/testservice"
+)
+
+func TestServiceRegistry(t *testing.T) {
+	h := newTestHarness(t, one2oneDirector)
+
+	backend := &proxy.SingleBackend{
+		GetConn: func(ctx context.Context) (context.Context, *grpc.ClientConn, error) {
+			md, _ := metadata.FromIncomingContext(ctx)
+
+			return metadata.NewOutgoingContext(ctx, md), h.backendConn, nil
+		},
+	}
+
+	topology := proxy.NewTopology()
+	registry := proxy.NewServiceRegistry()
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	server := grpc.NewServer(
+		grpc.CustomCodec(proxy.Codec()), //nolint: staticcheck
+		grpc.UnknownServiceHandler(registry.Handler()),
+	)
+
+	go server.Serve(lis) //nolint: errcheck
+
+	defer server.Stop()
+
+	conn, err := grpc.Dial(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+
+	defer conn.Close() //nolint: errcheck
+
+	client := pb.NewTestServiceClient(conn)
+	ctx := testContext(t)
+
+	// nothing registered yet, the server is serving already
+	_, err = client.Ping(ctx, &pb.PingRequest{Value: "foo"})
+	assert.Equal(t, codes.Unimplemented, status.Code(err))
+
+	require.NoError(t, registry.Register(one2oneDirector(backend), "talos.testproto.TestService",
+		proxy.WithMethodNames("Ping"),
+		proxy.WithTopology(topology)))
+
+	assert.Equal(t, []string{"talos.testproto.TestService"}, registry.Services())
+	require.Len(t, topology.Snapshot().Handlers, 1)
+
+	resp, err := client.Ping(ctx, &pb.PingRequest{Value: "foo"})
+	require.NoError(t, err)
+	assert.Equal(t, "foo", resp.Value)
+
+	// methods not registered are not proxied
+	_, err = client.PingEmpty(ctx, &pb.Empty{})
+	assert.Equal(t, codes.Unimplemented, status.Code(err))
+
+	// registering again replaces the service
+	require.NoError(t, registry.Register(one2oneDirector(backend), "talos.testproto.TestService", proxy.WithTopology(topology)))
+	require.Len(t, topology.Snapshot().Handlers, 1)
+
+	_, err = client.PingEmpty(ctx, &pb.Empty{})
+	require.NoError(t, err)
+
+	assert.True(t, registry.Unregister("talos.testproto.TestService"))
+	assert.False(t, registry.Unregister("talos.testproto.TestService"))
+	assert.Empty(t, topology.Snapshot().Handlers)
+
+	_, err = client.Ping(ctx, &pb.PingRequest{Value: "foo"})
+	assert.Equal(t, codes.Unimplemented, status.Code(err))
+
+	// the fallback handles the unregistered services
+	fallback, err := proxy.NewTransparentHandler(one2oneDirector(backend))
+	require.NoError(t, err)
+
+	registry.SetFallback(fallback)
+
+	_, err = client.Ping(ctx, &pb.PingRequest{Value: "foo"})
+	require.NoError(t, err)
+}
@@ -0,0 +1,182 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy
+
+import (
+	"context"
+	"sync/atomic"
+
+	"google.golang.org/grpc"
+)
+
+// EarlyBufferOverflow is the behavior of the early frame buffer when it is full.
+type EarlyBufferOverflow int
+
+// Early frame buffer overflow behaviors.
+const (
+	// EarlyBufferBlock stops reading the client frames until the backends are selected, so the client is slowed down
+	// by the flow control.
+	EarlyBufferBlock EarlyBufferOverflow = iota
+	// EarlyBufferFail fails the call with ErrEarlyBufferOverflow.
+	EarlyBufferFail
+)
+
+// EarlyBufferPolicy configures the buffering of the client frames received while the director is running.
+type EarlyBufferPolicy struct {
+	// MaxFrames is the number of the frames buffered (default 16).
+	MaxFrames int
+	// MaxBytes is the total size of the frames buffered, zero means no limit.
+	MaxBytes int
+	// Overflow is the behavior when the buffer is full.
+	Overflow EarlyBufferOverflow
+}
+
+// WithEarlyFrameBuffering buffers the client frames received while the director is running, so that the client
+// can start streaming immediately even if the director is slow (e.g. remote policy check). The buffered frames are
+// forwarded to the backends as usual once they are selected.
+//
+// If fullMethodNames is empty, the policy is applied to all methods.
+func WithEarlyFrameBuffering(policy EarlyBufferPolicy, fullMethodNames ...string) Option {
+	if policy.MaxFrames <= 0 {
+		policy.MaxFrames = 16
+	}
+
+	return func(o *handlerOptions) {
+		if policy.MaxBytes < 0 {
+			o.invalid("early frame buffer size should not be negative, got %d", policy.MaxBytes)
+		}
+
+		if o.earlyBuffers == nil {
+			o.earlyBuffers = map[string]EarlyBufferPolicy{}
+		}
+
+		if len(fullMethodNames) == 0 {
+			o.earlyBuffers[""] = policy
+
+			return
+		}
+
+		for _, name := range fullMethodNames {
+			o.earlyBuffers[name] = policy
+		}
+	}
+}
+
+// earlyBufferPolicy returns the early buffering policy of the method.
+func (o *handlerOptions) earlyBufferPolicy(fullMethodName string) (EarlyBufferPolicy, bool) {
+	policy, ok := o.earlyBuffers[fullMethodName]
+	if !ok {
+		policy, ok = o.earlyBuffers[""]
+	}
+
+	return policy, ok
+}
+
+// earlyFrame is the frame (or the error) received while the director is running.
+type earlyFrame struct {
+	payload []byte
+	err     error
+}
+
+// earlyBufferingServerStream reads the client frames in the background until the director finishes,
+// and replays them before reading further frames from the stream.
+type earlyBufferingServerStream struct {
+	grpc.ServerStream
+
+	policy EarlyBufferPolicy
+	method string
+	cancel context.CancelFunc
+
+	frames  chan earlyFrame
+	stopped int32
+	err     atomic.Value
+}
+
+// startEarlyBuffering starts reading the client frames, cancel is called on the overflow with EarlyBufferFail.
+func startEarlyBuffering(serverStream grpc.ServerStream, policy EarlyBufferPolicy, fullMethodName string, cancel context.CancelFunc) *earlyBufferingServerStream {
+	s := &earlyBufferingServerStream{
+		ServerStream: serverStream,
+		policy:       policy,
+		method:       fullMethodName,
+		cancel:       cancel,
+		// one extra slot for the frame received after the director finished
+		frames: make(chan earlyFrame, policy.MaxFrames+1),
+	}
+
+	go s.run()
+
+	return s
+}
+
+func (s *earlyBufferingServerStream) run() {
+	defer close(s.frames)
+
+	var frames, size int
+
+	for atomic.LoadInt32(&s.stopped) == 0 {
+		f := &Frame{}
+
+		if err := s.ServerStream.RecvMsg(f); err != nil {
+			s.frames <- earlyFrame{err: err}
+
+			return
+		}
+
+		if atomic.LoadInt32(&s.stopped) == 0 {
+			frames++
+			size += len(f.payload)
+
+			if frames > s.policy.MaxFrames || (s.policy.MaxBytes > 0 && size > s.policy.MaxBytes) {
+				if s.policy.Overflow == EarlyBufferFail {
+					s.err.Store(newError(ErrEarlyBufferOverflow, "more than %d frames (%d bytes) of %s received before backend selection",
+						s.policy.MaxFrames, s.policy.MaxBytes, s.method))
+					s.cancel()
+
+					return
+				}
+			}
+		}
+
+		if f.payload == nil {
+			f.payload = []byte{}
+		}
+
+		s.frames <- earlyFrame{payload: f.payload}
+
+		if s.policy.Overflow == EarlyBufferBlock && (frames >= s.policy.MaxFrames || (s.policy.MaxBytes > 0 && size >= s.policy.MaxBytes)) {
+			// the remaining frames are read once the backends are selected
+			return
+		}
+	}
+}
+
+// stop stops the background reading, it returns the overflow error, if any.
+func (s *earlyBufferingServerStream) stop() error {
+	atomic.StoreInt32(&s.stopped, 1)
+
+	if err, ok := s.err.Load().(error); ok {
+		return err
+	}
+
+	return nil
+}
+
+func (s *earlyBufferingServerStream) RecvMsg(m interface{}) error {
+	early, ok := <-s.frames
+	if !ok {
+		return s.ServerStream.RecvMsg(m)
+	}
+
+	if early.err != nil {
+		return early.err
+	}
+
+	if f, ok := m.(*Frame); ok {
+		f.payload = early.payload
+
+		return nil
+	}
+
+	return Codec().Unmarshal(early.payload, m)
+}
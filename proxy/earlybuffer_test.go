@@ -0,0 +1,91 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy_test
+
+import (
+	"context"
+	"io"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/noncepad/grpc-proxy/proxy"
+	pb "github.com/noncepad/grpc-proxy/testservice"
+)
+
+func TestEarlyFrameBuffering(t *testing.T) {
+	slowDirector := func(backend proxy.Backend) proxy.StreamDirector {
+		return func(ctx context.Context, fullMethodName string) (proxy.Mode, []proxy.Backend, error) {
+			select {
+			case <-ctx.Done():
+				return proxy.One2One, nil, ctx.Err()
+			case <-time.After(200 * time.Millisecond):
+			}
+
+			return proxy.One2One, []proxy.Backend{backend}, nil
+		}
+	}
+
+	t.Run("block", func(t *testing.T) {
+		h := newTestHarness(t, slowDirector, proxy.WithEarlyFrameBuffering(proxy.EarlyBufferPolicy{MaxFrames: 2}))
+
+		stream, err := h.client.PingStream(testContext(t))
+		require.NoError(t, err)
+
+		for i := 0; i < 5; i++ {
+			require.NoError(t, stream.Send(&pb.PingRequest{Value: strconv.Itoa(i)}))
+		}
+
+		require.NoError(t, stream.CloseSend())
+
+		for i := 0; i < 5; i++ {
+			resp, err := stream.Recv()
+			require.NoError(t, err)
+			assert.Equal(t, strconv.Itoa(i), resp.Value)
+		}
+
+		_, err = stream.Recv()
+		require.ErrorIs(t, err, io.EOF)
+	})
+
+	t.Run("fail", func(t *testing.T) {
+		h := newTestHarness(t, func(backend proxy.Backend) proxy.StreamDirector {
+			return func(ctx context.Context, fullMethodName string) (proxy.Mode, []proxy.Backend, error) {
+				<-ctx.Done()
+
+				return proxy.One2One, nil, ctx.Err()
+			}
+		}, proxy.WithEarlyFrameBuffering(proxy.EarlyBufferPolicy{MaxFrames: 2, Overflow: proxy.EarlyBufferFail}))
+
+		ctx, cancel := context.WithTimeout(testContext(t), 5*time.Second)
+		defer cancel()
+
+		stream, err := h.client.PingStream(ctx)
+		require.NoError(t, err)
+
+		for i := 0; i < 3; i++ {
+			require.NoError(t, stream.Send(&pb.PingRequest{Value: strconv.Itoa(i)}))
+		}
+
+		_, err = stream.Recv()
+		assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+		proxyErr, ok := proxy.FromError(err)
+		require.True(t, ok)
+		assert.Equal(t, proxy.ReasonEarlyBufferOverflow, proxyErr.Reason)
+	})
+
+	t.Run("unary", func(t *testing.T) {
+		h := newTestHarness(t, slowDirector, proxy.WithEarlyFrameBuffering(proxy.EarlyBufferPolicy{MaxFrames: 1, Overflow: proxy.EarlyBufferFail}))
+
+		resp, err := h.client.Ping(testContext(t), &pb.PingRequest{Value: "foo"})
+		require.NoError(t, err)
+		assert.Equal(t, "foo", resp.Value)
+	})
+}
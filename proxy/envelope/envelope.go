@@ -0,0 +1,139 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+// Package envelope unpacks the one2many responses the proxy sends in the standard envelope (see proxy.WithAnyEnvelope)
+// into the typed responses and the errors of the backends, so that the clients don't parse the envelope by hand:
+//
+//	var payload []byte
+//
+//	err := conn.Invoke(ctx, "/service.Service/Method", request, &payload, grpc.ForceCodec(rawCodec))
+//
+//	responses, backendErrors, err := envelope.Unpack[servicepb.Response](payload)
+//
+// The envelope is also available as the message decoded by the client:
+//
+//	responses, backendErrors, err := envelope.UnpackResponses[servicepb.Response](reply.GetResponses())
+package envelope
+
+import (
+	"fmt"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	spb "google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/noncepad/grpc-proxy/proxy"
+)
+
+// statusName is the full name of google.rpc.Status, the type of the errors in the envelope.
+var statusName = (&spb.Status{}).ProtoReflect().Descriptor().FullName()
+
+// BackendError is the error of the backend reported in the envelope.
+type BackendError struct {
+	// Backend is the name of the backend, empty if the envelope doesn't name it.
+	Backend string
+	Status  *status.Status
+}
+
+// Error implements error.
+func (e *BackendError) Error() string {
+	if e.Backend == "" {
+		return e.Status.Err().Error()
+	}
+
+	return fmt.Sprintf("backend %s: %v", e.Backend, e.Status.Err())
+}
+
+// GRPCStatus returns the status of the error, so that status.Code and status.Convert work with BackendError.
+func (e *BackendError) GRPCStatus() *status.Status {
+	return e.Status
+}
+
+// Unpack decodes the envelope the proxy sent to the client, and splits it into the responses of type T
+// and the errors of the backends, in the order of the envelope.
+//
+// The responses of the types other than T (e.g. of the backends implementing proxy.ResponseTypeBackend) fail
+// the unpacking, see UnpackResponses.
+func Unpack[T any, M interface {
+	*T
+	proto.Message
+}](payload []byte,
+) ([]M, []*BackendError, error) {
+	responses, err := proxy.DecodeAnyEnvelope(payload)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error decoding envelope: %w", err)
+	}
+
+	return UnpackResponses[T, M](responses)
+}
+
+// UnpackResponses splits the responses of the envelope into the responses of type T and the errors
+// of the backends.
+func UnpackResponses[T any, M interface {
+	*T
+	proto.Message
+}](responses []*anypb.Any,
+) ([]M, []*BackendError, error) {
+	var (
+		typed  []M
+		errors []*BackendError
+	)
+
+	expected := M(new(T)).ProtoReflect().Descriptor().FullName()
+
+	for i, response := range responses {
+		switch name := response.MessageName(); name {
+		case statusName:
+			backendErr, err := unpackError(response)
+			if err != nil {
+				return nil, nil, fmt.Errorf("error decoding error %d of envelope: %w", i, err)
+			}
+
+			errors = append(errors, backendErr)
+		case expected:
+			msg := M(new(T))
+
+			if err := proto.Unmarshal(response.Value, msg); err != nil {
+				return nil, nil, fmt.Errorf("error decoding response %d of envelope: %w", i, err)
+			}
+
+			typed = append(typed, msg)
+		default:
+			return nil, nil, fmt.Errorf("unexpected type %s of response %d of envelope, expected %s", typeName(response, name), i, expected)
+		}
+	}
+
+	return typed, errors, nil
+}
+
+// unpackError decodes google.rpc.Status of the envelope.
+func unpackError(response *anypb.Any) (*BackendError, error) {
+	st := &spb.Status{}
+
+	if err := proto.Unmarshal(response.Value, st); err != nil {
+		return nil, err
+	}
+
+	backendErr := &BackendError{Status: status.FromProto(st)}
+
+	for _, detail := range backendErr.Status.Details() {
+		if info, ok := detail.(*errdetails.ResourceInfo); ok && info.ResourceType == proxy.BackendResourceType {
+			backendErr.Backend = info.ResourceName
+
+			break
+		}
+	}
+
+	return backendErr, nil
+}
+
+func typeName(response *anypb.Any, name protoreflect.FullName) string {
+	if name == "" {
+		return fmt.Sprintf("%q", response.TypeUrl)
+	}
+
+	return string(name)
+}
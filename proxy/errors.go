@@ -0,0 +1,246 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy
+
+import (
+	"errors"
+	"fmt"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ErrorDomain is the domain of errdetails.ErrorInfo attached to the errors generated by the proxy itself.
+//
+// Errors returned by the backends are passed through as is, so the presence of the ErrorInfo with this domain
+// distinguishes proxy failures from backend failures, see FromError.
+const ErrorDomain = "grpc-proxy"
+
+// BackendMetadataKey is the key of the backend name in the errdetails.ErrorInfo metadata.
+const BackendMetadataKey = "backend"
+
+// Error reasons of the proxy-originated errors.
+const (
+	ReasonInternal             = "INTERNAL"
+	ReasonNoBackends           = "NO_BACKENDS"
+	ReasonBackendDial          = "BACKEND_DIAL"
+	ReasonMethodNotAllowed     = "METHOD_NOT_ALLOWED"
+	ReasonUnauthenticated      = "UNAUTHENTICATED"
+	ReasonPolicyDenied         = "POLICY_DENIED"
+	ReasonPolicyFailed         = "POLICY_FAILED"
+	ReasonMalformedRequest     = "MALFORMED_REQUEST"
+	ReasonConcurrencyLimit     = "CONCURRENCY_LIMIT"
+	ReasonStreamLimit          = "STREAM_LIMIT"
+	ReasonResponseVerification = "RESPONSE_VERIFICATION"
+	ReasonUpstreamSigning      = "UPSTREAM_SIGNING"
+	ReasonHalfCloseTimeout     = "HALF_CLOSE_TIMEOUT"
+	ReasonLoopDetected         = "LOOP_DETECTED"
+	ReasonBackendVetoed        = "BACKEND_VETOED"
+	ReasonInvalidTarget        = "INVALID_TARGET"
+	ReasonProgressTimeout      = "PROGRESS_TIMEOUT"
+	ReasonRateLimited          = "RATE_LIMITED"
+	ReasonMetadataTooLarge     = "METADATA_TOO_LARGE"
+	ReasonUpstreamStreamBudget = "UPSTREAM_STREAM_BUDGET"
+	ReasonEarlyBufferOverflow  = "EARLY_BUFFER_OVERFLOW"
+	ReasonUpstreamProtocol     = "UPSTREAM_PROTOCOL"
+	ReasonBackendVersion       = "BACKEND_VERSION"
+	ReasonMalformedResponse    = "MALFORMED_RESPONSE"
+	ReasonIdempotencyKeyReused = "IDEMPOTENCY_KEY_REUSED"
+	ReasonMessageTooLarge      = "MESSAGE_TOO_LARGE"
+)
+
+// Error is an error generated by the proxy itself.
+//
+// Error implements GRPCStatus, so that the client receives the status with the Code and the Message, and
+// the errdetails.ErrorInfo with the Reason and the Backend (if set).
+//
+// Errors are matched with errors.Is by the Reason, so the sentinel errors below can be used to check for
+// the specific kind of failure:
+//
+//	if errors.Is(err, proxy.ErrNoBackends) { ... }
+type Error struct {
+	// Err is the underlying error, if any.
+	Err error
+
+	Code    codes.Code
+	Reason  string
+	Message string
+	// Backend is the name of the backend the error relates to, if any.
+	Backend string
+}
+
+// Sentinel errors for each kind of the proxy-originated failure.
+var (
+	ErrInternal             = &Error{Code: codes.Internal, Reason: ReasonInternal, Message: "internal proxy error"}
+	ErrNoBackends           = &Error{Code: codes.Unavailable, Reason: ReasonNoBackends, Message: "no backend connections for proxying"}
+	ErrBackendDial          = &Error{Code: codes.Unavailable, Reason: ReasonBackendDial, Message: "error connecting to backend"}
+	ErrMethodNotAllowed     = &Error{Code: codes.PermissionDenied, Reason: ReasonMethodNotAllowed, Message: "method is not allowed"}
+	ErrUnauthenticated      = &Error{Code: codes.Unauthenticated, Reason: ReasonUnauthenticated, Message: "unauthenticated"}
+	ErrPolicyDenied         = &Error{Code: codes.PermissionDenied, Reason: ReasonPolicyDenied, Message: "denied by policy"}
+	ErrPolicyFailed         = &Error{Code: codes.Internal, Reason: ReasonPolicyFailed, Message: "error evaluating policy"}
+	ErrMalformedRequest     = &Error{Code: codes.InvalidArgument, Reason: ReasonMalformedRequest, Message: "malformed request"}
+	ErrConcurrencyLimit     = &Error{Code: codes.ResourceExhausted, Reason: ReasonConcurrencyLimit, Message: "too many concurrent calls"}
+	ErrStreamLimit          = &Error{Code: codes.ResourceExhausted, Reason: ReasonStreamLimit, Message: "stream limit exceeded"}
+	ErrResponseVerification = &Error{Code: codes.DataLoss, Reason: ReasonResponseVerification, Message: "response verification failed"}
+	ErrUpstreamSigning      = &Error{Code: codes.Internal, Reason: ReasonUpstreamSigning, Message: "error signing upstream call"}
+	ErrHalfCloseTimeout     = &Error{Code: codes.DeadlineExceeded, Reason: ReasonHalfCloseTimeout, Message: "upstream didn't finish after half-close"}
+	ErrLoopDetected         = &Error{Code: codes.FailedPrecondition, Reason: ReasonLoopDetected, Message: "proxy loop detected"}
+	ErrBackendVetoed        = &Error{Code: codes.Unavailable, Reason: ReasonBackendVetoed, Message: "backend vetoed"}
+	ErrInvalidTarget        = &Error{Code: codes.InvalidArgument, Reason: ReasonInvalidTarget, Message: "invalid target"}
+	ErrProgressTimeout      = &Error{Code: codes.DeadlineExceeded, Reason: ReasonProgressTimeout, Message: "upstream didn't make progress"}
+	ErrRateLimited          = &Error{Code: codes.ResourceExhausted, Reason: ReasonRateLimited, Message: "rate limit exceeded"}
+	ErrMetadataTooLarge     = &Error{Code: codes.ResourceExhausted, Reason: ReasonMetadataTooLarge, Message: "metadata too large"}
+	ErrUpstreamStreamBudget = &Error{Code: codes.Unavailable, Reason: ReasonUpstreamStreamBudget, Message: "no upstream stream slot available"}
+	ErrEarlyBufferOverflow  = &Error{Code: codes.ResourceExhausted, Reason: ReasonEarlyBufferOverflow, Message: "too many requests before backend selection"}
+	ErrUpstreamProtocol     = &Error{Code: codes.Unavailable, Reason: ReasonUpstreamProtocol, Message: "upstream is not speaking gRPC"}
+	ErrBackendVersion       = &Error{Code: codes.FailedPrecondition, Reason: ReasonBackendVersion, Message: "backend version doesn't satisfy the gate"}
+	ErrMalformedResponse    = &Error{Code: codes.Internal, Reason: ReasonMalformedResponse, Message: "malformed aggregated response"}
+	ErrIdempotencyKeyReused = &Error{Code: codes.FailedPrecondition, Reason: ReasonIdempotencyKeyReused, Message: "idempotency key reused with a different request"}
+	ErrMessageTooLarge      = &Error{Code: codes.ResourceExhausted, Reason: ReasonMessageTooLarge, Message: "reassembled message too large"}
+)
+
+// newError creates new Error of the same kind as the sentinel error.
+func newError(sentinel *Error, format string, args ...interface{}) *Error {
+	return &Error{
+		Code:    sentinel.Code,
+		Reason:  sentinel.Reason,
+		Message: fmt.Sprintf(format, args...),
+	}
+}
+
+// Error implements error.
+func (e *Error) Error() string {
+	return fmt.Sprintf("rpc error: code = %s desc = %s", e.Code, e.Message)
+}
+
+// Unwrap returns the underlying error.
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is matches errors by the Reason.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error) //nolint:errorlint
+	if !ok {
+		return false
+	}
+
+	return t.Reason == e.Reason
+}
+
+// GRPCStatus implements the interface used by grpc to convert errors to the status.
+func (e *Error) GRPCStatus() *status.Status {
+	info := &errdetails.ErrorInfo{
+		Reason: e.Reason,
+		Domain: ErrorDomain,
+	}
+
+	if e.Backend != "" {
+		info.Metadata = map[string]string{BackendMetadataKey: e.Backend}
+	}
+
+	st := status.New(e.Code, e.Message)
+
+	if withDetails, err := st.WithDetails(info); err == nil {
+		st = withDetails
+	}
+
+	return st
+}
+
+// BackendDialError is returned when the proxy fails to establish the connection or the stream to the backend.
+type BackendDialError struct {
+	Err     error
+	Backend string
+}
+
+// Error implements error.
+func (e *BackendDialError) Error() string {
+	return fmt.Sprintf("error connecting to backend %s: %v", e.Backend, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *BackendDialError) Unwrap() error {
+	return e.Err
+}
+
+// Is matches ErrBackendDial.
+func (e *BackendDialError) Is(target error) bool {
+	return target == ErrBackendDial //nolint:errorlint,goerr113
+}
+
+// GRPCStatus implements the interface used by grpc to convert errors to the status.
+//
+// The code of the underlying status is preserved, non-status errors are reported as codes.Unavailable.
+func (e *BackendDialError) GRPCStatus() *status.Status {
+	code := codes.Unavailable
+
+	if st, ok := status.FromError(e.Err); ok && st.Code() != codes.Unknown {
+		code = st.Code()
+	}
+
+	return (&Error{
+		Code:    code,
+		Reason:  ReasonBackendDial,
+		Message: fmt.Sprintf("error connecting to backend %s: %s", e.Backend, status.Convert(e.Err).Message()),
+		Backend: e.Backend,
+	}).GRPCStatus()
+}
+
+// FromError extracts the proxy-originated error.
+//
+// FromError works both with the errors returned by the proxy handler, and with the errors received
+// by the client of the proxy. If the error was not generated by the proxy (e.g. it was returned by the backend),
+// FromError returns false.
+func FromError(err error) (*Error, bool) {
+	if err == nil {
+		return nil, false
+	}
+
+	var proxyErr *Error
+
+	if errors.As(err, &proxyErr) {
+		return proxyErr, true
+	}
+
+	st, ok := status.FromError(err)
+	if !ok {
+		var dialErr *BackendDialError
+
+		if !errors.As(err, &dialErr) {
+			return nil, false
+		}
+
+		st = dialErr.GRPCStatus()
+	}
+
+	for _, detail := range st.Details() {
+		info, ok := detail.(*errdetails.ErrorInfo)
+		if !ok || info.Domain != ErrorDomain {
+			continue
+		}
+
+		return &Error{
+			Code:    st.Code(),
+			Reason:  info.Reason,
+			Message: st.Message(),
+			Backend: info.Metadata[BackendMetadataKey],
+		}, true
+	}
+
+	return nil, false
+}
+
+// s2cError converts the error of forwarding the client messages to the upstreams into the proxy error.
+func s2cError(err error) error {
+	var proxyErr *Error
+
+	// errors raised by the proxy while processing the client messages are returned as is
+	if errors.As(err, &proxyErr) {
+		return err
+	}
+
+	return &Error{Err: err, Code: codes.Internal, Reason: ReasonInternal, Message: fmt.Sprintf("failed proxying s2c: %v", err)}
+}
@@ -0,0 +1,81 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/noncepad/grpc-proxy/proxy"
+	pb "github.com/noncepad/grpc-proxy/testservice"
+)
+
+func TestProxyErrors(t *testing.T) {
+	failingBackend := &proxy.SingleBackend{
+		GetConn: func(ctx context.Context) (context.Context, *grpc.ClientConn, error) {
+			return ctx, nil, status.Error(codes.Unavailable, "connection refused")
+		},
+	}
+
+	h := newTestHarness(t, func(backend proxy.Backend) proxy.StreamDirector {
+		return func(ctx context.Context, fullMethodName string) (proxy.Mode, []proxy.Backend, error) {
+			switch fullMethodName {
+			case "/talos.testproto.TestService/PingEmpty":
+				return proxy.One2One, []proxy.Backend{failingBackend}, nil
+			case "/talos.testproto.TestService/PingList":
+				return proxy.One2Many, nil, nil
+			default:
+				return proxy.One2One, []proxy.Backend{backend}, nil
+			}
+		}
+	}, proxy.WithMethodAllowlist("/talos.testproto.TestService/*"))
+
+	_, err := h.client.PingEmpty(testContext(t), &pb.Empty{})
+	require.Error(t, err)
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+
+	proxyErr, ok := proxy.FromError(err)
+	require.True(t, ok)
+	assert.Equal(t, proxy.ReasonBackendDial, proxyErr.Reason)
+	assert.Equal(t, "backend", proxyErr.Backend)
+	assert.True(t, errors.Is(proxyErr, proxy.ErrBackendDial))
+
+	stream, err := h.client.PingList(testContext(t), &pb.PingRequest{Value: "foo"})
+	require.NoError(t, err)
+
+	_, err = stream.Recv()
+	require.Error(t, err)
+
+	proxyErr, ok = proxy.FromError(err)
+	require.True(t, ok)
+	assert.True(t, errors.Is(proxyErr, proxy.ErrNoBackends))
+	assert.False(t, errors.Is(proxyErr, proxy.ErrBackendDial))
+
+	_, err = h.client.PingError(testContext(t), &pb.PingRequest{Value: "foo"})
+	require.Error(t, err)
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	_, ok = proxy.FromError(err)
+	assert.False(t, ok, "backend errors are not proxy errors")
+}
+
+func TestProxyErrorsMethodNotAllowed(t *testing.T) {
+	h := newTestHarness(t, one2oneDirector, proxy.WithMethodAllowlist("/talos.testproto.TestService/Ping"))
+
+	_, err := h.client.PingEmpty(testContext(t), &pb.Empty{})
+	require.Error(t, err)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	proxyErr, ok := proxy.FromError(err)
+	require.True(t, ok)
+	assert.True(t, errors.Is(proxyErr, proxy.ErrMethodNotAllowed))
+	assert.Equal(t, "method /talos.testproto.TestService/PingEmpty is not allowed", proxyErr.Message)
+}
@@ -0,0 +1,337 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"strconv"
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// EventEncoder encodes the batch of events for the logging pipeline.
+type EventEncoder interface {
+	Encode(events []Event) ([]byte, error)
+}
+
+// JSONLinesEventEncoder encodes each event as a JSON object on a separate line.
+type JSONLinesEventEncoder struct{}
+
+// Encode implements EventEncoder.
+func (JSONLinesEventEncoder) Encode(events []Event) ([]byte, error) {
+	var buf []byte
+
+	for _, event := range events {
+		data, err := json.Marshal(event)
+		if err != nil {
+			return nil, err
+		}
+
+		buf = append(buf, data...)
+		buf = append(buf, '\n')
+	}
+
+	return buf, nil
+}
+
+// ProtoEventEncoder encodes the batch of events as the length-delimited protobuf message:
+//
+//	message EventBatch {
+//	  repeated Event events = 1;
+//	}
+//
+//	message Event {
+//	  int64 time_unix_nano = 1;
+//	  string type = 2;
+//	  string method = 3;
+//	  string mode = 4;
+//	  string backend = 5;
+//	  repeated string backends = 6;
+//	  string code = 7;
+//	  string error = 8;
+//	  int64 duration_nanos = 9;
+//	}
+//
+// Each batch is prefixed with its varint-encoded length, so the batches can be appended to the same stream.
+type ProtoEventEncoder struct{}
+
+// Encode implements EventEncoder.
+func (ProtoEventEncoder) Encode(events []Event) ([]byte, error) {
+	var batch []byte
+
+	for _, event := range events {
+		batch = protowire.AppendTag(batch, 1, protowire.BytesType)
+		batch = protowire.AppendBytes(batch, marshalEvent(event))
+	}
+
+	buf := protowire.AppendVarint(nil, uint64(len(batch)))
+
+	return append(buf, batch...), nil
+}
+
+func marshalEvent(event Event) []byte {
+	var buf []byte
+
+	appendString := func(num protowire.Number, value string) {
+		if value == "" {
+			return
+		}
+
+		buf = protowire.AppendTag(buf, num, protowire.BytesType)
+		buf = protowire.AppendString(buf, value)
+	}
+
+	appendInt := func(num protowire.Number, value int64) {
+		if value == 0 {
+			return
+		}
+
+		buf = protowire.AppendTag(buf, num, protowire.VarintType)
+		buf = protowire.AppendVarint(buf, uint64(value))
+	}
+
+	if !event.Time.IsZero() {
+		appendInt(1, event.Time.UnixNano())
+	}
+
+	appendString(2, string(event.Type))
+	appendString(3, event.Method)
+	appendString(4, event.Mode)
+	appendString(5, event.Backend)
+
+	for _, backend := range event.Backends {
+		buf = protowire.AppendTag(buf, 6, protowire.BytesType)
+		buf = protowire.AppendString(buf, backend)
+	}
+
+	appendString(7, event.Code)
+	appendString(8, event.Error)
+	appendInt(9, int64(event.Duration))
+
+	return buf
+}
+
+// OTLPLogsEventEncoder encodes the batch of events as OTLP logs (ExportLogsServiceRequest in the OTLP/HTTP JSON
+// encoding), so that the batches can be posted to the OpenTelemetry collector.
+//
+// The event type is the body of the log record, other fields are the attributes.
+type OTLPLogsEventEncoder struct {
+	// ServiceName is the service.name resource attribute.
+	ServiceName string
+}
+
+type otlpValue struct {
+	StringValue *string     `json:"stringValue,omitempty"`
+	IntValue    *string     `json:"intValue,omitempty"`
+	ArrayValue  *otlpValues `json:"arrayValue,omitempty"`
+}
+
+type otlpValues struct {
+	Values []otlpValue `json:"values"`
+}
+
+type otlpAttribute struct {
+	Key   string    `json:"key"`
+	Value otlpValue `json:"value"`
+}
+
+type otlpLogRecord struct {
+	TimeUnixNano   string          `json:"timeUnixNano"`
+	SeverityNumber int             `json:"severityNumber"`
+	SeverityText   string          `json:"severityText"`
+	Body           otlpValue       `json:"body"`
+	Attributes     []otlpAttribute `json:"attributes,omitempty"`
+}
+
+// OTLP severity numbers.
+const (
+	otlpSeverityInfo = 9
+	otlpSeverityWarn = 13
+)
+
+// Encode implements EventEncoder.
+func (e OTLPLogsEventEncoder) Encode(events []Event) ([]byte, error) {
+	records := make([]otlpLogRecord, 0, len(events))
+
+	for _, event := range events {
+		records = append(records, otlpRecord(event))
+	}
+
+	type scopeLogs struct {
+		Scope      map[string]string `json:"scope"`
+		LogRecords []otlpLogRecord   `json:"logRecords"`
+	}
+
+	type resourceLogs struct {
+		Resource struct {
+			Attributes []otlpAttribute `json:"attributes,omitempty"`
+		} `json:"resource"`
+		ScopeLogs []scopeLogs `json:"scopeLogs"`
+	}
+
+	resource := resourceLogs{
+		ScopeLogs: []scopeLogs{{
+			Scope:      map[string]string{"name": "github.com/noncepad/grpc-proxy"},
+			LogRecords: records,
+		}},
+	}
+
+	if e.ServiceName != "" {
+		resource.Resource.Attributes = []otlpAttribute{otlpString("service.name", e.ServiceName)}
+	}
+
+	return json.Marshal(struct {
+		ResourceLogs []resourceLogs `json:"resourceLogs"`
+	}{
+		ResourceLogs: []resourceLogs{resource},
+	})
+}
+
+func otlpString(key, value string) otlpAttribute {
+	return otlpAttribute{Key: key, Value: otlpValue{StringValue: &value}}
+}
+
+func otlpRecord(event Event) otlpLogRecord {
+	body := string(event.Type)
+
+	record := otlpLogRecord{
+		TimeUnixNano:   strconv.FormatInt(event.Time.UnixNano(), 10),
+		SeverityNumber: otlpSeverityInfo,
+		SeverityText:   "INFO",
+		Body:           otlpValue{StringValue: &body},
+	}
+
+	if event.Error != "" {
+		record.SeverityNumber, record.SeverityText = otlpSeverityWarn, "WARN"
+	}
+
+	add := func(key, value string) {
+		if value != "" {
+			record.Attributes = append(record.Attributes, otlpString(key, value))
+		}
+	}
+
+	add("rpc.method", event.Method)
+	add("proxy.mode", event.Mode)
+	add("proxy.backend", event.Backend)
+
+	if len(event.Backends) > 0 {
+		values := make([]otlpValue, 0, len(event.Backends))
+
+		for i := range event.Backends {
+			values = append(values, otlpValue{StringValue: &event.Backends[i]})
+		}
+
+		record.Attributes = append(record.Attributes, otlpAttribute{
+			Key:   "proxy.backends",
+			Value: otlpValue{ArrayValue: &otlpValues{Values: values}},
+		})
+	}
+
+	add("rpc.grpc.status_code", event.Code)
+	add("error.message", event.Error)
+
+	if event.Duration > 0 {
+		duration := strconv.FormatInt(int64(event.Duration), 10)
+
+		record.Attributes = append(record.Attributes, otlpAttribute{
+			Key:   "proxy.duration_nanos",
+			Value: otlpValue{IntValue: &duration},
+		})
+	}
+
+	return record
+}
+
+// BatchingEventWriter is the EventSink which encodes the events in batches and writes them to the writer.
+//
+// The batch is written once it reaches the size, and every interval while Run is running. Each batch is written
+// with a single Write call, so the writer can post each batch to the logging pipeline as is.
+type BatchingEventWriter struct {
+	w       io.Writer
+	encoder EventEncoder
+	size    int
+
+	mu      sync.Mutex
+	pending []Event
+	err     error
+}
+
+// NewBatchingEventWriter creates the BatchingEventWriter with the batch size.
+func NewBatchingEventWriter(w io.Writer, encoder EventEncoder, size int) *BatchingEventWriter {
+	if size <= 0 {
+		size = 1
+	}
+
+	return &BatchingEventWriter{
+		w:       w,
+		encoder: encoder,
+		size:    size,
+	}
+}
+
+// Emit implements EventSink.
+func (b *BatchingEventWriter) Emit(event Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.pending = append(b.pending, event)
+
+	if len(b.pending) >= b.size {
+		b.flushLocked() //nolint:errcheck // the error is returned by Err
+	}
+}
+
+// Flush writes the pending events.
+func (b *BatchingEventWriter) Flush() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.flushLocked()
+}
+
+// Err returns the error of writing the last batch.
+func (b *BatchingEventWriter) Err() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.err
+}
+
+// Run flushes the pending events every interval until the context is canceled, and once more when it is canceled.
+func (b *BatchingEventWriter) Run(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return b.Flush()
+		case <-ticker.C:
+			b.Flush() //nolint:errcheck // the error is returned by Err
+		}
+	}
+}
+
+func (b *BatchingEventWriter) flushLocked() error {
+	if len(b.pending) == 0 {
+		return nil
+	}
+
+	events := b.pending
+	b.pending = nil
+
+	data, err := b.encoder.Encode(events)
+	if err == nil {
+		_, err = b.w.Write(data)
+	}
+
+	b.err = err
+
+	return err
+}
@@ -0,0 +1,137 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy
+
+import (
+	"time"
+
+	"google.golang.org/grpc/status"
+)
+
+// EventType is the type of the proxy lifecycle event.
+type EventType string
+
+// Event types.
+const (
+	// EventCallStarted is emitted once the director selected the backends of the call.
+	EventCallStarted EventType = "call.started"
+	// EventBackendConnected is emitted once the upstream call to the backend is established.
+	EventBackendConnected EventType = "backend.connected"
+	// EventBackendFailed is emitted if the upstream call to the backend can't be established.
+	EventBackendFailed EventType = "backend.failed"
+	// EventCallFinished is emitted once the call is finished, including the calls rejected before the director.
+	EventCallFinished EventType = "call.finished"
+	// EventMetadataLimited is emitted if the metadata of the call is over the limit, see WithMetadataLimits.
+	EventMetadataLimited EventType = "metadata.limited"
+)
+
+// Event is the proxy lifecycle event.
+//
+// The schema of the event is stable: fields are only added, and the JSON names and the protobuf field numbers
+// (see ProtoEventEncoder) never change.
+type Event struct {
+	Time   time.Time `json:"time"`
+	Type   EventType `json:"type"`
+	Method string    `json:"method"`
+	// Mode is "one2one" or "one2many", set for EventCallStarted.
+	Mode string `json:"mode,omitempty"`
+	// Backend is set for the backend events.
+	Backend string `json:"backend,omitempty"`
+	// Backends are the backends selected by the director, set for EventCallStarted.
+	Backends []string `json:"backends,omitempty"`
+	// Code is the gRPC status code of the call (EventCallFinished) or the failure (EventBackendFailed).
+	Code string `json:"code,omitempty"`
+	// Error is the error message.
+	Error string `json:"error,omitempty"`
+	// Duration of the call, set for EventCallFinished.
+	Duration time.Duration `json:"duration,omitempty"`
+}
+
+// EventSink receives the proxy lifecycle events.
+//
+// Emit is called synchronously on the proxying path, so it should not block.
+type EventSink interface {
+	Emit(event Event)
+}
+
+// EventSinkFunc is a function adapter for EventSink.
+type EventSinkFunc func(event Event)
+
+// Emit implements EventSink.
+func (f EventSinkFunc) Emit(event Event) {
+	f(event)
+}
+
+// WithEvents emits the proxy lifecycle events to the sink.
+//
+// Events can be encoded for the logging pipelines with BatchingEventWriter.
+func WithEvents(sink EventSink) Option {
+	return func(o *handlerOptions) {
+		o.events = sink
+	}
+}
+
+// emitCallStarted emits EventCallStarted.
+func (o *handlerOptions) emitCallStarted(fullMethodName string, mode Mode, backends []Backend) {
+	if o.events == nil {
+		return
+	}
+
+	names := make([]string, 0, len(backends))
+
+	for _, backend := range backends {
+		names = append(names, backend.String())
+	}
+
+	o.events.Emit(Event{
+		Time:     time.Now(),
+		Type:     EventCallStarted,
+		Method:   fullMethodName,
+		Mode:     mode.String(),
+		Backends: names,
+	})
+}
+
+// emitBackendConnected emits EventBackendConnected or EventBackendFailed.
+func (o *handlerOptions) emitBackendConnected(fullMethodName string, conn *backendConnection) {
+	if o.events == nil {
+		return
+	}
+
+	event := Event{
+		Time:    time.Now(),
+		Type:    EventBackendConnected,
+		Method:  fullMethodName,
+		Backend: conn.backend.String(),
+	}
+
+	if conn.connError != nil {
+		event.Type = EventBackendFailed
+		event.Code = status.Convert(conn.connError).Code().String()
+		event.Error = conn.connError.Error()
+	}
+
+	o.events.Emit(event)
+}
+
+// emitCallFinished emits EventCallFinished.
+func (o *handlerOptions) emitCallFinished(fullMethodName string, start time.Time, err error) {
+	if o.events == nil {
+		return
+	}
+
+	event := Event{
+		Time:     time.Now(),
+		Type:     EventCallFinished,
+		Method:   fullMethodName,
+		Code:     status.Convert(err).Code().String(),
+		Duration: time.Since(start),
+	}
+
+	if err != nil {
+		event.Error = err.Error()
+	}
+
+	o.events.Emit(event)
+}
@@ -0,0 +1,231 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"github.com/noncepad/grpc-proxy/proxy"
+	pb "github.com/noncepad/grpc-proxy/testservice"
+)
+
+type recordingSink struct {
+	mu     sync.Mutex
+	events []proxy.Event
+}
+
+func (s *recordingSink) Emit(event proxy.Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.events = append(s.events, event)
+}
+
+func (s *recordingSink) take() []proxy.Event {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	events := s.events
+	s.events = nil
+
+	return events
+}
+
+func TestEvents(t *testing.T) {
+	sink := &recordingSink{}
+
+	h := newTestHarness(t, one2oneDirector, proxy.WithEvents(sink))
+	defer h.stop()
+
+	_, err := h.client.Ping(testContext(t), &pb.PingRequest{Value: "foo"})
+	require.NoError(t, err)
+
+	events := sink.take()
+	require.Len(t, events, 3)
+
+	assert.Equal(t, proxy.EventCallStarted, events[0].Type)
+	assert.Equal(t, "/talos.testproto.TestService/Ping", events[0].Method)
+	assert.Equal(t, "one2one", events[0].Mode)
+	assert.Len(t, events[0].Backends, 1)
+
+	assert.Equal(t, proxy.EventBackendConnected, events[1].Type)
+	assert.Equal(t, events[0].Backends[0], events[1].Backend)
+
+	assert.Equal(t, proxy.EventCallFinished, events[2].Type)
+	assert.Equal(t, "OK", events[2].Code)
+	assert.Empty(t, events[2].Error)
+	assert.Positive(t, events[2].Duration)
+
+	_, err = h.client.PingError(testContext(t), &pb.PingRequest{Value: "foo"})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	events = sink.take()
+	require.Len(t, events, 3)
+	assert.Equal(t, proxy.EventCallFinished, events[2].Type)
+	assert.Equal(t, "FailedPrecondition", events[2].Code)
+	assert.Contains(t, events[2].Error, "Userspace error.")
+}
+
+func testEvents() []proxy.Event {
+	now := time.Unix(1700000000, 42)
+
+	return []proxy.Event{
+		{Time: now, Type: proxy.EventCallStarted, Method: "/svc/Method", Mode: "one2many", Backends: []string{"a", "b"}},
+		{Time: now, Type: proxy.EventBackendFailed, Method: "/svc/Method", Backend: "b", Code: "Unavailable", Error: "down"},
+		{Time: now, Type: proxy.EventCallFinished, Method: "/svc/Method", Code: "OK", Duration: time.Second},
+	}
+}
+
+func TestEventEncoders(t *testing.T) {
+	events := testEvents()
+
+	t.Run("json lines", func(t *testing.T) {
+		data, err := proxy.JSONLinesEventEncoder{}.Encode(events)
+		require.NoError(t, err)
+
+		scanner := bufio.NewScanner(bytes.NewReader(data))
+
+		var decoded []proxy.Event
+
+		for scanner.Scan() {
+			var event proxy.Event
+
+			require.NoError(t, json.Unmarshal(scanner.Bytes(), &event))
+
+			decoded = append(decoded, event)
+		}
+
+		require.Len(t, decoded, len(events))
+
+		for i := range events {
+			assert.True(t, events[i].Time.Equal(decoded[i].Time))
+
+			decoded[i].Time = events[i].Time
+		}
+
+		assert.Equal(t, events, decoded)
+	})
+
+	t.Run("proto", func(t *testing.T) {
+		data, err := proxy.ProtoEventEncoder{}.Encode(events)
+		require.NoError(t, err)
+
+		size, n := protowire.ConsumeVarint(data)
+		require.Positive(t, n)
+		require.EqualValues(t, len(data)-n, size)
+
+		batch := data[n:]
+
+		var methods, backends []string
+
+		for len(batch) > 0 {
+			num, typ, n := protowire.ConsumeTag(batch)
+			require.Positive(t, n)
+			require.EqualValues(t, 1, num)
+			require.Equal(t, protowire.BytesType, typ)
+
+			event, n2 := protowire.ConsumeBytes(batch[n:])
+			require.Positive(t, n2)
+
+			batch = batch[n+n2:]
+
+			for len(event) > 0 {
+				num, typ, n := protowire.ConsumeTag(event)
+				require.Positive(t, n)
+
+				m := protowire.ConsumeFieldValue(num, typ, event[n:])
+				require.Positive(t, m)
+
+				switch num {
+				case 3:
+					value, _ := protowire.ConsumeString(event[n:])
+					methods = append(methods, value)
+				case 6:
+					value, _ := protowire.ConsumeString(event[n:])
+					backends = append(backends, value)
+				case 9:
+					value, _ := protowire.ConsumeVarint(event[n:])
+					assert.EqualValues(t, time.Second, value)
+				}
+
+				event = event[n+m:]
+			}
+		}
+
+		assert.Equal(t, []string{"/svc/Method", "/svc/Method", "/svc/Method"}, methods)
+		assert.Equal(t, []string{"a", "b"}, backends)
+	})
+
+	t.Run("otlp", func(t *testing.T) {
+		data, err := proxy.OTLPLogsEventEncoder{ServiceName: "proxy"}.Encode(events)
+		require.NoError(t, err)
+
+		var request struct {
+			ResourceLogs []struct {
+				Resource struct {
+					Attributes []struct {
+						Key string `json:"key"`
+					} `json:"attributes"`
+				} `json:"resource"`
+				ScopeLogs []struct {
+					LogRecords []struct {
+						TimeUnixNano string `json:"timeUnixNano"`
+						SeverityText string `json:"severityText"`
+						Body         struct {
+							StringValue string `json:"stringValue"`
+						} `json:"body"`
+					} `json:"logRecords"`
+				} `json:"scopeLogs"`
+			} `json:"resourceLogs"`
+		}
+
+		require.NoError(t, json.Unmarshal(data, &request))
+		require.Len(t, request.ResourceLogs, 1)
+		require.Len(t, request.ResourceLogs[0].ScopeLogs, 1)
+		assert.Equal(t, "service.name", request.ResourceLogs[0].Resource.Attributes[0].Key)
+
+		records := request.ResourceLogs[0].ScopeLogs[0].LogRecords
+		require.Len(t, records, 3)
+		assert.Equal(t, "call.started", records[0].Body.StringValue)
+		assert.Equal(t, "1700000000000000042", records[0].TimeUnixNano)
+		assert.Equal(t, "WARN", records[1].SeverityText)
+		assert.Equal(t, "INFO", records[2].SeverityText)
+	})
+}
+
+func TestBatchingEventWriter(t *testing.T) {
+	var buf bytes.Buffer
+
+	writer := proxy.NewBatchingEventWriter(&buf, proxy.JSONLinesEventEncoder{}, 2)
+
+	events := testEvents()
+
+	writer.Emit(events[0])
+	assert.Zero(t, buf.Len())
+
+	writer.Emit(events[1])
+	assert.Equal(t, 2, bytes.Count(buf.Bytes(), []byte("\n")))
+
+	writer.Emit(events[2])
+	assert.Equal(t, 2, bytes.Count(buf.Bytes(), []byte("\n")))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	require.NoError(t, writer.Run(ctx, time.Hour))
+	assert.Equal(t, 3, bytes.Count(buf.Bytes(), []byte("\n")))
+	assert.NoError(t, writer.Err())
+}
@@ -0,0 +1,325 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// defaultMaxReplayMessages is the default limit of the request messages buffered for the failover.
+const defaultMaxReplayMessages = 64
+
+// FailoverHeaders defines how the response headers of the failed attempts are relayed to the client.
+//
+// The proxy relays the headers of the upstream call together with the first response message, so the headers
+// of the attempt which failed before responding are never sent to the client as is.
+type FailoverHeaders int
+
+// Failover header modes.
+const (
+	// FailoverHeadersSuppress drops the headers of the failed attempts: the client receives only the headers
+	// of the attempt which delivered the first response message.
+	FailoverHeadersSuppress FailoverHeaders = iota
+	// FailoverHeadersReplace buffers the headers of the failed attempts, the headers of the attempt which delivered
+	// the first response message replace the buffered values with the same keys.
+	FailoverHeadersReplace
+)
+
+// FailoverPolicy configures the failover of one2one calls.
+type FailoverPolicy struct {
+	// Codes are the status codes of the failed attempt which trigger the failover, codes.Unavailable if empty.
+	//
+	// Attempts which fail to establish the upstream stream are always failed over.
+	Codes []codes.Code
+
+	// MaxReplayMessages limits the number of the request messages buffered to be replayed to the next backend,
+	// 64 if zero. The call is not failed over once the limit is exceeded.
+	MaxReplayMessages int
+
+	// Headers defines how the headers of the failed attempts are relayed.
+	Headers FailoverHeaders
+
+	// Resume enables the failover of the server streams after the responses were relayed to the client, if set.
+	Resume *ResumePolicy
+}
+
+// WithFailover enables the failover for one2one calls of the listed methods (all methods if none are listed).
+//
+// With the failover, the director might return more than one backend in One2One mode: the backends are attempted
+// in order, and the call is proxied to the next backend if the attempt fails before the first response message
+// is relayed to the client. The request messages sent so far are replayed to the next backend, so the methods
+// should be safe to repeat. The resumable server streams might be failed over in flight as well, see ResumePolicy.
+func WithFailover(policy FailoverPolicy, fullMethodNames ...string) Option {
+	if policy.MaxReplayMessages <= 0 {
+		policy.MaxReplayMessages = defaultMaxReplayMessages
+	}
+
+	if len(policy.Codes) == 0 {
+		policy.Codes = []codes.Code{codes.Unavailable}
+	}
+
+	return func(o *handlerOptions) {
+		if o.failoverPolicies == nil {
+			o.failoverPolicies = map[string]FailoverPolicy{}
+		}
+
+		if len(fullMethodNames) == 0 {
+			o.failoverPolicies[""] = policy
+
+			return
+		}
+
+		for _, name := range fullMethodNames {
+			o.failoverPolicies[name] = policy
+		}
+	}
+}
+
+func (o *handlerOptions) failoverPolicy(fullMethodName string) (FailoverPolicy, bool) {
+	policy, ok := o.failoverPolicies[fullMethodName]
+	if !ok {
+		policy, ok = o.failoverPolicies[""]
+	}
+
+	return policy, ok
+}
+
+// failover connects to the first available backend, the returned stream fails over to the next backends.
+func (s *handler) failover(fullMethodName string, policy FailoverPolicy, budget *RetryBudget, numBackends int,
+	connect func(i int) backendConnection,
+) backendConnection {
+	stream := &failoverClientStream{
+		method:      fullMethodName,
+		policy:      policy,
+		budget:      budget,
+		numBackends: numBackends,
+		connect:     connect,
+	}
+
+	if !stream.connectNext(nil) {
+		// all the backends failed, the error of the last one is returned
+		return stream.current
+	}
+
+	conn := stream.current
+	conn.clientStream = stream
+
+	return conn
+}
+
+// failoverClientStream proxies the call to the current attempt, failing over to the next backend on error.
+type failoverClientStream struct {
+	current backendConnection
+	connect func(i int) backendConnection
+	headers metadata.MD
+
+	replay [][]byte
+	policy FailoverPolicy
+	budget *RetryBudget
+	method string
+
+	// lastResponse is the last response relayed, marker is the resume marker to be relayed next
+	lastResponse []byte
+	marker       []byte
+
+	numBackends int
+	next        int
+
+	mu        sync.Mutex
+	closed    bool
+	committed bool
+	overflow  bool
+}
+
+// connectNext connects to the next backend which is available and replays the requests.
+//
+// The attempts following the first one are the retries, they are limited by the retry budget.
+func (s *failoverClientStream) connectNext(replay [][]byte) bool {
+	for s.next < s.numBackends {
+		if s.next > 0 && !s.budget.withdraw() {
+			return false
+		}
+
+		conn := s.connect(s.next)
+		s.next++
+
+		s.current = conn
+
+		if conn.connError != nil {
+			if conn.statsLeg != nil {
+				conn.statsLeg.end(conn.connError)
+			}
+
+			continue
+		}
+
+		// send errors are returned by RecvMsg
+		for _, payload := range replay {
+			conn.clientStream.SendMsg(NewFrame(payload)) //nolint:errcheck
+		}
+
+		if s.closed {
+			conn.clientStream.CloseSend() //nolint:errcheck
+		}
+
+		return true
+	}
+
+	return false
+}
+
+// failover switches to the next backend if the call can be failed over.
+func (s *failoverClientStream) failover(err error) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if (s.committed && !s.resumable()) || s.overflow || s.next >= s.numBackends || !s.retryable(err) {
+		return false
+	}
+
+	replay := s.replay
+
+	if s.committed {
+		var resumeErr error
+
+		if replay, s.marker, resumeErr = s.policy.Resume.resume(s.method, s.replay, s.lastResponse); resumeErr != nil {
+			return false
+		}
+	} else if s.policy.Headers == FailoverHeadersReplace {
+		if md, headerErr := s.current.clientStream.Header(); headerErr == nil {
+			s.headers = metadata.Join(s.headers, md)
+		}
+	}
+
+	failed := s.current
+
+	if s.connectNext(replay) {
+		return true
+	}
+
+	s.marker = nil
+
+	// keep the failed attempt, so that its error and trailers are returned
+	s.current = failed
+
+	return false
+}
+
+func (s *failoverClientStream) retryable(err error) bool {
+	if errors.Is(err, io.EOF) {
+		return false
+	}
+
+	code := status.Code(err)
+
+	for _, c := range s.policy.Codes {
+		if c == code {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s *failoverClientStream) stream() grpc.ClientStream {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.current.clientStream
+}
+
+func (s *failoverClientStream) Header() (metadata.MD, error) {
+	md, err := s.stream().Header()
+	if err != nil || s.policy.Headers != FailoverHeadersReplace {
+		return md, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	merged := s.headers.Copy()
+
+	for k, v := range md {
+		merged[k] = v
+	}
+
+	return merged, nil
+}
+
+func (s *failoverClientStream) Trailer() metadata.MD {
+	return s.stream().Trailer()
+}
+
+func (s *failoverClientStream) CloseSend() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.closed = true
+
+	return s.current.clientStream.CloseSend()
+}
+
+func (s *failoverClientStream) Context() context.Context {
+	return s.stream().Context()
+}
+
+func (s *failoverClientStream) SendMsg(m interface{}) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, ok := m.(*Frame)
+
+	if (!s.committed || s.policy.Resume != nil) && !s.overflow {
+		if ok && len(s.replay) < s.policy.MaxReplayMessages {
+			s.replay = append(s.replay, append([]byte(nil), f.payload...))
+		} else {
+			s.overflow = true
+			s.replay = nil
+		}
+	}
+
+	err := s.current.clientStream.SendMsg(m)
+	if err != nil && !s.committed && !s.overflow && s.next < s.numBackends {
+		// the attempt failed, the message is replayed to the next backend
+		return nil
+	}
+
+	return err
+}
+
+func (s *failoverClientStream) RecvMsg(m interface{}) error {
+	for {
+		if s.relayMarker(m) {
+			return nil
+		}
+
+		err := s.stream().RecvMsg(m)
+		if err == nil {
+			s.mu.Lock()
+			s.committed = true
+
+			if s.policy.Resume == nil {
+				s.replay = nil
+			} else if f, ok := m.(*Frame); ok {
+				s.lastResponse = append(s.lastResponse[:0], f.payload...)
+			}
+
+			s.mu.Unlock()
+
+			return nil
+		}
+
+		if !s.failover(err) {
+			return err
+		}
+	}
+}
@@ -0,0 +1,146 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/noncepad/grpc-proxy/proxy"
+	pb "github.com/noncepad/grpc-proxy/testservice"
+)
+
+// failingService fails PingStream on the backend tagged "bad" after receiving the first message.
+type failingService struct {
+	lenientService
+
+	code codes.Code
+}
+
+func (s *failingService) PingStream(stream pb.TestService_PingStreamServer) error {
+	md, _ := metadata.FromIncomingContext(stream.Context())
+	tag := md.Get(backendTagMdKey)[0]
+
+	header := metadata.Pairs("attempt", tag)
+	if tag == "bad" {
+		header.Set("bad-only", "1")
+	}
+
+	if err := stream.SendHeader(header); err != nil {
+		return err
+	}
+
+	if tag == "bad" {
+		if _, err := stream.Recv(); err != nil {
+			return err
+		}
+
+		return status.Error(s.code, "attempt failed")
+	}
+
+	return s.lenientService.PingStream(stream)
+}
+
+// unavailableBackend fails to connect.
+type unavailableBackend struct {
+	proxy.Backend
+}
+
+func (b *unavailableBackend) GetConnection(ctx context.Context, fullMethodName string) (context.Context, *grpc.ClientConn, error) {
+	return nil, nil, errors.New("backend is down")
+}
+
+func failoverDirector(backend proxy.Backend) proxy.StreamDirector {
+	return func(ctx context.Context, fullMethodName string) (proxy.Mode, []proxy.Backend, error) {
+		if fullMethodName == "/talos.testproto.TestService/Ping" {
+			return proxy.One2One, []proxy.Backend{&unavailableBackend{Backend: backend}, backend}, nil
+		}
+
+		return proxy.One2One, []proxy.Backend{
+			&taggedBackend{Backend: backend, tag: "bad"},
+			&taggedBackend{Backend: backend, tag: "good"},
+		}, nil
+	}
+}
+
+func TestFailover(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		headers proxy.FailoverHeaders
+		badOnly []string
+	}{
+		{
+			name:    "suppress",
+			headers: proxy.FailoverHeadersSuppress,
+		},
+		{
+			name:    "replace",
+			headers: proxy.FailoverHeadersReplace,
+			badOnly: []string{"1"},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestHarnessWithService(t, &failingService{code: codes.Unavailable}, failoverDirector,
+				proxy.WithFailover(proxy.FailoverPolicy{Headers: tt.headers}))
+
+			stream, err := h.client.PingStream(testContext(t))
+			require.NoError(t, err)
+
+			// the first message is replayed to the next backend
+			require.NoError(t, stream.Send(&pb.PingRequest{Value: "one"}))
+			require.NoError(t, stream.Send(&pb.PingRequest{Value: "two"}))
+
+			assert.Equal(t, []string{"one", "two"}, recvValues(t, stream, 2))
+
+			header, err := stream.Header()
+			require.NoError(t, err)
+
+			assert.Equal(t, []string{"good"}, header.Get("attempt"))
+			assert.Equal(t, tt.badOnly, header.Get("bad-only"))
+
+			require.NoError(t, stream.CloseSend())
+
+			_, err = stream.Recv()
+			assert.ErrorIs(t, err, io.EOF)
+		})
+	}
+}
+
+func TestFailoverNotRetryable(t *testing.T) {
+	h := newTestHarnessWithService(t, &failingService{code: codes.FailedPrecondition}, failoverDirector,
+		proxy.WithFailover(proxy.FailoverPolicy{}))
+
+	stream, err := h.client.PingStream(testContext(t))
+	require.NoError(t, err)
+
+	require.NoError(t, stream.Send(&pb.PingRequest{Value: "one"}))
+
+	_, err = stream.Recv()
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+}
+
+func TestFailoverConnectionError(t *testing.T) {
+	h := newTestHarnessWithService(t, &failingService{code: codes.Unavailable}, failoverDirector,
+		proxy.WithFailover(proxy.FailoverPolicy{}, "/talos.testproto.TestService/Ping"))
+
+	resp, err := h.client.Ping(testContext(t), &pb.PingRequest{Value: "foo"})
+	require.NoError(t, err)
+	assert.Equal(t, "foo", resp.Value)
+
+	// failover is not enabled for PingStream, so one2one call to two backends fails
+	stream, err := h.client.PingStream(testContext(t))
+	require.NoError(t, err)
+
+	_, err = stream.Recv()
+	assert.Equal(t, codes.Internal, status.Code(err))
+}
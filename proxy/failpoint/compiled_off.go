@@ -0,0 +1,11 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+//go:build !failpoints
+
+package failpoint
+
+// Compiled returns true if the failpoints are compiled into the proxy.
+func Compiled() bool {
+	return false
+}
@@ -0,0 +1,132 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+// Package failpoint provides deterministic fault injection into the critical paths of the proxy for tests.
+//
+// Failpoints are compiled into the proxy only with the "failpoints" build tag (go test -tags failpoints ./...),
+// without the tag the proxy doesn't evaluate them, and enabling a failpoint has no effect (see Compiled).
+//
+// Tests enable the failpoint with the action, which is evaluated each time the proxy passes the failpoint:
+//
+//	failpoint.Enable(failpoint.Recv, failpoint.OnBackend("backend-2", failpoint.Return(status.Error(codes.Unavailable, "injected"))))
+//	defer failpoint.Reset()
+//
+// Tests of the one2many aggregation can make the interleaving of the backend responses deterministic with
+// EnableScheduling, the responses are delivered in the order picked by the seed.
+package failpoint
+
+import (
+	"sync"
+)
+
+// Failpoints of the proxy.
+const (
+	// Dial is evaluated before the connection to the backend is used to establish the upstream stream,
+	// and before ConnPool dials the target.
+	Dial = "dial"
+	// Send is evaluated before each message is sent to the backend.
+	Send = "send"
+	// Recv is evaluated before each message is received from the backend.
+	Recv = "recv"
+	// Close is evaluated before the upstream stream is half-closed.
+	Close = "close"
+	// Forward is evaluated before each message is sent to the client, once the message passed the check
+	// that the call is not finished, the backend is empty.
+	Forward = "forward"
+)
+
+// Point describes the evaluation of the failpoint.
+type Point struct {
+	// Name is the name of the failpoint.
+	Name string
+	// Backend is the name of the backend (the target for ConnPool dials).
+	Backend string
+	// Method is the full method name of the call, empty for ConnPool dials.
+	Method string
+}
+
+// Action is evaluated when the proxy passes the enabled failpoint, a non-nil error is injected into the code path.
+type Action func(p Point) error
+
+var (
+	mu      sync.Mutex
+	actions = map[string]Action{}
+)
+
+// Enable enables the failpoint with the action, replacing the previous action.
+func Enable(name string, action Action) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	actions[name] = action
+}
+
+// Disable disables the failpoint.
+func Disable(name string) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	delete(actions, name)
+}
+
+// Reset disables all the failpoints and the scheduling.
+func Reset() {
+	mu.Lock()
+	defer mu.Unlock()
+
+	actions = map[string]Action{}
+
+	DisableScheduling()
+}
+
+// Eval evaluates the failpoint, it is called by the proxy.
+func Eval(p Point) error {
+	mu.Lock()
+	action, ok := actions[p.Name]
+	mu.Unlock()
+
+	if !ok {
+		return nil
+	}
+
+	return action(p)
+}
+
+// Return returns the action which always injects the error.
+func Return(err error) Action {
+	return func(Point) error {
+		return err
+	}
+}
+
+// Nth returns the action which injects the error on the n-th evaluation (starting with 1) only.
+func Nth(n int, err error) Action {
+	var (
+		mu    sync.Mutex
+		count int
+	)
+
+	return func(Point) error {
+		mu.Lock()
+		defer mu.Unlock()
+
+		count++
+
+		if count == n {
+			return err
+		}
+
+		return nil
+	}
+}
+
+// OnBackend returns the action which evaluates the action only for the backend.
+func OnBackend(backend string, action Action) Action {
+	return func(p Point) error {
+		if p.Backend != backend {
+			return nil
+		}
+
+		return action(p)
+	}
+}
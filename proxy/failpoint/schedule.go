@@ -0,0 +1,236 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package failpoint
+
+import (
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ScheduleTimeout is the time the scheduler waits for all the live legs to receive a message before it starts
+// the round with the legs which did, so that a leg which never receives anything doesn't deadlock the test.
+var ScheduleTimeout = 5 * time.Second
+
+// scheduler serializes the delivery of the backend responses in rounds.
+//
+// Each upstream leg is registered with the scheduler once established. Once the leg receives a message (or an error)
+// from the backend, it waits until every live leg has received one as well. Then the round starts: the legs deliver
+// their messages one by one in the order picked with the seeded random source, the next leg is released once
+// the previous one has delivered its message (it asks the backend for the next one, or leaves). The messages received
+// during the round wait for the next round, so the delivery order depends only on the seed.
+type scheduler struct {
+	rnd *rand.Rand
+
+	mu      sync.Mutex
+	seq     uint64
+	live    map[*Leg]struct{}
+	ready   []*Leg
+	round   []*Leg
+	current *Leg
+	timer   *time.Timer
+}
+
+var (
+	schedulerMu sync.Mutex
+	current     *scheduler
+)
+
+// EnableScheduling enables the deterministic delivery of the backend responses ordered by the seed, so that
+// the interleaving of the one2many responses is reproducible in tests.
+//
+// Scheduling affects all the calls of the proxy, so the tests using it should not run the calls concurrently.
+func EnableScheduling(seed int64) {
+	schedulerMu.Lock()
+	defer schedulerMu.Unlock()
+
+	current = &scheduler{
+		rnd:  rand.New(rand.NewSource(seed)), //nolint:gosec // the order should be reproducible
+		live: map[*Leg]struct{}{},
+	}
+}
+
+// DisableScheduling disables the deterministic delivery.
+func DisableScheduling() {
+	schedulerMu.Lock()
+	defer schedulerMu.Unlock()
+
+	current = nil
+}
+
+// Leg is the upstream leg registered with the scheduler.
+//
+// All methods are safe to be called on nil leg, which is not scheduled.
+type Leg struct {
+	scheduler *scheduler
+	seq       uint64
+	release   chan struct{}
+	left      bool
+}
+
+// RegisterLeg registers the upstream leg with the scheduler, it returns nil if the scheduling is disabled.
+//
+// RegisterLeg is called by the proxy.
+func RegisterLeg() *Leg {
+	schedulerMu.Lock()
+	s := current
+	schedulerMu.Unlock()
+
+	if s == nil {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.seq++
+
+	leg := &Leg{scheduler: s, seq: s.seq}
+	s.live[leg] = struct{}{}
+
+	return leg
+}
+
+// Deliver waits until the leg is scheduled to deliver the message (or the error) received from the backend.
+// The leg leaves the scheduler once it delivers the error.
+//
+// Deliver is called by the proxy.
+func (l *Leg) Deliver(err error) {
+	if l == nil {
+		return
+	}
+
+	s := l.scheduler
+
+	s.mu.Lock()
+	if l.left {
+		s.mu.Unlock()
+
+		return
+	}
+
+	release := make(chan struct{})
+	l.release = release
+	s.ready = append(s.ready, l)
+	s.scheduleLocked()
+	s.mu.Unlock()
+
+	<-release
+
+	if err != nil {
+		l.Leave()
+	}
+}
+
+// Delivered marks the message released by Deliver as delivered, it is called before the leg receives the next message.
+//
+// Delivered is called by the proxy.
+func (l *Leg) Delivered() {
+	if l == nil {
+		return
+	}
+
+	s := l.scheduler
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.current == l {
+		s.current = nil
+		s.scheduleLocked()
+	}
+}
+
+// Leave removes the leg from the scheduler, e.g. once the upstream stream is canceled.
+//
+// Leave is called by the proxy.
+func (l *Leg) Leave() {
+	if l == nil {
+		return
+	}
+
+	s := l.scheduler
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if l.left {
+		return
+	}
+
+	l.left = true
+	delete(s.live, l)
+
+	if s.current == l {
+		s.current = nil
+	}
+
+	// the leg waiting for the delivery is released, as it is not going to deliver anything
+	for _, queue := range []*[]*Leg{&s.ready, &s.round} {
+		for i, leg := range *queue {
+			if leg == l {
+				*queue = append((*queue)[:i], (*queue)[i+1:]...)
+				close(l.release)
+
+				break
+			}
+		}
+	}
+
+	s.scheduleLocked()
+}
+
+// scheduleLocked releases the next leg of the round, and starts the next round once all the live legs are ready.
+func (s *scheduler) scheduleLocked() {
+	if s.current != nil {
+		return
+	}
+
+	if len(s.round) == 0 {
+		if len(s.ready) == 0 {
+			return
+		}
+
+		if len(s.ready) < len(s.live) {
+			if s.timer == nil {
+				s.timer = time.AfterFunc(ScheduleTimeout, s.timeout)
+			}
+
+			return
+		}
+
+		s.startRoundLocked()
+	}
+
+	s.current = s.round[0]
+	s.round = s.round[1:]
+
+	close(s.current.release)
+}
+
+func (s *scheduler) startRoundLocked() {
+	if s.timer != nil {
+		s.timer.Stop()
+		s.timer = nil
+	}
+
+	sort.Slice(s.ready, func(i, j int) bool { return s.ready[i].seq < s.ready[j].seq })
+	s.rnd.Shuffle(len(s.ready), func(i, j int) { s.ready[i], s.ready[j] = s.ready[j], s.ready[i] })
+
+	s.round, s.ready = s.ready, nil
+}
+
+// timeout starts the round with the legs which are ready.
+func (s *scheduler) timeout() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.timer = nil
+
+	if s.current == nil && len(s.round) == 0 && len(s.ready) > 0 {
+		s.startRoundLocked()
+		s.scheduleLocked()
+	}
+}
@@ -0,0 +1,18 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+//go:build !failpoints
+
+package proxy
+
+import "google.golang.org/grpc"
+
+// evalFailpoint evaluates the failpoint, failpoints are compiled in only with the "failpoints" build tag.
+func evalFailpoint(name, backend, fullMethodName string) error {
+	return nil
+}
+
+// failpointClientStream evaluates the stream failpoints of the upstream stream.
+func failpointClientStream(clientStream grpc.ClientStream, backend Backend, fullMethodName string) grpc.ClientStream {
+	return clientStream
+}
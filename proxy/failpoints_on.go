@@ -0,0 +1,68 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+//go:build failpoints
+
+package proxy
+
+import (
+	"google.golang.org/grpc"
+
+	"github.com/noncepad/grpc-proxy/proxy/failpoint"
+)
+
+// evalFailpoint evaluates the failpoint, see package failpoint.
+func evalFailpoint(name, backend, fullMethodName string) error {
+	return failpoint.Eval(failpoint.Point{Name: name, Backend: backend, Method: fullMethodName})
+}
+
+// failpointClientStream evaluates the stream failpoints of the upstream stream, and registers the stream
+// with the scheduler, if the scheduling is enabled (see failpoint.EnableScheduling).
+func failpointClientStream(clientStream grpc.ClientStream, backend Backend, fullMethodName string) grpc.ClientStream {
+	leg := failpoint.RegisterLeg()
+	if leg != nil {
+		go func() {
+			<-clientStream.Context().Done()
+			leg.Leave()
+		}()
+	}
+
+	return &failpointStream{ClientStream: clientStream, backend: backend.String(), method: fullMethodName, leg: leg}
+}
+
+type failpointStream struct {
+	grpc.ClientStream
+
+	backend string
+	method  string
+	leg     *failpoint.Leg
+}
+
+func (s *failpointStream) SendMsg(m interface{}) error {
+	if err := evalFailpoint(failpoint.Send, s.backend, s.method); err != nil {
+		return err
+	}
+
+	return s.ClientStream.SendMsg(m)
+}
+
+func (s *failpointStream) RecvMsg(m interface{}) error {
+	s.leg.Delivered()
+
+	err := evalFailpoint(failpoint.Recv, s.backend, s.method)
+	if err == nil {
+		err = s.ClientStream.RecvMsg(m)
+	}
+
+	s.leg.Deliver(err)
+
+	return err
+}
+
+func (s *failpointStream) CloseSend() error {
+	if err := evalFailpoint(failpoint.Close, s.backend, s.method); err != nil {
+		return err
+	}
+
+	return s.ClientStream.CloseSend()
+}
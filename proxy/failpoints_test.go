@@ -0,0 +1,204 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+//go:build failpoints
+
+package proxy_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/noncepad/grpc-proxy/proxy"
+	"github.com/noncepad/grpc-proxy/proxy/failpoint"
+	pb "github.com/noncepad/grpc-proxy/testservice"
+)
+
+func TestFailpoints(t *testing.T) {
+	require.True(t, failpoint.Compiled())
+
+	h := newTestHarnessWithService(t, &lenientService{}, func(backend proxy.Backend) proxy.StreamDirector {
+		return func(ctx context.Context, fullMethodName string) (proxy.Mode, []proxy.Backend, error) {
+			return proxy.One2One, []proxy.Backend{backend}, nil
+		}
+	})
+
+	for _, tt := range []struct {
+		name string
+		code codes.Code
+	}{
+		{name: failpoint.Dial, code: codes.Aborted},
+		// errors sending to the upstream are reported as proxy failures
+		{name: failpoint.Send, code: codes.Internal},
+		{name: failpoint.Recv, code: codes.Aborted},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var points []failpoint.Point
+
+			failpoint.Enable(tt.name, func(p failpoint.Point) error {
+				points = append(points, p)
+
+				return status.Error(codes.Aborted, "injected")
+			})
+			t.Cleanup(failpoint.Reset)
+
+			_, err := h.client.Ping(testContext(t), &pb.PingRequest{Value: "foo"})
+			assert.Equal(t, tt.code, status.Code(err))
+
+			require.NotEmpty(t, points)
+			assert.Equal(t, "/talos.testproto.TestService/Ping", points[0].Method)
+		})
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		failpoint.Enable(failpoint.Recv, failpoint.Return(status.Error(codes.Aborted, "injected")))
+		failpoint.Disable(failpoint.Recv)
+
+		resp, err := h.client.Ping(testContext(t), &pb.PingRequest{Value: "foo"})
+		require.NoError(t, err)
+		assert.Equal(t, "foo", resp.Value)
+	})
+}
+
+// taggedListService responds to PingList with the tag of the backend.
+type taggedListService struct {
+	assertingService
+}
+
+func (s *taggedListService) PingList(ping *pb.PingRequest, stream pb.TestService_PingListServer) error {
+	tag := metadata.ValueFromIncomingContext(stream.Context(), backendTagMdKey)[0]
+
+	for i := 0; i < 5; i++ {
+		if err := stream.Send(&pb.PingResponse{Value: tag, Counter: int32(i)}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func TestFailpointScheduling(t *testing.T) {
+	h := newTestHarnessWithService(t, &taggedListService{}, func(backend proxy.Backend) proxy.StreamDirector {
+		return func(ctx context.Context, fullMethodName string) (proxy.Mode, []proxy.Backend, error) {
+			return proxy.One2Many, []proxy.Backend{
+				&taggedBackend{Backend: backend, tag: "a"},
+				&taggedBackend{Backend: backend, tag: "b"},
+				&taggedBackend{Backend: backend, tag: "c"},
+			}, nil
+		}
+	}, proxy.WithStreamedDetector(func(fullMethodName string) bool { return fullMethodName == "/talos.testproto.TestService/PingList" }))
+
+	defer failpoint.Reset()
+
+	ctx := testContext(t)
+
+	run := func(seed int64) string {
+		failpoint.EnableScheduling(seed)
+		defer failpoint.DisableScheduling()
+
+		stream, err := h.client.PingList(ctx, &pb.PingRequest{Value: "foo"})
+		require.NoError(t, err)
+
+		var order strings.Builder
+
+		for {
+			resp, err := stream.Recv()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+
+			require.NoError(t, err)
+
+			order.WriteString(resp.Value)
+		}
+
+		return order.String()
+	}
+
+	orders := map[string]struct{}{}
+
+	for seed := int64(1); seed <= 5; seed++ {
+		order := run(seed)
+
+		assert.Len(t, order, 15)
+		assert.Equal(t, order, run(seed), "seed %d", seed)
+
+		// each round delivers a message of every backend
+		for round := 0; round < 5; round++ {
+			assert.ElementsMatch(t, []rune("abc"), []rune(order[round*3:round*3+3]))
+		}
+
+		orders[order] = struct{}{}
+	}
+
+	assert.Greater(t, len(orders), 1)
+}
+
+func TestFailpointForwardJoined(t *testing.T) {
+	var backend proxy.Backend
+
+	newTestHarnessWithService(t, &floodService{}, func(b proxy.Backend) proxy.StreamDirector {
+		backend = b
+
+		return one2oneDirector(b)
+	})
+
+	client, violations := newGuardedProxy(t, func(ctx context.Context, fullMethodName string) (proxy.Mode, []proxy.Backend, error) {
+		return proxy.One2One, []proxy.Backend{backend}, nil
+	},
+		proxy.WithStreamedDetector(func(fullMethodName string) bool { return fullMethodName == pingList }),
+		proxy.WithStreamLimits(pingList, proxy.StreamLimits{}, proxy.StreamLimits{MaxDuration: 50 * time.Millisecond}),
+	)
+
+	defer failpoint.Reset()
+
+	var (
+		once     sync.Once
+		reached  = make(chan struct{})
+		released = make(chan struct{})
+	)
+
+	// the first message blocks after it passed the fence check, but before it is written to the client stream
+	failpoint.Enable(failpoint.Forward, func(p failpoint.Point) error {
+		once.Do(func() {
+			assert.Equal(t, pingList, p.Method)
+
+			close(reached)
+			<-released
+		})
+
+		return nil
+	})
+
+	stream, err := client.PingList(testContext(t), &pb.PingRequest{Value: "flood"})
+	require.NoError(t, err)
+
+	<-reached
+
+	// the handler finishes on the duration limit while the message is being sent, and waits for it to be written
+	time.Sleep(150 * time.Millisecond)
+	close(released)
+
+	for err == nil {
+		_, err = stream.Recv()
+	}
+
+	assert.Equal(t, codes.DeadlineExceeded, status.Code(err))
+
+	// let the handler finish
+	time.Sleep(100 * time.Millisecond)
+
+	assert.Zero(t, atomic.LoadInt32(violations))
+}
@@ -0,0 +1,64 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy
+
+import "google.golang.org/grpc"
+
+// FallbackHandler produces a degraded response when none of the backends are available.
+//
+// The handler works with the client stream the same way as a regular gRPC stream handler: messages can be received
+// and sent either as *Frame (raw bytes), or as typed protobuf messages. The error passed to the handler describes why
+// the backends are not available; returning it makes the call fail the same way as without the fallback.
+type FallbackHandler func(serverStream grpc.ServerStream, fullMethodName string, err error) error
+
+// WithLocalFallback sets the fallback handler for the listed methods.
+//
+// The fallback handler is invoked instead of failing the call with codes.Unavailable when the director returned
+// no backends, or connections to all the backends failed. It can serve cached data, a static payload, or a more
+// descriptive error. If fullMethodNames is empty, the handler is used for all methods.
+func WithLocalFallback(handler FallbackHandler, fullMethodNames ...string) Option {
+	return func(o *handlerOptions) {
+		if o.fallbackHandlers == nil {
+			o.fallbackHandlers = map[string]FallbackHandler{}
+		}
+
+		if len(fullMethodNames) == 0 {
+			o.fallbackHandlers[""] = handler
+
+			return
+		}
+
+		for _, name := range fullMethodNames {
+			o.fallbackHandlers[name] = handler
+		}
+	}
+}
+
+func (o *handlerOptions) fallbackHandler(fullMethodName string) FallbackHandler {
+	if handler, ok := o.fallbackHandlers[fullMethodName]; ok {
+		return handler
+	}
+
+	return o.fallbackHandlers[""]
+}
+
+// backendsDown returns an error if none of the backend connections is usable.
+func backendsDown(fullMethodName string, backendConnections []backendConnection) error {
+	if len(backendConnections) == 0 {
+		return newError(ErrNoBackends, "no backends for %s", fullMethodName)
+	}
+
+	for i := range backendConnections {
+		if backendConnections[i].connError == nil {
+			return nil
+		}
+	}
+
+	err := backendConnections[0].connError
+	if _, ok := FromError(err); ok {
+		return err
+	}
+
+	return &BackendDialError{Err: err, Backend: backendConnections[0].backend.String()}
+}
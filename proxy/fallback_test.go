@@ -0,0 +1,69 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/noncepad/grpc-proxy/proxy"
+	pb "github.com/noncepad/grpc-proxy/testservice"
+)
+
+func TestLocalFallback(t *testing.T) {
+	down := &proxy.SingleBackend{
+		GetConn: func(ctx context.Context) (context.Context, *grpc.ClientConn, error) {
+			return ctx, nil, status.Error(codes.Unavailable, "connection refused")
+		},
+	}
+
+	var fallbackErr error
+
+	h := newTestHarness(t, func(backend proxy.Backend) proxy.StreamDirector {
+		return func(ctx context.Context, fullMethodName string) (proxy.Mode, []proxy.Backend, error) {
+			switch fullMethodName {
+			case "/talos.testproto.TestService/PingList":
+				return proxy.One2Many, nil, nil
+			case "/talos.testproto.TestService/PingEmpty":
+				return proxy.One2One, []proxy.Backend{down}, nil
+			}
+
+			return proxy.One2Many, []proxy.Backend{down, down}, nil
+		}
+	}, proxy.WithLocalFallback(func(serverStream grpc.ServerStream, fullMethodName string, err error) error {
+		fallbackErr = err
+
+		var req pb.PingRequest
+
+		if err := serverStream.RecvMsg(&req); err != nil {
+			return err
+		}
+
+		return serverStream.SendMsg(&pb.PingResponse{Value: "cached " + req.Value})
+	}, "/talos.testproto.TestService/Ping", "/talos.testproto.TestService/PingList"))
+
+	out, err := h.client.Ping(testContext(t), &pb.PingRequest{Value: "foo"})
+	require.NoError(t, err)
+	assert.Equal(t, "cached foo", out.Value)
+	assert.True(t, errors.Is(fallbackErr, proxy.ErrBackendDial))
+
+	stream, err := h.client.PingList(testContext(t), &pb.PingRequest{Value: "bar"})
+	require.NoError(t, err)
+
+	out, err = stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, "cached bar", out.Value)
+	assert.True(t, errors.Is(fallbackErr, proxy.ErrNoBackends))
+
+	_, err = h.client.PingEmpty(testContext(t), &pb.Empty{})
+	require.Error(t, err)
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+}
@@ -0,0 +1,137 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy
+
+import (
+	"errors"
+	"io"
+
+	"google.golang.org/grpc"
+)
+
+// WithUnaryFastPath enables the fast path for one2one calls of the listed unary methods.
+//
+// On the fast path the call is forwarded like grpc.ClientConn.Invoke: the single request is read from the client,
+// the upstream call is made synchronously, and the single response is sent back, without the forwarding goroutines
+// and the bidirectional upstream stream. The upstream unary interceptors (see WithUpstreamUnaryInterceptors) are
+// applied on the fast path.
+//
+// Methods are considered unary the same way as for WithUpstreamUnaryInterceptors. The calls which can't take the
+// fast path (one2many calls, or the methods with chunking, stream limits, detached upstreams or the full close
+// timeout) are proxied as usual. If fullMethodNames is empty, the fast path is enabled for all unary methods.
+func WithUnaryFastPath(fullMethodNames ...string) Option {
+	return func(o *handlerOptions) {
+		if o.unaryFastPathMethods == nil {
+			o.unaryFastPathMethods = map[string]struct{}{}
+		}
+
+		if len(fullMethodNames) == 0 {
+			o.unaryFastPathMethods[""] = struct{}{}
+
+			return
+		}
+
+		for _, name := range fullMethodNames {
+			o.unaryFastPathMethods[name] = struct{}{}
+		}
+	}
+}
+
+// unaryFastPath checks whether the call can take the unary fast path.
+func (o *handlerOptions) unaryFastPath(fullMethodName string, mode Mode, backends []Backend) bool {
+	if len(o.unaryFastPathMethods) == 0 || len(o.upstreamStreamInterceptors) > 0 || mode != One2One || len(backends) != 1 {
+		return false
+	}
+
+	if _, ok := o.unaryFastPathMethods[fullMethodName]; !ok {
+		if _, ok = o.unaryFastPathMethods[""]; !ok {
+			return false
+		}
+	}
+
+	if _, ok := o.chunkingMethods[fullMethodName]; ok {
+		return false
+	}
+
+	if _, ok := o.streamLimits[fullMethodName]; ok {
+		return false
+	}
+
+	if _, ok := o.detachedMethods[fullMethodName]; ok {
+		return false
+	}
+
+	if o.halfClosePolicy(fullMethodName).FullCloseTimeout > 0 {
+		return false
+	}
+
+	return o.isUnary(fullMethodName)
+}
+
+// handlerUnary proxies the unary one2one call on the fast path.
+func (s *handler) handlerUnary(fullMethodName string, serverStream grpc.ServerStream, src *backendConnection) error {
+	if src.connError != nil {
+		return one2oneConnError(src)
+	}
+
+	request := NewFrame(nil)
+
+	if err := serverStream.RecvMsg(request); err != nil {
+		if errors.Is(err, io.EOF) {
+			return newError(ErrMalformedRequest, "no request message for unary method %s", fullMethodName)
+		}
+
+		return s2cError(err)
+	}
+
+	if err := src.clientStream.SendMsg(request); err != nil {
+		return s2cError(err)
+	}
+
+	src.clientStream.CloseSend() //nolint:errcheck
+
+	response := &Frame{}
+
+	if err := src.clientStream.RecvMsg(response); err != nil {
+		serverStream.SetTrailer(src.clientStream.Trailer())
+
+		return err
+	}
+
+	// the upstream call is finished by now, receive io.EOF to let the stream wrappers observe the end of the call
+	if err := src.clientStream.RecvMsg(&Frame{}); !errors.Is(err, io.EOF) {
+		if err == nil {
+			err = newError(ErrInternal, "more than one response message for unary method %s", fullMethodName)
+		}
+
+		return err
+	}
+
+	if buffer := s.options.newResponseBuffer(fullMethodName); buffer != nil {
+		if err := buffer.add(src.backend, response.payload); err != nil {
+			return err
+		}
+
+		if err := buffer.verify(src.backend, fullMethodName, src.clientStream.Trailer()); err != nil {
+			return err
+		}
+	}
+
+	md, err := src.clientStream.Header()
+	if err != nil {
+		return err
+	}
+
+	if err = serverStream.SendHeader(md); err != nil {
+		return err
+	}
+
+	if err = serverStream.SendMsg(response); err != nil {
+		return err
+	}
+
+	serverStream.SetTrailer(src.clientStream.Trailer())
+
+	return nil
+}
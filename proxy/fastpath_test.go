@@ -0,0 +1,142 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/noncepad/grpc-proxy/proxy"
+	pb "github.com/noncepad/grpc-proxy/testservice"
+)
+
+func TestUnaryFastPath(t *testing.T) {
+	for _, tt := range []struct {
+		name         string
+		options      []proxy.Option
+		streams      int32
+		interceptors int32
+	}{
+		{
+			name:    "disabled",
+			streams: 3,
+		},
+		{
+			name:    "enabled",
+			options: []proxy.Option{proxy.WithUnaryFastPath()},
+		},
+		{
+			name:    "other method",
+			options: []proxy.Option{proxy.WithUnaryFastPath("/talos.testproto.TestService/PingEmpty")},
+			streams: 3,
+		},
+		{
+			name:         "stream interceptors",
+			options:      []proxy.Option{proxy.WithUnaryFastPath()},
+			streams:      3,
+			interceptors: 3,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var streams, interceptors int32
+
+			options := append([]proxy.Option{
+				proxy.WithStreamedDetector(func(fullMethodName string) bool { return fullMethodName == "/talos.testproto.TestService/PingStream" }),
+			}, tt.options...)
+
+			// the fast path is disabled if the stream interceptors are registered, as they are not applied to it
+			if tt.interceptors > 0 {
+				options = append(options, proxy.WithUpstreamStreamInterceptors(
+					func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+						atomic.AddInt32(&interceptors, 1)
+
+						return streamer(ctx, desc, cc, method, opts...)
+					}))
+			}
+
+			var conn *grpc.ClientConn
+
+			h := newTestHarness(t, func(proxy.Backend) proxy.StreamDirector {
+				return one2oneDirector(&proxy.SingleBackend{
+					GetConn: func(ctx context.Context) (context.Context, *grpc.ClientConn, error) {
+						md, _ := metadata.FromIncomingContext(ctx)
+
+						return metadata.NewOutgoingContext(ctx, md.Copy()), conn, nil
+					},
+				})
+			}, options...)
+
+			// upstream streams are not established on the fast path
+			conn, err := grpc.Dial(h.backendAddr,
+				grpc.WithTransportCredentials(insecure.NewCredentials()),
+				grpc.WithCodec(proxy.Codec()), //nolint: staticcheck
+				grpc.WithStreamInterceptor(func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string,
+					streamer grpc.Streamer, opts ...grpc.CallOption,
+				) (grpc.ClientStream, error) {
+					atomic.AddInt32(&streams, 1)
+
+					return streamer(ctx, desc, cc, method, opts...)
+				}),
+			)
+			require.NoError(t, err)
+
+			t.Cleanup(func() { conn.Close() }) //nolint: errcheck
+
+			for i := 0; i < 2; i++ {
+				var header, trailer metadata.MD
+
+				resp, err := h.client.Ping(testContext(t), &pb.PingRequest{Value: "foo"}, grpc.Header(&header), grpc.Trailer(&trailer))
+				require.NoError(t, err)
+
+				assert.Equal(t, "foo", resp.Value)
+				assert.Equal(t, int32(42), resp.Counter)
+				assert.Equal(t, []string{"I like turtles."}, header.Get(serverHeaderMdKey))
+				assert.Equal(t, []string{"I like ending turtles."}, trailer.Get(serverTrailerMdKey))
+			}
+
+			_, err = h.client.PingError(testContext(t), &pb.PingRequest{Value: "foo"})
+			assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+			assert.Equal(t, "Userspace error.", status.Convert(err).Message())
+
+			assert.Equal(t, tt.streams, atomic.LoadInt32(&streams))
+			assert.Equal(t, tt.interceptors, atomic.LoadInt32(&interceptors))
+		})
+	}
+}
+
+func BenchmarkUnary(b *testing.B) {
+	for _, bb := range []struct {
+		name    string
+		options []proxy.Option
+	}{
+		{name: "stream"},
+		{name: "fast path", options: []proxy.Option{proxy.WithUnaryFastPath()}},
+	} {
+		b.Run(bb.name, func(b *testing.B) {
+			h := newTestHarnessWithService(b, &lenientService{}, one2oneDirector, append(bb.options,
+				proxy.WithStreamedDetector(func(fullMethodName string) bool { return fullMethodName == "/talos.testproto.TestService/PingStream" }),
+			)...)
+
+			ctx := testContext(b)
+
+			b.ReportAllocs()
+			b.ResetTimer()
+
+			for i := 0; i < b.N; i++ {
+				if _, err := h.client.PingEmpty(ctx, &pb.Empty{}); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,201 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/noncepad/grpc-proxy/proxy/failpoint"
+)
+
+// errCallFinished is returned by the client stream writes after the handler returned.
+var errCallFinished = errors.New("proxied call is finished")
+
+// sendDrainTimeout limits the wait for the client stream writes in progress when the call finishes.
+const sendDrainTimeout = time.Second
+
+// forwarders owns the forwarding goroutines of the proxied call.
+//
+// Downstream goroutines (which read the upstream streams and write to the client stream) are joined when the call
+// finishes: the upstream calls are canceled first, so that the goroutines return, and the client stream is fenced,
+// so that nothing is written to the client stream after the handler returned even if the goroutine is still running
+// (upstream calls of the detached methods are not canceled, see WithDetachedUpstream: their context is released
+// once the downstream goroutines return instead).
+//
+// The client stream writes in progress are waited for up to sendDrainTimeout: the writes blocked in the client
+// stream SendMsg (e.g. on the flow control, if the client stopped reading) can't be unblocked before the handler
+// returns, and they return once the client stream is closed, without writing anything else as the stream is fenced.
+//
+// Upstream goroutines (which read the client stream) are not joined, as the client stream can't be unblocked
+// before the handler returns, and they only write to the upstream streams.
+type forwarders struct {
+	downstream *fencedServerStream
+	cancel     context.CancelFunc
+
+	mu   sync.Mutex
+	idle *sync.Cond
+	// running is the number of the downstream goroutines, sending is the number of the client stream writes in progress
+	running, sending int
+	// expired is set once the wait for the client stream writes times out
+	expired bool
+
+	join     bool
+	detached *detachedUpstream
+}
+
+// newForwarders creates the forwarders of the call, upstreamCtx is the context of the upstream calls, and cancel
+// should cancel it.
+//
+// The goroutines are joined only if the upstream calls are not detached, i.e. if cancel unblocks the upstream calls.
+func newForwarders(upstreamCtx context.Context, serverStream grpc.ServerStream, cancel context.CancelFunc) *forwarders {
+	g := &forwarders{
+		cancel:   cancel,
+		detached: detachedUpstreamFromContext(upstreamCtx),
+	}
+
+	g.join = g.detached == nil
+
+	g.idle = sync.NewCond(&g.mu)
+	g.downstream = &fencedServerStream{ServerStream: serverStream, sends: g.track(&g.sending)}
+	g.downstream.method, _ = grpc.MethodFromServerStream(serverStream)
+
+	return g
+}
+
+// track returns the function which adjusts the counter and wakes up finish.
+func (g *forwarders) track(counter *int) func(delta int) {
+	return func(delta int) {
+		g.mu.Lock()
+		*counter += delta
+		g.mu.Unlock()
+
+		g.idle.Broadcast()
+	}
+}
+
+// goDownstream starts the goroutine which writes to the client stream.
+func (g *forwarders) goDownstream(f func()) {
+	running := g.track(&g.running)
+
+	running(1)
+
+	if g.detached != nil {
+		g.detached.acquire()
+	}
+
+	go func() {
+		defer running(-1)
+
+		if g.detached != nil {
+			defer g.detached.release()
+		}
+
+		f()
+	}()
+}
+
+// goUpstream starts the goroutine which reads the client stream.
+func (g *forwarders) goUpstream(f func()) {
+	go f()
+}
+
+// finish cancels the upstream calls, fences the client stream and waits for the client stream writes in progress
+// (up to sendDrainTimeout) and for the downstream goroutines which are not writing to the client stream.
+func (g *forwarders) finish() {
+	g.cancel()
+	g.downstream.fence()
+
+	timer := time.AfterFunc(sendDrainTimeout, func() {
+		g.mu.Lock()
+		g.expired = true
+		g.mu.Unlock()
+
+		g.idle.Broadcast()
+	})
+	defer timer.Stop()
+
+	g.mu.Lock()
+
+	for (g.sending > 0 && !g.expired) || (g.join && g.running > g.sending) {
+		g.idle.Wait()
+	}
+
+	g.mu.Unlock()
+}
+
+// fencedServerStream rejects the writes once fenced.
+//
+// The metadata writes don't block, so they are serialized with the fence. The messages are never sent while
+// holding the lock, as SendMsg blocks until the client reads: the fence is checked and the message is counted
+// as being sent under the lock instead, so that finish waits for every message which passed the fence.
+type fencedServerStream struct {
+	grpc.ServerStream
+
+	mu     sync.Mutex
+	fenced bool
+
+	method string
+	sends  func(delta int)
+}
+
+// fence waits for the metadata writes in progress and rejects the following writes.
+func (s *fencedServerStream) fence() {
+	s.mu.Lock()
+	s.fenced = true
+	s.mu.Unlock()
+}
+
+func (s *fencedServerStream) writeMetadata(f func() error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.fenced {
+		return errCallFinished
+	}
+
+	return f()
+}
+
+func (s *fencedServerStream) SetHeader(md metadata.MD) error {
+	return s.writeMetadata(func() error { return s.ServerStream.SetHeader(md) })
+}
+
+func (s *fencedServerStream) SendHeader(md metadata.MD) error {
+	return s.writeMetadata(func() error { return s.ServerStream.SendHeader(md) })
+}
+
+func (s *fencedServerStream) SetTrailer(md metadata.MD) {
+	s.writeMetadata(func() error { //nolint:errcheck
+		s.ServerStream.SetTrailer(md)
+
+		return nil
+	})
+}
+
+func (s *fencedServerStream) SendMsg(m interface{}) error {
+	s.mu.Lock()
+
+	if s.fenced {
+		s.mu.Unlock()
+
+		return errCallFinished
+	}
+
+	s.sends(1)
+	s.mu.Unlock()
+
+	defer s.sends(-1)
+
+	if err := evalFailpoint(failpoint.Forward, "", s.method); err != nil {
+		return err
+	}
+
+	return s.ServerStream.SendMsg(m)
+}
@@ -0,0 +1,242 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy_test
+
+import (
+	"context"
+	"net"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/noncepad/grpc-proxy/proxy"
+	pb "github.com/noncepad/grpc-proxy/testservice"
+)
+
+const pingList = "/talos.testproto.TestService/PingList"
+
+// floodService streams PingList responses at a high rate until the call is canceled.
+type floodService struct {
+	lenientService
+}
+
+func (s *floodService) PingList(ping *pb.PingRequest, stream pb.TestService_PingListServer) error {
+	for counter := int32(0); ; counter++ {
+		time.Sleep(100 * time.Microsecond)
+
+		if err := stream.Send(&pb.PingResponse{Value: ping.Value, Counter: counter}); err != nil {
+			return err
+		}
+	}
+}
+
+// returnGuardStream counts the writes made after the handler returned.
+type returnGuardStream struct {
+	grpc.ServerStream
+
+	returned   *int32
+	violations *int32
+}
+
+func (s *returnGuardStream) check() {
+	if atomic.LoadInt32(s.returned) == 1 {
+		atomic.AddInt32(s.violations, 1)
+	}
+}
+
+func (s *returnGuardStream) SendMsg(m interface{}) error {
+	s.check()
+
+	return s.ServerStream.SendMsg(m)
+}
+
+func (s *returnGuardStream) SendHeader(md metadata.MD) error {
+	s.check()
+
+	return s.ServerStream.SendHeader(md)
+}
+
+// newGuardedProxy starts the proxy with the director, counting the writes to the client streams
+// made after the handler returned.
+func newGuardedProxy(t *testing.T, director proxy.StreamDirector, options ...proxy.Option) (pb.TestServiceClient, *int32) {
+	t.Helper()
+
+	var violations int32
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	handler, err := proxy.NewTransparentHandler(director, options...)
+	require.NoError(t, err)
+
+	server := grpc.NewServer(
+		grpc.CustomCodec(proxy.Codec()), //nolint: staticcheck
+		grpc.UnknownServiceHandler(handler),
+		grpc.StreamInterceptor(func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			var returned int32
+
+			err := handler(srv, &returnGuardStream{ServerStream: ss, returned: &returned, violations: &violations})
+
+			atomic.StoreInt32(&returned, 1)
+
+			return err
+		}),
+	)
+
+	go server.Serve(listener) //nolint: errcheck
+
+	conn, err := grpc.Dial(listener.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		conn.Close() //nolint: errcheck
+		server.Stop()
+	})
+
+	return pb.NewTestServiceClient(conn), &violations
+}
+
+func TestForwardersNoWritesAfterReturn(t *testing.T) {
+	streamed := proxy.WithStreamedDetector(func(fullMethodName string) bool { return fullMethodName == pingList })
+
+	for _, tt := range []struct {
+		name    string
+		mode    proxy.Mode
+		options []proxy.Option
+		code    codes.Code
+		cancel  bool
+		stall   bool
+	}{
+		{
+			name:   "one2one client cancel",
+			mode:   proxy.One2One,
+			cancel: true,
+		},
+		{
+			name:   "one2many client cancel",
+			mode:   proxy.One2Many,
+			cancel: true,
+		},
+		{
+			name:    "one2one detached client cancel",
+			mode:    proxy.One2One,
+			options: []proxy.Option{proxy.WithDetachedUpstream(100*time.Millisecond, pingList)},
+			cancel:  true,
+		},
+		{
+			name:    "one2one response limit",
+			mode:    proxy.One2One,
+			options: []proxy.Option{proxy.WithStreamLimits(pingList, proxy.StreamLimits{}, proxy.StreamLimits{MaxMessages: 5})},
+			code:    codes.ResourceExhausted,
+		},
+		{
+			name:    "one2many response limit",
+			mode:    proxy.One2Many,
+			options: []proxy.Option{proxy.WithStreamLimits(pingList, proxy.StreamLimits{}, proxy.StreamLimits{MaxMessages: 5})},
+			code:    codes.ResourceExhausted,
+		},
+		{
+			name: "one2one stalled client",
+			mode: proxy.One2One,
+			options: []proxy.Option{
+				proxy.WithStreamLimits(pingList, proxy.StreamLimits{}, proxy.StreamLimits{MaxDuration: 200 * time.Millisecond}),
+				proxy.WithMethodConcurrencyLimit(pingList, 1, 50*time.Millisecond),
+			},
+			stall: true,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var backend proxy.Backend
+
+			newTestHarnessWithService(t, &floodService{}, func(b proxy.Backend) proxy.StreamDirector {
+				backend = b
+
+				return one2oneDirector(b)
+			})
+
+			client, violations := newGuardedProxy(t, func(ctx context.Context, fullMethodName string) (proxy.Mode, []proxy.Backend, error) {
+				if tt.mode == proxy.One2One {
+					return proxy.One2One, []proxy.Backend{backend}, nil
+				}
+
+				return proxy.One2Many, []proxy.Backend{
+					&taggedBackend{Backend: backend, tag: "a"},
+					&taggedBackend{Backend: backend, tag: "b"},
+				}, nil
+			}, append([]proxy.Option{streamed}, tt.options...)...)
+
+			if tt.stall {
+				ctx, cancel := context.WithCancel(testContext(t))
+				defer cancel()
+
+				// the client stops reading, so the handler gets blocked sending the responses
+				stalled, err := client.PingList(ctx, &pb.PingRequest{Value: strings.Repeat("x", 64*1024)})
+				require.NoError(t, err)
+
+				_, err = stalled.Recv()
+				require.NoError(t, err)
+
+				// the handler returns once the duration limit is exceeded, releasing the concurrency slot
+				require.Eventually(t, func() bool {
+					callCtx, callCancel := context.WithCancel(ctx)
+					defer callCancel()
+
+					stream, callErr := client.PingList(callCtx, &pb.PingRequest{Value: "flood"})
+					if callErr != nil {
+						return false
+					}
+
+					_, callErr = stream.Recv()
+
+					return callErr == nil
+				}, 2*time.Second, 50*time.Millisecond)
+
+				assert.Zero(t, atomic.LoadInt32(violations))
+
+				return
+			}
+
+			for i := 0; i < 100; i++ {
+				ctx, cancel := context.WithCancel(testContext(t))
+
+				stream, err := client.PingList(ctx, &pb.PingRequest{Value: "flood"})
+				require.NoError(t, err)
+
+				for j := 0; j < 3; j++ {
+					_, err = stream.Recv()
+					require.NoError(t, err)
+				}
+
+				if tt.cancel {
+					// the responses already buffered by the client are not drained
+					cancel()
+
+					continue
+				}
+
+				for err == nil {
+					_, err = stream.Recv()
+				}
+
+				assert.Equal(t, tt.code, status.Code(err))
+
+				cancel()
+			}
+
+			// let the handlers finish
+			time.Sleep(100 * time.Millisecond)
+
+			assert.Zero(t, atomic.LoadInt32(violations))
+		})
+	}
+}
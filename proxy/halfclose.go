@@ -0,0 +1,195 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy
+
+import (
+	"errors"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+// HalfCloseMode controls when the client half-close is propagated to the upstreams.
+//
+// Regardless of the mode, the proxy guarantees that each upstream receives the client frames in order,
+// and that the upstream half-close (CloseSend) is issued only after all the client frames were handed over to that
+// upstream, so no client frame is lost or reordered with the half-close.
+type HalfCloseMode int
+
+// Half-close modes.
+const (
+	// HalfCloseAfterFlush delays CloseSend until all the client frames are flushed to all the upstreams.
+	//
+	// Each client frame is sent to all upstreams before the next frame is read, so in one2many mode the upstreams
+	// observe the half-close at the same time, after the slowest upstream accepted the last frame. This is the default.
+	HalfCloseAfterFlush HalfCloseMode = iota
+	// HalfCloseImmediate propagates the client half-close to each upstream as soon as possible.
+	//
+	// In one2many mode client frames are queued for each upstream independently, and each upstream is half-closed
+	// as soon as it accepted the last frame, without waiting for the slower upstreams.
+	// In one2one mode this is the same as HalfCloseAfterFlush.
+	HalfCloseImmediate
+)
+
+// halfCloseQueueSize is the number of frames queued for each upstream in HalfCloseImmediate mode.
+const halfCloseQueueSize = 16
+
+// HalfClosePolicy configures half-close handling.
+type HalfClosePolicy struct {
+	Mode HalfCloseMode
+	// FullCloseTimeout forces full close of the call if the upstreams don't finish the call within the timeout
+	// after the client half-close. The call fails with codes.DeadlineExceeded, and the upstream calls are canceled.
+	//
+	// Zero means no timeout.
+	FullCloseTimeout time.Duration
+}
+
+// WithHalfClose sets the half-close policy for the listed methods.
+//
+// If fullMethodNames is empty, the policy is applied to all methods without a method-specific policy.
+func WithHalfClose(policy HalfClosePolicy, fullMethodNames ...string) Option {
+	return func(o *handlerOptions) {
+		if o.halfClosePolicies == nil {
+			o.halfClosePolicies = map[string]HalfClosePolicy{}
+		}
+
+		if len(fullMethodNames) == 0 {
+			o.halfClosePolicies[""] = policy
+
+			return
+		}
+
+		for _, name := range fullMethodNames {
+			o.halfClosePolicies[name] = policy
+		}
+	}
+}
+
+func (o *handlerOptions) halfClosePolicy(fullMethodName string) HalfClosePolicy {
+	if policy, ok := o.halfClosePolicies[fullMethodName]; ok {
+		return policy
+	}
+
+	return o.halfClosePolicies[""]
+}
+
+// fullCloseTimer starts the full close timer after the client half-close, nil channel is returned if there's no timeout.
+func (policy HalfClosePolicy) fullCloseTimer() (<-chan time.Time, func()) {
+	if policy.FullCloseTimeout <= 0 {
+		return nil, func() {}
+	}
+
+	timer := time.NewTimer(policy.FullCloseTimeout)
+
+	return timer.C, func() { timer.Stop() }
+}
+
+func (policy HalfClosePolicy) fullCloseError() error {
+	return newError(ErrHalfCloseTimeout, "upstream didn't finish the call within %s after client half-close", policy.FullCloseTimeout)
+}
+
+// forwardServerToClientsQueued forwards client frames to each destination independently, half-closing each destination
+// as soon as it accepted the last frame.
+func (s *handler) forwardServerToClientsQueued(forwarders *forwarders, src grpc.ServerStream, destinations []backendConnection, limiter *directionLimiter) chan error {
+	ret := make(chan error, 1)
+
+	forwarders.goUpstream(func() {
+		var (
+			wg   sync.WaitGroup
+			live int32
+			eof  bool
+		)
+
+		queues := make([]chan *Frame, len(destinations))
+
+		for i := range destinations {
+			dst := &destinations[i]
+
+			if dst.clientStream == nil || dst.connError != nil {
+				continue
+			}
+
+			queues[i] = make(chan *Frame, halfCloseQueueSize)
+			live++
+
+			wg.Add(1)
+
+			go func(queue <-chan *Frame) {
+				defer wg.Done()
+
+				failed := false
+
+				for f := range queue {
+					if failed {
+						continue
+					}
+
+					if err := dst.clientStream.SendMsg(f); err != nil {
+						failed = true
+
+						atomic.AddInt32(&live, -1)
+					}
+				}
+
+				// the client might have aborted the stream, half-close only on io.EOF
+				if eof && !failed {
+					dst.clientStream.CloseSend() //nolint: errcheck
+				}
+			}(queues[i])
+		}
+
+		closeQueues := func() {
+			for _, queue := range queues {
+				if queue != nil {
+					close(queue)
+				}
+			}
+		}
+
+		for {
+			f := &Frame{}
+
+			if err := src.RecvMsg(f); err != nil {
+				if errors.Is(err, io.EOF) {
+					eof = true
+
+					closeQueues()
+					wg.Wait()
+				} else {
+					closeQueues()
+				}
+
+				ret <- err
+
+				return
+			}
+
+			if limiter.message() != nil {
+				// error is delivered via limits
+				closeQueues()
+
+				return
+			}
+
+			if atomic.LoadInt32(&live) == 0 {
+				closeQueues()
+
+				ret <- io.EOF
+
+				return
+			}
+
+			for _, queue := range queues {
+				if queue != nil {
+					queue <- f
+				}
+			}
+		}
+	})
+
+	return ret
+}
@@ -0,0 +1,220 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/noncepad/grpc-proxy/proxy"
+	pb "github.com/noncepad/grpc-proxy/testservice"
+)
+
+const backendTagMdKey = "test-backend-tag"
+
+// taggedBackend tags upstream calls with the backend tag.
+type taggedBackend struct {
+	proxy.Backend
+
+	tag string
+}
+
+func (b *taggedBackend) String() string {
+	return b.tag
+}
+
+func (b *taggedBackend) GetConnection(ctx context.Context, fullMethodName string) (context.Context, *grpc.ClientConn, error) {
+	outCtx, conn, err := b.Backend.GetConnection(ctx, fullMethodName)
+
+	return metadata.AppendToOutgoingContext(outCtx, backendTagMdKey, b.tag), conn, err
+}
+
+// halfCloseService records the order of events observed by the PingStream upstreams.
+//
+// Upstream tagged "slow" receives messages slowly, stalling service never finishes the stream after the half-close.
+type halfCloseService struct {
+	assertingService
+
+	events   []string
+	canceled chan error
+	stall    bool
+	mu       sync.Mutex
+}
+
+func (s *halfCloseService) record(event string) {
+	s.mu.Lock()
+	s.events = append(s.events, event)
+	s.mu.Unlock()
+}
+
+func (s *halfCloseService) PingStream(stream pb.TestService_PingStreamServer) error {
+	md, _ := metadata.FromIncomingContext(stream.Context())
+	tag := md.Get(backendTagMdKey)[0]
+
+	for counter := int32(0); ; counter++ {
+		if tag == "slow" {
+			time.Sleep(100 * time.Millisecond)
+		}
+
+		ping, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			s.record(tag + ":eof")
+
+			break
+		}
+
+		if err != nil {
+			return err
+		}
+
+		s.record(fmt.Sprintf("%s:%d", tag, counter))
+
+		if err = stream.Send(&pb.PingResponse{Value: ping.Value[:1], Counter: counter}); err != nil {
+			return err
+		}
+	}
+
+	if s.stall {
+		<-stream.Context().Done()
+
+		s.canceled <- stream.Context().Err()
+
+		return stream.Context().Err()
+	}
+
+	return nil
+}
+
+func (s *halfCloseService) eventIndex(event string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, e := range s.events {
+		if e == event {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func runHalfCloseStream(t *testing.T, client pb.TestServiceClient, count int) error {
+	t.Helper()
+
+	stream, err := client.PingStream(testContext(t))
+	require.NoError(t, err)
+
+	for i := 0; i < count; i++ {
+		// large messages to exceed the flow control window, so that slow upstream blocks the sender
+		require.NoError(t, stream.Send(&pb.PingRequest{Value: strings.Repeat(fmt.Sprint(i), 256<<10)}))
+	}
+
+	require.NoError(t, stream.CloseSend())
+
+	for {
+		if _, err = stream.Recv(); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+
+			return err
+		}
+	}
+}
+
+func one2manyTaggedDirector(backend proxy.Backend) proxy.StreamDirector {
+	return func(ctx context.Context, fullMethodName string) (proxy.Mode, []proxy.Backend, error) {
+		return proxy.One2Many, []proxy.Backend{
+			&taggedBackend{Backend: backend, tag: "fast"},
+			&taggedBackend{Backend: backend, tag: "slow"},
+		}, nil
+	}
+}
+
+func TestHalfCloseOrdering(t *testing.T) {
+	const count = 5
+
+	for _, tc := range []struct {
+		name          string
+		mode          proxy.HalfCloseMode
+		fastEOFBefore bool
+	}{
+		{
+			name: "after flush",
+			mode: proxy.HalfCloseAfterFlush,
+		},
+		{
+			name:          "immediate",
+			mode:          proxy.HalfCloseImmediate,
+			fastEOFBefore: true,
+		},
+	} {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			service := &halfCloseService{}
+
+			h := newTestHarnessWithService(t, service, one2manyTaggedDirector,
+				proxy.WithStreamedDetector(func(fullMethodName string) bool { return fullMethodName == "/talos.testproto.TestService/PingStream" }),
+				proxy.WithHalfClose(proxy.HalfClosePolicy{Mode: tc.mode}))
+
+			require.NoError(t, runHalfCloseStream(t, h.client, count))
+
+			// each upstream receives all the frames in order before the half-close
+			for _, tag := range []string{"fast", "slow"} {
+				for i := 0; i < count-1; i++ {
+					assert.Less(t, service.eventIndex(fmt.Sprintf("%s:%d", tag, i)), service.eventIndex(fmt.Sprintf("%s:%d", tag, i+1)))
+				}
+
+				assert.Less(t, service.eventIndex(fmt.Sprintf("%s:%d", tag, count-1)), service.eventIndex(tag+":eof"))
+			}
+
+			// in immediate mode the fast upstream doesn't wait for the slow one
+			//
+			// in after flush mode the order depends on the flow control window, as the frames are accepted by
+			// the slow upstream before it reads them
+			if tc.fastEOFBefore {
+				assert.Less(t, service.eventIndex("fast:eof"), service.eventIndex("slow:0"))
+			}
+		})
+	}
+}
+
+func TestHalfCloseFullCloseTimeout(t *testing.T) {
+	service := &halfCloseService{stall: true, canceled: make(chan error, 1)}
+
+	h := newTestHarnessWithService(t, service, func(backend proxy.Backend) proxy.StreamDirector {
+		return one2oneDirector(&taggedBackend{Backend: backend, tag: "fast"})
+	}, proxy.WithHalfClose(proxy.HalfClosePolicy{FullCloseTimeout: 100 * time.Millisecond}, "/talos.testproto.TestService/PingStream"))
+
+	err := runHalfCloseStream(t, h.client, 3)
+	require.Error(t, err)
+	assert.Equal(t, codes.DeadlineExceeded, status.Code(err))
+
+	proxyErr, ok := proxy.FromError(err)
+	require.True(t, ok)
+	assert.True(t, errors.Is(proxyErr, proxy.ErrHalfCloseTimeout))
+
+	select {
+	case upstreamErr := <-service.canceled:
+		assert.ErrorIs(t, upstreamErr, context.Canceled)
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "upstream call was not canceled")
+	}
+
+	assert.Less(t, service.eventIndex("fast:2"), service.eventIndex("fast:eof"))
+}
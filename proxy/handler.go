@@ -6,10 +6,15 @@ package proxy
 
 import (
 	"context"
+	"time"
 
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/stats"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+
+	"github.com/noncepad/grpc-proxy/proxy/failpoint"
 )
 
 var clientStreamDescForProxying = &grpc.StreamDesc{
@@ -18,10 +23,83 @@ var clientStreamDescForProxying = &grpc.StreamDesc{
 }
 
 type handlerOptions struct {
-	streamedMethods  map[string]struct{}
-	streamedDetector StreamedDetectorFunc
-	serviceName      string
-	methodNames      []string
+	streamedMethods            map[string]struct{}
+	streamedDetector           StreamedDetectorFunc
+	methodAllowlist            map[string]struct{}
+	responseVerifierMethods    map[string]struct{}
+	responseBufferLimit        int
+	requestTypeDenylist        map[protoreflect.FullName]struct{}
+	descriptorFiles            *protoregistry.Files
+	serviceName                string
+	methodNames                []string
+	concurrencyGates           map[string]*concurrencyGate
+	jwtValidator               *JWTValidator
+	upstreamSigner             UpstreamSigner
+	upstreamSignedMethods      map[string]struct{}
+	responseVerifier           ResponseVerifier
+	streamLimits               map[string][2]StreamLimits
+	idempotencyCache           *idempotencyCache
+	deltaMethods               map[string]struct{}
+	bandwidthStats             *BandwidthStats
+	detachedMethods            map[string]time.Duration
+	halfClosePolicies          map[string]HalfClosePolicy
+	topology                   *Topology
+	waitForReady               map[string]bool
+	fallbackHandlers           map[string]FallbackHandler
+	chunkingMethods            map[string]int
+	chunkingMaxMessageSize     int
+	streamRegistry             *StreamRegistry
+	noBackendsPolicy           NoBackendsPolicy
+	statsHandler               stats.Handler
+	shadowPolicies             map[string]ShadowPolicy
+	loopDetection              *loopDetection
+	annotations                *annotationOptions
+	anyEnvelopeMethods         map[string]struct{}
+	upstreamUnaryInterceptors  []grpc.UnaryClientInterceptor
+	upstreamStreamInterceptors []grpc.StreamClientInterceptor
+	newStreamHook              NewStreamHook
+	failoverPolicies           map[string]FailoverPolicy
+	descriptorResolver         *DescriptorResolver
+	warnings                   *warningOptions
+	identity                   string
+	windowStats                *WindowStats
+	dedupPolicies              map[string]DedupPolicy
+	progressDeadlines          map[string]ProgressDeadlinePolicy
+	allFailedBuilders          map[string]AllFailedBuilder
+	unaryFastPathMethods       map[string]struct{}
+	reflectionRewriting        bool
+	wellKnownPolicies          map[string]WellKnownPolicy
+	events                     EventSink
+	messageEvents              *MessageEventsPolicy
+	rateLimits                 map[string]*RateLimitPolicy
+	cost                       *CostPolicy
+	serverStreamInterceptors   []grpc.StreamServerInterceptor
+	classifier                 Classifier
+	classifierMethods          map[string]struct{}
+	routeHints                 *RouteHintsPolicy
+	metadataLimits             *MetadataLimitsPolicy
+	frameWindows               map[string]FrameWindowPolicy
+	upstreamStreamBudget       *UpstreamStreamBudget
+	clientAuth                 *ClientAuthPolicy
+	schemaVersions             *SchemaVersions
+	earlyBuffers               map[string]EarlyBufferPolicy
+	lazyDirector               LazyDirector
+	optionErrors               []error
+	backpressure               map[string]BackpressurePolicy
+	requestLogging             map[string]RequestLogPolicy
+	retryBudget                *RetryBudget
+	binaryMetadataKeys         map[string]struct{}
+	aggregationPolicies        map[string]*AggregationPolicy
+	lroMethods                 map[string]*LROPolicy
+	streamChecksums            map[string]*StreamChecksumPolicy
+	sloTracker                 *SLOTracker
+	backendVersions            *VersionRegistry
+	responseOrders             map[string]ResponseOrder
+	payloadSizeClasses         map[string]PayloadSizeClass
+	decisionLog                *DecisionLog
+	metadataAllowlists         map[string]*metadataAllowlist
+	requestPeekMethods         map[string]struct{}
+	requestPeek                bool
 }
 
 type handler struct {
@@ -36,61 +114,477 @@ type backendConnection struct {
 	connError   error
 
 	clientStream grpc.ClientStream
+
+	statsLeg *statsLeg
 }
 
 // handler is where the real magic of proxying happens.
 // It is invoked like any gRPC server stream and uses the gRPC server framing to get and receive bytes from the wire,
 // forwarding it to a ClientStream established against the relevant ClientConn.
-func (s *handler) handler(srv interface{}, serverStream grpc.ServerStream) error {
+func (s *handler) handler(srv interface{}, serverStream grpc.ServerStream) (err error) {
 	// little bit of gRPC internals never hurt anyone
 	fullMethodName, ok := grpc.MethodFromServerStream(serverStream)
 	if !ok {
-		return status.Errorf(codes.Internal, "lowLevelServerStream doesn't exist in the context")
+		return newError(ErrInternal, "lowLevelServerStream doesn't exist in the context")
+	}
+
+	if s.options.events != nil {
+		start := time.Now()
+
+		defer func() { s.options.emitCallFinished(fullMethodName, start, err) }()
+	}
+
+	if s.options.sloTracker != nil {
+		start := time.Now()
+
+		defer func() { s.options.sloTracker.record(fullMethodName, time.Since(start), err) }()
+	}
+
+	if s.options.statsHandler != nil {
+		statsStream := newStatsServerStream(s.options.statsHandler, serverStream, fullMethodName)
+		serverStream = statsStream
+
+		defer func() { statsStream.end(err) }()
+	}
+
+	if len(s.options.serverStreamInterceptors) > 0 {
+		return s.options.intercept(srv, serverStream, fullMethodName, func(_ interface{}, serverStream grpc.ServerStream) error {
+			return s.serve(fullMethodName, serverStream)
+		})
+	}
+
+	return s.serve(fullMethodName, serverStream)
+}
+
+// serve handles the call after the server interceptors.
+func (s *handler) serve(fullMethodName string, serverStream grpc.ServerStream) (err error) {
+	if s.options.cost != nil {
+		meter := newCostMeter(s.options.cost, serverStream, fullMethodName)
+		serverStream = meter
+
+		defer func() { meter.finish(err) }()
+	}
+
+	if s.options.metadataLimits != nil {
+		if serverStream, err = s.options.limitMetadata(serverStream, fullMethodName); err != nil {
+			return err
+		}
+	}
+
+	if handled, err := s.options.handleWellKnown(serverStream, fullMethodName); handled {
+		return err
+	}
+
+	if err := s.options.checkAllowed(fullMethodName); err != nil {
+		return err
+	}
+
+	if s.options.loopDetection != nil {
+		if err := s.options.loopDetection.check(serverStream.Context(), fullMethodName); err != nil {
+			return err
+		}
+	}
+
+	if s.options.clientAuth != nil {
+		if serverStream, err = s.options.authenticateClient(serverStream); err != nil {
+			return err
+		}
+	}
+
+	if s.options.jwtValidator != nil {
+		ctx, err := s.options.jwtValidator.Authenticate(serverStream.Context())
+		if err != nil {
+			return err
+		}
+
+		serverStream = &contextServerStream{ServerStream: serverStream, ctx: ctx}
+	}
+
+	if err := s.options.checkRateLimit(serverStream.Context(), fullMethodName); err != nil {
+		return err
+	}
+
+	if s.options.idempotencyCache != nil {
+		if key := s.options.idempotencyCache.key(serverStream.Context(), fullMethodName); key != "" {
+			return s.options.idempotencyCache.doRequest(key, serverStream, func(serverStream grpc.ServerStream) error {
+				return s.proxy(fullMethodName, serverStream)
+			})
+		}
+	}
+
+	return s.proxy(fullMethodName, serverStream)
+}
+
+// proxy selects the backends and proxies the call.
+func (s *handler) proxy(fullMethodName string, serverStream grpc.ServerStream) (err error) {
+	if gate, ok := s.options.concurrencyGates[fullMethodName]; ok {
+		release, err := gate.acquire(serverStream.Context(), fullMethodName)
+		if err != nil {
+			return err
+		}
+
+		defer release()
+	}
+
+	envelope := s.options.anyEnvelopeEnabled(fullMethodName)
+	if envelope {
+		serverStream = s.options.newAnyUnwrappingServerStream(serverStream, fullMethodName)
+	}
+
+	if s.options.peekRequest(fullMethodName) {
+		peeked, err := peekServerStream(serverStream)
+		if err != nil {
+			return err
+		}
+
+		serverStream = peeked
+	}
+
+	if s.options.classified(fullMethodName) {
+		if serverStream, err = s.options.classify(serverStream, fullMethodName); err != nil {
+			return err
+		}
+	}
+
+	if s.options.warnings != nil {
+		warnings := newWarningsServerStream(s.options.warnings, serverStream, fullMethodName)
+		serverStream = warnings
+
+		defer warnings.finish()
+	}
+
+	var annotating *annotatingServerStream
+
+	if s.options.annotations != nil && annotationsNegotiated(serverStream.Context()) {
+		annotating = newAnnotatingServerStream(serverStream)
+		serverStream = annotating
+
+		defer annotating.finish()
 	}
 
-	mode, backends, err := s.director(serverStream.Context(), fullMethodName)
+	if policy, ok := s.options.frameWindows[fullMethodName]; ok {
+		windowing := newWindowingServerStream(serverStream, policy, fullMethodName)
+		serverStream = windowing
+
+		defer func() {
+			if flushErr := windowing.finish(); err == nil {
+				err = flushErr
+			}
+		}()
+	}
+
+	if s.options.lazyDirector != nil {
+		iterator, ok, err := s.options.lazyDirector(serverStream.Context(), fullMethodName)
+		if err != nil {
+			return err
+		}
+
+		if ok {
+			return s.proxyLazy(fullMethodName, serverStream, iterator)
+		}
+	}
+
+	directorCtx, allFailed := directorContext(serverStream.Context())
+
+	var early *earlyBufferingServerStream
+
+	if policy, ok := s.options.earlyBufferPolicy(fullMethodName); ok {
+		var cancel context.CancelFunc
+
+		directorCtx, cancel = context.WithCancel(directorCtx)
+		defer cancel()
+
+		early = startEarlyBuffering(serverStream, policy, fullMethodName, cancel)
+		serverStream = early
+	}
+
+	directStart := time.Now()
+
+	mode, backends, err := s.direct(directorCtx, fullMethodName)
+
+	if early != nil {
+		if overflowErr := early.stop(); overflowErr != nil {
+			return overflowErr
+		}
+	}
+
+	if err == nil {
+		mode, backends, err = s.options.directFallback(serverStream.Context(), fullMethodName, mode, backends)
+	}
+
+	s.options.decisionLog.record(serverStream.Context(), fullMethodName, time.Since(directStart), mode, backends, err)
+
 	if err != nil {
 		return err
 	}
 
+	s.options.emitCallStarted(fullMethodName, mode, backends)
+
+	if s.options.cost != nil {
+		observeCost(serverStream.Context(), backends)
+	}
+
+	if len(backends) == 0 {
+		err = s.options.noBackendsError(fullMethodName)
+
+		if fallback := s.options.fallbackHandler(fullMethodName); fallback != nil {
+			return fallback(serverStream, fullMethodName, err)
+		}
+
+		return err
+	}
+
+	if envelope {
+		if backends, err = s.options.anyEnvelopeBackends(fullMethodName, backends); err != nil {
+			return err
+		}
+	}
+
 	backendConnections := make([]backendConnection, len(backends))
 
-	clientCtx, clientCancel := context.WithCancel(serverStream.Context())
+	clientCtx, clientCancel := s.options.upstreamContext(serverStream.Context(), fullMethodName)
 	defer clientCancel()
 
-	for i := range backends {
-		backendConnections[i].backend = backends[i]
+	clientCtx, deadline := s.options.progressDeadline(clientCtx, fullMethodName)
+	defer deadline.stop()
+
+	legCtxs, unregister := s.options.streamRegistry.legContexts(clientCtx, fullMethodName, backends)
+	defer unregister()
+
+	if s.options.statsHandler != nil {
+		defer func() { endStatsLegs(backendConnections, err) }()
+	}
+
+	unary := s.options.unaryFastPath(fullMethodName, mode, backends)
+
+	connect := func(i int) backendConnection {
+		conn := s.connect(legCtxs[i], serverStream.Context(), fullMethodName, backends[i], unary)
+		s.options.emitBackendConnected(fullMethodName, &conn)
+
+		return conn
+	}
 
-		// We require that the backend's returned context inherits from the serverStream.Context().
-		var outgoingCtx context.Context
-		outgoingCtx, backendConnections[i].backendConn, backendConnections[i].connError = backends[i].GetConnection(clientCtx, fullMethodName)
+	s.options.retryBudget.deposit()
 
-		if backendConnections[i].connError != nil {
-			continue
+	if policy, ok := s.options.failoverPolicy(fullMethodName); ok && mode == One2One && len(backends) > 1 {
+		backendConnections = []backendConnection{s.failover(fullMethodName, policy, s.options.retryBudget, len(backends), connect)}
+	} else {
+		for i := range backends {
+			backendConnections[i] = connect(i)
 		}
+	}
+
+	deadline.wrap(backendConnections)
+
+	if mode == One2One && len(backendConnections) == 1 && backendConnections[0].connError == nil {
+		serverCtx := serverStream.Context()
+
+		backendConnections[0].clientStream = s.options.shadow(clientCtx, fullMethodName, backendConnections[0].backend, backendConnections[0].clientStream,
+			func(ctx context.Context, canary Backend) backendConnection {
+				return s.connect(ctx, serverCtx, fullMethodName, canary, false)
+			})
+	}
+
+	if envelope && mode == One2One && len(backendConnections) == 1 && backendConnections[0].connError == nil {
+		// one2many responses are wrapped by the backend on AppendInfo
+		backendConnections[0].clientStream = backendConnections[0].backend.(*anyEnvelopeBackend).wrapClientStream(backendConnections[0].clientStream) //nolint:forcetypeassert
+	}
 
-		backendConnections[i].clientStream, backendConnections[i].connError = grpc.NewClientStream(outgoingCtx, clientStreamDescForProxying,
-			backendConnections[i].backendConn, fullMethodName)
+	if annotating != nil && mode == One2Many && s.options.annotations.progressInterval > 0 &&
+		s.options.streamedDetector != nil && s.options.streamedDetector(fullMethodName) {
+		progress := startProgress(annotating, s.options.annotations.progressInterval, backendConnections)
+		defer progress.finish()
+	}
 
-		if backendConnections[i].connError != nil {
-			continue
+	if fallback := s.options.fallbackHandler(fullMethodName); fallback != nil {
+		if downErr := backendsDown(fullMethodName, backendConnections); downErr != nil {
+			return fallback(serverStream, fullMethodName, downErr)
 		}
 	}
 
+	serverStream, finishChecksum := s.options.wrapStreamChecksum(serverStream, fullMethodName)
+	defer finishChecksum()
+
+	if s.options.deltaRequested(serverStream.Context(), fullMethodName) {
+		if err = serverStream.SetHeader(metadata.Pairs(DeltaEncodingMetadataKey, "1")); err != nil {
+			return err
+		}
+
+		serverStream = &deltaServerStream{ServerStream: serverStream}
+	}
+
+	if s.options.requestLogging != nil {
+		serverStream = s.options.wrapRequestLogging(serverStream, fullMethodName)
+	}
+
+	if s.options.bandwidthStats != nil {
+		serverStream = s.options.bandwidthStats.wrapServerStream(serverStream, fullMethodName)
+	}
+
+	if s.options.windowStats != nil {
+		serverStream = s.options.windowStats.wrapServerStream(serverStream, fullMethodName)
+	}
+
+	limits := s.options.newStreamLimits(fullMethodName)
+	defer limits.stop()
+
+	forwarders := newForwarders(clientCtx, serverStream, clientCancel)
+	defer forwarders.finish()
+
+	serverStream = forwarders.downstream
+
+	if !unary {
+		serverStream = s.options.wrapBackpressure(fullMethodName, serverStream, backendConnections)
+	}
+
 	switch mode {
 	case One2One:
 		if len(backendConnections) != 1 {
-			return status.Errorf(codes.Internal, "one2one proxying should have exactly one connection (got %d)", len(backendConnections))
+			return newError(ErrInternal, "one2one proxying should have exactly one connection (got %d)", len(backendConnections))
 		}
 
-		return s.handlerOne2One(serverStream, backendConnections)
-	case One2Many:
-		if len(backendConnections) == 0 {
-			return status.Errorf(codes.Unavailable, "no backend connections for proxying")
+		if unary {
+			return s.handlerUnary(fullMethodName, serverStream, &backendConnections[0])
 		}
 
-		return s.handlerOne2Many(fullMethodName, serverStream, backendConnections)
+		return s.handlerOne2One(fullMethodName, serverStream, backendConnections, limits, forwarders)
+	case One2Many:
+		return s.handlerOne2Many(fullMethodName, serverStream, backendConnections, limits, forwarders, s.options.allFailedBuilder(fullMethodName, allFailed))
 	default:
-		return status.Errorf(codes.Internal, "unsupported proxy mode")
+		return newError(ErrInternal, "unsupported proxy mode")
+	}
+}
+
+// connect establishes the upstream stream to the backend, unary stream is established for the unary fast path.
+func (s *handler) connect(legCtx, serverCtx context.Context, fullMethodName string, backend Backend, unary bool) (conn backendConnection) {
+	conn.backend = backend
+
+	// We require that the backend's returned context inherits from the serverStream.Context().
+	var outgoingCtx context.Context
+	outgoingCtx, conn.backendConn, conn.connError = backend.GetConnection(legCtx, fullMethodName)
+
+	if conn.connError == nil {
+		conn.connError = evalFailpoint(failpoint.Dial, backend.String(), fullMethodName)
+	}
+
+	if conn.connError != nil {
+		return conn
+	}
+
+	md := editOutgoingMetadata(outgoingCtx)
+
+	s.options.allowlistOutgoingMetadata(fullMethodName, backend, &md)
+	applyMetadataDelta(&md, backend, fullMethodName)
+	s.options.normalizeBinaryMetadata(&md)
+
+	if s.options.loopDetection != nil {
+		s.options.loopDetection.outgoingMetadata(serverCtx, &md)
+	}
+
+	s.options.identityOutgoingMetadata(serverCtx, &md)
+
+	if !unary {
+		s.options.backpressureOutgoingMetadata(fullMethodName, &md)
+	}
+
+	outgoingCtx = md.context()
+
+	var (
+		upstreamMethodName string
+		hookOptions        []grpc.CallOption
+	)
+
+	upstreamMethodName, hookOptions, conn.connError = s.options.beforeNewStream(outgoingCtx, backend, fullMethodName)
+	if conn.connError != nil {
+		return conn
+	}
+
+	maxChunkSize, chunking := s.options.chunkingMethods[fullMethodName]
+	if chunking {
+		outgoingCtx = chunkingOutgoingContext(outgoingCtx, maxChunkSize)
+	}
+
+	if s.options.statsHandler != nil {
+		conn.statsLeg = newStatsLeg(outgoingCtx, s.options.statsHandler, upstreamMethodName)
+		outgoingCtx = conn.statsLeg.ctx
+	}
+
+	if s.options.signed(fullMethodName) {
+		outgoingCtx, conn.connError = s.signUpstream(outgoingCtx, backend, upstreamMethodName)
+
+		if conn.connError != nil {
+			return conn
+		}
+	}
+
+	var releaseBudget func()
+
+	if s.options.upstreamStreamBudget != nil {
+		if releaseBudget, conn.connError = s.options.upstreamStreamBudget.acquire(outgoingCtx, conn.backendConn, backend); conn.connError != nil {
+			return conn
+		}
+	}
+
+	callOptions := append(s.options.upstreamCallOptions(backend, fullMethodName), hookOptions...)
+
+	if unary {
+		conn.clientStream = newUnaryClientStream(outgoingCtx, conn.backendConn, upstreamMethodName, s.options.upstreamUnaryInterceptors, callOptions)
+	} else {
+		conn.clientStream, conn.connError = s.options.newUpstreamStream(outgoingCtx, conn.backendConn, upstreamMethodName, callOptions...)
 	}
+
+	if conn.connError != nil {
+		if releaseBudget != nil {
+			releaseBudget()
+		}
+
+		conn.connError = translateProtocolError(backend, conn.connError)
+
+		return conn
+	}
+
+	conn.clientStream = &protocolErrorClientStream{ClientStream: conn.clientStream, backend: backend}
+
+	if releaseBudget != nil {
+		conn.clientStream = newBudgetedClientStream(outgoingCtx, conn.clientStream, releaseBudget)
+	}
+
+	conn.clientStream = failpointClientStream(conn.clientStream, backend, fullMethodName)
+	conn.clientStream = s.options.wrapUpstreamChecksum(conn.clientStream, backend, fullMethodName)
+
+	if s.options.bandwidthStats != nil {
+		conn.clientStream = s.options.bandwidthStats.wrapClientStream(conn.clientStream, backend, fullMethodName)
+	}
+
+	if s.options.windowStats != nil {
+		conn.clientStream = s.options.windowStats.wrapClientStream(conn.clientStream, backend)
+	}
+
+	if conn.statsLeg != nil {
+		conn.clientStream = conn.statsLeg.wrap(conn.clientStream)
+	}
+
+	conn.clientStream = s.options.wrapMessageEvents(outgoingCtx, conn.clientStream, backend, fullMethodName)
+	conn.clientStream = s.options.wrapRouteHints(serverCtx, conn.clientStream, fullMethodName)
+	conn.clientStream = s.options.wrapLRO(conn.clientStream, backend, fullMethodName)
+	conn.clientStream = s.options.wrapBackendVersions(conn.clientStream, backend)
+
+	if s.options.reflectionRewriting && fullMethodName == reflectionMethod {
+		conn.clientStream = &reflectionRewritingStream{ClientStream: conn.clientStream, renamed: map[string]string{}}
+	}
+
+	if chunking {
+		conn.clientStream = &chunkingClientStream{
+			ClientStream: conn.clientStream,
+			reassembler: chunkReassembler{
+				capacity:       s.options.payloadSizeClass(fullMethodName).Capacity(),
+				maxMessageSize: s.options.chunkingMaxMessageSize,
+			},
+			maxChunkSize: maxChunkSize,
+		}
+	}
+
+	conn.clientStream = s.options.wrapMetadataAllowlist(conn.clientStream, backend, fullMethodName)
+
+	return conn
 }
@@ -8,25 +8,38 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"time"
 
 	"github.com/hashicorp/go-multierror"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
 
-func (s *handler) handlerOne2Many(fullMethodName string, serverStream grpc.ServerStream, backendConnections []backendConnection) error {
+func (s *handler) handlerOne2Many(fullMethodName string, serverStream grpc.ServerStream, backendConnections []backendConnection,
+	limits *streamLimits, forwarders *forwarders, allFailed AllFailedBuilder,
+) error {
 	// wrap the stream for safe concurrent access
 	serverStream = &ServerStreamWrapper{ServerStream: serverStream}
 
-	s2cErrChan := s.forwardServerToClientsMulti(serverStream, backendConnections)
+	halfClose := s.options.halfClosePolicy(fullMethodName)
 
-	var c2sErrChan chan error
+	var s2cErrChan chan error
+
+	if halfClose.Mode == HalfCloseImmediate {
+		s2cErrChan = s.forwardServerToClientsQueued(forwarders, serverStream, backendConnections, limits.requestLimiter())
+	} else {
+		s2cErrChan = s.forwardServerToClientsMulti(forwarders, serverStream, backendConnections, limits.requestLimiter())
+	}
+
+	var (
+		c2sErrChan  chan error
+		fullCloseCh <-chan time.Time
+	)
 
 	if s.options.streamedDetector != nil && s.options.streamedDetector(fullMethodName) {
-		c2sErrChan = s.forwardClientsToServerMultiStreaming(backendConnections, serverStream)
+		c2sErrChan = s.forwardClientsToServerMultiStreaming(forwarders, fullMethodName, backendConnections, serverStream, limits.responseLimiter(), allFailed)
 	} else {
-		c2sErrChan = s.forwardClientsToServerMultiUnary(backendConnections, serverStream)
+		c2sErrChan = s.forwardClientsToServerMultiUnary(forwarders, fullMethodName, backendConnections, serverStream, allFailed)
 	}
 
 	for i := 0; i < 2; i++ {
@@ -35,16 +48,23 @@ func (s *handler) handlerOne2Many(fullMethodName string, serverStream grpc.Serve
 			if errors.Is(s2cErr, io.EOF) {
 				// this is the happy case where the sender has encountered io.EOF, and won't be sending anymore./
 				// the clientStream>serverStream may continue pumping though.
+				limits.requestLimiter().done()
+
 				for i := range backendConnections {
 					if backendConnections[i].clientStream != nil {
 						backendConnections[i].clientStream.CloseSend() //nolint: errcheck
 					}
 				}
+
+				var stopTimer func()
+
+				fullCloseCh, stopTimer = halfClose.fullCloseTimer()
+				defer stopTimer()
 			} else {
 				// however, we may have gotten a receive error (stream disconnected, a read error etc) in which case we need
 				// to cancel the clientStream to the backend, let all of its goroutines be freed up by the CancelFunc and
 				// exit with an error to the stack
-				return status.Errorf(codes.Internal, "failed proxying s2c: %v", s2cErr)
+				return s2cError(s2cErr)
 			}
 		case c2sErr := <-c2sErrChan:
 			// c2sErr will contain RPC error from client code. If not io.EOF return the RPC error as server stream error.
@@ -53,15 +73,19 @@ func (s *handler) handlerOne2Many(fullMethodName string, serverStream grpc.Serve
 			}
 
 			return nil
+		case limitErr := <-limits.errors():
+			return limitErr
+		case <-fullCloseCh:
+			return halfClose.fullCloseError()
 		}
 	}
 
-	return status.Errorf(codes.Internal, "gRPC proxying should never reach this stage.")
+	return newError(ErrInternal, "gRPC proxying should never reach this stage.")
 }
 
 // formatError tries to format error from upstream as message to the client.
-func (s *handler) formatError(streaming bool, src *backendConnection, backendErr error) ([]byte, error) {
-	payload, err := src.backend.BuildError(streaming, backendErr)
+func (s *handler) formatError(ctx context.Context, fullMethodName string, streaming bool, src *backendConnection, backendErr error) ([]byte, error) {
+	payload, err := buildError(ctx, src.backend, fullMethodName, streaming, backendErr)
 	if err != nil {
 		return nil, fmt.Errorf("error building error for %s: %w", src.backend, err)
 	}
@@ -77,8 +101,8 @@ func (s *handler) formatError(streaming bool, src *backendConnection, backendErr
 //
 // If sendError fails to deliver the error, error is returned.
 // If sendError successfully delivers the error, nil is returned.
-func (s *handler) sendError(src *backendConnection, dst grpc.ServerStream, backendErr error) error {
-	payload, err := s.formatError(true, src, backendErr)
+func (s *handler) sendError(fullMethodName string, src *backendConnection, dst grpc.ServerStream, backendErr error) error {
+	payload, err := s.formatError(dst.Context(), fullMethodName, true, src, backendErr)
 	if err != nil {
 		return err
 	}
@@ -100,30 +124,63 @@ func (s *handler) sendError(src *backendConnection, dst grpc.ServerStream, backe
 	return nil
 }
 
+// prioritizedPayload is a response of the backend to be merged in one:many unary call.
+type prioritizedPayload struct {
+	// failed is set instead of the payload for the backend errors formatted after all the backends are done
+	failed   *BackendError
+	src      *backendConnection
+	payload  []byte
+	priority int
+	// index is the position of the backend in the list returned by the director
+	index int
+	// isError is set if the payload is the formatted backend error
+	isError bool
+}
+
 // forwardClientsToServerMultiUnary handles one:many proxying, unary call version (merging results)
 //
 //nolint:gocognit
-func (s *handler) forwardClientsToServerMultiUnary(sources []backendConnection, dst grpc.ServerStream) chan error {
+func (s *handler) forwardClientsToServerMultiUnary(forwarders *forwarders, fullMethodName string, sources []backendConnection, dst grpc.ServerStream,
+	allFailed AllFailedBuilder,
+) chan error {
 	ret := make(chan error, 1)
 
-	payloadCh := make(chan []byte, len(sources))
+	payloadCh := make(chan prioritizedPayload, len(sources))
 	errCh := make(chan error, len(sources))
 
 	for i := 0; i < len(sources); i++ {
-		go func(src *backendConnection) {
-			errCh <- func() error {
-				if src.connError != nil {
-					payload, err := s.formatError(false, src, src.connError)
-					if err != nil {
-						return err
-					}
+		src, index := &sources[i], i
 
-					payloadCh <- payload
+		forwarders.goDownstream(func() {
+			priority := backendPriority(src.backend)
+
+			// fail delivers the backend error as the response
+			fail := func(backendErr error) error {
+				if allFailed != nil {
+					payloadCh <- prioritizedPayload{priority: priority, index: index, src: src, failed: &BackendError{Backend: src.backend, Err: backendErr}, isError: true}
 
 					return nil
 				}
 
+				payload, err := s.formatError(dst.Context(), fullMethodName, false, src, backendErr)
+				if err != nil {
+					return err
+				}
+
+				payloadCh <- prioritizedPayload{priority: priority, index: index, src: src, payload: payload, isError: true}
+
+				return nil
+			}
+
+			errCh <- func() error {
+				if src.connError != nil {
+					return fail(src.connError)
+				}
+
 				f := &Frame{}
+				buffer := s.options.newResponseBuffer(fullMethodName)
+
+				var pending [][]byte
 
 				for j := 0; ; j++ {
 					if err := src.clientStream.RecvMsg(f); err != nil {
@@ -133,19 +190,22 @@ func (s *handler) forwardClientsToServerMultiUnary(sources []backendConnection,
 							// will be nil.
 							dst.SetTrailer(src.clientStream.Trailer())
 
-							return nil
+							if buffer == nil {
+								return nil
+							}
+
+							err = buffer.verify(src.backend, fullMethodName, src.clientStream.Trailer())
 						}
 
-						var payload []byte
+						if err == nil {
+							for _, payload := range pending {
+								payloadCh <- prioritizedPayload{priority: priority, index: index, src: src, payload: payload}
+							}
 
-						payload, err = s.formatError(false, src, err)
-						if err != nil {
-							return err
+							return nil
 						}
 
-						payloadCh <- payload
-
-						return nil
+						return fail(err)
 					}
 
 					if j == 0 {
@@ -154,16 +214,7 @@ func (s *handler) forwardClientsToServerMultiUnary(sources []backendConnection,
 						// This is the only place to do it nicely.
 						md, err := src.clientStream.Header()
 						if err != nil {
-							var payload []byte
-
-							payload, err = s.formatError(false, src, err)
-							if err != nil {
-								return err
-							}
-
-							payloadCh <- payload
-
-							return nil
+							return fail(err)
 						}
 
 						if err := dst.SetHeader(md); err != nil {
@@ -171,20 +222,32 @@ func (s *handler) forwardClientsToServerMultiUnary(sources []backendConnection,
 						}
 					}
 
+					if buffer != nil {
+						if err := buffer.add(src.backend, f.payload); err != nil {
+							return fail(err)
+						}
+					}
+
 					var err error
 
-					f.payload, err = src.backend.AppendInfo(false, f.payload)
+					f.payload, err = appendInfo(dst.Context(), src.backend, fullMethodName, false, f.payload)
 					if err != nil {
 						return fmt.Errorf("error appending info for %s: %w", src.backend, err)
 					}
 
-					payloadCh <- f.payload
+					if buffer != nil {
+						pending = append(pending, f.payload)
+
+						continue
+					}
+
+					payloadCh <- prioritizedPayload{priority: priority, index: index, src: src, payload: f.payload}
 				}
 			}()
-		}(&sources[i])
+		})
 	}
 
-	go func() {
+	forwarders.goDownstream(func() {
 		var multiErr *multierror.Error
 
 		for range sources {
@@ -199,33 +262,115 @@ func (s *handler) forwardClientsToServerMultiUnary(sources []backendConnection,
 
 		close(payloadCh)
 
-		var merged []byte
-		for b := range payloadCh {
-			merged = append(merged, b...)
+		payloads := make([]prioritizedPayload, 0, len(payloadCh))
+		for p := range payloadCh {
+			payloads = append(payloads, p)
+		}
+
+		if allFailed != nil {
+			var err error
+
+			if payloads, err = s.formatFailed(dst.Context(), fullMethodName, allFailed, len(sources), payloads); err != nil {
+				ret <- err
+
+				return
+			}
+		}
+
+		s.options.sortPayloads(fullMethodName, payloads)
+
+		merged, err := s.options.mergeResponses(fullMethodName, payloads)
+		if err != nil {
+			ret <- err
+
+			return
 		}
 
 		ret <- dst.SendMsg(NewFrame(merged))
-	}()
+	})
 
 	return ret
 }
 
+// formatFailed replaces the backend errors with the all-failed response if all the backends failed,
+// or formats each backend error otherwise.
+func (s *handler) formatFailed(ctx context.Context, fullMethodName string, allFailed AllFailedBuilder, total int, payloads []prioritizedPayload) ([]prioritizedPayload, error) {
+	var errs []BackendError
+
+	for _, p := range payloads {
+		if p.failed != nil {
+			errs = append(errs, *p.failed)
+		}
+	}
+
+	if len(errs) == total {
+		payload, err := allFailed(fullMethodName, false, errs)
+		if err != nil {
+			return nil, err
+		}
+
+		return []prioritizedPayload{{payload: payload, isError: true}}, nil
+	}
+
+	for i := range payloads {
+		if payloads[i].failed == nil {
+			continue
+		}
+
+		payload, err := s.formatError(ctx, fullMethodName, false, payloads[i].src, payloads[i].failed.Err)
+		if err != nil {
+			return nil, err
+		}
+
+		payloads[i].payload, payloads[i].failed = payload, nil
+	}
+
+	return payloads, nil
+}
+
 // one:many proxying, streaming version (no merge).
 //
 //nolint:gocognit
-func (s *handler) forwardClientsToServerMultiStreaming(sources []backendConnection, dst grpc.ServerStream) chan error {
+func (s *handler) forwardClientsToServerMultiStreaming(forwarders *forwarders, fullMethodName string, sources []backendConnection, dst grpc.ServerStream,
+	limiter *directionLimiter, allFailed AllFailedBuilder,
+) chan error {
 	ret := make(chan error, 1)
 
 	errCh := make(chan error, len(sources))
 
+	delayed := newAllFailedStream(allFailed, fullMethodName, len(sources))
+
+	// fail delivers the backend error as the response, unless it is delayed
+	fail := func(src *backendConnection, backendErr error) error {
+		if delayed.fail(src, backendErr) {
+			return nil
+		}
+
+		return s.sendError(fullMethodName, src, dst, backendErr)
+	}
+
+	// succeed sends the delayed errors once any backend succeeds
+	succeed := func() error {
+		return delayed.succeed(func(src *backendConnection, backendErr error) error {
+			return s.sendError(fullMethodName, src, dst, backendErr)
+		})
+	}
+
+	dedup := s.options.newDedupWindow(fullMethodName)
+
 	for i := range sources {
-		go func(src *backendConnection) {
+		src := &sources[i]
+
+		forwarders.goDownstream(func() {
 			errCh <- func() error {
 				if src.connError != nil {
-					return s.sendError(src, dst, src.connError)
+					return fail(src, src.connError)
 				}
 
 				f := &Frame{}
+				buffer := s.options.newResponseBuffer(fullMethodName)
+
+				var pending [][]byte
 
 				for j := 0; ; j++ {
 					if err := src.clientStream.RecvMsg(f); err != nil {
@@ -235,10 +380,28 @@ func (s *handler) forwardClientsToServerMultiStreaming(sources []backendConnecti
 							// will be nil.
 							dst.SetTrailer(src.clientStream.Trailer())
 
+							if buffer == nil {
+								return succeed()
+							}
+
+							if err = buffer.verify(src.backend, fullMethodName, src.clientStream.Trailer()); err != nil {
+								return fail(src, err)
+							}
+
+							if err = succeed(); err != nil {
+								return err
+							}
+
+							for _, payload := range pending {
+								if err = dst.SendMsg(NewFrame(payload)); err != nil {
+									return fmt.Errorf("error sending back to server from %s: %w", src.backend, err)
+								}
+							}
+
 							return nil
 						}
 
-						return s.sendError(src, dst, err)
+						return fail(src, err)
 					}
 					if j == 0 {
 						// This is a bit of a hack, but client to server headers are only readable after first client msg is
@@ -246,43 +409,79 @@ func (s *handler) forwardClientsToServerMultiStreaming(sources []backendConnecti
 						// This is the only place to do it nicely.
 						md, err := src.clientStream.Header()
 						if err != nil {
-							return s.sendError(src, dst, err)
+							return fail(src, err)
 						}
 
 						dst.SetHeader(md) //nolint:errcheck // ignore errors, as we might try to set headers multiple times
 					}
 
+					if dedup.duplicate(f.payload) {
+						continue
+					}
+
+					if err := limiter.message(); err != nil {
+						// error is delivered via limits
+						return nil //nolint:nilerr
+					}
+
+					if buffer != nil {
+						if err := buffer.add(src.backend, f.payload); err != nil {
+							return fail(src, err)
+						}
+					}
+
 					var err error
-					f.payload, err = src.backend.AppendInfo(true, f.payload)
+					f.payload, err = appendInfo(dst.Context(), src.backend, fullMethodName, true, f.payload)
 					if err != nil {
 						return fmt.Errorf("error appending info for %s: %w", src.backend, err)
 					}
 
+					if buffer != nil {
+						pending = append(pending, f.payload)
+
+						continue
+					}
+
+					if err = succeed(); err != nil {
+						return err
+					}
+
 					if err = dst.SendMsg(f); err != nil {
 						return fmt.Errorf("error sending back to server from %s: %w", src.backend, err)
 					}
 				}
 			}()
-		}(&sources[i])
+		})
 	}
 
-	go func() {
+	forwarders.goDownstream(func() {
 		var multiErr *multierror.Error
 
 		for range sources {
 			multiErr = multierror.Append(multiErr, <-errCh)
 		}
 
-		ret <- multiErr.ErrorOrNil()
-	}()
+		if err := multiErr.ErrorOrNil(); err != nil {
+			ret <- err
+
+			return
+		}
+
+		ret <- delayed.finish(
+			func(src *backendConnection, backendErr error) error {
+				return s.sendError(fullMethodName, src, dst, backendErr)
+			},
+			func(payload []byte) error { return dst.SendMsg(NewFrame(payload)) },
+		)
+	})
 
 	return ret
 }
 
-func (s *handler) forwardServerToClientsMulti(src grpc.ServerStream, destinations []backendConnection) chan error {
+func (s *handler) forwardServerToClientsMulti(forwarders *forwarders, src grpc.ServerStream, destinations []backendConnection, limiter *directionLimiter) chan error {
 	ret := make(chan error, 1)
 
-	go func() {
+	forwarders.goUpstream(func() {
 		f := NewFrame(nil)
 
 		for {
@@ -292,6 +491,11 @@ func (s *handler) forwardServerToClientsMulti(src grpc.ServerStream, destination
 				return
 			}
 
+			if limiter.message() != nil {
+				// error is delivered via limits
+				return
+			}
+
 			errCh := make(chan error)
 
 			for i := range destinations {
@@ -320,7 +524,7 @@ func (s *handler) forwardServerToClientsMulti(src grpc.ServerStream, destination
 				return
 			}
 		}
-	}()
+	})
 
 	return ret
 }
@@ -336,6 +336,26 @@ func (s *ProxyOne2ManySuite) TestPingEmptyConnError() {
 	s.Require().Empty(expectedUpstreams)
 }
 
+func (s *ProxyOne2ManySuite) TestPingEmptyOrderedByPriority() {
+	targets := []string{"3", "-1", "2", "0", "4", "1"}
+
+	md := metadata.Pairs(clientMdKey, "true", "ordered", "true")
+	md.Set("targets", targets...)
+
+	ctx := metadata.NewOutgoingContext(s.ctx, md)
+
+	for i := 0; i < 20; i++ {
+		out, err := s.testClient.PingEmpty(ctx, &pb.Empty{})
+		require.NoError(s.T(), err, "PingEmpty should succeed without errors")
+
+		s.Require().Len(out.Response, len(targets))
+
+		for j, resp := range out.Response {
+			s.Require().Equal("server"+targets[j], resp.Metadata.Hostname)
+		}
+	}
+}
+
 func (s *ProxyOne2ManySuite) TestPingCarriesServerHeadersAndTrailers() {
 	headerMd := make(metadata.MD)
 	trailerMd := make(metadata.MD)
@@ -623,6 +643,12 @@ func (s *ProxyOne2ManySuite) SetupSuite() {
 			}
 		}
 
+		if _, exists := md["ordered"]; exists {
+			for i := range result {
+				result[i] = proxy.BackendWithPriority(result[i], i)
+			}
+		}
+
 		return proxy.One2Many, result, nil
 	}
 
@@ -631,11 +657,11 @@ func (s *ProxyOne2ManySuite) SetupSuite() {
 		grpc.UnknownServiceHandler(proxy.TransparentHandler(director)),
 	)
 	// Ping handler is handled as an explicit registration and not as a TransparentHandler.
-	proxy.RegisterService(s.proxy, director,
+	s.Require().NoError(proxy.RegisterService(s.proxy, director,
 		"talos.testproto.MultiService",
 		proxy.WithMethodNames("Ping", "PingStream", "PingStreamError"),
 		proxy.WithStreamedMethodNames("PingStream", "PingStreamError"),
-	)
+	))
 
 	// Start the serving loops.
 	for i := range s.servers {
@@ -7,23 +7,29 @@ package proxy
 import (
 	"errors"
 	"io"
+	"time"
 
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/status"
 )
 
-func (s *handler) handlerOne2One(serverStream grpc.ServerStream, backendConnections []backendConnection) error {
+func (s *handler) handlerOne2One(fullMethodName string, serverStream grpc.ServerStream, backendConnections []backendConnection,
+	limits *streamLimits, forwarders *forwarders,
+) error {
 	// case of proxying one to one:
 	if backendConnections[0].connError != nil {
-		return backendConnections[0].connError
+		return one2oneConnError(&backendConnections[0])
 	}
 
 	// Explicitly *do not close* s2cErrChan and c2sErrChan, otherwise the select below will not terminate.
 	// Channels do not have to be closed, it is just a control flow mechanism, see
 	// https://groups.google.com/forum/#!msg/golang-nuts/pZwdYRGxCIk/qpbHxRRPJdUJ
-	s2cErrChan := s.forwardServerToClient(serverStream, &backendConnections[0])
-	c2sErrChan := s.forwardClientToServer(&backendConnections[0], serverStream)
+	s2cErrChan := s.forwardServerToClient(forwarders, serverStream, &backendConnections[0], limits.requestLimiter())
+	c2sErrChan := s.forwardClientToServer(forwarders, fullMethodName, &backendConnections[0], serverStream, limits.responseLimiter())
+
+	halfClose := s.options.halfClosePolicy(fullMethodName)
+
+	var fullCloseCh <-chan time.Time
+
 	// We don't know which side is going to stop sending first, so we need a select between the two.
 	for i := 0; i < 2; i++ {
 		select {
@@ -31,13 +37,20 @@ func (s *handler) handlerOne2One(serverStream grpc.ServerStream, backendConnecti
 			if errors.Is(s2cErr, io.EOF) {
 				// this is the happy case where the sender has encountered io.EOF, and won't be sending anymore./
 				// the clientStream>serverStream may continue pumping though.
+				limits.requestLimiter().done()
+
 				//nolint: errcheck
 				backendConnections[0].clientStream.CloseSend()
+
+				var stopTimer func()
+
+				fullCloseCh, stopTimer = halfClose.fullCloseTimer()
+				defer stopTimer()
 			} else {
 				// however, we may have gotten a receive error (stream disconnected, a read error etc) in which case we need
 				// to cancel the clientStream to the backend, let all of its goroutines be freed up by the CancelFunc and
 				// exit with an error to the stack
-				return status.Errorf(codes.Internal, "failed proxying s2c: %v", s2cErr)
+				return s2cError(s2cErr)
 			}
 		case c2sErr := <-c2sErrChan:
 			// This happens when the clientStream has nothing else to offer (io.EOF), returned a gRPC error. In those two
@@ -50,20 +63,40 @@ func (s *handler) handlerOne2One(serverStream grpc.ServerStream, backendConnecti
 			}
 
 			return nil
+		case limitErr := <-limits.errors():
+			return limitErr
+		case <-fullCloseCh:
+			return halfClose.fullCloseError()
 		}
 	}
 
-	return status.Errorf(codes.Internal, "gRPC proxying should never reach this stage.")
+	return newError(ErrInternal, "gRPC proxying should never reach this stage.")
 }
 
-func (s *handler) forwardClientToServer(src *backendConnection, dst grpc.ServerStream) chan error {
+// one2oneConnError converts the error of establishing the upstream call of one2one call.
+func one2oneConnError(conn *backendConnection) error {
+	var proxyErr *Error
+
+	if errors.As(conn.connError, &proxyErr) {
+		return conn.connError
+	}
+
+	return &BackendDialError{Err: conn.connError, Backend: conn.backend.String()}
+}
+
+func (s *handler) forwardClientToServer(forwarders *forwarders, fullMethodName string, src *backendConnection, dst grpc.ServerStream, limiter *directionLimiter) chan error {
 	ret := make(chan error, 1)
 
-	go func() {
-		f := NewFrame(nil)
+	forwarders.goDownstream(func() {
+		f := &Frame{}
+		buffer := s.options.newResponseBuffer(fullMethodName)
 
 		for i := 0; ; i++ {
 			if err := src.clientStream.RecvMsg(f); err != nil {
+				if buffer != nil && errors.Is(err, io.EOF) {
+					err = s.flushVerified(fullMethodName, src, dst, buffer)
+				}
+
 				ret <- err // this can be io.EOF which is happy case
 
 				break
@@ -87,21 +120,53 @@ func (s *handler) forwardClientToServer(src *backendConnection, dst grpc.ServerS
 				}
 			}
 
+			if limiter.message() != nil {
+				// error is delivered via limits
+				break
+			}
+
+			if buffer != nil {
+				if err := buffer.add(src.backend, f.payload); err != nil {
+					ret <- err
+
+					break
+				}
+
+				continue
+			}
+
 			if err := dst.SendMsg(f); err != nil {
 				ret <- err
 
 				break
 			}
 		}
-	}()
+	})
 
 	return ret
 }
 
-func (s *handler) forwardServerToClient(src grpc.ServerStream, dst *backendConnection) chan error {
+// flushVerified verifies buffered responses and sends them to the client.
+//
+// On success, io.EOF is returned.
+func (s *handler) flushVerified(fullMethodName string, src *backendConnection, dst grpc.ServerStream, buffer *responseBuffer) error {
+	if err := buffer.verify(src.backend, fullMethodName, src.clientStream.Trailer()); err != nil {
+		return err
+	}
+
+	for _, payload := range buffer.payloads {
+		if err := dst.SendMsg(NewFrame(payload)); err != nil {
+			return err
+		}
+	}
+
+	return io.EOF
+}
+
+func (s *handler) forwardServerToClient(forwarders *forwarders, src grpc.ServerStream, dst *backendConnection, limiter *directionLimiter) chan error {
 	ret := make(chan error, 1)
 
-	go func() {
+	forwarders.goUpstream(func() {
 		f := NewFrame(nil)
 
 		for {
@@ -111,13 +176,18 @@ func (s *handler) forwardServerToClient(src grpc.ServerStream, dst *backendConne
 				break
 			}
 
+			if limiter.message() != nil {
+				// error is delivered via limits
+				break
+			}
+
 			if err := dst.clientStream.SendMsg(f); err != nil {
 				ret <- err
 
 				break
 			}
 		}
-	}()
+	})
 
 	return ret
 }
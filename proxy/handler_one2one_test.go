@@ -255,10 +255,10 @@ func (s *ProxyOne2OneSuite) SetupSuite() {
 	)
 
 	// Ping handler is handled as an explicit registration and not as a TransparentHandler.
-	proxy.RegisterService(s.proxy, director,
+	s.Require().NoError(proxy.RegisterService(s.proxy, director,
 		"talos.testproto.TestService",
 		proxy.WithMethodNames("Ping"),
-	)
+	))
 
 	// Start the serving loops.
 	s.T().Logf("starting grpc.Server at: %v", s.serverListener.Addr().String())
@@ -0,0 +1,151 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/noncepad/grpc-proxy/proxy"
+	pb "github.com/noncepad/grpc-proxy/testservice"
+)
+
+// testHarness runs a single upstream TestService server behind a transparent proxy.
+type testHarness struct {
+	server *grpc.Server
+	proxy  *grpc.Server
+
+	backendConn *grpc.ClientConn
+	clientConn  *grpc.ClientConn
+
+	backendAddr string
+
+	client pb.TestServiceClient
+}
+
+// newTestHarness starts upstream server and the proxy with the director built by directorFn and handler options.
+func newTestHarness(t *testing.T, directorFn func(backend proxy.Backend) proxy.StreamDirector, options ...proxy.Option) *testHarness {
+	t.Helper()
+
+	return newTestHarnessWithService(t, &assertingService{t: t}, directorFn, options...)
+}
+
+// newTestHarnessWithService is same as newTestHarness, but with custom upstream service implementation.
+func newTestHarnessWithService(t testing.TB, service pb.TestServiceServer, directorFn func(backend proxy.Backend) proxy.StreamDirector,
+	options ...proxy.Option,
+) *testHarness {
+	t.Helper()
+
+	return newTestHarnessWithServer(t, service, nil, directorFn, options...)
+}
+
+// newTestHarnessWithServer is same as newTestHarnessWithService, but with custom upstream server options.
+func newTestHarnessWithServer(t testing.TB, service pb.TestServiceServer, serverOptions []grpc.ServerOption,
+	directorFn func(backend proxy.Backend) proxy.StreamDirector, options ...proxy.Option,
+) *testHarness {
+	t.Helper()
+
+	h := &testHarness{}
+
+	serverListener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	proxyListener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	h.server = grpc.NewServer(serverOptions...)
+	pb.RegisterTestServiceServer(h.server, service)
+
+	go h.server.Serve(serverListener) //nolint: errcheck
+
+	h.backendAddr = serverListener.Addr().String()
+
+	h.backendConn, err = grpc.Dial(serverListener.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()), grpc.WithCodec(proxy.Codec())) //nolint: staticcheck
+	require.NoError(t, err)
+
+	backend := &proxy.SingleBackend{
+		GetConn: func(ctx context.Context) (context.Context, *grpc.ClientConn, error) {
+			md, _ := metadata.FromIncomingContext(ctx)
+
+			return metadata.NewOutgoingContext(ctx, md.Copy()), h.backendConn, nil
+		},
+	}
+
+	handler, err := proxy.NewTransparentHandler(directorFn(backend), options...)
+	require.NoError(t, err)
+
+	h.proxy = grpc.NewServer(
+		grpc.CustomCodec(proxy.Codec()), //nolint: staticcheck
+		grpc.UnknownServiceHandler(handler),
+	)
+
+	go h.proxy.Serve(proxyListener) //nolint: errcheck
+
+	h.clientConn, err = grpc.Dial(proxyListener.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+
+	h.client = pb.NewTestServiceClient(h.clientConn)
+
+	t.Cleanup(h.stop)
+
+	return h
+}
+
+func (h *testHarness) stop() {
+	h.clientConn.Close()  //nolint: errcheck
+	h.backendConn.Close() //nolint: errcheck
+
+	// Close all transports so the logs don't get spammy.
+	time.Sleep(10 * time.Millisecond)
+
+	h.proxy.Stop()
+	h.server.Stop()
+}
+
+// one2oneDirector always proxies to the backend in one2one mode.
+func one2oneDirector(backend proxy.Backend) proxy.StreamDirector {
+	return func(ctx context.Context, fullMethodName string) (proxy.Mode, []proxy.Backend, error) {
+		return proxy.One2One, []proxy.Backend{backend}, nil
+	}
+}
+
+func testContext(t testing.TB) context.Context {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	t.Cleanup(cancel)
+
+	return metadata.NewOutgoingContext(ctx, metadata.Pairs(clientMdKey, "true"))
+}
+
+// lenientService is a TestService which tolerates streams aborted by the proxy.
+type lenientService struct {
+	assertingService
+}
+
+func (s *lenientService) PingStream(stream pb.TestService_PingStreamServer) error {
+	for counter := int32(0); ; counter++ {
+		ping, err := stream.Recv()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+
+			return err
+		}
+
+		if err = stream.Send(&pb.PingResponse{Value: ping.Value, Counter: counter}); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,229 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// defaultHashReplicas is the default number of points per backend on the hash ring.
+const defaultHashReplicas = 100
+
+// FieldHashDirector routes the calls by consistent hashing of the request field onto the backend set.
+//
+// It gives field-level shard routing (e.g. by "cluster_id") without a custom director for each service: calls with
+// the same field value are proxied to the same backend, and changes of the backend set only move the values which
+// were routed to the added or removed backends.
+//
+// The request is decoded with the registered descriptors, so WithRequestPeek should be enabled for the handler.
+type FieldHashDirector struct {
+	// Backends returns the current backend set, e.g. BackendGroup.Backends.
+	Backends func() []Backend
+
+	// Files is the registry of descriptors used to decode the request, protoregistry.GlobalFiles is used if nil.
+	Files *protoregistry.Files
+
+	ring atomic.Pointer[hashRing]
+
+	// Field is the path of the request field: protobuf field names separated by dots, e.g. "cluster_id" or "target.cluster_id".
+	Field string
+
+	// Replicas is the number of points per backend on the hash ring, 100 by default.
+	Replicas int
+}
+
+// Director is a StreamDirector.
+func (d *FieldHashDirector) Director(ctx context.Context, fullMethodName string) (Mode, []Backend, error) {
+	backends := d.Backends()
+	if len(backends) == 0 {
+		return One2One, nil, nil
+	}
+
+	payload, ok := RequestFrameFromContext(ctx)
+	if !ok {
+		return One2One, nil, newError(ErrMalformedRequest, "no request message to route %s by", fullMethodName)
+	}
+
+	key, err := requestField(d.Files, fullMethodName, payload, d.Field)
+	if err != nil {
+		return One2One, nil, newError(ErrMalformedRequest, "error extracting routing field: %v", err)
+	}
+
+	return One2One, []Backend{d.hashRing(backends).lookup(key)}, nil
+}
+
+// hashRing returns the ring for the backend set, rebuilding it if the set changed.
+func (d *FieldHashDirector) hashRing(backends []Backend) *hashRing {
+	names := make([]string, len(backends))
+
+	for i := range backends {
+		names[i] = backends[i].String()
+	}
+
+	sort.Strings(names)
+
+	id := strings.Join(names, "\x00")
+
+	if ring := d.ring.Load(); ring != nil && ring.id == id {
+		return ring
+	}
+
+	replicas := d.Replicas
+	if replicas <= 0 {
+		replicas = defaultHashReplicas
+	}
+
+	ring := newHashRing(id, backends, replicas)
+	d.ring.Store(ring)
+
+	return ring
+}
+
+// hashRing maps the keys onto the backends with consistent hashing.
+type hashRing struct {
+	id       string
+	points   []uint64
+	backends []Backend
+}
+
+func newHashRing(id string, backends []Backend, replicas int) *hashRing {
+	ring := &hashRing{
+		id:       id,
+		points:   make([]uint64, 0, len(backends)*replicas),
+		backends: make([]Backend, 0, len(backends)*replicas),
+	}
+
+	type point struct {
+		backend Backend
+		hash    uint64
+	}
+
+	points := make([]point, 0, len(backends)*replicas)
+
+	for _, backend := range backends {
+		for i := 0; i < replicas; i++ {
+			points = append(points, point{hash: hashKey(backend.String() + "#" + strconv.Itoa(i)), backend: backend})
+		}
+	}
+
+	sort.Slice(points, func(i, j int) bool { return points[i].hash < points[j].hash })
+
+	for _, p := range points {
+		ring.points = append(ring.points, p.hash)
+		ring.backends = append(ring.backends, p.backend)
+	}
+
+	return ring
+}
+
+func (r *hashRing) lookup(key []byte) Backend {
+	h := hashKey(string(key))
+
+	i := sort.Search(len(r.points), func(i int) bool { return r.points[i] >= h })
+	if i == len(r.points) {
+		i = 0
+	}
+
+	return r.backends[i]
+}
+
+func hashKey(key string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(key)) //nolint:errcheck
+
+	// mix the bits, as FNV of the similar keys is clustered
+	sum := h.Sum64()
+	sum ^= sum >> 33
+	sum *= 0xff51afd7ed558ccd
+	sum ^= sum >> 33
+
+	return sum
+}
+
+// requestField decodes the request and returns the value of the field by path as the routing key.
+func requestField(files *protoregistry.Files, fullMethodName string, payload []byte, path string) ([]byte, error) {
+	methodDesc, err := lookupMethod(files, fullMethodName)
+	if err != nil {
+		return nil, err
+	}
+
+	return messageField(methodDesc.Input(), payload, path)
+}
+
+// messageField decodes the message and extracts the value of the field by the dotted path.
+func messageField(desc protoreflect.MessageDescriptor, payload []byte, path string) ([]byte, error) {
+	msg, fd, err := resolveMessageField(desc, payload, path)
+	if err != nil {
+		return nil, err
+	}
+
+	return fieldKey(fd, msg.Get(fd)), nil
+}
+
+// resolveMessageField decodes the message and resolves the scalar field by the dotted path, it returns the message
+// the field belongs to.
+func resolveMessageField(desc protoreflect.MessageDescriptor, payload []byte, path string) (protoreflect.Message, protoreflect.FieldDescriptor, error) {
+	msg := protoreflect.Message(dynamicpb.NewMessage(desc))
+
+	if err := proto.Unmarshal(payload, msg.Interface()); err != nil {
+		return nil, nil, fmt.Errorf("error decoding %s: %w", desc.FullName(), err)
+	}
+
+	names := strings.Split(path, ".")
+
+	for i, name := range names {
+		fd := msg.Descriptor().Fields().ByName(protoreflect.Name(name))
+		if fd == nil {
+			return nil, nil, fmt.Errorf("field %q not found in %s", name, msg.Descriptor().FullName())
+		}
+
+		if fd.IsList() || fd.IsMap() {
+			return nil, nil, fmt.Errorf("field %q is not a singular field", name)
+		}
+
+		if fd.Kind() == protoreflect.MessageKind || fd.Kind() == protoreflect.GroupKind {
+			if i == len(names)-1 {
+				return nil, nil, fmt.Errorf("field %q is a message", name)
+			}
+
+			msg = msg.Get(fd).Message()
+
+			continue
+		}
+
+		if i != len(names)-1 {
+			return nil, nil, fmt.Errorf("field %q is not a message", name)
+		}
+
+		return msg, fd, nil
+	}
+
+	return nil, nil, fmt.Errorf("empty field path")
+}
+
+// fieldKey encodes the scalar field value.
+func fieldKey(fd protoreflect.FieldDescriptor, value protoreflect.Value) []byte {
+	switch fd.Kind() { //nolint:exhaustive
+	case protoreflect.BytesKind:
+		return value.Bytes()
+	case protoreflect.StringKind:
+		return []byte(value.String())
+	case protoreflect.EnumKind:
+		return binary.AppendVarint(nil, int64(value.Enum()))
+	default:
+		return []byte(value.String())
+	}
+}
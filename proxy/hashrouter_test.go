@@ -0,0 +1,102 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy_test
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/noncepad/grpc-proxy/proxy"
+	pb "github.com/noncepad/grpc-proxy/testservice"
+)
+
+// tagEchoService responds to Ping with the tag of the backend which proxied the call.
+type tagEchoService struct {
+	assertingService
+}
+
+func (s *tagEchoService) Ping(ctx context.Context, ping *pb.PingRequest) (*pb.PingResponse, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	return &pb.PingResponse{Value: md.Get(backendTagMdKey)[0]}, nil
+}
+
+func TestFieldHashDirector(t *testing.T) {
+	group := proxy.NewBackendGroup()
+
+	h := newTestHarnessWithService(t, &tagEchoService{}, func(backend proxy.Backend) proxy.StreamDirector {
+		for _, tag := range []string{"a", "b", "c"} {
+			group.Add(&taggedBackend{Backend: backend, tag: tag})
+		}
+
+		director := &proxy.FieldHashDirector{
+			Backends: group.Backends,
+			Field:    "value",
+		}
+
+		return director.Director
+	}, proxy.WithRequestPeek())
+
+	ctx := testContext(t)
+
+	route := func() map[string]string {
+		routes := map[string]string{}
+
+		for i := 0; i < 100; i++ {
+			key := "cluster-" + strconv.Itoa(i)
+
+			resp, err := h.client.Ping(ctx, &pb.PingRequest{Value: key})
+			require.NoError(t, err)
+
+			routes[key] = resp.Value
+		}
+
+		return routes
+	}
+
+	routes := route()
+
+	counts := map[string]int{}
+	for _, tag := range routes {
+		counts[tag]++
+	}
+
+	assert.Len(t, counts, 3)
+
+	// routing is stable
+	assert.Equal(t, routes, route())
+
+	group.Remove("c")
+
+	// only the keys of the removed backend are remapped
+	for key, tag := range route() {
+		if routes[key] != "c" {
+			assert.Equal(t, routes[key], tag, key)
+		} else {
+			assert.NotEqual(t, "c", tag, key)
+		}
+	}
+}
+
+func TestFieldHashDirectorUnknownField(t *testing.T) {
+	h := newTestHarnessWithService(t, &tagEchoService{}, func(backend proxy.Backend) proxy.StreamDirector {
+		director := &proxy.FieldHashDirector{
+			Backends: func() []proxy.Backend { return []proxy.Backend{&taggedBackend{Backend: backend, tag: "a"}} },
+			Field:    "cluster_id",
+		}
+
+		return director.Director
+	}, proxy.WithRequestPeek())
+
+	_, err := h.client.Ping(testContext(t), &pb.PingRequest{Value: "foo"})
+	require.Error(t, err)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
@@ -0,0 +1,214 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+// HTTP2Tuning configures the HTTP/2 transport of the connections.
+//
+// grpc defaults (64 KiB windows with BDP estimation, 32 KiB buffers) throttle high-bandwidth streams, as the proxy
+// forwards each stream through two flow-controlled legs. Zero values keep the grpc defaults. Setting the window sizes
+// disables the BDP estimation. grpc doesn't allow changing the HTTP/2 max frame size, which is fixed to 16 KiB.
+type HTTP2Tuning struct {
+	// InitialWindowSize is the initial flow control window of each stream, at least 64 KiB.
+	InitialWindowSize int32
+	// InitialConnWindowSize is the initial flow control window of the connection, at least 64 KiB.
+	InitialConnWindowSize int32
+	// ReadBufferSize and WriteBufferSize are the sizes of the connection buffers.
+	ReadBufferSize  int
+	WriteBufferSize int
+}
+
+// ServerOptions returns the options for the grpc server of the proxy.
+func (t HTTP2Tuning) ServerOptions() []grpc.ServerOption {
+	var options []grpc.ServerOption
+
+	if t.InitialWindowSize > 0 {
+		options = append(options, grpc.InitialWindowSize(t.InitialWindowSize))
+	}
+
+	if t.InitialConnWindowSize > 0 {
+		options = append(options, grpc.InitialConnWindowSize(t.InitialConnWindowSize))
+	}
+
+	if t.ReadBufferSize > 0 {
+		options = append(options, grpc.ReadBufferSize(t.ReadBufferSize))
+	}
+
+	if t.WriteBufferSize > 0 {
+		options = append(options, grpc.WriteBufferSize(t.WriteBufferSize))
+	}
+
+	return options
+}
+
+// DialOptions returns the options for the connections to the backends.
+func (t HTTP2Tuning) DialOptions() []grpc.DialOption {
+	var options []grpc.DialOption
+
+	if t.InitialWindowSize > 0 {
+		options = append(options, grpc.WithInitialWindowSize(t.InitialWindowSize))
+	}
+
+	if t.InitialConnWindowSize > 0 {
+		options = append(options, grpc.WithInitialConnWindowSize(t.InitialConnWindowSize))
+	}
+
+	if t.ReadBufferSize > 0 {
+		options = append(options, grpc.WithReadBufferSize(t.ReadBufferSize))
+	}
+
+	if t.WriteBufferSize > 0 {
+		options = append(options, grpc.WithWriteBufferSize(t.WriteBufferSize))
+	}
+
+	return options
+}
+
+// defaultStallThreshold is the default duration of the blocked send counted as the window stall.
+const defaultStallThreshold = 10 * time.Millisecond
+
+// WindowStats counts the flow control window stalls of the proxied streams.
+//
+// Sending the message blocks while the flow control window of the receiver is exhausted, so the sends which block
+// longer than the threshold are counted as stalls: stalls towards the client are counted per method, and stalls
+// towards the backends per backend.
+type WindowStats struct {
+	methods  sync.Map // map[string]*stallCounter
+	backends sync.Map // map[string]*stallCounter
+
+	threshold time.Duration
+}
+
+// NewWindowStats creates new WindowStats, zero threshold defaults to 10ms.
+func NewWindowStats(threshold time.Duration) *WindowStats {
+	if threshold == 0 {
+		threshold = defaultStallThreshold
+	}
+
+	return &WindowStats{threshold: threshold}
+}
+
+// WithWindowStats enables collection of the flow control window stalls.
+func WithWindowStats(stats *WindowStats) Option {
+	return func(o *handlerOptions) {
+		o.windowStats = stats
+	}
+}
+
+// WindowStalls is a snapshot of stall counters.
+type WindowStalls struct {
+	Stalls uint64 `json:"stalls"`
+	// StallTime is the total time the sends were blocked in the stalls.
+	StallTime time.Duration `json:"stall_time"`
+}
+
+// WindowSnapshot is a snapshot of all stall counters.
+type WindowSnapshot struct {
+	Methods  map[string]WindowStalls `json:"methods"`
+	Backends map[string]WindowStalls `json:"backends"`
+}
+
+type stallCounter struct {
+	stalls    uint64
+	stallTime int64
+}
+
+func (c *stallCounter) snapshot() WindowStalls {
+	return WindowStalls{
+		Stalls:    atomic.LoadUint64(&c.stalls),
+		StallTime: time.Duration(atomic.LoadInt64(&c.stallTime)),
+	}
+}
+
+func loadStallCounter(m *sync.Map, key string) *stallCounter {
+	if c, ok := m.Load(key); ok {
+		return c.(*stallCounter) //nolint:forcetypeassert
+	}
+
+	c, _ := m.LoadOrStore(key, &stallCounter{})
+
+	return c.(*stallCounter) //nolint:forcetypeassert
+}
+
+// Snapshot returns current values of the counters.
+func (w *WindowStats) Snapshot() WindowSnapshot {
+	snapshot := WindowSnapshot{
+		Methods:  map[string]WindowStalls{},
+		Backends: map[string]WindowStalls{},
+	}
+
+	w.methods.Range(func(key, value interface{}) bool {
+		snapshot.Methods[key.(string)] = value.(*stallCounter).snapshot() //nolint:forcetypeassert
+
+		return true
+	})
+
+	w.backends.Range(func(key, value interface{}) bool {
+		snapshot.Backends[key.(string)] = value.(*stallCounter).snapshot() //nolint:forcetypeassert
+
+		return true
+	})
+
+	return snapshot
+}
+
+// send measures the send and counts the stall.
+func (w *WindowStats) send(counter *stallCounter, send func() error) error {
+	start := time.Now()
+	err := send()
+
+	if blocked := time.Since(start); blocked >= w.threshold {
+		atomic.AddUint64(&counter.stalls, 1)
+		atomic.AddInt64(&counter.stallTime, int64(blocked))
+	}
+
+	return err
+}
+
+// wrapServerStream measures the sends to the client.
+func (w *WindowStats) wrapServerStream(serverStream grpc.ServerStream, fullMethodName string) grpc.ServerStream {
+	return &stallServerStream{
+		ServerStream: serverStream,
+		stats:        w,
+		counter:      loadStallCounter(&w.methods, fullMethodName),
+	}
+}
+
+// wrapClientStream measures the sends to the backend.
+func (w *WindowStats) wrapClientStream(clientStream grpc.ClientStream, backend Backend) grpc.ClientStream {
+	return &stallClientStream{
+		ClientStream: clientStream,
+		stats:        w,
+		counter:      loadStallCounter(&w.backends, backend.String()),
+	}
+}
+
+type stallServerStream struct {
+	grpc.ServerStream
+
+	stats   *WindowStats
+	counter *stallCounter
+}
+
+func (s *stallServerStream) SendMsg(m interface{}) error {
+	return s.stats.send(s.counter, func() error { return s.ServerStream.SendMsg(m) })
+}
+
+type stallClientStream struct {
+	grpc.ClientStream
+
+	stats   *WindowStats
+	counter *stallCounter
+}
+
+func (s *stallClientStream) SendMsg(m interface{}) error {
+	return s.stats.send(s.counter, func() error { return s.ClientStream.SendMsg(m) })
+}
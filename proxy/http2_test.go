@@ -0,0 +1,60 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/noncepad/grpc-proxy/proxy"
+	pb "github.com/noncepad/grpc-proxy/testservice"
+)
+
+func TestHTTP2Tuning(t *testing.T) {
+	assert.Empty(t, proxy.HTTP2Tuning{}.ServerOptions())
+	assert.Empty(t, proxy.HTTP2Tuning{}.DialOptions())
+
+	tuning := proxy.HTTP2Tuning{
+		InitialWindowSize:     1 << 20,
+		InitialConnWindowSize: 4 << 20,
+		WriteBufferSize:       256 << 10,
+	}
+
+	assert.Len(t, tuning.ServerOptions(), 3)
+	assert.Len(t, tuning.DialOptions(), 3)
+}
+
+func TestWindowStats(t *testing.T) {
+	stats := proxy.NewWindowStats(10 * time.Millisecond)
+
+	h := newTestHarnessWithService(t, &floodService{}, one2oneDirector, proxy.WithWindowStats(stats))
+
+	ctx, cancel := context.WithCancel(testContext(t))
+	defer cancel()
+
+	stream, err := h.client.PingList(ctx, &pb.PingRequest{Value: strings.Repeat("x", 32<<10)})
+	require.NoError(t, err)
+
+	_, err = stream.Recv()
+	require.NoError(t, err)
+
+	// the client doesn't read, so the window to the client is exhausted and the proxy send blocks
+	time.Sleep(100 * time.Millisecond)
+
+	// stalls are counted once the blocked send completes
+	for i := 0; i < 20; i++ {
+		_, err = stream.Recv()
+		require.NoError(t, err)
+	}
+
+	stalls := stats.Snapshot().Methods["/talos.testproto.TestService/PingList"]
+
+	assert.Positive(t, stalls.Stalls)
+	assert.GreaterOrEqual(t, stalls.StallTime, 10*time.Millisecond)
+}
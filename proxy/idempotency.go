@@ -0,0 +1,333 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"net"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// IdempotencyKeyMetadataKey is the default metadata key carrying the idempotency key.
+const IdempotencyKeyMetadataKey = "idempotency-key"
+
+// defaultIdempotencyMaxEntries is the default limit of the outcomes cached by WithIdempotencyCache.
+const defaultIdempotencyMaxEntries = 10000
+
+// WithIdempotencyCache enables caching of the outcomes of unary calls carrying the idempotency key.
+//
+// When the client retries the call with the same idempotency key (metadataKey, IdempotencyKeyMetadataKey if empty)
+// within the ttl, the proxy returns the cached outcome (response, headers, trailers and the error) instead of
+// proxying the call again, which prevents duplicate side effects on the backends. Concurrent calls with the same key
+// wait for the first call to finish. Outcomes with transient error codes (Canceled, DeadlineExceeded, Unavailable,
+// ResourceExhausted, Aborted) are not cached, so that such calls can be retried.
+//
+// Idempotency keys are scoped to the method and to the caller: the subject of the validated JWT (see WithJWTValidator),
+// the verified client identity (see WithClientAuth), the subject of the TLS client certificate or the peer address,
+// so that the outcomes are never replayed to another caller. The key is bound to the request: the call reusing the key
+// with a different request message fails with ErrIdempotencyKeyReused. The cache is shared by all handlers configured
+// with the same Option value.
+//
+// Only the calls of fullMethodNames are cached, nothing is cached if no method names are passed. The number of the
+// cached outcomes is limited by maxEntries (10000 if zero), once exceeded the least recently used outcomes are evicted.
+func WithIdempotencyCache(ttl time.Duration, maxEntries int, metadataKey string, fullMethodNames ...string) Option {
+	if metadataKey == "" {
+		metadataKey = IdempotencyKeyMetadataKey
+	}
+
+	cache := &idempotencyCache{
+		ttl:         ttl,
+		maxEntries:  maxEntries,
+		metadataKey: metadataKey,
+		methods:     map[string]struct{}{},
+		entries:     map[string]*idempotencyEntry{},
+		recent:      list.New(),
+	}
+
+	if cache.maxEntries == 0 {
+		cache.maxEntries = defaultIdempotencyMaxEntries
+	}
+
+	for _, name := range fullMethodNames {
+		cache.methods[name] = struct{}{}
+	}
+
+	return func(o *handlerOptions) {
+		if ttl <= 0 {
+			o.invalid("idempotency cache ttl should be positive, got %s", ttl)
+		}
+
+		if maxEntries < 0 {
+			o.invalid("idempotency cache max entries should not be negative, got %d", maxEntries)
+		}
+
+		o.idempotencyCache = cache
+	}
+}
+
+type idempotencyCache struct {
+	methods map[string]struct{}
+	entries map[string]*idempotencyEntry
+	// recent orders the cached outcomes from the most recently used one
+	recent      *list.List
+	lastSweep   time.Time
+	metadataKey string
+	ttl         time.Duration
+	maxEntries  int
+
+	mu sync.Mutex
+}
+
+type idempotencyEntry struct {
+	done        chan struct{}
+	expiresAt   time.Time
+	outcome     *recordedOutcome
+	element     *list.Element
+	requestHash [sha256.Size]byte
+}
+
+// key returns cache key for the call scoped to the caller, empty key means the call is not cached.
+func (c *idempotencyCache) key(ctx context.Context, fullMethodName string) string {
+	if _, ok := c.methods[fullMethodName]; !ok {
+		return ""
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	values := md.Get(c.metadataKey)
+	if len(values) == 0 || values[0] == "" {
+		return ""
+	}
+
+	return fullMethodName + "\x00" + idempotencyCaller(ctx) + "\x00" + values[0]
+}
+
+// idempotencyCaller returns the identity of the caller the idempotency keys are scoped to.
+func idempotencyCaller(ctx context.Context) string {
+	if claims, ok := JWTClaimsFromContext(ctx); ok && claims.Subject() != "" {
+		return "jwt:" + claims.Subject()
+	}
+
+	if identity, ok := ClientIdentityFromContext(ctx); ok && identity.Principal != "" {
+		return identity.Mechanism + ":" + identity.Principal
+	}
+
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+
+	if tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo); ok && len(tlsInfo.State.PeerCertificates) > 0 {
+		return "tls:" + tlsInfo.State.PeerCertificates[0].Subject.String()
+	}
+
+	// the port differs for each connection of the client
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		host = p.Addr.String()
+	}
+
+	return "peer:" + host
+}
+
+// doRequest reads the request message the outcome is bound to, and calls do.
+func (c *idempotencyCache) doRequest(key string, serverStream grpc.ServerStream, call func(grpc.ServerStream) error) error {
+	peeked, err := peekServerStream(serverStream)
+	if err != nil {
+		return err
+	}
+
+	return c.do(key, sha256.Sum256(peeked.payload), peeked, call)
+}
+
+// do either replays the cached outcome, or runs the call and caches the outcome.
+func (c *idempotencyCache) do(key string, requestHash [sha256.Size]byte, serverStream grpc.ServerStream, call func(grpc.ServerStream) error) error {
+	for {
+		c.mu.Lock()
+
+		now := time.Now()
+		c.sweep(now)
+
+		entry, ok := c.entries[key]
+		if !ok || (entry.outcome != nil && now.After(entry.expiresAt)) {
+			if ok {
+				c.remove(key, entry)
+			}
+
+			entry = &idempotencyEntry{
+				done:        make(chan struct{}),
+				requestHash: requestHash,
+			}
+
+			c.entries[key] = entry
+			c.mu.Unlock()
+
+			return c.run(key, entry, serverStream, call)
+		}
+
+		if entry.element != nil {
+			c.recent.MoveToFront(entry.element)
+		}
+
+		c.mu.Unlock()
+
+		if entry.requestHash != requestHash {
+			return newError(ErrIdempotencyKeyReused, "idempotency key was already used with a different request")
+		}
+
+		select {
+		case <-entry.done:
+		case <-serverStream.Context().Done():
+			return status.FromContextError(serverStream.Context().Err()).Err()
+		}
+
+		if entry.outcome != nil {
+			return entry.outcome.replay(serverStream)
+		}
+
+		// outcome was not cached, retry
+	}
+}
+
+func (c *idempotencyCache) run(key string, entry *idempotencyEntry, serverStream grpc.ServerStream, call func(grpc.ServerStream) error) error {
+	recorder := &recordingServerStream{
+		ServerStream: serverStream,
+	}
+
+	err := call(recorder)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	switch status.Code(err) { //nolint:exhaustive
+	case codes.Canceled, codes.DeadlineExceeded, codes.Unavailable, codes.ResourceExhausted, codes.Aborted:
+		delete(c.entries, key)
+	default:
+		recorder.outcome.err = err
+		entry.outcome = &recorder.outcome
+		entry.expiresAt = time.Now().Add(c.ttl)
+		entry.element = c.recent.PushFront(key)
+
+		c.evict()
+	}
+
+	close(entry.done)
+
+	return err
+}
+
+// remove removes the entry, it should be called with the lock held.
+func (c *idempotencyCache) remove(key string, entry *idempotencyEntry) {
+	delete(c.entries, key)
+
+	if entry.element != nil {
+		c.recent.Remove(entry.element)
+	}
+}
+
+// evict removes the least recently used outcomes over maxEntries, it should be called with the lock held.
+//
+// The calls in progress are not evicted, their number is bounded by the concurrency of the calls.
+func (c *idempotencyCache) evict() {
+	for c.recent.Len() > 0 && len(c.entries) > c.maxEntries {
+		key := c.recent.Back().Value.(string) //nolint:forcetypeassert
+
+		c.remove(key, c.entries[key])
+	}
+}
+
+// sweep removes expired entries, it should be called with the lock held.
+func (c *idempotencyCache) sweep(now time.Time) {
+	if now.Sub(c.lastSweep) < c.ttl {
+		return
+	}
+
+	c.lastSweep = now
+
+	for key, entry := range c.entries {
+		if entry.outcome != nil && now.After(entry.expiresAt) {
+			c.remove(key, entry)
+		}
+	}
+}
+
+// recordedOutcome is the outcome of the call as observed by the client.
+type recordedOutcome struct {
+	header   metadata.MD
+	trailer  metadata.MD
+	err      error
+	messages [][]byte
+}
+
+func (outcome *recordedOutcome) replay(serverStream grpc.ServerStream) error {
+	if outcome.header != nil {
+		if err := serverStream.SetHeader(outcome.header); err != nil {
+			return err
+		}
+	}
+
+	for _, payload := range outcome.messages {
+		if err := serverStream.SendMsg(NewFrame(payload)); err != nil {
+			return err
+		}
+	}
+
+	if outcome.trailer != nil {
+		serverStream.SetTrailer(outcome.trailer)
+	}
+
+	return outcome.err
+}
+
+// recordingServerStream records everything sent to the client.
+type recordingServerStream struct {
+	grpc.ServerStream
+
+	outcome recordedOutcome
+	mu      sync.Mutex
+}
+
+func (s *recordingServerStream) SetHeader(md metadata.MD) error {
+	s.mu.Lock()
+	s.outcome.header = metadata.Join(s.outcome.header, md)
+	s.mu.Unlock()
+
+	return s.ServerStream.SetHeader(md)
+}
+
+func (s *recordingServerStream) SendHeader(md metadata.MD) error {
+	s.mu.Lock()
+	s.outcome.header = metadata.Join(s.outcome.header, md)
+	s.mu.Unlock()
+
+	return s.ServerStream.SendHeader(md)
+}
+
+func (s *recordingServerStream) SetTrailer(md metadata.MD) {
+	s.mu.Lock()
+	s.outcome.trailer = metadata.Join(s.outcome.trailer, md)
+	s.mu.Unlock()
+
+	s.ServerStream.SetTrailer(md)
+}
+
+func (s *recordingServerStream) SendMsg(m interface{}) error {
+	if f, ok := m.(*Frame); ok {
+		s.mu.Lock()
+		s.outcome.messages = append(s.outcome.messages, append([]byte(nil), f.payload...))
+		s.mu.Unlock()
+	}
+
+	return s.ServerStream.SendMsg(m)
+}
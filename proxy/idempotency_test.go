@@ -0,0 +1,149 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/noncepad/grpc-proxy/proxy"
+	pb "github.com/noncepad/grpc-proxy/testservice"
+)
+
+// countingService counts Ping calls.
+type countingService struct {
+	assertingService
+
+	pings int32
+}
+
+func (s *countingService) Ping(ctx context.Context, ping *pb.PingRequest) (*pb.PingResponse, error) {
+	counter := atomic.AddInt32(&s.pings, 1)
+
+	grpc.SetTrailer(ctx, metadata.Pairs(serverTrailerMdKey, "I like ending turtles.")) //nolint: errcheck
+
+	return &pb.PingResponse{Value: ping.Value, Counter: counter}, nil
+}
+
+func TestIdempotencyCache(t *testing.T) {
+	service := &countingService{}
+
+	h := newTestHarnessWithService(t, service, one2oneDirector,
+		proxy.WithIdempotencyCache(time.Minute, 0, "", "/talos.testproto.TestService/Ping"))
+
+	call := func(key string) *pb.PingResponse {
+		ctx := testContext(t)
+
+		if key != "" {
+			ctx = metadata.AppendToOutgoingContext(ctx, proxy.IdempotencyKeyMetadataKey, key)
+		}
+
+		var trailer metadata.MD
+
+		out, err := h.client.Ping(ctx, &pb.PingRequest{Value: "foo"}, grpc.Trailer(&trailer))
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"I like ending turtles."}, trailer.Get(serverTrailerMdKey))
+
+		return out
+	}
+
+	first := call("key1")
+	assert.EqualValues(t, 1, first.Counter)
+
+	retry := call("key1")
+	assert.EqualValues(t, 1, retry.Counter)
+
+	other := call("key2")
+	assert.EqualValues(t, 2, other.Counter)
+
+	assert.EqualValues(t, 3, call("").Counter)
+	assert.EqualValues(t, 4, call("").Counter)
+
+	assert.EqualValues(t, 4, atomic.LoadInt32(&service.pings))
+
+	// the key is bound to the request
+	_, err := h.client.Ping(metadata.AppendToOutgoingContext(testContext(t), proxy.IdempotencyKeyMetadataKey, "key1"), &pb.PingRequest{Value: "bar"})
+
+	proxyErr, ok := proxy.FromError(err)
+	require.True(t, ok, "%v", err)
+	assert.Equal(t, codes.FailedPrecondition, proxyErr.Code)
+	assert.Equal(t, proxy.ReasonIdempotencyKeyReused, proxyErr.Reason)
+
+	assert.EqualValues(t, 4, atomic.LoadInt32(&service.pings))
+}
+
+func TestIdempotencyCacheMaxEntries(t *testing.T) {
+	service := &countingService{}
+
+	h := newTestHarnessWithService(t, service, one2oneDirector,
+		proxy.WithIdempotencyCache(time.Minute, 2, "", "/talos.testproto.TestService/Ping"))
+
+	call := func(key string) int32 {
+		out, err := h.client.Ping(metadata.AppendToOutgoingContext(testContext(t), proxy.IdempotencyKeyMetadataKey, key), &pb.PingRequest{Value: "foo"})
+		require.NoError(t, err)
+
+		return out.Counter
+	}
+
+	assert.EqualValues(t, 1, call("key1"))
+	assert.EqualValues(t, 2, call("key2"))
+	assert.EqualValues(t, 1, call("key1"))
+
+	// the least recently used outcome is evicted
+	assert.EqualValues(t, 3, call("key3"))
+	assert.EqualValues(t, 1, call("key1"))
+	assert.EqualValues(t, 4, call("key2"))
+
+	assert.EqualValues(t, 4, atomic.LoadInt32(&service.pings))
+}
+
+// staticJWTKey is the JWTKeySource with a single key.
+type staticJWTKey struct {
+	key interface{}
+}
+
+func (s staticJWTKey) Key(context.Context, string) (interface{}, error) {
+	return s.key, nil
+}
+
+func TestIdempotencyCacheCallerScope(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	service := &countingService{}
+
+	h := newTestHarnessWithService(t, service, one2oneDirector,
+		proxy.WithJWTValidator(&proxy.JWTValidator{Keys: staticJWTKey{key: &key.PublicKey}}),
+		proxy.WithIdempotencyCache(time.Minute, 0, "", "/talos.testproto.TestService/Ping"))
+
+	exp := time.Now().Add(time.Hour).Unix()
+
+	call := func(subject string) int32 {
+		token := signTestJWT(t, key, "key1", map[string]interface{}{"sub": subject, "exp": exp})
+
+		ctx := metadata.AppendToOutgoingContext(testContext(t), "authorization", "Bearer "+token, proxy.IdempotencyKeyMetadataKey, "key1")
+
+		out, err := h.client.Ping(ctx, &pb.PingRequest{Value: "foo"})
+		require.NoError(t, err)
+
+		return out.Counter
+	}
+
+	assert.EqualValues(t, 1, call("alice"))
+	assert.EqualValues(t, 1, call("alice"))
+
+	// the outcome of another caller is never replayed
+	assert.EqualValues(t, 2, call("bob"))
+}
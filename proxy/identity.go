@@ -0,0 +1,72 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy
+
+import (
+	"context"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// IdentityMetadataKey lists the identities of the proxies the call passed through, one value per proxy.
+const IdentityMetadataKey = "via"
+
+// ProxyIdentity identifies the proxy instance to the backends.
+type ProxyIdentity struct {
+	// Name of the proxy, e.g. "edge-proxy".
+	Name string
+	// Version of the proxy, optional.
+	Version string
+	// InstanceID distinguishes the proxy instances with the same name and version, optional.
+	InstanceID string
+}
+
+// UserAgent returns the user-agent of the proxy, "name/version".
+func (id ProxyIdentity) UserAgent() string {
+	if id.Version == "" {
+		return id.Name
+	}
+
+	return id.Name + "/" + id.Version
+}
+
+// String returns the Via-style identity, "name/version instance".
+func (id ProxyIdentity) String() string {
+	parts := []string{id.UserAgent()}
+
+	if id.InstanceID != "" {
+		parts = append(parts, id.InstanceID)
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// DialOption returns the dial option which prepends the proxy user-agent to the grpc user-agent of the connection.
+//
+// User-agent is the property of the connection in grpc and can't be set per call, so the option should be passed
+// to the connections to the backends, e.g. to NewConnPool.
+func (id ProxyIdentity) DialOption() grpc.DialOption {
+	return grpc.WithUserAgent(id.UserAgent())
+}
+
+// WithProxyIdentity appends the proxy identity to the IdentityMetadataKey metadata of the upstream calls.
+//
+// Identities of the proxies the incoming call already passed through are preserved, so that the backends can
+// tell which chain of the proxy instances forwarded the call.
+func WithProxyIdentity(identity ProxyIdentity) Option {
+	return func(o *handlerOptions) {
+		o.identity = identity.String()
+	}
+}
+
+// identityOutgoingMetadata appends the proxy identity to the outgoing metadata of the upstream call.
+func (o *handlerOptions) identityOutgoingMetadata(incomingCtx context.Context, md *outgoingMetadata) {
+	if o.identity == "" {
+		return
+	}
+
+	md.set(IdentityMetadataKey, append(metadata.ValueFromIncomingContext(incomingCtx, IdentityMetadataKey), o.identity)...)
+}
@@ -0,0 +1,41 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/noncepad/grpc-proxy/proxy"
+	pb "github.com/noncepad/grpc-proxy/testservice"
+)
+
+func TestProxyIdentity(t *testing.T) {
+	identity := proxy.ProxyIdentity{Name: "inner", Version: "2.0", InstanceID: "i-7"}
+
+	assert.Equal(t, "inner/2.0", identity.UserAgent())
+	assert.Equal(t, "inner/2.0 i-7", identity.String())
+	assert.Equal(t, "inner", proxy.ProxyIdentity{Name: "inner"}.String())
+
+	h := newTestHarnessWithService(t, &metadataEchoService{}, func(backend proxy.Backend) proxy.StreamDirector {
+		return func(ctx context.Context, fullMethodName string) (proxy.Mode, []proxy.Backend, error) {
+			return proxy.One2One, []proxy.Backend{backend}, nil
+		}
+	}, proxy.WithProxyIdentity(identity),
+		proxy.WithStreamedDetector(func(fullMethodName string) bool { return fullMethodName == "/talos.testproto.TestService/PingStream" }))
+
+	ctx := metadata.AppendToOutgoingContext(testContext(t), proxy.IdentityMetadataKey, "edge/1.0 e-1")
+
+	stream, err := h.client.PingStream(ctx)
+	require.NoError(t, err)
+
+	require.NoError(t, stream.Send(&pb.PingRequest{Value: proxy.IdentityMetadataKey}))
+	require.NoError(t, stream.CloseSend())
+
+	assert.Equal(t, []string{"via=edge/1.0 e-1|inner/2.0 i-7"}, recvValues(t, stream, 1))
+}
@@ -0,0 +1,248 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy
+
+import (
+	"context"
+	"io"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// WithUpstreamStreamInterceptors registers client stream interceptors applied to every upstream call made
+// by the handler (e.g. logging or auth interceptors from go-grpc-middleware).
+//
+// Interceptors are invoked in the order of registration, after the upstream context is prepared (metadata,
+// signing, etc.) and before the interceptors of the backend connection.
+func WithUpstreamStreamInterceptors(interceptors ...grpc.StreamClientInterceptor) Option {
+	return func(o *handlerOptions) {
+		o.upstreamStreamInterceptors = append(o.upstreamStreamInterceptors, interceptors...)
+	}
+}
+
+// WithUpstreamUnaryInterceptors registers client unary interceptors applied to the upstream calls of unary methods
+// made by the handler (e.g. retry interceptors from go-grpc-middleware).
+//
+// When unary interceptors are registered, upstream calls of unary methods are made with grpc.ClientConn.Invoke
+// instead of raw streams, so that the interceptors see a single request and response (as *Frame), and might repeat
+// the call. The unary interceptors of the backend connection are applied as well in that case. The request is sent
+// to the backend once the client finishes sending, and the response headers are available once the call completes.
+//
+// Methods are considered unary based on the registered descriptors (see WithDescriptorFiles) or, if the method
+// descriptor is not known, on the streamed methods configuration of the handler.
+func WithUpstreamUnaryInterceptors(interceptors ...grpc.UnaryClientInterceptor) Option {
+	return func(o *handlerOptions) {
+		o.upstreamUnaryInterceptors = append(o.upstreamUnaryInterceptors, interceptors...)
+	}
+}
+
+// WithServerStreamInterceptors registers server stream interceptors applied around the proxying of every call
+// (e.g. auth, recovery or rate limiting interceptors from go-grpc-middleware).
+//
+// Unlike the interceptors of the grpc.Server, which see the transparent handler as the streaming unknown service,
+// the interceptors receive the grpc.StreamServerInfo with the full method name, and with IsClientStream
+// and IsServerStream set based on the registered descriptors (see WithDescriptorFiles) or, if the method
+// descriptor is not known, on the streamed methods configuration of the handler. The stream messages are *Frame.
+//
+// Interceptors are invoked in the order of registration, after the stats handler (see WithStatsHandler)
+// and before any other processing of the call.
+func WithServerStreamInterceptors(interceptors ...grpc.StreamServerInterceptor) Option {
+	return func(o *handlerOptions) {
+		o.serverStreamInterceptors = append(o.serverStreamInterceptors, interceptors...)
+	}
+}
+
+// intercept applies the server interceptors around the handler.
+func (o *handlerOptions) intercept(srv interface{}, serverStream grpc.ServerStream, fullMethodName string, handler grpc.StreamHandler) error {
+	info := &grpc.StreamServerInfo{
+		FullMethod:     fullMethodName,
+		IsClientStream: true,
+		IsServerStream: true,
+	}
+
+	if methodDesc, err := o.lookupMethod(fullMethodName); err == nil {
+		info.IsClientStream, info.IsServerStream = methodDesc.IsStreamingClient(), methodDesc.IsStreamingServer()
+	} else if o.streamedDetector != nil && !o.streamedDetector(fullMethodName) {
+		info.IsClientStream, info.IsServerStream = false, false
+	}
+
+	for i := len(o.serverStreamInterceptors) - 1; i >= 0; i-- {
+		interceptor, next := o.serverStreamInterceptors[i], handler
+
+		handler = func(srv interface{}, serverStream grpc.ServerStream) error {
+			return interceptor(srv, serverStream, info, next)
+		}
+	}
+
+	return handler(srv, serverStream)
+}
+
+// isUnary checks whether the method is unary.
+func (o *handlerOptions) isUnary(fullMethodName string) bool {
+	if methodDesc, err := o.lookupMethod(fullMethodName); err == nil {
+		return !methodDesc.IsStreamingClient() && !methodDesc.IsStreamingServer()
+	}
+
+	return o.streamedDetector != nil && !o.streamedDetector(fullMethodName)
+}
+
+// newUpstreamStream creates the upstream stream, applying the upstream interceptors.
+func (o *handlerOptions) newUpstreamStream(ctx context.Context, conn *grpc.ClientConn, fullMethodName string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	if len(o.upstreamUnaryInterceptors) > 0 && o.isUnary(fullMethodName) {
+		return newUnaryClientStream(ctx, conn, fullMethodName, o.upstreamUnaryInterceptors, opts), nil
+	}
+
+	streamer := grpc.Streamer(grpc.NewClientStream)
+
+	for i := len(o.upstreamStreamInterceptors) - 1; i >= 0; i-- {
+		interceptor, next := o.upstreamStreamInterceptors[i], streamer
+
+		streamer = func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+			return interceptor(ctx, desc, cc, method, next, opts...)
+		}
+	}
+
+	return streamer(ctx, clientStreamDescForProxying, conn, fullMethodName, opts...)
+}
+
+// unaryClientStream adapts the unary upstream call to the grpc.ClientStream interface.
+type unaryClientStream struct {
+	ctx     context.Context //nolint:containedctx
+	conn    *grpc.ClientConn
+	invoker grpc.UnaryInvoker
+
+	request *Frame
+	reply   *Frame
+
+	header  metadata.MD
+	trailer metadata.MD
+	err     error
+
+	closed chan struct{}
+	done   chan struct{}
+
+	method string
+	opts   []grpc.CallOption
+
+	closeOnce  sync.Once
+	invokeOnce sync.Once
+	received   bool
+}
+
+func newUnaryClientStream(ctx context.Context, conn *grpc.ClientConn, fullMethodName string,
+	interceptors []grpc.UnaryClientInterceptor, opts []grpc.CallOption,
+) *unaryClientStream {
+	invoker := grpc.UnaryInvoker(func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		return cc.Invoke(ctx, method, req, reply, opts...)
+	})
+
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		interceptor, next := interceptors[i], invoker
+
+		invoker = func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+			return interceptor(ctx, method, req, reply, cc, next, opts...)
+		}
+	}
+
+	return &unaryClientStream{
+		ctx:     ctx,
+		conn:    conn,
+		invoker: invoker,
+		method:  fullMethodName,
+		opts:    opts,
+		reply:   &Frame{},
+		closed:  make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+}
+
+// invoke makes the call once the client finished sending.
+func (s *unaryClientStream) invoke() {
+	s.invokeOnce.Do(func() {
+		defer close(s.done)
+
+		select {
+		case <-s.closed:
+		case <-s.ctx.Done():
+			s.err = status.FromContextError(s.ctx.Err()).Err()
+
+			return
+		}
+
+		if s.request == nil {
+			s.err = newError(ErrMalformedRequest, "no request message for unary method %s", s.method)
+
+			return
+		}
+
+		opts := append(append([]grpc.CallOption(nil), s.opts...), grpc.Header(&s.header), grpc.Trailer(&s.trailer))
+
+		s.err = s.invoker(s.ctx, s.method, s.request, s.reply, s.conn, opts...)
+	})
+}
+
+func (s *unaryClientStream) Header() (metadata.MD, error) {
+	s.invoke()
+
+	return s.header, s.err
+}
+
+func (s *unaryClientStream) Trailer() metadata.MD {
+	select {
+	case <-s.done:
+		return s.trailer
+	default:
+		return nil
+	}
+}
+
+func (s *unaryClientStream) CloseSend() error {
+	s.closeOnce.Do(func() { close(s.closed) })
+
+	return nil
+}
+
+func (s *unaryClientStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *unaryClientStream) SendMsg(m interface{}) error {
+	f, ok := m.(*Frame)
+	if !ok {
+		return newError(ErrInternal, "unexpected message type %T", m)
+	}
+
+	if s.request != nil {
+		return newError(ErrMalformedRequest, "more than one request message for unary method %s", s.method)
+	}
+
+	s.request = &Frame{payload: append([]byte(nil), f.payload...)}
+
+	return nil
+}
+
+func (s *unaryClientStream) RecvMsg(m interface{}) error {
+	s.invoke()
+
+	if s.err != nil {
+		return s.err
+	}
+
+	if s.received {
+		return io.EOF
+	}
+
+	f, ok := m.(*Frame)
+	if !ok {
+		return newError(ErrInternal, "unexpected message type %T", m)
+	}
+
+	f.payload = s.reply.payload
+	s.received = true
+
+	return nil
+}
@@ -0,0 +1,189 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/noncepad/grpc-proxy/proxy"
+	pb "github.com/noncepad/grpc-proxy/testservice"
+)
+
+// flakyService fails the first Ping calls with Unavailable.
+type flakyService struct {
+	lenientService
+
+	mu       sync.Mutex
+	failures int
+	calls    int
+}
+
+func (s *flakyService) Ping(ctx context.Context, ping *pb.PingRequest) (*pb.PingResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.calls++
+
+	if s.calls <= s.failures {
+		return nil, status.Error(codes.Unavailable, "try again")
+	}
+
+	grpc.SetHeader(ctx, metadata.Pairs(serverHeaderMdKey, "I like turtles.")) //nolint: errcheck
+
+	return &pb.PingResponse{Value: ping.Value, Counter: int32(s.calls)}, nil
+}
+
+func TestUpstreamUnaryInterceptors(t *testing.T) {
+	service := &flakyService{failures: 2}
+
+	var (
+		attempts int
+		messages []interface{}
+	)
+
+	retry := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		messages = append(messages, req)
+
+		for {
+			attempts++
+
+			err := invoker(ctx, method, req, reply, cc, opts...)
+			if status.Code(err) != codes.Unavailable || attempts == 5 {
+				return err
+			}
+		}
+	}
+
+	h := newTestHarnessWithService(t, service, one2oneDirector, proxy.WithUpstreamUnaryInterceptors(retry))
+
+	var header metadata.MD
+
+	resp, err := h.client.Ping(testContext(t), &pb.PingRequest{Value: "foo"}, grpc.Header(&header))
+	require.NoError(t, err)
+
+	assert.Equal(t, "foo", resp.Value)
+	assert.EqualValues(t, 3, resp.Counter)
+	assert.Equal(t, 3, attempts)
+	assert.Equal(t, []string{"I like turtles."}, header.Get(serverHeaderMdKey))
+
+	require.Len(t, messages, 1)
+	assert.IsType(t, &proxy.Frame{}, messages[0])
+
+	// streaming methods are not affected by unary interceptors
+	stream, err := h.client.PingStream(testContext(t))
+	require.NoError(t, err)
+
+	require.NoError(t, stream.Send(&pb.PingRequest{Value: "bar"}))
+	assert.Equal(t, []string{"bar"}, recvValues(t, stream, 1))
+	require.NoError(t, stream.CloseSend())
+
+	assert.Len(t, messages, 1)
+}
+
+func TestUpstreamStreamInterceptors(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		calls []string
+	)
+
+	record := func(name string) grpc.StreamClientInterceptor {
+		return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+			mu.Lock()
+			calls = append(calls, name+" "+method)
+			mu.Unlock()
+
+			return streamer(metadata.AppendToOutgoingContext(ctx, "interceptor", name), desc, cc, method, opts...)
+		}
+	}
+
+	h := newTestHarnessWithService(t, &metadataEchoService{}, one2oneDirector,
+		proxy.WithUpstreamStreamInterceptors(record("first")),
+		proxy.WithUpstreamStreamInterceptors(record("second")),
+	)
+
+	stream, err := h.client.PingStream(testContext(t))
+	require.NoError(t, err)
+
+	require.NoError(t, stream.Send(&pb.PingRequest{Value: "interceptor"}))
+	assert.Equal(t, []string{"interceptor=first|second"}, recvValues(t, stream, 1))
+	require.NoError(t, stream.CloseSend())
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	assert.Equal(t, []string{
+		"first /talos.testproto.TestService/PingStream",
+		"second /talos.testproto.TestService/PingStream",
+	}, calls)
+}
+
+func TestServerStreamInterceptors(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		infos []grpc.StreamServerInfo
+		order []string
+	)
+
+	record := func(name string) grpc.StreamServerInterceptor {
+		return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			mu.Lock()
+			order = append(order, name)
+
+			if name == "first" {
+				infos = append(infos, *info)
+			}
+			mu.Unlock()
+
+			return handler(srv, ss)
+		}
+	}
+
+	deny := func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if info.FullMethod == "/talos.testproto.TestService/PingEmpty" {
+			return status.Error(codes.PermissionDenied, "denied by interceptor")
+		}
+
+		return handler(srv, ss)
+	}
+
+	h := newTestHarness(t, one2oneDirector,
+		proxy.WithServerStreamInterceptors(record("first"), deny),
+		proxy.WithServerStreamInterceptors(record("second")))
+
+	ctx := testContext(t)
+
+	_, err := h.client.Ping(ctx, &pb.PingRequest{Value: "foo"})
+	require.NoError(t, err)
+
+	stream, err := h.client.PingList(ctx, &pb.PingRequest{Value: "foo"})
+	require.NoError(t, err)
+
+	for {
+		if _, err = stream.Recv(); err != nil {
+			break
+		}
+	}
+
+	_, err = h.client.PingEmpty(ctx, &pb.Empty{})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	assert.Equal(t, []string{"first", "second", "first", "second", "first"}, order)
+	assert.Equal(t, []grpc.StreamServerInfo{
+		{FullMethod: "/talos.testproto.TestService/Ping"},
+		{FullMethod: "/talos.testproto.TestService/PingList", IsServerStream: true},
+		{FullMethod: "/talos.testproto.TestService/PingEmpty"},
+	}, infos)
+}
@@ -0,0 +1,576 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// JWTClaims is a set of claims of the validated token.
+type JWTClaims map[string]interface{}
+
+// String returns string value of the claim (or empty string if the claim is missing or not a string).
+func (c JWTClaims) String(name string) string {
+	v, _ := c[name].(string) //nolint:errcheck
+
+	return v
+}
+
+// Subject returns the "sub" claim.
+func (c JWTClaims) Subject() string {
+	return c.String("sub")
+}
+
+type jwtClaimsKey struct{}
+
+// JWTClaimsFromContext returns claims of the token validated by the JWTValidator.
+func JWTClaimsFromContext(ctx context.Context) (JWTClaims, bool) {
+	claims, ok := ctx.Value(jwtClaimsKey{}).(JWTClaims)
+
+	return claims, ok
+}
+
+// JWKS is a JSON Web Key Set fetched from the URL.
+//
+// Keys are refreshed periodically, and on demand when a token signed with an unknown key ID is seen,
+// which allows seamless key rotation. The key set is fetched by a single caller at a time without holding the lock,
+// so that a slow identity provider doesn't stall the validation of the tokens signed with the known keys.
+type JWKS struct {
+	keys      map[string]interface{}
+	fetchedAt time.Time
+	refresh   *jwksRefresh
+
+	// URL of the key set.
+	URL string
+
+	// Client is used to fetch the key set, http.DefaultClient is used if nil.
+	Client *http.Client
+
+	// RefreshInterval is the interval to refresh the key set, defaults to 1 hour.
+	RefreshInterval time.Duration
+
+	// MinRefreshInterval limits on demand refreshes on unknown key ID, defaults to 1 minute.
+	MinRefreshInterval time.Duration
+
+	mu sync.Mutex
+}
+
+// jwksRefresh is the refresh of the key set in progress, the callers needing the refresh wait for it.
+type jwksRefresh struct {
+	done chan struct{}
+	err  error
+}
+
+// Key returns the key by the key ID.
+func (s *JWKS) Key(ctx context.Context, kid string) (interface{}, error) {
+	refreshInterval, minRefreshInterval := s.RefreshInterval, s.MinRefreshInterval
+
+	if refreshInterval == 0 {
+		refreshInterval = time.Hour
+	}
+
+	if minRefreshInterval == 0 {
+		minRefreshInterval = time.Minute
+	}
+
+	s.mu.Lock()
+
+	key, ok := s.keys[kid]
+
+	if (ok && time.Since(s.fetchedAt) < refreshInterval) || (s.keys != nil && time.Since(s.fetchedAt) < minRefreshInterval) {
+		s.mu.Unlock()
+
+		if !ok {
+			return nil, fmt.Errorf("unknown key ID %q", kid)
+		}
+
+		return key, nil
+	}
+
+	refresh := s.refresh
+	leader := refresh == nil
+
+	if leader {
+		refresh = &jwksRefresh{done: make(chan struct{})}
+		s.refresh = refresh
+	}
+
+	s.mu.Unlock()
+
+	if leader {
+		keys, err := s.fetch(ctx)
+
+		s.mu.Lock()
+
+		if err == nil {
+			s.keys, s.fetchedAt = keys, time.Now()
+		}
+
+		refresh.err = err
+		s.refresh = nil
+
+		s.mu.Unlock()
+
+		close(refresh.done)
+	} else {
+		select {
+		case <-refresh.done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	s.mu.Lock()
+	key, ok = s.keys[kid]
+	s.mu.Unlock()
+
+	switch {
+	case ok:
+		// the stale key is served if the refresh failed
+		return key, nil
+	case refresh.err != nil:
+		return nil, refresh.err
+	default:
+		return nil, fmt.Errorf("unknown key ID %q", kid)
+	}
+}
+
+func (s *JWKS) fetch(ctx context.Context) (map[string]interface{}, error) {
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching JWKS: %w", err)
+	}
+
+	defer resp.Body.Close() //nolint:errcheck
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("error fetching JWKS: unexpected status %s", resp.Status)
+	}
+
+	return ParseJWKS(resp.Body)
+}
+
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+	K   string `json:"k"`
+}
+
+// ParseJWKS parses JSON Web Key Set into a map of key ID to public key (*rsa.PublicKey, *ecdsa.PublicKey or []byte for symmetric keys).
+func ParseJWKS(r io.Reader) (map[string]interface{}, error) {
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+
+	if err := json.NewDecoder(r).Decode(&set); err != nil {
+		return nil, fmt.Errorf("error decoding JWKS: %w", err)
+	}
+
+	keys := make(map[string]interface{}, len(set.Keys))
+
+	for _, jwk := range set.Keys {
+		key, err := jwk.publicKey()
+		if err != nil {
+			return nil, fmt.Errorf("error decoding key %q: %w", jwk.Kid, err)
+		}
+
+		if key != nil {
+			keys[jwk.Kid] = key
+		}
+	}
+
+	return keys, nil
+}
+
+func (jwk *jsonWebKey) publicKey() (interface{}, error) {
+	decode := base64.RawURLEncoding.DecodeString
+
+	switch jwk.Kty {
+	case "RSA":
+		n, err := decode(jwk.N)
+		if err != nil {
+			return nil, err
+		}
+
+		e, err := decode(jwk.E)
+		if err != nil {
+			return nil, err
+		}
+
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+
+		switch jwk.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", jwk.Crv)
+		}
+
+		x, err := decode(jwk.X)
+		if err != nil {
+			return nil, err
+		}
+
+		y, err := decode(jwk.Y)
+		if err != nil {
+			return nil, err
+		}
+
+		return &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}, nil
+	case "oct":
+		return decode(jwk.K)
+	default:
+		// unsupported key types are skipped
+		return nil, nil
+	}
+}
+
+// JWTKeySource provides keys to verify token signatures.
+//
+// JWKS implements JWTKeySource.
+type JWTKeySource interface {
+	Key(ctx context.Context, kid string) (interface{}, error)
+}
+
+// JWTValidator validates bearer tokens in the incoming request metadata.
+type JWTValidator struct {
+	// Keys is the source of the keys to verify token signatures.
+	Keys JWTKeySource
+
+	// Issuer, if set, should match the "iss" claim.
+	Issuer string
+
+	// Audience, if set, should be present in the "aud" claim.
+	Audience string
+
+	// Leeway is the allowed clock skew for "exp" and "nbf" claims.
+	Leeway time.Duration
+
+	// MetadataKey is the metadata key carrying the token, defaults to "authorization".
+	//
+	// Optional "Bearer " prefix is stripped from the value.
+	MetadataKey string
+
+	// InjectMetadata maps string claims to the incoming metadata keys.
+	//
+	// Values of the metadata keys are replaced with the values of the claims (or removed if the claim is missing,
+	// or if the call has no token), so that the backends receive verified values instead of the ones provided
+	// by the client.
+	InjectMetadata map[string]string
+
+	// Optional allows calls without the token (calls with invalid tokens are still rejected).
+	Optional bool
+}
+
+// WithJWTValidator enables validation of the tokens in the incoming requests.
+//
+// Calls with missing or invalid tokens are rejected with codes.Unauthenticated before the director is invoked.
+// Claims of the validated token are available to the director and backends via JWTClaimsFromContext.
+func WithJWTValidator(validator *JWTValidator) Option {
+	return func(o *handlerOptions) {
+		o.jwtValidator = validator
+	}
+}
+
+// StreamServerInterceptor returns the interceptor which validates the tokens.
+//
+// The interceptor might be used instead of WithJWTValidator to protect both proxied and locally served methods.
+func (v *JWTValidator) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := v.Authenticate(ss.Context())
+		if err != nil {
+			return err
+		}
+
+		return handler(srv, &contextServerStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// Authenticate validates the token in the incoming metadata and returns the context with the claims attached.
+func (v *JWTValidator) Authenticate(ctx context.Context) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	// the injected keys are stripped on every path, so that the client can't forge them by omitting the token
+	if len(v.InjectMetadata) > 0 {
+		md = md.Copy()
+
+		for _, mdKey := range v.InjectMetadata {
+			md.Delete(mdKey)
+		}
+
+		ctx = metadata.NewIncomingContext(ctx, md)
+	}
+
+	token, ok := v.token(md)
+	if !ok {
+		if v.Optional {
+			return ctx, nil
+		}
+
+		return nil, newError(ErrUnauthenticated, "missing token")
+	}
+
+	claims, err := v.Validate(ctx, token)
+	if err != nil {
+		return nil, newError(ErrUnauthenticated, "invalid token: %v", err)
+	}
+
+	if len(v.InjectMetadata) > 0 {
+		// md is the copy with the injected keys stripped
+		for claim, mdKey := range v.InjectMetadata {
+			if value := claims.String(claim); value != "" {
+				md.Set(mdKey, value)
+			}
+		}
+
+		ctx = metadata.NewIncomingContext(ctx, md)
+	}
+
+	return context.WithValue(ctx, jwtClaimsKey{}, claims), nil
+}
+
+// token extracts the token from the incoming metadata.
+func (v *JWTValidator) token(md metadata.MD) (string, bool) {
+	key := v.MetadataKey
+	if key == "" {
+		key = "authorization"
+	}
+
+	values := md.Get(key)
+	if len(values) == 0 {
+		return "", false
+	}
+
+	token := strings.TrimSpace(values[0])
+	if len(token) > 7 && strings.EqualFold(token[:7], "bearer ") {
+		token = strings.TrimSpace(token[7:])
+	}
+
+	return token, true
+}
+
+// Validate verifies the token signature and claims.
+//
+//nolint:gocognit,gocyclo,cyclop
+func (v *JWTValidator) Validate(ctx context.Context, token string) (JWTClaims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("malformed token")
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+
+	if err := decodeJWTSegment(parts[0], &header); err != nil {
+		return nil, err
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("malformed signature: %w", err)
+	}
+
+	key, err := v.Keys.Key(ctx, header.Kid)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = verifyJWTSignature(header.Alg, key, []byte(parts[0]+"."+parts[1]), signature); err != nil {
+		return nil, err
+	}
+
+	var claims JWTClaims
+
+	if err = decodeJWTSegment(parts[1], &claims); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+
+	if exp, ok := claims["exp"].(float64); ok && now.After(time.Unix(int64(exp), 0).Add(v.Leeway)) {
+		return nil, errors.New("token is expired")
+	}
+
+	if nbf, ok := claims["nbf"].(float64); ok && now.Add(v.Leeway).Before(time.Unix(int64(nbf), 0)) {
+		return nil, errors.New("token is not valid yet")
+	}
+
+	if v.Issuer != "" && claims.String("iss") != v.Issuer {
+		return nil, errors.New("issuer mismatch")
+	}
+
+	if v.Audience != "" {
+		found := false
+
+		switch aud := claims["aud"].(type) {
+		case string:
+			found = aud == v.Audience
+		case []interface{}:
+			for _, a := range aud {
+				if a == v.Audience {
+					found = true
+				}
+			}
+		}
+
+		if !found {
+			return nil, errors.New("audience mismatch")
+		}
+	}
+
+	return claims, nil
+}
+
+func decodeJWTSegment(segment string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return fmt.Errorf("malformed token: %w", err)
+	}
+
+	if err = json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("malformed token: %w", err)
+	}
+
+	return nil
+}
+
+// jwtECDSACurves maps the ECDSA algorithms to the curves of their keys.
+var jwtECDSACurves = map[string]elliptic.Curve{
+	"ES256": elliptic.P256(),
+	"ES384": elliptic.P384(),
+	"ES512": elliptic.P521(),
+}
+
+//nolint:gocyclo,cyclop
+func verifyJWTSignature(alg string, key interface{}, signed, signature []byte) error {
+	if len(alg) != 5 {
+		return fmt.Errorf("unsupported algorithm %q", alg)
+	}
+
+	var hash crypto.Hash
+
+	switch alg[2:] {
+	case "256":
+		hash = crypto.SHA256
+	case "384":
+		hash = crypto.SHA384
+	case "512":
+		hash = crypto.SHA512
+	default:
+		return fmt.Errorf("unsupported algorithm %q", alg)
+	}
+
+	h := hash.New()
+	h.Write(signed) //nolint:errcheck
+	digest := h.Sum(nil)
+
+	switch {
+	case strings.HasPrefix(alg, "RS"):
+		pub, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("key type mismatch for %q", alg)
+		}
+
+		return rsa.VerifyPKCS1v15(pub, hash, digest, signature)
+	case strings.HasPrefix(alg, "PS"):
+		pub, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("key type mismatch for %q", alg)
+		}
+
+		return rsa.VerifyPSS(pub, hash, digest, signature, nil)
+	case strings.HasPrefix(alg, "ES"):
+		pub, ok := key.(*ecdsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("key type mismatch for %q", alg)
+		}
+
+		// the algorithm is bound to the curve, and the signature is the fixed-size R || S (RFC 7518, section 3.4)
+		curve := jwtECDSACurves[alg]
+		if curve == nil || pub.Curve != curve {
+			return fmt.Errorf("key curve mismatch for %q", alg)
+		}
+
+		if size := (curve.Params().BitSize + 7) / 8; len(signature) != 2*size {
+			return fmt.Errorf("invalid signature length %d for %q", len(signature), alg)
+		}
+
+		r := new(big.Int).SetBytes(signature[:len(signature)/2])
+		s := new(big.Int).SetBytes(signature[len(signature)/2:])
+
+		if !ecdsa.Verify(pub, digest, r, s) {
+			return errors.New("invalid signature")
+		}
+
+		return nil
+	case strings.HasPrefix(alg, "HS"):
+		secret, ok := key.([]byte)
+		if !ok {
+			return fmt.Errorf("key type mismatch for %q", alg)
+		}
+
+		mac := hmac.New(hash.New, secret)
+		mac.Write(signed) //nolint:errcheck
+
+		if !hmac.Equal(mac.Sum(nil), signature) {
+			return errors.New("invalid signature")
+		}
+
+		return nil
+	default:
+		return fmt.Errorf("unsupported algorithm %q", alg)
+	}
+}
+
+// contextServerStream overrides the context of the server stream.
+type contextServerStream struct {
+	grpc.ServerStream
+
+	ctx context.Context //nolint:containedctx
+}
+
+func (s *contextServerStream) Context() context.Context {
+	return s.ctx
+}
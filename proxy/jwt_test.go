@@ -0,0 +1,311 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy_test
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/noncepad/grpc-proxy/proxy"
+	pb "github.com/noncepad/grpc-proxy/testservice"
+)
+
+func signTestJWT(t *testing.T, key *rsa.PrivateKey, kid string, claims map[string]interface{}) string {
+	t.Helper()
+
+	segment := func(v interface{}) string {
+		data, err := json.Marshal(v)
+		require.NoError(t, err)
+
+		return base64.RawURLEncoding.EncodeToString(data)
+	}
+
+	signed := segment(map[string]string{"alg": "RS256", "kid": kid}) + "." + segment(claims)
+	digest := sha256.Sum256([]byte(signed))
+
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	require.NoError(t, err)
+
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func TestJWTValidator(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	jwksServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{ //nolint:errcheck
+			"keys": []map[string]string{
+				{
+					"kty": "RSA",
+					"kid": "key1",
+					"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+					"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+				},
+			},
+		})
+	}))
+	t.Cleanup(jwksServer.Close)
+
+	var tenants []string
+
+	h := newTestHarness(t, func(backend proxy.Backend) proxy.StreamDirector {
+		return func(ctx context.Context, fullMethodName string) (proxy.Mode, []proxy.Backend, error) {
+			claims, ok := proxy.JWTClaimsFromContext(ctx)
+			if !ok {
+				return proxy.One2One, nil, status.Error(codes.Internal, "no claims")
+			}
+
+			md, _ := metadata.FromIncomingContext(ctx)
+			tenants = append(tenants, md.Get("x-tenant")...)
+
+			if claims.String("tenant") != "acme" {
+				return proxy.One2One, nil, status.Error(codes.NotFound, "unknown tenant")
+			}
+
+			return proxy.One2One, []proxy.Backend{backend}, nil
+		}
+	}, proxy.WithJWTValidator(&proxy.JWTValidator{
+		Keys:           &proxy.JWKS{URL: jwksServer.URL},
+		Issuer:         "test",
+		InjectMetadata: map[string]string{"tenant": "x-tenant"},
+	}))
+
+	call := func(token string) error {
+		ctx := metadata.AppendToOutgoingContext(testContext(t), "authorization", "Bearer "+token, "x-tenant", "spoofed")
+
+		_, err := h.client.Ping(ctx, &pb.PingRequest{Value: "foo"})
+
+		return err
+	}
+
+	exp := time.Now().Add(time.Hour).Unix()
+
+	require.NoError(t, call(signTestJWT(t, key, "key1", map[string]interface{}{"iss": "test", "tenant": "acme", "exp": exp})))
+	assert.Equal(t, []string{"acme"}, tenants)
+
+	err = call(signTestJWT(t, key, "key1", map[string]interface{}{"iss": "test", "tenant": "other", "exp": exp}))
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	err = call(signTestJWT(t, key, "key1", map[string]interface{}{"iss": "test", "tenant": "acme", "exp": time.Now().Add(-time.Hour).Unix()}))
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	err = call(signTestJWT(t, key, "key1", map[string]interface{}{"iss": "other", "tenant": "acme", "exp": exp}))
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	err = call(signTestJWT(t, key, "key2", map[string]interface{}{"iss": "test", "tenant": "acme", "exp": exp}))
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	err = call("garbage")
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	_, err = h.client.Ping(testContext(t), &pb.PingRequest{Value: "foo"})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
+
+func TestJWTValidatorOptionalStripsInjectedMetadata(t *testing.T) {
+	var tenants []string
+
+	h := newTestHarness(t, func(backend proxy.Backend) proxy.StreamDirector {
+		return func(ctx context.Context, fullMethodName string) (proxy.Mode, []proxy.Backend, error) {
+			md, _ := metadata.FromIncomingContext(ctx)
+			tenants = append(tenants, md.Get("x-tenant")...)
+
+			return proxy.One2One, []proxy.Backend{backend}, nil
+		}
+	}, proxy.WithJWTValidator(&proxy.JWTValidator{
+		Keys:           &proxy.JWKS{URL: "http://127.0.0.1:0"},
+		InjectMetadata: map[string]string{"tenant": "x-tenant"},
+		Optional:       true,
+	}))
+
+	_, err := h.client.Ping(metadata.AppendToOutgoingContext(testContext(t), "x-tenant", "spoofed"), &pb.PingRequest{Value: "foo"})
+	require.NoError(t, err)
+
+	assert.Empty(t, tenants)
+}
+
+func TestJWKSRefreshDoesNotBlock(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	var fetches int32
+
+	release := make(chan struct{})
+
+	jwksServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// the refreshes after the first fetch are stalled
+		if atomic.AddInt32(&fetches, 1) > 1 {
+			<-release
+		}
+
+		json.NewEncoder(w).Encode(map[string]interface{}{ //nolint:errcheck
+			"keys": []map[string]string{
+				{
+					"kty": "RSA",
+					"kid": "key1",
+					"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+					"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+				},
+			},
+		})
+	}))
+	t.Cleanup(jwksServer.Close)
+	t.Cleanup(func() { close(release) })
+
+	jwks := &proxy.JWKS{URL: jwksServer.URL, MinRefreshInterval: time.Nanosecond}
+
+	ctx := testContext(t)
+
+	_, err = jwks.Key(ctx, "key1")
+	require.NoError(t, err)
+
+	// the unknown key IDs trigger the refresh, which is stalled
+	refreshCtx, refreshCancel := context.WithTimeout(ctx, 500*time.Millisecond)
+	defer refreshCancel()
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			_, refreshErr := jwks.Key(refreshCtx, "key2")
+			assert.Error(t, refreshErr)
+		}()
+	}
+
+	// the known key is served while the refresh is in progress
+	for i := 0; i < 10; i++ {
+		start := time.Now()
+
+		_, err = jwks.Key(ctx, "key1")
+		require.NoError(t, err)
+
+		assert.Less(t, time.Since(start), 100*time.Millisecond)
+	}
+
+	wg.Wait()
+
+	// a single refresh was made by the concurrent callers
+	assert.EqualValues(t, 2, atomic.LoadInt32(&fetches))
+}
+
+// staticJWTKeys is the JWTKeySource of the fixed keys.
+type staticJWTKeys map[string]interface{}
+
+func (k staticJWTKeys) Key(_ context.Context, kid string) (interface{}, error) {
+	key, ok := k[kid]
+	if !ok {
+		return nil, errors.New("unknown key")
+	}
+
+	return key, nil
+}
+
+// signTestECDSAJWT signs the token with the key, the halves of the signature are padded to the size.
+func signTestECDSAJWT(t *testing.T, key *ecdsa.PrivateKey, alg, kid string, hash crypto.Hash, size int) string {
+	t.Helper()
+
+	segment := func(v interface{}) string {
+		data, err := json.Marshal(v)
+		require.NoError(t, err)
+
+		return base64.RawURLEncoding.EncodeToString(data)
+	}
+
+	signed := segment(map[string]string{"alg": alg, "kid": kid}) + "." + segment(map[string]interface{}{"sub": "test"})
+
+	h := hash.New()
+	h.Write([]byte(signed)) //nolint:errcheck
+
+	r, s, err := ecdsa.Sign(rand.Reader, key, h.Sum(nil))
+	require.NoError(t, err)
+
+	signature := make([]byte, 2*size)
+	r.FillBytes(signature[:size])
+	s.FillBytes(signature[size:])
+
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func TestJWTValidatorECDSA(t *testing.T) {
+	p256, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	p384, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(t, err)
+
+	p521, err := ecdsa.GenerateKey(elliptic.P521(), rand.Reader)
+	require.NoError(t, err)
+
+	validator := &proxy.JWTValidator{
+		Keys: staticJWTKeys{"p256": &p256.PublicKey, "p384": &p384.PublicKey, "p521": &p521.PublicKey},
+	}
+
+	ctx := testContext(t)
+
+	// truncate drops the last bytes of R and S
+	truncate := func(token string) string {
+		dot := strings.LastIndexByte(token, '.')
+
+		signature, err := base64.RawURLEncoding.DecodeString(token[dot+1:])
+		require.NoError(t, err)
+
+		size := len(signature) / 2
+		signature = append(signature[:size-1:size-1], signature[size:2*size-1]...)
+
+		return token[:dot+1] + base64.RawURLEncoding.EncodeToString(signature)
+	}
+
+	for _, test := range []struct {
+		name  string
+		token string
+		valid bool
+	}{
+		{"ES256", signTestECDSAJWT(t, p256, "ES256", "p256", crypto.SHA256, 32), true},
+		{"ES384", signTestECDSAJWT(t, p384, "ES384", "p384", crypto.SHA384, 48), true},
+		{"ES512", signTestECDSAJWT(t, p521, "ES512", "p521", crypto.SHA512, 66), true},
+		{"ES256 with P-384 key", signTestECDSAJWT(t, p384, "ES256", "p384", crypto.SHA256, 48), false},
+		{"ES384 with P-256 key", signTestECDSAJWT(t, p256, "ES384", "p256", crypto.SHA384, 32), false},
+		{"ES512 with P-384 key", signTestECDSAJWT(t, p384, "ES512", "p384", crypto.SHA512, 48), false},
+		{"padded signature", signTestECDSAJWT(t, p256, "ES256", "p256", crypto.SHA256, 33), false},
+		{"truncated signature", truncate(signTestECDSAJWT(t, p256, "ES256", "p256", crypto.SHA256, 32)), false},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			claims, err := validator.Validate(ctx, test.token)
+
+			if test.valid {
+				require.NoError(t, err)
+				assert.Equal(t, "test", claims.Subject())
+			} else {
+				require.Error(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,307 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/hashicorp/go-multierror"
+	"google.golang.org/grpc"
+)
+
+// BackendIterator returns the backends of the one2many call as they are discovered.
+type BackendIterator interface {
+	// Next blocks until the next backend is discovered, it returns io.EOF once all the backends are returned.
+	Next(ctx context.Context) (Backend, error)
+}
+
+// BackendChannel is the BackendIterator reading the backends from the channel, which is closed once all
+// the backends are sent.
+type BackendChannel <-chan Backend
+
+// Next implements BackendIterator.
+func (ch BackendChannel) Next(ctx context.Context) (Backend, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case backend, ok := <-ch:
+		if !ok {
+			return nil, io.EOF
+		}
+
+		return backend, nil
+	}
+}
+
+// LazyDirector returns the backends of the one2many call lazily, so that the call is forwarded to each backend
+// as soon as it is discovered, rather than after the whole set is enumerated.
+//
+// If ok is false, the call is directed by the StreamDirector as usual.
+type LazyDirector func(ctx context.Context, fullMethodName string) (backends BackendIterator, ok bool, err error)
+
+// WithLazyDirector configures the director of the one2many calls with the slow backend enumeration (e.g. service
+// discovery taking hundreds of milliseconds), it is consulted before the StreamDirector.
+//
+// Each backend receives the request messages already sent by the client once it is discovered, so the request
+// messages are kept until the enumeration is done. The responses of the streamed methods (see WithStreamedDetector)
+// are forwarded as they arrive, the responses of the unary methods are merged once all the backends responded.
+// The backend errors are delivered with BuildError, if no backends are discovered the call fails with the error of
+// the iterator (or the no backends error).
+//
+// The features operating on the complete set of the backends (failover, all-failed responses, progress annotations,
+// response verification and deduplication) are not applied to the lazily directed calls.
+func WithLazyDirector(director LazyDirector) Option {
+	return func(o *handlerOptions) {
+		o.lazyDirector = director
+	}
+}
+
+// requestLog keeps the request messages of the client, so they are replayed to the backends discovered later.
+type requestLog struct {
+	cond   *sync.Cond
+	failed chan struct{}
+	err    error
+	frames [][]byte
+
+	mu sync.Mutex
+}
+
+func newRequestLog() *requestLog {
+	l := &requestLog{failed: make(chan struct{})}
+	l.cond = sync.NewCond(&l.mu)
+
+	return l
+}
+
+// read reads the request messages until the client finishes.
+func (l *requestLog) read(src grpc.ServerStream) {
+	for {
+		f := &Frame{}
+		err := src.RecvMsg(f)
+
+		l.mu.Lock()
+
+		if err != nil {
+			l.err = err
+		} else {
+			l.frames = append(l.frames, f.payload)
+		}
+
+		l.cond.Broadcast()
+		l.mu.Unlock()
+
+		if err != nil {
+			if !errors.Is(err, io.EOF) {
+				close(l.failed)
+			}
+
+			return
+		}
+	}
+}
+
+// replay sends the request messages to the backend, and half-closes the backend stream once the client finishes.
+func (l *requestLog) replay(dst grpc.ClientStream) {
+	for i := 0; ; i++ {
+		l.mu.Lock()
+
+		for i >= len(l.frames) && l.err == nil {
+			l.cond.Wait()
+		}
+
+		if i >= len(l.frames) {
+			err := l.err
+			l.mu.Unlock()
+
+			if errors.Is(err, io.EOF) {
+				dst.CloseSend() //nolint:errcheck
+			}
+
+			return
+		}
+
+		payload := l.frames[i]
+		l.mu.Unlock()
+
+		if err := dst.SendMsg(NewFrame(payload)); err != nil {
+			return
+		}
+	}
+}
+
+// proxyLazy proxies the one2many call to the backends returned by the iterator.
+//
+//nolint:gocognit,gocyclo,cyclop
+func (s *handler) proxyLazy(fullMethodName string, serverStream grpc.ServerStream, iterator BackendIterator) error {
+	s.options.emitCallStarted(fullMethodName, One2Many, nil)
+
+	clientCtx, clientCancel := s.options.upstreamContext(serverStream.Context(), fullMethodName)
+	defer clientCancel()
+
+	forwarders := newForwarders(clientCtx, serverStream, clientCancel)
+	defer forwarders.finish()
+
+	dst := &ServerStreamWrapper{ServerStream: forwarders.downstream}
+	streaming := s.options.streamedDetector != nil && s.options.streamedDetector(fullMethodName)
+
+	requests := newRequestLog()
+	forwarders.goUpstream(func() { requests.read(serverStream) })
+
+	var (
+		backends []Backend
+		payloads []prioritizedPayload
+		multiErr *multierror.Error
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+	)
+
+	forward := func(src *backendConnection, index int) error {
+		priority := backendPriority(src.backend)
+
+		// deliver delivers the response (or the formatted backend error) to the client
+		deliver := func(payload []byte, isError bool) error {
+			if streaming {
+				if err := dst.SendMsg(NewFrame(payload)); err != nil {
+					return fmt.Errorf("error sending back to server from %s: %w", src.backend, err)
+				}
+
+				return nil
+			}
+
+			mu.Lock()
+			payloads = append(payloads, prioritizedPayload{priority: priority, index: index, src: src, payload: payload, isError: isError})
+			mu.Unlock()
+
+			return nil
+		}
+
+		fail := func(backendErr error) error {
+			if streaming {
+				return s.sendError(fullMethodName, src, dst, backendErr)
+			}
+
+			payload, err := s.formatError(dst.Context(), fullMethodName, false, src, backendErr)
+			if err != nil {
+				return err
+			}
+
+			return deliver(payload, true)
+		}
+
+		if src.connError != nil {
+			return fail(src.connError)
+		}
+
+		go requests.replay(src.clientStream)
+
+		for j := 0; ; j++ {
+			f := &Frame{}
+
+			if err := src.clientStream.RecvMsg(f); err != nil {
+				if errors.Is(err, io.EOF) {
+					dst.SetTrailer(src.clientStream.Trailer())
+
+					return nil
+				}
+
+				return fail(err)
+			}
+
+			if j == 0 {
+				md, err := src.clientStream.Header()
+				if err != nil {
+					return fail(err)
+				}
+
+				dst.SetHeader(md) //nolint:errcheck // ignore errors, as we might try to set headers multiple times
+			}
+
+			payload, err := appendInfo(dst.Context(), src.backend, fullMethodName, streaming, f.payload)
+			if err != nil {
+				return fmt.Errorf("error appending info for %s: %w", src.backend, err)
+			}
+
+			if err = deliver(payload, false); err != nil {
+				return err
+			}
+		}
+	}
+
+	var discoveryErr error
+
+	for {
+		backend, err := iterator.Next(clientCtx)
+		if err != nil {
+			if !errors.Is(err, io.EOF) {
+				discoveryErr = err
+			}
+
+			break
+		}
+
+		backends = append(backends, backend)
+		index := len(backends) - 1
+
+		conn := s.connect(clientCtx, serverStream.Context(), fullMethodName, backend, false)
+		s.options.emitBackendConnected(fullMethodName, &conn)
+
+		wg.Add(1)
+
+		forwarders.goDownstream(func() {
+			defer wg.Done()
+
+			if err := forward(&conn, index); err != nil {
+				mu.Lock()
+				multiErr = multierror.Append(multiErr, err)
+				mu.Unlock()
+			}
+		})
+	}
+
+	if s.options.cost != nil {
+		observeCost(serverStream.Context(), backends)
+	}
+
+	if len(backends) == 0 {
+		if discoveryErr != nil {
+			return discoveryErr
+		}
+
+		return s.options.noBackendsError(fullMethodName)
+	}
+
+	done := make(chan struct{})
+
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-requests.failed:
+		return s2cError(requests.err)
+	}
+
+	if err := multiErr.ErrorOrNil(); err != nil {
+		return err
+	}
+
+	if streaming {
+		return nil
+	}
+
+	s.options.sortPayloads(fullMethodName, payloads)
+
+	merged, err := s.options.mergeResponses(fullMethodName, payloads)
+	if err != nil {
+		return err
+	}
+
+	return dst.SendMsg(NewFrame(merged))
+}
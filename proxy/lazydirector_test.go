@@ -0,0 +1,101 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/noncepad/grpc-proxy/proxy"
+	pb "github.com/noncepad/grpc-proxy/testservice"
+)
+
+func TestLazyDirector(t *testing.T) {
+	var upstream proxy.Backend
+
+	h := newTestHarnessWithService(t, &metadataEchoService{}, func(backend proxy.Backend) proxy.StreamDirector {
+		upstream = backend
+
+		return one2oneDirector(backend)
+	},
+		proxy.WithStreamedDetector(func(fullMethodName string) bool { return fullMethodName == "/talos.testproto.TestService/PingStream" }),
+		proxy.WithLazyDirector(func(ctx context.Context, fullMethodName string) (proxy.BackendIterator, bool, error) {
+			if fullMethodName != "/talos.testproto.TestService/PingStream" {
+				return nil, false, nil
+			}
+
+			ch := make(chan proxy.Backend)
+
+			go func() {
+				defer close(ch)
+
+				for _, tag := range []string{"a", "b"} {
+					select {
+					case <-ctx.Done():
+						return
+					case ch <- &taggedBackend{Backend: upstream, tag: tag}:
+					}
+
+					// the enumeration of the next backend is slow
+					time.Sleep(200 * time.Millisecond)
+				}
+			}()
+
+			return proxy.BackendChannel(ch), true, nil
+		}),
+	)
+
+	stream, err := h.client.PingStream(testContext(t))
+	require.NoError(t, err)
+
+	start := time.Now()
+
+	require.NoError(t, stream.Send(&pb.PingRequest{Value: backendTagMdKey}))
+
+	// the first backend responds before the second one is discovered
+	resp, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, backendTagMdKey+"=a", resp.Value)
+	assert.Less(t, time.Since(start), 150*time.Millisecond)
+
+	// the second backend receives the request sent before it was discovered
+	resp, err = stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, backendTagMdKey+"=b", resp.Value)
+
+	require.NoError(t, stream.Send(&pb.PingRequest{Value: backendTagMdKey}))
+	assert.ElementsMatch(t, []string{backendTagMdKey + "=a", backendTagMdKey + "=b"}, recvValues(t, stream, 2))
+
+	require.NoError(t, stream.CloseSend())
+
+	_, err = stream.Recv()
+	require.True(t, errors.Is(err, io.EOF), "unexpected error %v", err)
+
+	// the unary methods are directed by the StreamDirector
+	pingResp, err := h.client.Ping(testContext(t), &pb.PingRequest{Value: "foo"})
+	require.NoError(t, err)
+	assert.Equal(t, "foo", pingResp.Value)
+}
+
+func TestLazyDirectorNoBackends(t *testing.T) {
+	h := newTestHarness(t, one2oneDirector,
+		proxy.WithLazyDirector(func(ctx context.Context, fullMethodName string) (proxy.BackendIterator, bool, error) {
+			ch := make(chan proxy.Backend)
+			close(ch)
+
+			return proxy.BackendChannel(ch), true, nil
+		}),
+	)
+
+	_, err := h.client.Ping(testContext(t), &pb.PingRequest{Value: "foo"})
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+}
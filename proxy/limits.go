@@ -0,0 +1,168 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc/codes"
+)
+
+// StreamLimits limits a single direction of the proxied stream.
+type StreamLimits struct {
+	// MaxMessages is the maximum number of messages, zero means no limit.
+	MaxMessages int64
+
+	// MaxDuration is the maximum duration since the start of the call until the direction is finished
+	// (client half-closes the stream for requests, backends finish the call for responses), zero means no limit.
+	MaxDuration time.Duration
+}
+
+func (l StreamLimits) isZero() bool {
+	return l.MaxMessages == 0 && l.MaxDuration == 0
+}
+
+// WithStreamLimits configures limits for the stream proxied for the method.
+//
+// Limits are enforced separately for each direction: requests (client to backends) and responses (backends to client).
+// For one2many proxying, responses from all the backends are counted together.
+//
+// If a limit is exceeded, the stream is closed with codes.ResourceExhausted (message count) or
+// codes.DeadlineExceeded (duration).
+func WithStreamLimits(fullMethodName string, requests, responses StreamLimits) Option {
+	return func(o *handlerOptions) {
+		if o.streamLimits == nil {
+			o.streamLimits = map[string][2]StreamLimits{}
+		}
+
+		o.streamLimits[fullMethodName] = [2]StreamLimits{requests, responses}
+	}
+}
+
+// streamLimits enforces limits for both directions of the stream.
+type streamLimits struct {
+	requests  *directionLimiter
+	responses *directionLimiter
+
+	// errCh receives the first error caused by exceeding the limits
+	errCh chan error
+}
+
+// directionLimiter enforces limits on a single direction.
+//
+// All methods are safe to be called on nil limiter.
+type directionLimiter struct {
+	limits    StreamLimits
+	direction string
+	count     int64
+	timer     *time.Timer
+	errCh     chan<- error
+}
+
+// newStreamLimits returns nil if no limits are configured for the method.
+func (o *handlerOptions) newStreamLimits(fullMethodName string) *streamLimits {
+	limits, ok := o.streamLimits[fullMethodName]
+	if !ok {
+		return nil
+	}
+
+	sl := &streamLimits{
+		errCh: make(chan error, 1),
+	}
+
+	sl.requests = sl.newLimiter("request", limits[0])
+	sl.responses = sl.newLimiter("response", limits[1])
+
+	return sl
+}
+
+func (sl *streamLimits) newLimiter(direction string, limits StreamLimits) *directionLimiter {
+	if limits.isZero() {
+		return nil
+	}
+
+	l := &directionLimiter{
+		limits:    limits,
+		direction: direction,
+		errCh:     sl.errCh,
+	}
+
+	if limits.MaxDuration > 0 {
+		l.timer = time.AfterFunc(limits.MaxDuration, func() {
+			l.fail(&Error{Code: codes.DeadlineExceeded, Reason: ReasonStreamLimit, Message: fmt.Sprintf("stream %s duration limit exceeded (%s)", direction, limits.MaxDuration)})
+		})
+	}
+
+	return l
+}
+
+// errors returns the channel which receives the limit errors, nil channel is returned for nil limits.
+func (sl *streamLimits) errors() <-chan error {
+	if sl == nil {
+		return nil
+	}
+
+	return sl.errCh
+}
+
+// stop stops all the timers.
+func (sl *streamLimits) stop() {
+	if sl == nil {
+		return
+	}
+
+	sl.requests.done()
+	sl.responses.done()
+}
+
+func (sl *streamLimits) requestLimiter() *directionLimiter {
+	if sl == nil {
+		return nil
+	}
+
+	return sl.requests
+}
+
+func (sl *streamLimits) responseLimiter() *directionLimiter {
+	if sl == nil {
+		return nil
+	}
+
+	return sl.responses
+}
+
+// message accounts for a message, it returns an error if the limit is exceeded.
+func (l *directionLimiter) message() error {
+	if l == nil || l.limits.MaxMessages == 0 {
+		return nil
+	}
+
+	if count := atomic.AddInt64(&l.count, 1); count > l.limits.MaxMessages {
+		err := newError(ErrStreamLimit, "stream %s message limit exceeded (%d)", l.direction, l.limits.MaxMessages)
+
+		l.fail(err)
+
+		return err
+	}
+
+	return nil
+}
+
+// done marks the direction as finished.
+func (l *directionLimiter) done() {
+	if l == nil || l.timer == nil {
+		return
+	}
+
+	l.timer.Stop()
+}
+
+func (l *directionLimiter) fail(err error) {
+	select {
+	case l.errCh <- err:
+	default:
+	}
+}
@@ -0,0 +1,95 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy_test
+
+import (
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/noncepad/grpc-proxy/proxy"
+	pb "github.com/noncepad/grpc-proxy/testservice"
+)
+
+func TestStreamLimitsRequestMessages(t *testing.T) {
+	h := newTestHarnessWithService(t, &lenientService{}, one2oneDirector,
+		proxy.WithStreamLimits("/talos.testproto.TestService/PingStream", proxy.StreamLimits{MaxMessages: 3}, proxy.StreamLimits{}))
+
+	stream, err := h.client.PingStream(testContext(t))
+	require.NoError(t, err)
+
+	for i := 0; i < 3; i++ {
+		require.NoError(t, stream.Send(&pb.PingRequest{Value: "foo"}))
+
+		_, err = stream.Recv()
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, stream.Send(&pb.PingRequest{Value: "foo"}))
+
+	_, err = stream.Recv()
+	require.Error(t, err)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+}
+
+func TestStreamLimitsResponseMessages(t *testing.T) {
+	h := newTestHarness(t, one2oneDirector,
+		proxy.WithStreamLimits("/talos.testproto.TestService/PingList", proxy.StreamLimits{}, proxy.StreamLimits{MaxMessages: 5}))
+
+	stream, err := h.client.PingList(testContext(t), &pb.PingRequest{Value: "foo"})
+	require.NoError(t, err)
+
+	received := 0
+
+	for {
+		_, err = stream.Recv()
+		if err != nil {
+			break
+		}
+
+		received++
+	}
+
+	assert.Equal(t, 5, received)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+}
+
+func TestStreamLimitsDuration(t *testing.T) {
+	h := newTestHarnessWithService(t, &lenientService{}, one2oneDirector,
+		proxy.WithStreamLimits("/talos.testproto.TestService/PingStream", proxy.StreamLimits{MaxDuration: 100 * time.Millisecond}, proxy.StreamLimits{}))
+
+	stream, err := h.client.PingStream(testContext(t))
+	require.NoError(t, err)
+
+	require.NoError(t, stream.Send(&pb.PingRequest{Value: "foo"}))
+
+	_, err = stream.Recv()
+	require.NoError(t, err)
+
+	// stream is stalled by the client
+	_, err = stream.Recv()
+	require.Error(t, err)
+	assert.Equal(t, codes.DeadlineExceeded, status.Code(err))
+
+	// a stream which finishes in time is not affected
+	stream, err = h.client.PingStream(testContext(t))
+	require.NoError(t, err)
+
+	require.NoError(t, stream.Send(&pb.PingRequest{Value: "foo"}))
+	require.NoError(t, stream.CloseSend())
+
+	_, err = stream.Recv()
+	require.NoError(t, err)
+
+	time.Sleep(150 * time.Millisecond)
+
+	_, err = stream.Recv()
+	require.True(t, errors.Is(err, io.EOF), "unexpected error %v", err)
+}
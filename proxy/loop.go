@@ -0,0 +1,80 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy
+
+import (
+	"context"
+	"strconv"
+
+	"google.golang.org/grpc/metadata"
+)
+
+// Loop detection metadata keys.
+const (
+	// HopCountMetadataKey is the number of proxies the call passed through.
+	HopCountMetadataKey = "proxy-hops"
+	// ViaMetadataKey lists the IDs of the proxies the call passed through, one value per proxy.
+	ViaMetadataKey = "proxy-via"
+)
+
+// loopDetection configures loop detection.
+type loopDetection struct {
+	proxyID string
+	maxHops int
+}
+
+// WithLoopDetection enables detection of the proxying loops caused by routing misconfiguration.
+//
+// The proxy increments the hop count and appends its proxyID to the via list in the upstream call metadata.
+// Calls which already passed through the proxy with the same proxyID, or through maxHops proxies, fail with
+// ErrLoopDetected instead of being forwarded again. proxyID should be unique for each proxy instance, and
+// maxHops zero disables the hop count limit.
+func WithLoopDetection(proxyID string, maxHops int) Option {
+	return func(o *handlerOptions) {
+		if proxyID == "" || maxHops < 0 {
+			o.invalid("loop detection should have the proxy ID and non-negative max hops")
+		}
+
+		o.loopDetection = &loopDetection{
+			proxyID: proxyID,
+			maxHops: maxHops,
+		}
+	}
+}
+
+// check verifies that the incoming call is not looping.
+func (l *loopDetection) check(ctx context.Context, fullMethodName string) error {
+	for _, via := range metadata.ValueFromIncomingContext(ctx, ViaMetadataKey) {
+		if via == l.proxyID {
+			return newError(ErrLoopDetected, "proxy loop detected for %s: call already passed through %q", fullMethodName, l.proxyID)
+		}
+	}
+
+	if hops := hopCount(ctx); l.maxHops > 0 && hops >= l.maxHops {
+		return newError(ErrLoopDetected, "proxy loop detected for %s: %d hops exceed the limit", fullMethodName, hops)
+	}
+
+	return nil
+}
+
+// outgoingMetadata records the hop in the outgoing metadata of the upstream call.
+func (l *loopDetection) outgoingMetadata(incomingCtx context.Context, md *outgoingMetadata) {
+	md.set(HopCountMetadataKey, strconv.Itoa(hopCount(incomingCtx)+1))
+	md.set(ViaMetadataKey, append(metadata.ValueFromIncomingContext(incomingCtx, ViaMetadataKey), l.proxyID)...)
+}
+
+// hopCount returns the hop count of the incoming call.
+func hopCount(ctx context.Context) int {
+	values := metadata.ValueFromIncomingContext(ctx, HopCountMetadataKey)
+	if len(values) == 0 {
+		return 0
+	}
+
+	hops, err := strconv.Atoi(values[0])
+	if err != nil || hops < 0 {
+		return 0
+	}
+
+	return hops
+}
@@ -0,0 +1,101 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/noncepad/grpc-proxy/proxy"
+	pb "github.com/noncepad/grpc-proxy/testservice"
+)
+
+func TestLoopDetectionHeaders(t *testing.T) {
+	h := newTestHarnessWithService(t, &metadataEchoService{}, one2oneDirector,
+		proxy.WithStreamedDetector(func(fullMethodName string) bool { return fullMethodName == "/talos.testproto.TestService/PingStream" }),
+		proxy.WithLoopDetection("a", 3),
+	)
+
+	for _, tt := range []struct {
+		name     string
+		md       metadata.MD
+		expected string
+		code     codes.Code
+	}{
+		{
+			name:     "first hop",
+			expected: "proxy-hops=1,proxy-via=a",
+		},
+		{
+			name:     "second hop",
+			md:       metadata.Pairs(proxy.HopCountMetadataKey, "1", proxy.ViaMetadataKey, "x"),
+			expected: "proxy-hops=2,proxy-via=x|a",
+		},
+		{
+			name: "too many hops",
+			md:   metadata.Pairs(proxy.HopCountMetadataKey, "3", proxy.ViaMetadataKey, "x"),
+			code: codes.FailedPrecondition,
+		},
+		{
+			name: "already visited",
+			md:   metadata.Pairs(proxy.HopCountMetadataKey, "1", proxy.ViaMetadataKey, "a"),
+			code: codes.FailedPrecondition,
+		},
+	} {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			stream, err := h.client.PingStream(metadata.NewOutgoingContext(testContext(t), tt.md))
+			require.NoError(t, err)
+
+			require.NoError(t, stream.Send(&pb.PingRequest{Value: proxy.HopCountMetadataKey + "," + proxy.ViaMetadataKey}))
+
+			resp, err := stream.Recv()
+			if tt.code != codes.OK {
+				assert.Equal(t, tt.code, status.Code(err))
+
+				proxyErr, ok := proxy.FromError(err)
+				require.True(t, ok)
+				assert.True(t, errors.Is(proxyErr, proxy.ErrLoopDetected))
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, resp.Value)
+		})
+	}
+}
+
+func TestLoopDetectionSelfForwarding(t *testing.T) {
+	pool := proxy.NewConnPool(grpc.WithTransportCredentials(insecure.NewCredentials()))
+	t.Cleanup(func() { pool.Close() }) //nolint: errcheck
+
+	var self proxy.Backend
+
+	h := newTestHarness(t, func(proxy.Backend) proxy.StreamDirector {
+		return func(ctx context.Context, fullMethodName string) (proxy.Mode, []proxy.Backend, error) {
+			return proxy.One2One, []proxy.Backend{self}, nil
+		}
+	}, proxy.WithLoopDetection("a", 0))
+
+	self = &proxy.DialBackend{Pool: pool, Target: h.clientConn.Target()}
+
+	_, err := h.client.Ping(testContext(t), &pb.PingRequest{Value: "foo"})
+	require.Error(t, err)
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	proxyErr, ok := proxy.FromError(err)
+	require.True(t, ok)
+	assert.True(t, errors.Is(proxyErr, proxy.ErrLoopDetected))
+}
@@ -0,0 +1,180 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// LRO (long-running operations) constants.
+const (
+	// LROStateNamespace is the prefix of the operation mappings in the ClusterState.
+	LROStateNamespace = "lro/"
+	// OperationsService is the name of the service polling the long-running operations.
+	OperationsService = "google.longrunning.Operations"
+)
+
+// LROPolicy configures recording of the long-running operations started via the proxy.
+type LROPolicy struct {
+	// State is the state the operation mappings are recorded to, see LRODirector.
+	State *ClusterState
+	// TTL of the recorded mappings, it should exceed the lifetime of the operations; zero means forever.
+	TTL time.Duration
+}
+
+// WithLongRunningOperations records the backends which started the long-running operations.
+//
+// The listed methods initiate the operations: they respond with google.longrunning.Operation, and the name
+// of the operation in the response is recorded in the state, mapped to the backend which served the call.
+// LRODirector routes the following calls of the google.longrunning.Operations service for the operation
+// to the same backend.
+func WithLongRunningOperations(policy LROPolicy, fullMethodNames ...string) Option {
+	return func(o *handlerOptions) {
+		if policy.State == nil {
+			o.invalid("long-running operations state is not set")
+		}
+
+		if o.lroMethods == nil {
+			o.lroMethods = map[string]*LROPolicy{}
+		}
+
+		for _, name := range fullMethodNames {
+			o.lroMethods[name] = &policy
+		}
+	}
+}
+
+// LRODirector pins the calls of the google.longrunning.Operations service to the backend which started
+// the operation, see WithLongRunningOperations.
+//
+// The name of the operation is read from the request, so the handler should have WithRequestPeek enabled.
+// The backends returned by the director for the calls of the operation are the candidates: the call is proxied
+// one2one to the mapped backend as long as the director still returns it (the backends are matched by String()),
+// or to the first backend otherwise. The calls of the other services are directed as is.
+func LRODirector(director StreamDirector, state *ClusterState) StreamDirector {
+	return func(ctx context.Context, fullMethodName string) (Mode, []Backend, error) {
+		mode, backends, err := director(ctx, fullMethodName)
+		if err != nil || len(backends) == 0 {
+			return mode, backends, err
+		}
+
+		backend, ok := lroBackend(ctx, state, fullMethodName)
+		if !ok {
+			return mode, backends, nil
+		}
+
+		for _, b := range backends {
+			if b.String() == backend {
+				return One2One, []Backend{b}, nil
+			}
+		}
+
+		return One2One, backends[:1], nil
+	}
+}
+
+// lroBackend returns the name of the backend which started the operation of the call, ok is set if the call is bound
+// to an operation, and the name is empty if the operation is not known.
+func lroBackend(ctx context.Context, state *ClusterState, fullMethodName string) (backend string, ok bool) {
+	service, method, valid := splitMethodName(fullMethodName)
+	if !valid || service != OperationsService {
+		return "", false
+	}
+
+	switch method {
+	case "GetOperation", "WaitOperation", "CancelOperation", "DeleteOperation":
+	default:
+		// ListOperations is not bound to a single operation
+		return "", false
+	}
+
+	payload, peeked := RequestFrameFromContext(ctx)
+	if !peeked {
+		return "", false
+	}
+
+	name := operationName(payload)
+	if name == "" {
+		return "", false
+	}
+
+	backend, _ = state.Get(LROStateNamespace + name)
+
+	return backend, true
+}
+
+// operationName returns the name of the operation: the field 1 of google.longrunning.Operation,
+// as well as of the requests of google.longrunning.Operations.
+func operationName(payload []byte) string {
+	var name string
+
+	for len(payload) > 0 {
+		num, typ, n := protowire.ConsumeTag(payload)
+		if n < 0 {
+			return ""
+		}
+
+		payload = payload[n:]
+
+		if num == 1 && typ == protowire.BytesType {
+			value, m := protowire.ConsumeBytes(payload)
+			if m < 0 {
+				return ""
+			}
+
+			// the last value wins, as with the merged messages
+			name = string(value)
+			payload = payload[m:]
+
+			continue
+		}
+
+		n = protowire.ConsumeFieldValue(num, typ, payload)
+		if n < 0 {
+			return ""
+		}
+
+		payload = payload[n:]
+	}
+
+	return name
+}
+
+// wrapLRO wraps the upstream stream of the method initiating the long-running operations to record the operations.
+func (o *handlerOptions) wrapLRO(clientStream grpc.ClientStream, backend Backend, fullMethodName string) grpc.ClientStream {
+	policy := o.lroMethods[fullMethodName]
+	if policy == nil {
+		return clientStream
+	}
+
+	return &lroClientStream{
+		ClientStream: clientStream,
+		policy:       policy,
+		backend:      backend.String(),
+	}
+}
+
+// lroClientStream records the operations of the responses.
+type lroClientStream struct {
+	grpc.ClientStream
+
+	policy  *LROPolicy
+	backend string
+}
+
+func (s *lroClientStream) RecvMsg(m interface{}) error {
+	err := s.ClientStream.RecvMsg(m)
+
+	if f, ok := m.(*Frame); ok && err == nil {
+		if name := operationName(f.payload); name != "" {
+			s.policy.State.Set(LROStateNamespace+name, s.backend, s.policy.TTL)
+		}
+	}
+
+	return err
+}
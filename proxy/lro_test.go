@@ -0,0 +1,83 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/noncepad/grpc-proxy/proxy"
+	pb "github.com/noncepad/grpc-proxy/testservice"
+)
+
+func TestLongRunningOperations(t *testing.T) {
+	const method = "/talos.testproto.TestService/Ping"
+
+	state := proxy.NewClusterState("node1")
+
+	var (
+		mu       sync.Mutex
+		directed string
+	)
+
+	director := func(backend proxy.Backend) proxy.StreamDirector {
+		a, b := &taggedBackend{Backend: backend, tag: "a"}, &taggedBackend{Backend: backend, tag: "b"}
+
+		lro := proxy.LRODirector(func(ctx context.Context, fullMethodName string) (proxy.Mode, []proxy.Backend, error) {
+			if fullMethodName == method {
+				// operations are started on the backend "b"
+				return proxy.One2One, []proxy.Backend{b}, nil
+			}
+
+			return proxy.One2One, []proxy.Backend{a, b}, nil
+		}, state)
+
+		return func(ctx context.Context, fullMethodName string) (proxy.Mode, []proxy.Backend, error) {
+			mode, backends, err := lro(ctx, fullMethodName)
+
+			mu.Lock()
+			directed = backends[0].String()
+			mu.Unlock()
+
+			return mode, backends, err
+		}
+	}
+
+	h := newTestHarness(t, director,
+		proxy.WithRequestPeek(),
+		proxy.WithLongRunningOperations(proxy.LROPolicy{State: state, TTL: time.Minute}, method),
+	)
+
+	// the response of Ping has the same wire format as google.longrunning.Operation: the name is the field 1
+	_, err := h.client.Ping(testContext(t), &pb.PingRequest{Value: "operations/1"})
+	require.NoError(t, err)
+
+	backend, ok := state.Get(proxy.LROStateNamespace + "operations/1")
+	require.True(t, ok)
+	assert.Equal(t, "b", backend)
+
+	for _, tt := range []struct {
+		name     string
+		expected string
+	}{
+		{name: "operations/1", expected: "b"},
+		{name: "operations/2", expected: "a"},
+	} {
+		// the request of GetOperation has the name in the field 1 as well
+		err = h.clientConn.Invoke(testContext(t), "/google.longrunning.Operations/GetOperation",
+			&pb.PingRequest{Value: tt.name}, &pb.PingResponse{})
+		assert.Equal(t, codes.Unimplemented, status.Code(err))
+
+		mu.Lock()
+		assert.Equal(t, tt.expected, directed, tt.name)
+		mu.Unlock()
+	}
+}
@@ -0,0 +1,154 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy
+
+import (
+	"context"
+	"math/rand"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+// MessageDirection is the direction of the message over the upstream leg.
+type MessageDirection string
+
+// MessageDirection constants.
+const (
+	MessageSent     MessageDirection = "SENT"
+	MessageReceived MessageDirection = "RECEIVED"
+)
+
+// MessageEvent describes the message exchanged with the backend over the upstream leg.
+type MessageEvent struct {
+	Time      time.Time
+	Backend   string
+	Method    string
+	Direction MessageDirection
+	// ID is the sequence number of the message in its direction, starting with 1.
+	ID uint64
+	// Size is the size of the message payload.
+	Size int
+	// Elapsed is the time since the start of the upstream leg.
+	Elapsed time.Duration
+}
+
+// MessageEventRecorder records the message event of the upstream leg.
+//
+// The context is the context of the upstream leg: with the OpenTelemetry stats handler configured via WithStatsHandler,
+// it carries the span of the leg, so the recorder can add the event to the span:
+//
+//	trace.SpanFromContext(ctx).AddEvent("message", trace.WithTimestamp(event.Time), trace.WithAttributes(
+//		attribute.String("message.type", string(event.Direction)),
+//		attribute.Int64("message.id", int64(event.ID)),
+//		attribute.Int("message.uncompressed_size", event.Size),
+//	))
+//
+// The recorder is called synchronously on the proxying path, so it should not block.
+type MessageEventRecorder func(ctx context.Context, event MessageEvent)
+
+// MessageEventsPolicy configures the message-level events of the upstream legs.
+type MessageEventsPolicy struct {
+	Recorder MessageEventRecorder
+	// SampleRate is the fraction of the upstream legs which record the message events, zero means all legs.
+	SampleRate float64
+	// MaxEvents limits the number of events recorded per leg (as the tracing backends limit the number of the span
+	// events), zero means no limit.
+	MaxEvents int
+}
+
+// WithMessageEvents records the message-level events of the sampled upstream legs, so that it can be seen where
+// the latency accrues inside the long proxied streams.
+func WithMessageEvents(policy MessageEventsPolicy) Option {
+	return func(o *handlerOptions) {
+		if policy.Recorder == nil {
+			o.messageEvents = nil
+
+			return
+		}
+
+		o.messageEvents = &policy
+	}
+}
+
+// wrapMessageEvents wraps the upstream stream of the sampled leg to record the message events.
+func (o *handlerOptions) wrapMessageEvents(ctx context.Context, clientStream grpc.ClientStream, backend Backend, fullMethodName string) grpc.ClientStream {
+	policy := o.messageEvents
+	if policy == nil {
+		return clientStream
+	}
+
+	//nolint:gosec // sampling doesn't need a secure random source
+	if policy.SampleRate > 0 && policy.SampleRate < 1 && rand.Float64() >= policy.SampleRate {
+		return clientStream
+	}
+
+	return &messageEventsClientStream{
+		ClientStream: clientStream,
+		ctx:          ctx,
+		policy:       policy,
+		backend:      backend.String(),
+		method:       fullMethodName,
+		start:        time.Now(),
+	}
+}
+
+// messageEventsClientStream records the message events of the upstream leg.
+//
+// SendMsg and RecvMsg are called from the different goroutines, but each of them from a single one, so the counters
+// of each direction are not shared.
+type messageEventsClientStream struct {
+	grpc.ClientStream
+
+	ctx     context.Context //nolint:containedctx
+	policy  *MessageEventsPolicy
+	backend string
+	method  string
+	start   time.Time
+
+	sent     uint64
+	received uint64
+	recorded int64
+}
+
+func (s *messageEventsClientStream) SendMsg(m interface{}) error {
+	err := s.ClientStream.SendMsg(m)
+
+	if f, ok := m.(*Frame); ok && err == nil {
+		s.sent++
+		s.record(MessageSent, s.sent, f.Size())
+	}
+
+	return err
+}
+
+func (s *messageEventsClientStream) RecvMsg(m interface{}) error {
+	err := s.ClientStream.RecvMsg(m)
+
+	if f, ok := m.(*Frame); ok && err == nil {
+		s.received++
+		s.record(MessageReceived, s.received, f.Size())
+	}
+
+	return err
+}
+
+func (s *messageEventsClientStream) record(direction MessageDirection, id uint64, size int) {
+	if s.policy.MaxEvents > 0 && atomic.AddInt64(&s.recorded, 1) > int64(s.policy.MaxEvents) {
+		return
+	}
+
+	now := time.Now()
+
+	s.policy.Recorder(s.ctx, MessageEvent{
+		Time:      now,
+		Backend:   s.backend,
+		Method:    s.method,
+		Direction: direction,
+		ID:        id,
+		Size:      size,
+		Elapsed:   now.Sub(s.start),
+	})
+}
@@ -0,0 +1,97 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/noncepad/grpc-proxy/proxy"
+	pb "github.com/noncepad/grpc-proxy/testservice"
+)
+
+type messageEventsRecorder struct {
+	mu     sync.Mutex
+	events []proxy.MessageEvent
+	legs   []string
+}
+
+func (r *messageEventsRecorder) record(ctx context.Context, event proxy.MessageEvent) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	leg, _ := ctx.Value(statsLegKey{}).(string)
+
+	r.events = append(r.events, event)
+	r.legs = append(r.legs, leg)
+}
+
+func TestMessageEvents(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		policy   proxy.MessageEventsPolicy
+		expected int
+	}{
+		{name: "all", expected: 6},
+		{name: "limited", policy: proxy.MessageEventsPolicy{MaxEvents: 4}, expected: 4},
+		{name: "sampled out", policy: proxy.MessageEventsPolicy{SampleRate: 1e-12}, expected: 0},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := &messageEventsRecorder{}
+
+			policy := tt.policy
+			policy.Recorder = recorder.record
+
+			h := newTestHarness(t, one2oneDirector,
+				proxy.WithMessageEvents(policy),
+				proxy.WithStatsHandler(&recordingStatsHandler{events: map[string][]string{}}),
+			)
+			defer h.stop()
+
+			stream, err := h.client.PingStream(testContext(t))
+			require.NoError(t, err)
+
+			for _, value := range []string{"a", "bb", "ccc"} {
+				require.NoError(t, stream.Send(&pb.PingRequest{Value: value}))
+
+				_, err = stream.Recv()
+				require.NoError(t, err)
+			}
+
+			require.NoError(t, stream.CloseSend())
+
+			_, err = stream.Recv()
+			require.True(t, errors.Is(err, io.EOF), "%v", err)
+
+			recorder.mu.Lock()
+			defer recorder.mu.Unlock()
+
+			require.Len(t, recorder.events, tt.expected)
+
+			var sent, received uint64
+
+			for i, event := range recorder.events {
+				assert.Equal(t, "/talos.testproto.TestService/PingStream", event.Method)
+				assert.NotEmpty(t, event.Backend)
+				assert.Positive(t, event.Size)
+				assert.Equal(t, "client", recorder.legs[i])
+
+				switch event.Direction {
+				case proxy.MessageSent:
+					sent++
+					assert.Equal(t, sent, event.ID)
+				case proxy.MessageReceived:
+					received++
+					assert.Equal(t, received, event.ID)
+				}
+			}
+		})
+	}
+}
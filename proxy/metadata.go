@@ -0,0 +1,110 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy
+
+import (
+	"context"
+	"strings"
+
+	"google.golang.org/grpc/metadata"
+)
+
+// outgoingMetadata edits the outgoing metadata of the upstream call with copy-on-write semantics.
+//
+// The metadata attached to the context returned by the backend might be shared (e.g. with the other legs of
+// one2many call), so it is never modified in place: the map is copied on the first modification, and the values
+// are replaced rather than appended to. If nothing is modified, the context is passed to the upstream call as is,
+// without copying the metadata.
+type outgoingMetadata struct {
+	ctx   context.Context //nolint:containedctx
+	md    metadata.MD
+	owned bool
+}
+
+func editOutgoingMetadata(ctx context.Context) outgoingMetadata {
+	md, added, _ := metadata.FromOutgoingContextRaw(ctx)
+	if len(added) > 0 {
+		// merge the pairs appended with metadata.AppendToOutgoingContext
+		md, _ = metadata.FromOutgoingContext(ctx)
+
+		return outgoingMetadata{ctx: ctx, md: md, owned: true}
+	}
+
+	return outgoingMetadata{ctx: ctx, md: md}
+}
+
+// own copies the map before the first modification.
+func (m *outgoingMetadata) own() {
+	if m.owned {
+		return
+	}
+
+	md := make(metadata.MD, len(m.md)+2)
+
+	for k, v := range m.md {
+		md[strings.ToLower(k)] = v
+	}
+
+	m.md = md
+	m.owned = true
+}
+
+func (m *outgoingMetadata) set(key string, values ...string) {
+	m.own()
+
+	m.md[strings.ToLower(key)] = values
+}
+
+func (m *outgoingMetadata) append(key string, values ...string) {
+	if len(values) == 0 {
+		return
+	}
+
+	m.own()
+
+	key = strings.ToLower(key)
+	existing := m.md[key]
+
+	merged := make([]string, 0, len(existing)+len(values))
+	merged = append(merged, existing...)
+	merged = append(merged, values...)
+
+	m.md[key] = merged
+}
+
+func (m *outgoingMetadata) delete(key string) {
+	key = strings.ToLower(key)
+
+	if !m.owned && !m.has(key) {
+		return
+	}
+
+	m.own()
+
+	delete(m.md, key)
+}
+
+// has checks whether the key is present, the keys of the shared metadata might be not normalized.
+func (m *outgoingMetadata) has(key string) bool {
+	if _, ok := m.md[key]; ok {
+		return true
+	}
+
+	for k := range m.md {
+		if strings.EqualFold(k, key) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// context returns the outgoing context with the edited metadata.
+func (m *outgoingMetadata) context() context.Context {
+	if !m.owned {
+		return m.ctx
+	}
+
+	return metadata.NewOutgoingContext(m.ctx, m.md)
+}
@@ -0,0 +1,313 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// MetadataAllowlistPolicy lists the metadata keys forwarded across the proxy, all other keys are dropped.
+//
+// The keys are matched case-insensitively, the key with the "*" suffix matches the keys with the prefix,
+// e.g. "x-tenant-*". Reserved entries (pseudo-headers, "grpc-" prefixed keys, content-type, user-agent and te)
+// are always forwarded.
+type MetadataAllowlistPolicy struct {
+	// Request are the keys of the client metadata forwarded to the backends.
+	Request []string
+	// Response are the keys of the backend headers and trailers forwarded to the client.
+	Response []string
+	// Observer is called with the keys dropped, if set, see MetadataDropCounter.
+	Observer func(event MetadataDropEvent)
+}
+
+// MetadataDropEvent describes the metadata keys dropped by the allowlist.
+type MetadataDropEvent struct {
+	Method  string
+	Backend string
+	// Direction is "request" or "response".
+	Direction string
+	Keys      []string
+}
+
+// WithMetadataAllowlist enables the compliance mode for the listed methods (all methods if none listed): only
+// the allowlisted metadata keys are forwarded to the backends and returned to the client, so that the metadata
+// doesn't leak when the proxy crosses the trust boundaries (data minimization).
+//
+// The allowlist applies to the metadata which crosses the proxy: the client metadata forwarded by the backend
+// (see Backend.GetConnection), including the calls mirrored to the canary (see WithShadowTraffic), and the headers
+// and the trailers of the backends. The metadata added by the proxy itself (e.g. WithBackendMetadata, the loop
+// detection and the identity propagation) is not filtered.
+func WithMetadataAllowlist(policy MetadataAllowlistPolicy, fullMethodNames ...string) Option {
+	allowlist := &metadataAllowlist{
+		request:  newMetadataKeyMatcher(policy.Request),
+		response: newMetadataKeyMatcher(policy.Response),
+		observer: policy.Observer,
+	}
+
+	return func(o *handlerOptions) {
+		if o.metadataAllowlists == nil {
+			o.metadataAllowlists = map[string]*metadataAllowlist{}
+		}
+
+		if len(fullMethodNames) == 0 {
+			fullMethodNames = []string{""}
+		}
+
+		for _, name := range fullMethodNames {
+			o.metadataAllowlists[name] = allowlist
+		}
+	}
+}
+
+// metadataAllowlist is the compiled MetadataAllowlistPolicy.
+type metadataAllowlist struct {
+	request  metadataKeyMatcher
+	response metadataKeyMatcher
+	observer func(event MetadataDropEvent)
+}
+
+// metadataKeyMatcher matches the keys against the allowlist.
+type metadataKeyMatcher struct {
+	keys     map[string]struct{}
+	prefixes []string
+}
+
+func newMetadataKeyMatcher(keys []string) metadataKeyMatcher {
+	m := metadataKeyMatcher{keys: map[string]struct{}{}}
+
+	for _, key := range keys {
+		key = strings.ToLower(key)
+
+		if strings.HasSuffix(key, "*") {
+			m.prefixes = append(m.prefixes, strings.TrimSuffix(key, "*"))
+		} else {
+			m.keys[key] = struct{}{}
+		}
+	}
+
+	return m
+}
+
+func (m metadataKeyMatcher) allowed(key string) bool {
+	key = strings.ToLower(key)
+
+	if isReservedMetadataKey(key) {
+		return true
+	}
+
+	if _, ok := m.keys[key]; ok {
+		return true
+	}
+
+	for _, prefix := range m.prefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// filter returns the metadata without the keys which are not allowed, and the dropped keys.
+func (m metadataKeyMatcher) filter(md metadata.MD) (metadata.MD, []string) {
+	var dropped []string
+
+	for key := range md {
+		if !m.allowed(key) {
+			dropped = append(dropped, strings.ToLower(key))
+		}
+	}
+
+	if len(dropped) == 0 {
+		return md, nil
+	}
+
+	sort.Strings(dropped)
+
+	filtered := make(metadata.MD, len(md)-len(dropped))
+
+	for key, values := range md {
+		if m.allowed(key) {
+			filtered[key] = values
+		}
+	}
+
+	return filtered, dropped
+}
+
+// metadataAllowlist returns the allowlist of the method, if any.
+func (o *handlerOptions) metadataAllowlist(fullMethodName string) *metadataAllowlist {
+	if allowlist, ok := o.metadataAllowlists[fullMethodName]; ok {
+		return allowlist
+	}
+
+	return o.metadataAllowlists[""]
+}
+
+// observe reports the dropped keys.
+func (a *metadataAllowlist) observe(fullMethodName string, backend Backend, direction string, dropped []string) {
+	if a.observer == nil || len(dropped) == 0 {
+		return
+	}
+
+	a.observer(MetadataDropEvent{
+		Method:    fullMethodName,
+		Backend:   backend.String(),
+		Direction: direction,
+		Keys:      dropped,
+	})
+}
+
+// allowlistOutgoingMetadata drops the keys of the outgoing metadata which are not allowed.
+func (o *handlerOptions) allowlistOutgoingMetadata(fullMethodName string, backend Backend, md *outgoingMetadata) {
+	allowlist := o.metadataAllowlist(fullMethodName)
+	if allowlist == nil {
+		return
+	}
+
+	var dropped []string
+
+	for key := range md.md {
+		if !allowlist.request.allowed(key) {
+			dropped = append(dropped, strings.ToLower(key))
+		}
+	}
+
+	sort.Strings(dropped)
+
+	for _, key := range dropped {
+		md.delete(key)
+	}
+
+	allowlist.observe(fullMethodName, backend, "request", dropped)
+}
+
+// wrapMetadataAllowlist wraps the upstream stream to drop the keys of the headers and the trailers which are not allowed.
+func (o *handlerOptions) wrapMetadataAllowlist(clientStream grpc.ClientStream, backend Backend, fullMethodName string) grpc.ClientStream {
+	allowlist := o.metadataAllowlist(fullMethodName)
+	if allowlist == nil {
+		return clientStream
+	}
+
+	return &allowlistClientStream{
+		ClientStream: clientStream,
+		allowlist:    allowlist,
+		backend:      backend,
+		method:       fullMethodName,
+	}
+}
+
+// allowlistClientStream filters the headers and the trailers of the backend.
+type allowlistClientStream struct {
+	grpc.ClientStream
+
+	allowlist *metadataAllowlist
+	backend   Backend
+	method    string
+
+	headerOnce, trailerOnce sync.Once
+	header, trailer         metadata.MD
+	headerErr               error
+}
+
+func (s *allowlistClientStream) Header() (metadata.MD, error) {
+	s.headerOnce.Do(func() {
+		var md metadata.MD
+
+		md, s.headerErr = s.ClientStream.Header()
+		s.header = s.filter(md)
+	})
+
+	return s.header, s.headerErr
+}
+
+func (s *allowlistClientStream) Trailer() metadata.MD {
+	s.trailerOnce.Do(func() {
+		s.trailer = s.filter(s.ClientStream.Trailer())
+	})
+
+	return s.trailer
+}
+
+func (s *allowlistClientStream) filter(md metadata.MD) metadata.MD {
+	filtered, dropped := s.allowlist.response.filter(md)
+
+	s.allowlist.observe(s.method, s.backend, "response", dropped)
+
+	return filtered
+}
+
+// MetadataDropCounter counts the metadata keys dropped by the allowlist, so that the compliance of the clients
+// and the backends can be audited. Observe is the MetadataAllowlistPolicy.Observer.
+//
+// MetadataDropCounter implements http.Handler which serves the counts as JSON, so that it can be mounted
+// on the admin HTTP server.
+type MetadataDropCounter struct {
+	mu     sync.Mutex
+	counts map[MetadataDropCount]uint64
+}
+
+// MetadataDropCount is the number of the calls of the method which dropped the key in the direction.
+type MetadataDropCount struct {
+	Method    string `json:"method"`
+	Direction string `json:"direction"`
+	Key       string `json:"key"`
+	Count     uint64 `json:"count"`
+}
+
+// Observe counts the dropped keys.
+func (c *MetadataDropCounter) Observe(event MetadataDropEvent) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.counts == nil {
+		c.counts = map[MetadataDropCount]uint64{}
+	}
+
+	for _, key := range event.Keys {
+		c.counts[MetadataDropCount{Method: event.Method, Direction: event.Direction, Key: key}]++
+	}
+}
+
+// Counts returns the counts sorted by the method, the direction and the key.
+func (c *MetadataDropCounter) Counts() []MetadataDropCount {
+	c.mu.Lock()
+
+	counts := make([]MetadataDropCount, 0, len(c.counts))
+
+	for count, n := range c.counts {
+		count.Count = n
+		counts = append(counts, count)
+	}
+
+	c.mu.Unlock()
+
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Method != counts[j].Method {
+			return counts[i].Method < counts[j].Method
+		}
+
+		if counts[i].Direction != counts[j].Direction {
+			return counts[i].Direction < counts[j].Direction
+		}
+
+		return counts[i].Key < counts[j].Key
+	})
+
+	return counts
+}
+
+// ServeHTTP implements http.Handler.
+func (c *MetadataDropCounter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	json.NewEncoder(w).Encode(c.Counts()) //nolint:errcheck
+}
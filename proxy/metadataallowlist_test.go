@@ -0,0 +1,164 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/noncepad/grpc-proxy/proxy"
+	pb "github.com/noncepad/grpc-proxy/testservice"
+)
+
+func TestMetadataAllowlist(t *testing.T) {
+	const (
+		ping       = "/talos.testproto.TestService/Ping"
+		pingStream = "/talos.testproto.TestService/PingStream"
+	)
+
+	var counter proxy.MetadataDropCounter
+
+	h := newTestHarnessWithService(t, &metadataEchoService{}, one2oneDirector,
+		proxy.WithMetadataAllowlist(proxy.MetadataAllowlistPolicy{
+			Request:  []string{clientMdKey, "X-Tenant-*"},
+			Response: []string{serverHeaderMdKey},
+			Observer: counter.Observe,
+		}, ping, pingStream),
+	)
+
+	ctx := metadata.AppendToOutgoingContext(testContext(t),
+		"x-tenant-id", "acme",
+		"x-secret", "s3cr3t",
+	)
+
+	stream, err := h.client.PingStream(ctx)
+	require.NoError(t, err)
+
+	require.NoError(t, stream.Send(&pb.PingRequest{Value: "x-tenant-id,x-secret," + clientMdKey}))
+
+	resp, err := stream.Recv()
+	require.NoError(t, err)
+
+	assert.Equal(t, "x-tenant-id=acme,x-secret=,"+clientMdKey+"=true", resp.Value)
+
+	require.NoError(t, stream.CloseSend())
+
+	var header, trailer metadata.MD
+
+	_, err = h.client.Ping(ctx, &pb.PingRequest{Value: "foo"}, grpc.Header(&header), grpc.Trailer(&trailer))
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"I like turtles."}, header.Get(serverHeaderMdKey))
+	assert.Empty(t, trailer.Get(serverTrailerMdKey))
+
+	assert.Equal(t, []proxy.MetadataDropCount{
+		{Method: ping, Direction: "request", Key: "x-secret", Count: 1},
+		{Method: ping, Direction: "response", Key: serverTrailerMdKey, Count: 1},
+		{Method: pingStream, Direction: "request", Key: "x-secret", Count: 1},
+	}, counter.Counts())
+}
+
+func TestMetadataAllowlistUnlistedMethod(t *testing.T) {
+	h := newTestHarnessWithService(t, &metadataEchoService{}, one2oneDirector,
+		proxy.WithMetadataAllowlist(proxy.MetadataAllowlistPolicy{}, "/talos.testproto.TestService/Ping"),
+	)
+
+	stream, err := h.client.PingStream(metadata.AppendToOutgoingContext(testContext(t), "x-secret", "s3cr3t"))
+	require.NoError(t, err)
+
+	require.NoError(t, stream.Send(&pb.PingRequest{Value: "x-secret"}))
+
+	resp, err := stream.Recv()
+	require.NoError(t, err)
+
+	assert.Equal(t, "x-secret=s3cr3t", resp.Value)
+
+	require.NoError(t, stream.CloseSend())
+}
+
+// metadataRecordingService records the metadata of Ping calls.
+type metadataRecordingService struct {
+	assertingService
+
+	calls chan metadata.MD
+}
+
+func (s *metadataRecordingService) Ping(ctx context.Context, ping *pb.PingRequest) (*pb.PingResponse, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	s.calls <- md
+
+	return &pb.PingResponse{Value: ping.Value}, nil
+}
+
+func TestMetadataAllowlistShadow(t *testing.T) {
+	const ping = "/talos.testproto.TestService/Ping"
+
+	pool := proxy.NewConnPool(grpc.WithTransportCredentials(insecure.NewCredentials()))
+	t.Cleanup(func() { pool.Close() }) //nolint: errcheck
+
+	canaryService := &metadataRecordingService{calls: make(chan metadata.MD, 1)}
+	canary := newTestHarnessWithService(t, canaryService, one2oneDirector)
+
+	var (
+		mu     sync.Mutex
+		events []proxy.MetadataDropEvent
+	)
+
+	h := newTestHarness(t, one2oneDirector,
+		proxy.WithMetadataAllowlist(proxy.MetadataAllowlistPolicy{
+			Request: []string{clientMdKey},
+			Observer: func(event proxy.MetadataDropEvent) {
+				mu.Lock()
+				events = append(events, event)
+				mu.Unlock()
+			},
+		}, ping),
+		proxy.WithShadowTraffic(proxy.ShadowPolicy{
+			Canary: &proxy.DialBackend{Pool: pool, Target: canary.backendAddr},
+			Weight: 1,
+		}, ping),
+	)
+
+	_, err := h.client.Ping(metadata.AppendToOutgoingContext(testContext(t), "x-secret", "s3cr3t"), &pb.PingRequest{Value: "foo"})
+	require.NoError(t, err)
+
+	// the mirrored call gets the same filtering as the primary one
+	select {
+	case md := <-canaryService.calls:
+		assert.Empty(t, md.Get("x-secret"))
+		assert.Equal(t, []string{"true"}, md.Get(clientMdKey))
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "canary call was not made")
+	}
+
+	requestDrops := func() map[string][]string {
+		mu.Lock()
+		defer mu.Unlock()
+
+		drops := map[string][]string{}
+
+		for _, event := range events {
+			if event.Direction == "request" {
+				drops[event.Backend] = event.Keys
+			}
+		}
+
+		return drops
+	}
+
+	// the drops are reported for both the primary backend and the canary
+	require.Eventually(t, func() bool { return len(requestDrops()) == 2 }, 5*time.Second, 10*time.Millisecond)
+
+	for backend, keys := range requestDrops() {
+		assert.Equal(t, []string{"x-secret"}, keys, backend)
+	}
+}
@@ -0,0 +1,299 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// MetadataLimitAction is the action taken on the metadata over the limit.
+type MetadataLimitAction int
+
+// MetadataLimitAction constants.
+const (
+	// MetadataReject fails the call with ErrMetadataTooLarge.
+	MetadataReject MetadataLimitAction = iota
+	// MetadataTruncate drops the largest entries until the metadata fits the limit.
+	MetadataTruncate
+)
+
+// metadataEntryOverhead is the per-entry overhead of the header, as accounted by HTTP/2 (RFC 7540, section 6.5.2).
+const metadataEntryOverhead = 32
+
+// MetadataLimit limits the size of the metadata in a single direction.
+//
+// The size is the sum of the key and value lengths of the entries plus 32 bytes per entry, as accounted by HTTP/2.
+// The binary values (keys with the "-bin" suffix) are counted with their base64-encoded length on the wire.
+// Reserved entries (pseudo-headers, "grpc-" prefixed keys, content-type, user-agent and te) are neither counted
+// nor dropped.
+type MetadataLimit struct {
+	// MaxSize is the maximum size of the metadata, zero means no limit.
+	MaxSize int
+	Action  MetadataLimitAction
+}
+
+// MetadataLimitEvent describes the metadata over the limit.
+type MetadataLimitEvent struct {
+	Method string
+	// Direction is "request" or "response".
+	Direction string
+	Size      int
+	Limit     int
+	Action    MetadataLimitAction
+	// Dropped are the keys dropped by the truncation.
+	Dropped []string
+}
+
+// MetadataLimitsPolicy limits the size of the metadata forwarded in each direction.
+type MetadataLimitsPolicy struct {
+	// Request limits the client metadata forwarded to the backends, it is enforced before the director is invoked,
+	// so the oversized metadata is never amplified to the one2many backends.
+	Request MetadataLimit
+	// Response limits the headers and the trailers forwarded to the client (each counted separately), for one2many
+	// proxying the metadata of all the backends is counted together. Rejected headers fail the call, rejected trailers
+	// are dropped, as the call has already been answered.
+	Response MetadataLimit
+	// Observer is called for the metadata over the limit, if set.
+	Observer func(event MetadataLimitEvent)
+}
+
+// WithMetadataLimits limits the size of the metadata forwarded in each direction.
+//
+// The metadata over the limit is reported to the observer of the policy and, if WithEvents is configured,
+// as EventMetadataLimited.
+func WithMetadataLimits(policy MetadataLimitsPolicy) Option {
+	return func(o *handlerOptions) {
+		o.metadataLimits = &policy
+	}
+}
+
+// isReservedMetadataKey checks whether the metadata key is managed by gRPC itself.
+func isReservedMetadataKey(key string) bool {
+	switch key {
+	case "content-type", "user-agent", "te":
+		return true
+	}
+
+	return strings.HasPrefix(key, ":") || strings.HasPrefix(key, "grpc-")
+}
+
+// metadataSize returns the size of the metadata as accounted by the limits.
+func metadataSize(md metadata.MD) int {
+	size := 0
+
+	for key, values := range md {
+		if isReservedMetadataKey(key) {
+			continue
+		}
+
+		for _, value := range values {
+			size += metadataEntrySize(key, value)
+		}
+	}
+
+	return size
+}
+
+// truncateMetadata drops the largest keys until the metadata fits the size, it returns the dropped keys.
+func truncateMetadata(md metadata.MD, maxSize int) (metadata.MD, []string) {
+	type keySize struct {
+		key  string
+		size int
+	}
+
+	var (
+		keys []keySize
+		size int
+	)
+
+	for key, values := range md {
+		if isReservedMetadataKey(key) {
+			continue
+		}
+
+		entry := keySize{key: key}
+
+		for _, value := range values {
+			entry.size += metadataEntrySize(key, value)
+		}
+
+		keys = append(keys, entry)
+		size += entry.size
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].size != keys[j].size {
+			return keys[i].size > keys[j].size
+		}
+
+		return keys[i].key < keys[j].key
+	})
+
+	md = md.Copy()
+
+	var dropped []string
+
+	for _, entry := range keys {
+		if size <= maxSize {
+			break
+		}
+
+		delete(md, entry.key)
+		dropped = append(dropped, entry.key)
+		size -= entry.size
+	}
+
+	return md, dropped
+}
+
+// enforce applies the limit to the metadata, the budget is the size left.
+func (l MetadataLimit) enforce(md metadata.MD, budget int) (metadata.MD, *MetadataLimitEvent) {
+	size := metadataSize(md)
+	if l.MaxSize == 0 || size <= budget {
+		return md, nil
+	}
+
+	event := &MetadataLimitEvent{
+		Size:   size + l.MaxSize - budget,
+		Limit:  l.MaxSize,
+		Action: l.Action,
+	}
+
+	if l.Action == MetadataTruncate {
+		md, event.Dropped = truncateMetadata(md, budget)
+	}
+
+	return md, event
+}
+
+// observeMetadataLimit reports the metadata over the limit.
+func (o *handlerOptions) observeMetadataLimit(event *MetadataLimitEvent, fullMethodName, direction string) {
+	event.Method = fullMethodName
+	event.Direction = direction
+
+	if o.metadataLimits.Observer != nil {
+		o.metadataLimits.Observer(*event)
+	}
+
+	if o.events != nil {
+		o.events.Emit(Event{
+			Time:   time.Now(),
+			Type:   EventMetadataLimited,
+			Method: fullMethodName,
+			Error:  event.String(),
+		})
+	}
+}
+
+func (e MetadataLimitEvent) String() string {
+	action := "rejected"
+	if e.Action == MetadataTruncate {
+		action = fmt.Sprintf("truncated (dropped %s)", strings.Join(e.Dropped, ", "))
+	}
+
+	return fmt.Sprintf("%s metadata of %d bytes over the limit of %d bytes %s", e.Direction, e.Size, e.Limit, action)
+}
+
+// limitMetadata enforces the metadata limits of the call, it returns the server stream with the limited metadata.
+func (o *handlerOptions) limitMetadata(serverStream grpc.ServerStream, fullMethodName string) (grpc.ServerStream, error) {
+	policy := o.metadataLimits
+	ctx := serverStream.Context()
+
+	if policy.Request.MaxSize > 0 {
+		md, _ := metadata.FromIncomingContext(ctx)
+
+		limited, event := policy.Request.enforce(md, policy.Request.MaxSize)
+		if event != nil {
+			o.observeMetadataLimit(event, fullMethodName, "request")
+
+			if event.Action == MetadataReject {
+				return nil, newError(ErrMetadataTooLarge, "%s", event)
+			}
+
+			ctx = metadata.NewIncomingContext(ctx, limited)
+			serverStream = &contextServerStream{ServerStream: serverStream, ctx: ctx}
+		}
+	}
+
+	if policy.Response.MaxSize > 0 {
+		serverStream = &metadataLimitedServerStream{
+			ServerStream:  serverStream,
+			options:       o,
+			method:        fullMethodName,
+			headerBudget:  policy.Response.MaxSize,
+			trailerBudget: policy.Response.MaxSize,
+		}
+	}
+
+	return serverStream, nil
+}
+
+// metadataLimitedServerStream limits the headers and the trailers sent to the client.
+type metadataLimitedServerStream struct {
+	grpc.ServerStream
+
+	options *handlerOptions
+	method  string
+
+	mu            sync.Mutex
+	headerBudget  int
+	trailerBudget int
+}
+
+// limit applies the limit to the metadata within the budget.
+func (s *metadataLimitedServerStream) limit(md metadata.MD, budget *int) (metadata.MD, error) {
+	s.mu.Lock()
+	limited, event := s.options.metadataLimits.Response.enforce(md, *budget)
+
+	if event == nil || event.Action == MetadataTruncate {
+		*budget -= metadataSize(limited)
+	}
+	s.mu.Unlock()
+
+	if event == nil {
+		return md, nil
+	}
+
+	s.options.observeMetadataLimit(event, s.method, "response")
+
+	if event.Action == MetadataReject {
+		return nil, newError(ErrMetadataTooLarge, "%s", event)
+	}
+
+	return limited, nil
+}
+
+func (s *metadataLimitedServerStream) SetHeader(md metadata.MD) error {
+	md, err := s.limit(md, &s.headerBudget)
+	if err != nil {
+		return err
+	}
+
+	return s.ServerStream.SetHeader(md)
+}
+
+func (s *metadataLimitedServerStream) SendHeader(md metadata.MD) error {
+	md, err := s.limit(md, &s.headerBudget)
+	if err != nil {
+		return err
+	}
+
+	return s.ServerStream.SendHeader(md)
+}
+
+func (s *metadataLimitedServerStream) SetTrailer(md metadata.MD) {
+	md, err := s.limit(md, &s.trailerBudget)
+	if err != nil {
+		return
+	}
+
+	s.ServerStream.SetTrailer(md)
+}
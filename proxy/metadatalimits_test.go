@@ -0,0 +1,100 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy_test
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/noncepad/grpc-proxy/proxy"
+	pb "github.com/noncepad/grpc-proxy/testservice"
+)
+
+func TestMetadataLimits(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		events []proxy.MetadataLimitEvent
+		types  []proxy.EventType
+	)
+
+	observer := func(event proxy.MetadataLimitEvent) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		events = append(events, event)
+	}
+
+	sink := proxy.EventSinkFunc(func(event proxy.Event) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if event.Type == proxy.EventMetadataLimited {
+			types = append(types, event.Type)
+		}
+	})
+
+	big := strings.Repeat("x", 200)
+
+	t.Run("reject", func(t *testing.T) {
+		h := newTestHarness(t, one2oneDirector, proxy.WithEvents(sink), proxy.WithMetadataLimits(proxy.MetadataLimitsPolicy{
+			Request:  proxy.MetadataLimit{MaxSize: 100},
+			Observer: observer,
+		}))
+
+		ctx := testContext(t)
+
+		_, err := h.client.Ping(ctx, &pb.PingRequest{Value: "foo"})
+		require.NoError(t, err)
+
+		_, err = h.client.Ping(metadata.AppendToOutgoingContext(ctx, "big", big), &pb.PingRequest{Value: "foo"})
+		assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	})
+
+	t.Run("truncate", func(t *testing.T) {
+		h := newTestHarnessWithService(t, &metadataEchoService{}, one2oneDirector, proxy.WithMetadataLimits(proxy.MetadataLimitsPolicy{
+			Request:  proxy.MetadataLimit{MaxSize: 100, Action: proxy.MetadataTruncate},
+			Response: proxy.MetadataLimit{MaxSize: 40, Action: proxy.MetadataTruncate},
+			Observer: observer,
+		}))
+
+		ctx := metadata.AppendToOutgoingContext(testContext(t), "big", big)
+
+		stream, err := h.client.PingStream(ctx)
+		require.NoError(t, err)
+
+		require.NoError(t, stream.Send(&pb.PingRequest{Value: "big," + clientMdKey}))
+
+		resp, err := stream.Recv()
+		require.NoError(t, err)
+		assert.Equal(t, "big=,"+clientMdKey+"=true", resp.Value)
+
+		require.NoError(t, stream.CloseSend())
+
+		var header metadata.MD
+
+		_, err = h.client.Ping(testContext(t), &pb.PingRequest{Value: "foo"}, grpc.Header(&header))
+		require.NoError(t, err)
+		assert.Empty(t, header.Get(serverHeaderMdKey))
+	})
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	require.Len(t, events, 4)
+	assert.Equal(t, "request", events[0].Direction)
+	assert.Equal(t, proxy.MetadataReject, events[0].Action)
+	assert.Equal(t, []string{"big"}, events[1].Dropped)
+	assert.Equal(t, "response", events[2].Direction)
+	assert.Equal(t, []string{serverHeaderMdKey}, events[2].Dropped)
+	assert.Equal(t, []string{serverTrailerMdKey}, events[3].Dropped)
+	assert.Len(t, types, 1)
+}
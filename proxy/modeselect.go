@@ -0,0 +1,143 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy
+
+import (
+	"context"
+	"sort"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+// FieldModeDirector selects the mode of the call by the request field, so that a single method can be proxied
+// One2One for the targeted requests and One2Many for the broadcast requests, e.g.
+//
+//	message RebootRequest {
+//	  // node to reboot, all the nodes are rebooted if empty
+//	  string node = 1;
+//	}
+//
+// The request is broadcast if the field is not set (or has the default value), or if its value is one
+// of BroadcastValues: it is proxied one2many to all the backends. Otherwise the request is targeted: it is proxied
+// one2one to the backend named by the value of the field (see Backend.String), ErrNoBackends is returned if there
+// is no such backend. The enum values are matched by the name.
+//
+// The mode is selected once the first request message is received, so the requests of the methods should be peeked
+// (see WithRequestPeek and MethodNames). The request is decoded with the registered descriptors.
+type FieldModeDirector struct {
+	// Backends returns the current backend set, e.g. BackendGroup.Backends.
+	Backends func() []Backend
+
+	// Files is the registry of descriptors used to decode the request, protoregistry.GlobalFiles is used if nil.
+	Files *protoregistry.Files
+
+	// Fields maps the full method names to the paths of the request fields selecting the mode: protobuf field names
+	// separated by dots, e.g. "node" or "target.node".
+	Fields map[string]string
+
+	// BroadcastValues are the values of the field which select the broadcast besides the default one, e.g. "*".
+	BroadcastValues []string
+
+	// Fallback routes the calls of the methods not listed in Fields, the calls are rejected if nil.
+	Fallback StreamDirector
+}
+
+// MethodNames returns the methods which mode is selected by the request, so that only their requests are peeked:
+//
+//	proxy.WithRequestPeek(director.MethodNames()...)
+func (d *FieldModeDirector) MethodNames() []string {
+	names := make([]string, 0, len(d.Fields))
+
+	for name := range d.Fields {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// Director is a StreamDirector.
+func (d *FieldModeDirector) Director(ctx context.Context, fullMethodName string) (Mode, []Backend, error) {
+	path, ok := d.Fields[fullMethodName]
+	if !ok {
+		if d.Fallback == nil {
+			return One2One, nil, newError(ErrMethodNotAllowed, "method %s has no mode field", fullMethodName)
+		}
+
+		return d.Fallback(ctx, fullMethodName)
+	}
+
+	payload, ok := RequestFrameFromContext(ctx)
+	if !ok {
+		return One2One, nil, newError(ErrMalformedRequest, "no request message to select the mode of %s by", fullMethodName)
+	}
+
+	value, broadcast, err := d.selectorValue(fullMethodName, payload, path)
+	if err != nil {
+		return One2One, nil, newError(ErrMalformedRequest, "error extracting mode field: %v", err)
+	}
+
+	backends := d.Backends()
+
+	if broadcast {
+		if len(backends) == 0 {
+			return One2Many, nil, newError(ErrNoBackends, "no backends to broadcast %s to", fullMethodName)
+		}
+
+		return One2Many, backends, nil
+	}
+
+	for _, backend := range backends {
+		if backend.String() == value {
+			return One2One, []Backend{backend}, nil
+		}
+	}
+
+	return One2One, nil, newError(ErrNoBackends, "no backend %q for %s", value, fullMethodName)
+}
+
+// selectorValue extracts the value of the field, broadcast is set if the value selects the broadcast.
+func (d *FieldModeDirector) selectorValue(fullMethodName string, payload []byte, path string) (value string, broadcast bool, err error) {
+	methodDesc, err := lookupMethod(d.Files, fullMethodName)
+	if err != nil {
+		return "", false, err
+	}
+
+	msg, fd, err := resolveMessageField(methodDesc.Input(), payload, path)
+	if err != nil {
+		return "", false, err
+	}
+
+	if !msg.Has(fd) {
+		return "", true, nil
+	}
+
+	value = fieldString(fd, msg.Get(fd))
+
+	for _, broadcastValue := range d.BroadcastValues {
+		if value == broadcastValue {
+			return value, true, nil
+		}
+	}
+
+	return value, false, nil
+}
+
+// fieldString formats the scalar field value, the enum values are formatted by the name.
+func fieldString(fd protoreflect.FieldDescriptor, value protoreflect.Value) string {
+	switch fd.Kind() { //nolint:exhaustive
+	case protoreflect.BytesKind:
+		return string(value.Bytes())
+	case protoreflect.EnumKind:
+		if enumValue := fd.Enum().Values().ByNumber(value.Enum()); enumValue != nil {
+			return string(enumValue.Name())
+		}
+
+		return value.String()
+	default:
+		return value.String()
+	}
+}
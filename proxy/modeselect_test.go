@@ -0,0 +1,86 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy_test
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/noncepad/grpc-proxy/proxy"
+	pb "github.com/noncepad/grpc-proxy/testservice"
+)
+
+func TestFieldModeDirector(t *testing.T) {
+	const ping = "/talos.testproto.TestService/Ping"
+
+	group := proxy.NewBackendGroup()
+
+	director := &proxy.FieldModeDirector{
+		Backends:        group.Backends,
+		Fields:          map[string]string{ping: "value"},
+		BroadcastValues: []string{"*"},
+	}
+
+	h := newTestHarnessWithService(t, &tagEchoService{}, func(backend proxy.Backend) proxy.StreamDirector {
+		for _, tag := range []string{"a", "b", "c"} {
+			group.Add(&taggedBackend{Backend: backend, tag: tag})
+		}
+
+		director.Fallback = func(ctx context.Context, fullMethodName string) (proxy.Mode, []proxy.Backend, error) {
+			return proxy.One2One, []proxy.Backend{backend}, nil
+		}
+
+		return director.Director
+	}, proxy.WithRequestPeek(director.MethodNames()...))
+
+	ctx := testContext(t)
+
+	invoke := func(value string) ([]string, error) {
+		in, err := proto.Marshal(&pb.PingRequest{Value: value})
+		require.NoError(t, err)
+
+		var out []byte
+
+		if err = h.clientConn.Invoke(ctx, ping, &in, &out, grpc.ForceCodec(bytesCodec{})); err != nil {
+			return nil, err
+		}
+
+		values := pingValues(t, out)
+		sort.Strings(values)
+
+		return values, nil
+	}
+
+	for _, tt := range []struct {
+		value    string
+		expected []string
+	}{
+		{value: "", expected: []string{"a", "b", "c"}},
+		{value: "*", expected: []string{"a", "b", "c"}},
+		{value: "b", expected: []string{"b"}},
+		{value: "c", expected: []string{"c"}},
+	} {
+		values, err := invoke(tt.value)
+		require.NoError(t, err, tt.value)
+
+		assert.Equal(t, tt.expected, values, tt.value)
+	}
+
+	_, err := invoke("d")
+
+	proxyErr, ok := proxy.FromError(err)
+	require.True(t, ok, "%v", err)
+	assert.Equal(t, proxy.ReasonNoBackends, proxyErr.Reason)
+
+	// the calls of the other methods are routed by the fallback without peeking
+	resp, err := h.client.PingEmpty(ctx, &pb.Empty{})
+	require.NoError(t, err)
+	assert.EqualValues(t, 42, resp.Counter)
+}
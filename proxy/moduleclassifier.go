@@ -0,0 +1,129 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// DefaultClassifyFunction is the default name of the classifier function exported by the module.
+const DefaultClassifyFunction = "classify"
+
+// ErrNoClassifierModule is returned by ModuleClassifier before the module is loaded.
+var ErrNoClassifierModule = errors.New("no classifier module loaded")
+
+// ClassifierModule is the classifier module instantiated by the ClassifierRuntime.
+type ClassifierModule interface {
+	// Call calls the exported function with the input in the module memory, and returns the output of the function.
+	// Call should be safe for the concurrent use (e.g. with a pool of the module instances).
+	Call(ctx context.Context, function string, input []byte) ([]byte, error)
+	// Close releases the module.
+	Close(ctx context.Context) error
+}
+
+// ClassifierRuntime instantiates the classifier modules from their binaries.
+//
+// ClassifierRuntime is the extension point for the sandboxed plugin runtimes: the proxy doesn't ship any runtime,
+// neither WebAssembly nor other. The embedder implements ClassifierRuntime with the runtime of choice (e.g. wazero
+// for the WebAssembly modules), so the runtime decides how the untrusted code is sandboxed (memory limits,
+// no host access, etc.).
+type ClassifierRuntime interface {
+	Instantiate(ctx context.Context, binary []byte) (ClassifierModule, error)
+}
+
+// ModuleClassifier is the Classifier implemented by the module of the ClassifierRuntime, which can be replaced
+// at runtime with Load, so the untrusted or frequently changing routing logic doesn't require recompiling
+// the embedding binary.
+//
+// The classifier function of the module receives the ClassificationRequest encoded as JSON, and returns
+// the Classification encoded as JSON (the messages are base64-encoded as usual in JSON).
+type ModuleClassifier struct {
+	runtime  ClassifierRuntime
+	function string
+
+	mu     sync.RWMutex
+	module ClassifierModule
+}
+
+// NewModuleClassifier creates the ModuleClassifier calling the function of the module, empty function means
+// DefaultClassifyFunction.
+func NewModuleClassifier(runtime ClassifierRuntime, function string) *ModuleClassifier {
+	if function == "" {
+		function = DefaultClassifyFunction
+	}
+
+	return &ModuleClassifier{
+		runtime:  runtime,
+		function: function,
+	}
+}
+
+// Load instantiates the module from the binary and replaces the current module with it.
+//
+// The calls being classified by the previous module finish before it is closed. If the module fails to instantiate,
+// the current module is kept.
+func (c *ModuleClassifier) Load(ctx context.Context, binary []byte) error {
+	module, err := c.runtime.Instantiate(ctx, binary)
+	if err != nil {
+		return fmt.Errorf("error instantiating classifier module: %w", err)
+	}
+
+	c.mu.Lock()
+	previous := c.module
+	c.module = module
+	c.mu.Unlock()
+
+	if previous != nil {
+		return previous.Close(ctx)
+	}
+
+	return nil
+}
+
+// Close closes the current module.
+func (c *ModuleClassifier) Close(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.module == nil {
+		return nil
+	}
+
+	err := c.module.Close(ctx)
+	c.module = nil
+
+	return err
+}
+
+// Classify implements Classifier.
+func (c *ModuleClassifier) Classify(ctx context.Context, request ClassificationRequest) (Classification, error) {
+	var classification Classification
+
+	input, err := json.Marshal(request)
+	if err != nil {
+		return classification, err
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.module == nil {
+		return classification, ErrNoClassifierModule
+	}
+
+	output, err := c.module.Call(ctx, c.function, input)
+	if err != nil {
+		return classification, fmt.Errorf("error calling %s: %w", c.function, err)
+	}
+
+	if err = json.Unmarshal(output, &classification); err != nil {
+		return classification, fmt.Errorf("error decoding output of %s: %w", c.function, err)
+	}
+
+	return classification, nil
+}
@@ -0,0 +1,236 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// ErrUnknownBackendName is returned for the names which are not registered in BackendNames.
+var ErrUnknownBackendName = errors.New("unknown backend name")
+
+// BackendNames maps stable human-readable backend names and their aliases to the connection targets.
+//
+// Backends created with DialBackend are reported by their names everywhere (Backend.String is used for the
+// target metadata, the errors, the metrics and the topology), so the connection details never leak to the clients,
+// and the targets can be changed without breaking the consumers. Aliases allow renaming the backends: the old name
+// registered as an alias keeps working in the target metadata.
+//
+// BackendNames implements http.Handler which serves the names and the aliases (without the targets) as JSON.
+type BackendNames struct {
+	targets map[string]string // name -> target
+	aliases map[string]string // alias -> name
+
+	mu sync.RWMutex
+}
+
+// BackendName describes the registered name.
+type BackendName struct {
+	Name    string   `json:"name"`
+	Aliases []string `json:"aliases,omitempty"`
+}
+
+// NewBackendNames creates an empty BackendNames.
+func NewBackendNames() *BackendNames {
+	return &BackendNames{
+		targets: map[string]string{},
+		aliases: map[string]string{},
+	}
+}
+
+// Register registers the name of the target with the aliases.
+//
+// Registering the name again replaces its target and aliases. Names and aliases share the namespace, so the alias
+// can't be the name or the alias of another backend.
+func (n *BackendNames) Register(name, target string, aliases ...string) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if owner, ok := n.aliases[name]; ok && owner != name {
+		return fmt.Errorf("name %q is an alias of %q", name, owner)
+	}
+
+	for _, alias := range aliases {
+		if _, ok := n.targets[alias]; ok && alias != name {
+			return fmt.Errorf("alias %q is a backend name", alias)
+		}
+
+		if owner, ok := n.aliases[alias]; ok && owner != name {
+			return fmt.Errorf("alias %q is an alias of %q", alias, owner)
+		}
+	}
+
+	n.unregisterLocked(name)
+
+	n.targets[name] = target
+
+	for _, alias := range aliases {
+		n.aliases[alias] = name
+	}
+
+	return nil
+}
+
+// Unregister removes the name and its aliases.
+func (n *BackendNames) Unregister(name string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.unregisterLocked(name)
+}
+
+func (n *BackendNames) unregisterLocked(name string) {
+	delete(n.targets, name)
+
+	for alias, owner := range n.aliases {
+		if owner == name {
+			delete(n.aliases, alias)
+		}
+	}
+}
+
+// Canonical returns the registered name for the name or the alias.
+func (n *BackendNames) Canonical(nameOrAlias string) (string, bool) {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
+	if _, ok := n.targets[nameOrAlias]; ok {
+		return nameOrAlias, true
+	}
+
+	name, ok := n.aliases[nameOrAlias]
+
+	return name, ok
+}
+
+// Target returns the target of the name or the alias.
+func (n *BackendNames) Target(nameOrAlias string) (string, error) {
+	name, ok := n.Canonical(nameOrAlias)
+	if !ok {
+		return "", fmt.Errorf("%w: %q", ErrUnknownBackendName, nameOrAlias)
+	}
+
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
+	target, ok := n.targets[name]
+	if !ok {
+		return "", fmt.Errorf("%w: %q", ErrUnknownBackendName, nameOrAlias)
+	}
+
+	return target, nil
+}
+
+// Names returns the registered names sorted by name.
+func (n *BackendNames) Names() []BackendName {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
+	names := make([]BackendName, 0, len(n.targets))
+	index := make(map[string]int, len(n.targets))
+
+	for name := range n.targets {
+		names = append(names, BackendName{Name: name})
+	}
+
+	sort.Slice(names, func(i, j int) bool { return names[i].Name < names[j].Name })
+
+	for i := range names {
+		index[names[i].Name] = i
+	}
+
+	for alias, name := range n.aliases {
+		names[index[name]].Aliases = append(names[index[name]].Aliases, alias)
+	}
+
+	for i := range names {
+		sort.Strings(names[i].Aliases)
+	}
+
+	return names
+}
+
+// ServeHTTP implements http.Handler.
+func (n *BackendNames) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	json.NewEncoder(w).Encode(n.Names()) //nolint:errcheck
+}
+
+// DialBackend returns the backend for the name or the alias, which connects to the target via the pool.
+//
+// The target is looked up on each connection, so the changes of the target registered for the name are picked up
+// by the existing backends.
+func (n *BackendNames) DialBackend(pool *ConnPool, nameOrAlias string) (*NamedBackend, error) {
+	name, ok := n.Canonical(nameOrAlias)
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownBackendName, nameOrAlias)
+	}
+
+	return &NamedBackend{names: n, pool: pool, name: name}, nil
+}
+
+// TargetsDirector is same as TargetsDirector, but the clients can use the aliases of the backends in the metadata.
+func (n *BackendNames) TargetsDirector(director StreamDirector, key string) StreamDirector {
+	return targetsDirector(director, key, func(target string) string {
+		if name, ok := n.Canonical(target); ok {
+			return name
+		}
+
+		return target
+	})
+}
+
+// NamedBackend is a Backend registered in BackendNames.
+//
+// NamedBackend forwards the incoming metadata as is, like DialBackend. The errors of establishing the connection
+// report the backend name instead of the target.
+type NamedBackend struct {
+	names *BackendNames
+	pool  *ConnPool
+	name  string
+}
+
+func (b *NamedBackend) String() string {
+	return b.name
+}
+
+// GetConnection returns a grpc connection to the backend.
+func (b *NamedBackend) GetConnection(ctx context.Context, fullMethodName string) (context.Context, *grpc.ClientConn, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	outCtx := metadata.NewOutgoingContext(ctx, md)
+
+	target, err := b.names.Target(b.name)
+	if err != nil {
+		return outCtx, nil, err
+	}
+
+	conn, err := b.pool.Get(ctx, target)
+	if err != nil {
+		// the error might contain the target
+		return outCtx, nil, status.Errorf(status.Code(err), "error connecting to %s", b.name)
+	}
+
+	return outCtx, conn, nil
+}
+
+// AppendInfo is called to enhance response from the backend with additional data.
+func (b *NamedBackend) AppendInfo(streaming bool, resp []byte) ([]byte, error) {
+	return resp, nil
+}
+
+// BuildError is called to convert error from upstream into response field.
+func (b *NamedBackend) BuildError(streaming bool, err error) ([]byte, error) {
+	return nil, nil
+}
@@ -0,0 +1,93 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/noncepad/grpc-proxy/proxy"
+	pb "github.com/noncepad/grpc-proxy/testservice"
+)
+
+func TestBackendNames(t *testing.T) {
+	names := proxy.NewBackendNames()
+
+	require.NoError(t, names.Register("billing", "10.0.0.1:50000", "billing-v1", "payments"))
+	require.NoError(t, names.Register("search", "10.0.0.2:50000"))
+
+	assert.Error(t, names.Register("payments", "10.0.0.3:50000"))
+	assert.Error(t, names.Register("search", "10.0.0.2:50000", "billing"))
+	assert.Error(t, names.Register("search", "10.0.0.2:50000", "billing-v1"))
+
+	name, ok := names.Canonical("payments")
+	assert.True(t, ok)
+	assert.Equal(t, "billing", name)
+
+	target, err := names.Target("billing-v1")
+	require.NoError(t, err)
+	assert.Equal(t, "10.0.0.1:50000", target)
+
+	assert.Equal(t, []proxy.BackendName{
+		{Name: "billing", Aliases: []string{"billing-v1", "payments"}},
+		{Name: "search"},
+	}, names.Names())
+
+	names.Unregister("billing")
+
+	_, err = names.Target("payments")
+	assert.ErrorIs(t, err, proxy.ErrUnknownBackendName)
+
+	_, err = names.DialBackend(nil, "billing")
+	assert.ErrorIs(t, err, proxy.ErrUnknownBackendName)
+}
+
+func TestBackendNamesDialBackend(t *testing.T) {
+	pool := proxy.NewConnPool(grpc.WithTransportCredentials(insecure.NewCredentials()))
+	t.Cleanup(func() { pool.Close() }) //nolint: errcheck
+
+	names := proxy.NewBackendNames()
+
+	var h *testHarness
+
+	h = newTestHarness(t, func(proxy.Backend) proxy.StreamDirector {
+		return names.TargetsDirector(func(ctx context.Context, fullMethodName string) (proxy.Mode, []proxy.Backend, error) {
+			backend, err := names.DialBackend(pool, "worker")
+			if err != nil {
+				return proxy.One2One, nil, err
+			}
+
+			return proxy.One2One, []proxy.Backend{backend}, nil
+		}, "")
+	})
+
+	require.NoError(t, names.Register("worker", h.backendAddr, "worker-old"))
+
+	ctx := metadata.AppendToOutgoingContext(testContext(t), proxy.DefaultTargetsMetadataKey, "worker-old")
+
+	out, err := h.client.Ping(ctx, &pb.PingRequest{Value: "foo"})
+	require.NoError(t, err)
+	assert.Equal(t, "foo", out.Value)
+
+	// the target is gone, the error reports the name only
+	require.NoError(t, names.Register("worker", "unknown://worker"))
+	proxy.RegisterResolver("unknown", func(ctx context.Context, target string) (string, error) {
+		return "", status.Errorf(codes.NotFound, "no address for %s", target)
+	})
+	t.Cleanup(func() { proxy.RegisterResolver("unknown", nil) })
+
+	_, err = h.client.Ping(testContext(t), &pb.PingRequest{Value: "foo"})
+	require.Error(t, err)
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	assert.NotContains(t, status.Convert(err).Message(), "unknown://")
+	assert.Contains(t, status.Convert(err).Message(), "worker")
+}
@@ -0,0 +1,57 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"google.golang.org/grpc"
+)
+
+// NewStreamHook is invoked for each backend before the upstream stream is created.
+//
+// The hook receives the upstream context (with the outgoing metadata), the backend and the full method name
+// of the client call. It returns the method name of the upstream call (which might be rewritten, e.g. to route
+// to a different version of the service) and additional call options. If the hook returns an error, the backend
+// is vetoed: the upstream call is not made, and the error is handled as the backend connection error.
+type NewStreamHook func(ctx context.Context, backend Backend, fullMethodName string) (upstreamMethodName string, opts []grpc.CallOption, err error)
+
+// WithNewStreamHook configures the hook invoked before each upstream stream is created, see NewStreamHook.
+func WithNewStreamHook(hook NewStreamHook) Option {
+	return func(o *handlerOptions) {
+		o.newStreamHook = hook
+	}
+}
+
+// beforeNewStream invokes the hook, returning the upstream method name and the call options.
+func (o *handlerOptions) beforeNewStream(ctx context.Context, backend Backend, fullMethodName string) (string, []grpc.CallOption, error) {
+	if o.newStreamHook == nil {
+		return fullMethodName, nil, nil
+	}
+
+	upstreamMethodName, opts, err := o.newStreamHook(ctx, backend, fullMethodName)
+	if err != nil {
+		var proxyErr *Error
+
+		if errors.As(err, &proxyErr) {
+			return "", nil, err
+		}
+
+		return "", nil, &Error{
+			Err:     err,
+			Code:    ErrBackendVetoed.Code,
+			Reason:  ReasonBackendVetoed,
+			Message: fmt.Sprintf("backend %s vetoed: %v", backend, err),
+			Backend: backend.String(),
+		}
+	}
+
+	if upstreamMethodName == "" {
+		upstreamMethodName = fullMethodName
+	}
+
+	return upstreamMethodName, opts, nil
+}
@@ -0,0 +1,81 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/noncepad/grpc-proxy/proxy"
+	pb "github.com/noncepad/grpc-proxy/testservice"
+)
+
+func TestNewStreamHookRewrite(t *testing.T) {
+	var upstreamHeader metadata.MD
+
+	h := newTestHarness(t, one2oneDirector, proxy.WithNewStreamHook(
+		func(ctx context.Context, backend proxy.Backend, fullMethodName string) (string, []grpc.CallOption, error) {
+			if fullMethodName == "/talos.testproto.TestService/PingEmpty" {
+				fullMethodName = "/talos.testproto.TestService/Ping"
+			}
+
+			return fullMethodName, []grpc.CallOption{grpc.Header(&upstreamHeader)}, nil
+		},
+	))
+
+	// Empty request is a valid PingRequest with empty value
+	resp, err := h.client.PingEmpty(testContext(t), &pb.Empty{})
+	require.NoError(t, err)
+
+	assert.Equal(t, "", resp.Value)
+	assert.Equal(t, []string{"I like turtles."}, upstreamHeader.Get(serverHeaderMdKey))
+}
+
+func TestNewStreamHookVeto(t *testing.T) {
+	const pingStream = "/talos.testproto.TestService/PingStream"
+
+	h := newTestHarnessWithService(t, &metadataEchoService{}, one2manyReportingDirector,
+		proxy.WithStreamedDetector(func(fullMethodName string) bool { return fullMethodName == pingStream }),
+		proxy.WithNewStreamHook(func(ctx context.Context, backend proxy.Backend, fullMethodName string) (string, []grpc.CallOption, error) {
+			if backend.String() == "b" {
+				return "", nil, errors.New("maintenance")
+			}
+
+			return fullMethodName, nil, nil
+		}),
+	)
+
+	stream, err := h.client.PingStream(testContext(t))
+	require.NoError(t, err)
+
+	require.NoError(t, stream.Send(&pb.PingRequest{Value: backendTagMdKey}))
+	assert.ElementsMatch(t, []string{backendTagMdKey + "=a", "b:" + codes.Unavailable.String()}, recvValues(t, stream, 2))
+	require.NoError(t, stream.CloseSend())
+}
+
+func TestNewStreamHookVetoOne2One(t *testing.T) {
+	h := newTestHarness(t, one2oneDirector, proxy.WithNewStreamHook(
+		func(ctx context.Context, backend proxy.Backend, fullMethodName string) (string, []grpc.CallOption, error) {
+			return "", nil, errors.New("maintenance")
+		},
+	))
+
+	_, err := h.client.Ping(testContext(t), &pb.PingRequest{Value: "foo"})
+	require.Error(t, err)
+
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+
+	proxyErr, ok := proxy.FromError(err)
+	require.True(t, ok)
+	assert.ErrorIs(t, proxyErr, proxy.ErrBackendVetoed)
+	assert.Equal(t, "backend", proxyErr.Backend)
+}
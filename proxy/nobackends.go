@@ -0,0 +1,54 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy
+
+import (
+	"context"
+
+	"google.golang.org/grpc/codes"
+)
+
+// NoBackendsPolicy defines what happens when the director returns an empty list of backends.
+//
+// By default the call fails with ErrNoBackends (codes.Unavailable) in both one2one and one2many modes.
+type NoBackendsPolicy struct {
+	// FallbackDirector is invoked when the director returns no backends.
+	//
+	// If the fallback director returns an error, the call fails with that error. If it returns no backends as well,
+	// the call fails with Code.
+	FallbackDirector StreamDirector
+
+	// Code is the status code of the ErrNoBackends error: codes.Unavailable (default, the client might retry),
+	// or e.g. codes.NotFound if the empty list means the request addresses something which doesn't exist.
+	Code codes.Code
+}
+
+// WithNoBackends sets the policy for the calls which have no backends.
+//
+// The error returned for the call with no backends is passed to the fallback handler if it is set (see WithLocalFallback).
+func WithNoBackends(policy NoBackendsPolicy) Option {
+	return func(o *handlerOptions) {
+		o.noBackendsPolicy = policy
+	}
+}
+
+// directFallback invokes the fallback director if the director returned no backends.
+func (o *handlerOptions) directFallback(ctx context.Context, fullMethodName string, mode Mode, backends []Backend) (Mode, []Backend, error) {
+	if len(backends) > 0 || o.noBackendsPolicy.FallbackDirector == nil {
+		return mode, backends, nil
+	}
+
+	return o.noBackendsPolicy.FallbackDirector(ctx, fullMethodName)
+}
+
+// noBackendsError returns the error for the call with no backends.
+func (o *handlerOptions) noBackendsError(fullMethodName string) *Error {
+	err := newError(ErrNoBackends, "no backends for %s", fullMethodName)
+
+	if o.noBackendsPolicy.Code != codes.OK {
+		err.Code = o.noBackendsPolicy.Code
+	}
+
+	return err
+}
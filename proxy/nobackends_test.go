@@ -0,0 +1,121 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/noncepad/grpc-proxy/proxy"
+	pb "github.com/noncepad/grpc-proxy/testservice"
+)
+
+func TestNoBackends(t *testing.T) {
+	for _, tt := range []struct { //nolint:govet
+		name     string
+		fallback func(backend proxy.Backend) proxy.StreamDirector
+		code     codes.Code
+		expected codes.Code
+	}{
+		{
+			name:     "default",
+			expected: codes.Unavailable,
+		},
+		{
+			name:     "not found",
+			code:     codes.NotFound,
+			expected: codes.NotFound,
+		},
+		{
+			name:     "fallback director",
+			fallback: one2oneDirector,
+			expected: codes.OK,
+		},
+		{
+			name: "empty fallback director",
+			fallback: func(proxy.Backend) proxy.StreamDirector {
+				return func(context.Context, string) (proxy.Mode, []proxy.Backend, error) {
+					return proxy.One2Many, nil, nil
+				}
+			},
+			code:     codes.NotFound,
+			expected: codes.NotFound,
+		},
+		{
+			name: "failing fallback director",
+			fallback: func(proxy.Backend) proxy.StreamDirector {
+				return func(context.Context, string) (proxy.Mode, []proxy.Backend, error) {
+					return proxy.One2One, nil, status.Error(codes.PermissionDenied, "denied")
+				}
+			},
+			expected: codes.PermissionDenied,
+		},
+	} {
+		tt := tt
+
+		for _, mode := range []proxy.Mode{proxy.One2One, proxy.One2Many} {
+			mode := mode
+
+			name := tt.name + "/one2one"
+			if mode == proxy.One2Many {
+				name = tt.name + "/one2many"
+			}
+
+			t.Run(name, func(t *testing.T) {
+				var upstream proxy.Backend
+
+				policy := proxy.NoBackendsPolicy{Code: tt.code}
+
+				if tt.fallback != nil {
+					policy.FallbackDirector = func(ctx context.Context, fullMethodName string) (proxy.Mode, []proxy.Backend, error) {
+						return tt.fallback(upstream)(ctx, fullMethodName)
+					}
+				}
+
+				h := newTestHarnessWithService(t, &lenientService{}, func(backend proxy.Backend) proxy.StreamDirector {
+					upstream = backend
+
+					return func(ctx context.Context, fullMethodName string) (proxy.Mode, []proxy.Backend, error) {
+						return mode, nil, nil
+					}
+				},
+					proxy.WithStreamedDetector(func(fullMethodName string) bool { return fullMethodName == "/talos.testproto.TestService/PingStream" }),
+					proxy.WithNoBackends(policy),
+				)
+
+				_, err := h.client.Ping(testContext(t), &pb.PingRequest{Value: "foo"})
+				assertNoBackendsError(t, tt.expected, err)
+
+				stream, err := h.client.PingStream(testContext(t))
+				require.NoError(t, err)
+
+				if err = stream.Send(&pb.PingRequest{Value: "foo"}); err == nil {
+					_, err = stream.Recv()
+				}
+
+				assertNoBackendsError(t, tt.expected, err)
+			})
+		}
+	}
+}
+
+func assertNoBackendsError(t *testing.T, expected codes.Code, err error) {
+	t.Helper()
+
+	assert.Equal(t, expected, status.Code(err))
+
+	if expected == codes.OK || expected == codes.PermissionDenied {
+		return
+	}
+
+	proxyErr, ok := proxy.FromError(err)
+	require.True(t, ok)
+	assert.True(t, errors.Is(proxyErr, proxy.ErrNoBackends))
+}
@@ -0,0 +1,120 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/hashicorp/go-multierror"
+	"google.golang.org/grpc"
+)
+
+// ErrInvalidOptions is returned by the handler constructors if the options are invalid or incompatible.
+var ErrInvalidOptions = errors.New("invalid proxy options")
+
+// invalid records the invalid option value, reported by the handler constructors.
+func (o *handlerOptions) invalid(format string, args ...interface{}) {
+	o.optionErrors = append(o.optionErrors, fmt.Errorf(format, args...))
+}
+
+// validate checks the options of the handler for the invalid values and the incompatible combinations.
+func (o *handlerOptions) validate(director StreamDirector, transparent bool) error {
+	var multiErr *multierror.Error
+
+	multiErr = multierror.Append(multiErr, o.optionErrors...)
+
+	fail := func(format string, args ...interface{}) {
+		multiErr = multierror.Append(multiErr, fmt.Errorf(format, args...))
+	}
+
+	if director == nil && o.lazyDirector == nil {
+		fail("director is not set")
+	}
+
+	if transparent {
+		if len(o.methodNames) > 0 {
+			fail("WithMethodNames can't be used with TransparentHandler")
+		}
+
+		if o.streamedMethods != nil {
+			fail("WithStreamedMethodNames can't be used with TransparentHandler, use WithStreamedDetector")
+		}
+	} else {
+		if o.serviceName == "" {
+			fail("service name is empty")
+		}
+
+		methods := map[string]struct{}{}
+
+		for _, name := range o.methodNames {
+			methods["/"+o.serviceName+"/"+name] = struct{}{}
+		}
+
+		for name := range o.streamedMethods {
+			if _, ok := methods[name]; !ok && len(methods) > 0 {
+				fail("streamed method %q is not in the method names", name)
+			}
+		}
+	}
+
+	// pool options
+	if budget := o.upstreamStreamBudget; budget != nil {
+		if budget.perConn < 0 || budget.perHost < 0 || budget.queueTimeout < 0 {
+			fail("upstream stream budget limits and queue timeout should not be negative")
+		}
+
+		if budget.perConn == 0 && budget.perHost == 0 {
+			fail("upstream stream budget has no limits")
+		}
+	}
+
+	// metrics options
+	if o.bandwidthStats != nil && o.bandwidthStats.topN < 0 {
+		fail("bandwidth stats top N should not be negative, got %d", o.bandwidthStats.topN)
+	}
+
+	if o.windowStats != nil && o.windowStats.threshold < 0 {
+		fail("window stats stall threshold should not be negative, got %s", o.windowStats.threshold)
+	}
+
+	if o.descriptorFiles != nil && o.descriptorResolver != nil {
+		fail("WithDescriptorFiles is ignored with WithDescriptorResolver, configure the resolver registry instead")
+	}
+
+	if err := multiErr.ErrorOrNil(); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidOptions, err)
+	}
+
+	return nil
+}
+
+// newHandler creates the handler with the options applied, the returned error reports the invalid options.
+func newHandler(director StreamDirector, serviceName string, transparent bool, options []Option) (*handler, error) {
+	streamer := &handler{
+		director: director,
+		options: handlerOptions{
+			serviceName: serviceName,
+		},
+	}
+
+	for _, o := range options {
+		o(&streamer.options)
+	}
+
+	return streamer, streamer.options.validate(director, transparent)
+}
+
+// NewTransparentHandler is TransparentHandler which validates the options: the invalid option values and
+// the incompatible combinations of the options are reported with ErrInvalidOptions instead of being logged.
+func NewTransparentHandler(director StreamDirector, options ...Option) (grpc.StreamHandler, error) {
+	streamer, err := newHandler(director, "", true, options)
+	if err != nil {
+		return nil, err
+	}
+
+	streamer.options.topology.register(streamer, true)
+
+	return streamer.handler, nil
+}
@@ -0,0 +1,129 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/reflect/protoregistry"
+
+	"github.com/noncepad/grpc-proxy/proxy"
+)
+
+func TestOptionsValidation(t *testing.T) {
+	director := one2oneDirector(nil)
+
+	_, err := proxy.NewTransparentHandler(director, proxy.WithStreamedDetector(func(string) bool { return false }))
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		name    string
+		options []proxy.Option
+		message string
+	}{
+		{
+			name:    "method names",
+			options: []proxy.Option{proxy.WithMethodNames("Ping")},
+			message: "WithMethodNames can't be used with TransparentHandler",
+		},
+		{
+			name:    "streamed method names",
+			options: []proxy.Option{proxy.WithStreamedMethodNames("Ping")},
+			message: "WithStreamedMethodNames can't be used with TransparentHandler",
+		},
+		{
+			name:    "concurrency limit",
+			options: []proxy.Option{proxy.WithMethodConcurrencyLimit("/talos.testproto.TestService/Ping", 0, time.Second)},
+			message: "concurrency limit of /talos.testproto.TestService/Ping should be positive, got 0",
+		},
+		{
+			name:    "rate limit",
+			options: []proxy.Option{proxy.WithMethodRateLimit("", proxy.RateLimitPolicy{Limit: 10, Window: time.Second})},
+			message: "rate limit of \"\" should have the store",
+		},
+		{
+			name: "descriptors",
+			options: []proxy.Option{
+				proxy.WithDescriptorFiles(&protoregistry.Files{}),
+				proxy.WithDescriptorResolver(proxy.NewDescriptorResolver(nil, nil)),
+			},
+			message: "WithDescriptorFiles is ignored with WithDescriptorResolver",
+		},
+		{
+			name:    "upstream stream budget",
+			options: []proxy.Option{proxy.WithUpstreamStreamBudget(proxy.NewUpstreamStreamBudget(0, 0, time.Second))},
+			message: "upstream stream budget has no limits",
+		},
+		{
+			name:    "bandwidth stats",
+			options: []proxy.Option{proxy.WithBandwidthStats(proxy.NewBandwidthStats(-1))},
+			message: "bandwidth stats top N should not be negative, got -1",
+		},
+		{
+			name:    "window stats",
+			options: []proxy.Option{proxy.WithWindowStats(proxy.NewWindowStats(-time.Second))},
+			message: "window stats stall threshold should not be negative, got -1s",
+		},
+		{
+			name:    "upstream signer",
+			options: []proxy.Option{proxy.WithUpstreamSigner(proxy.HMACSigner([]byte("secret")))},
+			message: "upstream signer requires the signed methods",
+		},
+		{
+			name:    "chunk size",
+			options: []proxy.Option{proxy.WithChunking(16, "/talos.testproto.TestService/PingStream")},
+			message: "chunk size should be at least 64, got 16",
+		},
+	} {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := proxy.NewTransparentHandler(director, tc.options...)
+			require.ErrorIs(t, err, proxy.ErrInvalidOptions)
+			assert.ErrorContains(t, err, tc.message)
+		})
+	}
+
+	_, err = proxy.NewTransparentHandler(nil)
+	assert.ErrorContains(t, err, "director is not set")
+
+	server := grpc.NewServer()
+
+	err = proxy.RegisterService(server, director, "talos.testproto.TestService")
+	assert.ErrorContains(t, err, "no method names to proxy")
+
+	err = proxy.RegisterService(server, director, "talos.testproto.TestService",
+		proxy.WithMethodNames("Ping"),
+		proxy.WithStreamedMethodNames("PingStream"))
+	assert.ErrorContains(t, err, "streamed method \"/talos.testproto.TestService/PingStream\" is not in the method names")
+
+	err = proxy.RegisterService(server, director, "talos.testproto.TestService",
+		proxy.WithStreamedDetector(func(string) bool { return false }),
+		proxy.WithMethodNames("Ping"),
+		proxy.WithStreamedMethodNames("Ping"))
+	assert.ErrorContains(t, err, "WithStreamedMethodNames conflicts with WithStreamedDetector")
+
+	require.NoError(t, proxy.RegisterService(server, director, "talos.testproto.TestService", proxy.WithMethodNames("Ping")))
+
+	err = proxy.RegisterService(server, director, "talos.testproto.TestService", proxy.WithMethodNames("Ping"))
+	assert.ErrorContains(t, err, "already registered")
+
+	pool := proxy.NewConnPool()
+	require.NoError(t, pool.Validate())
+
+	pool.OnSlowDial = func(proxy.DialStats) {}
+	pool.Racing = &proxy.DialRacing{}
+
+	err = pool.Validate()
+	require.ErrorIs(t, err, proxy.ErrInvalidOptions)
+	assert.ErrorContains(t, err, "Racing is ignored with OnDial or OnSlowDial")
+	assert.ErrorContains(t, err, "OnSlowDial requires positive SlowDialThreshold")
+
+	_, err = proxy.NewServer(proxy.ServerConfig{Director: director, Pools: []*proxy.ConnPool{pool}})
+	require.ErrorIs(t, err, proxy.ErrInvalidOptions)
+}
@@ -0,0 +1,88 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy
+
+import "fmt"
+
+// PayloadSizeClass is the expected size of the messages of the method.
+type PayloadSizeClass int
+
+// Payload size classes.
+const (
+	// PayloadSizeUnknown sizes the buffers by the messages as they arrive.
+	PayloadSizeUnknown PayloadSizeClass = iota
+	// PayloadSizeSmall is the class of the control messages, up to a few KiB.
+	PayloadSizeSmall
+	// PayloadSizeMedium is the class of the messages up to a few hundred KiB.
+	PayloadSizeMedium
+	// PayloadSizeLarge is the class of the multi-MB blobs.
+	PayloadSizeLarge
+)
+
+// String implements fmt.Stringer.
+func (c PayloadSizeClass) String() string {
+	switch c {
+	case PayloadSizeUnknown:
+		return "unknown"
+	case PayloadSizeSmall:
+		return "small"
+	case PayloadSizeMedium:
+		return "medium"
+	case PayloadSizeLarge:
+		return "large"
+	default:
+		return fmt.Sprintf("PayloadSizeClass(%d)", int(c))
+	}
+}
+
+// Capacity returns the initial capacity of the buffers of the class, zero for PayloadSizeUnknown.
+func (c PayloadSizeClass) Capacity() int {
+	switch c {
+	case PayloadSizeSmall:
+		return 1 << 10
+	case PayloadSizeMedium:
+		return 64 << 10
+	case PayloadSizeLarge:
+		return 4 << 20
+	default:
+		return 0
+	}
+}
+
+// WithPayloadSizeClass declares the expected size of the messages of the listed methods (all methods if none listed),
+// so that the buffers the proxy assembles the messages in (e.g. the chunked messages, see WithChunking) are
+// allocated once with the capacity of the class, instead of growing by reslicing with each part of the message.
+//
+// The hint only affects the allocation: the messages bigger than the class are still proxied. Declaring the large
+// class for the methods of the small messages wastes memory.
+func WithPayloadSizeClass(class PayloadSizeClass, fullMethodNames ...string) Option {
+	return func(o *handlerOptions) {
+		if class < PayloadSizeUnknown || class > PayloadSizeLarge {
+			o.invalid("unknown payload size class %s", class)
+
+			return
+		}
+
+		if o.payloadSizeClasses == nil {
+			o.payloadSizeClasses = map[string]PayloadSizeClass{}
+		}
+
+		if len(fullMethodNames) == 0 {
+			fullMethodNames = []string{""}
+		}
+
+		for _, name := range fullMethodNames {
+			o.payloadSizeClasses[name] = class
+		}
+	}
+}
+
+// payloadSizeClass returns the size class of the method.
+func (o *handlerOptions) payloadSizeClass(fullMethodName string) PayloadSizeClass {
+	if class, ok := o.payloadSizeClasses[fullMethodName]; ok {
+		return class
+	}
+
+	return o.payloadSizeClasses[""]
+}
@@ -0,0 +1,67 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	"github.com/noncepad/grpc-proxy/proxy"
+	pb "github.com/noncepad/grpc-proxy/testservice"
+)
+
+func TestPayloadSizeClass(t *testing.T) {
+	const pingStream = "/talos.testproto.TestService/PingStream"
+
+	serverOptions := []grpc.ServerOption{
+		grpc.CustomCodec(proxy.Codec()), //nolint: staticcheck
+		grpc.StreamInterceptor(proxy.ChunkingStreamServerInterceptor(0)),
+	}
+
+	for _, class := range []proxy.PayloadSizeClass{
+		proxy.PayloadSizeUnknown,
+		proxy.PayloadSizeSmall,
+		proxy.PayloadSizeMedium,
+		proxy.PayloadSizeLarge,
+	} {
+		class := class
+
+		t.Run(class.String(), func(t *testing.T) {
+			h := newTestHarnessWithServer(t, &lenientService{}, serverOptions, one2oneDirector,
+				proxy.WithStreamedDetector(func(fullMethodName string) bool { return fullMethodName == pingStream }),
+				proxy.WithChunking(4<<10, pingStream),
+				proxy.WithPayloadSizeClass(class, pingStream),
+			)
+
+			stream, err := h.client.PingStream(testContext(t))
+			require.NoError(t, err)
+
+			// the messages of the mixed sizes, both below and above the capacity of the class
+			for i, size := range []int{10, 100 << 10, 10, 2 << 10, 300 << 10} {
+				payload := strings.Repeat("x", size)
+
+				require.NoError(t, stream.Send(&pb.PingRequest{Value: payload}))
+
+				resp, err := stream.Recv()
+				require.NoError(t, err)
+
+				assert.Equal(t, payload, resp.Value)
+				assert.EqualValues(t, i, resp.Counter)
+			}
+
+			require.NoError(t, stream.CloseSend())
+		})
+	}
+}
+
+func TestPayloadSizeClassValidation(t *testing.T) {
+	_, err := proxy.NewTransparentHandler(one2oneDirector(nil), proxy.WithPayloadSizeClass(proxy.PayloadSizeClass(7)))
+	require.Error(t, err)
+
+	assert.Contains(t, err.Error(), "unknown payload size class PayloadSizeClass(7)")
+}
@@ -0,0 +1,127 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"google.golang.org/grpc"
+)
+
+type requestFrameKey struct{}
+
+// WithRequestPeek enables reading the first request message before the director is invoked.
+//
+// The raw (serialized) first request message is made available to the director and backends via
+// RequestFrameFromContext, and it is forwarded to the backends as usual.
+//
+// Peeking delays the director until the client sends the first message, so it should not be enabled
+// for streaming methods where the client waits for the response headers before sending anything.
+// If fullMethodNames are listed, only the requests of the listed methods are peeked, otherwise all requests are.
+func WithRequestPeek(fullMethodNames ...string) Option {
+	return func(o *handlerOptions) {
+		if len(fullMethodNames) == 0 {
+			o.requestPeek = true
+
+			return
+		}
+
+		if o.requestPeekMethods == nil {
+			o.requestPeekMethods = map[string]struct{}{}
+		}
+
+		for _, name := range fullMethodNames {
+			o.requestPeekMethods[name] = struct{}{}
+		}
+	}
+}
+
+// peekRequest checks whether the first request message of the method should be peeked.
+func (o *handlerOptions) peekRequest(fullMethodName string) bool {
+	if o.requestPeek {
+		return true
+	}
+
+	_, ok := o.requestPeekMethods[fullMethodName]
+
+	return ok
+}
+
+// RequestFrameFromContext returns the first request message peeked by the handler.
+//
+// The message is available only if WithRequestPeek is enabled, and the client sent at least one message.
+// The returned slice should not be modified.
+func RequestFrameFromContext(ctx context.Context) ([]byte, bool) {
+	payload, ok := ctx.Value(requestFrameKey{}).([]byte)
+
+	return payload, ok
+}
+
+// peekedServerStream replays the peeked first message before reading further messages from the stream.
+type peekedServerStream struct {
+	grpc.ServerStream
+
+	ctx context.Context //nolint:containedctx
+
+	// payload of the peeked message, nil if the message was consumed
+	payload []byte
+	// error encountered while peeking
+	err error
+}
+
+// peekServerStream reads the first message from the server stream.
+func peekServerStream(serverStream grpc.ServerStream) (*peekedServerStream, error) {
+	peeked := &peekedServerStream{
+		ServerStream: serverStream,
+		ctx:          serverStream.Context(),
+	}
+
+	f := &Frame{}
+
+	if err := serverStream.RecvMsg(f); err != nil {
+		if !errors.Is(err, io.EOF) {
+			return nil, err
+		}
+
+		peeked.err = err
+
+		return peeked, nil
+	}
+
+	if f.payload == nil {
+		f.payload = []byte{}
+	}
+
+	peeked.payload = f.payload
+	peeked.ctx = context.WithValue(peeked.ctx, requestFrameKey{}, f.payload)
+
+	return peeked, nil
+}
+
+func (s *peekedServerStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *peekedServerStream) RecvMsg(m interface{}) error {
+	if s.err != nil {
+		return s.err
+	}
+
+	if s.payload == nil {
+		return s.ServerStream.RecvMsg(m)
+	}
+
+	payload := s.payload
+	s.payload = nil
+
+	if f, ok := m.(*Frame); ok {
+		f.payload = payload
+
+		return nil
+	}
+
+	return Codec().Unmarshal(payload, m)
+}
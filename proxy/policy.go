@@ -0,0 +1,178 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+// PolicyInput is the document passed to the policy for evaluation.
+//
+// JSON encoding of the input is stable, so that it can be used as an input document of the external policy engine
+// (e.g. OPA).
+type PolicyInput struct {
+	// Method is the full method name (/package.Service/Method).
+	Method string `json:"method"`
+	// Metadata is the incoming request metadata, the values of the binary keys ("-bin" suffix) are base64-encoded
+	// (standard encoding with padding).
+	Metadata map[string][]string `json:"metadata"`
+	// Peer describes the client.
+	Peer PolicyPeer `json:"peer"`
+	// Request is the first request message decoded into JSON-like structure using protobuf field names.
+	//
+	// Request is available only when WithRequestPeek is enabled and request descriptor is registered.
+	Request map[string]interface{} `json:"request,omitempty"`
+}
+
+// PolicyPeer describes the client connection.
+type PolicyPeer struct {
+	// Address is the network address of the client.
+	Address string `json:"address,omitempty"`
+	// AuthType is the transport security protocol (e.g. "tls"), if any.
+	AuthType string `json:"auth_type,omitempty"`
+}
+
+// PolicyDecision is the result of policy evaluation.
+type PolicyDecision struct {
+	// Allow should be set for the call to be proxied.
+	Allow bool `json:"allow"`
+	// Reason is returned to the client if the call is denied.
+	Reason string `json:"reason,omitempty"`
+	// Mode is the proxying mode: "one2one" (default) or "one2many".
+	Mode string `json:"mode,omitempty"`
+	// Backends is the list of backend names to proxy the call to.
+	Backends []string `json:"backends,omitempty"`
+}
+
+// Policy evaluates authorization and routing policy for the call.
+//
+// RulePolicy is the only evaluator shipped with the proxy. The proxy doesn't integrate OPA and doesn't evaluate Rego,
+// the Rego policies are evaluated by the caller (with the OPA library or the OPA server) plugged in via PolicyFunc,
+// the result of the evaluation is decoded with DecodePolicyDecision, e.g. with the OPA library in the caller code:
+//
+//	query, _ := rego.New(rego.Query("data.proxy.decision"), rego.Module("proxy.rego", src)).PrepareForEval(ctx)
+//
+//	policy := proxy.PolicyFunc(func(ctx context.Context, input *proxy.PolicyInput) (*proxy.PolicyDecision, error) {
+//		rs, err := query.Eval(ctx, rego.EvalInput(input))
+//		if err != nil || len(rs) == 0 {
+//			return nil, err
+//		}
+//
+//		return proxy.DecodePolicyDecision(rs[0].Expressions[0].Value)
+//	})
+type Policy interface {
+	Evaluate(ctx context.Context, input *PolicyInput) (*PolicyDecision, error)
+}
+
+// PolicyFunc is a function adapter for Policy.
+type PolicyFunc func(ctx context.Context, input *PolicyInput) (*PolicyDecision, error)
+
+// Evaluate implements Policy.
+func (f PolicyFunc) Evaluate(ctx context.Context, input *PolicyInput) (*PolicyDecision, error) {
+	return f(ctx, input)
+}
+
+// DecodePolicyDecision converts generic JSON-like value (e.g. result of the Rego evaluation) into PolicyDecision.
+func DecodePolicyDecision(value interface{}) (*PolicyDecision, error) {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding policy decision: %w", err)
+	}
+
+	var decision PolicyDecision
+
+	if err = json.Unmarshal(encoded, &decision); err != nil {
+		return nil, fmt.Errorf("error decoding policy decision: %w", err)
+	}
+
+	return &decision, nil
+}
+
+// PolicyDirector implements StreamDirector by evaluating the Policy for each call.
+type PolicyDirector struct {
+	// Policy to evaluate.
+	Policy Policy
+
+	// Resolve returns the Backend by the name returned in the policy decision.
+	Resolve func(ctx context.Context, name string) (Backend, error)
+
+	// Files is the registry of descriptors used to decode the request, protoregistry.GlobalFiles is used if nil.
+	Files *protoregistry.Files
+}
+
+// Director is a StreamDirector.
+func (d *PolicyDirector) Director(ctx context.Context, fullMethodName string) (Mode, []Backend, error) {
+	input := &PolicyInput{
+		Method:   fullMethodName,
+		Metadata: map[string][]string{},
+	}
+
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		input.Metadata = policyMetadata(md)
+	}
+
+	if p, ok := peer.FromContext(ctx); ok {
+		if p.Addr != nil {
+			input.Peer.Address = p.Addr.String()
+		}
+
+		if p.AuthInfo != nil {
+			input.Peer.AuthType = p.AuthInfo.AuthType()
+		}
+	}
+
+	if payload, ok := RequestFrameFromContext(ctx); ok {
+		var err error
+
+		input.Request, err = decodeRequest(d.Files, fullMethodName, payload)
+		if err != nil {
+			return One2One, nil, newError(ErrMalformedRequest, "error decoding request: %v", err)
+		}
+	}
+
+	decision, err := d.Policy.Evaluate(ctx, input)
+	if err != nil {
+		return One2One, nil, newError(ErrPolicyFailed, "error evaluating policy: %v", err)
+	}
+
+	if decision == nil || !decision.Allow {
+		reason := "denied by policy"
+
+		if decision != nil && decision.Reason != "" {
+			reason = decision.Reason
+		}
+
+		return One2One, nil, newError(ErrPolicyDenied, "%s", reason)
+	}
+
+	var mode Mode
+
+	switch decision.Mode {
+	case "", "one2one":
+		mode = One2One
+	case "one2many":
+		mode = One2Many
+	default:
+		return One2One, nil, newError(ErrPolicyFailed, "unsupported proxy mode %q in policy decision", decision.Mode)
+	}
+
+	backends := make([]Backend, 0, len(decision.Backends))
+
+	for _, name := range decision.Backends {
+		backend, err := d.Resolve(ctx, name)
+		if err != nil {
+			return mode, nil, err
+		}
+
+		backends = append(backends, backend)
+	}
+
+	return mode, backends, nil
+}
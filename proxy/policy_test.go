@@ -0,0 +1,107 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/noncepad/grpc-proxy/proxy"
+	pb "github.com/noncepad/grpc-proxy/testservice"
+)
+
+func TestPolicyDirector(t *testing.T) {
+	var inputs []*proxy.PolicyInput
+
+	h := newTestHarness(t, func(backend proxy.Backend) proxy.StreamDirector {
+		director := &proxy.PolicyDirector{
+			Policy: proxy.PolicyFunc(func(ctx context.Context, input *proxy.PolicyInput) (*proxy.PolicyDecision, error) {
+				inputs = append(inputs, input)
+
+				if input.Request["value"] == "deny" {
+					return &proxy.PolicyDecision{Reason: "value is not allowed"}, nil
+				}
+
+				return proxy.DecodePolicyDecision(map[string]interface{}{
+					"allow":    true,
+					"backends": []interface{}{"default"},
+				})
+			}),
+			Resolve: func(ctx context.Context, name string) (proxy.Backend, error) {
+				return backend, nil
+			},
+		}
+
+		return director.Director
+	}, proxy.WithRequestPeek())
+
+	ctx := testContext(t)
+
+	out, err := h.client.Ping(ctx, &pb.PingRequest{Value: "foo"})
+	require.NoError(t, err)
+	assert.Equal(t, "foo", out.Value)
+
+	_, err = h.client.Ping(ctx, &pb.PingRequest{Value: "deny"})
+	require.Error(t, err)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	assert.Equal(t, "value is not allowed", status.Convert(err).Message())
+
+	require.Len(t, inputs, 2)
+	assert.Equal(t, "/talos.testproto.TestService/Ping", inputs[0].Method)
+	assert.Equal(t, []string{"true"}, inputs[0].Metadata[clientMdKey])
+	assert.NotEmpty(t, inputs[0].Peer.Address)
+}
+
+func TestRulePolicy(t *testing.T) {
+	_, err := proxy.ParseRulePolicy([]byte(`{"rules": [{"peer_cidrs": ["10.0.0.0"]}]}`))
+	require.Error(t, err)
+
+	policy, err := proxy.ParseRulePolicy([]byte(`{
+		"rules": [
+			{
+				"method": "/talos.testproto.TestService/*",
+				"request": {"value": ["deny*"]},
+				"decision": {"reason": "value is not allowed"}
+			},
+			{
+				"method": "/talos.testproto.TestService/Ping",
+				"metadata": {"` + clientMdKey + `": ["true"]},
+				"peer_cidrs": ["127.0.0.0/8", "::1/128"],
+				"decision": {"allow": true, "backends": ["default"]}
+			}
+		],
+		"default": {"reason": "no matching rule"}
+	}`))
+	require.NoError(t, err)
+
+	h := newTestHarness(t, func(backend proxy.Backend) proxy.StreamDirector {
+		director := &proxy.PolicyDirector{
+			Policy: policy,
+			Resolve: func(ctx context.Context, name string) (proxy.Backend, error) {
+				return backend, nil
+			},
+		}
+
+		return director.Director
+	}, proxy.WithRequestPeek())
+
+	ctx := testContext(t)
+
+	out, err := h.client.Ping(ctx, &pb.PingRequest{Value: "foo"})
+	require.NoError(t, err)
+	assert.Equal(t, "foo", out.Value)
+
+	_, err = h.client.Ping(ctx, &pb.PingRequest{Value: "deny-me"})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	assert.Equal(t, "value is not allowed", status.Convert(err).Message())
+
+	_, err = h.client.PingEmpty(ctx, &pb.Empty{})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	assert.Equal(t, "no matching rule", status.Convert(err).Message())
+}
@@ -0,0 +1,236 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"strings"
+)
+
+// RulePolicy is the embedded Policy which evaluates the ordered list of the rules: the decision of the first rule
+// matching the call is returned, the default decision (deny, unless configured) is returned if no rule matches.
+//
+// The rules are data, so that the routing and the authorization are managed as the policy document instead of
+// the Go code, e.g.:
+//
+//	{
+//	  "rules": [
+//	    {
+//	      "method": "/admin.*",
+//	      "peer_cidrs": ["10.0.0.0/8"],
+//	      "decision": {"allow": true, "backends": ["admin"]}
+//	    },
+//	    {
+//	      "method": "/talos.testproto.TestService/*",
+//	      "metadata": {"x-tenant": ["acme", "globex"]},
+//	      "request": {"target.cluster_id": ["*"]},
+//	      "decision": {"allow": true, "mode": "one2many", "backends": ["node-1", "node-2"]}
+//	    }
+//	  ],
+//	  "default": {"reason": "no matching rule"}
+//	}
+//
+// RulePolicy is not Rego: the policies which need the full expressiveness of Rego are evaluated outside
+// of the proxy and plugged in via PolicyFunc, see Policy.
+type RulePolicy struct {
+	rules    []compiledPolicyRule
+	fallback PolicyDecision
+}
+
+// PolicyRule matches the calls by the fields of PolicyInput, all the set conditions should match.
+//
+// The values of the method and the lists are matched exactly, the value with the "*" suffix matches the values
+// with the prefix ("*" matches any present value).
+type PolicyRule struct {
+	// Method matches the full method name, e.g. "/package.Service/*".
+	Method string `json:"method,omitempty"`
+	// Metadata matches any of the values of the metadata keys (lowercase).
+	Metadata map[string][]string `json:"metadata,omitempty"`
+	// PeerCIDRs match the address of the client.
+	PeerCIDRs []string `json:"peer_cidrs,omitempty"`
+	// AuthType matches the transport security protocol of the client (e.g. "tls").
+	AuthType string `json:"auth_type,omitempty"`
+	// Request matches the fields of the decoded request by the dot-separated path of the protobuf field names,
+	// e.g. "target.cluster_id". The values are compared as strings, the repeated fields match if any element does.
+	//
+	// The request is available only with WithRequestPeek, the rules matching the request don't match otherwise.
+	Request map[string][]string `json:"request,omitempty"`
+
+	// Decision is returned if the rule matches.
+	Decision PolicyDecision `json:"decision"`
+}
+
+type compiledPolicyRule struct {
+	PolicyRule
+
+	networks []*net.IPNet
+}
+
+// NewRulePolicy creates the policy with the rules, fallback is the decision if no rule matches (deny if nil).
+func NewRulePolicy(rules []PolicyRule, fallback *PolicyDecision) (*RulePolicy, error) {
+	p := &RulePolicy{
+		rules: make([]compiledPolicyRule, 0, len(rules)),
+	}
+
+	if fallback != nil {
+		p.fallback = *fallback
+	}
+
+	for i, rule := range rules {
+		compiled := compiledPolicyRule{PolicyRule: rule}
+
+		for _, cidr := range rule.PeerCIDRs {
+			_, network, err := net.ParseCIDR(cidr)
+			if err != nil {
+				return nil, fmt.Errorf("rule %d: %w", i, err)
+			}
+
+			compiled.networks = append(compiled.networks, network)
+		}
+
+		switch rule.Decision.Mode {
+		case "", "one2one", "one2many":
+		default:
+			return nil, fmt.Errorf("rule %d: unsupported proxy mode %q", i, rule.Decision.Mode)
+		}
+
+		p.rules = append(p.rules, compiled)
+	}
+
+	return p, nil
+}
+
+// ParseRulePolicy parses the JSON policy document with the "rules" (list of PolicyRule) and the "default" decision.
+func ParseRulePolicy(data []byte) (*RulePolicy, error) {
+	var document struct {
+		Rules   []PolicyRule    `json:"rules"`
+		Default *PolicyDecision `json:"default"`
+	}
+
+	if err := json.Unmarshal(data, &document); err != nil {
+		return nil, fmt.Errorf("error parsing rule policy: %w", err)
+	}
+
+	return NewRulePolicy(document.Rules, document.Default)
+}
+
+// Evaluate implements Policy.
+func (p *RulePolicy) Evaluate(ctx context.Context, input *PolicyInput) (*PolicyDecision, error) {
+	for i := range p.rules {
+		if p.rules[i].matches(input) {
+			decision := p.rules[i].Decision
+
+			return &decision, nil
+		}
+	}
+
+	decision := p.fallback
+
+	return &decision, nil
+}
+
+func (r *compiledPolicyRule) matches(input *PolicyInput) bool {
+	if r.Method != "" && !matchPolicyValue(r.Method, input.Method) {
+		return false
+	}
+
+	if r.AuthType != "" && r.AuthType != input.Peer.AuthType {
+		return false
+	}
+
+	if len(r.networks) > 0 && !r.matchesPeer(input.Peer.Address) {
+		return false
+	}
+
+	for key, patterns := range r.Metadata {
+		if !matchPolicyValues(patterns, input.Metadata[key]) {
+			return false
+		}
+	}
+
+	for path, patterns := range r.Request {
+		if input.Request == nil || !matchPolicyValues(patterns, requestFieldValues(input.Request, strings.Split(path, "."))) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (r *compiledPolicyRule) matchesPeer(address string) bool {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		host = address
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+
+	for _, network := range r.networks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// requestFieldValues returns the values of the decoded request field by path, the repeated fields are flattened.
+func requestFieldValues(value interface{}, path []string) []string {
+	switch v := value.(type) {
+	case []interface{}:
+		var values []string
+
+		for _, element := range v {
+			values = append(values, requestFieldValues(element, path)...)
+		}
+
+		return values
+	case map[string]interface{}:
+		if len(path) == 0 {
+			return nil
+		}
+
+		field, ok := v[path[0]]
+		if !ok {
+			return nil
+		}
+
+		return requestFieldValues(field, path[1:])
+	case nil:
+		return nil
+	default:
+		if len(path) > 0 {
+			return nil
+		}
+
+		return []string{fmt.Sprint(v)}
+	}
+}
+
+// matchPolicyValues checks whether any of the values matches any of the patterns.
+func matchPolicyValues(patterns, values []string) bool {
+	for _, pattern := range patterns {
+		for _, value := range values {
+			if matchPolicyValue(pattern, value) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func matchPolicyValue(pattern, value string) bool {
+	if prefix := strings.TrimSuffix(pattern, "*"); prefix != pattern {
+		return strings.HasPrefix(value, prefix)
+	}
+
+	return pattern == value
+}
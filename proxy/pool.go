@@ -0,0 +1,439 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/noncepad/grpc-proxy/proxy/failpoint"
+)
+
+const (
+	// defaultResolveTTL is the default of ConnPool.ResolveTTL.
+	defaultResolveTTL = 5 * time.Second
+	// defaultIdentityIdleTimeout is the default of ConnPool.IdentityIdleTimeout.
+	defaultIdentityIdleTimeout = 5 * time.Minute
+	// retireGrace is the minimum time the retired connection is kept for.
+	retireGrace = time.Second
+)
+
+// ConnPool keeps connections to the backends by target.
+//
+// Targets are resolved with the resolvers registered via RegisterResolver (or with Resolver, if set) once
+// ResolveTTL passes, and the connection is re-established if the target resolves to a different address.
+// The previous connection is retired: the calls in flight continue over it, and it is closed once they finish.
+type ConnPool struct {
+	// Resolver overrides the registered resolvers, if set.
+	Resolver ResolverFunc
+	// ResolveTTL is the time the resolved address of the target is reused for (default 5s), negative resolves
+	// the target on each Get.
+	ResolveTTL time.Duration
+
+	// Credentials override the transport credentials of the dial options, if set.
+	//
+	// Credentials should be set instead of the dial option to measure the TLS handshake (see DialStats).
+	Credentials credentials.TransportCredentials
+
+	// TLSConfig configures the TLS of the connections if Credentials are not set.
+	//
+	// The TLS sessions are cached (unless TLSConfig has its own ClientSessionCache), so that the connections
+	// re-established to the same backend resume the session instead of the full handshake, see Stats.
+	TLSConfig *tls.Config
+	// TLSSessionCacheSize is the capacity of the TLS session cache (default 256), negative disables the cache.
+	TLSSessionCacheSize int
+
+	// OnDial is invoked with the stats of each connection attempt, if set.
+	OnDial func(DialStats)
+
+	// OnSlowDial is invoked with the stats of the connection attempts which took longer than SlowDialThreshold, if set.
+	OnSlowDial        func(DialStats)
+	SlowDialThreshold time.Duration
+
+	// HTTP2 tunes the HTTP/2 transport of the connections.
+	HTTP2 HTTP2Tuning
+
+	// Racing races the connection attempts to the addresses of the target, if set.
+	Racing *DialRacing
+
+	// IdentityIdleTimeout is the time the connections with the identity override (see GetWithIdentity) are kept
+	// for without calls (default 5m), negative keeps them until Close. The idle connections are retired, so that
+	// the number of the connections doesn't grow with the number of the identities (e.g. per-tenant authorities).
+	IdentityIdleTimeout time.Duration
+
+	conns    map[string]*pooledConn
+	retired  map[*pooledConn]struct{}
+	tlsCreds credentials.TransportCredentials
+	counters connPoolCounters
+	// lastIdleSweep is the time the idle connections were last looked for
+	lastIdleSweep time.Time
+
+	stateWatchers map[chan ConnStateEvent]struct{}
+
+	dialOptions []grpc.DialOption
+
+	mu      sync.Mutex
+	watchMu sync.Mutex
+}
+
+type pooledConn struct {
+	conn *grpc.ClientConn
+	addr string
+	// resolvedAt is the time the target was last resolved to addr, guarded by ConnPool.mu
+	resolvedAt time.Time
+	// lastUsed is the time the connection was last returned by the pool, guarded by ConnPool.mu
+	lastUsed time.Time
+	// identity is set for the connections with the identity override, which are retired once idle
+	identity bool
+
+	mu sync.Mutex
+	// calls is the number of the calls in flight over the connection
+	calls int
+	// draining is set once the retired connection passed retireGrace, it is closed when the calls finish
+	draining bool
+	pool     *ConnPool
+}
+
+// NewConnPool creates new ConnPool, connections are dialed with the options and the proxy codec.
+func NewConnPool(dialOptions ...grpc.DialOption) *ConnPool {
+	return &ConnPool{
+		conns:       map[string]*pooledConn{},
+		dialOptions: append([]grpc.DialOption{grpc.WithCodec(Codec())}, dialOptions...), //nolint: staticcheck
+	}
+}
+
+// Validate checks the pool configuration for the incompatible combinations, which would be silently ignored
+// otherwise. The errors are reported with ErrInvalidOptions, NewServer validates ServerConfig.Pools.
+func (p *ConnPool) Validate() error {
+	var multiErr *multierror.Error
+
+	fail := func(format string, args ...interface{}) {
+		multiErr = multierror.Append(multiErr, fmt.Errorf(format, args...))
+	}
+
+	if p.Credentials != nil && p.TLSConfig != nil {
+		fail("TLSConfig is ignored with Credentials")
+	}
+
+	if p.Racing != nil && p.telemetryEnabled() {
+		fail("Racing is ignored with OnDial or OnSlowDial")
+	}
+
+	if p.OnSlowDial != nil && p.SlowDialThreshold <= 0 {
+		fail("OnSlowDial requires positive SlowDialThreshold")
+	}
+
+	if p.Racing != nil && p.Racing.Delay < 0 {
+		fail("racing delay should not be negative, got %s", p.Racing.Delay)
+	}
+
+	if err := multiErr.ErrorOrNil(); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidOptions, err)
+	}
+
+	return nil
+}
+
+// Get returns a connection to the target, dialing it if necessary.
+func (p *ConnPool) Get(ctx context.Context, target string) (*grpc.ClientConn, error) {
+	return p.get(ctx, target, DialIdentity{})
+}
+
+func (p *ConnPool) get(ctx context.Context, target string, identity DialIdentity) (*grpc.ClientConn, error) {
+	resolve := p.Resolver
+	if resolve == nil {
+		resolve = Resolve
+	}
+
+	ttl := p.ResolveTTL
+	if ttl == 0 {
+		ttl = defaultResolveTTL
+	}
+
+	p.counters.gets.Add(1)
+
+	key := identity.key(target)
+
+	p.mu.Lock()
+
+	now := time.Now()
+	p.sweepIdleLocked(now)
+
+	if pooled, ok := p.conns[key]; ok && now.Sub(pooled.resolvedAt) < ttl {
+		pooled.lastUsed = now
+		p.mu.Unlock()
+
+		return pooled.conn, nil
+	}
+
+	p.mu.Unlock()
+
+	addr, err := resolve(ctx, target)
+	if err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+
+	if pooled, ok := p.conns[key]; ok && pooled.addr == addr {
+		pooled.resolvedAt = time.Now()
+		pooled.lastUsed = pooled.resolvedAt
+		p.mu.Unlock()
+
+		return pooled.conn, nil
+	}
+
+	p.mu.Unlock()
+
+	if err = evalFailpoint(failpoint.Dial, target, ""); err != nil {
+		return nil, err
+	}
+
+	// the dial might block (e.g. with grpc.WithBlock or racing), so the other targets are served meanwhile
+	pooled := &pooledConn{addr: addr, resolvedAt: time.Now(), identity: key != target, pool: p}
+	pooled.lastUsed = pooled.resolvedAt
+
+	conn, err := grpc.DialContext(ctx, addr, append(p.connDialOptions(target, identity),
+		grpc.WithChainUnaryInterceptor(pooled.unaryInterceptor),
+		grpc.WithChainStreamInterceptor(pooled.streamInterceptor),
+	)...)
+	if err != nil {
+		return nil, err
+	}
+
+	p.counters.dials.Add(1)
+
+	pooled.conn = conn
+
+	p.mu.Lock()
+
+	if current, ok := p.conns[key]; ok {
+		if current.addr == addr {
+			// dialed concurrently by another call
+			p.mu.Unlock()
+
+			conn.Close() //nolint: errcheck
+
+			return current.conn, nil
+		}
+
+		p.retireLocked(current)
+	}
+
+	p.conns[key] = pooled
+
+	p.mu.Unlock()
+
+	go p.watchState(target, identity, addr, conn)
+
+	return conn, nil
+}
+
+// sweepIdleLocked retires the connections with the identity override which had no calls for IdentityIdleTimeout,
+// it should be called with the lock held.
+func (p *ConnPool) sweepIdleLocked(now time.Time) {
+	timeout := p.IdentityIdleTimeout
+	if timeout == 0 {
+		timeout = defaultIdentityIdleTimeout
+	}
+
+	if timeout < 0 || now.Sub(p.lastIdleSweep) < timeout {
+		return
+	}
+
+	p.lastIdleSweep = now
+
+	for key, pooled := range p.conns {
+		if !pooled.identity || now.Sub(pooled.lastUsed) < timeout {
+			continue
+		}
+
+		pooled.mu.Lock()
+		busy := pooled.calls > 0
+		pooled.mu.Unlock()
+
+		if busy {
+			continue
+		}
+
+		delete(p.conns, key)
+		p.retireLocked(pooled)
+	}
+}
+
+// retireLocked removes the connection from the pool, it is closed once the calls in flight finish.
+//
+// The connection is kept for retireGrace at least, as the calls might have got the connection, but haven't
+// started the stream yet.
+func (p *ConnPool) retireLocked(pooled *pooledConn) {
+	if p.retired == nil {
+		p.retired = map[*pooledConn]struct{}{}
+	}
+
+	p.retired[pooled] = struct{}{}
+
+	time.AfterFunc(retireGrace, func() {
+		pooled.mu.Lock()
+		pooled.draining = true
+		drained := pooled.calls == 0
+		pooled.mu.Unlock()
+
+		if drained {
+			p.closeRetired(pooled)
+		}
+	})
+}
+
+// closeRetired closes the retired connection unless the pool closed it already.
+func (p *ConnPool) closeRetired(pooled *pooledConn) {
+	p.mu.Lock()
+	_, ok := p.retired[pooled]
+	delete(p.retired, pooled)
+	p.mu.Unlock()
+
+	if ok {
+		pooled.conn.Close() //nolint: errcheck
+	}
+}
+
+func (c *pooledConn) acquire() {
+	c.mu.Lock()
+	c.calls++
+	c.mu.Unlock()
+}
+
+func (c *pooledConn) release() {
+	c.mu.Lock()
+	c.calls--
+	drained := c.draining && c.calls == 0
+	c.mu.Unlock()
+
+	if drained {
+		c.pool.closeRetired(c)
+	}
+}
+
+func (c *pooledConn) unaryInterceptor(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	c.acquire()
+	defer c.release()
+
+	return invoker(ctx, method, req, reply, cc, opts...)
+}
+
+func (c *pooledConn) streamInterceptor(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	c.acquire()
+
+	stream, err := streamer(ctx, desc, cc, method, opts...)
+	if err != nil {
+		c.release()
+
+		return nil, err
+	}
+
+	tracked := &trackedClientStream{ClientStream: stream, done: make(chan struct{})}
+
+	// the stream is finished once it is received to the end or its context is canceled
+	go func() {
+		select {
+		case <-tracked.done:
+		case <-ctx.Done():
+		}
+
+		c.release()
+	}()
+
+	return tracked, nil
+}
+
+// trackedClientStream signals when the stream is received to the end.
+type trackedClientStream struct {
+	grpc.ClientStream
+
+	done chan struct{}
+	once sync.Once
+}
+
+func (s *trackedClientStream) RecvMsg(m interface{}) error {
+	err := s.ClientStream.RecvMsg(m)
+	if err != nil {
+		s.once.Do(func() { close(s.done) })
+	}
+
+	return err
+}
+
+// Close closes all the connections.
+func (p *ConnPool) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var result *multierror.Error
+
+	for key, pooled := range p.conns {
+		if err := pooled.conn.Close(); err != nil {
+			result = multierror.Append(result, err)
+		}
+
+		delete(p.conns, key)
+	}
+
+	for pooled := range p.retired {
+		if err := pooled.conn.Close(); err != nil {
+			result = multierror.Append(result, err)
+		}
+
+		delete(p.retired, pooled)
+	}
+
+	return result.ErrorOrNil()
+}
+
+// DialBackend is a Backend which connects to the target via ConnPool.
+//
+// Incoming metadata is forwarded to the backend as is.
+type DialBackend struct {
+	Pool *ConnPool
+	// Target is resolved with the resolvers registered via RegisterResolver.
+	Target string
+	// Identity returns the identity override of the upstream connection for the call, if set.
+	Identity func(ctx context.Context, fullMethodName string) DialIdentity
+}
+
+func (b *DialBackend) String() string {
+	return b.Target
+}
+
+// GetConnection returns a grpc connection to the backend.
+func (b *DialBackend) GetConnection(ctx context.Context, fullMethodName string) (context.Context, *grpc.ClientConn, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	outCtx := metadata.NewOutgoingContext(ctx, md)
+
+	var identity DialIdentity
+
+	if b.Identity != nil {
+		identity = b.Identity(ctx, fullMethodName)
+	}
+
+	conn, err := b.Pool.get(ctx, b.Target, identity)
+
+	return outCtx, conn, err
+}
+
+// AppendInfo is called to enhance response from the backend with additional data.
+func (b *DialBackend) AppendInfo(streaming bool, resp []byte) ([]byte, error) {
+	return resp, nil
+}
+
+// BuildError is called to convert error from upstream into response field.
+func (b *DialBackend) BuildError(streaming bool, err error) ([]byte, error) {
+	return nil, nil
+}
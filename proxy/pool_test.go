@@ -0,0 +1,185 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+
+	"github.com/noncepad/grpc-proxy/proxy"
+	pb "github.com/noncepad/grpc-proxy/testservice"
+)
+
+func TestDialBackendResolver(t *testing.T) {
+	pool := proxy.NewConnPool(grpc.WithTransportCredentials(insecure.NewCredentials()))
+	t.Cleanup(func() { pool.Close() }) //nolint: errcheck
+
+	var h *testHarness
+
+	proxy.RegisterResolver("testnode", func(ctx context.Context, target string) (string, error) {
+		if target != "testnode://worker-3" {
+			return "", errors.New("unknown node")
+		}
+
+		return h.backendAddr, nil
+	})
+	t.Cleanup(func() { proxy.RegisterResolver("testnode", nil) })
+
+	h = newTestHarness(t, func(proxy.Backend) proxy.StreamDirector {
+		return func(ctx context.Context, fullMethodName string) (proxy.Mode, []proxy.Backend, error) {
+			if fullMethodName == "/talos.testproto.TestService/PingEmpty" {
+				return proxy.One2One, []proxy.Backend{&proxy.DialBackend{Pool: pool, Target: "testnode://worker-4"}}, nil
+			}
+
+			return proxy.One2One, []proxy.Backend{&proxy.DialBackend{Pool: pool, Target: "testnode://worker-3"}}, nil
+		}
+	})
+
+	for i := 0; i < 3; i++ {
+		out, err := h.client.Ping(testContext(t), &pb.PingRequest{Value: "foo"})
+		require.NoError(t, err)
+		assert.Equal(t, "foo", out.Value)
+	}
+
+	_, err := h.client.PingEmpty(testContext(t), &pb.Empty{})
+	require.Error(t, err)
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	assert.Contains(t, status.Convert(err).Message(), "unknown node")
+
+	addr, err := proxy.Resolve(context.Background(), "127.0.0.1:1234")
+	require.NoError(t, err)
+	assert.Equal(t, "127.0.0.1:1234", addr)
+}
+
+func TestConnPoolResolveTTL(t *testing.T) {
+	var resolved atomic.Int32
+
+	pool := proxy.NewConnPool(grpc.WithTransportCredentials(insecure.NewCredentials()))
+	pool.ResolveTTL = time.Hour
+	pool.Resolver = func(ctx context.Context, target string) (string, error) {
+		resolved.Add(1)
+
+		return "127.0.0.1:1", nil
+	}
+
+	t.Cleanup(func() { pool.Close() }) //nolint: errcheck
+
+	for i := 0; i < 3; i++ {
+		_, err := pool.Get(testContext(t), "node")
+		require.NoError(t, err)
+	}
+
+	assert.EqualValues(t, 1, resolved.Load())
+	assert.EqualValues(t, 1, pool.Stats().Dials)
+}
+
+func TestConnPoolDialOutsideLock(t *testing.T) {
+	pool := proxy.NewConnPool(grpc.WithTransportCredentials(insecure.NewCredentials()), grpc.WithBlock())
+	pool.Resolver = func(ctx context.Context, target string) (string, error) {
+		return target, nil
+	}
+
+	t.Cleanup(func() { pool.Close() }) //nolint: errcheck
+
+	h := newTestHarness(t, one2oneDirector)
+
+	// the blocking dial to the unreachable address doesn't stall the other targets
+	stuckCtx, cancel := context.WithTimeout(testContext(t), 5*time.Second)
+	defer cancel()
+
+	go pool.Get(stuckCtx, "127.0.0.1:1") //nolint: errcheck
+
+	time.Sleep(50 * time.Millisecond)
+
+	start := time.Now()
+
+	_, err := pool.Get(testContext(t), h.backendAddr)
+	require.NoError(t, err)
+
+	assert.Less(t, time.Since(start), 2*time.Second)
+}
+
+func TestConnPoolRetireDrains(t *testing.T) {
+	first := newTestHarness(t, one2oneDirector)
+	second := newTestHarness(t, one2oneDirector)
+
+	var addr atomic.Value
+
+	addr.Store(first.backendAddr)
+
+	pool := proxy.NewConnPool(grpc.WithTransportCredentials(insecure.NewCredentials()))
+	pool.ResolveTTL = -1
+	pool.Resolver = func(ctx context.Context, target string) (string, error) {
+		return addr.Load().(string), nil //nolint: forcetypeassert
+	}
+
+	t.Cleanup(func() { pool.Close() }) //nolint: errcheck
+
+	ctx := testContext(t)
+	events := pool.WatchState(ctx)
+
+	h := newTestHarness(t, func(proxy.Backend) proxy.StreamDirector {
+		return func(ctx context.Context, fullMethodName string) (proxy.Mode, []proxy.Backend, error) {
+			return proxy.One2One, []proxy.Backend{&proxy.DialBackend{Pool: pool, Target: "node"}}, nil
+		}
+	})
+
+	stream, err := h.client.PingStream(ctx)
+	require.NoError(t, err)
+
+	ping := func() {
+		require.NoError(t, stream.Send(&pb.PingRequest{Value: "foo"}))
+
+		out, err := stream.Recv()
+		require.NoError(t, err)
+		assert.Equal(t, "foo", out.Value)
+	}
+
+	ping()
+
+	// the target moves, the new calls go to the new address
+	addr.Store(second.backendAddr)
+
+	_, err = h.client.Ping(ctx, &pb.PingRequest{Value: "foo"})
+	require.NoError(t, err)
+
+	shutdown := func() bool {
+		for {
+			select {
+			case event := <-events:
+				if event.Addr == first.backendAddr && event.State == connectivity.Shutdown {
+					return true
+				}
+			default:
+				return false
+			}
+		}
+	}
+
+	// the stream in flight continues over the retired connection past the grace period
+	time.Sleep(1500 * time.Millisecond)
+
+	ping()
+	assert.False(t, shutdown())
+
+	require.NoError(t, stream.CloseSend())
+
+	_, err = stream.Recv()
+	require.ErrorIs(t, err, io.EOF)
+
+	// the retired connection is closed once drained
+	require.Eventually(t, shutdown, 5*time.Second, 10*time.Millisecond)
+}
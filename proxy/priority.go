@@ -0,0 +1,46 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy
+
+// PriorityBackend is an optional interface implemented by backends to order the responses of one2many unary calls.
+type PriorityBackend interface {
+	Backend
+
+	// Priority returns the position of the backend response in the aggregated one2many unary response.
+	//
+	// Responses are ordered by ascending priority, responses of the backends with the same priority are ordered
+	// by arrival. Backends which don't implement PriorityBackend have priority zero.
+	Priority() int
+}
+
+// BackendWithPriority wraps the backend to set the priority of its responses, see PriorityBackend.
+//
+// The director might use the index of the backend in the returned list as the priority, so that the clients get
+// the responses in deterministic order.
+func BackendWithPriority(backend Backend, priority int) PriorityBackend {
+	return &priorityBackend{Backend: backend, priority: priority}
+}
+
+type priorityBackend struct {
+	Backend
+
+	priority int
+}
+
+func (b *priorityBackend) Priority() int {
+	return b.priority
+}
+
+// Unwrap returns the wrapped backend.
+func (b *priorityBackend) Unwrap() Backend {
+	return b.Backend
+}
+
+func backendPriority(backend Backend) int {
+	if pb, ok := backendAs[PriorityBackend](backend); ok {
+		return pb.Priority()
+	}
+
+	return 0
+}
@@ -0,0 +1,204 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+// ProgressDeadlinePolicy extends the deadline of the upstream calls while the backends make progress.
+type ProgressDeadlinePolicy struct {
+	// IsProgress reports whether the response is the progress frame, if set.
+	//
+	// By default every response received from the backends is the progress.
+	IsProgress func(fullMethodName string, payload []byte) bool
+	// Idle is the time the upstream calls are allowed to run without the progress.
+	Idle time.Duration
+	// Max is the maximum total duration of the upstream calls, zero means no limit.
+	Max time.Duration
+}
+
+// WithProgressDeadline sets the progress deadline policy for the listed methods.
+//
+// The upstream calls are canceled if no progress is observed for Idle since the start of the call or the last
+// progress, and in any case once Max passes since the start of the call, so that the long operations with the duration
+// unknown a priori can run as long as they make progress, but die quickly when stalled. The deadline is enforced by
+// the proxy, the backends don't see it in the call timeout. The expired calls fail with ErrProgressTimeout.
+// If fullMethodNames is empty, the policy is applied to all methods without a method-specific policy.
+func WithProgressDeadline(policy ProgressDeadlinePolicy, fullMethodNames ...string) Option {
+	return func(o *handlerOptions) {
+		if o.progressDeadlines == nil {
+			o.progressDeadlines = map[string]ProgressDeadlinePolicy{}
+		}
+
+		if len(fullMethodNames) == 0 {
+			o.progressDeadlines[""] = policy
+
+			return
+		}
+
+		for _, name := range fullMethodNames {
+			o.progressDeadlines[name] = policy
+		}
+	}
+}
+
+// progressDeadline returns the upstream context canceled by the progress deadline, the deadline is nil if
+// the policy is not configured for the method.
+func (o *handlerOptions) progressDeadline(ctx context.Context, fullMethodName string) (context.Context, *progressDeadline) {
+	policy, ok := o.progressDeadlines[fullMethodName]
+	if !ok {
+		policy, ok = o.progressDeadlines[""]
+	}
+
+	if !ok || policy.Idle <= 0 {
+		return ctx, nil
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+
+	d := &progressDeadline{
+		policy: policy,
+		method: fullMethodName,
+		cancel: cancel,
+		start:  time.Now(),
+	}
+
+	d.timer = time.AfterFunc(d.next(d.start), d.expire)
+
+	return ctx, d
+}
+
+// progressDeadline cancels the upstream calls which don't make progress.
+//
+// All methods are safe to be called on nil deadline.
+type progressDeadline struct {
+	start  time.Time
+	timer  *time.Timer
+	cancel context.CancelFunc
+	policy ProgressDeadlinePolicy
+	method string
+
+	mu      sync.Mutex
+	expired bool
+	stopped bool
+}
+
+// next returns the time until the deadline after the progress at now.
+func (d *progressDeadline) next(now time.Time) time.Duration {
+	timeout := d.policy.Idle
+
+	if d.policy.Max > 0 {
+		if remaining := d.policy.Max - now.Sub(d.start); remaining < timeout {
+			timeout = remaining
+		}
+	}
+
+	return timeout
+}
+
+func (d *progressDeadline) expire() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.stopped {
+		return
+	}
+
+	d.expired = true
+
+	d.cancel()
+}
+
+// progress extends the deadline.
+func (d *progressDeadline) progress(payload []byte) {
+	if d == nil {
+		return
+	}
+
+	if d.policy.IsProgress != nil && !d.policy.IsProgress(d.method, payload) {
+		return
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.expired || d.stopped {
+		return
+	}
+
+	d.timer.Reset(d.next(time.Now()))
+}
+
+// err returns the progress timeout error if the deadline expired.
+func (d *progressDeadline) err() error {
+	if d == nil {
+		return nil
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if !d.expired {
+		return nil
+	}
+
+	return newError(ErrProgressTimeout, "upstream didn't make progress within %s", d.policy.Idle)
+}
+
+// stop stops the timer once the call is done.
+func (d *progressDeadline) stop() {
+	if d == nil {
+		return
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.stopped = true
+
+	d.timer.Stop()
+}
+
+// wrap observes the progress of the upstream streams.
+func (d *progressDeadline) wrap(backendConnections []backendConnection) {
+	if d == nil {
+		return
+	}
+
+	for i := range backendConnections {
+		if backendConnections[i].clientStream == nil {
+			continue
+		}
+
+		backendConnections[i].clientStream = &progressDeadlineClientStream{ClientStream: backendConnections[i].clientStream, deadline: d}
+	}
+}
+
+type progressDeadlineClientStream struct {
+	grpc.ClientStream
+
+	deadline *progressDeadline
+}
+
+func (s *progressDeadlineClientStream) RecvMsg(m interface{}) error {
+	err := s.ClientStream.RecvMsg(m)
+	if err != nil {
+		if deadlineErr := s.deadline.err(); deadlineErr != nil {
+			return deadlineErr
+		}
+
+		return err
+	}
+
+	if f, ok := m.(*Frame); ok {
+		s.deadline.progress(f.payload)
+	}
+
+	return nil
+}
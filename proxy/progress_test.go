@@ -0,0 +1,120 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy_test
+
+import (
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/noncepad/grpc-proxy/proxy"
+	pb "github.com/noncepad/grpc-proxy/testservice"
+)
+
+// slowListService sends 10 PingList responses 50ms apart, the request "stall" stalls after the second response.
+type slowListService struct {
+	lenientService
+}
+
+func (s *slowListService) PingList(ping *pb.PingRequest, stream pb.TestService_PingListServer) error {
+	for counter := int32(0); counter < 10; counter++ {
+		if ping.Value == "stall" && counter == 2 {
+			<-stream.Context().Done()
+
+			return stream.Context().Err()
+		}
+
+		time.Sleep(50 * time.Millisecond)
+
+		if err := stream.Send(&pb.PingResponse{Value: ping.Value, Counter: counter}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func TestProgressDeadline(t *testing.T) {
+	for _, tt := range []struct {
+		name   string
+		value  string
+		policy proxy.ProgressDeadlinePolicy
+		// received is the number of the responses received before the failure, zero if it depends on the timing
+		received int
+		failed   bool
+	}{
+		{
+			name:     "progress",
+			policy:   proxy.ProgressDeadlinePolicy{Idle: 200 * time.Millisecond},
+			received: 10,
+		},
+		{
+			name:     "stall",
+			value:    "stall",
+			policy:   proxy.ProgressDeadlinePolicy{Idle: 200 * time.Millisecond},
+			received: 2,
+			failed:   true,
+		},
+		{
+			name:  "max",
+			value: "max",
+			policy: proxy.ProgressDeadlinePolicy{
+				Idle: 200 * time.Millisecond,
+				Max:  275 * time.Millisecond,
+			},
+			failed: true,
+		},
+		{
+			name:  "not progress",
+			value: "ignored",
+			policy: proxy.ProgressDeadlinePolicy{
+				IsProgress: func(fullMethodName string, payload []byte) bool { return false },
+				Idle:       175 * time.Millisecond,
+			},
+			failed: true,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestHarnessWithService(t, &slowListService{}, one2oneDirector, proxy.WithProgressDeadline(tt.policy))
+
+			stream, err := h.client.PingList(testContext(t), &pb.PingRequest{Value: tt.value})
+			require.NoError(t, err)
+
+			received := 0
+
+			for {
+				_, err = stream.Recv()
+				if err != nil {
+					break
+				}
+
+				received++
+			}
+
+			if tt.received > 0 {
+				assert.Equal(t, tt.received, received)
+			} else {
+				assert.Less(t, received, 10)
+			}
+
+			if !tt.failed {
+				assert.True(t, errors.Is(err, io.EOF), "unexpected error %v", err)
+
+				return
+			}
+
+			assert.Equal(t, codes.DeadlineExceeded, status.Code(err))
+
+			proxyErr, ok := proxy.FromError(err)
+			require.True(t, ok)
+			assert.ErrorIs(t, proxyErr, proxy.ErrProgressTimeout)
+		})
+	}
+}
@@ -0,0 +1,88 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy
+
+import (
+	"errors"
+	"io"
+	"regexp"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	// unexpectedHTTPStatus matches the error of the gRPC transport for the non-200 HTTP responses.
+	unexpectedHTTPStatus = regexp.MustCompile(`unexpected HTTP status code received from server: (\d+) \(([^)]*)\)`)
+	// unexpectedContentType matches the error of the gRPC transport for the non-gRPC content types.
+	unexpectedContentType = regexp.MustCompile(`received unexpected content-type "([^"]*)"`)
+)
+
+// translateProtocolError converts the errors of the gRPC transport caused by the upstream which is not speaking gRPC
+// (e.g. the HTML error page of the L7 load balancer, or the HTTP/1.1 server) into ErrUpstreamProtocol with
+// the diagnostic details, other errors are returned as is.
+func translateProtocolError(backend Backend, err error) error {
+	if err == nil || errors.Is(err, io.EOF) {
+		return err
+	}
+
+	st, ok := status.FromError(err)
+	if !ok || st.Code() == codes.OK {
+		return err
+	}
+
+	msg := st.Message()
+
+	var details []string
+
+	if m := unexpectedHTTPStatus.FindStringSubmatch(msg); m != nil {
+		details = append(details, "HTTP status "+m[1]+" ("+m[2]+")")
+	}
+
+	if m := unexpectedContentType.FindStringSubmatch(msg); m != nil {
+		details = append(details, "content-type "+m[1])
+	} else if strings.Contains(msg, "malformed header: missing HTTP content-type") {
+		details = append(details, "no content-type")
+	}
+
+	if strings.Contains(msg, "server preface") {
+		details = append(details, "no HTTP/2 server preface")
+	}
+
+	if len(details) == 0 {
+		return err
+	}
+
+	return &Error{
+		Err:     err,
+		Code:    ErrUpstreamProtocol.Code,
+		Reason:  ErrUpstreamProtocol.Reason,
+		Message: "upstream " + backend.String() + " is not speaking gRPC: " + strings.Join(details, ", "),
+		Backend: backend.String(),
+	}
+}
+
+// protocolErrorClientStream translates the upstream protocol errors, see translateProtocolError.
+type protocolErrorClientStream struct {
+	grpc.ClientStream
+
+	backend Backend
+}
+
+func (s *protocolErrorClientStream) Header() (metadata.MD, error) {
+	md, err := s.ClientStream.Header()
+
+	return md, translateProtocolError(s.backend, err)
+}
+
+func (s *protocolErrorClientStream) SendMsg(m interface{}) error {
+	return translateProtocolError(s.backend, s.ClientStream.SendMsg(m))
+}
+
+func (s *protocolErrorClientStream) RecvMsg(m interface{}) error {
+	return translateProtocolError(s.backend, s.ClientStream.RecvMsg(m))
+}
@@ -0,0 +1,123 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy_test
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+
+	"github.com/noncepad/grpc-proxy/proxy"
+	pb "github.com/noncepad/grpc-proxy/testservice"
+)
+
+func TestUpstreamProtocolErrors(t *testing.T) {
+	// the HTTP/2 load balancer responding with the error page
+	lb := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/talos.testproto.TestService/PingEmpty" {
+			w.Header().Set("Content-Type", "text/html")
+			w.Write([]byte("<html>hello</html>")) //nolint:errcheck
+
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/html")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte("<html>503 Service Unavailable</html>")) //nolint:errcheck
+	}))
+	lb.EnableHTTP2 = true
+	lb.StartTLS()
+	t.Cleanup(lb.Close)
+
+	// the HTTP/1.1 server
+	http1 := httptest.NewServer(http.NotFoundHandler())
+	t.Cleanup(http1.Close)
+
+	dial := func(target string, creds credentials.TransportCredentials) proxy.Backend {
+		conn, err := grpc.Dial(target, grpc.WithTransportCredentials(creds), grpc.WithCodec(proxy.Codec())) //nolint:staticcheck
+		require.NoError(t, err)
+
+		t.Cleanup(func() { conn.Close() }) //nolint:errcheck
+
+		return &taggedBackend{
+			tag: target,
+			Backend: &proxy.SingleBackend{
+				GetConn: func(ctx context.Context) (context.Context, *grpc.ClientConn, error) {
+					return ctx, conn, nil
+				},
+			},
+		}
+	}
+
+	lbBackend := dial(lb.Listener.Addr().String(), credentials.NewTLS(&tls.Config{InsecureSkipVerify: true})) //nolint:gosec
+	http1Backend := dial(http1.Listener.Addr().String(), insecure.NewCredentials())
+
+	var backend proxy.Backend
+
+	h := newTestHarness(t, func(proxy.Backend) proxy.StreamDirector {
+		return func(ctx context.Context, fullMethodName string) (proxy.Mode, []proxy.Backend, error) {
+			return proxy.One2One, []proxy.Backend{backend}, nil
+		}
+	})
+
+	for _, tc := range []struct {
+		name    string
+		backend proxy.Backend
+		call    func(ctx context.Context) error
+		message string
+	}{
+		{
+			name:    "error page",
+			backend: lbBackend,
+			call: func(ctx context.Context) error {
+				_, err := h.client.Ping(ctx, &pb.PingRequest{Value: "foo"})
+
+				return err
+			},
+			message: "is not speaking gRPC: HTTP status 503 (Service Unavailable), content-type text/html",
+		},
+		{
+			name:    "html page",
+			backend: lbBackend,
+			call: func(ctx context.Context) error {
+				_, err := h.client.PingEmpty(ctx, &pb.Empty{})
+
+				return err
+			},
+			message: "is not speaking gRPC: content-type text/html",
+		},
+		{
+			name:    "http1",
+			backend: http1Backend,
+			call: func(ctx context.Context) error {
+				_, err := h.client.Ping(ctx, &pb.PingRequest{Value: "foo"})
+
+				return err
+			},
+			message: "is not speaking gRPC: no HTTP/2 server preface",
+		},
+	} {
+		backend = tc.backend
+
+		err := tc.call(testContext(t))
+		require.Error(t, err, tc.name)
+		assert.Equal(t, codes.Unavailable, status.Code(err), tc.name)
+		assert.Contains(t, status.Convert(err).Message(), tc.message, tc.name)
+
+		proxyErr, ok := proxy.FromError(err)
+		require.True(t, ok, tc.name)
+		assert.Equal(t, proxy.ReasonUpstreamProtocol, proxyErr.Reason, tc.name)
+		assert.Equal(t, tc.backend.String(), proxyErr.Backend, tc.name)
+	}
+}
@@ -4,7 +4,12 @@
 
 package proxy
 
-import "google.golang.org/grpc"
+import (
+	"fmt"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/grpclog"
+)
 
 // Mode specifies proxying mode: one2one (transparent) or one2many (aggregation, error wrapping).
 type Mode int
@@ -15,6 +20,17 @@ const (
 	One2Many
 )
 
+func (m Mode) String() string {
+	switch m {
+	case One2One:
+		return "one2one"
+	case One2Many:
+		return "one2many"
+	default:
+		return "unknown"
+	}
+}
+
 // StreamedDetectorFunc reports is gRPC is doing streaming (only for one2many proxying).
 type StreamedDetectorFunc func(fullMethodName string) bool
 
@@ -31,9 +47,13 @@ func WithMethodNames(methodNames ...string) Option {
 // WithStreamedMethodNames configures list of streamed method names.
 //
 // This is only important for one2many proxying.
-// This option can't be used with TransparentHandler.
+// This option can't be used with TransparentHandler, and it can't be combined with WithStreamedDetector.
 func WithStreamedMethodNames(streamedMethodNames ...string) Option {
 	return func(o *handlerOptions) {
+		if o.streamedDetector != nil && o.streamedMethods == nil {
+			o.invalid("WithStreamedMethodNames conflicts with WithStreamedDetector")
+		}
+
 		o.streamedMethods = map[string]struct{}{}
 
 		for _, methodName := range streamedMethodNames {
@@ -51,8 +71,13 @@ func WithStreamedMethodNames(streamedMethodNames ...string) Option {
 // WithStreamedDetector configures a function to detect streamed methods.
 //
 // This is only important for one2many proxying.
+// This option can't be combined with WithStreamedMethodNames.
 func WithStreamedDetector(detector StreamedDetectorFunc) Option {
 	return func(o *handlerOptions) {
+		if o.streamedMethods != nil {
+			o.invalid("WithStreamedDetector conflicts with WithStreamedMethodNames")
+		}
+
 		o.streamedDetector = detector
 	}
 }
@@ -61,16 +86,28 @@ func WithStreamedDetector(detector StreamedDetectorFunc) Option {
 // The behavior is the same as if you were registering a handler method, e.g. from a codegenerated pb.go file.
 //
 // This can *only* be used if the `server` also uses grpc.CustomCodec() ServerOption.
-func RegisterService(server grpc.ServiceRegistrar, director StreamDirector, serviceName string, options ...Option) {
-	streamer := &handler{
-		director: director,
-		options: handlerOptions{
-			serviceName: serviceName,
-		},
+//
+// The services can't be registered once the server is serving, see ServiceRegistry for the services which can be
+// added and removed at runtime.
+//
+// The service is not registered if the options are invalid (ErrInvalidOptions), or the service is already
+// registered with the server.
+func RegisterService(server grpc.ServiceRegistrar, director StreamDirector, serviceName string, options ...Option) error {
+	streamer, err := newHandler(director, serviceName, false, options)
+	if err != nil {
+		return err
 	}
 
-	for _, o := range options {
-		o(&streamer.options)
+	if len(streamer.options.methodNames) == 0 {
+		return fmt.Errorf("%w: no method names to proxy for service %q, see WithMethodNames", ErrInvalidOptions, serviceName)
+	}
+
+	if info, ok := server.(interface {
+		GetServiceInfo() map[string]grpc.ServiceInfo
+	}); ok {
+		if _, registered := info.GetServiceInfo()[serviceName]; registered {
+			return fmt.Errorf("service %q is already registered", serviceName)
+		}
 	}
 
 	fakeDesc := &grpc.ServiceDesc{
@@ -89,6 +126,10 @@ func RegisterService(server grpc.ServiceRegistrar, director StreamDirector, serv
 	}
 
 	server.RegisterService(fakeDesc, streamer)
+
+	streamer.options.topology.register(streamer, false)
+
+	return nil
 }
 
 // TransparentHandler returns a handler that attempts to proxy all requests that are not registered in the server.
@@ -96,14 +137,16 @@ func RegisterService(server grpc.ServiceRegistrar, director StreamDirector, serv
 // backends. It should be used as a `grpc.UnknownServiceHandler`.
 //
 // This can *only* be used if the `server` also uses grpc.CustomCodec() ServerOption.
+//
+// TransparentHandler logs the invalid options with grpclog and proceeds with the options applied as configured,
+// see NewTransparentHandler to fail instead.
 func TransparentHandler(director StreamDirector, options ...Option) grpc.StreamHandler {
-	streamer := &handler{
-		director: director,
+	streamer, err := newHandler(director, "", true, options)
+	if err != nil {
+		grpclog.Errorf("grpc-proxy: TransparentHandler: %v", err)
 	}
 
-	for _, o := range options {
-		o(&streamer.options)
-	}
+	streamer.options.topology.register(streamer, true)
 
 	return streamer.handler
 }
@@ -0,0 +1,173 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy
+
+import (
+	"context"
+	"errors"
+	"net"
+	"time"
+)
+
+// defaultRaceDelay is the default head start of the connection attempt (see RFC 8305).
+const defaultRaceDelay = 250 * time.Millisecond
+
+// DialRacing races the connection attempts to the addresses of the backend ("happy eyeballs"): the attempts are
+// started one after another with the head start of Delay (or as soon as the previous attempt fails), the first
+// established connection wins and the other attempts are canceled.
+//
+// It cuts the latency of the first call to the backend with several addresses (IPv6 and IPv4, multiple endpoints)
+// when some of them are unreachable or slow to respond.
+type DialRacing struct {
+	// Addresses returns the addresses to race for the address of the target, if set.
+	//
+	// By default the host is resolved with DNS, and the addresses are interleaved by the address family,
+	// starting with IPv6.
+	Addresses func(ctx context.Context, addr string) ([]string, error)
+
+	// Delay is the head start of each attempt before the next address is attempted, 250ms by default.
+	Delay time.Duration
+}
+
+// dial resolves the addresses and races the connection attempts.
+func (r *DialRacing) dial(ctx context.Context, addr string) (net.Conn, error) {
+	addrs, err := r.addresses(ctx, addr)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.race(ctx, addrs)
+}
+
+// addresses returns the addresses to race.
+func (r *DialRacing) addresses(ctx context.Context, addr string) ([]string, error) {
+	if r.Addresses != nil {
+		return r.Addresses(ctx, addr)
+	}
+
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+
+	if net.ParseIP(host) != nil {
+		return []string{addr}, nil
+	}
+
+	ips, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+
+	return interleaveFamilies(ips, port), nil
+}
+
+// interleaveFamilies orders the addresses alternating IPv6 and IPv4 (RFC 8305, section 4).
+func interleaveFamilies(ips []net.IPAddr, port string) []string {
+	var v6, v4 []string
+
+	for _, ip := range ips {
+		if ip.IP.To4() == nil {
+			v6 = append(v6, net.JoinHostPort(ip.String(), port))
+		} else {
+			v4 = append(v4, net.JoinHostPort(ip.String(), port))
+		}
+	}
+
+	addrs := make([]string, 0, len(ips))
+
+	for i := 0; i < len(v6) || i < len(v4); i++ {
+		if i < len(v6) {
+			addrs = append(addrs, v6[i])
+		}
+
+		if i < len(v4) {
+			addrs = append(addrs, v4[i])
+		}
+	}
+
+	return addrs
+}
+
+// race dials the addresses returning the first connection established, the error of the first failed attempt
+// is returned if all of them fail.
+func (r *DialRacing) race(ctx context.Context, addrs []string) (net.Conn, error) {
+	if len(addrs) == 0 {
+		return nil, errors.New("no addresses to dial")
+	}
+
+	delay := r.Delay
+	if delay <= 0 {
+		delay = defaultRaceDelay
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type attempt struct {
+		conn net.Conn
+		err  error
+	}
+
+	var (
+		dialer    net.Dialer
+		next      int
+		pending   int
+		nextStart <-chan time.Time
+		firstErr  error
+	)
+
+	results := make(chan attempt, len(addrs))
+
+	start := func() {
+		addr := addrs[next]
+		next++
+		pending++
+
+		go func() {
+			conn, err := dialer.DialContext(ctx, "tcp", addr)
+			results <- attempt{conn: conn, err: err}
+		}()
+
+		nextStart = nil
+
+		if next < len(addrs) {
+			nextStart = time.After(delay)
+		}
+	}
+
+	start()
+
+	for pending > 0 {
+		select {
+		case <-nextStart:
+			start()
+		case res := <-results:
+			pending--
+
+			if res.err == nil {
+				// the losers are canceled, the connections established meanwhile are closed
+				go func(n int) {
+					for i := 0; i < n; i++ {
+						if lost := <-results; lost.conn != nil {
+							lost.conn.Close() //nolint:errcheck
+						}
+					}
+				}(pending)
+
+				return res.conn, nil
+			}
+
+			if firstErr == nil {
+				firstErr = res.err
+			}
+
+			if next < len(addrs) {
+				start()
+			}
+		}
+	}
+
+	return nil, firstErr
+}
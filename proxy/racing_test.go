@@ -0,0 +1,88 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy_test
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+
+	"github.com/noncepad/grpc-proxy/proxy"
+	pb "github.com/noncepad/grpc-proxy/testservice"
+)
+
+func TestDialRacing(t *testing.T) {
+	// reserve the address with no upstream
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	closedAddr := listener.Addr().String()
+	require.NoError(t, listener.Close())
+
+	for _, tt := range []struct {
+		name      string
+		telemetry bool
+	}{
+		{name: "plain"},
+		{name: "telemetry", telemetry: true},
+	} {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			var backendAddr string
+
+			pool := proxy.NewConnPool(grpc.WithTransportCredentials(insecure.NewCredentials()))
+			pool.Racing = &proxy.DialRacing{
+				Addresses: func(ctx context.Context, addr string) ([]string, error) {
+					if addr == "unreachable:1" {
+						return []string{closedAddr}, nil
+					}
+
+					// the blackhole address (TEST-NET-1) never connects
+					return []string{"192.0.2.1:1", closedAddr, backendAddr}, nil
+				},
+				Delay: 50 * time.Millisecond,
+			}
+
+			if tt.telemetry {
+				pool.OnDial = func(proxy.DialStats) {}
+			}
+
+			t.Cleanup(func() { pool.Close() }) //nolint: errcheck
+
+			h := newTestHarness(t, func(proxy.Backend) proxy.StreamDirector {
+				return func(ctx context.Context, fullMethodName string) (proxy.Mode, []proxy.Backend, error) {
+					if fullMethodName == "/talos.testproto.TestService/PingEmpty" {
+						return proxy.One2One, []proxy.Backend{&proxy.DialBackend{Pool: pool, Target: "unreachable:1"}}, nil
+					}
+
+					return proxy.One2One, []proxy.Backend{&proxy.DialBackend{Pool: pool, Target: "backend:1"}}, nil
+				}
+			})
+
+			backendAddr = h.backendAddr
+
+			ctx, cancel := context.WithTimeout(testContext(t), 5*time.Second)
+			defer cancel()
+
+			start := time.Now()
+
+			out, err := h.client.Ping(ctx, &pb.PingRequest{Value: "foo"})
+			require.NoError(t, err)
+			assert.Equal(t, "foo", out.Value)
+			assert.Less(t, time.Since(start), 2*time.Second)
+
+			_, err = h.client.PingEmpty(ctx, &pb.Empty{})
+			assert.Equal(t, codes.Unavailable, status.Code(err))
+		})
+	}
+}
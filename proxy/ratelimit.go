@@ -0,0 +1,150 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// RateLimitStore keeps the counters of the rate limiter.
+//
+// With the external store (e.g. the Redis store of package proxy/redisstore) shared by the proxy instances,
+// the limits are enforced globally across the fleet rather than per process.
+type RateLimitStore interface {
+	// Increment increments the counter of the key and returns the new value. The counter expires after the window,
+	// which starts with the first increment.
+	Increment(ctx context.Context, key string, window time.Duration) (int64, error)
+}
+
+// RateLimitKeyFunc returns the key the calls are limited by (e.g. the client or the tenant ID from the metadata),
+// empty key means the call is not limited.
+type RateLimitKeyFunc func(ctx context.Context, fullMethodName string) string
+
+// RateLimitPolicy limits the number of calls per window.
+type RateLimitPolicy struct {
+	Store RateLimitStore
+	// Limit is the number of calls allowed per Window for each key.
+	Limit  int64
+	Window time.Duration
+	// Key returns the key of the call, nil means all calls share the same limit.
+	Key RateLimitKeyFunc
+	// FailOpen allows the calls if the store fails, otherwise they fail with ErrRateLimited.
+	FailOpen bool
+}
+
+// WithMethodRateLimit limits the rate of the calls to the method, empty method name applies the limit
+// to all methods without their own limit.
+//
+// The calls over the limit fail with codes.ResourceExhausted before the director is invoked. The counters are fixed
+// windows kept in the policy Store, see NewMemoryRateLimitStore and package proxy/redisstore.
+func WithMethodRateLimit(fullMethodName string, policy RateLimitPolicy) Option {
+	return func(o *handlerOptions) {
+		if policy.Store == nil || policy.Limit <= 0 || policy.Window <= 0 {
+			o.invalid("rate limit of %q should have the store, positive limit and window", fullMethodName)
+		}
+
+		if o.rateLimits == nil {
+			o.rateLimits = map[string]*RateLimitPolicy{}
+		}
+
+		o.rateLimits[fullMethodName] = &policy
+	}
+}
+
+// checkRateLimit counts the call against the rate limit of the method.
+func (o *handlerOptions) checkRateLimit(ctx context.Context, fullMethodName string) error {
+	if o.rateLimits == nil {
+		return nil
+	}
+
+	scope := fullMethodName
+
+	policy, ok := o.rateLimits[scope]
+	if !ok {
+		scope = ""
+
+		if policy, ok = o.rateLimits[scope]; !ok {
+			return nil
+		}
+	}
+
+	key := "ratelimit/" + scope
+
+	if policy.Key != nil {
+		callKey := policy.Key(ctx, fullMethodName)
+		if callKey == "" {
+			return nil
+		}
+
+		key += "/" + callKey
+	}
+
+	count, err := policy.Store.Increment(ctx, key, policy.Window)
+	if err != nil {
+		if policy.FailOpen {
+			return nil
+		}
+
+		return newError(ErrRateLimited, "error checking rate limit of %s: %v", fullMethodName, err)
+	}
+
+	if count > policy.Limit {
+		return newError(ErrRateLimited, "rate limit of %s exceeded: %d calls per %s", fullMethodName, policy.Limit, policy.Window)
+	}
+
+	return nil
+}
+
+// MemoryRateLimitStore is the in-process RateLimitStore.
+type MemoryRateLimitStore struct {
+	mu       sync.Mutex
+	counters map[string]*memoryCounter
+}
+
+type memoryCounter struct {
+	count   int64
+	expires time.Time
+}
+
+// NewMemoryRateLimitStore creates the in-process RateLimitStore, the limits are enforced per process.
+func NewMemoryRateLimitStore() *MemoryRateLimitStore {
+	return &MemoryRateLimitStore{
+		counters: map[string]*memoryCounter{},
+	}
+}
+
+// Increment implements RateLimitStore.
+func (s *MemoryRateLimitStore) Increment(_ context.Context, key string, window time.Duration) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+
+	counter, ok := s.counters[key]
+	if !ok || !now.Before(counter.expires) {
+		s.evictLocked(now)
+
+		counter = &memoryCounter{expires: now.Add(window)}
+		s.counters[key] = counter
+	}
+
+	counter.count++
+
+	return counter.count, nil
+}
+
+// evictLocked removes the expired counters once the map grows.
+func (s *MemoryRateLimitStore) evictLocked(now time.Time) {
+	if len(s.counters) < 1024 {
+		return
+	}
+
+	for key, counter := range s.counters {
+		if !now.Before(counter.expires) {
+			delete(s.counters, key)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/noncepad/grpc-proxy/proxy"
+	pb "github.com/noncepad/grpc-proxy/testservice"
+)
+
+// unavailableStore is the RateLimitStore failing all increments.
+type unavailableStore struct{}
+
+func (unavailableStore) Increment(context.Context, string, time.Duration) (int64, error) {
+	return 0, errors.New("store unavailable")
+}
+
+func TestMethodRateLimit(t *testing.T) {
+	policy := proxy.RateLimitPolicy{
+		Store:  proxy.NewMemoryRateLimitStore(),
+		Limit:  2,
+		Window: time.Minute,
+	}
+
+	h := newTestHarness(t, one2oneDirector, proxy.WithMethodRateLimit("/talos.testproto.TestService/Ping", policy))
+
+	ctx := testContext(t)
+
+	for i := 0; i < 2; i++ {
+		_, err := h.client.Ping(ctx, &pb.PingRequest{Value: "foo"})
+		require.NoError(t, err)
+	}
+
+	_, err := h.client.Ping(ctx, &pb.PingRequest{Value: "foo"})
+	require.Error(t, err)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	proxyErr, ok := proxy.FromError(err)
+	require.True(t, ok)
+	assert.Equal(t, proxy.ReasonRateLimited, proxyErr.Reason)
+
+	// other methods are not limited
+	_, err = h.client.PingList(ctx, &pb.PingRequest{Value: "foo"})
+	require.NoError(t, err)
+}
+
+func TestRateLimitSharedStore(t *testing.T) {
+	policy := proxy.RateLimitPolicy{
+		Store:  proxy.NewMemoryRateLimitStore(),
+		Limit:  3,
+		Window: time.Minute,
+		Key:    func(ctx context.Context, fullMethodName string) string { return "client" },
+	}
+
+	// two proxy instances share the limit
+	h1 := newTestHarness(t, one2oneDirector, proxy.WithMethodRateLimit("", policy))
+	h2 := newTestHarness(t, one2oneDirector, proxy.WithMethodRateLimit("", policy))
+
+	ctx := testContext(t)
+
+	for _, client := range []pb.TestServiceClient{h1.client, h2.client, h1.client} {
+		_, err := client.Ping(ctx, &pb.PingRequest{Value: "foo"})
+		require.NoError(t, err)
+	}
+
+	_, err := h2.client.Ping(ctx, &pb.PingRequest{Value: "foo"})
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	// the store failures fail the calls unless the policy fails open
+	policy.Store = unavailableStore{}
+
+	h3 := newTestHarness(t, one2oneDirector, proxy.WithMethodRateLimit("", policy))
+
+	_, err = h3.client.Ping(ctx, &pb.PingRequest{Value: "foo"})
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	policy.FailOpen = true
+	h4 := newTestHarness(t, one2oneDirector, proxy.WithMethodRateLimit("", policy))
+
+	_, err = h4.client.Ping(ctx, &pb.PingRequest{Value: "foo"})
+	require.NoError(t, err)
+}
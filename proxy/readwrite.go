@@ -0,0 +1,81 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy
+
+import (
+	"context"
+	"sync/atomic"
+
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// ReadWriteDirector splits the calls between the primary and the replica backends: the read-only methods are proxied
+// to the replicas, and the mutating methods are proxied to the primaries.
+//
+// The method is read-only if it is listed in ReadOnly, or if its descriptor has the option
+// idempotency_level = NO_SIDE_EFFECTS. The methods which are not known to be read-only (including the methods
+// without the descriptor) are proxied to the primaries, as it is always safe.
+//
+// Each call is proxied one2one to the backend of the group picked round-robin.
+type ReadWriteDirector struct {
+	// Primaries and Replicas return the current backends of the groups, e.g. BackendGroup.Backends.
+	Primaries func() []Backend
+	Replicas  func() []Backend
+
+	// Resolver looks up the method descriptors, the resolver of the global registry is used if nil.
+	Resolver *DescriptorResolver
+
+	// ReadOnly overrides the descriptors: true marks the method read-only, false marks it mutating.
+	ReadOnly map[string]bool
+
+	// ReplicaFallback proxies the read-only methods to the primaries if there are no replicas.
+	ReplicaFallback bool
+
+	primaryNext, replicaNext uint64
+}
+
+// Director is a StreamDirector.
+func (d *ReadWriteDirector) Director(ctx context.Context, fullMethodName string) (Mode, []Backend, error) {
+	if d.readOnly(ctx, fullMethodName) {
+		if backends := d.Replicas(); len(backends) > 0 {
+			return One2One, []Backend{pickRoundRobin(backends, &d.replicaNext)}, nil
+		}
+
+		if !d.ReplicaFallback {
+			return One2One, nil, newError(ErrNoBackends, "no replica backends for read-only method %s", fullMethodName)
+		}
+	}
+
+	backends := d.Primaries()
+	if len(backends) == 0 {
+		return One2One, nil, newError(ErrNoBackends, "no primary backends for %s", fullMethodName)
+	}
+
+	return One2One, []Backend{pickRoundRobin(backends, &d.primaryNext)}, nil
+}
+
+// readOnly checks whether the method is known to be read-only.
+func (d *ReadWriteDirector) readOnly(ctx context.Context, fullMethodName string) bool {
+	if readOnly, ok := d.ReadOnly[fullMethodName]; ok {
+		return readOnly
+	}
+
+	resolver := d.Resolver
+	if resolver == nil {
+		resolver = sharedResolver(nil)
+	}
+
+	methodDesc, err := resolver.FindMethod(ctx, fullMethodName)
+	if err != nil {
+		return false
+	}
+
+	options, ok := methodDesc.Options().(*descriptorpb.MethodOptions)
+
+	return ok && options.GetIdempotencyLevel() == descriptorpb.MethodOptions_NO_SIDE_EFFECTS
+}
+
+func pickRoundRobin(backends []Backend, next *uint64) Backend {
+	return backends[(atomic.AddUint64(next, 1)-1)%uint64(len(backends))]
+}
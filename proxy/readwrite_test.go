@@ -0,0 +1,99 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+
+	"github.com/noncepad/grpc-proxy/proxy"
+	pb "github.com/noncepad/grpc-proxy/testservice"
+)
+
+// readOnlyPingFiles returns the registry of the test service with Ping marked as having no side effects.
+func readOnlyPingFiles(t *testing.T) *protoregistry.Files {
+	t.Helper()
+
+	fileProto := protodesc.ToFileDescriptorProto(pb.File_test_proto)
+
+	for _, service := range fileProto.Service {
+		for _, method := range service.Method {
+			if method.GetName() == "Ping" {
+				method.Options = &descriptorpb.MethodOptions{IdempotencyLevel: descriptorpb.MethodOptions_NO_SIDE_EFFECTS.Enum()}
+			}
+		}
+	}
+
+	set := &descriptorpb.FileDescriptorSet{}
+
+	for i := 0; i < pb.File_test_proto.Imports().Len(); i++ {
+		set.File = append(set.File, protodesc.ToFileDescriptorProto(pb.File_test_proto.Imports().Get(i).FileDescriptor))
+	}
+
+	set.File = append(set.File, fileProto)
+
+	files, err := protodesc.NewFiles(set)
+	require.NoError(t, err)
+
+	return files
+}
+
+func TestReadWriteDirector(t *testing.T) {
+	files := readOnlyPingFiles(t)
+
+	var (
+		mu       sync.Mutex
+		directed []string
+	)
+
+	h := newTestHarness(t, func(backend proxy.Backend) proxy.StreamDirector {
+		d := &proxy.ReadWriteDirector{
+			Primaries: func() []proxy.Backend { return []proxy.Backend{&taggedBackend{Backend: backend, tag: "primary"}} },
+			Replicas: func() []proxy.Backend {
+				return []proxy.Backend{&taggedBackend{Backend: backend, tag: "replica1"}, &taggedBackend{Backend: backend, tag: "replica2"}}
+			},
+			Resolver: proxy.NewDescriptorResolver(files, nil),
+			ReadOnly: map[string]bool{"/talos.testproto.TestService/PingList": true},
+		}
+
+		return func(ctx context.Context, fullMethodName string) (proxy.Mode, []proxy.Backend, error) {
+			mode, backends, err := d.Director(ctx, fullMethodName)
+
+			mu.Lock()
+			directed = append(directed, backends[0].String())
+			mu.Unlock()
+
+			return mode, backends, err
+		}
+	})
+
+	ctx := testContext(t)
+
+	_, err := h.client.Ping(ctx, &pb.PingRequest{Value: "ping"})
+	require.NoError(t, err)
+
+	_, err = h.client.Ping(ctx, &pb.PingRequest{Value: "ping"})
+	require.NoError(t, err)
+
+	_, err = h.client.PingEmpty(ctx, &pb.Empty{})
+	require.NoError(t, err)
+
+	stream, err := h.client.PingList(ctx, &pb.PingRequest{Value: "ping"})
+	require.NoError(t, err)
+
+	_, err = stream.Recv()
+	require.NoError(t, err)
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	assert.Equal(t, []string{"replica1", "replica2", "primary", "replica1"}, directed)
+}
@@ -0,0 +1,83 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+// Package redisstore keeps the counters of the proxy rate limiter (see proxy.WithMethodRateLimit) in Redis,
+// so the limits are enforced globally across the proxy instances sharing the server:
+//
+//	client := redis.NewClient(&redis.Options{Addr: "localhost:6379", ContextTimeoutEnabled: true})
+//	defer client.Close()
+//
+//	policy := proxy.RateLimitPolicy{
+//		Store:  redisstore.New(client),
+//		Limit:  100,
+//		Window: time.Minute,
+//	}
+//
+// The connections, the reconnects and the protocol are handled by the go-redis client, so any of its clients
+// (single node, cluster, ring or failover) can back the store.
+package redisstore
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/noncepad/grpc-proxy/proxy"
+)
+
+// incrementScript increments the counter and starts its window on the first increment, atomically.
+var incrementScript = redis.NewScript(`local count = redis.call('INCR', KEYS[1])
+if count == 1 then redis.call('PEXPIRE', KEYS[1], ARGV[1]) end
+return count`)
+
+// DefaultKeyPrefix is the default prefix of the keys of the counters.
+const DefaultKeyPrefix = "grpc-proxy/"
+
+// Store is the proxy.RateLimitStore keeping the counters in Redis (or any server speaking the Redis protocol
+// with EVAL support).
+//
+// Each increment is a single round trip, so the latency of the server adds to each limited call.
+type Store struct {
+	client redis.Scripter
+
+	// KeyPrefix is prepended to the keys of the counters (default DefaultKeyPrefix).
+	KeyPrefix string
+	// Timeout of each increment, including waiting for the pooled connection (default 1s). The reads and writes
+	// honor it only if the client is created with ContextTimeoutEnabled, otherwise they use the timeouts of the client.
+	Timeout time.Duration
+}
+
+var _ proxy.RateLimitStore = (*Store)(nil)
+
+// New creates the Store on top of the client, the client stays owned by the caller.
+func New(client redis.Scripter) *Store {
+	return &Store{
+		client:    client,
+		KeyPrefix: DefaultKeyPrefix,
+	}
+}
+
+// Increment implements proxy.RateLimitStore.
+func (s *Store) Increment(ctx context.Context, key string, window time.Duration) (int64, error) {
+	timeout := s.Timeout
+	if timeout <= 0 {
+		timeout = time.Second
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	ms := window.Milliseconds()
+	if ms < 1 {
+		ms = 1
+	}
+
+	count, err := incrementScript.Run(ctx, s.client, []string{s.KeyPrefix + key}, ms).Int64()
+	if err != nil {
+		return 0, fmt.Errorf("error incrementing rate limit counter: %w", err)
+	}
+
+	return count, nil
+}
@@ -0,0 +1,170 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package redisstore_test
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/noncepad/grpc-proxy/proxy/redisstore"
+)
+
+func newClient(t *testing.T, addr string) *redis.Client {
+	t.Helper()
+
+	client := redis.NewClient(&redis.Options{
+		Addr:                  addr,
+		MaxRetries:            -1,
+		ContextTimeoutEnabled: true,
+	})
+
+	t.Cleanup(func() { client.Close() }) //nolint:errcheck
+
+	return client
+}
+
+func TestStore(t *testing.T) {
+	server := miniredis.RunT(t)
+
+	ctx := context.Background()
+
+	// two stores (as in two proxy instances) share the counters
+	store1 := redisstore.New(newClient(t, server.Addr()))
+	store2 := redisstore.New(newClient(t, server.Addr()))
+
+	for i, store := range []*redisstore.Store{store1, store2, store1} {
+		count, err := store.Increment(ctx, "client", time.Minute)
+		require.NoError(t, err)
+		assert.EqualValues(t, i+1, count)
+	}
+
+	assert.True(t, server.Exists(redisstore.DefaultKeyPrefix+"client"))
+	assert.Equal(t, time.Minute, server.TTL(redisstore.DefaultKeyPrefix+"client"))
+
+	// the window starts with the first increment
+	server.FastForward(30 * time.Second)
+
+	_, err := store2.Increment(ctx, "client", time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, 30*time.Second, server.TTL(redisstore.DefaultKeyPrefix+"client"))
+
+	server.FastForward(30 * time.Second)
+
+	count, err := store1.Increment(ctx, "client", time.Minute)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, count)
+
+	// the keys are prefixed
+	store1.KeyPrefix = "other/"
+
+	count, err = store1.Increment(ctx, "client", time.Minute)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, count)
+}
+
+func TestStoreErrorReply(t *testing.T) {
+	server := miniredis.RunT(t)
+
+	store := redisstore.New(newClient(t, server.Addr()))
+
+	ctx := context.Background()
+
+	server.SetError("LOADING Redis is loading the dataset in memory")
+
+	_, err := store.Increment(ctx, "client", time.Minute)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "LOADING")
+
+	server.SetError("")
+
+	count, err := store.Increment(ctx, "client", time.Minute)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, count)
+}
+
+func TestStoreTruncatedReply(t *testing.T) {
+	lis, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+
+	t.Cleanup(func() { lis.Close() }) //nolint:errcheck
+
+	// the server replies with the incomplete integer and drops the connection
+	go func() {
+		for {
+			conn, err := lis.Accept()
+			if err != nil {
+				return
+			}
+
+			buf := make([]byte, 1024)
+			conn.Read(buf)            //nolint:errcheck
+			conn.Write([]byte(":12")) //nolint:errcheck
+			conn.Close()              //nolint:errcheck
+		}
+	}()
+
+	store := redisstore.New(newClient(t, lis.Addr().String()))
+
+	_, err = store.Increment(context.Background(), "client", time.Minute)
+	require.Error(t, err)
+}
+
+func TestStoreReconnect(t *testing.T) {
+	server := miniredis.RunT(t)
+
+	store := redisstore.New(newClient(t, server.Addr()))
+
+	ctx := context.Background()
+
+	count, err := store.Increment(ctx, "client", time.Minute)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, count)
+
+	// the server drops the connections, the increments fail until it is back
+	server.Close()
+
+	_, err = store.Increment(ctx, "client", time.Minute)
+	require.Error(t, err)
+
+	require.NoError(t, server.Restart())
+
+	count, err = store.Increment(ctx, "client", time.Minute)
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, count)
+}
+
+func TestStoreTimeout(t *testing.T) {
+	lis, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+
+	t.Cleanup(func() { lis.Close() }) //nolint:errcheck
+
+	// the server accepts the connections but never replies
+	go func() {
+		for {
+			conn, err := lis.Accept()
+			if err != nil {
+				return
+			}
+
+			t.Cleanup(func() { conn.Close() }) //nolint:errcheck
+		}
+	}()
+
+	store := redisstore.New(newClient(t, lis.Addr().String()))
+	store.Timeout = 100 * time.Millisecond
+
+	start := time.Now()
+
+	_, err = store.Increment(context.Background(), "client", time.Minute)
+	require.Error(t, err)
+	assert.Less(t, time.Since(start), time.Second)
+}
@@ -0,0 +1,195 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"regexp"
+	"sync"
+
+	"google.golang.org/grpc"
+	rpb "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// WithReflectionRewriting rewrites the file descriptors of the server reflection streams passed through to the backends.
+//
+// Backends of different versions might serve different contents under the same file name, which breaks the clients
+// caching the descriptors by the file name. With the rewriting, each file is renamed to "@<hash>/<name>", where the hash
+// covers the contents of the file and the names of its dependencies, and the dependencies are renamed accordingly, so
+// that the files with the same name and different contents never collide. The client requests of the rewritten file
+// names are mapped back to the original names.
+//
+// Dependencies which are neither included in the same response nor sent earlier on the stream are not renamed
+// (gRPC servers send all the dependencies not sent yet along with the file).
+func WithReflectionRewriting() Option {
+	return func(o *handlerOptions) {
+		o.reflectionRewriting = true
+	}
+}
+
+// rewrittenFileName matches the rewritten file names.
+var rewrittenFileName = regexp.MustCompile(`^@[0-9a-f]{16}/`)
+
+// reflectionRewritingStream rewrites the file names of the server reflection stream.
+type reflectionRewritingStream struct {
+	grpc.ClientStream
+
+	// renamed maps the original file names sent on the stream to the rewritten names
+	renamed map[string]string
+	// requests keeps the client requests for the original_request of the responses
+	requests []*rpb.ServerReflectionRequest
+
+	mu sync.Mutex
+}
+
+func (s *reflectionRewritingStream) SendMsg(m interface{}) error {
+	f, ok := m.(*Frame)
+	if !ok {
+		return s.ClientStream.SendMsg(m)
+	}
+
+	var req rpb.ServerReflectionRequest
+
+	if err := proto.Unmarshal(f.payload, &req); err != nil {
+		// let the backend handle the malformed request
+		return s.ClientStream.SendMsg(m)
+	}
+
+	s.mu.Lock()
+	s.requests = append(s.requests, proto.Clone(&req).(*rpb.ServerReflectionRequest)) //nolint:forcetypeassert
+	s.mu.Unlock()
+
+	if name, ok := req.MessageRequest.(*rpb.ServerReflectionRequest_FileByFilename); ok {
+		name.FileByFilename = rewrittenFileName.ReplaceAllString(name.FileByFilename, "")
+	}
+
+	payload, err := proto.Marshal(&req)
+	if err != nil {
+		return err
+	}
+
+	return s.ClientStream.SendMsg(NewFrame(payload))
+}
+
+func (s *reflectionRewritingStream) RecvMsg(m interface{}) error {
+	if err := s.ClientStream.RecvMsg(m); err != nil {
+		return err
+	}
+
+	f, ok := m.(*Frame)
+	if !ok {
+		return nil
+	}
+
+	var resp rpb.ServerReflectionResponse
+
+	if err := proto.Unmarshal(f.payload, &resp); err != nil {
+		return nil //nolint:nilerr // pass the malformed response as is
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.requests) > 0 {
+		resp.OriginalRequest, s.requests = s.requests[0], s.requests[1:]
+	}
+
+	if files, ok := resp.MessageResponse.(*rpb.ServerReflectionResponse_FileDescriptorResponse); ok {
+		rewritten, err := s.rewriteLocked(files.FileDescriptorResponse.FileDescriptorProto)
+		if err != nil {
+			return err
+		}
+
+		files.FileDescriptorResponse.FileDescriptorProto = rewritten
+	}
+
+	payload, err := proto.Marshal(&resp)
+	if err != nil {
+		return err
+	}
+
+	f.payload = payload
+
+	return nil
+}
+
+// rewriteLocked renames the files of the response and their dependencies.
+func (s *reflectionRewritingStream) rewriteLocked(encoded [][]byte) ([][]byte, error) {
+	files := make(map[string]*descriptorpb.FileDescriptorProto, len(encoded))
+	order := make([]string, 0, len(encoded))
+
+	for _, data := range encoded {
+		fd := &descriptorpb.FileDescriptorProto{}
+
+		if err := proto.Unmarshal(data, fd); err != nil {
+			return nil, err
+		}
+
+		files[fd.GetName()] = fd
+		order = append(order, fd.GetName())
+	}
+
+	done := make(map[string]string, len(files))
+
+	var rename func(name string, visiting map[string]bool) string
+
+	rename = func(name string, visiting map[string]bool) string {
+		if renamed, ok := done[name]; ok {
+			return renamed
+		}
+
+		fd, ok := files[name]
+		if !ok {
+			if renamed, ok := s.renamed[name]; ok {
+				return renamed
+			}
+
+			// unknown dependency
+			return name
+		}
+
+		if visiting[name] {
+			// cyclic dependency
+			return name
+		}
+
+		visiting[name] = true
+
+		for i, dep := range fd.Dependency {
+			fd.Dependency[i] = rename(dep, visiting)
+		}
+
+		data, err := proto.MarshalOptions{Deterministic: true}.Marshal(fd)
+		if err != nil {
+			return name
+		}
+
+		sum := sha256.Sum256(data)
+		renamed := "@" + hex.EncodeToString(sum[:8]) + "/" + name
+
+		fd.Name = proto.String(renamed)
+		done[name] = renamed
+		s.renamed[name] = renamed
+
+		return renamed
+	}
+
+	result := make([][]byte, 0, len(order))
+
+	for _, name := range order {
+		rename(name, map[string]bool{})
+
+		data, err := proto.Marshal(files[name])
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, data)
+	}
+
+	return result, nil
+}
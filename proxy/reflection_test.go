@@ -0,0 +1,121 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy_test
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	rpb "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+
+	"github.com/noncepad/grpc-proxy/proxy"
+	pb "github.com/noncepad/grpc-proxy/testservice"
+)
+
+func reflectionFiles(t *testing.T, stream rpb.ServerReflection_ServerReflectionInfoClient, req *rpb.ServerReflectionRequest) []*descriptorpb.FileDescriptorProto {
+	t.Helper()
+
+	require.NoError(t, stream.Send(req))
+
+	resp, err := stream.Recv()
+	require.NoError(t, err)
+
+	assert.True(t, proto.Equal(req, resp.OriginalRequest), "original request: %v", resp.OriginalRequest)
+
+	files := resp.GetFileDescriptorResponse().GetFileDescriptorProto()
+	require.NotEmpty(t, files, "response: %v", resp)
+
+	result := make([]*descriptorpb.FileDescriptorProto, 0, len(files))
+
+	for _, data := range files {
+		fd := &descriptorpb.FileDescriptorProto{}
+		require.NoError(t, proto.Unmarshal(data, fd))
+
+		result = append(result, fd)
+	}
+
+	return result
+}
+
+func TestReflectionRewriting(t *testing.T) {
+	backends := []proxy.Backend{
+		newReflectionBackend(t, grpc.WithCodec(proxy.Codec())), //nolint: staticcheck
+		newReflectionBackend(t, grpc.WithCodec(proxy.Codec())), //nolint: staticcheck
+	}
+
+	var next int
+
+	h := newTestHarness(t, func(proxy.Backend) proxy.StreamDirector {
+		return func(ctx context.Context, fullMethodName string) (proxy.Mode, []proxy.Backend, error) {
+			backend := backends[next%len(backends)]
+			next++
+
+			return proxy.One2One, []proxy.Backend{backend}, nil
+		}
+	}, proxy.WithReflectionRewriting())
+	defer h.stop()
+
+	client := rpb.NewServerReflectionClient(h.clientConn)
+
+	var names []string
+
+	for range backends {
+		stream, err := client.ServerReflectionInfo(testContext(t))
+		require.NoError(t, err)
+
+		files := reflectionFiles(t, stream, &rpb.ServerReflectionRequest{
+			MessageRequest: &rpb.ServerReflectionRequest_FileContainingSymbol{FileContainingSymbol: "talos.testproto.TestService"},
+		})
+		require.Len(t, files, 1)
+
+		name := files[0].GetName()
+		assert.Regexp(t, regexp.MustCompile(`^@[0-9a-f]{16}/test\.proto$`), name)
+		assert.Equal(t, "talos.testproto", files[0].GetPackage())
+
+		names = append(names, name)
+
+		// the rewritten name is resolved by the backend
+		files = reflectionFiles(t, stream, &rpb.ServerReflectionRequest{
+			MessageRequest: &rpb.ServerReflectionRequest_FileByFilename{FileByFilename: name},
+		})
+		require.Len(t, files, 1)
+		assert.Equal(t, name, files[0].GetName())
+
+		// other requests are passed through
+		require.NoError(t, stream.Send(&rpb.ServerReflectionRequest{
+			MessageRequest: &rpb.ServerReflectionRequest_ListServices{},
+		}))
+
+		resp, err := stream.Recv()
+		require.NoError(t, err)
+		assert.NotEmpty(t, resp.GetListServicesResponse().GetService())
+
+		require.NoError(t, stream.CloseSend())
+	}
+
+	// same contents on both backends produce the same name
+	assert.Equal(t, names[0], names[1])
+}
+
+func TestReflectionRewritingDisabled(t *testing.T) {
+	backend := newReflectionBackend(t, grpc.WithCodec(proxy.Codec())) //nolint: staticcheck
+
+	h := newTestHarness(t, func(proxy.Backend) proxy.StreamDirector { return one2oneDirector(backend) })
+	defer h.stop()
+
+	stream, err := rpb.NewServerReflectionClient(h.clientConn).ServerReflectionInfo(testContext(t))
+	require.NoError(t, err)
+
+	files := reflectionFiles(t, stream, &rpb.ServerReflectionRequest{
+		MessageRequest: &rpb.ServerReflectionRequest_FileContainingSymbol{FileContainingSymbol: "talos.testproto.TestService"},
+	})
+	require.Len(t, files, 1)
+	assert.Equal(t, pb.File_test_proto.Path(), files[0].GetName())
+}
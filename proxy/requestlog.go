@@ -0,0 +1,216 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy
+
+import (
+	"context"
+	"math/rand"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// RedactedValue replaces the values of the redacted request fields in RequestLogEntry.
+const RedactedValue = "[REDACTED]"
+
+// RequestLogEntry is the logged subset of the request message fields.
+type RequestLogEntry struct {
+	// Fields are the decoded values of the logged fields (JSON-like, as encoded by protojson) by path,
+	// the fields not set in the request are omitted.
+	Fields map[string]interface{}
+	// Err is set if the request couldn't be decoded, e.g. the descriptor of the method is unknown.
+	Err error
+
+	Method string
+	// Message is the sequence number of the request message in the call (starting with 1).
+	Message uint64
+}
+
+// RequestLogger logs the request fields of the sampled call.
+//
+// The logger is called synchronously on the proxying path, so it should not block.
+type RequestLogger func(ctx context.Context, entry RequestLogEntry)
+
+// RequestLogPolicy configures the logging of the request fields.
+type RequestLogPolicy struct {
+	Logger RequestLogger
+	// Fields are the paths of the logged fields: protobuf field names separated by dots, e.g. "cluster_id"
+	// or "target.cluster_id". The message fields are logged with all their fields (except the redacted ones).
+	Fields []string
+	// Redact are the paths of the fields which values are replaced with RedactedValue, also inside the logged
+	// message fields, so that the secrets never reach the logs.
+	Redact []string
+	// SampleRate is the fraction of the calls which log the requests, zero means all calls.
+	SampleRate float64
+	// MaxMessages limits the number of the request messages logged per call, zero means no limit.
+	MaxMessages int
+}
+
+// WithRequestLogging decodes and logs the configured subset of the request fields of the sampled calls of
+// the listed methods, so that the operators get the content-level visibility without capturing the full payloads.
+// If fullMethodNames is empty, the policy is applied to all methods without a method-specific policy.
+//
+// The requests are decoded with the descriptors of the handler (see WithDescriptorFiles, WithDescriptorResolver).
+func WithRequestLogging(policy RequestLogPolicy, fullMethodNames ...string) Option {
+	return func(o *handlerOptions) {
+		if policy.Logger == nil {
+			o.invalid("request logger is required")
+		}
+
+		if policy.SampleRate < 0 || policy.SampleRate > 1 {
+			o.invalid("request log sample rate %v is out of range [0, 1]", policy.SampleRate)
+		}
+
+		if o.requestLogging == nil {
+			o.requestLogging = map[string]RequestLogPolicy{}
+		}
+
+		if len(fullMethodNames) == 0 {
+			o.requestLogging[""] = policy
+
+			return
+		}
+
+		for _, name := range fullMethodNames {
+			o.requestLogging[name] = policy
+		}
+	}
+}
+
+// wrapRequestLogging wraps the stream of the sampled call to log the request fields.
+func (o *handlerOptions) wrapRequestLogging(serverStream grpc.ServerStream, fullMethodName string) grpc.ServerStream {
+	policy, ok := o.requestLogging[fullMethodName]
+	if !ok {
+		policy, ok = o.requestLogging[""]
+	}
+
+	if !ok || policy.Logger == nil {
+		return serverStream
+	}
+
+	//nolint:gosec // sampling doesn't need a secure random source
+	if policy.SampleRate > 0 && policy.SampleRate < 1 && rand.Float64() >= policy.SampleRate {
+		return serverStream
+	}
+
+	s := &requestLoggingServerStream{
+		ServerStream: serverStream,
+		policy:       policy,
+		method:       fullMethodName,
+	}
+
+	if methodDesc, err := o.lookupMethod(fullMethodName); err == nil {
+		s.input = methodDesc.Input()
+	} else {
+		s.err = err
+	}
+
+	return s
+}
+
+// requestLoggingServerStream logs the request fields.
+type requestLoggingServerStream struct {
+	grpc.ServerStream
+
+	input  protoreflect.MessageDescriptor
+	err    error
+	policy RequestLogPolicy
+	method string
+	count  uint64
+}
+
+func (s *requestLoggingServerStream) RecvMsg(m interface{}) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+
+	f, ok := m.(*Frame)
+	if !ok {
+		return nil
+	}
+
+	s.count++
+
+	if s.policy.MaxMessages > 0 && s.count > uint64(s.policy.MaxMessages) {
+		return nil
+	}
+
+	entry := RequestLogEntry{
+		Method:  s.method,
+		Message: s.count,
+		Err:     s.err,
+	}
+
+	if s.input != nil {
+		entry.Fields, entry.Err = s.policy.fields(s.input, f.payload)
+	}
+
+	s.policy.Logger(s.Context(), entry)
+
+	return nil
+}
+
+// fields decodes the request and extracts the logged fields.
+func (policy *RequestLogPolicy) fields(desc protoreflect.MessageDescriptor, payload []byte) (map[string]interface{}, error) {
+	decoded, err := decodeMessage(desc, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, path := range policy.Redact {
+		redactPath(decoded, strings.Split(path, "."))
+	}
+
+	fields := make(map[string]interface{}, len(policy.Fields))
+
+	for _, path := range policy.Fields {
+		if value, ok := lookupPath(decoded, strings.Split(path, ".")); ok {
+			fields[path] = value
+		}
+	}
+
+	return fields, nil
+}
+
+// lookupPath returns the value of the decoded message by path.
+func lookupPath(decoded map[string]interface{}, path []string) (interface{}, bool) {
+	value, ok := decoded[path[0]]
+	if !ok || len(path) == 1 {
+		return value, ok
+	}
+
+	nested, isMessage := value.(map[string]interface{})
+	if !isMessage {
+		return nil, false
+	}
+
+	return lookupPath(nested, path[1:])
+}
+
+// redactPath replaces the value of the decoded message by path, the repeated message fields are redacted
+// in each of the messages.
+func redactPath(decoded map[string]interface{}, path []string) {
+	value, ok := decoded[path[0]]
+	if !ok {
+		return
+	}
+
+	if len(path) == 1 {
+		decoded[path[0]] = RedactedValue
+
+		return
+	}
+
+	switch nested := value.(type) {
+	case map[string]interface{}:
+		redactPath(nested, path[1:])
+	case []interface{}:
+		for _, item := range nested {
+			if msg, isMessage := item.(map[string]interface{}); isMessage {
+				redactPath(msg, path[1:])
+			}
+		}
+	}
+}
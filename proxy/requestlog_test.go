@@ -0,0 +1,115 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy_test
+
+import (
+	"context"
+	"io"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/noncepad/grpc-proxy/proxy"
+	pb "github.com/noncepad/grpc-proxy/testservice"
+)
+
+func TestRequestLogging(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		policy   proxy.RequestLogPolicy
+		expected []proxy.RequestLogEntry
+	}{
+		{
+			name:   "fields",
+			policy: proxy.RequestLogPolicy{Fields: []string{"value", "unknown", "value.nested"}},
+			expected: []proxy.RequestLogEntry{
+				{Method: "/talos.testproto.TestService/PingStream", Message: 1, Fields: map[string]interface{}{"value": "foo"}},
+				{Method: "/talos.testproto.TestService/PingStream", Message: 2, Fields: map[string]interface{}{"value": "bar"}},
+			},
+		},
+		{
+			name:   "redacted",
+			policy: proxy.RequestLogPolicy{Fields: []string{"value"}, Redact: []string{"value"}, MaxMessages: 1},
+			expected: []proxy.RequestLogEntry{
+				{Method: "/talos.testproto.TestService/PingStream", Message: 1, Fields: map[string]interface{}{"value": proxy.RedactedValue}},
+			},
+		},
+		{
+			name:     "not sampled",
+			policy:   proxy.RequestLogPolicy{Fields: []string{"value"}, SampleRate: 1e-12},
+			expected: nil,
+		},
+	} {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				mu      sync.Mutex
+				entries []proxy.RequestLogEntry
+			)
+
+			tt.policy.Logger = func(ctx context.Context, entry proxy.RequestLogEntry) {
+				mu.Lock()
+				defer mu.Unlock()
+
+				entries = append(entries, entry)
+			}
+
+			h := newTestHarness(t, one2oneDirector, proxy.WithRequestLogging(tt.policy, "/talos.testproto.TestService/PingStream"))
+
+			stream, err := h.client.PingStream(testContext(t))
+			require.NoError(t, err)
+
+			for _, value := range []string{"foo", "bar"} {
+				require.NoError(t, stream.Send(&pb.PingRequest{Value: value}))
+
+				_, err = stream.Recv()
+				require.NoError(t, err)
+			}
+
+			require.NoError(t, stream.CloseSend())
+
+			_, err = stream.Recv()
+			require.ErrorIs(t, err, io.EOF)
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			assert.Equal(t, tt.expected, entries)
+		})
+	}
+}
+
+func TestRequestLoggingUnary(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		entries []proxy.RequestLogEntry
+	)
+
+	h := newTestHarness(t, one2oneDirector, proxy.WithRequestLogging(proxy.RequestLogPolicy{
+		Fields: []string{"value"},
+		Logger: func(ctx context.Context, entry proxy.RequestLogEntry) {
+			mu.Lock()
+			defer mu.Unlock()
+
+			entries = append(entries, entry)
+		},
+	}))
+
+	_, err := h.client.Ping(testContext(t), &pb.PingRequest{Value: "foo"})
+	require.NoError(t, err)
+
+	_, err = h.client.PingEmpty(testContext(t), &pb.Empty{})
+	require.NoError(t, err)
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	assert.Equal(t, []proxy.RequestLogEntry{
+		{Method: "/talos.testproto.TestService/Ping", Message: 1, Fields: map[string]interface{}{"value": "foo"}},
+		{Method: "/talos.testproto.TestService/PingEmpty", Message: 1, Fields: map[string]interface{}{}},
+	}, entries)
+}
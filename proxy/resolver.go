@@ -0,0 +1,65 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// ResolverFunc resolves the backend target (e.g. "node://worker-3") to the address which can be dialed by gRPC.
+type ResolverFunc func(ctx context.Context, target string) (string, error)
+
+var resolvers = struct {
+	funcs map[string]ResolverFunc
+	mu    sync.RWMutex
+}{
+	funcs: map[string]ResolverFunc{},
+}
+
+// RegisterResolver registers the resolver for the target scheme, e.g. "node" for "node://worker-3".
+//
+// Registered resolvers are used by all built-in backend helpers (ConnPool, DialBackend), so that environments with
+// custom discovery can be integrated without custom Backend implementations. Registering the resolver for the scheme
+// again replaces the previous one, nil resolver removes it.
+func RegisterResolver(scheme string, resolver ResolverFunc) {
+	resolvers.mu.Lock()
+	defer resolvers.mu.Unlock()
+
+	if resolver == nil {
+		delete(resolvers.funcs, scheme)
+
+		return
+	}
+
+	resolvers.funcs[scheme] = resolver
+}
+
+// Resolve resolves the target with the resolver registered for its scheme.
+//
+// Targets without a scheme or with a scheme which has no registered resolver are returned as is, so that they can be
+// handled by gRPC name resolution.
+func Resolve(ctx context.Context, target string) (string, error) {
+	pos := strings.Index(target, "://")
+	if pos <= 0 {
+		return target, nil
+	}
+
+	resolvers.mu.RLock()
+	resolver, ok := resolvers.funcs[target[:pos]]
+	resolvers.mu.RUnlock()
+
+	if !ok {
+		return target, nil
+	}
+
+	addr, err := resolver(ctx, target)
+	if err != nil {
+		return "", fmt.Errorf("error resolving %q: %w", target, err)
+	}
+
+	return addr, nil
+}
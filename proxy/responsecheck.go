@@ -0,0 +1,118 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy
+
+import (
+	"fmt"
+
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// checkPayloads verifies the wire encoding of the responses merged in one:many unary call.
+//
+// The responses are concatenated, so a single response with the inconsistent length prefix (e.g. produced by
+// Backend.AppendInfo which grew the embedded message without updating its length) corrupts the whole
+// aggregated message, and the client either fails to decode it or silently decodes garbage. The call fails
+// with ErrMalformedResponse instead, which names the backend and the offending field.
+//
+// The embedded messages are verified as well if the descriptor of the output message is known (see
+// WithDescriptorResolver), otherwise (desc is nil) only the top-level fields are verified.
+func checkPayloads(fullMethodName string, desc protoreflect.MessageDescriptor, payloads []prioritizedPayload) error {
+	for _, p := range payloads {
+		err := checkWireMessage(p.payload, desc, 0)
+		if err == nil {
+			continue
+		}
+
+		source := "all-failed response"
+		backend := ""
+
+		if p.src != nil {
+			backend = p.src.backend.String()
+			source = "response of backend " + backend
+
+			if p.isError {
+				source = "formatted error of backend " + backend
+			}
+		}
+
+		proxyErr := newError(ErrMalformedResponse, "malformed %s for %s (%d bytes): %v", source, fullMethodName, len(p.payload), err)
+		proxyErr.Backend = backend
+
+		return proxyErr
+	}
+
+	return nil
+}
+
+// wireError describes the inconsistency of the wire encoding.
+type wireError struct {
+	// path is the path of the field, e.g. "messages.metadata.hostname" or "#3" for the unknown fields
+	path string
+	// offset is the offset of the field in the payload
+	offset int
+	// declared and available are set if the length prefix doesn't match the remaining size
+	declared, available int
+	err                 error
+}
+
+func (e *wireError) Error() string {
+	if e.declared > e.available {
+		return fmt.Sprintf("field %s at offset %d declares %d bytes, but only %d bytes remain", e.path, e.offset, e.declared, e.available)
+	}
+
+	return fmt.Sprintf("field %s at offset %d: %v", e.path, e.offset, e.err)
+}
+
+// checkWireMessage walks the fields of the message, the embedded messages are walked if the descriptor is known.
+//
+// base is the offset of the message in the payload.
+func checkWireMessage(b []byte, desc protoreflect.MessageDescriptor, base int) error {
+	for offset := 0; offset < len(b); {
+		num, typ, tagLen := protowire.ConsumeTag(b[offset:])
+		if tagLen < 0 {
+			return &wireError{path: "tag", offset: base + offset, err: protowire.ParseError(tagLen)}
+		}
+
+		var fd protoreflect.FieldDescriptor
+
+		if desc != nil {
+			fd = desc.Fields().ByNumber(num)
+		}
+
+		path := fmt.Sprintf("#%d", num)
+		if fd != nil {
+			path = string(fd.Name())
+		}
+
+		valueLen := protowire.ConsumeFieldValue(num, typ, b[offset+tagLen:])
+		if valueLen < 0 {
+			wireErr := &wireError{path: path, offset: base + offset, err: protowire.ParseError(valueLen)}
+
+			if typ == protowire.BytesType {
+				if declared, n := protowire.ConsumeVarint(b[offset+tagLen:]); n > 0 {
+					wireErr.declared, wireErr.available = int(declared), len(b)-offset-tagLen-n
+				}
+			}
+
+			return wireErr
+		}
+
+		if typ == protowire.BytesType && fd != nil && fd.Message() != nil && !fd.IsMap() {
+			value, n := protowire.ConsumeBytes(b[offset+tagLen:])
+
+			if err := checkWireMessage(value, fd.Message(), base+offset+tagLen+n); err != nil {
+				wireErr := err.(*wireError) //nolint:errorlint,forcetypeassert
+				wireErr.path = path + "." + wireErr.path
+
+				return wireErr
+			}
+		}
+
+		offset += tagLen + valueLen
+	}
+
+	return nil
+}
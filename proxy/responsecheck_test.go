@@ -0,0 +1,113 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy_test
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoregistry"
+
+	"github.com/noncepad/grpc-proxy/proxy"
+	pb "github.com/noncepad/grpc-proxy/testservice"
+)
+
+// corruptingBackend breaks the length prefix of the response after AppendInfo.
+type corruptingBackend struct {
+	*assertingBackend
+
+	// corrupt returns the position of the length prefix to break
+	corrupt func(payload []byte, metadataSize int) int
+}
+
+func (b *corruptingBackend) AppendInfo(streaming bool, resp []byte) ([]byte, error) {
+	payload, err := b.assertingBackend.AppendInfo(streaming, resp)
+	if err != nil {
+		return nil, err
+	}
+
+	payload[b.corrupt(payload, proto.Size(&pb.ResponseMetadata{Hostname: fmt.Sprintf("server%d", b.i)}))]++
+
+	return payload, nil
+}
+
+func TestMalformedAggregatedResponse(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	server := grpc.NewServer()
+	pb.RegisterMultiServiceServer(server, &assertingMultiService{t: t, server: "server"})
+
+	go server.Serve(listener) //nolint:errcheck
+
+	t.Cleanup(server.Stop)
+
+	for _, tt := range []struct {
+		name     string
+		options  []proxy.Option
+		corrupt  func(payload []byte, metadataSize int) int
+		expected string
+	}{
+		{
+			name: "envelope",
+			// the length of the top-level embedded message
+			corrupt:  func([]byte, int) int { return 1 },
+			expected: "field response at offset 0 declares 41 bytes, but only 40 bytes remain",
+		},
+		{
+			name: "metadata",
+			// the length of the metadata appended to the embedded message
+			corrupt:  func(payload []byte, metadataSize int) int { return len(payload) - metadataSize - 1 },
+			expected: "field response.metadata at offset",
+		},
+		{
+			name: "metadata without descriptors",
+			// the resolver doesn't know the method, so only the top-level fields are verified
+			options:  []proxy.Option{proxy.WithDescriptorResolver(proxy.NewDescriptorResolver(&protoregistry.Files{}, nil))},
+			corrupt:  func(payload []byte, metadataSize int) int { return len(payload) - metadataSize - 1 },
+			expected: "",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			healthy := &assertingBackend{i: 0, addr: listener.Addr().String()}
+			corrupted := &corruptingBackend{
+				assertingBackend: &assertingBackend{i: 1, addr: listener.Addr().String()},
+				corrupt:          tt.corrupt,
+			}
+
+			h := newTestHarness(t, func(proxy.Backend) proxy.StreamDirector {
+				return func(context.Context, string) (proxy.Mode, []proxy.Backend, error) {
+					return proxy.One2Many, []proxy.Backend{healthy, corrupted}, nil
+				}
+			}, tt.options...)
+
+			err := h.clientConn.Invoke(testContext(t), "/talos.testproto.MultiService/PingEmpty", &pb.Empty{}, &pb.MultiPingReply{})
+
+			if tt.expected == "" {
+				// the response is passed to the client, which fails to decode it
+				_, ok := proxy.FromError(err)
+				assert.False(t, ok, "%v", err)
+				assert.Error(t, err)
+
+				return
+			}
+
+			proxyErr, ok := proxy.FromError(err)
+			require.True(t, ok, "%v", err)
+
+			assert.Equal(t, codes.Internal, proxyErr.Code)
+			assert.Equal(t, proxy.ReasonMalformedResponse, proxyErr.Reason)
+			assert.Equal(t, "backend1", proxyErr.Backend)
+			assert.Contains(t, proxyErr.Message, "response of backend backend1")
+			assert.Contains(t, proxyErr.Message, tt.expected)
+		})
+	}
+}
@@ -0,0 +1,80 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy
+
+import "sort"
+
+// ResponseOrder is the order of the backend responses merged in the one2many unary calls.
+type ResponseOrder int
+
+// ResponseOrder constants.
+//
+// Within the responses and within the backend errors (see Backend.BuildError), the backends are ordered
+// by the priority (see BackendWithPriority).
+const (
+	// ResponsesByArrival keeps the arrival order of the responses and the backend errors of the same priority,
+	// it is the default.
+	ResponsesByArrival ResponseOrder = iota
+	// ResponsesByBackend orders the responses and the backend errors of the same priority as the backends
+	// returned by the director, so that the order doesn't depend on the scheduling.
+	ResponsesByBackend
+	// ErrorsFirst puts the backend errors before the successful responses, each in the order of the backends.
+	ErrorsFirst
+	// ErrorsLast puts the backend errors after the successful responses, each in the order of the backends.
+	ErrorsLast
+)
+
+// WithResponseOrder sets the order of the responses merged in the one2many unary calls of the listed methods
+// (all methods, if none are listed), e.g. for the clients which scan the errors first.
+func WithResponseOrder(order ResponseOrder, fullMethodNames ...string) Option {
+	return func(o *handlerOptions) {
+		if order < ResponsesByArrival || order > ErrorsLast {
+			o.invalid("unknown response order %d", order)
+		}
+
+		if o.responseOrders == nil {
+			o.responseOrders = map[string]ResponseOrder{}
+		}
+
+		if len(fullMethodNames) == 0 {
+			o.responseOrders[""] = order
+
+			return
+		}
+
+		for _, name := range fullMethodNames {
+			o.responseOrders[name] = order
+		}
+	}
+}
+
+// sortPayloads orders the responses of the backends to be merged.
+func (o *handlerOptions) sortPayloads(fullMethodName string, payloads []prioritizedPayload) {
+	order, ok := o.responseOrders[fullMethodName]
+	if !ok {
+		order = o.responseOrders[""]
+	}
+
+	// errorRank orders the errors relative to the responses
+	errorRank := func(p prioritizedPayload) int {
+		switch {
+		case order == ErrorsFirst && !p.isError, order == ErrorsLast && p.isError:
+			return 1
+		default:
+			return 0
+		}
+	}
+
+	sort.SliceStable(payloads, func(i, j int) bool {
+		if ri, rj := errorRank(payloads[i]), errorRank(payloads[j]); ri != rj {
+			return ri < rj
+		}
+
+		if payloads[i].priority != payloads[j].priority {
+			return payloads[i].priority < payloads[j].priority
+		}
+
+		return order != ResponsesByArrival && payloads[i].index < payloads[j].index
+	})
+}
@@ -0,0 +1,113 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/noncepad/grpc-proxy/proxy"
+	pb "github.com/noncepad/grpc-proxy/testservice"
+)
+
+// tagValueService responds with the backend tag, and fails the backends tagged "bad*".
+type tagValueService struct {
+	badTagService
+}
+
+func (s *tagValueService) Ping(ctx context.Context, ping *pb.PingRequest) (*pb.PingResponse, error) {
+	if err := badTag(ctx); err != nil {
+		return nil, err
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	return &pb.PingResponse{Value: md.Get(backendTagMdKey)[0]}, nil
+}
+
+// pingValues decodes all the values of the merged PingResponse messages.
+func pingValues(t *testing.T, payload []byte) []string {
+	t.Helper()
+
+	var values []string
+
+	for len(payload) > 0 {
+		num, typ, n := protowire.ConsumeTag(payload)
+		require.GreaterOrEqual(t, n, 0)
+
+		payload = payload[n:]
+
+		n = protowire.ConsumeFieldValue(num, typ, payload)
+		require.GreaterOrEqual(t, n, 0)
+
+		if num == 1 {
+			value, _ := protowire.ConsumeBytes(payload)
+			values = append(values, string(value))
+		}
+
+		payload = payload[n:]
+	}
+
+	return values
+}
+
+func TestResponseOrder(t *testing.T) {
+	const ping = "/talos.testproto.TestService/Ping"
+
+	tags := []string{"bad1", "good1", "bad2", "good2"}
+
+	for _, tt := range []struct {
+		name     string
+		order    proxy.ResponseOrder
+		expected []string
+	}{
+		{
+			name:     "by backend",
+			order:    proxy.ResponsesByBackend,
+			expected: []string{"bad1:Unavailable", "good1", "bad2:Unavailable", "good2"},
+		},
+		{
+			name:     "errors first",
+			order:    proxy.ErrorsFirst,
+			expected: []string{"bad1:Unavailable", "bad2:Unavailable", "good1", "good2"},
+		},
+		{
+			name:     "errors last",
+			order:    proxy.ErrorsLast,
+			expected: []string{"good1", "good2", "bad1:Unavailable", "bad2:Unavailable"},
+		},
+	} {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestHarnessWithService(t, &tagValueService{}, func(backend proxy.Backend) proxy.StreamDirector {
+				return func(ctx context.Context, fullMethodName string) (proxy.Mode, []proxy.Backend, error) {
+					backends := make([]proxy.Backend, 0, len(tags))
+
+					for _, tag := range tags {
+						backends = append(backends, &reportingBackend{taggedBackend{Backend: backend, tag: tag}})
+					}
+
+					return proxy.One2Many, backends, nil
+				}
+			}, proxy.WithResponseOrder(tt.order, ping))
+
+			in, err := proto.Marshal(&pb.PingRequest{Value: "ping"})
+			require.NoError(t, err)
+
+			var out []byte
+
+			require.NoError(t, h.clientConn.Invoke(testContext(t), ping, &in, &out, grpc.ForceCodec(bytesCodec{})))
+
+			assert.Equal(t, tt.expected, pingValues(t, out))
+		})
+	}
+}
@@ -0,0 +1,71 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy
+
+// ResumePolicy configures the failover of the resumable server streams in flight.
+//
+// Without the resume policy, the call is only failed over until the first response is relayed to the client.
+// With the policy, the stream failed once the responses were relayed is resumed by the next backend: the requests
+// are replayed (rewritten by Requests to continue after the last response relayed), and the client receives
+// the resume marker (if Marker is set) before the responses of the next backend, so that it can deduplicate
+// the responses across the seam while the stream is kept open.
+//
+// The stream is only resumed once the client has half-closed the stream, so that the replayed requests are complete.
+type ResumePolicy struct {
+	// Requests returns the serialized request messages sent to the next backend to resume the stream, given
+	// the requests of the call and the last response relayed to the client, if set.
+	//
+	// By default the requests are replayed as is. The error fails the call with the error of the failed attempt.
+	Requests func(fullMethodName string, requests [][]byte, lastResponse []byte) ([][]byte, error)
+
+	// Marker returns the serialized response message (the resume token) relayed to the client before the responses
+	// of the next backend, if set. The nil message is not relayed.
+	Marker func(fullMethodName string, lastResponse []byte) []byte
+}
+
+// resume returns the requests replayed to the next backend and the resume marker.
+func (policy *ResumePolicy) resume(fullMethodName string, requests [][]byte, lastResponse []byte) ([][]byte, []byte, error) {
+	if policy.Requests != nil {
+		var err error
+
+		if requests, err = policy.Requests(fullMethodName, requests, lastResponse); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	var marker []byte
+
+	if policy.Marker != nil {
+		marker = policy.Marker(fullMethodName, lastResponse)
+	}
+
+	return requests, marker, nil
+}
+
+// resumable returns true if the committed stream can be resumed by the next backend.
+func (s *failoverClientStream) resumable() bool {
+	return s.policy.Resume != nil && s.closed
+}
+
+// relayMarker relays the pending resume marker.
+func (s *failoverClientStream) relayMarker(m interface{}) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	marker := s.marker
+	if marker == nil {
+		return false
+	}
+
+	s.marker = nil
+
+	f, ok := m.(*Frame)
+	if !ok {
+		return false
+	}
+
+	f.payload = marker
+
+	return true
+}
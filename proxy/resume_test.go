@@ -0,0 +1,128 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy_test
+
+import (
+	"errors"
+	"io"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/noncepad/grpc-proxy/proxy"
+	pb "github.com/noncepad/grpc-proxy/testservice"
+)
+
+// resumableService streams the counters starting with the request value, the backend tagged "bad" fails
+// after two responses.
+type resumableService struct {
+	lenientService
+}
+
+func (s *resumableService) PingList(ping *pb.PingRequest, stream pb.TestService_PingListServer) error {
+	md, _ := metadata.FromIncomingContext(stream.Context())
+	tag := md.Get(backendTagMdKey)[0]
+
+	start := 0
+
+	if ping.Value != "" {
+		var err error
+
+		if start, err = strconv.Atoi(ping.Value); err != nil {
+			return err
+		}
+	}
+
+	for i := start; i < 5; i++ {
+		if tag == "bad" && i == start+2 {
+			return status.Error(codes.Unavailable, "attempt failed")
+		}
+
+		if err := stream.Send(&pb.PingResponse{Value: tag, Counter: int32(i)}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func TestFailoverResume(t *testing.T) {
+	lastCounter := func(t *testing.T, lastResponse []byte) int32 {
+		var resp pb.PingResponse
+
+		require.NoError(t, proto.Unmarshal(lastResponse, &resp))
+
+		return resp.Counter
+	}
+
+	for _, tt := range []struct {
+		name     string
+		resume   *proxy.ResumePolicy
+		expected []string
+		code     codes.Code
+	}{
+		{
+			name:     "no resume",
+			expected: []string{"bad 0", "bad 1"},
+			code:     codes.Unavailable,
+		},
+		{
+			name:     "replay",
+			resume:   &proxy.ResumePolicy{},
+			expected: []string{"bad 0", "bad 1", "good 0", "good 1", "good 2", "good 3", "good 4"},
+		},
+		{
+			name: "resume with marker",
+			resume: &proxy.ResumePolicy{
+				Requests: func(fullMethodName string, requests [][]byte, lastResponse []byte) ([][]byte, error) {
+					payload, err := proto.Marshal(&pb.PingRequest{Value: strconv.Itoa(int(lastCounter(t, lastResponse)) + 1)})
+
+					return [][]byte{payload}, err
+				},
+				Marker: func(fullMethodName string, lastResponse []byte) []byte {
+					payload, err := proto.Marshal(&pb.PingResponse{Value: "resume", Counter: lastCounter(t, lastResponse)})
+					require.NoError(t, err)
+
+					return payload
+				},
+			},
+			expected: []string{"bad 0", "bad 1", "resume 1", "good 2", "good 3", "good 4"},
+		},
+	} {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestHarnessWithService(t, &resumableService{}, failoverDirector,
+				proxy.WithFailover(proxy.FailoverPolicy{Resume: tt.resume}))
+
+			stream, err := h.client.PingList(testContext(t), &pb.PingRequest{})
+			require.NoError(t, err)
+
+			var received []string
+
+			for {
+				out, err := stream.Recv()
+				if errors.Is(err, io.EOF) {
+					break
+				}
+
+				if err != nil {
+					assert.Equal(t, tt.code, status.Code(err))
+
+					break
+				}
+
+				received = append(received, out.Value+" "+strconv.Itoa(int(out.Counter)))
+			}
+
+			assert.Equal(t, tt.expected, received)
+		})
+	}
+}
@@ -0,0 +1,118 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+)
+
+// RetryBudget limits the retries (the failover attempts) to a fraction of the traffic, so that the retries
+// can't amplify an upstream outage: each proxied call deposits ratio tokens to the bucket, each retry withdraws
+// a token, and the retries are not attempted once the bucket is empty.
+//
+// The budget is shared by all the methods and all the handlers configured with it, on top of the per-method failover
+// policies.
+//
+// RetryBudget implements http.Handler which serves the stats as JSON, so that it can be mounted on the admin HTTP server.
+type RetryBudget struct {
+	ratio     float64
+	maxTokens float64
+
+	mu    sync.Mutex
+	stats RetryBudgetStats
+}
+
+// RetryBudgetStats is the budget consumption.
+type RetryBudgetStats struct {
+	// Tokens is the number of the retries available.
+	Tokens float64 `json:"tokens"`
+	// Calls is the number of the calls which deposited to the budget.
+	Calls uint64 `json:"calls"`
+	// Retries is the number of the retries attempted.
+	Retries uint64 `json:"retries"`
+	// Rejected is the number of the retries rejected as the budget was exhausted.
+	Rejected uint64 `json:"rejected"`
+}
+
+// NewRetryBudget creates the budget which allows ratio retries per call (e.g. 0.1 for 10% of the calls),
+// the unused budget accumulates up to maxTokens retries (at least 1), the bucket is full initially.
+func NewRetryBudget(ratio, maxTokens float64) *RetryBudget {
+	if maxTokens < 1 {
+		maxTokens = 1
+	}
+
+	return &RetryBudget{
+		ratio:     ratio,
+		maxTokens: maxTokens,
+		stats:     RetryBudgetStats{Tokens: maxTokens},
+	}
+}
+
+// WithRetryBudget limits the retries of the calls with the budget.
+func WithRetryBudget(budget *RetryBudget) Option {
+	return func(o *handlerOptions) {
+		if budget != nil && budget.ratio < 0 {
+			o.invalid("retry budget ratio should not be negative")
+		}
+
+		o.retryBudget = budget
+	}
+}
+
+// Stats returns the budget consumption.
+func (b *RetryBudget) Stats() RetryBudgetStats {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.stats
+}
+
+// ServeHTTP implements http.Handler.
+func (b *RetryBudget) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	json.NewEncoder(w).Encode(b.Stats()) //nolint:errcheck
+}
+
+// deposit adds the share of the call to the budget, it is safe to be called on nil budget.
+func (b *RetryBudget) deposit() {
+	if b == nil {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.stats.Calls++
+
+	b.stats.Tokens += b.ratio
+	if b.stats.Tokens > b.maxTokens {
+		b.stats.Tokens = b.maxTokens
+	}
+}
+
+// withdraw takes the token for the retry, it returns false if the budget is exhausted.
+//
+// It is safe to be called on nil budget.
+func (b *RetryBudget) withdraw() bool {
+	if b == nil {
+		return true
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.stats.Tokens < 1 {
+		b.stats.Rejected++
+
+		return false
+	}
+
+	b.stats.Tokens--
+	b.stats.Retries++
+
+	return true
+}
@@ -0,0 +1,54 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy_test
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/noncepad/grpc-proxy/proxy"
+	pb "github.com/noncepad/grpc-proxy/testservice"
+)
+
+func TestRetryBudget(t *testing.T) {
+	budget := proxy.NewRetryBudget(0.5, 1)
+
+	h := newTestHarnessWithService(t, &failingService{code: codes.Unavailable}, failoverDirector,
+		proxy.WithFailover(proxy.FailoverPolicy{}),
+		proxy.WithRetryBudget(budget),
+	)
+
+	ping := func() codes.Code {
+		_, err := h.client.Ping(testContext(t), &pb.PingRequest{Value: "foo"})
+
+		return status.Code(err)
+	}
+
+	// the initial token and the deposits of the calls allow every other retry
+	assert.Equal(t, codes.OK, ping())
+	assert.Equal(t, codes.Unavailable, ping())
+	assert.Equal(t, codes.OK, ping())
+	assert.Equal(t, codes.Unavailable, ping())
+
+	assert.Equal(t, proxy.RetryBudgetStats{Tokens: 0.5, Calls: 4, Retries: 2, Rejected: 2}, budget.Stats())
+
+	w := httptest.NewRecorder()
+	budget.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+	var served proxy.RetryBudgetStats
+
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &served))
+	assert.Equal(t, budget.Stats(), served)
+}
+
+func TestRetryBudgetInvalid(t *testing.T) {
+	_, err := proxy.NewTransparentHandler(one2oneDirector(nil), proxy.WithRetryBudget(proxy.NewRetryBudget(-1, 1)))
+	require.ErrorIs(t, err, proxy.ErrInvalidOptions)
+}
@@ -0,0 +1,96 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// RouteHintTrailer is the trailer the backends return to instruct the proxy where the subsequent calls
+// of the client should be routed.
+//
+// The value is the name of the backend (as returned by Backend.String()). The trailer is consumed by the proxy,
+// it is not forwarded to the client.
+const RouteHintTrailer = "proxy-route-hint-bin"
+
+// RouteHintsPolicy configures the routing hints returned by the backends.
+type RouteHintsPolicy struct {
+	// State is the affinity state the hints are recorded to, see AffinityDirector.
+	State *ClusterState
+	// Key returns the affinity key of the call, the same as the one used by AffinityDirector.
+	Key AffinityKeyFunc
+	// TTL of the recorded hints, zero means forever.
+	TTL time.Duration
+}
+
+// WithRouteHints records the routing hints returned by the backends in the RouteHintTrailer to the affinity state,
+// so that AffinityDirector routes the subsequent calls of the client with the same affinity key to the hinted backend.
+//
+// This enables the backend-driven sharding through the proxy: the backend which doesn't own the client's shard
+// serves (or fails) the call and hints the owner, and the following calls go to the owner directly. The hints are
+// honored only if the hinted backend is among the backends returned by the director wrapped by AffinityDirector.
+func WithRouteHints(policy RouteHintsPolicy) Option {
+	return func(o *handlerOptions) {
+		if policy.State == nil || policy.Key == nil {
+			o.routeHints = nil
+
+			return
+		}
+
+		o.routeHints = &policy
+	}
+}
+
+// wrapRouteHints wraps the upstream stream to consume the routing hints.
+func (o *handlerOptions) wrapRouteHints(ctx context.Context, clientStream grpc.ClientStream, fullMethodName string) grpc.ClientStream {
+	if o.routeHints == nil {
+		return clientStream
+	}
+
+	key := o.routeHints.Key(ctx, fullMethodName)
+	if key == "" {
+		return clientStream
+	}
+
+	return &routeHintClientStream{
+		ClientStream: clientStream,
+		policy:       o.routeHints,
+		key:          AffinityStateNamespace + key,
+	}
+}
+
+// routeHintClientStream records the routing hint of the trailer, and strips it from the trailer.
+type routeHintClientStream struct {
+	grpc.ClientStream
+
+	policy *RouteHintsPolicy
+	key    string
+
+	once sync.Once
+}
+
+func (s *routeHintClientStream) Trailer() metadata.MD {
+	trailer := s.ClientStream.Trailer()
+
+	hints := trailer.Get(RouteHintTrailer)
+	if len(hints) == 0 {
+		return trailer
+	}
+
+	s.once.Do(func() {
+		if hint := hints[len(hints)-1]; hint != "" {
+			s.policy.State.Set(s.key, hint, s.policy.TTL)
+		}
+	})
+
+	trailer = trailer.Copy()
+	delete(trailer, RouteHintTrailer)
+
+	return trailer
+}
@@ -0,0 +1,69 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/noncepad/grpc-proxy/proxy"
+	pb "github.com/noncepad/grpc-proxy/testservice"
+)
+
+// shardingService responds with the tag of the backend, the backend "a" hints the owner "b".
+type shardingService struct {
+	assertingService
+}
+
+func (s *shardingService) Ping(ctx context.Context, ping *pb.PingRequest) (*pb.PingResponse, error) {
+	tag := metadata.ValueFromIncomingContext(ctx, backendTagMdKey)[0]
+
+	if tag == "a" {
+		grpc.SetTrailer(ctx, metadata.Pairs(proxy.RouteHintTrailer, "b")) //nolint: errcheck
+	}
+
+	return &pb.PingResponse{Value: tag}, nil
+}
+
+func TestRouteHints(t *testing.T) {
+	state := proxy.NewClusterState("proxy")
+	key := func(ctx context.Context, fullMethodName string) string {
+		return metadata.ValueFromIncomingContext(ctx, clientMdKey)[0]
+	}
+
+	h := newTestHarnessWithService(t, &shardingService{}, func(backend proxy.Backend) proxy.StreamDirector {
+		return proxy.AffinityDirector(func(ctx context.Context, fullMethodName string) (proxy.Mode, []proxy.Backend, error) {
+			return proxy.One2One, []proxy.Backend{
+				&taggedBackend{Backend: backend, tag: "a"},
+				&taggedBackend{Backend: backend, tag: "b"},
+			}, nil
+		}, state, key, time.Minute)
+	}, proxy.WithRouteHints(proxy.RouteHintsPolicy{State: state, Key: key, TTL: time.Minute}))
+
+	ctx := testContext(t)
+
+	var trailer metadata.MD
+
+	resp, err := h.client.Ping(ctx, &pb.PingRequest{Value: "foo"}, grpc.Trailer(&trailer))
+	require.NoError(t, err)
+	assert.Equal(t, "a", resp.Value)
+	assert.Empty(t, trailer.Get(proxy.RouteHintTrailer))
+
+	hint, ok := state.Get(proxy.AffinityStateNamespace + "true")
+	require.True(t, ok)
+	assert.Equal(t, "b", hint)
+
+	// the subsequent calls follow the hint
+	for i := 0; i < 2; i++ {
+		resp, err = h.client.Ping(ctx, &pb.PingRequest{Value: "foo"})
+		require.NoError(t, err)
+		assert.Equal(t, "b", resp.Value)
+	}
+}
@@ -0,0 +1,301 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+// ErrInvalidRoutingConfig is returned by Router.Apply for the configurations with error diagnostics.
+var ErrInvalidRoutingConfig = errors.New("invalid routing configuration")
+
+// Route routes the methods matching the pattern to the targets.
+type Route struct {
+	// Pattern is the full method name ("/package.Service/Method"), all methods of the service ("/package.Service/*"),
+	// or all methods ("*").
+	Pattern string `json:"pattern"`
+	// Mode of proxying the calls.
+	Mode Mode `json:"mode"`
+	// Targets are dialed via the ConnPool of the Router.
+	Targets []string `json:"targets"`
+}
+
+// RoutingConfig is the routing configuration of the Router.
+//
+// The most specific route matching the method is used: the full method name, then the service, then "*".
+type RoutingConfig struct {
+	Routes []Route `json:"routes"`
+}
+
+// DiagnosticSeverity is the severity of the Diagnostic.
+type DiagnosticSeverity string
+
+// Diagnostic severities.
+const (
+	// DiagnosticError prevents the configuration from being applied.
+	DiagnosticError DiagnosticSeverity = "error"
+	// DiagnosticWarning reports the likely mistake which doesn't prevent the configuration from being applied.
+	DiagnosticWarning DiagnosticSeverity = "warning"
+)
+
+// Diagnostic codes.
+const (
+	DiagnosticInvalidPattern    = "INVALID_PATTERN"
+	DiagnosticConflictingRoutes = "CONFLICTING_ROUTES"
+	DiagnosticNoTargets         = "NO_TARGETS"
+	DiagnosticMultipleTargets   = "MULTIPLE_TARGETS"
+	DiagnosticUnknownService    = "UNKNOWN_SERVICE"
+	DiagnosticUnknownMethod     = "UNKNOWN_METHOD"
+	DiagnosticUnreachableTarget = "UNREACHABLE_TARGET"
+)
+
+// Diagnostic describes the problem of the routing configuration.
+type Diagnostic struct {
+	Severity DiagnosticSeverity `json:"severity"`
+	Code     string             `json:"code"`
+	// Route is the index of the route in RoutingConfig.Routes.
+	Route   int    `json:"route"`
+	Pattern string `json:"pattern"`
+	// Target is set for the diagnostics of the targets.
+	Target  string `json:"target,omitempty"`
+	Message string `json:"message"`
+}
+
+func (d Diagnostic) String() string {
+	if d.Target != "" {
+		return fmt.Sprintf("%s: route %d (%s), target %s: %s", d.Severity, d.Route, d.Pattern, d.Target, d.Message)
+	}
+
+	return fmt.Sprintf("%s: route %d (%s): %s", d.Severity, d.Route, d.Pattern, d.Message)
+}
+
+// Router implements StreamDirector with the routing configuration, which can be validated and replaced at runtime.
+type Router struct {
+	pool  *ConnPool
+	files *protoregistry.Files
+
+	routes map[string]routerRoute
+
+	mu sync.RWMutex
+}
+
+type routerRoute struct {
+	mode     Mode
+	backends []Backend
+}
+
+// NewRouter creates a Router with the empty configuration.
+//
+// The targets are dialed via the pool. The patterns are checked against the descriptor registry, if set.
+func NewRouter(pool *ConnPool, files *protoregistry.Files) *Router {
+	return &Router{
+		pool:   pool,
+		files:  files,
+		routes: map[string]routerRoute{},
+	}
+}
+
+// Validate checks the configuration against the descriptor registry and the connection pool.
+//
+// The targets of the configuration are dialed, and the targets which don't become ready until the context is done are
+// reported as unreachable, so the context should have a deadline.
+func (r *Router) Validate(ctx context.Context, config RoutingConfig) []Diagnostic {
+	var diagnostics []Diagnostic
+
+	report := func(severity DiagnosticSeverity, code string, i int, target, format string, args ...interface{}) {
+		diagnostics = append(diagnostics, Diagnostic{
+			Severity: severity,
+			Code:     code,
+			Route:    i,
+			Pattern:  config.Routes[i].Pattern,
+			Target:   target,
+			Message:  fmt.Sprintf(format, args...),
+		})
+	}
+
+	seen := map[string]int{}
+	targets := map[string][]int{}
+
+	for i, route := range config.Routes {
+		if first, ok := seen[route.Pattern]; ok {
+			report(DiagnosticError, DiagnosticConflictingRoutes, i, "", "pattern is already routed by route %d", first)
+		} else {
+			seen[route.Pattern] = i
+		}
+
+		if code, message := r.checkPattern(route.Pattern); code != "" {
+			report(DiagnosticError, code, i, "", "%s", message)
+		}
+
+		switch {
+		case len(route.Targets) == 0:
+			report(DiagnosticError, DiagnosticNoTargets, i, "", "route has no targets")
+		case route.Mode == One2One && len(route.Targets) > 1:
+			report(DiagnosticWarning, DiagnosticMultipleTargets, i, "", "one2one route has %d targets, only the first one is used without failover", len(route.Targets))
+		}
+
+		for _, target := range route.Targets {
+			targets[target] = append(targets[target], i)
+		}
+	}
+
+	for target, err := range r.probeTargets(ctx, targets) {
+		for _, i := range targets[target] {
+			report(DiagnosticError, DiagnosticUnreachableTarget, i, target, "%s", err)
+		}
+	}
+
+	sort.SliceStable(diagnostics, func(i, j int) bool { return diagnostics[i].Route < diagnostics[j].Route })
+
+	return diagnostics
+}
+
+// Apply validates the configuration and activates it if there are no error diagnostics.
+//
+// With dryRun the configuration is only validated. If the configuration has error diagnostics,
+// ErrInvalidRoutingConfig is returned along with all the diagnostics.
+func (r *Router) Apply(ctx context.Context, config RoutingConfig, dryRun bool) ([]Diagnostic, error) {
+	diagnostics := r.Validate(ctx, config)
+
+	for _, diagnostic := range diagnostics {
+		if diagnostic.Severity == DiagnosticError {
+			return diagnostics, fmt.Errorf("%w: %s", ErrInvalidRoutingConfig, diagnostic)
+		}
+	}
+
+	if dryRun {
+		return diagnostics, nil
+	}
+
+	routes := make(map[string]routerRoute, len(config.Routes))
+
+	for _, route := range config.Routes {
+		backends := make([]Backend, 0, len(route.Targets))
+
+		for _, target := range route.Targets {
+			backends = append(backends, &DialBackend{Pool: r.pool, Target: target})
+		}
+
+		routes[route.Pattern] = routerRoute{mode: route.Mode, backends: backends}
+	}
+
+	r.mu.Lock()
+	r.routes = routes
+	r.mu.Unlock()
+
+	return diagnostics, nil
+}
+
+// Director is a StreamDirector.
+//
+// The methods which don't match any route are proxied to no backends.
+func (r *Router) Director(ctx context.Context, fullMethodName string) (Mode, []Backend, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	candidates := []string{fullMethodName}
+
+	if service, _, ok := splitMethodName(fullMethodName); ok {
+		candidates = append(candidates, "/"+service+"/*")
+	}
+
+	for _, pattern := range append(candidates, "*") {
+		if route, ok := r.routes[pattern]; ok {
+			return route.mode, route.backends, nil
+		}
+	}
+
+	return One2One, nil, nil
+}
+
+// checkPattern checks the syntax of the pattern, and whether the service and the method are in the registry.
+//
+// The code of the diagnostic is empty if the pattern is valid.
+func (r *Router) checkPattern(pattern string) (code, message string) {
+	if pattern == "*" {
+		return "", ""
+	}
+
+	service, method, ok := splitMethodName(pattern)
+	if !ok || !strings.HasPrefix(pattern, "/") || strings.Contains(service, "/") {
+		return DiagnosticInvalidPattern, `pattern should be "/package.Service/Method", "/package.Service/*" or "*"`
+	}
+
+	if r.files == nil {
+		return "", ""
+	}
+
+	desc, err := r.files.FindDescriptorByName(protoreflect.FullName(service))
+	if err != nil {
+		return DiagnosticUnknownService, fmt.Sprintf("service %s is not in the descriptor registry", service)
+	}
+
+	serviceDesc, ok := desc.(protoreflect.ServiceDescriptor)
+	if !ok {
+		return DiagnosticUnknownService, fmt.Sprintf("%s is not a service", service)
+	}
+
+	if method != "*" && serviceDesc.Methods().ByName(protoreflect.Name(method)) == nil {
+		return DiagnosticUnknownMethod, fmt.Sprintf("service %s has no method %s", service, method)
+	}
+
+	return "", ""
+}
+
+// probeTargets dials the targets and waits for the connections to become ready, it returns the errors
+// of the unreachable targets.
+func (r *Router) probeTargets(ctx context.Context, targets map[string][]int) map[string]error {
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs = map[string]error{}
+	)
+
+	for target := range targets {
+		wg.Add(1)
+
+		go func(target string) {
+			defer wg.Done()
+
+			if err := r.probeTarget(ctx, target); err != nil {
+				mu.Lock()
+				errs[target] = err
+				mu.Unlock()
+			}
+		}(target)
+	}
+
+	wg.Wait()
+
+	return errs
+}
+
+func (r *Router) probeTarget(ctx context.Context, target string) error {
+	conn, err := r.pool.Get(ctx, target)
+	if err != nil {
+		return err
+	}
+
+	conn.Connect()
+
+	for {
+		state := conn.GetState()
+		if state == connectivity.Ready {
+			return nil
+		}
+
+		if !conn.WaitForStateChange(ctx, state) {
+			return fmt.Errorf("connection is %s: %w", state, ctx.Err())
+		}
+	}
+}
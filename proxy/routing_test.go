@@ -0,0 +1,118 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/reflect/protoregistry"
+
+	"github.com/noncepad/grpc-proxy/proxy"
+	pb "github.com/noncepad/grpc-proxy/testservice"
+)
+
+func TestRouterValidate(t *testing.T) {
+	pool := proxy.NewConnPool(grpc.WithTransportCredentials(insecure.NewCredentials()))
+	defer pool.Close() //nolint:errcheck
+
+	router := proxy.NewRouter(pool, protoregistry.GlobalFiles)
+
+	h := newTestHarness(t, func(proxy.Backend) proxy.StreamDirector { return router.Director })
+	defer h.stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	diagnostics := router.Validate(ctx, proxy.RoutingConfig{
+		Routes: []proxy.Route{
+			{Pattern: "/talos.testproto.TestService/*", Mode: proxy.One2One, Targets: []string{h.backendAddr}},
+			{Pattern: "/talos.testproto.TestService/Ping", Mode: proxy.One2One, Targets: []string{h.backendAddr, "127.0.0.1:1"}},
+			{Pattern: "/talos.testproto.TestService/Pong", Mode: proxy.One2Many, Targets: []string{h.backendAddr}},
+			{Pattern: "/talos.testproto.NoService/*", Mode: proxy.One2Many, Targets: []string{h.backendAddr}},
+			{Pattern: "talos.testproto.TestService", Mode: proxy.One2One},
+			{Pattern: "/talos.testproto.TestService/*", Mode: proxy.One2One, Targets: []string{h.backendAddr}},
+		},
+	})
+
+	type diagnostic struct {
+		severity proxy.DiagnosticSeverity
+		code     string
+		route    int
+		target   string
+	}
+
+	actual := make([]diagnostic, 0, len(diagnostics))
+
+	for _, d := range diagnostics {
+		actual = append(actual, diagnostic{severity: d.Severity, code: d.Code, route: d.Route, target: d.Target})
+	}
+
+	assert.ElementsMatch(t, []diagnostic{
+		{proxy.DiagnosticWarning, proxy.DiagnosticMultipleTargets, 1, ""},
+		{proxy.DiagnosticError, proxy.DiagnosticUnreachableTarget, 1, "127.0.0.1:1"},
+		{proxy.DiagnosticError, proxy.DiagnosticUnknownMethod, 2, ""},
+		{proxy.DiagnosticError, proxy.DiagnosticUnknownService, 3, ""},
+		{proxy.DiagnosticError, proxy.DiagnosticInvalidPattern, 4, ""},
+		{proxy.DiagnosticError, proxy.DiagnosticNoTargets, 4, ""},
+		{proxy.DiagnosticError, proxy.DiagnosticConflictingRoutes, 5, ""},
+	}, actual)
+}
+
+func TestRouterApply(t *testing.T) {
+	pool := proxy.NewConnPool(
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithCodec(proxy.Codec()), //nolint: staticcheck
+	)
+	defer pool.Close() //nolint:errcheck
+
+	router := proxy.NewRouter(pool, protoregistry.GlobalFiles)
+
+	h := newTestHarness(t, func(proxy.Backend) proxy.StreamDirector { return router.Director })
+	defer h.stop()
+
+	_, err := h.client.Ping(testContext(t), &pb.PingRequest{Value: "foo"})
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// the invalid configuration is rejected
+	diagnostics, err := router.Apply(ctx, proxy.RoutingConfig{
+		Routes: []proxy.Route{
+			{Pattern: "/talos.testproto.TestService/Pong", Mode: proxy.One2One, Targets: []string{h.backendAddr}},
+		},
+	}, false)
+	require.ErrorIs(t, err, proxy.ErrInvalidRoutingConfig)
+	require.Len(t, diagnostics, 1)
+	assert.Equal(t, proxy.DiagnosticUnknownMethod, diagnostics[0].Code)
+
+	config := proxy.RoutingConfig{
+		Routes: []proxy.Route{
+			{Pattern: "/talos.testproto.TestService/*", Mode: proxy.One2One, Targets: []string{h.backendAddr}},
+		},
+	}
+
+	// dry run doesn't activate the configuration
+	diagnostics, err = router.Apply(ctx, config, true)
+	require.NoError(t, err)
+	assert.Empty(t, diagnostics)
+
+	_, err = h.client.Ping(testContext(t), &pb.PingRequest{Value: "foo"})
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+
+	_, err = router.Apply(ctx, config, false)
+	require.NoError(t, err)
+
+	resp, err := h.client.Ping(testContext(t), &pb.PingRequest{Value: "foo"})
+	require.NoError(t, err)
+	assert.EqualValues(t, 42, resp.Counter)
+}
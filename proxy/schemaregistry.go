@@ -0,0 +1,171 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// SchemaRegistry serves the versioned schemas of the services, so that the descriptor-aware features use
+// the message schema matching the version deployed on the backend.
+type SchemaRegistry interface {
+	// Schema returns the file declaring the service and its dependencies at the schema version, empty version
+	// means the latest one. The dependencies registered in the local registry of the resolver may be omitted.
+	Schema(ctx context.Context, serviceName, version string) (*descriptorpb.FileDescriptorSet, error)
+}
+
+// SchemaRegistryFunc is a function adapter for SchemaRegistry.
+type SchemaRegistryFunc func(ctx context.Context, serviceName, version string) (*descriptorpb.FileDescriptorSet, error)
+
+// Schema implements SchemaRegistry.
+func (f SchemaRegistryFunc) Schema(ctx context.Context, serviceName, version string) (*descriptorpb.FileDescriptorSet, error) {
+	return f(ctx, serviceName, version)
+}
+
+// HTTPSchemaRegistry is the SchemaRegistry fetching the schemas from the HTTP server.
+//
+// The schema is fetched with GET {URL}/services/{serviceName}/versions/{version} (version "latest" if empty),
+// the response is the FileDescriptorSet encoded as JSON (Content-Type application/json) or binary protobuf
+// (any other content type).
+type HTTPSchemaRegistry struct {
+	// URL is the base URL of the registry.
+	URL string
+	// Client is the HTTP client (default http.DefaultClient).
+	Client *http.Client
+	// Header is added to the requests, e.g. for the authorization.
+	Header http.Header
+}
+
+// Schema implements SchemaRegistry.
+func (r *HTTPSchemaRegistry) Schema(ctx context.Context, serviceName, version string) (*descriptorpb.FileDescriptorSet, error) {
+	if version == "" {
+		version = "latest"
+	}
+
+	u := strings.TrimSuffix(r.URL, "/") + "/services/" + url.PathEscape(serviceName) + "/versions/" + url.PathEscape(version)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	for key, values := range r.Header {
+		req.Header[key] = values
+	}
+
+	req.Header.Set("Accept", "application/x-protobuf, application/json")
+
+	client := r.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close() //nolint:errcheck
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("error fetching schema %s@%s: %s", serviceName, version, resp.Status)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	set := &descriptorpb.FileDescriptorSet{}
+
+	if mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type")); mediaType == "application/json" { //nolint:errcheck
+		err = protojson.Unmarshal(body, set)
+	} else {
+		err = proto.Unmarshal(body, set)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("error decoding schema %s@%s: %w", serviceName, version, err)
+	}
+
+	return set, nil
+}
+
+// SchemaVersionFunc returns the schema version deployed on the backend, empty version means the latest one.
+type SchemaVersionFunc func(backend Backend) string
+
+// SchemaVersions keeps the descriptor resolvers of the schema versions, so that the fleet of the backends running
+// mixed versions of the services is handled with the schema matching each backend.
+//
+// The resolvers of the versions are created on demand and shared by all the backends running the version.
+type SchemaVersions struct {
+	files    *protoregistry.Files
+	registry SchemaRegistry
+	version  SchemaVersionFunc
+
+	mu        sync.Mutex
+	resolvers map[string]*DescriptorResolver
+}
+
+// NewSchemaVersions creates the resolvers of the schema versions, fetched from the registry for the services
+// not registered in the local registry (protoregistry.GlobalFiles if nil). version returns the version of the backend.
+func NewSchemaVersions(files *protoregistry.Files, registry SchemaRegistry, version SchemaVersionFunc) *SchemaVersions {
+	return &SchemaVersions{
+		files:     files,
+		registry:  registry,
+		version:   version,
+		resolvers: map[string]*DescriptorResolver{},
+	}
+}
+
+// Resolver returns the resolver of the schema version.
+func (v *SchemaVersions) Resolver(version string) *DescriptorResolver {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	r, ok := v.resolvers[version]
+	if !ok {
+		r = NewSchemaRegistryResolver(v.files, v.registry, version)
+		v.resolvers[version] = r
+	}
+
+	return r
+}
+
+// ForBackend returns the resolver of the schema version deployed on the backend.
+func (v *SchemaVersions) ForBackend(backend Backend) *DescriptorResolver {
+	return v.Resolver(v.version(backend))
+}
+
+// WithSchemaVersions configures the descriptor-aware features working with the messages of the selected backend
+// (e.g. the response comparison of the shadow traffic) to use the schema version deployed on the backend.
+//
+// The features working with the call before the backend is selected use the resolver configured with
+// WithDescriptorResolver.
+func WithSchemaVersions(versions *SchemaVersions) Option {
+	return func(o *handlerOptions) {
+		o.schemaVersions = versions
+	}
+}
+
+// backendResolver returns the descriptor resolver of the backend.
+func (o *handlerOptions) backendResolver(backend Backend) *DescriptorResolver {
+	if o.schemaVersions != nil {
+		return o.schemaVersions.ForBackend(backend)
+	}
+
+	return o.resolver()
+}
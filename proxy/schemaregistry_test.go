@@ -0,0 +1,92 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+
+	"github.com/noncepad/grpc-proxy/proxy"
+	pb "github.com/noncepad/grpc-proxy/testservice"
+)
+
+func TestSchemaRegistry(t *testing.T) {
+	v1 := &descriptorpb.FileDescriptorSet{File: []*descriptorpb.FileDescriptorProto{protodesc.ToFileDescriptorProto(pb.File_test_proto)}}
+
+	// v2 adds the method Echo to the service
+	v2 := proto.Clone(v1).(*descriptorpb.FileDescriptorSet) //nolint:forcetypeassert
+	v2.File[0].Service[0].Method = append(v2.File[0].Service[0].Method, &descriptorpb.MethodDescriptorProto{
+		Name:       proto.String("Echo"),
+		InputType:  proto.String(".talos.testproto.PingRequest"),
+		OutputType: proto.String(".talos.testproto.PingResponse"),
+	})
+
+	var requests int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+
+		switch r.URL.Path {
+		case "/services/talos.testproto.TestService/versions/v1":
+			data, err := proto.Marshal(v1)
+			require.NoError(t, err)
+
+			w.Header().Set("Content-Type", "application/x-protobuf")
+			w.Write(data) //nolint:errcheck
+		case "/services/talos.testproto.TestService/versions/latest":
+			data, err := protojson.Marshal(v2)
+			require.NoError(t, err)
+
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+			w.Write(data) //nolint:errcheck
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	versions := proxy.NewSchemaVersions(&protoregistry.Files{}, &proxy.HTTPSchemaRegistry{URL: server.URL}, func(backend proxy.Backend) string {
+		return backend.String()
+	})
+
+	ctx := testContext(t)
+
+	methodDesc, err := versions.ForBackend(&taggedBackend{tag: "v1"}).FindMethod(ctx, "/talos.testproto.TestService/Ping")
+	require.NoError(t, err)
+	assert.EqualValues(t, "talos.testproto.PingResponse", methodDesc.Output().FullName())
+
+	_, err = versions.Resolver("v1").FindMethod(ctx, "/talos.testproto.TestService/Echo")
+	require.Error(t, err)
+
+	methodDesc, err = versions.ForBackend(&taggedBackend{tag: ""}).FindMethod(ctx, "/talos.testproto.TestService/Echo")
+	require.NoError(t, err)
+	assert.EqualValues(t, "talos.testproto.PingRequest", methodDesc.Input().FullName())
+
+	_, err = versions.Resolver("v3").FindMethod(ctx, "/talos.testproto.TestService/Ping")
+	require.ErrorContains(t, err, "404")
+
+	assert.EqualValues(t, 4, atomic.LoadInt32(&requests))
+
+	// the version change drops the fetched schema
+	resolver := proxy.NewSchemaRegistryResolver(&protoregistry.Files{}, &proxy.HTTPSchemaRegistry{URL: server.URL}, "v1")
+
+	_, err = resolver.FindMethod(ctx, "/talos.testproto.TestService/Echo")
+	require.Error(t, err)
+
+	resolver.SetVersion("")
+
+	_, err = resolver.FindMethod(ctx, "/talos.testproto.TestService/Echo")
+	require.NoError(t, err)
+	assert.EqualValues(t, 6, atomic.LoadInt32(&requests))
+}
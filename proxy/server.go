@@ -0,0 +1,218 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+
+	"github.com/hashicorp/go-multierror"
+	"google.golang.org/grpc"
+)
+
+// ControlService is the gRPC service served on the control plane listener, e.g. the health, the reflection,
+// the channelz or the admin service.
+type ControlService struct {
+	Desc *grpc.ServiceDesc
+	Impl interface{}
+}
+
+// ServerConfig configures the Server.
+type ServerConfig struct {
+	// Director routes the calls of the data plane, Options configure the data plane handler (see NewTransparentHandler).
+	Director StreamDirector
+	Options  []Option
+
+	// DataPlaneOptions are the options of the data plane grpc.Server, e.g. the credentials and the interceptors
+	// of the proxied calls. The proxy codec and the unknown service handler are set by the Server.
+	DataPlaneOptions []grpc.ServerOption
+
+	// ControlPlaneOptions are the options of the control plane grpc.Server, so that the control services
+	// are protected by the credentials and the interceptors of their own.
+	ControlPlaneOptions []grpc.ServerOption
+
+	// ControlServices are served on the control plane listener.
+	ControlServices []ControlService
+
+	// Pools are closed on Shutdown once the calls are drained.
+	Pools []*ConnPool
+	// Flushers are flushed last on Shutdown, so that the observations of the drained calls are not lost,
+	// e.g. BatchingEventWriter.
+	Flushers []Flusher
+}
+
+// Flusher is the observer with the buffered data, e.g. BatchingEventWriter.
+type Flusher interface {
+	Flush() error
+}
+
+// Server is the proxy with the data plane and the control plane bound to the separate listeners.
+//
+// The data plane proxies all the calls with the director, the control plane serves the control services
+// (the health checks, the metrics, the admin services). The services registered on the control plane are never
+// proxied: their calls to the data plane listener are rejected with ErrMethodNotAllowed, so the control endpoints
+// are only reachable through the control plane listener with its credentials.
+type Server struct {
+	data    *grpc.Server
+	control *grpc.Server
+
+	pools    []*ConnPool
+	flushers []Flusher
+}
+
+// NewServer creates the Server, the handler options are validated (see NewTransparentHandler).
+func NewServer(config ServerConfig) (*Server, error) {
+	handler, err := NewTransparentHandler(config.Director, config.Options...)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, pool := range config.Pools {
+		if err = pool.Validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	s := &Server{
+		control:  grpc.NewServer(config.ControlPlaneOptions...),
+		pools:    config.Pools,
+		flushers: config.Flushers,
+	}
+
+	for _, service := range config.ControlServices {
+		s.control.RegisterService(service.Desc, service.Impl)
+	}
+
+	dataOptions := append(append([]grpc.ServerOption(nil), config.DataPlaneOptions...),
+		grpc.CustomCodec(Codec()), //nolint: staticcheck
+		grpc.UnknownServiceHandler(s.dataPlaneHandler(handler)))
+
+	s.data = grpc.NewServer(dataOptions...)
+
+	return s, nil
+}
+
+// DataPlane returns the data plane grpc.Server.
+func (s *Server) DataPlane() *grpc.Server {
+	return s.data
+}
+
+// ControlPlane returns the control plane grpc.Server, so that the control services can be registered
+// with the generated code, e.g. grpc_health_v1.RegisterHealthServer(s.ControlPlane(), health.NewServer()).
+func (s *Server) ControlPlane() *grpc.Server {
+	return s.control
+}
+
+// dataPlaneHandler rejects the calls of the control services.
+func (s *Server) dataPlaneHandler(handler grpc.StreamHandler) grpc.StreamHandler {
+	return func(srv interface{}, serverStream grpc.ServerStream) error {
+		fullMethodName, ok := grpc.MethodFromServerStream(serverStream)
+		if ok {
+			if service, _, ok := splitMethodName(fullMethodName); ok {
+				if _, control := s.control.GetServiceInfo()[service]; control {
+					return newError(ErrMethodNotAllowed, "service %s is only served on the control plane", service)
+				}
+			}
+		}
+
+		return handler(srv, serverStream)
+	}
+}
+
+// Serve serves the data plane and the control plane on the listeners until the server is stopped.
+//
+// If one of the planes fails, the other one is stopped and the error is returned.
+func (s *Server) Serve(dataListener, controlListener net.Listener) error {
+	if dataListener == nil || controlListener == nil {
+		return errors.New("both data plane and control plane listeners are required")
+	}
+
+	errCh := make(chan error, 2)
+
+	go func() { errCh <- s.data.Serve(dataListener) }()
+	go func() { errCh <- s.control.Serve(controlListener) }()
+
+	err := <-errCh
+	if err != nil {
+		s.data.Stop()
+		s.control.Stop()
+	}
+
+	if otherErr := <-errCh; err == nil {
+		err = otherErr
+	}
+
+	return err
+}
+
+// Shutdown stops the server in the defined order:
+//
+//  1. the data plane stops accepting the connections and drains the calls in progress,
+//  2. the control plane is stopped the same way, so that the health checks and the admin services are available
+//     while the data plane drains,
+//  3. the pools are closed,
+//  4. the flushers are flushed.
+//
+// If the context is done before the calls are drained, the remaining calls are canceled, and the shutdown proceeds
+// with the next steps. All the steps are always performed, the errors of the steps (including the context error
+// if the drain was cut short) are returned together.
+func (s *Server) Shutdown(ctx context.Context) error {
+	var result *multierror.Error
+
+	for _, plane := range []*grpc.Server{s.data, s.control} {
+		if err := drain(ctx, plane); err != nil {
+			result = multierror.Append(result, err)
+		}
+	}
+
+	for _, pool := range s.pools {
+		if err := pool.Close(); err != nil {
+			result = multierror.Append(result, err)
+		}
+	}
+
+	for _, flusher := range s.flushers {
+		if err := flusher.Flush(); err != nil {
+			result = multierror.Append(result, err)
+		}
+	}
+
+	return result.ErrorOrNil()
+}
+
+// drain stops the server gracefully, it is stopped immediately once the context is done.
+func drain(ctx context.Context, server *grpc.Server) error {
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		server.GracefulStop()
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		server.Stop()
+		<-done
+
+		return fmt.Errorf("calls were not drained: %w", ctx.Err())
+	}
+}
+
+// GracefulStop is Shutdown without the deadline.
+func (s *Server) GracefulStop() {
+	s.Shutdown(context.Background()) //nolint:errcheck
+}
+
+// Stop is Shutdown with the calls in progress canceled immediately.
+func (s *Server) Stop() {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	s.Shutdown(ctx) //nolint:errcheck
+}
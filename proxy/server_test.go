@@ -0,0 +1,186 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy_test
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/noncepad/grpc-proxy/proxy"
+	pb "github.com/noncepad/grpc-proxy/testservice"
+)
+
+func TestServerPlanes(t *testing.T) {
+	var upstream proxy.Backend
+
+	newTestHarness(t, func(backend proxy.Backend) proxy.StreamDirector {
+		upstream = backend
+
+		return one2oneDirector(backend)
+	})
+
+	adminOnly := func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if token := metadata.ValueFromIncomingContext(ctx, "admin-token"); len(token) == 0 || token[0] != "secret" {
+			return nil, status.Error(codes.Unauthenticated, "admin token required")
+		}
+
+		return handler(ctx, req)
+	}
+
+	server, err := proxy.NewServer(proxy.ServerConfig{
+		Director: func(ctx context.Context, fullMethodName string) (proxy.Mode, []proxy.Backend, error) {
+			return proxy.One2One, []proxy.Backend{upstream}, nil
+		},
+		ControlPlaneOptions: []grpc.ServerOption{grpc.UnaryInterceptor(adminOnly)},
+		ControlServices: []proxy.ControlService{
+			{Desc: &healthpb.Health_ServiceDesc, Impl: health.NewServer()},
+		},
+	})
+	require.NoError(t, err)
+
+	dataListener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	controlListener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	serveErr := make(chan error, 1)
+
+	go func() { serveErr <- server.Serve(dataListener, controlListener) }()
+
+	dial := func(listener net.Listener) *grpc.ClientConn {
+		conn, dialErr := grpc.Dial(listener.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+		require.NoError(t, dialErr)
+
+		t.Cleanup(func() { conn.Close() }) //nolint: errcheck
+
+		return conn
+	}
+
+	dataConn, controlConn := dial(dataListener), dial(controlListener)
+
+	ctx := testContext(t)
+
+	// data plane proxies the calls
+	out, err := pb.NewTestServiceClient(dataConn).Ping(ctx, &pb.PingRequest{Value: "foo"})
+	require.NoError(t, err)
+	assert.Equal(t, "foo", out.Value)
+
+	// control services are not reachable via the data plane
+	_, err = healthpb.NewHealthClient(dataConn).Check(ctx, &healthpb.HealthCheckRequest{})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	// control plane has its own interceptors
+	_, err = healthpb.NewHealthClient(controlConn).Check(ctx, &healthpb.HealthCheckRequest{})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	resp, err := healthpb.NewHealthClient(controlConn).Check(metadata.AppendToOutgoingContext(ctx, "admin-token", "secret"), &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, resp.Status)
+
+	// data plane services are not served on the control plane
+	_, err = pb.NewTestServiceClient(controlConn).Ping(ctx, &pb.PingRequest{Value: "foo"})
+	assert.Equal(t, codes.Unimplemented, status.Code(err))
+
+	server.GracefulStop()
+	require.NoError(t, <-serveErr)
+}
+
+func TestServerInvalidOptions(t *testing.T) {
+	_, err := proxy.NewServer(proxy.ServerConfig{})
+	require.ErrorIs(t, err, proxy.ErrInvalidOptions)
+}
+
+func TestServerShutdown(t *testing.T) {
+	var upstream proxy.Backend
+
+	h := newTestHarnessWithService(t, &lenientService{}, func(backend proxy.Backend) proxy.StreamDirector {
+		upstream = backend
+
+		return one2oneDirector(backend)
+	})
+
+	pool := proxy.NewConnPool(grpc.WithTransportCredentials(insecure.NewCredentials()))
+
+	var events bytes.Buffer
+
+	writer := proxy.NewBatchingEventWriter(&events, proxy.JSONLinesEventEncoder{}, 100)
+
+	server, err := proxy.NewServer(proxy.ServerConfig{
+		Director: func(ctx context.Context, fullMethodName string) (proxy.Mode, []proxy.Backend, error) {
+			if fullMethodName == "/talos.testproto.TestService/PingStream" {
+				return proxy.One2One, []proxy.Backend{upstream}, nil
+			}
+
+			return proxy.One2One, []proxy.Backend{&proxy.DialBackend{Pool: pool, Target: h.backendAddr}}, nil
+		},
+		Options:  []proxy.Option{proxy.WithEvents(writer)},
+		Pools:    []*proxy.ConnPool{pool},
+		Flushers: []proxy.Flusher{writer},
+	})
+	require.NoError(t, err)
+
+	dataListener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	controlListener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	serveErr := make(chan error, 1)
+
+	go func() { serveErr <- server.Serve(dataListener, controlListener) }()
+
+	conn, err := grpc.Dial(dataListener.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+
+	t.Cleanup(func() { conn.Close() }) //nolint: errcheck
+
+	client := pb.NewTestServiceClient(conn)
+
+	_, err = client.Ping(testContext(t), &pb.PingRequest{Value: "foo"})
+	require.NoError(t, err)
+
+	pooled, err := pool.Get(testContext(t), h.backendAddr)
+	require.NoError(t, err)
+
+	// the stream in progress is not drained before the deadline
+	stream, err := client.PingStream(testContext(t))
+	require.NoError(t, err)
+
+	require.NoError(t, stream.Send(&pb.PingRequest{Value: "foo"}))
+
+	_, err = stream.Recv()
+	require.NoError(t, err)
+
+	assert.Empty(t, events.String())
+
+	ctx, cancel := context.WithTimeout(testContext(t), 100*time.Millisecond)
+	defer cancel()
+
+	err = server.Shutdown(ctx)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	_, err = stream.Recv()
+	require.Error(t, err)
+
+	require.NoError(t, <-serveErr)
+
+	// the pools are closed, the events are flushed
+	assert.Equal(t, connectivity.Shutdown, pooled.GetState())
+	assert.Contains(t, events.String(), "call.finished")
+}
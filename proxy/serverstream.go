@@ -5,6 +5,7 @@ package proxy
 
 import (
 	"sync"
+	"time"
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/metadata"
@@ -17,6 +18,19 @@ type ServerStreamWrapper struct {
 	sendMu sync.Mutex
 }
 
+// lockSend acquires the send lock, recording the contention if the lock is held by another goroutine.
+func (wrapper *ServerStreamWrapper) lockSend() {
+	if wrapper.sendMu.TryLock() {
+		return
+	}
+
+	start := time.Now()
+
+	wrapper.sendMu.Lock()
+
+	recordLockContention(time.Since(start))
+}
+
 // SetHeader sets the header metadata.
 //
 // It may be called multiple times.
@@ -26,7 +40,7 @@ type ServerStreamWrapper struct {
 //   - The first response is sent out;
 //   - An RPC status is sent out (error or success).
 func (wrapper *ServerStreamWrapper) SetHeader(md metadata.MD) error {
-	wrapper.sendMu.Lock()
+	wrapper.lockSend()
 	defer wrapper.sendMu.Unlock()
 
 	err := wrapper.ServerStream.SetHeader(md)
@@ -42,7 +56,7 @@ func (wrapper *ServerStreamWrapper) SetHeader(md metadata.MD) error {
 // The provided md and headers set by SetHeader() will be sent.
 // It fails if called multiple times.
 func (wrapper *ServerStreamWrapper) SendHeader(md metadata.MD) error {
-	wrapper.sendMu.Lock()
+	wrapper.lockSend()
 	defer wrapper.sendMu.Unlock()
 
 	err := wrapper.ServerStream.SendHeader(md)
@@ -57,7 +71,7 @@ func (wrapper *ServerStreamWrapper) SendHeader(md metadata.MD) error {
 // SetTrailer sets the trailer metadata which will be sent with the RPC status.
 // When called more than once, all the provided metadata will be merged.
 func (wrapper *ServerStreamWrapper) SetTrailer(md metadata.MD) {
-	wrapper.sendMu.Lock()
+	wrapper.lockSend()
 	defer wrapper.sendMu.Unlock()
 
 	wrapper.ServerStream.SetTrailer(md)
@@ -78,7 +92,7 @@ func (wrapper *ServerStreamWrapper) SetTrailer(md metadata.MD) {
 // calling RecvMsg on the same stream at the same time, but it is not safe
 // to call SendMsg on the same stream in different goroutines.
 func (wrapper *ServerStreamWrapper) SendMsg(m interface{}) error {
-	wrapper.sendMu.Lock()
+	wrapper.lockSend()
 	defer wrapper.sendMu.Unlock()
 
 	return wrapper.ServerStream.SendMsg(m)
@@ -0,0 +1,286 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+// defaultShadowTimeout is the default timeout of the mirrored canary call.
+const defaultShadowTimeout = 30 * time.Second
+
+// shadowQueueSize is the number of request messages buffered for the canary.
+const shadowQueueSize = 64
+
+// ShadowPolicy configures mirroring of the calls to the canary backend.
+type ShadowPolicy struct {
+	// Canary is the backend which receives the mirrored calls.
+	Canary Backend
+	// Diff compares the canary responses with the primary responses, if set. The responses are decoded with
+	// the schema of the primary backend, see WithSchemaVersions.
+	Diff *ResponseDiff
+	// Weight is the fraction of the calls mirrored to the canary, from 0 to 1.
+	Weight float64
+	// Timeout of the canary call, the canary call is not canceled when the primary call finishes.
+	//
+	// Default is 30 seconds.
+	Timeout time.Duration
+}
+
+// WithShadowTraffic mirrors the sampled calls of the listed methods to the canary backend.
+//
+// Only one2one calls are mirrored. The canary receives the same request messages as the primary backend, and its
+// responses are discarded after the comparison with the primary responses by the ResponseDiff. Mirroring never
+// slows down the primary call: if the canary falls behind, the mirrored call is abandoned and counted as failed.
+// If fullMethodNames is empty, the policy is applied to all methods.
+func WithShadowTraffic(policy ShadowPolicy, fullMethodNames ...string) Option {
+	if policy.Timeout == 0 {
+		policy.Timeout = defaultShadowTimeout
+	}
+
+	return func(o *handlerOptions) {
+		if policy.Canary == nil || policy.Weight < 0 || policy.Weight > 1 {
+			o.invalid("shadow traffic policy should have the canary and the weight from 0 to 1")
+		}
+
+		if o.shadowPolicies == nil {
+			o.shadowPolicies = map[string]ShadowPolicy{}
+		}
+
+		if len(fullMethodNames) == 0 {
+			o.shadowPolicies[""] = policy
+
+			return
+		}
+
+		for _, name := range fullMethodNames {
+			o.shadowPolicies[name] = policy
+		}
+	}
+}
+
+// shadowConnect establishes the upstream stream to the canary via the same path as the primary upstream streams,
+// so that the canary call gets the same metadata processing, signing and client interceptors.
+type shadowConnect func(ctx context.Context, canary Backend) backendConnection
+
+// shadow starts the mirrored call if the call is sampled, the returned stream tees the primary stream to the canary.
+func (o *handlerOptions) shadow(ctx context.Context, fullMethodName string, backend Backend, primary grpc.ClientStream, connect shadowConnect) grpc.ClientStream {
+	policy, ok := o.shadowPolicies[fullMethodName]
+	if !ok {
+		policy, ok = o.shadowPolicies[""]
+	}
+
+	if !ok || policy.Canary == nil || rand.Float64() >= policy.Weight { //nolint:gosec
+		return primary
+	}
+
+	// the canary call is not canceled when the primary call finishes
+	canaryCtx, cancel := context.WithTimeout(detachedContext{ctx}, policy.Timeout)
+
+	call := &shadowCall{
+		policy:      policy,
+		resolver:    o.backendResolver(backend),
+		method:      fullMethodName,
+		queue:       make(chan []byte, shadowQueueSize),
+		primaryDone: make(chan struct{}),
+		cancel:      cancel,
+	}
+
+	go call.run(ctx, canaryCtx, connect)
+
+	return &shadowClientStream{ClientStream: primary, call: call}
+}
+
+// shadowCall is the mirrored call to the canary.
+type shadowCall struct {
+	primaryErr  error
+	resolver    *DescriptorResolver
+	primaryDone chan struct{}
+	queue       chan []byte
+	cancel      context.CancelFunc
+
+	policy           ShadowPolicy
+	method           string
+	primaryResponses [][]byte
+
+	doneOnce sync.Once
+	mu       sync.Mutex
+	closed   bool
+	overflow bool
+}
+
+// send queues the request message for the canary without blocking.
+func (c *shadowCall) send(payload []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed || c.overflow {
+		return
+	}
+
+	select {
+	case c.queue <- append([]byte(nil), payload...):
+	default:
+		// the canary falls behind, abandon the mirrored call: it is canceled instead of half-closed,
+		// so that the canary never handles the truncated request stream as the complete one
+		c.overflow = true
+
+		c.cancel()
+	}
+}
+
+// closeSend finishes the canary requests.
+func (c *shadowCall) closeSend() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed || c.overflow {
+		return
+	}
+
+	c.closed = true
+
+	close(c.queue)
+}
+
+// abandoned returns true if the canary queue overflowed.
+func (c *shadowCall) abandoned() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.overflow
+}
+
+// run performs the canary call and compares the responses when both calls are done.
+func (c *shadowCall) run(ctx, canaryCtx context.Context, connect shadowConnect) {
+	defer c.cancel()
+
+	canaryResponses, established, canaryErr := c.call(canaryCtx, connect)
+
+	// wait for the primary call, the context is canceled once the primary call is done
+	select {
+	case <-c.primaryDone:
+	case <-ctx.Done():
+		select {
+		case <-c.primaryDone:
+		default:
+			// primary call was aborted
+			return
+		}
+	}
+
+	if c.policy.Diff == nil {
+		return
+	}
+
+	if !established || c.abandoned() {
+		c.policy.Diff.failed()
+
+		return
+	}
+
+	c.policy.Diff.compare(c.resolver, c.method, c.primaryResponses, canaryResponses, c.primaryErr, canaryErr)
+}
+
+// call performs the canary call, established is false if the call couldn't be established.
+//
+// The responses are collected only if they are compared.
+func (c *shadowCall) call(canaryCtx context.Context, connect shadowConnect) (responses [][]byte, established bool, err error) {
+	conn := connect(canaryCtx, c.policy.Canary)
+
+	defer func() {
+		endStatsLegs([]backendConnection{conn}, err)
+	}()
+
+	if conn.connError != nil {
+		return nil, false, conn.connError
+	}
+
+	stream := conn.clientStream
+
+	go func() {
+		for {
+			select {
+			case payload, ok := <-c.queue:
+				if !ok {
+					stream.CloseSend() //nolint:errcheck
+
+					return
+				}
+
+				if stream.SendMsg(NewFrame(payload)) != nil {
+					// the error is returned by RecvMsg
+					return
+				}
+			case <-canaryCtx.Done():
+				return
+			}
+		}
+	}()
+
+	for {
+		f := &Frame{}
+
+		if err = stream.RecvMsg(f); err != nil {
+			if errors.Is(err, io.EOF) {
+				err = nil
+			}
+
+			return responses, true, err
+		}
+
+		if c.policy.Diff != nil {
+			responses = append(responses, f.payload)
+		}
+	}
+}
+
+// shadowClientStream tees the primary stream to the canary.
+type shadowClientStream struct {
+	grpc.ClientStream
+
+	call *shadowCall
+}
+
+func (s *shadowClientStream) SendMsg(m interface{}) error {
+	if f, ok := m.(*Frame); ok {
+		s.call.send(f.payload)
+	}
+
+	return s.ClientStream.SendMsg(m)
+}
+
+func (s *shadowClientStream) CloseSend() error {
+	s.call.closeSend()
+
+	return s.ClientStream.CloseSend()
+}
+
+func (s *shadowClientStream) RecvMsg(m interface{}) error {
+	err := s.ClientStream.RecvMsg(m)
+	if err == nil {
+		if f, ok := m.(*Frame); ok && s.call.policy.Diff != nil {
+			s.call.primaryResponses = append(s.call.primaryResponses, append([]byte(nil), f.payload...))
+		}
+
+		return nil
+	}
+
+	s.call.doneOnce.Do(func() {
+		if !errors.Is(err, io.EOF) {
+			s.call.primaryErr = err
+		}
+
+		close(s.call.primaryDone)
+	})
+
+	return err
+}
@@ -0,0 +1,201 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+
+	"github.com/noncepad/grpc-proxy/proxy"
+	pb "github.com/noncepad/grpc-proxy/testservice"
+)
+
+// canaryService responds with a different counter.
+type canaryService struct {
+	assertingService
+}
+
+func (s *canaryService) Ping(ctx context.Context, ping *pb.PingRequest) (*pb.PingResponse, error) {
+	return &pb.PingResponse{Value: ping.Value, Counter: 43}, nil
+}
+
+func TestShadowTraffic(t *testing.T) {
+	pool := proxy.NewConnPool(grpc.WithTransportCredentials(insecure.NewCredentials()))
+	t.Cleanup(func() { pool.Close() }) //nolint: errcheck
+
+	for _, tt := range []struct { //nolint:govet
+		name    string
+		service pb.TestServiceServer
+		diff    *proxy.ResponseDiff
+		weight  float64
+
+		expected proxy.DiffStats
+		reason   string
+	}{
+		{
+			name:     "raw match",
+			service:  &assertingService{},
+			diff:     proxy.NewResponseDiff(proxy.DiffRaw, 10),
+			weight:   1,
+			expected: proxy.DiffStats{Compared: 2, Matched: 2},
+		},
+		{
+			name:     "raw mismatch",
+			service:  &canaryService{},
+			diff:     proxy.NewResponseDiff(proxy.DiffRaw, 10),
+			weight:   1,
+			expected: proxy.DiffStats{Compared: 2, Matched: 1, Mismatched: 1},
+			reason:   "response 0 differs",
+		},
+		{
+			name:     "fields mismatch",
+			service:  &canaryService{},
+			diff:     proxy.NewResponseDiff(proxy.DiffFields, 10),
+			weight:   1,
+			expected: proxy.DiffStats{Compared: 2, Matched: 1, Mismatched: 1},
+			reason:   `response 0: field "counter" differs`,
+		},
+		{
+			name:     "fields ignored",
+			service:  &canaryService{},
+			diff:     proxy.NewResponseDiff(proxy.DiffFields, 10, "counter"),
+			weight:   1,
+			expected: proxy.DiffStats{Compared: 2, Matched: 2},
+		},
+		{
+			name:     "not sampled",
+			service:  &canaryService{},
+			diff:     proxy.NewResponseDiff(proxy.DiffRaw, 10),
+			weight:   0,
+			expected: proxy.DiffStats{},
+		},
+	} {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			canary := newTestHarnessWithService(t, tt.service, one2oneDirector)
+
+			h := newTestHarness(t, one2oneDirector, proxy.WithShadowTraffic(proxy.ShadowPolicy{
+				Canary: &proxy.DialBackend{Pool: pool, Target: canary.backendAddr},
+				Diff:   tt.diff,
+				Weight: tt.weight,
+			}))
+
+			resp, err := h.client.Ping(testContext(t), &pb.PingRequest{Value: "foo"})
+			require.NoError(t, err)
+			assert.EqualValues(t, 42, resp.Counter)
+
+			_, err = h.client.PingError(testContext(t), &pb.PingRequest{Value: "foo"})
+			require.Error(t, err)
+
+			if tt.weight == 0 {
+				// give the mirrored calls (if any) time to finish
+				time.Sleep(100 * time.Millisecond)
+			} else {
+				require.Eventually(t, func() bool { return tt.diff.Stats().Compared == tt.expected.Compared }, 5*time.Second, 10*time.Millisecond)
+			}
+
+			stats := tt.diff.Stats()
+
+			assert.Equal(t, tt.expected.Compared, stats.Compared)
+			assert.Equal(t, tt.expected.Matched, stats.Matched)
+			assert.Equal(t, tt.expected.Mismatched, stats.Mismatched)
+			assert.Zero(t, stats.Failed)
+
+			if tt.reason == "" {
+				assert.Empty(t, stats.Samples)
+
+				return
+			}
+
+			require.Len(t, stats.Samples, 1)
+			assert.Equal(t, "/talos.testproto.TestService/Ping", stats.Samples[0].Method)
+			assert.Equal(t, tt.reason, stats.Samples[0].Reason)
+			assert.Len(t, stats.Samples[0].Primary, 1)
+			assert.Len(t, stats.Samples[0].Canary, 1)
+		})
+	}
+}
+
+// sinkService reads the whole PingStream before responding with the number of requests.
+type sinkService struct {
+	assertingService
+
+	// stall delays reading the requests
+	stall time.Duration
+	// outcome receives io.EOF if the stream was half-closed, or the error the stream was aborted with
+	outcome chan error
+}
+
+func (s *sinkService) PingStream(stream pb.TestService_PingStreamServer) error {
+	time.Sleep(s.stall)
+
+	for counter := int32(0); ; counter++ {
+		_, err := stream.Recv()
+		if err != nil {
+			if s.outcome != nil {
+				s.outcome <- err
+			}
+
+			if errors.Is(err, io.EOF) {
+				return stream.Send(&pb.PingResponse{Counter: counter})
+			}
+
+			return err
+		}
+	}
+}
+
+func TestShadowTrafficOverflow(t *testing.T) {
+	pool := proxy.NewConnPool(grpc.WithTransportCredentials(insecure.NewCredentials()))
+	t.Cleanup(func() { pool.Close() }) //nolint: errcheck
+
+	canaryService := &sinkService{stall: 500 * time.Millisecond, outcome: make(chan error, 1)}
+	canary := newTestHarnessWithService(t, canaryService, one2oneDirector)
+
+	diff := proxy.NewResponseDiff(proxy.DiffRaw, 10)
+
+	h := newTestHarnessWithService(t, &sinkService{}, one2oneDirector, proxy.WithShadowTraffic(proxy.ShadowPolicy{
+		Canary: &proxy.DialBackend{Pool: pool, Target: canary.backendAddr},
+		Diff:   diff,
+		Weight: 1,
+	}))
+
+	stream, err := h.client.PingStream(testContext(t))
+	require.NoError(t, err)
+
+	// the canary doesn't read the requests, so the flow control stalls the mirrored stream and the queue overflows
+	const requests = 256
+
+	for i := 0; i < requests; i++ {
+		require.NoError(t, stream.Send(&pb.PingRequest{Value: strings.Repeat("x", 32*1024)}))
+	}
+
+	require.NoError(t, stream.CloseSend())
+
+	resp, err := stream.Recv()
+	require.NoError(t, err)
+	assert.EqualValues(t, requests, resp.Counter)
+
+	// the canary never sees the truncated request stream as complete
+	select {
+	case canaryErr := <-canaryService.outcome:
+		assert.Equal(t, codes.Canceled, status.Code(canaryErr))
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "canary call was not finished")
+	}
+
+	require.Eventually(t, func() bool { return diff.Stats().Failed == 1 }, 5*time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,177 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+)
+
+// UpstreamSigner computes signature metadata for the upstream call.
+//
+// Signer receives the outgoing metadata (as returned by Backend.GetConnection) and the serialized first request message
+// (nil if the client didn't send any), returned metadata is appended to the outgoing metadata of the upstream call.
+type UpstreamSigner func(ctx context.Context, backend Backend, fullMethodName string, md metadata.MD, firstFrame []byte) (metadata.MD, error)
+
+// WithUpstreamSigner configures the signer for the upstream calls of the listed methods.
+//
+// Signing requires the first request message, so this option implies WithRequestPeek for the listed methods.
+// The methods should be listed explicitly, as peeking blocks the methods where the client waits for the server
+// to send first. If signer returns an error, the call to the backend fails with that error.
+func WithUpstreamSigner(signer UpstreamSigner, fullMethodNames ...string) Option {
+	return func(o *handlerOptions) {
+		if len(fullMethodNames) == 0 {
+			o.invalid("upstream signer requires the signed methods")
+		}
+
+		o.upstreamSigner = signer
+		o.upstreamSignedMethods = map[string]struct{}{}
+
+		if o.requestPeekMethods == nil {
+			o.requestPeekMethods = map[string]struct{}{}
+		}
+
+		for _, name := range fullMethodNames {
+			o.upstreamSignedMethods[name] = struct{}{}
+			o.requestPeekMethods[name] = struct{}{}
+		}
+	}
+}
+
+// signed checks whether the upstream calls of the method are signed.
+func (o *handlerOptions) signed(fullMethodName string) bool {
+	if o.upstreamSigner == nil {
+		return false
+	}
+
+	_, ok := o.upstreamSignedMethods[fullMethodName]
+
+	return ok
+}
+
+// signUpstream attaches signature metadata to the outgoing context.
+func (s *handler) signUpstream(outgoingCtx context.Context, backend Backend, fullMethodName string) (context.Context, error) {
+	md, _ := metadata.FromOutgoingContext(outgoingCtx)
+	firstFrame, _ := RequestFrameFromContext(outgoingCtx)
+
+	signature, err := s.options.upstreamSigner(outgoingCtx, backend, fullMethodName, md, firstFrame)
+	if err != nil {
+		return nil, &Error{
+			Err:     err,
+			Code:    codes.Internal,
+			Reason:  ReasonUpstreamSigning,
+			Message: fmt.Sprintf("error signing call to %s: %v", backend, err),
+			Backend: backend.String(),
+		}
+	}
+
+	return metadata.NewOutgoingContext(outgoingCtx, metadata.Join(md, signature)), nil
+}
+
+// HMAC signature metadata keys.
+const (
+	SignatureMetadataKey          = "proxy-signature"
+	SignatureTimestampMetadataKey = "proxy-signature-timestamp"
+)
+
+// HMACSigner returns an UpstreamSigner which signs upstream calls with HMAC-SHA256.
+//
+// The signature covers the method name, timestamp, values of the listed metadata keys (each value is signed
+// separately, so the multiple values are not confused with a single value containing a comma), and the SHA256 hash of
+// the first request message. Signature and timestamp are attached as SignatureMetadataKey and SignatureTimestampMetadataKey.
+// Backends can verify the signature with VerifyHMACSignature.
+func HMACSigner(key []byte, signedMetadataKeys ...string) UpstreamSigner {
+	return func(ctx context.Context, backend Backend, fullMethodName string, md metadata.MD, firstFrame []byte) (metadata.MD, error) {
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+		return metadata.Pairs(
+			SignatureMetadataKey, hmacSignature(key, fullMethodName, timestamp, md, signedMetadataKeys, firstFrame),
+			SignatureTimestampMetadataKey, timestamp,
+		), nil
+	}
+}
+
+// VerifyHMACSignature verifies the signature produced by HMACSigner.
+//
+// Metadata md is the incoming metadata of the backend call, maxAge limits the age of the signature (zero disables the check).
+func VerifyHMACSignature(key []byte, fullMethodName string, md metadata.MD, firstFrame []byte, maxAge time.Duration, signedMetadataKeys ...string) error {
+	signatures := md.Get(SignatureMetadataKey)
+	timestamps := md.Get(SignatureTimestampMetadataKey)
+
+	if len(signatures) != 1 || len(timestamps) != 1 {
+		return errors.New("missing signature")
+	}
+
+	if maxAge > 0 {
+		ts, err := strconv.ParseInt(timestamps[0], 10, 64)
+		if err != nil {
+			return errors.New("malformed signature timestamp")
+		}
+
+		if age := time.Since(time.Unix(ts, 0)); age > maxAge || age < -maxAge {
+			return errors.New("signature expired")
+		}
+	}
+
+	expected := hmacSignature(key, fullMethodName, timestamps[0], md, signedMetadataKeys, firstFrame)
+
+	if !hmac.Equal([]byte(expected), []byte(signatures[0])) {
+		return errors.New("signature mismatch")
+	}
+
+	return nil
+}
+
+func hmacSignature(key []byte, fullMethodName, timestamp string, md metadata.MD, signedMetadataKeys []string, firstFrame []byte) string {
+	keys := append([]string(nil), signedMetadataKeys...)
+	sort.Strings(keys)
+
+	frameHash := sha256.Sum256(firstFrame)
+
+	var canonical strings.Builder
+
+	canonical.WriteString(fullMethodName)
+	canonical.WriteByte('\n')
+	canonical.WriteString(timestamp)
+	canonical.WriteByte('\n')
+
+	// each value is prefixed by its length, so that the values containing the separators can't be shifted
+	// between the values and the keys with the same signature
+	for _, k := range keys {
+		k = strings.ToLower(k)
+		values := md.Get(k)
+
+		canonical.WriteString(k)
+		canonical.WriteByte(':')
+		canonical.WriteString(strconv.Itoa(len(values)))
+
+		for _, value := range values {
+			canonical.WriteByte(':')
+			canonical.WriteString(strconv.Itoa(len(value)))
+			canonical.WriteByte(':')
+			canonical.WriteString(value)
+		}
+
+		canonical.WriteByte('\n')
+	}
+
+	canonical.WriteString(hex.EncodeToString(frameHash[:]))
+
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(canonical.String())) //nolint:errcheck
+
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,144 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/noncepad/grpc-proxy/proxy"
+	pb "github.com/noncepad/grpc-proxy/testservice"
+)
+
+func TestUpstreamSigner(t *testing.T) {
+	key := []byte("secret")
+	signer := proxy.HMACSigner(key, clientMdKey)
+
+	var verifyErr error
+
+	h := newTestHarness(t, one2oneDirector, proxy.WithUpstreamSigner(
+		func(ctx context.Context, backend proxy.Backend, fullMethodName string, md metadata.MD, firstFrame []byte) (metadata.MD, error) {
+			var req pb.PingRequest
+
+			if err := proto.Unmarshal(firstFrame, &req); err != nil {
+				return nil, err
+			}
+
+			if req.Value == "fail" {
+				return nil, errors.New("refusing to sign")
+			}
+
+			signature, err := signer(ctx, backend, fullMethodName, md, firstFrame)
+			if err != nil {
+				return nil, err
+			}
+
+			verifyErr = proxy.VerifyHMACSignature(key, fullMethodName, metadata.Join(md, signature), firstFrame, time.Minute, clientMdKey)
+
+			return signature, nil
+		}, "/talos.testproto.TestService/Ping"))
+
+	ctx := testContext(t)
+
+	_, err := h.client.Ping(ctx, &pb.PingRequest{Value: "foo"})
+	require.NoError(t, err)
+	require.NoError(t, verifyErr)
+
+	_, err = h.client.Ping(ctx, &pb.PingRequest{Value: "fail"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "refusing to sign")
+}
+
+// serverFirstService sends the PingStream response before the client sends anything.
+type serverFirstService struct {
+	assertingService
+}
+
+func (s *serverFirstService) PingStream(stream pb.TestService_PingStreamServer) error {
+	if err := stream.Send(&pb.PingResponse{Value: "hello"}); err != nil {
+		return err
+	}
+
+	ping, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+
+	return stream.Send(&pb.PingResponse{Value: ping.Value})
+}
+
+func TestUpstreamSignerServerFirst(t *testing.T) {
+	var signed []string
+
+	h := newTestHarnessWithService(t, &serverFirstService{}, one2oneDirector, proxy.WithUpstreamSigner(
+		func(ctx context.Context, backend proxy.Backend, fullMethodName string, md metadata.MD, firstFrame []byte) (metadata.MD, error) {
+			signed = append(signed, fullMethodName)
+
+			return nil, nil
+		}, "/talos.testproto.TestService/Ping"))
+
+	ctx, cancel := context.WithTimeout(testContext(t), time.Second)
+	defer cancel()
+
+	// the request of the unsigned method is not peeked, so the server sends first
+	stream, err := h.client.PingStream(ctx)
+	require.NoError(t, err)
+
+	resp, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, "hello", resp.Value)
+
+	require.NoError(t, stream.Send(&pb.PingRequest{Value: "foo"}))
+
+	resp, err = stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, "foo", resp.Value)
+
+	require.NoError(t, stream.CloseSend())
+
+	_, err = h.client.Ping(ctx, &pb.PingRequest{Value: "foo"})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"/talos.testproto.TestService/Ping"}, signed)
+}
+
+func TestVerifyHMACSignature(t *testing.T) {
+	key := []byte("secret")
+	method := "/talos.testproto.TestService/Ping"
+	frame := []byte("frame")
+	md := metadata.Pairs("tenant", "acme")
+
+	signature, err := proxy.HMACSigner(key, "tenant")(context.Background(), nil, method, md, frame)
+	require.NoError(t, err)
+
+	signed := metadata.Join(md, signature)
+
+	require.NoError(t, proxy.VerifyHMACSignature(key, method, signed, frame, time.Minute, "tenant"))
+	require.Error(t, proxy.VerifyHMACSignature([]byte("other"), method, signed, frame, time.Minute, "tenant"))
+	require.Error(t, proxy.VerifyHMACSignature(key, method, signed, []byte("other"), time.Minute, "tenant"))
+	require.Error(t, proxy.VerifyHMACSignature(key, "/other", signed, frame, time.Minute, "tenant"))
+
+	tampered := signed.Copy()
+	tampered.Set("tenant", "evil")
+	require.Error(t, proxy.VerifyHMACSignature(key, method, tampered, frame, time.Minute, "tenant"))
+
+	require.Error(t, proxy.VerifyHMACSignature(key, method, md, frame, time.Minute, "tenant"))
+
+	// multiple values are not confused with a single value containing a comma
+	multi := metadata.Pairs("tenant", "acme", "tenant", "globex")
+
+	signature, err = proxy.HMACSigner(key, "tenant")(context.Background(), nil, method, multi, frame)
+	require.NoError(t, err)
+
+	joined := metadata.Join(metadata.Pairs("tenant", "acme,globex"), signature)
+	require.Error(t, proxy.VerifyHMACSignature(key, method, joined, frame, time.Minute, "tenant"))
+	require.NoError(t, proxy.VerifyHMACSignature(key, method, metadata.Join(multi, signature), frame, time.Minute, "tenant"))
+}
@@ -0,0 +1,311 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// SLObjective is the service level objective of the method.
+type SLObjective struct {
+	// Name identifies the objective in the alerts and the stats.
+	Name string
+	// Method is the full method name the objective applies to, empty means all methods.
+	Method string
+	// Target is the fraction of the good calls, e.g. 0.999.
+	Target float64
+	// LatencyThreshold makes the calls slower than the threshold bad, zero means the latency is not part
+	// of the objective.
+	LatencyThreshold time.Duration
+	// ErrorCodes are the codes of the bad calls, the default is the codes of the server failures:
+	// Unknown, DeadlineExceeded, Internal, Unavailable and DataLoss.
+	ErrorCodes []codes.Code
+}
+
+// BurnRateWindow is the multiwindow burn rate alert condition: the alert fires when the burn rate of the error budget
+// exceeds the threshold over both the long and the short window, and resolves when it no longer does.
+//
+// The burn rate is the ratio of the bad calls divided by the error budget (1 - Target): the burn rate of 1 consumes
+// exactly the error budget over the window.
+type BurnRateWindow struct {
+	Long      time.Duration `json:"long"`
+	Short     time.Duration `json:"short"`
+	Threshold float64       `json:"threshold"`
+}
+
+// DefaultBurnRateWindows are the windows used if none are configured: 2% of the monthly budget burnt in 1 hour,
+// and 5% of the monthly budget burnt in 6 hours.
+var DefaultBurnRateWindows = []BurnRateWindow{
+	{Long: time.Hour, Short: 5 * time.Minute, Threshold: 14.4},
+	{Long: 6 * time.Hour, Short: 30 * time.Minute, Threshold: 6},
+}
+
+// SLOAlert is the change of the state of the burn rate alert.
+type SLOAlert struct {
+	Time      time.Time
+	Objective string
+	Window    BurnRateWindow
+	// LongBurnRate and ShortBurnRate are the burn rates over the windows at the time of the change.
+	LongBurnRate  float64
+	ShortBurnRate float64
+	// Firing is set when the alert fires, and cleared when it resolves.
+	Firing bool
+}
+
+// SLOStats is the state of the objective.
+type SLOStats struct {
+	Objective string          `json:"objective"`
+	Method    string          `json:"method,omitempty"`
+	Target    float64         `json:"target"`
+	Windows   []BurnRateStats `json:"windows"`
+}
+
+// BurnRateStats is the state of the burn rate alert of the objective.
+type BurnRateStats struct {
+	Window        BurnRateWindow `json:"window"`
+	LongBurnRate  float64        `json:"long_burn_rate"`
+	ShortBurnRate float64        `json:"short_burn_rate"`
+	Firing        bool           `json:"firing"`
+}
+
+// SLOTracker measures the calls against the objectives, and alerts when the error budgets burn too fast.
+//
+// The proxy sees every call of the upstream services, including the ones which never reached a backend,
+// so it is the natural point to measure the objectives of the services.
+//
+// SLOTracker implements http.Handler which serves the stats as JSON, so that it can be mounted on the admin HTTP server.
+type SLOTracker struct {
+	alert      func(SLOAlert)
+	windows    []BurnRateWindow
+	objectives []*sloObjective
+	resolution time.Duration
+
+	mu sync.Mutex
+}
+
+// sloObjective is the state of the objective: the ring of the call counters.
+type sloObjective struct {
+	SLObjective
+
+	badCodes map[codes.Code]struct{}
+	buckets  []sloBucket
+	firing   []bool
+}
+
+// sloBucket counts the calls of the time slot.
+type sloBucket struct {
+	slot  int64
+	total uint64
+	bad   uint64
+}
+
+// NewSLOTracker creates the tracker of the objectives, alert (if set) is called on each change of the alert state.
+//
+// If windows is empty, DefaultBurnRateWindows are used. The calls are counted in the time slots
+// of 1/10 of the shortest window.
+func NewSLOTracker(objectives []SLObjective, windows []BurnRateWindow, alert func(SLOAlert)) *SLOTracker {
+	if len(windows) == 0 {
+		windows = DefaultBurnRateWindows
+	}
+
+	var longest, shortest time.Duration
+
+	for _, w := range windows {
+		if w.Long > longest {
+			longest = w.Long
+		}
+
+		if shortest == 0 || (w.Short > 0 && w.Short < shortest) {
+			shortest = w.Short
+		}
+	}
+
+	t := &SLOTracker{
+		alert:      alert,
+		windows:    append([]BurnRateWindow(nil), windows...),
+		resolution: shortest / 10,
+	}
+
+	if t.resolution <= 0 {
+		t.resolution = time.Second
+	}
+
+	for _, objective := range objectives {
+		o := &sloObjective{
+			SLObjective: objective,
+			badCodes:    map[codes.Code]struct{}{},
+			buckets:     make([]sloBucket, int(longest/t.resolution)+1),
+			firing:      make([]bool, len(windows)),
+		}
+
+		errorCodes := objective.ErrorCodes
+		if errorCodes == nil {
+			errorCodes = []codes.Code{codes.Unknown, codes.DeadlineExceeded, codes.Internal, codes.Unavailable, codes.DataLoss}
+		}
+
+		for _, code := range errorCodes {
+			o.badCodes[code] = struct{}{}
+		}
+
+		t.objectives = append(t.objectives, o)
+	}
+
+	return t
+}
+
+// WithSLOTracker measures the calls against the objectives of the tracker.
+func WithSLOTracker(tracker *SLOTracker) Option {
+	return func(o *handlerOptions) {
+		if tracker == nil {
+			o.sloTracker = nil
+
+			return
+		}
+
+		for _, objective := range tracker.objectives {
+			if objective.Target <= 0 || objective.Target >= 1 {
+				o.invalid("SLO target of %q should be between 0 and 1, got %v", objective.Name, objective.Target)
+			}
+		}
+
+		for _, w := range tracker.windows {
+			if w.Short <= 0 || w.Long < w.Short || w.Threshold <= 0 {
+				o.invalid("invalid burn rate window %+v", w)
+			}
+		}
+
+		o.sloTracker = tracker
+	}
+}
+
+// Stats returns the state of the objectives.
+func (t *SLOTracker) Stats() []SLOStats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := t.slot(time.Now())
+
+	result := make([]SLOStats, 0, len(t.objectives))
+
+	for _, o := range t.objectives {
+		stats := SLOStats{
+			Objective: o.Name,
+			Method:    o.Method,
+			Target:    o.Target,
+		}
+
+		for i, w := range t.windows {
+			stats.Windows = append(stats.Windows, BurnRateStats{
+				Window:        w,
+				LongBurnRate:  t.burnRate(o, now, w.Long),
+				ShortBurnRate: t.burnRate(o, now, w.Short),
+				Firing:        o.firing[i],
+			})
+		}
+
+		result = append(result, stats)
+	}
+
+	return result
+}
+
+// ServeHTTP implements http.Handler.
+func (t *SLOTracker) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	json.NewEncoder(w).Encode(t.Stats()) //nolint:errcheck
+}
+
+// record counts the finished call against the objectives of the method, and evaluates the alerts.
+//
+// It is safe to be called on nil tracker.
+func (t *SLOTracker) record(fullMethodName string, duration time.Duration, err error) {
+	if t == nil {
+		return
+	}
+
+	now := time.Now()
+	code := status.Code(err)
+
+	var alerts []SLOAlert
+
+	t.mu.Lock()
+
+	slot := t.slot(now)
+
+	for _, o := range t.objectives {
+		if o.Method != "" && o.Method != fullMethodName {
+			continue
+		}
+
+		bucket := &o.buckets[slot%int64(len(o.buckets))]
+		if bucket.slot != slot {
+			*bucket = sloBucket{slot: slot}
+		}
+
+		bucket.total++
+
+		if _, bad := o.badCodes[code]; bad || (o.LatencyThreshold > 0 && duration > o.LatencyThreshold) {
+			bucket.bad++
+		}
+
+		for i, w := range t.windows {
+			long, short := t.burnRate(o, slot, w.Long), t.burnRate(o, slot, w.Short)
+
+			firing := long >= w.Threshold && short >= w.Threshold
+			if firing == o.firing[i] {
+				continue
+			}
+
+			o.firing[i] = firing
+
+			alerts = append(alerts, SLOAlert{
+				Time:          now,
+				Objective:     o.Name,
+				Window:        w,
+				LongBurnRate:  long,
+				ShortBurnRate: short,
+				Firing:        firing,
+			})
+		}
+	}
+
+	t.mu.Unlock()
+
+	if t.alert != nil {
+		for _, alert := range alerts {
+			t.alert(alert)
+		}
+	}
+}
+
+func (t *SLOTracker) slot(now time.Time) int64 {
+	return now.UnixNano() / int64(t.resolution)
+}
+
+// burnRate returns the burn rate of the objective over the window ending at the slot.
+func (t *SLOTracker) burnRate(o *sloObjective, slot int64, window time.Duration) float64 {
+	first := slot - int64(window/t.resolution) + 1
+
+	var total, bad uint64
+
+	for _, bucket := range o.buckets {
+		if bucket.slot >= first && bucket.slot <= slot {
+			total += bucket.total
+			bad += bucket.bad
+		}
+	}
+
+	if total == 0 {
+		return 0
+	}
+
+	return float64(bad) / float64(total) / (1 - o.Target)
+}
@@ -0,0 +1,78 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+
+	"github.com/noncepad/grpc-proxy/proxy"
+	pb "github.com/noncepad/grpc-proxy/testservice"
+)
+
+func TestSLOBurnRateAlerts(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		alerts []proxy.SLOAlert
+	)
+
+	tracker := proxy.NewSLOTracker([]proxy.SLObjective{
+		{
+			Name:       "failed-precondition",
+			Target:     0.9,
+			ErrorCodes: []codes.Code{codes.FailedPrecondition},
+		},
+		{
+			Name:   "ping-empty",
+			Method: "/talos.testproto.TestService/PingEmpty",
+			Target: 0.99,
+		},
+	}, []proxy.BurnRateWindow{
+		{Long: time.Second, Short: 100 * time.Millisecond, Threshold: 2},
+	}, func(alert proxy.SLOAlert) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		alerts = append(alerts, alert)
+	})
+
+	h := newTestHarness(t, one2oneDirector, proxy.WithSLOTracker(tracker))
+
+	for i := 0; i < 3; i++ {
+		_, err := h.client.PingError(testContext(t), &pb.PingRequest{Value: "ping"})
+		require.Error(t, err)
+	}
+
+	mu.Lock()
+	require.Len(t, alerts, 1)
+	assert.Equal(t, "failed-precondition", alerts[0].Objective)
+	assert.True(t, alerts[0].Firing)
+	assert.InDelta(t, 10, alerts[0].LongBurnRate, 0.001)
+	mu.Unlock()
+
+	stats := tracker.Stats()
+	require.Len(t, stats, 2)
+	assert.True(t, stats[0].Windows[0].Firing)
+	assert.False(t, stats[1].Windows[0].Firing)
+
+	// the short window recovers after the good call
+	time.Sleep(150 * time.Millisecond)
+
+	_, err := h.client.PingEmpty(testContext(t), &pb.Empty{})
+	require.NoError(t, err)
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	require.Len(t, alerts, 2)
+	assert.Equal(t, "failed-precondition", alerts[1].Objective)
+	assert.False(t, alerts[1].Firing)
+	assert.Zero(t, alerts[1].ShortBurnRate)
+	assert.InDelta(t, 7.5, alerts[1].LongBurnRate, 0.001)
+}
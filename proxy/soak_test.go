@@ -0,0 +1,235 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+//go:build soak
+
+package proxy_test
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"io"
+	"math/rand"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/noncepad/grpc-proxy/proxy"
+	pb "github.com/noncepad/grpc-proxy/testservice"
+)
+
+// The soak test is opt-in, run it with:
+//
+//	go test -tags soak -run TestSoak -timeout 0 ./proxy -soak.calls 1000000
+var (
+	soakCalls          = flag.Int("soak.calls", 1_000_000, "number of the proxied calls")
+	soakConcurrency    = flag.Int("soak.concurrency", 64, "number of the concurrent callers")
+	soakCancelRate     = flag.Float64("soak.cancel", 0.1, "fraction of the calls canceled by the client")
+	soakSeed           = flag.Int64("soak.seed", 1, "seed of the call mix, so that the runs are reproducible")
+	soakGoroutineLeak  = flag.Int("soak.goroutine-budget", 50, "allowed growth of the number of the goroutines")
+	soakHeapLeakMiB    = flag.Int("soak.heap-budget", 64, "allowed growth of the live heap, MiB")
+	soakSettleDuration = flag.Duration("soak.settle", 10*time.Second, "time to wait for the goroutines to finish")
+)
+
+// soakCall is a kind of the call in the mix.
+type soakCall func(ctx context.Context, client pb.TestServiceClient) error
+
+var soakMix = []soakCall{
+	// unary one2one
+	func(ctx context.Context, client pb.TestServiceClient) error {
+		_, err := client.Ping(ctx, &pb.PingRequest{Value: "soak"})
+
+		return err
+	},
+	// unary one2many
+	func(ctx context.Context, client pb.TestServiceClient) error {
+		_, err := client.Ping(metadata.AppendToOutgoingContext(ctx, "soak-mode", "one2many"), &pb.PingRequest{Value: "soak"})
+
+		return err
+	},
+	// unary error
+	func(ctx context.Context, client pb.TestServiceClient) error {
+		_, err := client.PingError(ctx, &pb.PingRequest{Value: "soak"})
+		if status.Code(err) == codes.FailedPrecondition {
+			return nil
+		}
+
+		return err
+	},
+	// server streaming, abandoned halfway
+	func(ctx context.Context, client pb.TestServiceClient) error {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		stream, err := client.PingList(ctx, &pb.PingRequest{Value: "soak"})
+		if err != nil {
+			return err
+		}
+
+		for i := 0; i < countListResponses/2; i++ {
+			if _, err = stream.Recv(); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	},
+	// bidi streaming
+	func(ctx context.Context, client pb.TestServiceClient) error {
+		stream, err := client.PingStream(ctx)
+		if err != nil {
+			return err
+		}
+
+		for i := 0; i < 5; i++ {
+			if err = stream.Send(&pb.PingRequest{Value: "soak"}); err != nil {
+				return err
+			}
+
+			if _, err = stream.Recv(); err != nil {
+				return err
+			}
+		}
+
+		if err = stream.CloseSend(); err != nil {
+			return err
+		}
+
+		if _, err = stream.Recv(); !errors.Is(err, io.EOF) {
+			return err
+		}
+
+		return nil
+	},
+}
+
+// soakUsage is the resource usage of the process.
+type soakUsage struct {
+	goroutines int
+	heap       uint64
+}
+
+func measureSoakUsage() soakUsage {
+	runtime.GC()
+
+	var stats runtime.MemStats
+
+	runtime.ReadMemStats(&stats)
+
+	return soakUsage{goroutines: runtime.NumGoroutine(), heap: stats.HeapAlloc}
+}
+
+// TestSoak drives the mix of the proxied calls with random cancellations, and asserts that the goroutines
+// and the live heap don't grow beyond the budgets, so that the leaks are caught (and bisected) by the failing run.
+func TestSoak(t *testing.T) {
+	h := newTestHarnessWithService(t, &lenientService{}, func(backend proxy.Backend) proxy.StreamDirector {
+		return func(ctx context.Context, fullMethodName string) (proxy.Mode, []proxy.Backend, error) {
+			md, _ := metadata.FromIncomingContext(ctx)
+
+			if len(md.Get("soak-mode")) > 0 {
+				return proxy.One2Many, []proxy.Backend{
+					&taggedBackend{Backend: backend, tag: "a"},
+					&taggedBackend{Backend: backend, tag: "b"},
+				}, nil
+			}
+
+			return proxy.One2One, []proxy.Backend{backend}, nil
+		}
+	})
+
+	var (
+		next, failed, canceled atomic.Int64
+		baseline               soakUsage
+		baselineOnce           sync.Once
+		wg                     sync.WaitGroup
+	)
+
+	// the baseline is taken after the warm-up, once the connections and the pools are established
+	warmup := int64(*soakCalls / 100)
+
+	start := time.Now()
+
+	for worker := 0; worker < *soakConcurrency; worker++ {
+		wg.Add(1)
+
+		go func(worker int) {
+			defer wg.Done()
+
+			rnd := rand.New(rand.NewSource(*soakSeed + int64(worker))) //nolint:gosec
+
+			for {
+				n := next.Add(1)
+				if n > int64(*soakCalls) {
+					return
+				}
+
+				if n == warmup {
+					baselineOnce.Do(func() { baseline = measureSoakUsage() })
+				}
+
+				if n%100_000 == 0 {
+					t.Logf("%d calls in %s, %d goroutines", n, time.Since(start).Round(time.Second), runtime.NumGoroutine())
+				}
+
+				ctx, cancel := context.WithTimeout(metadata.AppendToOutgoingContext(context.Background(), clientMdKey, "true"), 10*time.Second)
+
+				var timer *time.Timer
+
+				cancelCall := rnd.Float64() < *soakCancelRate
+				if cancelCall {
+					// cancel at a random point of the call
+					timer = time.AfterFunc(time.Duration(rnd.Int63n(int64(time.Millisecond))), cancel)
+				}
+
+				err := soakMix[rnd.Intn(len(soakMix))](ctx, h.client)
+
+				if timer != nil {
+					timer.Stop()
+				}
+
+				cancel()
+
+				switch {
+				case err == nil:
+				case cancelCall && status.Code(err) == codes.Canceled:
+					canceled.Add(1)
+				default:
+					if failed.Add(1) <= 10 {
+						t.Logf("call %d failed: %v", n, err)
+					}
+				}
+			}
+		}(worker)
+	}
+
+	wg.Wait()
+
+	t.Logf("%d calls in %s, %d canceled, %d failed", *soakCalls, time.Since(start).Round(time.Second), canceled.Load(), failed.Load())
+
+	baselineOnce.Do(func() { baseline = measureSoakUsage() })
+
+	// the canceled calls are torn down asynchronously
+	var usage soakUsage
+
+	for deadline := time.Now().Add(*soakSettleDuration); ; time.Sleep(100 * time.Millisecond) {
+		usage = measureSoakUsage()
+
+		if usage.goroutines-baseline.goroutines <= *soakGoroutineLeak || time.Now().After(deadline) {
+			break
+		}
+	}
+
+	t.Logf("goroutines: %d -> %d, heap: %d -> %d KiB", baseline.goroutines, usage.goroutines, baseline.heap>>10, usage.heap>>10)
+
+	require.Zero(t, failed.Load(), "calls failed")
+	require.LessOrEqual(t, usage.goroutines-baseline.goroutines, *soakGoroutineLeak, "goroutine budget exceeded")
+	require.LessOrEqual(t, int64(usage.heap)-int64(baseline.heap), int64(*soakHeapLeakMiB)<<20, "heap budget exceeded")
+}
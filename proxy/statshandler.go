@@ -0,0 +1,232 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/stats"
+)
+
+// WithStatsHandler reports the RPC events of the proxied calls to the stats handler.
+//
+// The events are reported both for the client leg (Client is false) and for each upstream leg (Client is true),
+// so that the existing stats tooling (e.g. OpenCensus or OpenTelemetry stats handlers) works with the proxy unmodified.
+// The handler is not invoked for the connection events (TagConn and HandleConn), as the proxy doesn't own the connections.
+//
+// Payloads are reported as *Frame, i.e. the raw bytes, as the proxy doesn't decode the messages.
+func WithStatsHandler(handler stats.Handler) Option {
+	return func(o *handlerOptions) {
+		o.statsHandler = handler
+	}
+}
+
+// statsServerStream reports the events of the client leg.
+type statsServerStream struct {
+	grpc.ServerStream
+
+	ctx       context.Context //nolint:containedctx
+	handler   stats.Handler
+	beginTime time.Time
+}
+
+func newStatsServerStream(handler stats.Handler, serverStream grpc.ServerStream, fullMethodName string) *statsServerStream {
+	s := &statsServerStream{
+		ServerStream: serverStream,
+		ctx:          handler.TagRPC(serverStream.Context(), &stats.RPCTagInfo{FullMethodName: fullMethodName}),
+		handler:      handler,
+		beginTime:    time.Now(),
+	}
+
+	handler.HandleRPC(s.ctx, &stats.Begin{
+		BeginTime:      s.beginTime,
+		IsClientStream: true,
+		IsServerStream: true,
+	})
+
+	md, _ := metadata.FromIncomingContext(serverStream.Context())
+
+	handler.HandleRPC(s.ctx, &stats.InHeader{
+		Header:     md,
+		FullMethod: fullMethodName,
+	})
+
+	return s
+}
+
+// Context returns the context tagged by the stats handler.
+func (s *statsServerStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *statsServerStream) SendMsg(m interface{}) error {
+	err := s.ServerStream.SendMsg(m)
+
+	if f, ok := m.(*Frame); ok && err == nil {
+		s.handler.HandleRPC(s.ctx, &stats.OutPayload{
+			Payload:  f,
+			Data:     f.payload,
+			Length:   f.Size(),
+			SentTime: time.Now(),
+		})
+	}
+
+	return err
+}
+
+func (s *statsServerStream) RecvMsg(m interface{}) error {
+	err := s.ServerStream.RecvMsg(m)
+
+	if f, ok := m.(*Frame); ok && err == nil {
+		s.handler.HandleRPC(s.ctx, &stats.InPayload{
+			Payload:  f,
+			Data:     f.payload,
+			Length:   f.Size(),
+			RecvTime: time.Now(),
+		})
+	}
+
+	return err
+}
+
+func (s *statsServerStream) end(err error) {
+	s.handler.HandleRPC(s.ctx, &stats.End{
+		BeginTime: s.beginTime,
+		EndTime:   time.Now(),
+		Error:     err,
+	})
+}
+
+// statsLeg reports the events of the upstream leg.
+type statsLeg struct {
+	ctx       context.Context //nolint:containedctx
+	handler   stats.Handler
+	beginTime time.Time
+	trailer   func() metadata.MD
+	endOnce   sync.Once
+}
+
+// newStatsLeg tags the upstream call, it should be created before the upstream stream.
+func newStatsLeg(ctx context.Context, handler stats.Handler, fullMethodName string) *statsLeg {
+	leg := &statsLeg{
+		ctx:       handler.TagRPC(ctx, &stats.RPCTagInfo{FullMethodName: fullMethodName}),
+		handler:   handler,
+		beginTime: time.Now(),
+	}
+
+	handler.HandleRPC(leg.ctx, &stats.Begin{
+		Client:         true,
+		BeginTime:      leg.beginTime,
+		IsClientStream: true,
+		IsServerStream: true,
+	})
+
+	md, _ := metadata.FromOutgoingContext(leg.ctx)
+
+	handler.HandleRPC(leg.ctx, &stats.OutHeader{
+		Client:     true,
+		Header:     md,
+		FullMethod: fullMethodName,
+	})
+
+	return leg
+}
+
+// wrap reports the messages exchanged over the upstream stream.
+func (leg *statsLeg) wrap(clientStream grpc.ClientStream) grpc.ClientStream {
+	leg.trailer = clientStream.Trailer
+
+	return &statsClientStream{ClientStream: clientStream, leg: leg}
+}
+
+// end reports the end of the upstream call, only the first call has any effect.
+func (leg *statsLeg) end(err error) {
+	leg.endOnce.Do(func() {
+		end := &stats.End{
+			Client:    true,
+			BeginTime: leg.beginTime,
+			EndTime:   time.Now(),
+			Error:     err,
+		}
+
+		if err == nil && leg.trailer != nil {
+			end.Trailer = leg.trailer()
+		}
+
+		leg.handler.HandleRPC(leg.ctx, end)
+	})
+}
+
+type statsClientStream struct {
+	grpc.ClientStream
+
+	leg *statsLeg
+}
+
+func (s *statsClientStream) SendMsg(m interface{}) error {
+	err := s.ClientStream.SendMsg(m)
+
+	if f, ok := m.(*Frame); ok && err == nil {
+		s.leg.handler.HandleRPC(s.leg.ctx, &stats.OutPayload{
+			Client:   true,
+			Payload:  f,
+			Data:     f.payload,
+			Length:   f.Size(),
+			SentTime: time.Now(),
+		})
+	}
+
+	return err
+}
+
+func (s *statsClientStream) RecvMsg(m interface{}) error {
+	err := s.ClientStream.RecvMsg(m)
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			s.leg.end(nil)
+		} else {
+			s.leg.end(err)
+		}
+
+		return err
+	}
+
+	if f, ok := m.(*Frame); ok {
+		s.leg.handler.HandleRPC(s.leg.ctx, &stats.InPayload{
+			Client:   true,
+			Payload:  f,
+			Data:     f.payload,
+			Length:   f.Size(),
+			RecvTime: time.Now(),
+		})
+	}
+
+	return nil
+}
+
+// endStatsLegs reports the end of the upstream legs which didn't finish by the end of the proxied call.
+func endStatsLegs(backendConnections []backendConnection, err error) {
+	for i := range backendConnections {
+		leg := backendConnections[i].statsLeg
+		if leg == nil {
+			continue
+		}
+
+		switch {
+		case backendConnections[i].connError != nil:
+			leg.end(backendConnections[i].connError)
+		case err != nil:
+			leg.end(err)
+		default:
+			// the upstream stream is aborted
+			leg.end(context.Canceled)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/stats"
+
+	"github.com/noncepad/grpc-proxy/proxy"
+	pb "github.com/noncepad/grpc-proxy/testservice"
+)
+
+const statsTagMdKey = "stats-tag"
+
+// recordingStatsHandler records the RPC events by leg, upstream calls are tagged with the metadata.
+type recordingStatsHandler struct {
+	events map[string][]string
+	mu     sync.Mutex
+}
+
+type statsLegKey struct{}
+
+func (h *recordingStatsHandler) TagRPC(ctx context.Context, info *stats.RPCTagInfo) context.Context {
+	if _, ok := metadata.FromIncomingContext(ctx); ok {
+		if _, ok = metadata.FromOutgoingContext(ctx); !ok {
+			return context.WithValue(ctx, statsLegKey{}, "server")
+		}
+	}
+
+	return context.WithValue(metadata.AppendToOutgoingContext(ctx, statsTagMdKey, info.FullMethodName), statsLegKey{}, "client")
+}
+
+func (h *recordingStatsHandler) HandleRPC(ctx context.Context, s stats.RPCStats) {
+	leg, _ := ctx.Value(statsLegKey{}).(string)
+
+	event := fmt.Sprintf("%T", s)
+	if end, ok := s.(*stats.End); ok && end.Error != nil {
+		event += ":error"
+	}
+
+	if leg != "" && (leg == "client") != s.IsClient() {
+		event += ":mismatch"
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.events[leg] = append(h.events[leg], event)
+}
+
+func (h *recordingStatsHandler) TagConn(ctx context.Context, _ *stats.ConnTagInfo) context.Context {
+	return ctx
+}
+
+func (h *recordingStatsHandler) HandleConn(context.Context, stats.ConnStats) {}
+
+func (h *recordingStatsHandler) legEvents(leg string) []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return append([]string(nil), h.events[leg]...)
+}
+
+func TestStatsHandler(t *testing.T) {
+	handler := &recordingStatsHandler{events: map[string][]string{}}
+
+	h := newTestHarnessWithService(t, &metadataEchoService{}, one2oneDirector,
+		proxy.WithStreamedDetector(func(fullMethodName string) bool { return fullMethodName == "/talos.testproto.TestService/PingStream" }),
+		proxy.WithStatsHandler(handler),
+	)
+
+	stream, err := h.client.PingStream(testContext(t))
+	require.NoError(t, err)
+
+	require.NoError(t, stream.Send(&pb.PingRequest{Value: statsTagMdKey}))
+	require.NoError(t, stream.CloseSend())
+
+	resp, err := stream.Recv()
+	require.NoError(t, err)
+
+	// upstream leg context is tagged by the stats handler
+	assert.Equal(t, statsTagMdKey+"=/talos.testproto.TestService/PingStream", resp.Value)
+
+	_, err = stream.Recv()
+	require.True(t, errors.Is(err, io.EOF), "unexpected error %v", err)
+
+	for _, leg := range []struct {
+		name     string
+		header   string
+		payloads []string
+	}{
+		{
+			name:     "server",
+			header:   "*stats.InHeader",
+			payloads: []string{"*stats.InPayload", "*stats.OutPayload"},
+		},
+		{
+			name:     "client",
+			header:   "*stats.OutHeader",
+			payloads: []string{"*stats.OutPayload", "*stats.InPayload"},
+		},
+	} {
+		require.Eventually(t, func() bool {
+			events := handler.legEvents(leg.name)
+
+			return len(events) > 0 && events[len(events)-1] == "*stats.End"
+		}, 5*time.Second, 10*time.Millisecond, "leg %s: %v", leg.name, handler.legEvents(leg.name))
+
+		events := handler.legEvents(leg.name)
+		require.Len(t, events, 5)
+		assert.Equal(t, []string{"*stats.Begin", leg.header}, events[:2])
+		assert.ElementsMatch(t, leg.payloads, events[2:4])
+	}
+}
@@ -0,0 +1,148 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+)
+
+// StatsStore persists the bandwidth and routing statistics across the proxy restarts.
+type StatsStore interface {
+	// Load returns the stored snapshot, or nil if nothing was stored yet.
+	Load() (*BandwidthSnapshot, error)
+	// Save replaces the stored snapshot.
+	Save(snapshot BandwidthSnapshot) error
+}
+
+// FileStatsStore stores the statistics as JSON in the file.
+//
+// The file is replaced atomically on each save, so it is never left partially written.
+type FileStatsStore struct {
+	Path string
+}
+
+// NewFileStatsStore creates the store which keeps the statistics in the file at path.
+func NewFileStatsStore(path string) *FileStatsStore {
+	return &FileStatsStore{Path: path}
+}
+
+// Load implements StatsStore.
+func (s *FileStatsStore) Load() (*BandwidthSnapshot, error) {
+	data, err := os.ReadFile(s.Path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	var snapshot BandwidthSnapshot
+
+	if err = json.Unmarshal(data, &snapshot); err != nil {
+		return nil, err
+	}
+
+	return &snapshot, nil
+}
+
+// Save implements StatsStore.
+func (s *FileStatsStore) Save(snapshot BandwidthSnapshot) error {
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.Path), filepath.Base(s.Path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+
+	defer os.Remove(tmp.Name()) //nolint:errcheck
+
+	if _, err = tmp.Write(data); err != nil {
+		tmp.Close() //nolint:errcheck
+
+		return err
+	}
+
+	if err = tmp.Sync(); err != nil {
+		tmp.Close() //nolint:errcheck
+
+		return err
+	}
+
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), s.Path)
+}
+
+// Restore adds the counters of the snapshot (usually loaded from the StatsStore) to the statistics.
+//
+// Restored traffic is not included in the reports, which cover only the traffic since the previous report.
+func (b *BandwidthStats) Restore(snapshot BandwidthSnapshot) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for method, counters := range snapshot.Methods {
+		loadCounter(&b.methods, method).add(counters)
+
+		b.lastTotals[method] += counters.InBytes + counters.OutBytes
+	}
+
+	for backend, counters := range snapshot.Backends {
+		loadCounter(&b.backends, backend).add(counters)
+	}
+
+	for method, backends := range snapshot.Routes {
+		for backend, calls := range backends {
+			atomic.AddUint64(loadRoute(&b.routes, routeKey{method: method, backend: backend}), calls)
+		}
+	}
+}
+
+// Persist restores the statistics from the store, and then saves them to the store every interval until
+// the context is canceled.
+//
+// The statistics are saved once more when the context is canceled, the error of that save is returned.
+func (b *BandwidthStats) Persist(ctx context.Context, store StatsStore, interval time.Duration) error {
+	snapshot, err := store.Load()
+	if err != nil {
+		return err
+	}
+
+	if snapshot != nil {
+		b.Restore(*snapshot)
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return store.Save(b.Snapshot())
+		case <-ticker.C:
+			store.Save(b.Snapshot()) //nolint:errcheck // retried on the next tick
+		}
+	}
+}
+
+func (c *bandwidthCounter) add(counters BandwidthCounters) {
+	atomic.AddUint64(&c.inBytes, counters.InBytes)
+	atomic.AddUint64(&c.outBytes, counters.OutBytes)
+
+	for i := range counters.InFrames {
+		atomic.AddUint64(&c.inFrames[i], counters.InFrames[i])
+		atomic.AddUint64(&c.outFrames[i], counters.OutFrames[i])
+	}
+}
@@ -0,0 +1,68 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/noncepad/grpc-proxy/proxy"
+	pb "github.com/noncepad/grpc-proxy/testservice"
+)
+
+func TestBandwidthStatsPersist(t *testing.T) {
+	store := proxy.NewFileStatsStore(filepath.Join(t.TempDir(), "stats.json"))
+
+	snapshot, err := store.Load()
+	require.NoError(t, err)
+	assert.Nil(t, snapshot)
+
+	stats := proxy.NewBandwidthStats(1)
+
+	h := newTestHarness(t, one2oneDirector, proxy.WithBandwidthStats(stats))
+	defer h.stop()
+
+	for i := 0; i < 3; i++ {
+		_, err = h.client.Ping(testContext(t), &pb.PingRequest{Value: "foo"})
+		require.NoError(t, err)
+	}
+
+	before := stats.Snapshot()
+
+	require.Len(t, before.Routes["/talos.testproto.TestService/Ping"], 1)
+
+	for _, calls := range before.Routes["/talos.testproto.TestService/Ping"] {
+		assert.EqualValues(t, 3, calls)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	require.NoError(t, stats.Persist(ctx, store, time.Hour))
+
+	// the proxy restarts
+	restarted := proxy.NewBandwidthStats(1)
+
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+
+	require.NoError(t, restarted.Persist(ctx, store, time.Hour))
+
+	assert.Equal(t, before, restarted.Snapshot())
+
+	// the restored traffic is not reported as the recent one
+	assert.Empty(t, restarted.Report().Top)
+
+	restarted.Restore(before)
+
+	after := restarted.Snapshot()
+	ping := after.Methods["/talos.testproto.TestService/Ping"]
+	assert.Equal(t, 2*before.Methods["/talos.testproto.TestService/Ping"].InBytes, ping.InBytes)
+	assert.EqualValues(t, 6, ping.InFrames[0])
+}
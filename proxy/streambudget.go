@@ -0,0 +1,191 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// UpstreamStreamBudget caps the number of the concurrent upstream streams per backend connection and per backend
+// host, so that the proxy respects the MAX_CONCURRENT_STREAMS limits of the upstreams: the streams over the budget
+// are queued until a stream finishes, instead of failing once the upstream limit is exhausted.
+//
+// The connections are identified by the *grpc.ClientConn returned by the backend, the hosts by the target
+// of the connection. The budget is shared by all the handlers configured with it.
+type UpstreamStreamBudget struct {
+	perConn      int
+	perHost      int
+	queueTimeout time.Duration
+
+	mu    sync.Mutex
+	conns map[*grpc.ClientConn]*streamSlots
+	hosts map[string]*streamSlots
+}
+
+// streamSlots is the semaphore of the streams.
+type streamSlots struct {
+	slots chan struct{}
+	// users counts the streams holding or waiting for the slot, the semaphore is dropped once it is unused
+	users int
+}
+
+// NewUpstreamStreamBudget creates the budget with the maximum number of the concurrent streams per connection
+// and per host (zero means no limit).
+//
+// The streams wait for a free slot up to queueTimeout (zero means wait as long as the call context allows),
+// and fail with ErrUpstreamStreamBudget if no slot was freed up.
+func NewUpstreamStreamBudget(perConn, perHost int, queueTimeout time.Duration) *UpstreamStreamBudget {
+	return &UpstreamStreamBudget{
+		perConn:      perConn,
+		perHost:      perHost,
+		queueTimeout: queueTimeout,
+		conns:        map[*grpc.ClientConn]*streamSlots{},
+		hosts:        map[string]*streamSlots{},
+	}
+}
+
+// WithUpstreamStreamBudget enforces the budget of the upstream streams.
+func WithUpstreamStreamBudget(budget *UpstreamStreamBudget) Option {
+	return func(o *handlerOptions) {
+		o.upstreamStreamBudget = budget
+	}
+}
+
+// InUse returns the number of the streams holding or waiting for the slot of the connection.
+func (b *UpstreamStreamBudget) InUse(conn *grpc.ClientConn) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if slots, ok := b.conns[conn]; ok {
+		return slots.users
+	}
+
+	return 0
+}
+
+// acquire waits for the slots of the connection and its host, it returns a function to release the slots.
+func (b *UpstreamStreamBudget) acquire(ctx context.Context, conn *grpc.ClientConn, backend Backend) (func(), error) {
+	if b.queueTimeout > 0 {
+		var cancel context.CancelFunc
+
+		ctx, cancel = context.WithTimeout(ctx, b.queueTimeout)
+		defer cancel()
+	}
+
+	var releases []func()
+
+	release := func() {
+		for _, release := range releases {
+			release()
+		}
+	}
+
+	if b.perConn > 0 {
+		r, err := acquireSlot(ctx, b, b.conns, conn, b.perConn)
+		if err != nil {
+			return nil, b.error(err, backend)
+		}
+
+		releases = append(releases, r)
+	}
+
+	if b.perHost > 0 {
+		r, err := acquireSlot(ctx, b, b.hosts, conn.Target(), b.perHost)
+		if err != nil {
+			release()
+
+			return nil, b.error(err, backend)
+		}
+
+		releases = append(releases, r)
+	}
+
+	return release, nil
+}
+
+// error converts the error of waiting for the slot.
+func (b *UpstreamStreamBudget) error(err error, backend Backend) error {
+	if st := status.FromContextError(err); st.Code() == codes.Canceled {
+		return st.Err()
+	}
+
+	return &Error{
+		Code:    ErrUpstreamStreamBudget.Code,
+		Reason:  ErrUpstreamStreamBudget.Reason,
+		Message: "no upstream stream slot available for backend " + backend.String(),
+		Backend: backend.String(),
+	}
+}
+
+// acquireSlot waits for the slot of the key.
+func acquireSlot[K comparable](ctx context.Context, b *UpstreamStreamBudget, m map[K]*streamSlots, key K, limit int) (func(), error) {
+	b.mu.Lock()
+	slots, ok := m[key]
+
+	if !ok {
+		slots = &streamSlots{slots: make(chan struct{}, limit)}
+		m[key] = slots
+	}
+
+	slots.users++
+	b.mu.Unlock()
+
+	done := func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+
+		slots.users--
+
+		if slots.users == 0 {
+			delete(m, key)
+		}
+	}
+
+	select {
+	case slots.slots <- struct{}{}:
+		var once sync.Once
+
+		return func() {
+			once.Do(func() {
+				<-slots.slots
+				done()
+			})
+		}, nil
+	case <-ctx.Done():
+		done()
+
+		return nil, ctx.Err()
+	}
+}
+
+// budgetedClientStream releases the slots of the upstream stream once it is finished.
+type budgetedClientStream struct {
+	grpc.ClientStream
+
+	release func()
+}
+
+func newBudgetedClientStream(ctx context.Context, clientStream grpc.ClientStream, release func()) *budgetedClientStream {
+	go func() {
+		<-ctx.Done()
+		release()
+	}()
+
+	return &budgetedClientStream{ClientStream: clientStream, release: release}
+}
+
+func (s *budgetedClientStream) RecvMsg(m interface{}) error {
+	err := s.ClientStream.RecvMsg(m)
+	if err != nil {
+		s.release()
+	}
+
+	return err
+}
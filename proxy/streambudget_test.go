@@ -0,0 +1,64 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/noncepad/grpc-proxy/proxy"
+	pb "github.com/noncepad/grpc-proxy/testservice"
+)
+
+func TestUpstreamStreamBudget(t *testing.T) {
+	budget := proxy.NewUpstreamStreamBudget(1, 0, 500*time.Millisecond)
+
+	h := newTestHarness(t, one2oneDirector, proxy.WithUpstreamStreamBudget(budget))
+
+	ctx := testContext(t)
+
+	stream1, err := h.client.PingStream(ctx)
+	require.NoError(t, err)
+
+	require.NoError(t, stream1.Send(&pb.PingRequest{Value: "foo"}))
+
+	_, err = stream1.Recv()
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, budget.InUse(h.backendConn))
+
+	// the second stream waits for the slot, and gives up after the queue timeout
+	_, err = h.client.Ping(ctx, &pb.PingRequest{Value: "foo"})
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+
+	// the queued stream proceeds once the slot is released
+	errCh := make(chan error, 1)
+
+	go func() {
+		_, err := h.client.Ping(ctx, &pb.PingRequest{Value: "foo"})
+		errCh <- err
+	}()
+
+	require.Eventually(t, func() bool { return budget.InUse(h.backendConn) == 2 }, time.Second, time.Millisecond)
+
+	require.NoError(t, stream1.CloseSend())
+
+	for {
+		if _, err = stream1.Recv(); err != nil {
+			break
+		}
+	}
+
+	select {
+	case err = <-errCh:
+		require.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("queued call didn't finish")
+	}
+}
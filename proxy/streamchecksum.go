@@ -0,0 +1,247 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
+	"strings"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+)
+
+// StreamChecksumTrailer is the trailer carrying the rolling checksum of the messages of the call.
+//
+// The value is the name of the algorithm and the hex-encoded checksum separated by a colon, e.g. "crc32c:1a2b3c4d".
+// The checksum is computed over the sequence of the messages, each one prefixed by its length as 4-byte big-endian
+// integer (as in the gRPC message framing, but without the compression flag), so that the lost, truncated
+// and reordered messages are detected.
+const StreamChecksumTrailer = "proxy-stream-checksum"
+
+// StreamChecksumPolicy configures the rolling checksum of the messages of the call, see WithStreamChecksum.
+type StreamChecksumPolicy struct {
+	// Hash creates the hash of the checksum (e.g. xxhash.New), and Name is the name of its algorithm in the trailer.
+	// The default is CRC32C, named "crc32c".
+	Hash func() hash.Hash
+	Name string
+	// Downstream enables the checksum of the messages delivered to the client, sent in the StreamChecksumTrailer.
+	Downstream bool
+	// Upstream enables the verification of the messages received from the backends against
+	// the StreamChecksumTrailer of the backend (e.g. the upstream proxy with Downstream enabled).
+	//
+	// The messages are forwarded as they arrive, so the mismatch fails the call with ErrResponseVerification
+	// after the messages were forwarded. The backends which don't send the trailer are not verified.
+	Upstream bool
+}
+
+// WithStreamChecksum enables the rolling checksum of the messages of the listed methods, so that the clients detect
+// truncation or corruption of the streams across the proxy hop.
+//
+// If fullMethodNames is empty, the checksum is enabled for all methods. The checksum doesn't buffer the messages,
+// see WithResponseVerifier for the verification before the responses are forwarded.
+func WithStreamChecksum(policy StreamChecksumPolicy, fullMethodNames ...string) Option {
+	if policy.Hash == nil {
+		policy.Hash = newCRC32C
+		policy.Name = "crc32c"
+	}
+
+	return func(o *handlerOptions) {
+		if policy.Name == "" || strings.Contains(policy.Name, ":") {
+			o.invalid("invalid stream checksum algorithm name %q", policy.Name)
+		}
+
+		if o.streamChecksums == nil {
+			o.streamChecksums = map[string]*StreamChecksumPolicy{}
+		}
+
+		if len(fullMethodNames) == 0 {
+			o.streamChecksums[""] = &policy
+
+			return
+		}
+
+		for _, name := range fullMethodNames {
+			o.streamChecksums[name] = &policy
+		}
+	}
+}
+
+// StreamChecksum computes the StreamChecksumTrailer value of the messages with the default CRC32C algorithm.
+func StreamChecksum(messages ...[]byte) string {
+	c := newStreamChecksum(&StreamChecksumPolicy{Hash: newCRC32C, Name: "crc32c"})
+
+	for _, msg := range messages {
+		c.add(msg)
+	}
+
+	return c.value()
+}
+
+func newCRC32C() hash.Hash {
+	return crc32.New(crc32.MakeTable(crc32.Castagnoli))
+}
+
+// streamChecksumPolicy returns the policy of the method.
+func (o *handlerOptions) streamChecksumPolicy(fullMethodName string) *StreamChecksumPolicy {
+	if policy, ok := o.streamChecksums[fullMethodName]; ok {
+		return policy
+	}
+
+	return o.streamChecksums[""]
+}
+
+// streamChecksum is the rolling checksum of the messages.
+type streamChecksum struct {
+	policy *StreamChecksumPolicy
+	hash   hash.Hash
+}
+
+func newStreamChecksum(policy *StreamChecksumPolicy) *streamChecksum {
+	return &streamChecksum{
+		policy: policy,
+		hash:   policy.Hash(),
+	}
+}
+
+func (c *streamChecksum) add(payload []byte) {
+	var prefix [4]byte
+
+	binary.BigEndian.PutUint32(prefix[:], uint32(len(payload)))
+
+	c.hash.Write(prefix[:]) //nolint:errcheck
+	c.hash.Write(payload)   //nolint:errcheck
+}
+
+func (c *streamChecksum) value() string {
+	return c.policy.Name + ":" + hex.EncodeToString(c.hash.Sum(nil))
+}
+
+// wrapStreamChecksum wraps the server stream to compute the checksum of the messages delivered to the client,
+// finish sends the checksum in the trailer.
+func (o *handlerOptions) wrapStreamChecksum(serverStream grpc.ServerStream, fullMethodName string) (grpc.ServerStream, func()) {
+	policy := o.streamChecksumPolicy(fullMethodName)
+	if policy == nil || !policy.Downstream {
+		return serverStream, func() {}
+	}
+
+	wrapped := &checksumServerStream{
+		ServerStream: serverStream,
+		checksum:     newStreamChecksum(policy),
+	}
+
+	return wrapped, wrapped.finish
+}
+
+// checksumServerStream computes the checksum of the messages sent to the client.
+//
+// The checksum of the backend trailers is replaced with the checksum of the proxy.
+type checksumServerStream struct {
+	grpc.ServerStream
+
+	checksum *streamChecksum
+	mu       sync.Mutex
+}
+
+func (s *checksumServerStream) SendMsg(m interface{}) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	err := s.ServerStream.SendMsg(m)
+
+	if f, ok := m.(*Frame); ok && err == nil {
+		s.checksum.add(f.payload)
+	}
+
+	return err
+}
+
+func (s *checksumServerStream) SetTrailer(md metadata.MD) {
+	if _, ok := md[StreamChecksumTrailer]; ok {
+		md = md.Copy()
+		delete(md, StreamChecksumTrailer)
+	}
+
+	s.ServerStream.SetTrailer(md)
+}
+
+func (s *checksumServerStream) finish() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.ServerStream.SetTrailer(metadata.Pairs(StreamChecksumTrailer, s.checksum.value()))
+}
+
+// wrapUpstreamChecksum wraps the upstream stream to verify the checksum of the messages received from the backend.
+func (o *handlerOptions) wrapUpstreamChecksum(clientStream grpc.ClientStream, backend Backend, fullMethodName string) grpc.ClientStream {
+	policy := o.streamChecksumPolicy(fullMethodName)
+	if policy == nil || !policy.Upstream {
+		return clientStream
+	}
+
+	return &checksumClientStream{
+		ClientStream: clientStream,
+		checksum:     newStreamChecksum(policy),
+		backend:      backend,
+	}
+}
+
+// checksumClientStream verifies the checksum of the messages received from the backend once the backend
+// finishes the call.
+type checksumClientStream struct {
+	grpc.ClientStream
+
+	checksum *streamChecksum
+	backend  Backend
+}
+
+func (s *checksumClientStream) RecvMsg(m interface{}) error {
+	err := s.ClientStream.RecvMsg(m)
+
+	if f, ok := m.(*Frame); ok && err == nil {
+		s.checksum.add(f.payload)
+	}
+
+	if errors.Is(err, io.EOF) {
+		if verifyErr := s.verify(); verifyErr != nil {
+			return verifyErr
+		}
+	}
+
+	return err
+}
+
+// verify compares the checksum with the trailer of the backend.
+func (s *checksumClientStream) verify() error {
+	values := s.ClientStream.Trailer().Get(StreamChecksumTrailer)
+	if len(values) == 0 {
+		return nil
+	}
+
+	expected := values[len(values)-1]
+
+	name, _, _ := strings.Cut(expected, ":")
+	if name != s.checksum.policy.Name {
+		// the checksum of the other algorithm can't be verified
+		return nil
+	}
+
+	if actual := s.checksum.value(); actual != expected {
+		return &Error{
+			Code:    codes.DataLoss,
+			Reason:  ReasonResponseVerification,
+			Message: fmt.Sprintf("stream checksum mismatch for %s: expected %s, got %s", s.backend, expected, actual),
+			Backend: s.backend.String(),
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,89 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/noncepad/grpc-proxy/proxy"
+	pb "github.com/noncepad/grpc-proxy/testservice"
+)
+
+// checksumService sends the stream checksum trailer, corrupted if the request value is "corrupt".
+type checksumService struct {
+	lenientService
+}
+
+func (s *checksumService) Ping(ctx context.Context, ping *pb.PingRequest) (*pb.PingResponse, error) {
+	resp := &pb.PingResponse{Value: ping.Value}
+
+	payload, err := proto.Marshal(resp)
+	if err != nil {
+		return nil, err
+	}
+
+	checksum := proxy.StreamChecksum(payload)
+	if ping.Value == "corrupt" {
+		checksum = proxy.StreamChecksum()
+	}
+
+	grpc.SetTrailer(ctx, metadata.Pairs(proxy.StreamChecksumTrailer, checksum)) //nolint:errcheck
+
+	return resp, nil
+}
+
+func TestStreamChecksumDownstream(t *testing.T) {
+	h := newTestHarness(t, one2oneDirector, proxy.WithStreamChecksum(proxy.StreamChecksumPolicy{Downstream: true}))
+
+	stream, err := h.client.PingList(testContext(t), &pb.PingRequest{Value: "ping"})
+	require.NoError(t, err)
+
+	var messages [][]byte
+
+	for {
+		resp, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		require.NoError(t, err)
+
+		payload, err := proto.Marshal(resp)
+		require.NoError(t, err)
+
+		messages = append(messages, payload)
+	}
+
+	assert.Len(t, messages, countListResponses)
+	assert.Equal(t, []string{proxy.StreamChecksum(messages...)}, stream.Trailer().Get(proxy.StreamChecksumTrailer))
+}
+
+func TestStreamChecksumUpstream(t *testing.T) {
+	h := newTestHarnessWithService(t, &checksumService{}, one2oneDirector,
+		proxy.WithStreamChecksum(proxy.StreamChecksumPolicy{Upstream: true}, "/talos.testproto.TestService/Ping"))
+
+	var trailer metadata.MD
+
+	_, err := h.client.Ping(testContext(t), &pb.PingRequest{Value: "ping"}, grpc.Trailer(&trailer))
+	require.NoError(t, err)
+	assert.Len(t, trailer.Get(proxy.StreamChecksumTrailer), 1)
+
+	_, err = h.client.Ping(testContext(t), &pb.PingRequest{Value: "corrupt"})
+	assert.Equal(t, codes.DataLoss, status.Code(err))
+
+	proxyErr, ok := proxy.FromError(err)
+	require.True(t, ok)
+	assert.Equal(t, proxy.ReasonResponseVerification, proxyErr.Reason)
+}
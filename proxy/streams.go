@@ -0,0 +1,172 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrStreamNotFound is returned by StreamRegistry.CancelBackend when the stream or its backend is not found.
+var ErrStreamNotFound = errors.New("stream not found")
+
+// StreamRegistry tracks the active proxied streams and allows to cancel individual backend legs.
+//
+// Handlers track their streams in the registry with WithStreamRegistry option. StreamRegistry implements http.Handler,
+// so that it can be mounted on the admin HTTP server: GET lists the active streams, DELETE with the query parameters
+// "stream" (stream ID) and "backend" (backend name) cancels the backend leg.
+//
+// The streams are registered and unregistered on each call, so the registry doesn't take any shared lock.
+type StreamRegistry struct {
+	// streams maps the stream ID to *activeStream
+	streams sync.Map
+	nextID  atomic.Uint64
+}
+
+// ActiveStream describes the active proxied stream.
+type ActiveStream struct {
+	Method    string    `json:"method"`
+	StartedAt time.Time `json:"started_at"`
+	Backends  []string  `json:"backends"`
+	ID        uint64    `json:"id"`
+}
+
+type activeStream struct {
+	info ActiveStream
+	// cancels are not modified once the stream is registered
+	cancels []context.CancelFunc
+}
+
+// NewStreamRegistry creates an empty StreamRegistry.
+func NewStreamRegistry() *StreamRegistry {
+	return &StreamRegistry{}
+}
+
+// WithStreamRegistry tracks the streams of the handler in the registry.
+func WithStreamRegistry(registry *StreamRegistry) Option {
+	return func(o *handlerOptions) {
+		o.streamRegistry = registry
+	}
+}
+
+// Streams returns the active streams ordered by ID.
+func (r *StreamRegistry) Streams() []ActiveStream {
+	var streams []ActiveStream
+
+	r.streams.Range(func(_, value interface{}) bool {
+		info := value.(*activeStream).info //nolint:forcetypeassert
+		info.Backends = append([]string(nil), info.Backends...)
+
+		streams = append(streams, info)
+
+		return true
+	})
+
+	if streams == nil {
+		streams = []ActiveStream{}
+	}
+
+	sort.Slice(streams, func(i, j int) bool { return streams[i].ID < streams[j].ID })
+
+	return streams
+}
+
+// CancelBackend cancels the upstream stream to the backend without terminating the client stream.
+//
+// In one2many mode the canceled backend is reported to the client as failed (see Backend.BuildError), other backends
+// are not affected. In one2one mode canceling the only backend fails the call.
+func (r *StreamRegistry) CancelBackend(id uint64, backend string) error {
+	value, ok := r.streams.Load(id)
+	if !ok {
+		return fmt.Errorf("%w: %d", ErrStreamNotFound, id)
+	}
+
+	stream := value.(*activeStream) //nolint:forcetypeassert
+
+	for i, name := range stream.info.Backends {
+		if name == backend {
+			stream.cancels[i]()
+
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w: backend %q of stream %d", ErrStreamNotFound, backend, id)
+}
+
+// ServeHTTP implements http.Handler.
+func (r *StreamRegistry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	switch req.Method {
+	case http.MethodGet:
+		w.Header().Set("Content-Type", "application/json")
+
+		json.NewEncoder(w).Encode(r.Streams()) //nolint:errcheck
+	case http.MethodDelete:
+		id, err := strconv.ParseUint(req.URL.Query().Get("stream"), 10, 64)
+		if err != nil {
+			http.Error(w, "invalid stream ID", http.StatusBadRequest)
+
+			return
+		}
+
+		if err = r.CancelBackend(id, req.URL.Query().Get("backend")); err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.Header().Set("Allow", "GET, DELETE")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// legContexts creates cancelable contexts for the backend legs of the stream, it is nil-safe.
+//
+// The returned function unregisters the stream.
+func (r *StreamRegistry) legContexts(ctx context.Context, fullMethodName string, backends []Backend) ([]context.Context, func()) {
+	contexts := make([]context.Context, len(backends))
+
+	if r == nil {
+		for i := range contexts {
+			contexts[i] = ctx
+		}
+
+		return contexts, func() {}
+	}
+
+	stream := &activeStream{
+		info: ActiveStream{
+			Method:    fullMethodName,
+			StartedAt: time.Now(),
+			Backends:  make([]string, len(backends)),
+		},
+		cancels: make([]context.CancelFunc, len(backends)),
+	}
+
+	for i := range backends {
+		contexts[i], stream.cancels[i] = context.WithCancel(ctx)
+		stream.info.Backends[i] = backends[i].String()
+	}
+
+	stream.info.ID = r.nextID.Add(1)
+	r.streams.Store(stream.info.ID, stream)
+
+	return contexts, func() {
+		r.streams.Delete(stream.info.ID)
+
+		for _, cancel := range stream.cancels {
+			cancel()
+		}
+	}
+}
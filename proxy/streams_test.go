@@ -0,0 +1,164 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/noncepad/grpc-proxy/proxy"
+	pb "github.com/noncepad/grpc-proxy/testservice"
+)
+
+// reportingBackend reports upstream errors as PingResponse messages.
+type reportingBackend struct {
+	taggedBackend
+}
+
+func (b *reportingBackend) BuildError(streaming bool, err error) ([]byte, error) {
+	return proto.Marshal(&pb.PingResponse{Value: b.tag + ":" + status.Code(err).String()})
+}
+
+// cancelObservingService reports the cancellation of the upstream PingStream contexts.
+type cancelObservingService struct {
+	metadataEchoService
+
+	canceled chan string
+}
+
+func (s *cancelObservingService) PingStream(stream pb.TestService_PingStreamServer) error {
+	err := s.metadataEchoService.PingStream(stream)
+
+	if stream.Context().Err() != nil {
+		md, _ := metadata.FromIncomingContext(stream.Context())
+
+		s.canceled <- md.Get(backendTagMdKey)[0]
+	}
+
+	return err
+}
+
+func one2manyReportingDirector(backend proxy.Backend) proxy.StreamDirector {
+	return func(ctx context.Context, fullMethodName string) (proxy.Mode, []proxy.Backend, error) {
+		return proxy.One2Many, []proxy.Backend{
+			&reportingBackend{taggedBackend{Backend: backend, tag: "a"}},
+			&reportingBackend{taggedBackend{Backend: backend, tag: "b"}},
+		}, nil
+	}
+}
+
+func recvValues(t *testing.T, stream pb.TestService_PingStreamClient, n int) []string {
+	t.Helper()
+
+	values := make([]string, 0, n)
+
+	for i := 0; i < n; i++ {
+		resp, err := stream.Recv()
+		require.NoError(t, err)
+
+		values = append(values, resp.Value)
+	}
+
+	return values
+}
+
+func TestCancelPropagation(t *testing.T) {
+	service := &cancelObservingService{canceled: make(chan string, 2)}
+	registry := proxy.NewStreamRegistry()
+
+	h := newTestHarnessWithService(t, service, one2manyReportingDirector,
+		proxy.WithStreamedDetector(func(fullMethodName string) bool { return fullMethodName == "/talos.testproto.TestService/PingStream" }),
+		proxy.WithStreamRegistry(registry),
+	)
+
+	ctx, cancel := context.WithCancel(testContext(t))
+
+	stream, err := h.client.PingStream(ctx)
+	require.NoError(t, err)
+
+	require.NoError(t, stream.Send(&pb.PingRequest{Value: backendTagMdKey}))
+	assert.ElementsMatch(t, []string{backendTagMdKey + "=a", backendTagMdKey + "=b"}, recvValues(t, stream, 2))
+
+	cancel()
+
+	var canceled []string
+
+	for i := 0; i < 2; i++ {
+		select {
+		case tag := <-service.canceled:
+			canceled = append(canceled, tag)
+		case <-time.After(5 * time.Second):
+			require.FailNow(t, "upstream stream was not canceled")
+		}
+	}
+
+	assert.ElementsMatch(t, []string{"a", "b"}, canceled)
+
+	assert.Eventually(t, func() bool { return len(registry.Streams()) == 0 }, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestStreamRegistryCancelBackend(t *testing.T) {
+	service := &cancelObservingService{canceled: make(chan string, 2)}
+	registry := proxy.NewStreamRegistry()
+
+	h := newTestHarnessWithService(t, service, one2manyReportingDirector,
+		proxy.WithStreamedDetector(func(fullMethodName string) bool { return fullMethodName == "/talos.testproto.TestService/PingStream" }),
+		proxy.WithStreamRegistry(registry),
+	)
+
+	stream, err := h.client.PingStream(testContext(t))
+	require.NoError(t, err)
+
+	require.NoError(t, stream.Send(&pb.PingRequest{Value: backendTagMdKey}))
+	assert.ElementsMatch(t, []string{backendTagMdKey + "=a", backendTagMdKey + "=b"}, recvValues(t, stream, 2))
+
+	streams := registry.Streams()
+	require.Len(t, streams, 1)
+	assert.Equal(t, "/talos.testproto.TestService/PingStream", streams[0].Method)
+	assert.Equal(t, []string{"a", "b"}, streams[0].Backends)
+
+	assert.ErrorIs(t, registry.CancelBackend(streams[0].ID+1, "b"), proxy.ErrStreamNotFound)
+	assert.ErrorIs(t, registry.CancelBackend(streams[0].ID, "c"), proxy.ErrStreamNotFound)
+
+	// cancel via the admin endpoint
+	w := httptest.NewRecorder()
+	registry.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/?backend=b&stream="+strconv.FormatUint(streams[0].ID, 10), nil))
+	assert.Equal(t, http.StatusNoContent, w.Code)
+
+	// the canceled leg is reported as an error response
+	assert.Equal(t, []string{"b:" + codes.Canceled.String()}, recvValues(t, stream, 1))
+
+	select {
+	case tag := <-service.canceled:
+		assert.Equal(t, "b", tag)
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "upstream stream was not canceled")
+	}
+
+	// the client stream goes on with the remaining backend
+	for i := 0; i < 3; i++ {
+		require.NoError(t, stream.Send(&pb.PingRequest{Value: backendTagMdKey}))
+		assert.Equal(t, []string{backendTagMdKey + "=a"}, recvValues(t, stream, 1))
+	}
+
+	require.NoError(t, stream.CloseSend())
+
+	_, err = stream.Recv()
+	assert.True(t, errors.Is(err, io.EOF), "unexpected error %v", err)
+
+	assert.Eventually(t, func() bool { return len(registry.Streams()) == 0 }, 5*time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,72 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy
+
+import (
+	"context"
+
+	"google.golang.org/grpc/metadata"
+)
+
+// DefaultTargetsMetadataKey is the default metadata key of the backend subset requested by the client.
+const DefaultTargetsMetadataKey = "targets"
+
+// TargetsDirector wraps the director to let the clients select the subset of the backends.
+//
+// The client lists the names of the backends (as returned by Backend.String) in the metadata key, one value per
+// backend, and the backends returned by the director are filtered to the listed ones in the order of the metadata
+// values. Calls without the metadata key are proxied to all the backends returned by the director. Unknown or
+// duplicate targets fail the call with ErrInvalidTarget. If key is empty, DefaultTargetsMetadataKey is used.
+func TargetsDirector(director StreamDirector, key string) StreamDirector {
+	return targetsDirector(director, key, func(target string) string { return target })
+}
+
+// targetsDirector filters the backends by the targets, canonical maps the targets to the backend names.
+func targetsDirector(director StreamDirector, key string, canonical func(target string) string) StreamDirector {
+	if key == "" {
+		key = DefaultTargetsMetadataKey
+	}
+
+	return func(ctx context.Context, fullMethodName string) (Mode, []Backend, error) {
+		mode, backends, err := director(ctx, fullMethodName)
+		if err != nil {
+			return mode, backends, err
+		}
+
+		md, _ := metadata.FromIncomingContext(ctx)
+
+		targets, ok := md[key]
+		if !ok {
+			return mode, backends, nil
+		}
+
+		byName := make(map[string]Backend, len(backends))
+
+		for _, backend := range backends {
+			byName[backend.String()] = backend
+		}
+
+		selected := make([]Backend, 0, len(targets))
+		seen := make(map[string]struct{}, len(targets))
+
+		for _, target := range targets {
+			target = canonical(target)
+
+			backend, ok := byName[target]
+			if !ok {
+				return mode, nil, newError(ErrInvalidTarget, "unknown target %q for %s", target, fullMethodName)
+			}
+
+			if _, ok = seen[target]; ok {
+				return mode, nil, newError(ErrInvalidTarget, "duplicate target %q for %s", target, fullMethodName)
+			}
+
+			seen[target] = struct{}{}
+
+			selected = append(selected, backend)
+		}
+
+		return mode, selected, nil
+	}
+}
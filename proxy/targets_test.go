@@ -0,0 +1,71 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/noncepad/grpc-proxy/proxy"
+	pb "github.com/noncepad/grpc-proxy/testservice"
+)
+
+func TestTargetsDirector(t *testing.T) {
+	h := newTestHarnessWithService(t, &tagEchoService{}, func(backend proxy.Backend) proxy.StreamDirector {
+		return proxy.TargetsDirector(func(ctx context.Context, fullMethodName string) (proxy.Mode, []proxy.Backend, error) {
+			return proxy.One2One, []proxy.Backend{
+				&taggedBackend{Backend: backend, tag: "a"},
+				&taggedBackend{Backend: backend, tag: "b"},
+				&taggedBackend{Backend: backend, tag: "c"},
+			}, nil
+		}, "")
+	})
+
+	for _, target := range []string{"a", "c"} {
+		ctx := metadata.AppendToOutgoingContext(testContext(t), proxy.DefaultTargetsMetadataKey, target)
+
+		resp, err := h.client.Ping(ctx, &pb.PingRequest{})
+		require.NoError(t, err)
+		assert.Equal(t, target, resp.Value)
+	}
+
+	for _, targets := range [][]string{{"d"}, {"a", "a"}} {
+		ctx := testContext(t)
+
+		for _, target := range targets {
+			ctx = metadata.AppendToOutgoingContext(ctx, proxy.DefaultTargetsMetadataKey, target)
+		}
+
+		_, err := h.client.Ping(ctx, &pb.PingRequest{})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+		proxyErr, ok := proxy.FromError(err)
+		require.True(t, ok)
+		assert.ErrorIs(t, proxyErr, proxy.ErrInvalidTarget)
+	}
+}
+
+func TestTargetsDirectorNoTargets(t *testing.T) {
+	backends := []proxy.Backend{&taggedBackend{tag: "a"}, &taggedBackend{tag: "b"}, &taggedBackend{tag: "c"}}
+
+	director := proxy.TargetsDirector(func(ctx context.Context, fullMethodName string) (proxy.Mode, []proxy.Backend, error) {
+		return proxy.One2Many, backends, nil
+	}, "x-targets")
+
+	_, selected, err := director(context.Background(), "/service/Method")
+	require.NoError(t, err)
+	assert.Equal(t, backends, selected)
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-targets", "c", "x-targets", "a"))
+
+	_, selected, err = director(ctx, "/service/Method")
+	require.NoError(t, err)
+	assert.Equal(t, []proxy.Backend{backends[2], backends[0]}, selected)
+}
@@ -0,0 +1,132 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"sync/atomic"
+
+	"google.golang.org/grpc/credentials"
+)
+
+// defaultTLSSessionCacheSize is the default capacity of the TLS session cache of the ConnPool.
+const defaultTLSSessionCacheSize = 256
+
+// ConnPoolStats are the counters of the ConnPool connections, so that the cost of the connection churn
+// (new connections and full TLS handshakes) can be monitored.
+type ConnPoolStats struct {
+	// Gets is the number of the connections requested from the pool.
+	Gets uint64 `json:"gets"`
+	// Dials is the number of the new connections dialed by the pool.
+	Dials uint64 `json:"dials"`
+	// TLSHandshakes is the number of the TLS handshakes completed, TLSResumed of them resumed the session.
+	TLSHandshakes uint64 `json:"tlsHandshakes"`
+	TLSResumed    uint64 `json:"tlsResumed"`
+	// TLSFailed is the number of the failed TLS handshakes.
+	TLSFailed uint64 `json:"tlsFailed"`
+}
+
+// ConnectionReuseRate returns the fraction of the requested connections served from the pool.
+func (s ConnPoolStats) ConnectionReuseRate() float64 {
+	if s.Gets == 0 || s.Dials > s.Gets {
+		return 0
+	}
+
+	return float64(s.Gets-s.Dials) / float64(s.Gets)
+}
+
+// TLSResumptionRate returns the fraction of the TLS handshakes which resumed the session.
+func (s ConnPoolStats) TLSResumptionRate() float64 {
+	if s.TLSHandshakes == 0 {
+		return 0
+	}
+
+	return float64(s.TLSResumed) / float64(s.TLSHandshakes)
+}
+
+// connPoolCounters are the live counters of ConnPoolStats.
+type connPoolCounters struct {
+	gets, dials                 atomic.Uint64
+	handshakes, resumed, failed atomic.Uint64
+}
+
+// Stats returns the counters of the pool connections.
+func (p *ConnPool) Stats() ConnPoolStats {
+	return ConnPoolStats{
+		Gets:          p.counters.gets.Load(),
+		Dials:         p.counters.dials.Load(),
+		TLSHandshakes: p.counters.handshakes.Load(),
+		TLSResumed:    p.counters.resumed.Load(),
+		TLSFailed:     p.counters.failed.Load(),
+	}
+}
+
+// transportCredentials returns the transport credentials of the connections, nil if not configured.
+//
+// The credentials built from TLSConfig are created once, so that the connections share the session cache.
+func (p *ConnPool) transportCredentials() credentials.TransportCredentials {
+	creds := p.Credentials
+
+	if creds == nil && p.TLSConfig != nil {
+		if p.tlsCreds == nil {
+			config := p.TLSConfig.Clone()
+
+			if config.ClientSessionCache == nil && p.TLSSessionCacheSize >= 0 {
+				size := p.TLSSessionCacheSize
+				if size == 0 {
+					size = defaultTLSSessionCacheSize
+				}
+
+				config.ClientSessionCache = tls.NewLRUClientSessionCache(size)
+			}
+
+			p.tlsCreds = credentials.NewTLS(config)
+		}
+
+		creds = p.tlsCreds
+	}
+
+	if creds == nil {
+		return nil
+	}
+
+	return &countingCredentials{TransportCredentials: creds, counters: &p.counters}
+}
+
+// countingCredentials counts the TLS handshakes and the resumed sessions.
+type countingCredentials struct {
+	credentials.TransportCredentials
+
+	counters *connPoolCounters
+}
+
+func (c *countingCredentials) ClientHandshake(ctx context.Context, authority string, rawConn net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	conn, info, err := c.TransportCredentials.ClientHandshake(ctx, authority, rawConn)
+	if err != nil {
+		c.counters.failed.Add(1)
+
+		return conn, info, err
+	}
+
+	c.counters.handshakes.Add(1)
+
+	if tlsResumed(info) {
+		c.counters.resumed.Add(1)
+	}
+
+	return conn, info, nil
+}
+
+func (c *countingCredentials) Clone() credentials.TransportCredentials {
+	return &countingCredentials{TransportCredentials: c.TransportCredentials.Clone(), counters: c.counters}
+}
+
+// tlsResumed returns true if the TLS handshake resumed the session.
+func tlsResumed(info credentials.AuthInfo) bool {
+	tlsInfo, ok := info.(credentials.TLSInfo)
+
+	return ok && tlsInfo.State.DidResume
+}
@@ -0,0 +1,68 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy_test
+
+import (
+	"context"
+	"crypto/tls"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/noncepad/grpc-proxy/proxy"
+	pb "github.com/noncepad/grpc-proxy/testservice"
+)
+
+func TestConnPoolTLSSessionResumption(t *testing.T) {
+	for _, tt := range []struct {
+		name      string
+		cacheSize int
+		resumed   uint64
+	}{
+		{name: "cache", resumed: 1},
+		{name: "no cache", cacheSize: -1},
+	} {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			addr, roots := startTLSUpstream(t)
+
+			pool := proxy.NewConnPool()
+			pool.TLSConfig = &tls.Config{RootCAs: roots, MinVersion: tls.VersionTLS12}
+			pool.TLSSessionCacheSize = tt.cacheSize
+
+			t.Cleanup(func() { pool.Close() }) //nolint: errcheck
+
+			h := newTestHarness(t, func(proxy.Backend) proxy.StreamDirector {
+				return func(ctx context.Context, fullMethodName string) (proxy.Mode, []proxy.Backend, error) {
+					return proxy.One2One, []proxy.Backend{&proxy.DialBackend{Pool: pool, Target: addr}}, nil
+				}
+			})
+
+			ping := func() {
+				out, err := h.client.Ping(testContext(t), &pb.PingRequest{Value: "foo"})
+				require.NoError(t, err)
+				assert.Equal(t, "foo", out.Value)
+			}
+
+			// the second call reuses the pooled connection
+			ping()
+			ping()
+
+			// the connection is re-established after the pool is closed
+			require.NoError(t, pool.Close())
+			ping()
+
+			stats := pool.Stats()
+			assert.Equal(t, uint64(3), stats.Gets)
+			assert.Equal(t, uint64(2), stats.Dials)
+			assert.Equal(t, uint64(2), stats.TLSHandshakes)
+			assert.Equal(t, tt.resumed, stats.TLSResumed)
+			assert.Zero(t, stats.TLSFailed)
+			assert.InDelta(t, 1.0/3, stats.ConnectionReuseRate(), 1e-9)
+			assert.InDelta(t, float64(tt.resumed)/2, stats.TLSResumptionRate(), 1e-9)
+		})
+	}
+}
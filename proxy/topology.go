@@ -0,0 +1,200 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"runtime"
+	"sort"
+	"sync"
+)
+
+// Topology tracks the proxy handlers and backend groups for the read-only topology snapshot.
+//
+// Handlers are registered with the Topology via WithTopology option, backend groups with AddBackendGroup.
+// Topology implements http.Handler which serves the snapshot as JSON, so that it can be mounted on the admin HTTP server.
+type Topology struct {
+	groups   map[string]*BackendGroup
+	handlers []TopologyHandler
+
+	mu sync.Mutex
+}
+
+// TopologySnapshot is a serializable snapshot of the proxy topology.
+type TopologySnapshot struct {
+	Handlers      []TopologyHandler      `json:"handlers"`
+	BackendGroups []TopologyBackendGroup `json:"backend_groups"`
+}
+
+// TopologyHandler describes the registered proxy handler.
+type TopologyHandler struct {
+	// Service name, empty for the transparent handler.
+	Service         string   `json:"service,omitempty"`
+	Methods         []string `json:"methods,omitempty"`
+	StreamedMethods []string `json:"streamed_methods,omitempty"`
+	// Director is the name of the director function.
+	Director    string `json:"director"`
+	Transparent bool   `json:"transparent"`
+}
+
+// TopologyBackendGroup describes the backend group.
+type TopologyBackendGroup struct {
+	Name     string        `json:"name"`
+	Backends []BackendInfo `json:"backends"`
+	Version  uint64        `json:"version"`
+}
+
+// BackendInfo describes the state of the backend.
+type BackendInfo struct {
+	Name string `json:"name"`
+	// Healthy is nil if the health is unknown.
+	Healthy *bool `json:"healthy,omitempty"`
+	// ConnectionState is the state of the connection to the backend (e.g. "READY"), if known.
+	ConnectionState string `json:"connection_state,omitempty"`
+}
+
+// BackendInspector is an optional interface implemented by the backends to report their state in the topology.
+type BackendInspector interface {
+	Inspect() BackendInfo
+}
+
+// NewTopology creates an empty Topology.
+func NewTopology() *Topology {
+	return &Topology{
+		groups: map[string]*BackendGroup{},
+	}
+}
+
+// WithTopology registers the handler with the topology.
+func WithTopology(topology *Topology) Option {
+	return func(o *handlerOptions) {
+		o.topology = topology
+	}
+}
+
+// AddBackendGroup adds the named backend group to the topology.
+func (t *Topology) AddBackendGroup(name string, group *BackendGroup) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.groups[name] = group
+}
+
+// RemoveBackendGroup removes the named backend group from the topology.
+func (t *Topology) RemoveBackendGroup(name string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	delete(t.groups, name)
+}
+
+// register records the handler, it is nil-safe.
+func (t *Topology) register(s *handler, transparent bool) {
+	if t == nil {
+		return
+	}
+
+	entry := TopologyHandler{
+		Service:     s.options.serviceName,
+		Methods:     append([]string(nil), s.options.methodNames...),
+		Director:    functionName(s.director),
+		Transparent: transparent,
+	}
+
+	for name := range s.options.streamedMethods {
+		entry.StreamedMethods = append(entry.StreamedMethods, name)
+	}
+
+	sort.Strings(entry.StreamedMethods)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.handlers = append(t.handlers, entry)
+}
+
+// unregister removes the record of the dynamically registered service handler, it is nil-safe.
+func (t *Topology) unregister(s *handler) {
+	if t == nil {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for i, entry := range t.handlers {
+		if !entry.Transparent && entry.Service == s.options.serviceName {
+			t.handlers = append(t.handlers[:i], t.handlers[i+1:]...)
+
+			return
+		}
+	}
+}
+
+// Snapshot returns the current topology.
+func (t *Topology) Snapshot() TopologySnapshot {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	snapshot := TopologySnapshot{
+		Handlers:      append([]TopologyHandler(nil), t.handlers...),
+		BackendGroups: make([]TopologyBackendGroup, 0, len(t.groups)),
+	}
+
+	for name, group := range t.groups {
+		groupSnapshot := group.Snapshot()
+
+		entry := TopologyBackendGroup{
+			Name:     name,
+			Version:  groupSnapshot.Version,
+			Backends: make([]BackendInfo, 0, len(groupSnapshot.Backends)),
+		}
+
+		for _, backend := range groupSnapshot.Backends {
+			entry.Backends = append(entry.Backends, inspectBackend(backend))
+		}
+
+		snapshot.BackendGroups = append(snapshot.BackendGroups, entry)
+	}
+
+	sort.Slice(snapshot.BackendGroups, func(i, j int) bool { return snapshot.BackendGroups[i].Name < snapshot.BackendGroups[j].Name })
+
+	return snapshot
+}
+
+// ServeHTTP implements http.Handler.
+func (t *Topology) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	json.NewEncoder(w).Encode(t.Snapshot()) //nolint:errcheck
+}
+
+func inspectBackend(backend Backend) BackendInfo {
+	var info BackendInfo
+
+	if inspector, ok := backend.(BackendInspector); ok {
+		info = inspector.Inspect()
+	}
+
+	if info.Name == "" {
+		info.Name = backend.String()
+	}
+
+	return info
+}
+
+func functionName(f interface{}) string {
+	v := reflect.ValueOf(f)
+	if v.Kind() != reflect.Func || v.IsNil() {
+		return ""
+	}
+
+	if fn := runtime.FuncForPC(v.Pointer()); fn != nil {
+		return fn.Name()
+	}
+
+	return ""
+}
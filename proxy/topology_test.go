@@ -0,0 +1,74 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	"github.com/noncepad/grpc-proxy/proxy"
+)
+
+// inspectedBackend reports its health in the topology.
+type inspectedBackend struct {
+	taggedBackend
+
+	healthy bool
+}
+
+func (b *inspectedBackend) Inspect() proxy.BackendInfo {
+	return proxy.BackendInfo{Healthy: &b.healthy, ConnectionState: "READY"}
+}
+
+func TestTopology(t *testing.T) {
+	topology := proxy.NewTopology()
+
+	group := proxy.NewBackendGroup(&taggedBackend{tag: "a"}, &inspectedBackend{taggedBackend: taggedBackend{tag: "b"}, healthy: true})
+	topology.AddBackendGroup("workers", group)
+
+	server := grpc.NewServer()
+
+	_, err := proxy.NewTransparentHandler(group.Director(proxy.One2Many), proxy.WithTopology(topology))
+	require.NoError(t, err)
+
+	require.NoError(t, proxy.RegisterService(server, group.Director(proxy.One2One), "talos.testproto.TestService",
+		proxy.WithMethodNames("Ping", "PingStream"),
+		proxy.WithStreamedMethodNames("PingStream"),
+		proxy.WithTopology(topology)))
+
+	snapshot := topology.Snapshot()
+
+	require.Len(t, snapshot.Handlers, 2)
+	assert.True(t, snapshot.Handlers[0].Transparent)
+	assert.Contains(t, snapshot.Handlers[0].Director, "BackendGroup")
+	assert.Equal(t, "talos.testproto.TestService", snapshot.Handlers[1].Service)
+	assert.Equal(t, []string{"Ping", "PingStream"}, snapshot.Handlers[1].Methods)
+	assert.Equal(t, []string{"/talos.testproto.TestService/PingStream"}, snapshot.Handlers[1].StreamedMethods)
+
+	require.Len(t, snapshot.BackendGroups, 1)
+	assert.Equal(t, "workers", snapshot.BackendGroups[0].Name)
+	require.Len(t, snapshot.BackendGroups[0].Backends, 2)
+	assert.Equal(t, proxy.BackendInfo{Name: "a"}, snapshot.BackendGroups[0].Backends[0])
+	assert.Equal(t, "b", snapshot.BackendGroups[0].Backends[1].Name)
+	assert.True(t, *snapshot.BackendGroups[0].Backends[1].Healthy)
+	assert.Equal(t, "READY", snapshot.BackendGroups[0].Backends[1].ConnectionState)
+
+	group.Remove("a")
+
+	rec := httptest.NewRecorder()
+	topology.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/topology", nil))
+
+	var served proxy.TopologySnapshot
+
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &served))
+	require.Len(t, served.BackendGroups, 1)
+	assert.Len(t, served.BackendGroups[0].Backends, 1)
+	assert.EqualValues(t, 1, served.BackendGroups[0].Version)
+}
@@ -0,0 +1,187 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"hash"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+)
+
+// ResponseVerifier verifies response messages received from the backend against the trailer.
+//
+// Verifier receives all the serialized response messages of the backend (before AppendInfo is applied) and the trailer.
+type ResponseVerifier func(backend Backend, fullMethodName string, responses [][]byte, trailer metadata.MD) error
+
+// WithResponseVerifier enables verification of the backend responses.
+//
+// When enabled, responses from the backend are buffered until the backend finishes the call successfully,
+// and verified before being forwarded to the client. If verification fails, backend contribution is replaced
+// with codes.DataLoss error (for one2many proxying, the error is passed to Backend.BuildError).
+//
+// The buffered responses are limited to 64 MiB per backend (see WithResponseBufferLimit), the backend contribution
+// is replaced with ErrMessageTooLarge once the limit is exceeded.
+//
+// If fullMethodNames is empty, verification is enabled for all methods.
+func WithResponseVerifier(verifier ResponseVerifier, fullMethodNames ...string) Option {
+	return func(o *handlerOptions) {
+		o.responseVerifier = verifier
+		o.responseVerifierMethods = nil
+
+		if len(fullMethodNames) > 0 {
+			o.responseVerifierMethods = map[string]struct{}{}
+
+			for _, name := range fullMethodNames {
+				o.responseVerifierMethods[name] = struct{}{}
+			}
+		}
+	}
+}
+
+// defaultResponseBufferLimit is the default limit of the responses buffered for the verification.
+const defaultResponseBufferLimit = 64 << 20
+
+// WithResponseBufferLimit limits the size of the responses of the backend buffered for the verification
+// (see WithResponseVerifier), default is 64 MiB.
+func WithResponseBufferLimit(maxBytes int) Option {
+	return func(o *handlerOptions) {
+		if maxBytes <= 0 {
+			o.invalid("WithResponseBufferLimit has non-positive limit %d", maxBytes)
+
+			return
+		}
+
+		o.responseBufferLimit = maxBytes
+	}
+}
+
+// ChecksumTrailerKey is the trailer key used by ChecksumVerifier.
+const ChecksumTrailerKey = "proxy-response-sha256"
+
+// ChecksumVerifier returns a ResponseVerifier which verifies that the trailer key contains SHA256 checksum of the responses.
+//
+// If trailerKey is empty, ChecksumTrailerKey is used. The checksum is calculated by the backend with ResponseChecksum.
+func ChecksumVerifier(trailerKey string) ResponseVerifier {
+	if trailerKey == "" {
+		trailerKey = ChecksumTrailerKey
+	}
+
+	return func(backend Backend, fullMethodName string, responses [][]byte, trailer metadata.MD) error {
+		values := trailer.Get(trailerKey)
+		if len(values) != 1 {
+			return fmt.Errorf("missing checksum trailer %q", trailerKey)
+		}
+
+		if expected := ResponseChecksum(responses...); values[0] != expected {
+			return fmt.Errorf("checksum mismatch: expected %s, got %s", expected, values[0])
+		}
+
+		return nil
+	}
+}
+
+// ResponseChecksum calculates checksum of the serialized response messages in the format expected by ChecksumVerifier.
+//
+// The backends streaming the responses should use ResponseHasher instead, so that the responses are not kept
+// until the checksum is calculated.
+func ResponseChecksum(responses ...[]byte) string {
+	h := NewResponseHasher()
+
+	for _, resp := range responses {
+		h.Add(resp)
+	}
+
+	return h.Checksum()
+}
+
+// ResponseHasher calculates checksum of the response messages as they are sent, see ResponseChecksum.
+//
+// Each message is prefixed by its length as 8-byte big-endian integer, so that the message boundaries are
+// a part of the checksum.
+type ResponseHasher struct {
+	hash hash.Hash
+}
+
+// NewResponseHasher creates ResponseHasher.
+func NewResponseHasher() *ResponseHasher {
+	return &ResponseHasher{hash: sha256.New()}
+}
+
+// Add adds the serialized response message to the checksum.
+func (h *ResponseHasher) Add(resp []byte) {
+	var prefix [8]byte
+
+	binary.BigEndian.PutUint64(prefix[:], uint64(len(resp)))
+
+	h.hash.Write(prefix[:]) //nolint:errcheck
+	h.hash.Write(resp)      //nolint:errcheck
+}
+
+// Checksum returns the checksum of the messages added so far.
+func (h *ResponseHasher) Checksum() string {
+	return hex.EncodeToString(h.hash.Sum(nil))
+}
+
+// responseBuffer buffers responses from the backend until they are verified.
+type responseBuffer struct {
+	verifier ResponseVerifier
+	payloads [][]byte
+	size     int
+	limit    int
+}
+
+// newResponseBuffer returns nil if responses of the method should not be verified.
+func (o *handlerOptions) newResponseBuffer(fullMethodName string) *responseBuffer {
+	if o.responseVerifier == nil {
+		return nil
+	}
+
+	if o.responseVerifierMethods != nil {
+		if _, ok := o.responseVerifierMethods[fullMethodName]; !ok {
+			return nil
+		}
+	}
+
+	limit := o.responseBufferLimit
+	if limit == 0 {
+		limit = defaultResponseBufferLimit
+	}
+
+	return &responseBuffer{
+		verifier: o.responseVerifier,
+		limit:    limit,
+	}
+}
+
+// add buffers the response, it fails with ErrMessageTooLarge if the buffered responses exceed the limit.
+func (b *responseBuffer) add(backend Backend, payload []byte) error {
+	b.size += len(payload)
+
+	if b.size > b.limit {
+		b.payloads = nil
+
+		proxyErr := newError(ErrMessageTooLarge, "responses of %s buffered for verification exceed %d bytes", backend, b.limit)
+		proxyErr.Backend = backend.String()
+
+		return proxyErr
+	}
+
+	b.payloads = append(b.payloads, payload)
+
+	return nil
+}
+
+// verify returns codes.DataLoss error if the verification fails.
+func (b *responseBuffer) verify(backend Backend, fullMethodName string, trailer metadata.MD) error {
+	if err := b.verifier(backend, fullMethodName, b.payloads, trailer); err != nil {
+		return &Error{Err: err, Code: codes.DataLoss, Reason: ReasonResponseVerification, Message: fmt.Sprintf("response verification failed for %s: %v", backend, err), Backend: backend.String()}
+	}
+
+	return nil
+}
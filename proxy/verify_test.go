@@ -0,0 +1,105 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/noncepad/grpc-proxy/proxy"
+	pb "github.com/noncepad/grpc-proxy/testservice"
+)
+
+func TestResponseVerifier(t *testing.T) {
+	h := newTestHarness(t, one2oneDirector, proxy.WithResponseVerifier(
+		func(backend proxy.Backend, fullMethodName string, responses [][]byte, trailer metadata.MD) error {
+			if len(trailer.Get(serverTrailerMdKey)) == 0 {
+				return errors.New("trailer is missing")
+			}
+
+			for _, resp := range responses {
+				var msg pb.PingResponse
+
+				if err := proto.Unmarshal(resp, &msg); err != nil {
+					return err
+				}
+
+				if msg.Value == "corrupted" {
+					return errors.New("corrupted response")
+				}
+			}
+
+			return nil
+		}, "/talos.testproto.TestService/Ping", "/talos.testproto.TestService/PingList"))
+
+	ctx := testContext(t)
+
+	out, err := h.client.Ping(ctx, &pb.PingRequest{Value: "foo"})
+	require.NoError(t, err)
+	assert.Equal(t, "foo", out.Value)
+
+	_, err = h.client.Ping(ctx, &pb.PingRequest{Value: "corrupted"})
+	require.Error(t, err)
+	assert.Equal(t, codes.DataLoss, status.Code(err))
+
+	stream, err := h.client.PingList(ctx, &pb.PingRequest{Value: "corrupted"})
+	require.NoError(t, err)
+
+	// no responses are delivered if verification fails
+	_, err = stream.Recv()
+	require.Error(t, err)
+	assert.Equal(t, codes.DataLoss, status.Code(err))
+
+	// not verified method
+	_, err = h.client.PingEmpty(ctx, &pb.Empty{})
+	require.NoError(t, err)
+}
+
+func TestChecksumVerifier(t *testing.T) {
+	verifier := proxy.ChecksumVerifier("")
+	responses := [][]byte{[]byte("foo"), []byte("bar")}
+
+	require.NoError(t, verifier(nil, "/a/b", responses, metadata.Pairs(proxy.ChecksumTrailerKey, proxy.ResponseChecksum(responses...))))
+	require.Error(t, verifier(nil, "/a/b", responses, metadata.Pairs(proxy.ChecksumTrailerKey, proxy.ResponseChecksum(responses[0]))))
+	require.Error(t, verifier(nil, "/a/b", responses, nil))
+
+	// the message boundaries are part of the checksum
+	require.Error(t, verifier(nil, "/a/b", [][]byte{[]byte("foob"), []byte("ar")}, metadata.Pairs(proxy.ChecksumTrailerKey, proxy.ResponseChecksum(responses...))))
+
+	hasher := proxy.NewResponseHasher()
+
+	for _, resp := range responses {
+		hasher.Add(resp)
+	}
+
+	assert.Equal(t, proxy.ResponseChecksum(responses...), hasher.Checksum())
+}
+
+func TestResponseBufferLimit(t *testing.T) {
+	h := newTestHarness(t, one2oneDirector,
+		proxy.WithResponseVerifier(func(proxy.Backend, string, [][]byte, metadata.MD) error { return nil }),
+		proxy.WithResponseBufferLimit(100),
+	)
+
+	ctx := testContext(t)
+
+	_, err := h.client.Ping(ctx, &pb.PingRequest{Value: "foo"})
+	require.NoError(t, err)
+
+	stream, err := h.client.PingList(ctx, &pb.PingRequest{Value: "foo"})
+	require.NoError(t, err)
+
+	// the responses over the limit are not buffered
+	_, err = stream.Recv()
+	require.Error(t, err)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	assert.Contains(t, status.Convert(err).Message(), "exceed 100 bytes")
+}
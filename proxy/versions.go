@@ -0,0 +1,273 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// BackendVersionMetadataKey is the key of the header or the trailer the backends report their version with.
+//
+// The key is not stripped, so the clients see the version of the backend which served the call.
+const BackendVersionMetadataKey = "proxy-backend-version"
+
+// VersionRegistry collects the versions of the backends, so that the version skew across the fleet can be seen,
+// and the calls can be gated on the backend versions (see Gate).
+//
+// The versions are collected from the BackendVersionMetadataKey of the responses (see WithBackendVersions),
+// or set explicitly, e.g. from the payload of the health checks. The backends are identified by String().
+//
+// VersionRegistry implements http.Handler which serves the versions as JSON, so that it can be mounted on the admin
+// HTTP server.
+type VersionRegistry struct {
+	mu       sync.Mutex
+	versions map[string]string
+}
+
+// NewVersionRegistry creates the empty registry.
+func NewVersionRegistry() *VersionRegistry {
+	return &VersionRegistry{
+		versions: map[string]string{},
+	}
+}
+
+// WithBackendVersions records the versions the backends report in BackendVersionMetadataKey to the registry.
+func WithBackendVersions(registry *VersionRegistry) Option {
+	return func(o *handlerOptions) {
+		o.backendVersions = registry
+	}
+}
+
+// Set records the version of the backend.
+func (r *VersionRegistry) Set(backend Backend, version string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.versions[backend.String()] = version
+}
+
+// Version returns the version of the backend, if known.
+func (r *VersionRegistry) Version(backend Backend) (string, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	version, ok := r.versions[backend.String()]
+
+	return version, ok
+}
+
+// Versions returns the known versions of the backends by the backend name.
+func (r *VersionRegistry) Versions() map[string]string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	versions := make(map[string]string, len(r.versions))
+
+	for backend, version := range r.versions {
+		versions[backend] = version
+	}
+
+	return versions
+}
+
+// Skew returns the distinct versions of the backends sorted from the oldest, more than one version means the skew.
+func (r *VersionRegistry) Skew() []string {
+	seen := map[string]struct{}{}
+
+	var versions []string
+
+	for _, version := range r.Versions() {
+		if _, ok := seen[version]; !ok {
+			seen[version] = struct{}{}
+			versions = append(versions, version)
+		}
+	}
+
+	sort.Slice(versions, func(i, j int) bool { return CompareVersions(versions[i], versions[j]) < 0 })
+
+	return versions
+}
+
+// ServeHTTP implements http.Handler.
+func (r *VersionRegistry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	json.NewEncoder(w).Encode(struct { //nolint:errcheck
+		Versions map[string]string `json:"versions"`
+		Skew     []string          `json:"skew"`
+	}{
+		Versions: r.Versions(),
+		Skew:     r.Skew(),
+	})
+}
+
+// VersionGate requires the backends of the calls to run at least the version.
+type VersionGate struct {
+	// Method is the full method name the gate applies to, empty means all methods.
+	Method string
+	// Mode limits the gate to the calls proxied in the mode (e.g. One2Many writes fanned out to the fleet),
+	// nil means all modes.
+	Mode *Mode
+	// MinVersion is the minimum version of the backends, see CompareVersions.
+	MinVersion string
+	// AllowUnknown lets the calls through to the backends of unknown version.
+	AllowUnknown bool
+}
+
+// Gate wraps the director to fail the calls early with ErrBackendVersion if any of the backends returned
+// by the director doesn't satisfy the gates of the method, instead of proxying the call to the mixed fleet.
+//
+// The error reports the backend in the status details (see FromError).
+func (r *VersionRegistry) Gate(director StreamDirector, gates ...VersionGate) StreamDirector {
+	return func(ctx context.Context, fullMethodName string) (Mode, []Backend, error) {
+		mode, backends, err := director(ctx, fullMethodName)
+		if err != nil {
+			return mode, backends, err
+		}
+
+		for _, gate := range gates {
+			if (gate.Method != "" && gate.Method != fullMethodName) || (gate.Mode != nil && *gate.Mode != mode) {
+				continue
+			}
+
+			for _, backend := range backends {
+				if err = r.check(gate, backend, fullMethodName); err != nil {
+					return mode, nil, err
+				}
+			}
+		}
+
+		return mode, backends, nil
+	}
+}
+
+// check returns ErrBackendVersion if the backend doesn't satisfy the gate.
+func (r *VersionRegistry) check(gate VersionGate, backend Backend, fullMethodName string) error {
+	version, ok := r.Version(backend)
+
+	switch {
+	case !ok && gate.AllowUnknown:
+		return nil
+	case !ok:
+		err := newError(ErrBackendVersion, "version of backend %s is unknown, %s requires at least %s", backend, fullMethodName, gate.MinVersion)
+		err.Backend = backend.String()
+
+		return err
+	case CompareVersions(version, gate.MinVersion) < 0:
+		err := newError(ErrBackendVersion, "backend %s runs version %s, %s requires at least %s", backend, version, fullMethodName, gate.MinVersion)
+		err.Backend = backend.String()
+
+		return err
+	default:
+		return nil
+	}
+}
+
+// CompareVersions compares the semantic versions (the leading "v" is optional), returning -1, 0 or 1.
+//
+// The numeric components are compared numerically, the missing components are zero, and the pre-release version
+// (with the "-" suffix) precedes the release. The build metadata (with the "+" suffix) is ignored.
+func CompareVersions(a, b string) int {
+	a, _, _ = strings.Cut(strings.TrimPrefix(a, "v"), "+")
+	b, _, _ = strings.Cut(strings.TrimPrefix(b, "v"), "+")
+
+	aRelease, aPre, aHasPre := strings.Cut(a, "-")
+	bRelease, bPre, bHasPre := strings.Cut(b, "-")
+
+	if cmp := compareVersionParts(strings.Split(aRelease, "."), strings.Split(bRelease, ".")); cmp != 0 {
+		return cmp
+	}
+
+	switch {
+	case aHasPre && !bHasPre:
+		return -1
+	case !aHasPre && bHasPre:
+		return 1
+	}
+
+	return compareVersionParts(strings.Split(aPre, "."), strings.Split(bPre, "."))
+}
+
+// compareVersionParts compares the dot-separated components.
+func compareVersionParts(aParts, bParts []string) int {
+	for i := 0; i < len(aParts) || i < len(bParts); i++ {
+		if cmp := compareVersionPart(versionPart(aParts, i), versionPart(bParts, i)); cmp != 0 {
+			return cmp
+		}
+	}
+
+	return 0
+}
+
+func versionPart(parts []string, i int) string {
+	if i < len(parts) {
+		return parts[i]
+	}
+
+	return "0"
+}
+
+// compareVersionPart compares the parts numerically if both are numbers, lexically otherwise.
+func compareVersionPart(a, b string) int {
+	an, aErr := strconv.ParseUint(a, 10, 64)
+	bn, bErr := strconv.ParseUint(b, 10, 64)
+
+	if aErr == nil && bErr == nil {
+		return compareOrdered(an, bn)
+	}
+
+	return compareOrdered(a, b)
+}
+
+// wrapBackendVersions wraps the upstream stream to record the version the backend reports.
+func (o *handlerOptions) wrapBackendVersions(clientStream grpc.ClientStream, backend Backend) grpc.ClientStream {
+	if o.backendVersions == nil {
+		return clientStream
+	}
+
+	return &versionClientStream{
+		ClientStream: clientStream,
+		registry:     o.backendVersions,
+		backend:      backend,
+	}
+}
+
+// versionClientStream records the version of the backend from the headers and the trailers.
+type versionClientStream struct {
+	grpc.ClientStream
+
+	registry *VersionRegistry
+	backend  Backend
+}
+
+func (s *versionClientStream) Header() (metadata.MD, error) {
+	md, err := s.ClientStream.Header()
+
+	s.observe(md)
+
+	return md, err
+}
+
+func (s *versionClientStream) Trailer() metadata.MD {
+	md := s.ClientStream.Trailer()
+
+	s.observe(md)
+
+	return md
+}
+
+func (s *versionClientStream) observe(md metadata.MD) {
+	if values := md.Get(BackendVersionMetadataKey); len(values) > 0 && values[len(values)-1] != "" {
+		s.registry.Set(s.backend, values[len(values)-1])
+	}
+}
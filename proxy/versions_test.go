@@ -0,0 +1,103 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/noncepad/grpc-proxy/proxy"
+	pb "github.com/noncepad/grpc-proxy/testservice"
+)
+
+// versionService reports the version of the backend by its tag.
+type versionService struct {
+	lenientService
+
+	versions map[string]string
+}
+
+func (s *versionService) report(ctx context.Context) {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	grpc.SendHeader(ctx, metadata.Pairs(proxy.BackendVersionMetadataKey, s.versions[md.Get(backendTagMdKey)[0]])) //nolint:errcheck
+}
+
+func (s *versionService) PingEmpty(ctx context.Context, _ *pb.Empty) (*pb.PingResponse, error) {
+	s.report(ctx)
+
+	return &pb.PingResponse{}, nil
+}
+
+func (s *versionService) Ping(ctx context.Context, ping *pb.PingRequest) (*pb.PingResponse, error) {
+	s.report(ctx)
+
+	return &pb.PingResponse{Value: ping.Value}, nil
+}
+
+func TestVersionGate(t *testing.T) {
+	registry := proxy.NewVersionRegistry()
+	one2many := proxy.One2Many
+
+	h := newTestHarnessWithService(t, &versionService{versions: map[string]string{"old": "v1.2.0", "new": "v1.10.0"}},
+		func(backend proxy.Backend) proxy.StreamDirector {
+			return registry.Gate(allFailedDirector("old", "new")(backend), proxy.VersionGate{
+				Method:     "/talos.testproto.TestService/Ping",
+				Mode:       &one2many,
+				MinVersion: "1.5",
+			})
+		},
+		proxy.WithBackendVersions(registry),
+	)
+
+	// the versions are unknown until the backends report them
+	_, err := h.client.Ping(testContext(t), &pb.PingRequest{Value: "ping"})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	_, err = h.client.PingEmpty(testContext(t), &pb.Empty{})
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]string{"old": "v1.2.0", "new": "v1.10.0"}, registry.Versions())
+	assert.Equal(t, []string{"v1.2.0", "v1.10.0"}, registry.Skew())
+
+	_, err = h.client.Ping(testContext(t), &pb.PingRequest{Value: "ping"})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	proxyErr, ok := proxy.FromError(err)
+	require.True(t, ok)
+	assert.Equal(t, proxy.ReasonBackendVersion, proxyErr.Reason)
+	assert.Equal(t, "old", proxyErr.Backend)
+
+	registry.Set(&taggedBackend{tag: "old"}, "v1.6.0")
+
+	_, err = h.client.Ping(testContext(t), &pb.PingRequest{Value: "ping"})
+	require.NoError(t, err)
+}
+
+func TestCompareVersions(t *testing.T) {
+	for _, tt := range []struct {
+		a, b     string
+		expected int
+	}{
+		{"1.2.3", "1.2.3", 0},
+		{"v1.2.3", "1.2.3", 0},
+		{"1.2", "1.2.0", 0},
+		{"1.2.3", "1.10.0", -1},
+		{"2.0.0", "1.10.0", 1},
+		{"1.0.0-rc.1", "1.0.0", -1},
+		{"1.0.0-alpha", "1.0.0-beta", -1},
+		{"1.0.0-rc.2", "1.0.0-rc.10", -1},
+		{"1.0.0+build.1", "1.0.0", 0},
+	} {
+		assert.Equal(t, tt.expected, proxy.CompareVersions(tt.a, tt.b), "%s vs %s", tt.a, tt.b)
+		assert.Equal(t, -tt.expected, proxy.CompareVersions(tt.b, tt.a), "%s vs %s", tt.b, tt.a)
+	}
+}
@@ -0,0 +1,79 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy
+
+import "google.golang.org/grpc"
+
+// WithWaitForReady controls whether the upstream streams of the listed methods are created with WaitForReady.
+//
+// With WaitForReady enabled, upstream stream creation blocks until the backend connection is ready (or the call
+// context is done) instead of failing immediately with codes.Unavailable while the backend is (re)connecting, so that
+// e.g. one2many broadcasts tolerate briefly restarting upstreams. The calls should have a deadline, as otherwise
+// the call waits for the unavailable backend indefinitely.
+//
+// If fullMethodNames is empty, the setting is applied to all methods. Backends can override the setting
+// by implementing WaitForReadyBackend (see BackendWithWaitForReady).
+func WithWaitForReady(enabled bool, fullMethodNames ...string) Option {
+	return func(o *handlerOptions) {
+		if o.waitForReady == nil {
+			o.waitForReady = map[string]bool{}
+		}
+
+		if len(fullMethodNames) == 0 {
+			o.waitForReady[""] = enabled
+
+			return
+		}
+
+		for _, name := range fullMethodNames {
+			o.waitForReady[name] = enabled
+		}
+	}
+}
+
+// WaitForReadyBackend is an optional interface implemented by backends to override WaitForReady setting of the handler.
+type WaitForReadyBackend interface {
+	Backend
+
+	// WaitForReady returns whether the upstream stream for the method should be created with WaitForReady.
+	WaitForReady(fullMethodName string) bool
+}
+
+// BackendWithWaitForReady wraps the backend to always use the WaitForReady setting.
+func BackendWithWaitForReady(backend Backend, enabled bool) WaitForReadyBackend {
+	return &waitForReadyBackend{Backend: backend, enabled: enabled}
+}
+
+type waitForReadyBackend struct {
+	Backend
+
+	enabled bool
+}
+
+func (b *waitForReadyBackend) WaitForReady(string) bool {
+	return b.enabled
+}
+
+// Unwrap returns the wrapped backend.
+func (b *waitForReadyBackend) Unwrap() Backend {
+	return b.Backend
+}
+
+// upstreamCallOptions returns call options for the upstream stream.
+func (o *handlerOptions) upstreamCallOptions(backend Backend, fullMethodName string) []grpc.CallOption {
+	if wfr, ok := backendAs[WaitForReadyBackend](backend); ok {
+		return []grpc.CallOption{grpc.WaitForReady(wfr.WaitForReady(fullMethodName))}
+	}
+
+	enabled, ok := o.waitForReady[fullMethodName]
+	if !ok {
+		enabled, ok = o.waitForReady[""]
+	}
+
+	if !ok {
+		return nil
+	}
+
+	return []grpc.CallOption{grpc.WaitForReady(enabled)}
+}
@@ -0,0 +1,79 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy_test
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+
+	"github.com/noncepad/grpc-proxy/proxy"
+	pb "github.com/noncepad/grpc-proxy/testservice"
+)
+
+func TestWaitForReady(t *testing.T) {
+	// reserve the address of the restarting upstream
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	addr := listener.Addr().String()
+	require.NoError(t, listener.Close())
+
+	pool := proxy.NewConnPool(grpc.WithTransportCredentials(insecure.NewCredentials()))
+	t.Cleanup(func() { pool.Close() }) //nolint: errcheck
+
+	restarting := &proxy.DialBackend{Pool: pool, Target: addr}
+
+	h := newTestHarness(t, func(proxy.Backend) proxy.StreamDirector {
+		return func(ctx context.Context, fullMethodName string) (proxy.Mode, []proxy.Backend, error) {
+			switch fullMethodName {
+			case "/talos.testproto.TestService/PingEmpty":
+				return proxy.One2One, []proxy.Backend{proxy.BackendWithWaitForReady(restarting, false)}, nil
+			default:
+				return proxy.One2One, []proxy.Backend{restarting}, nil
+			}
+		}
+	}, proxy.WithWaitForReady(true, "/talos.testproto.TestService/Ping"))
+
+	callCtx := func() context.Context {
+		ctx, cancel := context.WithTimeout(testContext(t), 5*time.Second)
+		t.Cleanup(cancel)
+
+		return ctx
+	}
+
+	// backend override: fail fast
+	_, err = h.client.PingEmpty(callCtx(), &pb.Empty{})
+	require.Error(t, err)
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+
+	// the upstream comes up while the call is waiting
+	go func() {
+		time.Sleep(200 * time.Millisecond)
+
+		l, err := net.Listen("tcp", addr)
+		if err != nil {
+			return
+		}
+
+		server := grpc.NewServer()
+		pb.RegisterTestServiceServer(server, &assertingService{t: t})
+
+		t.Cleanup(server.Stop)
+
+		server.Serve(l) //nolint: errcheck
+	}()
+
+	out, err := h.client.Ping(callCtx(), &pb.PingRequest{Value: "foo"})
+	require.NoError(t, err)
+	assert.Equal(t, "foo", out.Value)
+}
@@ -0,0 +1,162 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// WarningsMetadataKey is the trailer key of the warnings reported during the call, one JSON-encoded Warning per value.
+const WarningsMetadataKey = "proxy-warning"
+
+// maxWarnings is the number of warnings reported per call, the rest are dropped.
+const maxWarnings = 32
+
+// Warning is a non-fatal condition of the call, e.g. stale data served by the backend or a degraded path used.
+//
+// Unlike the errors (and the errors embedded into the responses via BuildError), warnings don't affect the result
+// of the call.
+type Warning struct {
+	// Backend is the name of the backend the warning relates to, if any.
+	Backend string `json:"backend,omitempty"`
+	Reason  string `json:"reason"`
+	Message string `json:"message,omitempty"`
+}
+
+// WarningObserver is invoked for each warning reported during the call.
+type WarningObserver func(ctx context.Context, fullMethodName string, warning Warning)
+
+// WithWarnings enables the warnings reported with ReportWarning.
+//
+// The warnings are sent to the client in the WarningsMetadataKey trailer (see WarningsFromMetadata), and passed to
+// the observer, if set.
+func WithWarnings(observer WarningObserver) Option {
+	return func(o *handlerOptions) {
+		o.warnings = &warningOptions{observer: observer}
+	}
+}
+
+type warningOptions struct {
+	observer WarningObserver
+}
+
+type warningsKey struct{}
+
+// ReportWarning reports the non-fatal warning of the call.
+//
+// The context passed to the director and to the backends carries the warnings collector of the call, so that the
+// warnings can be reported while selecting the backends, connecting to them and processing the responses.
+// ReportWarning returns false if the warnings are not enabled for the call.
+func ReportWarning(ctx context.Context, warning Warning) bool {
+	collector, ok := ctx.Value(warningsKey{}).(*warningsCollector)
+	if !ok {
+		return false
+	}
+
+	collector.report(warning)
+
+	return true
+}
+
+// WarningsFromMetadata decodes the warnings from the trailer received by the client, malformed values are skipped.
+func WarningsFromMetadata(md metadata.MD) []Warning {
+	var warnings []Warning
+
+	for _, value := range md.Get(WarningsMetadataKey) {
+		var warning Warning
+
+		if err := json.Unmarshal([]byte(value), &warning); err != nil {
+			continue
+		}
+
+		warnings = append(warnings, warning)
+	}
+
+	return warnings
+}
+
+// warningsCollector collects the warnings of the call.
+type warningsCollector struct {
+	ctx            context.Context //nolint:containedctx
+	observer       WarningObserver
+	fullMethodName string
+
+	mu       sync.Mutex
+	warnings []Warning
+}
+
+func (c *warningsCollector) report(warning Warning) {
+	c.mu.Lock()
+
+	if len(c.warnings) >= maxWarnings {
+		c.mu.Unlock()
+
+		return
+	}
+
+	c.warnings = append(c.warnings, warning)
+	c.mu.Unlock()
+
+	if c.observer != nil {
+		c.observer(c.ctx, c.fullMethodName, warning)
+	}
+}
+
+// trailer encodes the collected warnings.
+func (c *warningsCollector) trailer() metadata.MD {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	md := metadata.MD{}
+
+	for _, warning := range c.warnings {
+		value, err := json.Marshal(warning)
+		if err != nil {
+			continue
+		}
+
+		md.Append(WarningsMetadataKey, string(value))
+	}
+
+	return md
+}
+
+// warningsServerStream carries the warnings collector in the context.
+type warningsServerStream struct {
+	grpc.ServerStream
+
+	ctx       context.Context //nolint:containedctx
+	collector *warningsCollector
+}
+
+func newWarningsServerStream(o *warningOptions, serverStream grpc.ServerStream, fullMethodName string) *warningsServerStream {
+	collector := &warningsCollector{
+		ctx:            serverStream.Context(),
+		observer:       o.observer,
+		fullMethodName: fullMethodName,
+	}
+
+	return &warningsServerStream{
+		ServerStream: serverStream,
+		ctx:          context.WithValue(serverStream.Context(), warningsKey{}, collector),
+		collector:    collector,
+	}
+}
+
+// Context returns the context which carries the warnings collector.
+func (s *warningsServerStream) Context() context.Context {
+	return s.ctx
+}
+
+// finish sends the collected warnings in the trailer once the call is done.
+func (s *warningsServerStream) finish() {
+	if md := s.collector.trailer(); md.Len() > 0 {
+		s.ServerStream.SetTrailer(md)
+	}
+}
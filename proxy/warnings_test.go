@@ -0,0 +1,85 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/noncepad/grpc-proxy/proxy"
+	pb "github.com/noncepad/grpc-proxy/testservice"
+)
+
+// warningBackend reports the warning on each connection.
+type warningBackend struct {
+	proxy.Backend
+}
+
+func (b *warningBackend) GetConnection(ctx context.Context, fullMethodName string) (context.Context, *grpc.ClientConn, error) {
+	proxy.ReportWarning(ctx, proxy.Warning{Backend: "stale", Reason: "STALE_DATA", Message: "served from cache"})
+
+	return b.Backend.GetConnection(ctx, fullMethodName)
+}
+
+func TestWarnings(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		observed []proxy.Warning
+	)
+
+	h := newTestHarness(t, func(backend proxy.Backend) proxy.StreamDirector {
+		return func(ctx context.Context, fullMethodName string) (proxy.Mode, []proxy.Backend, error) {
+			proxy.ReportWarning(ctx, proxy.Warning{Reason: "DEGRADED", Message: "fallback routing"})
+
+			return proxy.One2One, []proxy.Backend{&warningBackend{Backend: backend}}, nil
+		}
+	}, proxy.WithWarnings(func(ctx context.Context, fullMethodName string, warning proxy.Warning) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		assert.Equal(t, "/talos.testproto.TestService/Ping", fullMethodName)
+
+		observed = append(observed, warning)
+	}))
+
+	var trailer metadata.MD
+
+	resp, err := h.client.Ping(testContext(t), &pb.PingRequest{Value: "foo"}, grpc.Trailer(&trailer))
+	require.NoError(t, err)
+	assert.Equal(t, "foo", resp.Value)
+
+	expected := []proxy.Warning{
+		{Reason: "DEGRADED", Message: "fallback routing"},
+		{Backend: "stale", Reason: "STALE_DATA", Message: "served from cache"},
+	}
+
+	assert.Equal(t, expected, proxy.WarningsFromMetadata(trailer))
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	assert.Equal(t, expected, observed)
+}
+
+func TestWarningsDisabled(t *testing.T) {
+	h := newTestHarness(t, func(backend proxy.Backend) proxy.StreamDirector {
+		return func(ctx context.Context, fullMethodName string) (proxy.Mode, []proxy.Backend, error) {
+			assert.False(t, proxy.ReportWarning(ctx, proxy.Warning{Reason: "DEGRADED"}))
+
+			return proxy.One2One, []proxy.Backend{backend}, nil
+		}
+	})
+
+	var trailer metadata.MD
+
+	_, err := h.client.Ping(testContext(t), &pb.PingRequest{Value: "foo"}, grpc.Trailer(&trailer))
+	require.NoError(t, err)
+	assert.Empty(t, trailer.Get(proxy.WarningsMetadataKey))
+}
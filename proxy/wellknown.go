@@ -0,0 +1,155 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy
+
+import (
+	"context"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Well-known service prefixes.
+//
+// The prefix matches the services with the same name or the names starting with the prefix and a dot, e.g.
+// ReflectionServices matches both grpc.reflection.v1alpha.ServerReflection and grpc.reflection.v1.ServerReflection.
+const (
+	HealthServices     = "grpc.health"
+	ReflectionServices = "grpc.reflection"
+	ChannelzServices   = "grpc.channelz"
+)
+
+// WellKnownAction is the way the calls of the well-known services are handled.
+type WellKnownAction int
+
+// WellKnownAction constants.
+const (
+	// WellKnownDirect routes the calls with the director like any other call.
+	WellKnownDirect WellKnownAction = iota
+	// WellKnownServe serves the calls by the proxy itself with the service implementation.
+	WellKnownServe
+	// WellKnownPassthrough proxies the calls one2one to the backends of the policy, bypassing the director.
+	WellKnownPassthrough
+	// WellKnownBlock rejects the calls with ErrMethodNotAllowed.
+	WellKnownBlock
+)
+
+// WellKnownPolicy configures handling of the well-known service calls.
+type WellKnownPolicy struct {
+	// ServiceDesc and ServiceImpl serve the calls for WellKnownServe, e.g. health.NewServer() with
+	// grpc_health_v1.Health_ServiceDesc.
+	ServiceDesc *grpc.ServiceDesc
+	ServiceImpl interface{}
+
+	// Backends for WellKnownPassthrough. With several backends, the failover policy (if any) picks the backend.
+	Backends []Backend
+
+	Action WellKnownAction
+}
+
+// WithWellKnownService sets the policy for the calls of the services matching the prefix (see HealthServices,
+// ReflectionServices, ChannelzServices), so that the calls of the health checks, the server reflection and
+// the channelz don't fall into the director accidentally.
+//
+// The policy with the longest matching prefix is used. The calls are served or blocked before any other checks,
+// including the method allowlist and the authentication, so the proxy health can be checked without credentials.
+//
+// The services registered on the grpc.Server itself never reach the proxy handler.
+func WithWellKnownService(prefix string, policy WellKnownPolicy) Option {
+	return func(o *handlerOptions) {
+		if o.wellKnownPolicies == nil {
+			o.wellKnownPolicies = map[string]WellKnownPolicy{}
+		}
+
+		o.wellKnownPolicies[prefix] = policy
+	}
+}
+
+// wellKnownPolicy returns the policy of the well-known service of the method.
+func (o *handlerOptions) wellKnownPolicy(fullMethodName string) (WellKnownPolicy, bool) {
+	if len(o.wellKnownPolicies) == 0 {
+		return WellKnownPolicy{}, false
+	}
+
+	service, _, ok := splitMethodName(fullMethodName)
+	if !ok {
+		return WellKnownPolicy{}, false
+	}
+
+	var (
+		matched string
+		policy  WellKnownPolicy
+		found   bool
+	)
+
+	for prefix, p := range o.wellKnownPolicies {
+		if service != prefix && !strings.HasPrefix(service, prefix+".") {
+			continue
+		}
+
+		if !found || len(prefix) > len(matched) {
+			matched, policy, found = prefix, p, true
+		}
+	}
+
+	return policy, found
+}
+
+// handleWellKnown serves or blocks the call of the well-known service, handled is false if the call should be proxied.
+func (o *handlerOptions) handleWellKnown(serverStream grpc.ServerStream, fullMethodName string) (handled bool, err error) {
+	policy, ok := o.wellKnownPolicy(fullMethodName)
+	if !ok {
+		return false, nil
+	}
+
+	switch policy.Action { //nolint:exhaustive
+	case WellKnownBlock:
+		return true, newError(ErrMethodNotAllowed, "method %s of the well-known service is blocked", fullMethodName)
+	case WellKnownServe:
+		return true, serveLocally(policy.ServiceDesc, policy.ServiceImpl, serverStream, fullMethodName)
+	default:
+		return false, nil
+	}
+}
+
+// direct invokes the director, unless the call of the well-known service is passed through to the fixed backends.
+func (s *handler) direct(ctx context.Context, fullMethodName string) (Mode, []Backend, error) {
+	if policy, ok := s.options.wellKnownPolicy(fullMethodName); ok && policy.Action == WellKnownPassthrough {
+		return One2One, policy.Backends, nil
+	}
+
+	return s.director(ctx, fullMethodName)
+}
+
+// serveLocally dispatches the call to the method of the service implementation.
+func serveLocally(desc *grpc.ServiceDesc, impl interface{}, serverStream grpc.ServerStream, fullMethodName string) error {
+	service, method, _ := splitMethodName(fullMethodName)
+
+	if desc == nil || desc.ServiceName != service {
+		return status.Errorf(codes.Unimplemented, "unknown service %s", service)
+	}
+
+	for _, m := range desc.Methods {
+		if m.MethodName != method {
+			continue
+		}
+
+		resp, err := m.Handler(impl, serverStream.Context(), serverStream.RecvMsg, nil)
+		if err != nil {
+			return err
+		}
+
+		return serverStream.SendMsg(resp)
+	}
+
+	for _, s := range desc.Streams {
+		if s.StreamName == method {
+			return s.Handler(impl, serverStream)
+		}
+	}
+
+	return status.Errorf(codes.Unimplemented, "unknown method %s for service %s", method, service)
+}
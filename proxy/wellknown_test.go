@@ -0,0 +1,93 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	channelzpb "google.golang.org/grpc/channelz/grpc_channelz_v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	rpb "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+	"google.golang.org/grpc/status"
+
+	"github.com/noncepad/grpc-proxy/proxy"
+	pb "github.com/noncepad/grpc-proxy/testservice"
+)
+
+func TestWellKnownServices(t *testing.T) {
+	var directed int32
+
+	healthServer := health.NewServer()
+	healthServer.SetServingStatus("proxy", healthpb.HealthCheckResponse_NOT_SERVING)
+
+	reflectionBackend := newReflectionBackend(t, grpc.WithCodec(proxy.Codec())) //nolint: staticcheck
+
+	h := newTestHarness(t, func(backend proxy.Backend) proxy.StreamDirector {
+		return func(ctx context.Context, fullMethodName string) (proxy.Mode, []proxy.Backend, error) {
+			atomic.AddInt32(&directed, 1)
+
+			return proxy.One2One, []proxy.Backend{backend}, nil
+		}
+	},
+		proxy.WithWellKnownService(proxy.HealthServices, proxy.WellKnownPolicy{
+			Action:      proxy.WellKnownServe,
+			ServiceDesc: &healthpb.Health_ServiceDesc,
+			ServiceImpl: healthServer,
+		}),
+		proxy.WithWellKnownService(proxy.ReflectionServices, proxy.WellKnownPolicy{
+			Action:   proxy.WellKnownPassthrough,
+			Backends: []proxy.Backend{reflectionBackend},
+		}),
+		proxy.WithWellKnownService(proxy.ChannelzServices, proxy.WellKnownPolicy{Action: proxy.WellKnownBlock}),
+	)
+	defer h.stop()
+
+	ctx := testContext(t)
+
+	// served by the proxy
+	healthClient := healthpb.NewHealthClient(h.clientConn)
+
+	resp, err := healthClient.Check(ctx, &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, resp.Status)
+
+	watch, err := healthClient.Watch(ctx, &healthpb.HealthCheckRequest{Service: "proxy"})
+	require.NoError(t, err)
+
+	resp, err = watch.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, resp.Status)
+
+	// passed through to the fixed backend
+	stream, err := rpb.NewServerReflectionClient(h.clientConn).ServerReflectionInfo(ctx)
+	require.NoError(t, err)
+
+	require.NoError(t, stream.Send(&rpb.ServerReflectionRequest{MessageRequest: &rpb.ServerReflectionRequest_ListServices{}}))
+
+	reflectionResp, err := stream.Recv()
+	require.NoError(t, err)
+	assert.NotEmpty(t, reflectionResp.GetListServicesResponse().GetService())
+	require.NoError(t, stream.CloseSend())
+
+	assert.EqualValues(t, 1, atomic.LoadInt32(&reflectionBackend.connections))
+
+	// blocked
+	_, err = channelzpb.NewChannelzClient(h.clientConn).GetTopChannels(ctx, &channelzpb.GetTopChannelsRequest{})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	assert.Zero(t, atomic.LoadInt32(&directed))
+
+	// other calls go to the director
+	_, err = h.client.Ping(ctx, &pb.PingRequest{Value: "foo"})
+	require.NoError(t, err)
+
+	assert.EqualValues(t, 1, atomic.LoadInt32(&directed))
+}
@@ -0,0 +1,181 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+// errWindowClosed is returned for the messages sent once the windowing stream is finished.
+var errWindowClosed = errors.New("windowing stream is finished")
+
+// FrameCombiner combines the serialized response messages of the window into a single message.
+type FrameCombiner func(fullMethodName string, payloads [][]byte) ([]byte, error)
+
+// ConcatFrames is the FrameCombiner which concatenates the serialized messages.
+//
+// Concatenation of the serialized protobuf messages is the serialization of the merged message: repeated fields
+// are appended, scalar fields keep the last value. So for the responses with a repeated field of the items
+// (e.g. `repeated Event events = 1;`), the combined message carries the items of all the messages of the window.
+func ConcatFrames(_ string, payloads [][]byte) ([]byte, error) {
+	size := 0
+
+	for _, payload := range payloads {
+		size += len(payload)
+	}
+
+	combined := make([]byte, 0, size)
+
+	for _, payload := range payloads {
+		combined = append(combined, payload...)
+	}
+
+	return combined, nil
+}
+
+// FrameWindowPolicy configures the coalescing of the response messages.
+type FrameWindowPolicy struct {
+	// MaxFrames is the maximum number of messages in the window, zero means no limit.
+	MaxFrames int
+	// MaxDelay is the maximum time the first message of the window waits for the window to be sent,
+	// zero means no limit.
+	MaxDelay time.Duration
+	// Combiner combines the messages of the window (default ConcatFrames).
+	Combiner FrameCombiner
+}
+
+// WithFrameWindowing coalesces the response messages of the listed methods sent to the client in windows:
+// the messages are buffered until the window is full (MaxFrames) or its time is up (MaxDelay), then combined
+// into a single message with the combiner, reducing the per-message overhead of the chatty upstream streams.
+//
+// The pending window is sent once the call finishes. Windowing trades latency for the message overhead,
+// so the policy should set MaxDelay for the streams the client consumes interactively.
+func WithFrameWindowing(policy FrameWindowPolicy, fullMethodNames ...string) Option {
+	if policy.Combiner == nil {
+		policy.Combiner = ConcatFrames
+	}
+
+	return func(o *handlerOptions) {
+		if policy.MaxFrames < 0 || policy.MaxDelay < 0 {
+			o.invalid("frame window limits should not be negative")
+		}
+
+		if o.frameWindows == nil {
+			o.frameWindows = map[string]FrameWindowPolicy{}
+		}
+
+		for _, name := range fullMethodNames {
+			o.frameWindows[name] = policy
+		}
+	}
+}
+
+// windowingServerStream coalesces the messages sent to the client.
+type windowingServerStream struct {
+	grpc.ServerStream
+
+	policy FrameWindowPolicy
+	method string
+
+	mu      sync.Mutex
+	pending [][]byte
+	timer   *time.Timer
+	err     error
+	closed  bool
+}
+
+func newWindowingServerStream(serverStream grpc.ServerStream, policy FrameWindowPolicy, fullMethodName string) *windowingServerStream {
+	return &windowingServerStream{
+		ServerStream: serverStream,
+		policy:       policy,
+		method:       fullMethodName,
+	}
+}
+
+func (s *windowingServerStream) SendMsg(m interface{}) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.err != nil {
+		return s.err
+	}
+
+	if s.closed {
+		return errWindowClosed
+	}
+
+	f, ok := m.(*Frame)
+	if !ok {
+		// not a proxied message, the window is sent first to keep the order
+		if err := s.flushLocked(); err != nil {
+			return err
+		}
+
+		return s.ServerStream.SendMsg(m)
+	}
+
+	s.pending = append(s.pending, f.payload)
+
+	if len(s.pending) == 1 && s.policy.MaxDelay > 0 {
+		s.timer = time.AfterFunc(s.policy.MaxDelay, s.timedFlush)
+	}
+
+	if s.policy.MaxFrames > 0 && len(s.pending) >= s.policy.MaxFrames {
+		return s.flushLocked()
+	}
+
+	return nil
+}
+
+func (s *windowingServerStream) timedFlush() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.closed {
+		s.flushLocked() //nolint:errcheck // the error is returned by the next SendMsg or finish
+	}
+}
+
+// flushLocked combines and sends the pending messages.
+func (s *windowingServerStream) flushLocked() error {
+	if s.timer != nil {
+		s.timer.Stop()
+		s.timer = nil
+	}
+
+	if len(s.pending) == 0 || s.err != nil {
+		return s.err
+	}
+
+	pending := s.pending
+	s.pending = nil
+
+	payload, err := s.policy.Combiner(s.method, pending)
+	if err != nil {
+		s.err = newError(ErrInternal, "error combining the messages of %s: %v", s.method, err)
+
+		return s.err
+	}
+
+	if err = s.ServerStream.SendMsg(&Frame{payload: payload}); err != nil {
+		s.err = err
+	}
+
+	return s.err
+}
+
+// finish sends the pending window, no messages can be sent afterwards.
+func (s *windowingServerStream) finish() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	err := s.flushLocked()
+	s.closed = true
+
+	return err
+}
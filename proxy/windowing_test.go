@@ -0,0 +1,71 @@
+// Copyright 2026 noncepad. All Rights Reserved.
+// See LICENSE for licensing terms.
+
+package proxy_test
+
+import (
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/noncepad/grpc-proxy/proxy"
+	pb "github.com/noncepad/grpc-proxy/testservice"
+)
+
+func TestFrameWindowing(t *testing.T) {
+	var windows []int
+
+	h := newTestHarness(t, one2oneDirector,
+		proxy.WithFrameWindowing(proxy.FrameWindowPolicy{
+			MaxFrames: 6,
+			Combiner: func(fullMethodName string, payloads [][]byte) ([]byte, error) {
+				windows = append(windows, len(payloads))
+
+				return proxy.ConcatFrames(fullMethodName, payloads)
+			},
+		}, "/talos.testproto.TestService/PingList"),
+		proxy.WithFrameWindowing(proxy.FrameWindowPolicy{MaxDelay: 10 * time.Millisecond}, "/talos.testproto.TestService/PingStream"))
+
+	ctx := testContext(t)
+
+	stream, err := h.client.PingList(ctx, &pb.PingRequest{Value: "foo"})
+	require.NoError(t, err)
+
+	var counters []int32
+
+	for {
+		resp, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		require.NoError(t, err)
+
+		// the scalar fields of the concatenated messages keep the last value
+		assert.Equal(t, "foo", resp.Value)
+		counters = append(counters, resp.Counter)
+	}
+
+	// 20 responses in the windows of 6, the last window is sent once the call finishes
+	assert.Equal(t, []int32{5, 11, 17, 19}, counters)
+	assert.Equal(t, []int{6, 6, 6, 2}, windows)
+
+	// the window is sent once its time is up, even if the stream is idle
+	pingStream, err := h.client.PingStream(ctx)
+	require.NoError(t, err)
+
+	require.NoError(t, pingStream.Send(&pb.PingRequest{Value: "bar"}))
+
+	resp, err := pingStream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, "bar", resp.Value)
+
+	require.NoError(t, pingStream.CloseSend())
+
+	_, err = pingStream.Recv()
+	require.ErrorIs(t, err, io.EOF)
+}